@@ -53,3 +53,20 @@ func (h *AlertHandler) GetExpiringSoonAlerts(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, utils.SuccessResponse(items))
 }
+
+// GetExpiringDocumentAlerts returns supplier documents expiring within N days
+func (h *AlertHandler) GetExpiringDocumentAlerts(c *gin.Context) {
+	days := 30
+	if d := c.Query("days"); d != "" {
+		if parsed, err := strconv.Atoi(d); err == nil && parsed > 0 {
+			days = parsed
+		}
+	}
+
+	items, err := h.alertService.GetExpiringDocumentAlerts(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("ALERT_ERROR", "Failed to get expiring document alerts: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(items))
+}
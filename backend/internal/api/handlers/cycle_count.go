@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CycleCountHandler handles HTTP requests for ABC classification and cycle counting
+type CycleCountHandler struct {
+	service service.CycleCountService
+}
+
+func NewCycleCountHandler(service service.CycleCountService) *CycleCountHandler {
+	return &CycleCountHandler{service: service}
+}
+
+// GetPolicy handles GET /cycle-counts/policies/:warehouse_id
+func (h *CycleCountHandler) GetPolicy(c *gin.Context) {
+	warehouseID, err := strconv.ParseUint(c.Param("warehouse_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid warehouse ID"))
+		return
+	}
+	policy, err := h.service.GetPolicy(uint(warehouseID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("FETCH_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(policy))
+}
+
+// SavePolicy handles PUT /cycle-counts/policies
+func (h *CycleCountHandler) SavePolicy(c *gin.Context) {
+	var req dto.SaveCycleCountPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	policy, err := h.service.SavePolicy(&req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("SAVE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Cycle count policy saved", policy))
+}
+
+// Classify handles POST /cycle-counts/classify/:warehouse_id
+func (h *CycleCountHandler) Classify(c *gin.Context) {
+	warehouseID, err := strconv.ParseUint(c.Param("warehouse_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid warehouse ID"))
+		return
+	}
+	result, err := h.service.Classify(uint(warehouseID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CLASSIFY_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("ABC classification updated", result))
+}
+
+// ListClasses handles GET /cycle-counts/classes
+func (h *CycleCountHandler) ListClasses(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	filters := make(map[string]interface{})
+	for _, key := range []string{"abc_class", "item_type"} {
+		if v := c.Query(key); v != "" {
+			filters[key] = v
+		}
+	}
+	if v := c.Query("warehouse_id"); v != "" {
+		id, _ := strconv.ParseUint(v, 10, 32)
+		filters["warehouse_id"] = uint(id)
+	}
+
+	classes, total, err := h.service.ListClasses(filters, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       classes,
+		"pagination": utils.CalculatePagination(page, limit, total),
+	})
+}
+
+// Generate handles POST /cycle-counts/generate
+func (h *CycleCountHandler) Generate(c *gin.Context) {
+	var req dto.GenerateCycleCountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+
+	result, err := h.service.Generate(&req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("GENERATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Cycle count tasks generated", result))
+}
+
+// ListTasks handles GET /cycle-counts/tasks
+func (h *CycleCountHandler) ListTasks(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	filters := make(map[string]interface{})
+	for _, key := range []string{"status", "abc_class", "item_type", "due_by"} {
+		if v := c.Query(key); v != "" {
+			filters[key] = v
+		}
+	}
+	for _, key := range []string{"warehouse_id", "item_id", "location_id"} {
+		if v := c.Query(key); v != "" {
+			id, _ := strconv.ParseUint(v, 10, 32)
+			filters[key] = uint(id)
+		}
+	}
+
+	tasks, total, err := h.service.ListTasks(filters, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       tasks,
+		"pagination": utils.CalculatePagination(page, limit, total),
+	})
+}
+
+// GetTask handles GET /cycle-counts/tasks/:id
+func (h *CycleCountHandler) GetTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	task, err := h.service.GetTask(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(task))
+}
+
+// Count handles POST /cycle-counts/tasks/:id/count
+func (h *CycleCountHandler) Count(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	var req dto.EnterCycleCountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	task, err := h.service.Count(uint(id), &req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("COUNT_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Count recorded", task))
+}
+
+// Recount handles POST /cycle-counts/tasks/:id/recount
+func (h *CycleCountHandler) Recount(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	task, err := h.service.Recount(uint(id), userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("RECOUNT_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Recount requested", task))
+}
+
+// Approve handles POST /cycle-counts/approve
+func (h *CycleCountHandler) Approve(c *gin.Context) {
+	var req dto.ApproveCycleCountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	result, err := h.service.Approve(&req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("APPROVE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Variances approved, draft stock adjustment created", result))
+}
+
+// Cancel handles POST /cycle-counts/tasks/:id/cancel
+func (h *CycleCountHandler) Cancel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	task, err := h.service.Cancel(uint(id), userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CANCEL_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Cycle count task cancelled", task))
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// DocumentHandler serves printable PDFs for purchase orders, GRNs, MINs, delivery orders and transfers
+type DocumentHandler struct {
+	service service.DocumentService
+}
+
+func NewDocumentHandler(service service.DocumentService) *DocumentHandler {
+	return &DocumentHandler{service: service}
+}
+
+// PurchaseOrderPDF handles GET /purchase-orders/:id/pdf
+func (h *DocumentHandler) PurchaseOrderPDF(c *gin.Context) {
+	h.serve(c, h.service.PurchaseOrderPDF)
+}
+
+// GRNPDF handles GET /grns/:id/pdf
+func (h *DocumentHandler) GRNPDF(c *gin.Context) {
+	h.serve(c, h.service.GRNPDF)
+}
+
+// MaterialIssueNotePDF handles GET /material-issue-notes/:id/pdf
+func (h *DocumentHandler) MaterialIssueNotePDF(c *gin.Context) {
+	h.serve(c, h.service.MaterialIssueNotePDF)
+}
+
+// DeliveryOrderPDF handles GET /delivery-orders/:id/pdf
+func (h *DocumentHandler) DeliveryOrderPDF(c *gin.Context) {
+	h.serve(c, h.service.DeliveryOrderPDF)
+}
+
+// StockTransferPDF handles GET /inventory/transfers/:id/pdf
+func (h *DocumentHandler) StockTransferPDF(c *gin.Context) {
+	h.serve(c, h.service.StockTransferPDF)
+}
+
+// serve renders the document in ?lang=vi|en (default from config) and returns it inline;
+// ?download=true asks the browser to save it instead
+func (h *DocumentHandler) serve(c *gin.Context, render func(id uint, lang string) (*service.RenderedDocument, error)) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+
+	doc, err := render(uint(id), c.Query("lang"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("RENDER_ERROR", err.Error()))
+		return
+	}
+
+	disposition := "inline"
+	if c.Query("download") == "true" {
+		disposition = "attachment"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, doc.FileName))
+	c.Data(http.StatusOK, "application/pdf", doc.Content)
+}
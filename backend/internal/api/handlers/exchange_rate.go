@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ExchangeRateHandler handles HTTP requests for exchange rates
+type ExchangeRateHandler struct {
+	service service.ExchangeRateService
+}
+
+func NewExchangeRateHandler(service service.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{service: service}
+}
+
+// List handles GET /exchange-rates?currency=USD&from=&to=
+func (h *ExchangeRateHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset := (page - 1) * limit
+
+	filters := make(map[string]interface{})
+	if currency := c.Query("currency"); currency != "" {
+		filters["currency"] = service.NormalizeCurrency(currency)
+	}
+	if from := c.Query("from"); from != "" {
+		filters["from"] = from
+	}
+	if to := c.Query("to"); to != "" {
+		filters["to"] = to
+	}
+
+	rates, total, err := h.service.List(filters, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       rates,
+		"pagination": utils.CalculatePagination(page, limit, total),
+	})
+}
+
+// GetRate handles GET /exchange-rates/effective?currency=USD&date=YYYY-MM-DD
+func (h *ExchangeRateHandler) GetRate(c *gin.Context) {
+	currency := service.NormalizeCurrency(c.Query("currency"))
+	date := c.Query("date")
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	rate, err := h.service.GetRate(currency, date)
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("RATE_NOT_FOUND", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(gin.H{"currency": currency, "date": date, "rate": rate}))
+}
+
+// Upsert handles POST /exchange-rates
+func (h *ExchangeRateHandler) Upsert(c *gin.Context) {
+	var req dto.UpsertExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	rate, err := h.service.Upsert(&req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("SAVE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Exchange rate saved", rate))
+}
+
+// Import handles POST /exchange-rates/import (multipart "file": currency,rate_date,rate)
+func (h *ExchangeRateHandler) Import(c *gin.Context) {
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("NO_FILE", "No file provided"))
+		return
+	}
+	defer file.Close()
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	result, err := h.service.Import(file, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("IMPORT_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Exchange rates imported", result))
+}
+
+// Delete handles DELETE /exchange-rates/:id
+func (h *ExchangeRateHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	if err := h.service.Delete(uint(id), userID, usernameStr); err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Exchange rate deleted", nil))
+}
@@ -158,6 +158,62 @@ func (h *InventoryHandler) PostTransfer(c *gin.Context) {
 	c.JSON(http.StatusOK, st)
 }
 
+// ShipTransfer moves the transfer's stock into the in-transit location of the destination
+func (h *InventoryHandler) ShipTransfer(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	var req dto.ShipStockTransferRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userID := uint(c.MustGet("user_id").(int64))
+	st, err := h.stService.ShipTransfer(uint(id), &req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// ReceiveTransfer books a shipped transfer into the destination; items left out arrived in full
+func (h *InventoryHandler) ReceiveTransfer(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	var req dto.ReceiveStockTransferRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userID := uint(c.MustGet("user_id").(int64))
+	st, err := h.stService.ReceiveTransfer(uint(id), &req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// InTransitReport lists the transfers shipped and not yet received, with their value
+func (h *InventoryHandler) InTransitReport(c *gin.Context) {
+	var filter dto.InTransitFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfers, err := h.stService.InTransitReport(&filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": transfers})
+}
+
 func (h *InventoryHandler) CancelTransfer(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 32)
 	userID := uint(c.MustGet("user_id").(int64))
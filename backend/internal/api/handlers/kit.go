@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// KitHandler handles HTTP requests for kit definitions and assembly/disassembly orders
+type KitHandler struct {
+	service service.KitService
+}
+
+func NewKitHandler(service service.KitService) *KitHandler {
+	return &KitHandler{service: service}
+}
+
+// List handles GET /kits
+func (h *KitHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	filters := make(map[string]interface{})
+	if v := c.Query("search"); v != "" {
+		filters["search"] = v
+	}
+	if v := c.Query("is_active"); v != "" {
+		filters["is_active"] = v == "true"
+	}
+	if v := c.Query("product_id"); v != "" {
+		id, _ := strconv.ParseUint(v, 10, 32)
+		filters["product_id"] = uint(id)
+	}
+
+	kits, total, err := h.service.ListKits(filters, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       kits,
+		"pagination": utils.CalculatePagination(page, limit, total),
+	})
+}
+
+// Get handles GET /kits/:id
+func (h *KitHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	kit, err := h.service.GetKit(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(kit))
+}
+
+// Create handles POST /kits
+func (h *KitHandler) Create(c *gin.Context) {
+	var req dto.CreateKitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	kit, err := h.service.CreateKit(&req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CREATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Kit created", kit))
+}
+
+// Update handles PUT /kits/:id
+func (h *KitHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	var req dto.UpdateKitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	kit, err := h.service.UpdateKit(uint(id), &req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("UPDATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Kit updated", kit))
+}
+
+// ListOrders handles GET /kit-orders
+func (h *KitHandler) ListOrders(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	filters := make(map[string]interface{})
+	for _, key := range []string{"status", "order_type"} {
+		if v := c.Query(key); v != "" {
+			filters[key] = v
+		}
+	}
+	for _, key := range []string{"warehouse_id", "kit_id"} {
+		if v := c.Query(key); v != "" {
+			id, _ := strconv.ParseUint(v, 10, 32)
+			filters[key] = uint(id)
+		}
+	}
+
+	orders, total, err := h.service.ListOrders(filters, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       orders,
+		"pagination": utils.CalculatePagination(page, limit, total),
+	})
+}
+
+// GetOrder handles GET /kit-orders/:id
+func (h *KitHandler) GetOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	order, err := h.service.GetOrder(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(order))
+}
+
+// CreateOrder handles POST /kit-orders
+func (h *KitHandler) CreateOrder(c *gin.Context) {
+	var req dto.CreateKitOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	order, err := h.service.CreateOrder(&req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CREATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Kit order created", order))
+}
+
+// PostOrder handles POST /kit-orders/:id/post
+func (h *KitHandler) PostOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	order, err := h.service.PostOrder(uint(id), userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("POST_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Kit order posted", order))
+}
+
+// CancelOrder handles POST /kit-orders/:id/cancel
+func (h *KitHandler) CancelOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	order, err := h.service.CancelOrder(uint(id), userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CANCEL_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Kit order cancelled", order))
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// LabelHandler handles HTTP requests for label templates, printing and the reprint log
+type LabelHandler struct {
+	service service.LabelService
+}
+
+func NewLabelHandler(service service.LabelService) *LabelHandler {
+	return &LabelHandler{service: service}
+}
+
+// ListTemplates handles GET /labels/templates
+func (h *LabelHandler) ListTemplates(c *gin.Context) {
+	filters := make(map[string]interface{})
+	if v := c.Query("label_type"); v != "" {
+		filters["label_type"] = v
+	}
+	if v := c.Query("format"); v != "" {
+		filters["format"] = v
+	}
+	if v := c.Query("is_active"); v != "" {
+		filters["is_active"] = v == "true"
+	}
+
+	templates, err := h.service.ListTemplates(filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(templates))
+}
+
+// CreateTemplate handles POST /labels/templates
+func (h *LabelHandler) CreateTemplate(c *gin.Context) {
+	var req dto.SaveLabelTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	template, err := h.service.CreateTemplate(&req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CREATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Label template created", template))
+}
+
+// UpdateTemplate handles PUT /labels/templates/:id
+func (h *LabelHandler) UpdateTemplate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	var req dto.SaveLabelTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	template, err := h.service.UpdateTemplate(uint(id), &req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("UPDATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Label template updated", template))
+}
+
+// Print handles POST /labels/print and returns the ZPL or PDF file
+func (h *LabelHandler) Print(c *gin.Context) {
+	var req dto.PrintLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	labels, err := h.service.Print(&req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("PRINT_ERROR", err.Error()))
+		return
+	}
+	h.serve(c, labels)
+}
+
+// ListLogs handles GET /labels/logs
+func (h *LabelHandler) ListLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	filters := make(map[string]interface{})
+	if v := c.Query("label_type"); v != "" {
+		filters["label_type"] = v
+	}
+	if v := c.Query("source_type"); v != "" {
+		filters["source_type"] = v
+	}
+	if v := c.Query("source_id"); v != "" {
+		id, _ := strconv.ParseUint(v, 10, 32)
+		filters["source_id"] = uint(id)
+	}
+	if v := c.Query("reprints"); v != "" {
+		filters["reprints"] = v == "true"
+	}
+
+	logs, total, err := h.service.ListLogs(filters, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       logs,
+		"pagination": utils.CalculatePagination(page, limit, total),
+	})
+}
+
+// GetLog handles GET /labels/logs/:id
+func (h *LabelHandler) GetLog(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	log, err := h.service.GetLog(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(log))
+}
+
+// Reprint handles POST /labels/logs/:id/reprint
+func (h *LabelHandler) Reprint(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	var req dto.ReprintLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	labels, err := h.service.Reprint(uint(id), &req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("REPRINT_ERROR", err.Error()))
+		return
+	}
+	h.serve(c, labels)
+}
+
+// serve returns the print file; X-Label-Log-ID carries the print log entry for a later reprint
+func (h *LabelHandler) serve(c *gin.Context, labels *service.RenderedLabels) {
+	disposition := "inline"
+	if c.Query("download") == "true" {
+		disposition = "attachment"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, labels.FileName))
+	c.Header("X-Label-Log-ID", strconv.FormatUint(uint64(labels.LogID), 10))
+	c.Data(http.StatusOK, labels.ContentType, labels.Content)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// LandedCostHandler handles HTTP requests for landed cost vouchers
+type LandedCostHandler struct {
+	service service.LandedCostService
+}
+
+func NewLandedCostHandler(service service.LandedCostService) *LandedCostHandler {
+	return &LandedCostHandler{service: service}
+}
+
+// Create handles POST /landed-costs
+func (h *LandedCostHandler) Create(c *gin.Context) {
+	var req dto.CreateLandedCostVoucherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	voucher, err := h.service.Create(&req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CREATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Landed cost voucher created successfully", voucher))
+}
+
+// GetByID handles GET /landed-costs/:id
+func (h *LandedCostHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	voucher, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(voucher))
+}
+
+// List handles GET /landed-costs
+func (h *LandedCostHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	filters := make(map[string]interface{})
+	if search := c.Query("search"); search != "" {
+		filters["search"] = search
+	}
+	if status := c.Query("status"); status != "" {
+		filters["status"] = status
+	}
+	if grnID := c.Query("grn_id"); grnID != "" {
+		id, _ := strconv.ParseUint(grnID, 10, 32)
+		filters["grn_id"] = uint(id)
+	}
+
+	vouchers, total, err := h.service.List(filters, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       vouchers,
+		"pagination": utils.CalculatePagination(page, limit, total),
+	})
+}
+
+// Post handles POST /landed-costs/:id/post
+func (h *LandedCostHandler) Post(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	voucher, err := h.service.Post(uint(id), userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("POST_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Landed cost voucher posted successfully", voucher))
+}
+
+// Cancel handles POST /landed-costs/:id/cancel
+func (h *LandedCostHandler) Cancel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	if err := h.service.Cancel(uint(id), userID, usernameStr); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CANCEL_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Landed cost voucher cancelled", nil))
+}
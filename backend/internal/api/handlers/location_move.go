@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// LocationMoveHandler handles HTTP requests for moves between locations of one warehouse
+type LocationMoveHandler struct {
+	service service.LocationMoveService
+}
+
+func NewLocationMoveHandler(service service.LocationMoveService) *LocationMoveHandler {
+	return &LocationMoveHandler{service: service}
+}
+
+// List handles GET /location-moves
+func (h *LocationMoveHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	filters := make(map[string]interface{})
+	for _, key := range []string{"status", "source"} {
+		if v := c.Query(key); v != "" {
+			filters[key] = v
+		}
+	}
+	for _, key := range []string{"warehouse_id", "location_id"} {
+		if v := c.Query(key); v != "" {
+			id, _ := strconv.ParseUint(v, 10, 32)
+			filters[key] = uint(id)
+		}
+	}
+
+	moves, total, err := h.service.ListMoves(filters, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       moves,
+		"pagination": utils.CalculatePagination(page, limit, total),
+	})
+}
+
+// Get handles GET /location-moves/:id
+func (h *LocationMoveHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	move, err := h.service.GetMove(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(move))
+}
+
+// Create handles POST /location-moves
+func (h *LocationMoveHandler) Create(c *gin.Context) {
+	var req dto.CreateLocationMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	move, err := h.service.CreateMove(&req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CREATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Location move created", move))
+}
+
+// Scan handles POST /location-moves/scan: source location, item and target location scanned
+// on a handheld; the stock moves at once
+func (h *LocationMoveHandler) Scan(c *gin.Context) {
+	var req dto.ScanLocationMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	move, err := h.service.ScanMove(&req, userID, usernameStr)
+	if err != nil {
+		if errors.Is(err, service.ErrScanMismatch) {
+			c.JSON(http.StatusUnprocessableEntity, utils.ErrorResponse("SCAN_MISMATCH", err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("MOVE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Stock moved", move))
+}
+
+// Post handles POST /location-moves/:id/post
+func (h *LocationMoveHandler) Post(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	move, err := h.service.PostMove(uint(id), userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("POST_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Location move posted", move))
+}
+
+// Cancel handles POST /location-moves/:id/cancel
+func (h *LocationMoveHandler) Cancel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	move, err := h.service.CancelMove(uint(id), userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CANCEL_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Location move cancelled", move))
+}
+
+// Consolidation handles GET /location-moves/consolidation?warehouse_id=
+func (h *LocationMoveHandler) Consolidation(c *gin.Context) {
+	var filter dto.ConsolidationFilterRequest
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	suggestions, err := h.service.Consolidation(&filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("REPORT_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(suggestions))
+}
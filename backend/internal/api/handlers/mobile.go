@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// MobileHandler handles the task-oriented API used by handheld devices
+type MobileHandler struct {
+	service service.MobileTaskService
+}
+
+func NewMobileHandler(service service.MobileTaskService) *MobileHandler {
+	return &MobileHandler{service: service}
+}
+
+// mobileError answers with the status a handheld can act on: 404 unknown task, 409 claimed by
+// someone else or a reused request ID, 422 wrong scan
+func mobileError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, service.ErrMobileTaskNotFound):
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", err.Error()))
+	case errors.Is(err, service.ErrMobileTaskClaimed):
+		c.JSON(http.StatusConflict, utils.ErrorResponse("TASK_CLAIMED", err.Error()))
+	case errors.Is(err, service.ErrMobileRequestReused):
+		c.JSON(http.StatusConflict, utils.ErrorResponse("REQUEST_REUSED", err.Error()))
+	case errors.Is(err, service.ErrScanMismatch):
+		c.JSON(http.StatusUnprocessableEntity, utils.ErrorResponse("SCAN_MISMATCH", err.Error()))
+	default:
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse(code, err.Error()))
+	}
+}
+
+// mobileTaskRef reads the task type and ID from the path
+func mobileTaskRef(c *gin.Context) (string, uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return "", 0, false
+	}
+	return c.Param("type"), uint(id), true
+}
+
+// ListTasks handles GET /mobile/tasks: the open tasks of the user and unassigned ones by default;
+// scope=mine|available|all narrows or widens the list
+func (h *MobileHandler) ListTasks(c *gin.Context) {
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+	q := repository.MobileTaskQuery{
+		Scope:  c.DefaultQuery("scope", repository.MobileScopeOpen),
+		UserID: userID,
+		Limit:  limit,
+	}
+	if v := c.Query("warehouse_id"); v != "" {
+		id, _ := strconv.ParseUint(v, 10, 32)
+		q.WarehouseID = uint(id)
+	}
+
+	tasks, err := h.service.ListTasks(c.Query("type"), q)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(tasks))
+}
+
+// GetTask handles GET /mobile/tasks/:type/:id
+func (h *MobileHandler) GetTask(c *gin.Context) {
+	taskType, id, ok := mobileTaskRef(c)
+	if !ok {
+		return
+	}
+	task, err := h.service.GetTask(taskType, id)
+	if err != nil {
+		mobileError(c, "GET_FAILED", err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(task))
+}
+
+// Claim handles POST /mobile/tasks/:type/:id/claim
+func (h *MobileHandler) Claim(c *gin.Context) {
+	taskType, id, ok := mobileTaskRef(c)
+	if !ok {
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	task, err := h.service.Claim(taskType, id, userID, usernameStr)
+	if err != nil {
+		mobileError(c, "CLAIM_ERROR", err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Task claimed", task))
+}
+
+// Release handles POST /mobile/tasks/:type/:id/release
+func (h *MobileHandler) Release(c *gin.Context) {
+	taskType, id, ok := mobileTaskRef(c)
+	if !ok {
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	task, err := h.service.Release(taskType, id, userID, usernameStr)
+	if err != nil {
+		mobileError(c, "RELEASE_ERROR", err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Task released", task))
+}
+
+// Assign handles POST /mobile/tasks/:type/:id/assign
+func (h *MobileHandler) Assign(c *gin.Context) {
+	taskType, id, ok := mobileTaskRef(c)
+	if !ok {
+		return
+	}
+	var req dto.AssignMobileTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	task, err := h.service.Assign(taskType, id, &req, userID, usernameStr)
+	if err != nil {
+		mobileError(c, "ASSIGN_ERROR", err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Task assigned", task))
+}
+
+// ConfirmStep handles POST /mobile/tasks/:type/:id/confirm. The request ID comes from the body
+// or the Idempotency-Key header; a retry with the same ID returns the first answer.
+func (h *MobileHandler) ConfirmStep(c *gin.Context) {
+	taskType, id, ok := mobileTaskRef(c)
+	if !ok {
+		return
+	}
+	var req dto.ConfirmMobileStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+	if req.RequestID == "" {
+		req.RequestID = c.GetHeader("Idempotency-Key")
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	result, err := h.service.ConfirmStep(taskType, id, &req, userID, usernameStr)
+	if err != nil {
+		mobileError(c, "CONFIRM_ERROR", err)
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// ReportException handles POST /mobile/tasks/:type/:id/exceptions
+func (h *MobileHandler) ReportException(c *gin.Context) {
+	taskType, id, ok := mobileTaskRef(c)
+	if !ok {
+		return
+	}
+	var req dto.ReportMobileExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	exception, err := h.service.ReportException(taskType, id, &req, userID, usernameStr)
+	if err != nil {
+		mobileError(c, "REPORT_ERROR", err)
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Exception reported", exception))
+}
+
+// ListExceptions handles GET /mobile/exceptions
+func (h *MobileHandler) ListExceptions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	filters := make(map[string]interface{})
+	if v := c.Query("status"); v != "" {
+		filters["status"] = v
+	}
+	if v := c.Query("task_type"); v != "" {
+		filters["task_type"] = v
+	}
+	if v := c.Query("warehouse_id"); v != "" {
+		id, _ := strconv.ParseUint(v, 10, 32)
+		filters["warehouse_id"] = uint(id)
+	}
+	if c.Query("mine") == "true" {
+		val, _ := c.Get("user_id")
+		filters["reported_by"] = uint(val.(int64))
+	}
+
+	exceptions, total, err := h.service.ListExceptions(filters, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       exceptions,
+		"pagination": utils.CalculatePagination(page, limit, total),
+	})
+}
+
+// ResolveException handles POST /mobile/exceptions/:id/resolve
+func (h *MobileHandler) ResolveException(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	var req dto.ResolveMobileExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	exception, err := h.service.ResolveException(uint(id), &req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("RESOLVE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Exception resolved", exception))
+}
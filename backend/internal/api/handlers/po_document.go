@@ -11,13 +11,14 @@ import (
 	"time"
 
 	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
 	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 const (
-	poUploadDir = "/app/uploads/po_documents"
+	poUploadDir = service.PODocumentDir
 )
 
 type PODocumentHandler struct {
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// POMailHandler handles e-mails to suppliers about purchase orders
+type POMailHandler struct {
+	service service.POMailService
+}
+
+func NewPOMailHandler(service service.POMailService) *POMailHandler {
+	return &POMailHandler{service: service}
+}
+
+// SendPurchaseOrder handles POST /purchase-orders/:id/email
+func (h *POMailHandler) SendPurchaseOrder(c *gin.Context) {
+	h.send(c, h.service.SendPurchaseOrder, "Purchase order e-mailed to supplier")
+}
+
+// SendDeliveryReminder handles POST /purchase-orders/:id/delivery-reminder
+func (h *POMailHandler) SendDeliveryReminder(c *gin.Context) {
+	h.send(c, h.service.SendDeliveryReminder, "Delivery reminder e-mailed to supplier")
+}
+
+func (h *POMailHandler) send(c *gin.Context, send func(uint, *dto.SendPOEmailRequest, uint, string) (*models.POEmailLog, error), message string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	var req dto.SendPOEmailRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+			return
+		}
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	entry, err := send(uint(id), &req, userID, usernameStr)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMailNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, utils.ErrorResponse("MAIL_NOT_CONFIGURED", err.Error()))
+		case entry != nil:
+			// Logged as failed: the SMTP server rejected or could not be reached
+			c.JSON(http.StatusBadGateway, utils.ErrorResponse("SEND_FAILED", err.Error()))
+		default:
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse("EMAIL_ERROR", err.Error()))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse(message, entry))
+}
+
+// ListByPurchaseOrder handles GET /purchase-orders/:id/emails
+func (h *POMailHandler) ListByPurchaseOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	logs, _, err := h.service.ListEmailLogs(map[string]interface{}{"purchase_order_id": uint(id)}, 0, 200)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("FETCH_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(logs))
+}
+
+// List handles GET /supplier-emails
+func (h *POMailHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	filters := make(map[string]interface{})
+	for _, key := range []string{"status", "message_type"} {
+		if v := c.Query(key); v != "" {
+			filters[key] = v
+		}
+	}
+	for _, key := range []string{"purchase_order_id", "supplier_id"} {
+		if v := c.Query(key); v != "" {
+			id, _ := strconv.ParseUint(v, 10, 32)
+			filters[key] = uint(id)
+		}
+	}
+
+	logs, total, err := h.service.ListEmailLogs(filters, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       logs,
+		"pagination": utils.CalculatePagination(page, limit, total),
+	})
+}
+
+// RunDeliveryReminders handles POST /supplier-emails/delivery-reminders/run
+func (h *POMailHandler) RunDeliveryReminders(c *gin.Context) {
+	var req dto.RunDeliveryRemindersRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+			return
+		}
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	result, err := h.service.RunDeliveryReminders(&req, userID, usernameStr)
+	if err != nil {
+		if errors.Is(err, service.ErrMailNotConfigured) {
+			c.JSON(http.StatusServiceUnavailable, utils.ErrorResponse("MAIL_NOT_CONFIGURED", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("REMINDER_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// PurchaseAgreementHandler handles HTTP requests for blanket purchase agreements
+type PurchaseAgreementHandler struct {
+	service   service.PurchaseAgreementService
+	poService service.PurchaseOrderService
+}
+
+func NewPurchaseAgreementHandler(service service.PurchaseAgreementService, poService service.PurchaseOrderService) *PurchaseAgreementHandler {
+	return &PurchaseAgreementHandler{service: service, poService: poService}
+}
+
+// Create handles POST /purchase-agreements
+func (h *PurchaseAgreementHandler) Create(c *gin.Context) {
+	var req dto.CreatePurchaseAgreementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	agreement, err := h.service.Create(&req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CREATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Purchase agreement created successfully", agreement))
+}
+
+// GetByID handles GET /purchase-agreements/:id (includes consumed vs remaining)
+func (h *PurchaseAgreementHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	agreement, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(agreement))
+}
+
+// List handles GET /purchase-agreements
+func (h *PurchaseAgreementHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	filters := make(map[string]interface{})
+	if search := c.Query("search"); search != "" {
+		filters["search"] = search
+	}
+	if status := c.Query("status"); status != "" {
+		filters["status"] = status
+	}
+	if supplierID := c.Query("supplier_id"); supplierID != "" {
+		id, _ := strconv.ParseUint(supplierID, 10, 32)
+		filters["supplier_id"] = uint(id)
+	}
+	if materialID := c.Query("material_id"); materialID != "" {
+		id, _ := strconv.ParseUint(materialID, 10, 32)
+		filters["material_id"] = uint(id)
+	}
+
+	agreements, total, err := h.service.List(filters, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       agreements,
+		"pagination": utils.CalculatePagination(page, limit, total),
+	})
+}
+
+// Update handles PUT /purchase-agreements/:id
+func (h *PurchaseAgreementHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	var req dto.UpdatePurchaseAgreementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	agreement, err := h.service.Update(uint(id), &req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("UPDATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Purchase agreement updated successfully", agreement))
+}
+
+// Activate handles POST /purchase-agreements/:id/activate
+func (h *PurchaseAgreementHandler) Activate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	agreement, err := h.service.Activate(uint(id), userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("ACTIVATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Purchase agreement activated", agreement))
+}
+
+// Close handles POST /purchase-agreements/:id/close
+func (h *PurchaseAgreementHandler) Close(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	agreement, err := h.service.Close(uint(id), userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CLOSE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Purchase agreement closed", agreement))
+}
+
+// Cancel handles POST /purchase-agreements/:id/cancel
+func (h *PurchaseAgreementHandler) Cancel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	if err := h.service.Cancel(uint(id), userID, usernameStr); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CANCEL_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Purchase agreement cancelled", nil))
+}
+
+// CreateCallOff handles POST /purchase-agreements/:id/call-offs
+// Creates a draft PO for the agreement's supplier at the agreed prices and returns
+// any warnings about exceeding the committed quantity or value.
+func (h *PurchaseAgreementHandler) CreateCallOff(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	var req dto.CreateCallOffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	agreement, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", err.Error()))
+		return
+	}
+
+	agreementID := agreement.ID
+	poReq := dto.CreatePurchaseOrderRequest{
+		PONumber:             req.PONumber,
+		SupplierID:           agreement.SupplierID,
+		Currency:             agreement.Currency,
+		WarehouseID:          req.WarehouseID,
+		OrderDate:            req.OrderDate,
+		ExpectedDeliveryDate: req.ExpectedDeliveryDate,
+		PaymentTerms:         agreement.PaymentTerms,
+		ShippingMethod:       req.ShippingMethod,
+		Notes:                req.Notes,
+		AssignedTo:           req.AssignedTo,
+		AgreementID:          &agreementID,
+		AllowOverCommitment:  req.AllowOverCommitment,
+	}
+	for _, item := range req.Items {
+		poReq.Items = append(poReq.Items, dto.CreatePurchaseOrderItemRequest{
+			MaterialID:           item.MaterialID,
+			Quantity:             item.Quantity,
+			Notes:                item.Notes,
+			ExpectedDeliveryDate: item.ExpectedDeliveryDate,
+		})
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	po, err := h.poService.CreatePurchaseOrder(&poReq, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CREATE_ERROR", err.Error()))
+		return
+	}
+
+	// Re-read consumption so the caller sees the commitment after this call-off
+	var warnings []string
+	if updated, err := h.service.GetByID(agreementID); err == nil {
+		warnings = updated.Warnings
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Call-off order created successfully", gin.H{
+		"purchase_order": po,
+		"warnings":       warnings,
+	}))
+}
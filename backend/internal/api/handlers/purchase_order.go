@@ -316,3 +316,32 @@ func (h *PurchaseOrderHandler) UpdateInvoiceStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.SuccessResponse(po))
 }
 
+
+// CloseShort closes PO lines that will not be delivered in full
+func (h *PurchaseOrderHandler) CloseShort(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid purchase order ID"))
+		return
+	}
+	var req dto.ClosePOShortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+	val, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse("UNAUTHORIZED", "User not authenticated"))
+		return
+	}
+	userID := val.(int64)
+	username, _ := c.Get("username")
+	userStr, _ := username.(string)
+	po, err := h.service.CloseShort(uint(id), &req, uint(userID), userStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CLOSE_SHORT_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(po))
+}
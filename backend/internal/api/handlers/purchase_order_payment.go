@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// PurchaseOrderPaymentHandler handles PO payments and the purchase currency report
+type PurchaseOrderPaymentHandler struct {
+	service service.PurchaseOrderPaymentService
+}
+
+func NewPurchaseOrderPaymentHandler(service service.PurchaseOrderPaymentService) *PurchaseOrderPaymentHandler {
+	return &PurchaseOrderPaymentHandler{service: service}
+}
+
+// RecordPayment handles POST /purchase-orders/:id/payments
+func (h *PurchaseOrderPaymentHandler) RecordPayment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid purchase order ID"))
+		return
+	}
+	var req dto.RecordPOPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	payment, err := h.service.RecordPayment(uint(id), &req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("PAYMENT_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Payment recorded successfully", payment))
+}
+
+// ListPayments handles GET /purchase-orders/:id/payments
+func (h *PurchaseOrderPaymentHandler) ListPayments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid purchase order ID"))
+		return
+	}
+	payments, err := h.service.ListPayments(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("FETCH_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(payments))
+}
+
+// GetCurrencyReport handles GET /reports/purchase-currency?from=&to=&currency=
+func (h *PurchaseOrderPaymentHandler) GetCurrencyReport(c *gin.Context) {
+	rows, err := h.service.GetCurrencyReport(c.Query("from"), c.Query("to"), c.Query("currency"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("REPORT_ERROR", "Failed to generate purchase currency report: "+err.Error()))
+		return
+	}
+
+	if c.Query("export") == "csv" {
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment;filename=purchase_currency.csv")
+		csv := "PO Number,Supplier Code,Supplier Name,Order Date,Currency,Order Rate,Total Amount,Total (VND),Received Amount,Received (VND),Paid Amount,Paid (VND),Realized FX (VND)\n"
+		for _, r := range rows {
+			csv += r.PONumber + "," + r.SupplierCode + "," + r.SupplierName + "," + r.OrderDate + "," + r.Currency + "," +
+				utils.FloatToString(r.OrderRate) + "," + utils.FloatToString(r.TotalAmount) + "," + utils.FloatToString(r.TotalBaseAmount) + "," +
+				utils.FloatToString(r.ReceivedAmount) + "," + utils.FloatToString(r.ReceivedBase) + "," +
+				utils.FloatToString(r.PaidAmount) + "," + utils.FloatToString(r.PaidBase) + "," +
+				utils.FloatToString(r.RealizedFXAmount) + "\n"
+		}
+		c.String(http.StatusOK, csv)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(rows))
+}
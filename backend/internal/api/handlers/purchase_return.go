@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// PurchaseReturnHandler handles HTTP requests for returns to supplier
+type PurchaseReturnHandler struct {
+	service service.PurchaseReturnService
+}
+
+func NewPurchaseReturnHandler(service service.PurchaseReturnService) *PurchaseReturnHandler {
+	return &PurchaseReturnHandler{service: service}
+}
+
+// Create handles POST /purchase-returns
+func (h *PurchaseReturnHandler) Create(c *gin.Context) {
+	var req dto.CreatePurchaseReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	ret, err := h.service.Create(&req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CREATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Purchase return created successfully", ret))
+}
+
+// GetByID handles GET /purchase-returns/:id
+func (h *PurchaseReturnHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	ret, err := h.service.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(ret))
+}
+
+// List handles GET /purchase-returns
+func (h *PurchaseReturnHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	filters := make(map[string]interface{})
+	for _, key := range []string{"search", "status", "source", "replacement_status", "date_from", "date_to"} {
+		if v := c.Query(key); v != "" {
+			filters[key] = v
+		}
+	}
+	if supplierID := c.Query("supplier_id"); supplierID != "" {
+		id, _ := strconv.ParseUint(supplierID, 10, 32)
+		filters["supplier_id"] = uint(id)
+	}
+	if poID := c.Query("purchase_order_id"); poID != "" {
+		id, _ := strconv.ParseUint(poID, 10, 32)
+		filters["purchase_order_id"] = uint(id)
+	}
+
+	returns, total, err := h.service.List(filters, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("LIST_FAILED", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       returns,
+		"pagination": utils.CalculatePagination(page, limit, total),
+	})
+}
+
+// Post handles POST /purchase-returns/:id/post
+// Issues the returned stock and raises the debit note for credited lines.
+func (h *PurchaseReturnHandler) Post(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	ret, err := h.service.Post(uint(id), userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("POST_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Purchase return posted", ret))
+}
+
+// Cancel handles POST /purchase-returns/:id/cancel
+func (h *PurchaseReturnHandler) Cancel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	ret, err := h.service.Cancel(uint(id), userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CANCEL_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Purchase return cancelled", ret))
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ReplenishmentHandler handles reorder-point replenishment proposals
+type ReplenishmentHandler struct {
+	service service.ReplenishmentService
+}
+
+func NewReplenishmentHandler(service service.ReplenishmentService) *ReplenishmentHandler {
+	return &ReplenishmentHandler{service: service}
+}
+
+// Preview handles GET /replenishment/proposals?warehouse_id=1&material_ids=2&material_ids=3
+func (h *ReplenishmentHandler) Preview(c *gin.Context) {
+	var req dto.ReplenishmentRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_QUERY", err.Error()))
+		return
+	}
+	plan, err := h.service.Preview(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("REPLENISHMENT_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(plan))
+}
+
+// Run handles POST /replenishment/run
+// Creates one draft PO per preferred supplier for the buyer to review.
+func (h *ReplenishmentHandler) Run(c *gin.Context) {
+	var req dto.ReplenishmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	username, _ := c.Get("username")
+	usernameStr, _ := username.(string)
+
+	result, err := h.service.Run(&req, userID, usernameStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("REPLENISHMENT_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Replenishment draft purchase orders created", result))
+}
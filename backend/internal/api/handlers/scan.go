@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ScanHandler handles HTTP requests for barcode scanning
+type ScanHandler struct {
+	service service.ScanService
+}
+
+func NewScanHandler(service service.ScanService) *ScanHandler {
+	return &ScanHandler{service: service}
+}
+
+// Resolve handles POST /scan/resolve
+func (h *ScanHandler) Resolve(c *gin.Context) {
+	var req dto.ScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+
+	result, err := h.service.Resolve(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrScanNotRecognised) {
+			c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_RECOGNISED", err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("SCAN_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
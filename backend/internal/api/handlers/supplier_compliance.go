@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierComplianceHandler handles supplier document compliance checks and rules
+type SupplierComplianceHandler struct {
+	service service.SupplierComplianceService
+}
+
+func NewSupplierComplianceHandler(service service.SupplierComplianceService) *SupplierComplianceHandler {
+	return &SupplierComplianceHandler{service: service}
+}
+
+// Check handles GET /suppliers/:id/compliance?material_ids=1,2&stage=po_approval
+func (h *SupplierComplianceHandler) Check(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid supplier ID"))
+		return
+	}
+
+	var materialIDs []uint
+	if raw := c.Query("material_ids"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			mID, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_QUERY", "Invalid material_ids"))
+				return
+			}
+			materialIDs = append(materialIDs, uint(mID))
+		}
+	}
+
+	issues, err := h.service.Check(uint(id), materialIDs, c.Query("stage"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("COMPLIANCE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(gin.H{
+		"compliant": len(issues) == 0,
+		"issues":    issues,
+	}))
+}
+
+// ListRequirements handles GET /supplier-document-requirements
+func (h *SupplierComplianceHandler) ListRequirements(c *gin.Context) {
+	rules, err := h.service.ListRequirements()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("FETCH_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(rules))
+}
+
+// CreateRequirement handles POST /supplier-document-requirements
+func (h *SupplierComplianceHandler) CreateRequirement(c *gin.Context) {
+	var rule models.SupplierDocumentRequirement
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	rule.ID = 0
+	rule.CreatedBy = &userID
+	rule.UpdatedBy = &userID
+
+	if err := h.service.CreateRequirement(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("CREATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, utils.SuccessMessageResponse("Requirement created successfully", rule))
+}
+
+// UpdateRequirement handles PUT /supplier-document-requirements/:id
+func (h *SupplierComplianceHandler) UpdateRequirement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	var rule models.SupplierDocumentRequirement
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+	val, _ := c.Get("user_id")
+	userID := uint(val.(int64))
+	rule.UpdatedBy = &userID
+
+	updated, err := h.service.UpdateRequirement(uint(id), &rule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("UPDATE_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Requirement updated successfully", updated))
+}
+
+// DeleteRequirement handles DELETE /supplier-document-requirements/:id
+func (h *SupplierComplianceHandler) DeleteRequirement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid ID format"))
+		return
+	}
+	if err := h.service.DeleteRequirement(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessMessageResponse("Requirement deleted", nil))
+}
@@ -10,7 +10,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
 	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
 	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -71,6 +73,13 @@ func (h *SupplierDocumentHandler) Upload(c *gin.Context) {
 		return
 	}
 
+	// Document type and validity (optional, defaults to "other")
+	meta, err := parseSupplierDocumentMeta(c.PostForm)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_METADATA", err.Error()))
+		return
+	}
+
 	file, header, err := c.Request.FormFile("file")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse("NO_FILE", "No file provided"))
@@ -169,6 +178,7 @@ func (h *SupplierDocumentHandler) Upload(c *gin.Context) {
 		MimeType:     mimeType,
 		UploadedBy:   uploadedBy,
 	}
+	meta.applyTo(&doc)
 
 	if err := h.db.Create(&doc).Error; err != nil {
 		os.Remove(destPath)
@@ -179,6 +189,43 @@ func (h *SupplierDocumentHandler) Upload(c *gin.Context) {
 	c.JSON(http.StatusCreated, utils.SuccessResponse(doc))
 }
 
+// UpdateMeta updates the type, number and validity dates of an uploaded document
+func (h *SupplierDocumentHandler) UpdateMeta(c *gin.Context) {
+	supplierID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid supplier ID"))
+		return
+	}
+	docID, err := strconv.ParseUint(c.Param("docId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid document ID"))
+		return
+	}
+
+	var doc models.SupplierDocument
+	if err := h.db.Where("id = ? AND supplier_id = ?", docID, supplierID).First(&doc).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse("NOT_FOUND", "Document not found"))
+		return
+	}
+
+	var req dto.UpdateSupplierDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_REQUEST", err.Error()))
+		return
+	}
+	if err := applySupplierDocumentUpdate(&doc, &req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_METADATA", err.Error()))
+		return
+	}
+
+	if err := h.db.Save(&doc).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse("DB_ERROR", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(doc))
+}
+
 // Delete removes a document
 func (h *SupplierDocumentHandler) Delete(c *gin.Context) {
 	supplierID, err := strconv.ParseUint(c.Param("id"), 10, 64)
@@ -239,3 +286,122 @@ func sanitizeFilename(name string) string {
 	}
 	return result
 }
+
+// supplierDocumentMeta holds the typed-document fields sent alongside an upload
+type supplierDocumentMeta struct {
+	documentType   string
+	documentNumber *string
+	materialID     *uint
+	issueDate      *string
+	expiryDate     *string
+	notes          *string
+}
+
+// parseSupplierDocumentMeta reads document_type, document_number, material_id,
+// issue_date, expiry_date and notes using the given lookup (form or JSON body)
+func parseSupplierDocumentMeta(get func(string) string) (*supplierDocumentMeta, error) {
+	meta := &supplierDocumentMeta{documentType: models.SupplierDocOther}
+
+	if t := strings.TrimSpace(get("document_type")); t != "" {
+		if !service.IsValidSupplierDocumentType(t) {
+			return nil, fmt.Errorf("invalid document_type %q", t)
+		}
+		meta.documentType = t
+	}
+	if v := strings.TrimSpace(get("document_number")); v != "" {
+		meta.documentNumber = &v
+	}
+	if v := strings.TrimSpace(get("material_id")); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid material_id")
+		}
+		mID := uint(id)
+		meta.materialID = &mID
+	}
+	for _, f := range []struct {
+		key string
+		dst **string
+	}{{"issue_date", &meta.issueDate}, {"expiry_date", &meta.expiryDate}} {
+		if v := strings.TrimSpace(get(f.key)); v != "" {
+			if _, err := time.Parse("2006-01-02", v); err != nil {
+				return nil, fmt.Errorf("invalid %s, use YYYY-MM-DD", f.key)
+			}
+			*f.dst = &v
+		}
+	}
+	if meta.issueDate != nil && meta.expiryDate != nil && *meta.expiryDate < *meta.issueDate {
+		return nil, fmt.Errorf("expiry_date must be after issue_date")
+	}
+	if v := strings.TrimSpace(get("notes")); v != "" {
+		meta.notes = &v
+	}
+	return meta, nil
+}
+
+func (m *supplierDocumentMeta) applyTo(doc *models.SupplierDocument) {
+	doc.DocumentType = m.documentType
+	doc.DocumentNumber = m.documentNumber
+	doc.MaterialID = m.materialID
+	doc.IssueDate = m.issueDate
+	doc.ExpiryDate = m.expiryDate
+	doc.Notes = m.notes
+}
+
+// applySupplierDocumentUpdate applies the fields sent in an update to a document, leaving the others as they are
+func applySupplierDocumentUpdate(doc *models.SupplierDocument, req *dto.UpdateSupplierDocumentRequest) error {
+	optional := func(v *string) *string {
+		if t := strings.TrimSpace(*v); t != "" {
+			return &t
+		}
+		return nil
+	}
+
+	if req.DocumentType != nil {
+		t := strings.TrimSpace(*req.DocumentType)
+		if !service.IsValidSupplierDocumentType(t) {
+			return fmt.Errorf("invalid document_type %q", t)
+		}
+		doc.DocumentType = t
+	}
+	if req.DocumentNumber != nil {
+		doc.DocumentNumber = optional(req.DocumentNumber)
+	}
+	if req.MaterialID != nil {
+		doc.MaterialID = nil
+		if *req.MaterialID > 0 {
+			id := *req.MaterialID
+			doc.MaterialID = &id
+		}
+	}
+	for _, f := range []struct {
+		key string
+		src *string
+		dst **string
+	}{{"issue_date", req.IssueDate, &doc.IssueDate}, {"expiry_date", req.ExpiryDate, &doc.ExpiryDate}} {
+		if f.src == nil {
+			continue
+		}
+		v := optional(f.src)
+		if v != nil {
+			if _, err := time.Parse("2006-01-02", *v); err != nil {
+				return fmt.Errorf("invalid %s, use YYYY-MM-DD", f.key)
+			}
+		}
+		*f.dst = v
+	}
+	// dates read back from the database carry a time part
+	day := func(s string) string {
+		if len(s) > 10 {
+			return s[:10]
+		}
+		return s
+	}
+	if doc.IssueDate != nil && doc.ExpiryDate != nil && day(*doc.ExpiryDate) < day(*doc.IssueDate) {
+		return fmt.Errorf("expiry_date must be after issue_date")
+	}
+	if req.Notes != nil {
+		doc.Notes = optional(req.Notes)
+	}
+	return nil
+}
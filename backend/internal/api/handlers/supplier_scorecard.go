@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/service"
+	"github.com/VyVy-ERP/warehouse-backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierScorecardHandler exposes supplier performance scorecards
+type SupplierScorecardHandler struct {
+	service service.SupplierScorecardService
+}
+
+func NewSupplierScorecardHandler(service service.SupplierScorecardService) *SupplierScorecardHandler {
+	return &SupplierScorecardHandler{service: service}
+}
+
+// GetScorecard handles GET /suppliers/:id/scorecard?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *SupplierScorecardHandler) GetScorecard(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid supplier ID"))
+		return
+	}
+
+	card, err := h.service.GetScorecard(uint(id), c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("SCORECARD_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(card))
+}
+
+// GetRanking handles GET /reports/supplier-ranking
+func (h *SupplierScorecardHandler) GetRanking(c *gin.Context) {
+	rows, err := h.service.GetRanking(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("REPORT_ERROR", "Failed to generate supplier ranking: "+err.Error()))
+		return
+	}
+
+	if c.Query("export") == "csv" {
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment;filename=supplier_ranking.csv")
+		// supplier names may hold commas and quotes; let the csv writer escape them
+		w := csv.NewWriter(c.Writer)
+		_ = w.Write([]string{"Rank", "Supplier Code", "Supplier Name", "On-time Rate", "Fill Rate", "Rejection Rate", "Price Variance", "Score", "Grade"})
+		for _, r := range rows {
+			_ = w.Write([]string{
+				strconv.Itoa(r.Rank), r.SupplierCode, r.SupplierName,
+				ratioToString(r.OnTimeRate), ratioToString(r.FillRate),
+				ratioToString(r.RejectionRate), ratioToString(r.PriceVariance),
+				utils.FloatToString(r.Score), r.Grade,
+			})
+		}
+		w.Flush()
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.SuccessResponse(rows))
+}
+
+// SuggestPriorities handles GET /materials/:id/supplier-priority-suggestions
+func (h *SupplierScorecardHandler) SuggestPriorities(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("INVALID_ID", "Invalid material ID"))
+		return
+	}
+
+	suggestions, err := h.service.SuggestPriorities(id, c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse("SCORECARD_ERROR", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, utils.SuccessResponse(suggestions))
+}
+
+// ratioToString renders an optional KPI ratio for CSV export (empty when no data)
+func ratioToString(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return utils.FloatToString(*v)
+}
@@ -1,9 +1,14 @@
 package routes
 
 import (
+	"log"
+	"time"
+
 	"github.com/VyVy-ERP/warehouse-backend/internal/api/handlers"
 	"github.com/VyVy-ERP/warehouse-backend/internal/api/middleware"
 	"github.com/VyVy-ERP/warehouse-backend/internal/config"
+	"github.com/VyVy-ERP/warehouse-backend/internal/document"
+	"github.com/VyVy-ERP/warehouse-backend/internal/mail"
 	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
 	"github.com/VyVy-ERP/warehouse-backend/internal/service"
 	"github.com/gin-gonic/gin"
@@ -41,6 +46,26 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 	auditLogRepo := repository.NewAuditLogRepository(db)
 	productionTaskRepo := repository.NewProductionTaskRepository(db)
 	fprnRepo := repository.NewFinishedProductReceiptRepository(db)
+	landedCostRepo := repository.NewLandedCostRepository(db)
+	purchaseAgreementRepo := repository.NewPurchaseAgreementRepository(db)
+	exchangeRateRepo := repository.NewExchangeRateRepository(db)
+	poPaymentRepo := repository.NewPurchaseOrderPaymentRepository(db)
+	purchaseReturnRepo := repository.NewPurchaseReturnRepository(db)
+	replenishmentRepo := repository.NewReplenishmentRepository(db)
+	qcInspectionRepo := repository.NewQCInspectionRepository(db)
+	poEmailLogRepo := repository.NewPOEmailLogRepository(db)
+	putawayTaskRepo := repository.NewPutawayTaskRepository(db)
+	warehouseZoneRepo := repository.NewWarehouseZoneRepository(db)
+	pickListRepo := repository.NewPickListRepository(db)
+	pickWaveRepo := repository.NewPickWaveRepository(db)
+	cycleCountRepo := repository.NewCycleCountRepository(db)
+	physicalInventoryRepo := repository.NewPhysicalInventoryRepository(db)
+	scanRepo := repository.NewScanRepository(db)
+	labelRepo := repository.NewLabelRepository(db)
+	mobileTaskRepo := repository.NewMobileTaskRepository(db)
+	locationMoveRepo := repository.NewLocationMoveRepository(db)
+	kitRepo := repository.NewKitRepository(db)
+	serialRepo := repository.NewSerialRepository(db)
 
 	// Initialize services
 	authService := service.NewAuthService(userRepo, cfg)
@@ -48,16 +73,56 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 	materialService := service.NewMaterialService(materialRepo, materialSupplierRepo, auditLogService)
 	supplierService := service.NewSupplierService(supplierRepo, auditLogService)
 	warehouseService := service.NewWarehouseService(warehouseRepo, warehouseLocationRepo, auditLogService)
-	warehouseLocationService := service.NewWarehouseLocationService(warehouseLocationRepo, warehouseRepo)
+	warehouseLocationService := service.NewWarehouseLocationService(warehouseLocationRepo, warehouseRepo, warehouseZoneRepo)
 	finishedProductService := service.NewFinishedProductService(finishedProductRepo, auditLogService)
 	productFormulaService := service.NewProductFormulaService(productFormulaRepo, finishedProductRepo, materialRepo)
-	purchaseOrderService := service.NewPurchaseOrderService(purchaseOrderRepo, purchaseOrderItemRepo, supplierRepo, warehouseRepo, ppRepo, db, auditLogService)
-	grnService := service.NewGRNService(db, grnRepo, grnItemRepo, purchaseOrderRepo, purchaseOrderItemRepo, warehouseRepo, stockLedgerRepo, stockBalanceRepo, ppRepo, auditLogService)
+	supplierComplianceService := service.NewSupplierComplianceService(db)
+	exchangeRateService := service.NewExchangeRateService(exchangeRateRepo, auditLogService)
+	purchaseAgreementService := service.NewPurchaseAgreementService(purchaseAgreementRepo, supplierRepo, materialRepo, auditLogService)
+	documentFonts, err := document.LoadFontSet(cfg.Documents.FontPath, cfg.Documents.FontBoldPath)
+	if err != nil {
+		log.Printf("Warning: %v; PDFs will use built-in fonts", err)
+	}
+	documentService := service.NewDocumentService(db, purchaseOrderRepo, grnRepo, minRepo, doRepo, stRepo, cfg.Company, cfg.Documents.DefaultLanguage, documentFonts)
+	var poDocumentService service.DocumentService
+	if cfg.Documents.AttachPOOnApproval {
+		poDocumentService = documentService
+	}
+	var mailSender mail.Sender
+	if sc := cfg.SMTP; sc.Host != "" && sc.From != "" {
+		sender, err := mail.NewSMTPSender(mail.Config{Host: sc.Host, Port: sc.Port, Username: sc.User, Password: sc.Password, From: sc.From, FromName: sc.FromName, TLS: sc.TLS})
+		if err != nil {
+			log.Printf("Warning: %v; supplier e-mail is disabled", err)
+		} else {
+			mailSender = sender
+		}
+	}
+	poMailService := service.NewPOMailService(purchaseOrderRepo, poEmailLogRepo, documentService, auditLogService, mailSender, cfg.Company, cfg.Documents.DefaultLanguage, cfg.POMail.ReminderDays)
+	var poApprovalMailService service.POMailService
+	if cfg.POMail.SendOnApproval {
+		poApprovalMailService = poMailService
+	}
+	purchaseOrderService := service.NewPurchaseOrderService(purchaseOrderRepo, purchaseOrderItemRepo, supplierRepo, warehouseRepo, ppRepo, db, auditLogService, supplierComplianceService, purchaseAgreementService, exchangeRateService, poDocumentService, poApprovalMailService)
+	poPaymentService := service.NewPurchaseOrderPaymentService(db, purchaseOrderRepo, poPaymentRepo, exchangeRateService, auditLogService)
+	putawayService := service.NewPutawayService(db, putawayTaskRepo, auditLogService)
+	warehouseZoneService := service.NewWarehouseZoneService(db, warehouseZoneRepo, warehouseRepo, auditLogService)
+	grnService := service.NewGRNService(db, grnRepo, grnItemRepo, purchaseOrderRepo, purchaseOrderItemRepo, warehouseRepo, stockLedgerRepo, stockBalanceRepo, ppRepo, auditLogService, supplierComplianceService, exchangeRateService, putawayService)
 	ppService := service.NewProductionPlanService(db, ppRepo, ppItemRepo, warehouseRepo, materialRepo, stockBalanceRepo, stockReservationRepo, auditLogService)
 	minService := service.NewMaterialIssueNoteService(minRepo, ppRepo, materialRepo, stockBalanceRepo, stockReservationRepo, db)
 	stockService := service.NewStockService(stockBalanceRepo)
 	doService := service.NewDeliveryOrderService(db, doRepo, warehouseRepo, finishedProductRepo, stockBalanceRepo, stockReservationRepo)
+	pickListService := service.NewPickListService(db, pickListRepo, auditLogService)
+	pickWaveService := service.NewPickWaveService(db, pickWaveRepo, doService, auditLogService)
 	saService := service.NewStockAdjustmentService(db, saRepo, warehouseRepo, materialRepo, finishedProductRepo, stockBalanceRepo)
+	cycleCountService := service.NewCycleCountService(db, cycleCountRepo, auditLogService)
+	physicalInventoryService := service.NewPhysicalInventoryService(db, physicalInventoryRepo, auditLogService)
+	scanService := service.NewScanService(scanRepo)
+	labelService := service.NewLabelService(db, labelRepo, auditLogService, cfg.Labels, cfg.Documents.DefaultLanguage, documentFonts)
+	mobileTaskService := service.NewMobileTaskService(db, mobileTaskRepo, scanService, auditLogService)
+	locationMoveService := service.NewLocationMoveService(db, locationMoveRepo, scanService, auditLogService)
+	kitService := service.NewKitService(db, kitRepo, auditLogService)
+	serialService := service.NewSerialService(serialRepo)
+	stockReservationService := service.NewStockReservationService(db, stockReservationRepo, auditLogService)
 	stService := service.NewStockTransferService(db, stRepo, warehouseRepo, stockBalanceRepo)
 	dashboardService := service.NewDashboardService(db)
 	reportService := service.NewReportService(db)
@@ -67,7 +132,12 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 	reconService := service.NewReconciliationService(reconRepo, carrierRepo)
 	roService := service.NewReturnOrderService(db, roRepo, doRepo)
 	productionTaskService := service.NewProductionTaskService(productionTaskRepo)
-	fprnService := service.NewFinishedProductReceiptService(fprnRepo, stockLedgerRepo, stockBalanceRepo, ppRepo, db)
+	fprnService := service.NewFinishedProductReceiptService(fprnRepo, stockLedgerRepo, stockBalanceRepo, ppRepo, db, putawayService)
+	landedCostService := service.NewLandedCostService(db, landedCostRepo, grnRepo, auditLogService)
+	supplierScorecardService := service.NewSupplierScorecardService(db)
+	replenishmentService := service.NewReplenishmentService(replenishmentRepo, warehouseRepo, purchaseOrderService, auditLogService)
+	purchaseReturnService := service.NewPurchaseReturnService(db, purchaseReturnRepo, grnRepo, grnItemRepo, purchaseOrderRepo, supplierRepo, warehouseRepo, stockBalanceRepo, auditLogService, exchangeRateService)
+	qcInspectionService := service.NewQCInspectionService(db, qcInspectionRepo, grnRepo, grnItemRepo, materialRepo, auditLogService)
 
 	supplierDocHandler := handlers.NewSupplierDocumentHandler(db)
 	poDocHandler := handlers.NewPODocumentHandler(db)
@@ -97,6 +167,45 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 	roHandler := handlers.NewReturnOrderHandler(roService)
 	productionTaskHandler := handlers.NewProductionTaskHandler(productionTaskService)
 	fprnHandler := handlers.NewFinishedProductReceiptHandler(fprnService)
+	landedCostHandler := handlers.NewLandedCostHandler(landedCostService)
+	purchaseAgreementHandler := handlers.NewPurchaseAgreementHandler(purchaseAgreementService, purchaseOrderService)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateService)
+	poPaymentHandler := handlers.NewPurchaseOrderPaymentHandler(poPaymentService)
+	supplierScorecardHandler := handlers.NewSupplierScorecardHandler(supplierScorecardService)
+	supplierComplianceHandler := handlers.NewSupplierComplianceHandler(supplierComplianceService)
+	purchaseReturnHandler := handlers.NewPurchaseReturnHandler(purchaseReturnService)
+	replenishmentHandler := handlers.NewReplenishmentHandler(replenishmentService)
+	qcInspectionHandler := handlers.NewQCInspectionHandler(qcInspectionService)
+	documentHandler := handlers.NewDocumentHandler(documentService)
+	poMailHandler := handlers.NewPOMailHandler(poMailService)
+	putawayHandler := handlers.NewPutawayHandler(putawayService)
+	warehouseZoneHandler := handlers.NewWarehouseZoneHandler(warehouseZoneService)
+	pickListHandler := handlers.NewPickListHandler(pickListService)
+	pickWaveHandler := handlers.NewPickWaveHandler(pickWaveService)
+	cycleCountHandler := handlers.NewCycleCountHandler(cycleCountService)
+	physicalInventoryHandler := handlers.NewPhysicalInventoryHandler(physicalInventoryService)
+	scanHandler := handlers.NewScanHandler(scanService)
+	labelHandler := handlers.NewLabelHandler(labelService)
+	mobileHandler := handlers.NewMobileHandler(mobileTaskService)
+	locationMoveHandler := handlers.NewLocationMoveHandler(locationMoveService)
+	kitHandler := handlers.NewKitHandler(kitService)
+	serialHandler := handlers.NewSerialHandler(serialService)
+	stockReservationHandler := handlers.NewStockReservationHandler(stockReservationService)
+
+	// Scheduled reorder-point replenishment (disabled unless configured)
+	if rc := cfg.Replenishment; rc.IntervalHours > 0 && rc.WarehouseID > 0 && rc.UserID > 0 {
+		service.StartReplenishmentScheduler(replenishmentService, time.Duration(rc.IntervalHours)*time.Hour, uint(rc.WarehouseID), uint(rc.UserID))
+	}
+
+	// Scheduled supplier delivery reminders (disabled unless configured)
+	if pm := cfg.POMail; pm.ReminderIntervalHours > 0 && mailSender != nil {
+		service.StartPOReminderScheduler(poMailService, time.Duration(pm.ReminderIntervalHours)*time.Hour)
+	}
+
+	// Scheduled ABC classification and cycle count tasks (disabled unless configured)
+	if cc := cfg.CycleCount; cc.IntervalHours > 0 && cc.UserID > 0 {
+		service.StartCycleCountScheduler(cycleCountService, time.Duration(cc.IntervalHours)*time.Hour, uint(cc.UserID))
+	}
 
 	// API v1 group
 	v1 := router.Group("/api/v1")
@@ -122,6 +231,8 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 		materialGroup.POST("", materialHandler.Create)
 		materialGroup.PUT("/:id", materialHandler.Update)
 		materialGroup.DELETE("/:id", middleware.RequireRole("admin"), materialHandler.Delete)
+		// Supplier priority suggestions from scorecards
+		materialGroup.GET("/:id/supplier-priority-suggestions", supplierScorecardHandler.SuggestPriorities)
 	}
 
 	// Supplier routes - All protected
@@ -136,7 +247,22 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 		// Documents
 		supplierGroup.GET("/:id/documents", supplierDocHandler.List)
 		supplierGroup.POST("/:id/documents", supplierDocHandler.Upload)
+		supplierGroup.PUT("/:id/documents/:docId", supplierDocHandler.UpdateMeta)
 		supplierGroup.DELETE("/:id/documents/:docId", supplierDocHandler.Delete)
+		// Performance scorecard
+		supplierGroup.GET("/:id/scorecard", supplierScorecardHandler.GetScorecard)
+		// Document compliance (mandatory COA/MSDS/GMP/... present and not expired)
+		supplierGroup.GET("/:id/compliance", supplierComplianceHandler.Check)
+	}
+
+	// Supplier document requirement rules - which documents block PO approval / GRN posting
+	docRuleGroup := v1.Group("/supplier-document-requirements")
+	docRuleGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		docRuleGroup.GET("", supplierComplianceHandler.ListRequirements)
+		docRuleGroup.POST("", middleware.RequireRole("procurement_manager"), supplierComplianceHandler.CreateRequirement)
+		docRuleGroup.PUT("/:id", middleware.RequireRole("procurement_manager"), supplierComplianceHandler.UpdateRequirement)
+		docRuleGroup.DELETE("/:id", middleware.RequireRole("admin"), supplierComplianceHandler.DeleteRequirement)
 	}
 
 	// Warehouse routes - All protected
@@ -146,6 +272,7 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 		warehouseGroup.GET("", warehouseHandler.List)
 		warehouseGroup.GET("/:id", warehouseHandler.GetByID)
 		warehouseGroup.GET("/:id/locations", warehouseHandler.GetLocations)
+		warehouseGroup.GET("/:id/utilization", warehouseZoneHandler.Utilization)
 
 		// Admin/Warehouse Admin roles
 		warehouseGroup.POST("", middleware.RequireRole("warehouse_admin"), warehouseHandler.Create)
@@ -165,6 +292,18 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 		locationGroup.DELETE("/:id", middleware.RequireRole("admin"), warehouseLocationHandler.Delete)
 	}
 
+	// Warehouse zones - storage conditions shared by their locations
+	zoneGroup := v1.Group("/warehouse-zones")
+	zoneGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		zoneGroup.GET("", warehouseZoneHandler.List)
+		zoneGroup.GET("/:id", warehouseZoneHandler.Get)
+
+		zoneGroup.POST("", middleware.RequireRole("warehouse_admin"), warehouseZoneHandler.Create)
+		zoneGroup.PUT("/:id", middleware.RequireRole("warehouse_admin"), warehouseZoneHandler.Update)
+		zoneGroup.DELETE("/:id", middleware.RequireRole("admin"), warehouseZoneHandler.Delete)
+	}
+
 	// Finished Products routes - All protected
 	productGroup := v1.Group("/finished-products")
 	productGroup.Use(middleware.AuthMiddleware(authService))
@@ -200,12 +339,158 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 		poGroup.PUT("/:id/order-status", purchaseOrderHandler.UpdateOrderStatus)
 		poGroup.PUT("/:id/payment-status", purchaseOrderHandler.UpdatePaymentStatus)
 		poGroup.PUT("/:id/invoice-status", purchaseOrderHandler.UpdateInvoiceStatus)
+		poGroup.POST("/:id/close-short", middleware.RequireRole("procurement_manager"), purchaseOrderHandler.CloseShort)
+		poGroup.GET("/:id/pdf", documentHandler.PurchaseOrderPDF)
+		// Supplier e-mail (PO with PDF, delivery reminders) and its log
+		poGroup.GET("/:id/emails", poMailHandler.ListByPurchaseOrder)
+		poGroup.POST("/:id/email", middleware.RequireRole("procurement_manager"), poMailHandler.SendPurchaseOrder)
+		poGroup.POST("/:id/delivery-reminder", middleware.RequireRole("procurement_manager"), poMailHandler.SendDeliveryReminder)
+		// Payments (document currency, realized FX against GRN rates)
+		poGroup.GET("/:id/payments", poPaymentHandler.ListPayments)
+		poGroup.POST("/:id/payments", middleware.RequireRole("procurement_manager"), poPaymentHandler.RecordPayment)
 		// Documents
 		poGroup.GET("/:id/documents", poDocHandler.List)
 		poGroup.POST("/:id/documents", poDocHandler.Upload)
 		poGroup.DELETE("/:id/documents/:docId", poDocHandler.Delete)
 	}
 
+	// Supplier e-mail log and delivery reminder runs
+	supplierEmailGroup := v1.Group("/supplier-emails")
+	supplierEmailGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		supplierEmailGroup.GET("", poMailHandler.List)
+		supplierEmailGroup.POST("/delivery-reminders/run", middleware.RequireRole("procurement_manager"), poMailHandler.RunDeliveryReminders)
+	}
+
+	// Exchange rates (VND per unit of foreign currency)
+	fxGroup := v1.Group("/exchange-rates")
+	fxGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		fxGroup.GET("", exchangeRateHandler.List)
+		fxGroup.GET("/effective", exchangeRateHandler.GetRate)
+		fxGroup.POST("", middleware.RequireRole("procurement_manager"), exchangeRateHandler.Upsert)
+		fxGroup.POST("/import", middleware.RequireRole("procurement_manager"), exchangeRateHandler.Import)
+		fxGroup.DELETE("/:id", middleware.RequireRole("admin"), exchangeRateHandler.Delete)
+	}
+
+	// Blanket purchase agreements - call-off POs draw down committed quantities
+	agreementGroup := v1.Group("/purchase-agreements")
+	agreementGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		agreementGroup.GET("", purchaseAgreementHandler.List)
+		agreementGroup.GET("/:id", purchaseAgreementHandler.GetByID)
+		agreementGroup.POST("", purchaseAgreementHandler.Create)
+		agreementGroup.PUT("/:id", purchaseAgreementHandler.Update)
+		agreementGroup.POST("/:id/activate", middleware.RequireRole("procurement_manager"), purchaseAgreementHandler.Activate)
+		agreementGroup.POST("/:id/close", middleware.RequireRole("procurement_manager"), purchaseAgreementHandler.Close)
+		agreementGroup.POST("/:id/cancel", middleware.RequireRole("procurement_manager"), purchaseAgreementHandler.Cancel)
+		agreementGroup.POST("/:id/call-offs", purchaseAgreementHandler.CreateCallOff)
+	}
+
+	// Reorder-point replenishment - proposals and draft POs grouped by preferred supplier
+	replenishmentGroup := v1.Group("/replenishment")
+	replenishmentGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		replenishmentGroup.GET("/proposals", replenishmentHandler.Preview)
+		replenishmentGroup.POST("/run", middleware.RequireRole("procurement_manager"), replenishmentHandler.Run)
+	}
+
+	// Purchase returns to supplier (trả hàng NCC)
+	purchaseReturnGroup := v1.Group("/purchase-returns")
+	purchaseReturnGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		purchaseReturnGroup.GET("", purchaseReturnHandler.List)
+		purchaseReturnGroup.GET("/:id", purchaseReturnHandler.GetByID)
+		purchaseReturnGroup.POST("", purchaseReturnHandler.Create)
+		purchaseReturnGroup.POST("/:id/post", middleware.RequireRole("warehouse_manager"), purchaseReturnHandler.Post)
+		purchaseReturnGroup.POST("/:id/cancel", purchaseReturnHandler.Cancel)
+	}
+
+	// Incoming QC - inspection templates per material, batch results, retained samples, COA
+	qcGroup := v1.Group("/qc")
+	qcGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		qcGroup.GET("/templates", qcInspectionHandler.ListTemplates)
+		qcGroup.GET("/templates/:id", qcInspectionHandler.GetTemplate)
+		qcGroup.POST("/templates", middleware.RequireRole("qc_staff", "warehouse_manager"), qcInspectionHandler.CreateTemplate)
+		qcGroup.PUT("/templates/:id", middleware.RequireRole("qc_staff", "warehouse_manager"), qcInspectionHandler.UpdateTemplate)
+
+		qcGroup.GET("/inspections", qcInspectionHandler.ListInspections)
+		qcGroup.GET("/inspections/:id", qcInspectionHandler.GetInspection)
+		qcGroup.POST("/inspections", qcInspectionHandler.CreateInspection)
+		qcGroup.PUT("/inspections/:id/results", qcInspectionHandler.RecordResults)
+		qcGroup.GET("/inspections/:id/coa", qcInspectionHandler.GetCOA)
+		qcGroup.POST("/inspections/:id/retained-samples", qcInspectionHandler.CreateRetainedSample)
+
+		qcGroup.GET("/retained-samples", qcInspectionHandler.ListRetainedSamples)
+		qcGroup.POST("/retained-samples/:id/dispose", middleware.RequireRole("qc_staff", "warehouse_manager"), qcInspectionHandler.DisposeRetainedSample)
+	}
+
+	// Guided putaway - tasks generated when GRN/FPRN stock is posted without a storage location
+	putawayGroup := v1.Group("/putaway-tasks")
+	putawayGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		putawayGroup.GET("", putawayHandler.List)
+		putawayGroup.GET("/:id", putawayHandler.Get)
+		putawayGroup.GET("/:id/suggestions", putawayHandler.Suggest)
+		putawayGroup.POST("/:id/confirm", putawayHandler.Confirm)
+		putawayGroup.POST("/:id/cancel", middleware.RequireRole("warehouse_manager"), putawayHandler.Cancel)
+	}
+
+	// Bin-to-bin moves inside one warehouse and consolidation of stock spread over partially-filled locations
+	locationMoveGroup := v1.Group("/location-moves")
+	locationMoveGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		locationMoveGroup.GET("", locationMoveHandler.List)
+		locationMoveGroup.GET("/consolidation", locationMoveHandler.Consolidation)
+		locationMoveGroup.GET("/:id", locationMoveHandler.Get)
+		locationMoveGroup.POST("", locationMoveHandler.Create)
+		locationMoveGroup.POST("/scan", locationMoveHandler.Scan)
+		locationMoveGroup.POST("/:id/post", locationMoveHandler.Post)
+		locationMoveGroup.POST("/:id/cancel", locationMoveHandler.Cancel)
+	}
+
+	// Kits assembled from finished products, and the orders that assemble or take them apart
+	kitGroup := v1.Group("/kits")
+	kitGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		kitGroup.GET("", kitHandler.List)
+		kitGroup.GET("/:id", kitHandler.Get)
+		kitGroup.POST("", middleware.RequireRole("warehouse_manager"), kitHandler.Create)
+		kitGroup.PUT("/:id", middleware.RequireRole("warehouse_manager"), kitHandler.Update)
+	}
+
+	kitOrderGroup := v1.Group("/kit-orders")
+	kitOrderGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		kitOrderGroup.GET("", kitHandler.ListOrders)
+		kitOrderGroup.GET("/:id", kitHandler.GetOrder)
+		kitOrderGroup.POST("", kitHandler.CreateOrder)
+		kitOrderGroup.POST("/:id/post", middleware.RequireRole("warehouse_manager"), kitHandler.PostOrder)
+		kitOrderGroup.POST("/:id/cancel", kitHandler.CancelOrder)
+	}
+
+	// Serial numbers of serial-tracked items, with their history from the stock ledger
+	serialGroup := v1.Group("/serials")
+	serialGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		serialGroup.GET("", serialHandler.List)
+		serialGroup.GET("/:id", serialHandler.Get)
+		serialGroup.GET("/:id/history", serialHandler.History)
+	}
+
+	// Stock reservations for customer and delivery orders, with the reserved vs available report
+	reservationGroup := v1.Group("/stock-reservations")
+	reservationGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		reservationGroup.GET("", stockReservationHandler.List)
+		reservationGroup.GET("/report", stockReservationHandler.Report)
+		reservationGroup.GET("/:id", stockReservationHandler.Get)
+		reservationGroup.POST("", stockReservationHandler.Create)
+		reservationGroup.POST("/:id/reallocate", stockReservationHandler.Reallocate)
+		reservationGroup.POST("/:id/release", stockReservationHandler.Release)
+	}
+
 
 	// Stock / Inventory routes - All protected
 	invGroup := v1.Group("/inventory")
@@ -222,9 +507,13 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 
 		// Transfers
 		invGroup.GET("/transfers", inventoryHandler.ListTransfers)
+		invGroup.GET("/transfers/in-transit", inventoryHandler.InTransitReport)
 		invGroup.GET("/transfers/:id", inventoryHandler.GetTransfer)
+		invGroup.GET("/transfers/:id/pdf", documentHandler.StockTransferPDF)
 		invGroup.POST("/transfers", inventoryHandler.CreateTransfer)
 		invGroup.POST("/transfers/:id/post", middleware.RequireRole("warehouse_manager"), inventoryHandler.PostTransfer)
+		invGroup.POST("/transfers/:id/ship", middleware.RequireRole("warehouse_manager"), inventoryHandler.ShipTransfer)
+		invGroup.POST("/transfers/:id/receive", middleware.RequireRole("warehouse_manager"), inventoryHandler.ReceiveTransfer)
 		invGroup.POST("/transfers/:id/cancel", middleware.RequireRole("warehouse_manager"), inventoryHandler.CancelTransfer)
 	}
 
@@ -234,11 +523,23 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 	{
 		grnGroup.GET("", grnHandler.List)
 		grnGroup.GET("/:id", grnHandler.GetByID)
+		grnGroup.GET("/:id/pdf", documentHandler.GRNPDF)
 		grnGroup.POST("", grnHandler.Create)
 		grnGroup.POST("/:id/qc", grnHandler.UpdateQC)
 		grnGroup.POST("/:id/post", middleware.RequireRole("warehouse_manager"), grnHandler.Post)
 	}
 
+	// Landed cost voucher routes - phân bổ chi phí mua hàng vào GRN đã nhập kho
+	landedCostGroup := v1.Group("/landed-costs")
+	landedCostGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		landedCostGroup.GET("", landedCostHandler.List)
+		landedCostGroup.GET("/:id", landedCostHandler.GetByID)
+		landedCostGroup.POST("", landedCostHandler.Create)
+		landedCostGroup.POST("/:id/post", middleware.RequireRole("warehouse_manager"), landedCostHandler.Post)
+		landedCostGroup.POST("/:id/cancel", middleware.RequireRole("warehouse_manager"), landedCostHandler.Cancel)
+	}
+
 	// Material Request (MR) routes - All protected
 	ppGroup := v1.Group("/production-plans")
 	ppGroup.Use(middleware.AuthMiddleware(authService))
@@ -271,6 +572,7 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 	{
 		minGroup.GET("", minHandler.List)
 		minGroup.GET("/:id", minHandler.GetByID)
+		minGroup.GET("/:id/pdf", documentHandler.MaterialIssueNotePDF)
 		minGroup.POST("", minHandler.Create)
 		minGroup.POST("/:id/post", middleware.RequireRole("warehouse_manager"), minHandler.Post)
 		minGroup.POST("/:id/cancel", middleware.RequireRole("warehouse_manager"), minHandler.Cancel)
@@ -293,12 +595,102 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 	{
 		doGroup.GET("", doHandler.List)
 		doGroup.GET("/:id", doHandler.GetByID)
+		doGroup.GET("/:id/pdf", documentHandler.DeliveryOrderPDF)
 		doGroup.POST("", doHandler.Create)
 		doGroup.PUT("/:id", doHandler.Update)
 		doGroup.POST("/:id/ship", middleware.RequireRole("warehouse_manager"), doHandler.Ship)
 		doGroup.POST("/:id/cancel", middleware.RequireRole("warehouse_manager"), doHandler.Cancel)
 	}
 
+	// Pick lists - FEFO picking for one or more delivery orders; confirmed picks feed the DO ship posting
+	pickListGroup := v1.Group("/pick-lists")
+	pickListGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		pickListGroup.GET("", pickListHandler.List)
+		pickListGroup.GET("/:id", pickListHandler.Get)
+		pickListGroup.POST("", pickListHandler.Generate)
+		pickListGroup.POST("/:id/confirm", pickListHandler.Confirm)
+		pickListGroup.POST("/:id/cancel", middleware.RequireRole("warehouse_manager"), pickListHandler.Cancel)
+	}
+
+	// Pick waves - consolidated picking, sort/pack per DO and bulk shipping of marketplace order batches
+	pickWaveGroup := v1.Group("/pick-waves")
+	pickWaveGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		pickWaveGroup.GET("", pickWaveHandler.List)
+		pickWaveGroup.GET("/:id", pickWaveHandler.Get)
+		pickWaveGroup.POST("", pickWaveHandler.Create)
+		pickWaveGroup.POST("/:id/pick", pickWaveHandler.ConfirmPicks)
+		pickWaveGroup.POST("/:id/sort", pickWaveHandler.Sort)
+		pickWaveGroup.POST("/:id/ship", middleware.RequireRole("warehouse_manager"), pickWaveHandler.Ship)
+		pickWaveGroup.POST("/:id/cancel", middleware.RequireRole("warehouse_manager"), pickWaveHandler.Cancel)
+	}
+
+	// Cycle count routes - ABC classification, blind counts and variance approval
+	cycleCountGroup := v1.Group("/cycle-counts")
+	cycleCountGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		cycleCountGroup.GET("/policies/:warehouse_id", cycleCountHandler.GetPolicy)
+		cycleCountGroup.PUT("/policies", middleware.RequireRole("warehouse_manager"), cycleCountHandler.SavePolicy)
+		cycleCountGroup.GET("/classes", cycleCountHandler.ListClasses)
+		cycleCountGroup.POST("/classify/:warehouse_id", middleware.RequireRole("warehouse_manager"), cycleCountHandler.Classify)
+		cycleCountGroup.POST("/generate", middleware.RequireRole("warehouse_manager"), cycleCountHandler.Generate)
+		cycleCountGroup.GET("/tasks", cycleCountHandler.ListTasks)
+		cycleCountGroup.GET("/tasks/:id", cycleCountHandler.GetTask)
+		cycleCountGroup.POST("/tasks/:id/count", cycleCountHandler.Count)
+		cycleCountGroup.POST("/tasks/:id/recount", middleware.RequireRole("warehouse_manager"), cycleCountHandler.Recount)
+		cycleCountGroup.POST("/tasks/:id/cancel", middleware.RequireRole("warehouse_manager"), cycleCountHandler.Cancel)
+		cycleCountGroup.POST("/approve", middleware.RequireRole("warehouse_manager"), cycleCountHandler.Approve)
+	}
+
+	// Physical inventory routes - stocktake with warehouse freeze
+	physicalInventoryGroup := v1.Group("/physical-inventories")
+	physicalInventoryGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		physicalInventoryGroup.GET("", physicalInventoryHandler.List)
+		physicalInventoryGroup.GET("/:id", physicalInventoryHandler.Get)
+		physicalInventoryGroup.GET("/:id/sheets", physicalInventoryHandler.Sheets)
+		physicalInventoryGroup.POST("", middleware.RequireRole("warehouse_manager"), physicalInventoryHandler.Create)
+		physicalInventoryGroup.POST("/:id/counts", physicalInventoryHandler.RecordCounts)
+		physicalInventoryGroup.POST("/:id/finalize", middleware.RequireRole("warehouse_manager"), physicalInventoryHandler.Finalize)
+		physicalInventoryGroup.POST("/:id/cancel", middleware.RequireRole("warehouse_manager"), physicalInventoryHandler.Cancel)
+	}
+
+	// Barcode scanning - All protected
+	scanGroup := v1.Group("/scan")
+	scanGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		scanGroup.POST("/resolve", scanHandler.Resolve)
+	}
+
+	// Labels (ZPL / PDF) and the print log - All protected
+	labelGroup := v1.Group("/labels")
+	labelGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		labelGroup.GET("/templates", labelHandler.ListTemplates)
+		labelGroup.POST("/templates", middleware.RequireRole("warehouse_manager"), labelHandler.CreateTemplate)
+		labelGroup.PUT("/templates/:id", middleware.RequireRole("warehouse_manager"), labelHandler.UpdateTemplate)
+		labelGroup.POST("/print", labelHandler.Print)
+		labelGroup.GET("/logs", labelHandler.ListLogs)
+		labelGroup.GET("/logs/:id", labelHandler.GetLog)
+		labelGroup.POST("/logs/:id/reprint", labelHandler.Reprint)
+	}
+
+	// Handheld task API - putaway, pick, count and transfer tasks of the logged-in operator
+	mobileGroup := v1.Group("/mobile")
+	mobileGroup.Use(middleware.AuthMiddleware(authService))
+	{
+		mobileGroup.GET("/tasks", mobileHandler.ListTasks)
+		mobileGroup.GET("/tasks/:type/:id", mobileHandler.GetTask)
+		mobileGroup.POST("/tasks/:type/:id/claim", mobileHandler.Claim)
+		mobileGroup.POST("/tasks/:type/:id/release", mobileHandler.Release)
+		mobileGroup.POST("/tasks/:type/:id/assign", middleware.RequireRole("warehouse_manager"), mobileHandler.Assign)
+		mobileGroup.POST("/tasks/:type/:id/confirm", mobileHandler.ConfirmStep)
+		mobileGroup.POST("/tasks/:type/:id/exceptions", mobileHandler.ReportException)
+		mobileGroup.GET("/exceptions", mobileHandler.ListExceptions)
+		mobileGroup.POST("/exceptions/:id/resolve", middleware.RequireRole("warehouse_manager"), mobileHandler.ResolveException)
+	}
+
 	// Sales Channel routes - All protected
 	scGroup := v1.Group("/sales-channels")
 	scGroup.Use(middleware.AuthMiddleware(authService))
@@ -324,6 +716,8 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 		reportGroup.GET("/inventory-value", reportHandler.GetInventoryValueReport)
 		reportGroup.GET("/low-stock", reportHandler.GetLowStockReport)
 		reportGroup.GET("/expiring-soon", reportHandler.GetExpiringSoonReport)
+		reportGroup.GET("/supplier-ranking", supplierScorecardHandler.GetRanking)
+		reportGroup.GET("/purchase-currency", poPaymentHandler.GetCurrencyReport)
 	}
 
 	// Alert routes - All protected
@@ -333,6 +727,7 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 		alertGroup.GET("/summary", alertHandler.GetAlertSummary)
 		alertGroup.GET("/low-stock", alertHandler.GetLowStockAlerts)
 		alertGroup.GET("/expiring-soon", alertHandler.GetExpiringSoonAlerts)
+		alertGroup.GET("/expiring-documents", alertHandler.GetExpiringDocumentAlerts)
 	}
 
 	// Audit Log routes - All protected
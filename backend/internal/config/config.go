@@ -13,6 +13,14 @@ type Config struct {
 	JWT      JWTConfig
 	CORS     CORSConfig
 	Log      LogConfig
+
+	Replenishment ReplenishmentConfig
+	Company       CompanyConfig
+	Documents     DocumentConfig
+	SMTP          SMTPConfig
+	POMail        POMailConfig
+	CycleCount    CycleCountConfig
+	Labels        LabelConfig
 }
 
 type ServerConfig struct {
@@ -44,6 +52,63 @@ type LogConfig struct {
 	Format string
 }
 
+// ReplenishmentConfig schedules automatic reorder-point runs (IntervalHours = 0 disables)
+type ReplenishmentConfig struct {
+	IntervalHours int
+	WarehouseID   int
+	UserID        int // user recorded as creator of the draft POs
+}
+
+// CompanyConfig is printed in the header of generated documents
+type CompanyConfig struct {
+	Name    string
+	Address string
+	TaxCode string
+	Phone   string
+}
+
+// DocumentConfig controls PDF rendering. FontPath should point to a Unicode TrueType font
+// so Vietnamese prints with diacritics; without it the built-in Helvetica is used.
+type DocumentConfig struct {
+	FontPath           string
+	FontBoldPath       string
+	DefaultLanguage    string // vi, en
+	AttachPOOnApproval bool   // save the PO PDF to po_documents when a PO is approved
+}
+
+// SMTPConfig is the outbound mail server. Mail is disabled while Host or From is empty;
+// for a local sink (MailHog on port 1025) leave User empty.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	From     string
+	FromName string
+	TLS      bool // implicit TLS (port 465); otherwise STARTTLS when offered
+}
+
+// POMailConfig controls e-mails to suppliers about purchase orders
+type POMailConfig struct {
+	SendOnApproval        bool // e-mail the PO PDF to the supplier when approved
+	ReminderDays          int  // remind suppliers this many days before the expected delivery date
+	ReminderIntervalHours int  // scheduled reminder runs (0 = on demand only)
+}
+
+// CycleCountConfig schedules ABC classification and count task generation for every
+// warehouse whose cycle count policy has auto_schedule set (IntervalHours = 0 disables)
+type CycleCountConfig struct {
+	IntervalHours int
+	UserID        int // user recorded as creator of the generated tasks
+}
+
+// LabelConfig holds the GS1 company prefix used to number pallet SSCCs; pallet labels
+// cannot be printed while it is empty
+type LabelConfig struct {
+	GS1CompanyPrefix   string
+	SSCCExtensionDigit int
+}
+
 func LoadConfig() (*Config, error) {
 	viper.SetConfigFile(".env")
 	viper.AutomaticEnv()
@@ -77,6 +142,45 @@ func LoadConfig() (*Config, error) {
 			Level:  getEnv("LOG_LEVEL", "debug"),
 			Format: getEnv("LOG_FORMAT", "json"),
 		},
+		Replenishment: ReplenishmentConfig{
+			IntervalHours: getEnvInt("REPLENISHMENT_INTERVAL_HOURS", 0),
+			WarehouseID:   getEnvInt("REPLENISHMENT_WAREHOUSE_ID", 0),
+			UserID:        getEnvInt("REPLENISHMENT_USER_ID", 0),
+		},
+		Company: CompanyConfig{
+			Name:    getEnv("COMPANY_NAME", ""),
+			Address: getEnv("COMPANY_ADDRESS", ""),
+			TaxCode: getEnv("COMPANY_TAX_CODE", ""),
+			Phone:   getEnv("COMPANY_PHONE", ""),
+		},
+		Documents: DocumentConfig{
+			FontPath:           getEnv("DOCUMENT_FONT_PATH", ""),
+			FontBoldPath:       getEnv("DOCUMENT_FONT_BOLD_PATH", ""),
+			DefaultLanguage:    getEnv("DOCUMENT_LANGUAGE", "vi"),
+			AttachPOOnApproval: getEnvBool("PO_ATTACH_PDF_ON_APPROVAL", false),
+		},
+		SMTP: SMTPConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnvInt("SMTP_PORT", 587),
+			User:     getEnv("SMTP_USER", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", ""),
+			FromName: getEnv("SMTP_FROM_NAME", ""),
+			TLS:      getEnvBool("SMTP_TLS", false),
+		},
+		POMail: POMailConfig{
+			SendOnApproval:        getEnvBool("PO_EMAIL_ON_APPROVAL", false),
+			ReminderDays:          getEnvInt("PO_REMINDER_DAYS", 3),
+			ReminderIntervalHours: getEnvInt("PO_REMINDER_INTERVAL_HOURS", 0),
+		},
+		CycleCount: CycleCountConfig{
+			IntervalHours: getEnvInt("CYCLE_COUNT_INTERVAL_HOURS", 0),
+			UserID:        getEnvInt("CYCLE_COUNT_USER_ID", 0),
+		},
+		Labels: LabelConfig{
+			GS1CompanyPrefix:   getEnv("LABEL_GS1_COMPANY_PREFIX", ""),
+			SSCCExtensionDigit: getEnvInt("LABEL_SSCC_EXTENSION_DIGIT", 0),
+		},
 	}
 
 	return config, nil
@@ -98,3 +202,8 @@ func getEnvInt(key string, defaultValue int) int {
 	viper.SetDefault(key, defaultValue)
 	return viper.GetInt(key)
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	viper.SetDefault(key, defaultValue)
+	return viper.GetBool(key)
+}
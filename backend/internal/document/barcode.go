@@ -0,0 +1,190 @@
+package document
+
+import (
+	"fmt"
+	"strings"
+)
+
+// code128Patterns are the bar/space module widths of Code 128 symbol values 0..105 and the stop pattern (106)
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128FNC1   = 102
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// fnc1 marks an FNC1 position in the data handed to the encoder
+const fnc1 = -1
+
+// EncodeCode128 encodes printable ASCII as Code 128 (subsets B and C) and returns the module
+// widths, alternating bar and space and starting with a bar, without quiet zones
+func EncodeCode128(data string) ([]int, error) {
+	chars := make([]int, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		chars = append(chars, int(data[i]))
+	}
+	return encodeCode128(chars, false)
+}
+
+// EncodeGS1128 encodes a GS1 element string given in bracketed form, e.g. "(01)04006381333931(10)LOT1",
+// as GS1-128: FNC1 after the start character and after every variable-length field except the last
+func EncodeGS1128(bracketed string) ([]int, error) {
+	elements, err := splitGS1(bracketed)
+	if err != nil {
+		return nil, err
+	}
+	var chars []int
+	for i, e := range elements {
+		for j := 0; j < len(e.AI); j++ {
+			chars = append(chars, int(e.AI[j]))
+		}
+		for j := 0; j < len(e.Value); j++ {
+			chars = append(chars, int(e.Value[j]))
+		}
+		if i < len(elements)-1 && !gs1FixedLength(e.AI) {
+			chars = append(chars, fnc1)
+		}
+	}
+	return encodeCode128(chars, true)
+}
+
+// IsGS1Bracketed reports whether s is a GS1 element string in bracketed form
+func IsGS1Bracketed(s string) bool {
+	_, err := splitGS1(s)
+	return err == nil
+}
+
+type gs1Element struct {
+	AI    string
+	Value string
+}
+
+func splitGS1(s string) ([]gs1Element, error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, fmt.Errorf("GS1 data must start with an application identifier in brackets")
+	}
+	var elements []gs1Element
+	for _, part := range strings.Split(s[1:], "(") {
+		ai, value, ok := strings.Cut(part, ")")
+		if !ok || len(ai) < 2 || len(ai) > 4 || strings.Trim(ai, "0123456789") != "" || value == "" {
+			return nil, fmt.Errorf("invalid GS1 element %q", "("+part)
+		}
+		elements = append(elements, gs1Element{AI: ai, Value: value})
+	}
+	return elements, nil
+}
+
+// gs1FixedLength reports whether an AI has a predefined length and so needs no FNC1 separator
+func gs1FixedLength(ai string) bool {
+	switch ai[:2] {
+	case "00", "01", "02", "03", "04", "11", "12", "13", "14", "15", "16", "17", "18", "19", "20",
+		"31", "32", "33", "34", "35", "36", "41":
+		return true
+	}
+	return false
+}
+
+// encodeCode128 picks subset C for runs of four or more digits and subset B otherwise
+func encodeCode128(chars []int, gs1 bool) ([]int, error) {
+	for _, c := range chars {
+		if c != fnc1 && (c < 32 || c > 126) {
+			return nil, fmt.Errorf("code 128: unsupported character %q", rune(c))
+		}
+	}
+	digitRun := func(i int) int {
+		n := 0
+		for i+n < len(chars) && chars[i+n] >= '0' && chars[i+n] <= '9' {
+			n++
+		}
+		return n
+	}
+
+	var values []int
+	subsetC := digitRun(0) >= 4 || (digitRun(0) == len(chars) && len(chars) >= 2 && len(chars)%2 == 0)
+	if subsetC {
+		values = append(values, code128StartC)
+	} else {
+		values = append(values, code128StartB)
+	}
+	if gs1 {
+		values = append(values, code128FNC1)
+	}
+
+	for i := 0; i < len(chars); {
+		if chars[i] == fnc1 {
+			values = append(values, code128FNC1)
+			i++
+			continue
+		}
+		run := digitRun(i)
+		if subsetC {
+			if run >= 2 {
+				values = append(values, int(chars[i]-'0')*10+int(chars[i+1]-'0'))
+				i += 2
+				continue
+			}
+			values = append(values, code128CodeB)
+			subsetC = false
+		}
+		if run >= 4 {
+			if run%2 == 1 {
+				values = append(values, chars[i]-32)
+				i++
+			}
+			values = append(values, code128CodeC)
+			subsetC = true
+			continue
+		}
+		values = append(values, chars[i]-32)
+		i++
+	}
+
+	sum := values[0]
+	for i := 1; i < len(values); i++ {
+		sum += i * values[i]
+	}
+	values = append(values, sum%103, code128Stop)
+
+	modules := make([]int, 0, len(values)*6+1)
+	for _, v := range values {
+		for _, w := range code128Patterns[v] {
+			modules = append(modules, int(w-'0'))
+		}
+	}
+	return modules, nil
+}
+
+// Barcode draws a linear barcode from module widths (as returned by EncodeCode128) scaled
+// to width w, with its top-left corner at x,y
+func (p *PDF) Barcode(x, y, w, h float64, modules []int) {
+	total := 0
+	for _, m := range modules {
+		total += m
+	}
+	if total == 0 {
+		return
+	}
+	unit := w / float64(total)
+	for i, m := range modules {
+		if i%2 == 0 {
+			p.FillRect(x, y, float64(m)*unit, h)
+		}
+		x += float64(m) * unit
+	}
+}
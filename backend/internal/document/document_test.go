@@ -0,0 +1,92 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRenderProducesPDF(t *testing.T) {
+	doc := &Document{
+		Lang:    LangVI,
+		Company: Company{Name: "Công ty TNHH VyVy", Address: "Quận 7, TP.HCM", TaxCode: "0312345678"},
+		Title:   T(LangVI, "title_grn"),
+		Number:  "GRN-2026-000001",
+		Date:    FormatDate(LangVI, "2026-03-15"),
+		Fields:  []Field{{Label: T(LangVI, "supplier"), Value: "Nhà cung cấp A"}, {Label: T(LangVI, "warehouse"), Value: "Kho chính"}},
+		Columns: []Column{{Title: "STT", Width: 1, Align: AlignCenter}, {Title: "Tên hàng", Width: 6}, {Title: "Số lượng", Width: 2, Align: AlignRight}},
+		Notes:   "Hàng đủ",
+		Signatures: []string{
+			T(LangVI, "sign_deliverer"), T(LangVI, "sign_storekeeper"), T(LangVI, "sign_accountant"),
+		},
+	}
+	for i := 1; i <= 120; i++ {
+		doc.Rows = append(doc.Rows, []string{fmt.Sprint(i), "Glycerin tinh khiết 99,5%", FormatNumber(LangVI, 1250.5, 3)})
+	}
+
+	out, err := Render(doc, nil)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("output is not a complete PDF")
+	}
+	// ~40 rows fit on a page, the header is repeated on each
+	if !bytes.Contains(out, []byte("/Count 4")) {
+		t.Errorf("expected 120 rows to span 4 pages")
+	}
+}
+
+func TestWrapSplitsOnWidth(t *testing.T) {
+	p := NewPDF(nil)
+	p.SetFont(false, 10)
+	lines := wrap(p, "the quick brown fox jumps over the lazy dog", 60)
+	if len(lines) < 3 {
+		t.Fatalf("expected several lines, got %q", lines)
+	}
+	for _, l := range lines {
+		if p.TextWidth(l) > 60 {
+			t.Errorf("line %q is wider than 60pt", l)
+		}
+	}
+	long := wrap(p, strings.Repeat("W", 40), 50)
+	if len(long) < 2 || strings.Join(long, "") != strings.Repeat("W", 40) {
+		t.Errorf("long word not split cleanly: %q", long)
+	}
+	if got := wrap(p, "a\nb", 100); len(got) != 2 {
+		t.Errorf("explicit newline not kept: %q", got)
+	}
+}
+
+func TestFoldASCII(t *testing.T) {
+	if got := foldASCII("Phiếu nhập kho Đ – ≥5"); got != "Phieu nhap kho D - >5" {
+		t.Errorf("foldASCII = %q", got)
+	}
+}
+
+func TestFormatNumberAndDate(t *testing.T) {
+	cases := []struct {
+		lang string
+		v    float64
+		dec  int
+		want string
+	}{
+		{LangVI, 1234567.5, 2, "1.234.567,5"},
+		{LangEN, 1234567.5, 2, "1,234,567.5"},
+		{LangEN, -1000, 0, "-1,000"},
+		{LangVI, 12.0, 3, "12"},
+		{LangVI, -0.0001, 2, "0"},
+	}
+	for _, c := range cases {
+		if got := FormatNumber(c.lang, c.v, c.dec); got != c.want {
+			t.Errorf("FormatNumber(%s, %v, %d) = %q, want %q", c.lang, c.v, c.dec, got, c.want)
+		}
+	}
+	if got := FormatDate(LangVI, "2026-01-31T00:00:00Z"); got != "31/01/2026" {
+		t.Errorf("FormatDate vi = %q", got)
+	}
+	if got := FormatDate("en-US", "2026-01-31"); got != "31 Jan 2026" {
+		t.Errorf("FormatDate en = %q", got)
+	}
+}
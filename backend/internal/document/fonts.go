@@ -0,0 +1,155 @@
+package document
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FontSet holds the TrueType faces used for rendering. Vietnamese text needs a Unicode
+// font (e.g. Noto Sans, Roboto, DejaVu Sans); without one, output falls back to the PDF
+// built-in Helvetica and diacritics are dropped.
+type FontSet struct {
+	Regular *TrueType
+	Bold    *TrueType
+}
+
+// LoadFontSet loads the regular and (optional) bold TrueType files.
+// An empty regular path returns nil, meaning built-in fonts.
+func LoadFontSet(regularPath, boldPath string) (*FontSet, error) {
+	if regularPath == "" {
+		return nil, nil
+	}
+	fs := &FontSet{}
+	data, err := os.ReadFile(regularPath)
+	if err != nil {
+		return nil, fmt.Errorf("document font: %w", err)
+	}
+	if fs.Regular, err = ParseTrueType(data); err != nil {
+		return nil, fmt.Errorf("document font %s: %w", regularPath, err)
+	}
+	if boldPath != "" {
+		data, err := os.ReadFile(boldPath)
+		if err != nil {
+			return nil, fmt.Errorf("document bold font: %w", err)
+		}
+		if fs.Bold, err = ParseTrueType(data); err != nil {
+			return nil, fmt.Errorf("document bold font %s: %w", boldPath, err)
+		}
+	}
+	return fs, nil
+}
+
+// standardFont is one of the 14 built-in PDF fonts, limited to printable ASCII
+type standardFont struct {
+	baseFont string
+	widths   *[95]int // ASCII 32..126
+}
+
+func newStandardFont(baseFont string, widths *[95]int) *standardFont {
+	return &standardFont{baseFont: baseFont, widths: widths}
+}
+
+func (f *standardFont) encode(s string) string {
+	s = foldASCII(s)
+	var b strings.Builder
+	b.WriteByte('(')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+func (f *standardFont) width(s string) float64 {
+	s = foldASCII(s)
+	total := 0
+	for i := 0; i < len(s); i++ {
+		total += f.widths[s[i]-32]
+	}
+	return float64(total)
+}
+
+func (f *standardFont) write(w *objWriter) int {
+	return w.object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.baseFont))
+}
+
+// foldASCII strips diacritics (Vietnamese included) and replaces anything outside printable ASCII
+func foldASCII(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 32 && r <= 126:
+			b.WriteRune(r)
+		case r == '\t' || r == '\n':
+			b.WriteByte(' ')
+		default:
+			if base, ok := asciiFold[r]; ok {
+				b.WriteByte(base)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+// asciiFold maps accented Latin letters used in Vietnamese (and common Western ones) to their base letter
+var asciiFold = func() map[rune]byte {
+	groups := map[byte]string{
+		'a': "àáâãäåạảấầẩẫậắằẳẵặă",
+		'A': "ÀÁÂÃÄÅẠẢẤẦẨẪẬẮẰẲẴẶĂ",
+		'e': "èéêëẹẻẽếềểễệ",
+		'E': "ÈÉÊËẸẺẼẾỀỂỄỆ",
+		'i': "ìíîïịỉĩ",
+		'I': "ÌÍÎÏỊỈĨ",
+		'o': "òóôõöøọỏốồổỗộớờởỡợơ",
+		'O': "ÒÓÔÕÖØỌỎỐỒỔỖỘỚỜỞỠỢƠ",
+		'u': "ùúûüụủũứừửữựư",
+		'U': "ÙÚÛÜỤỦŨỨỪỬỮỰƯ",
+		'y': "ýÿỳỵỷỹ",
+		'Y': "ÝỲỴỶỸ",
+		'd': "đ",
+		'D': "Đ",
+		'c': "ç",
+		'C': "Ç",
+		'n': "ñ",
+		'N': "Ñ",
+	}
+	m := make(map[rune]byte)
+	for base, letters := range groups {
+		for _, r := range letters {
+			m[r] = base
+		}
+	}
+	// Typographic punctuation
+	for r, base := range map[rune]byte{'–': '-', '—': '-', '‘': '\'', '’': '\'', '“': '"', '”': '"', '…': '.', '≤': '<', '≥': '>', '\u00a0': ' '} {
+		m[r] = base
+	}
+	return m
+}()
+
+// Advance widths of the standard Helvetica faces for ASCII 32..126 (Adobe AFM)
+var helveticaWidths = &[95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = &[95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,133 @@
+package document
+
+import "fmt"
+
+// Label is one printed label: a headline, a few text lines and a barcode. Barcode holds the
+// scan payload; GS1 data is given in bracketed form "(01)...(10)..." and printed as GS1-128.
+type Label struct {
+	Title   string            `json:"title"`
+	Lines   []string          `json:"lines,omitempty"`
+	Barcode string            `json:"barcode"`
+	Fields  map[string]string `json:"fields,omitempty"` // named values for custom ZPL templates
+}
+
+// LabelLayout places labels of WidthMM x HeightMM in a Columns x Rows grid on A4 sheets
+type LabelLayout struct {
+	WidthMM      float64
+	HeightMM     float64
+	Columns      int
+	Rows         int
+	MarginLeftMM float64
+	MarginTopMM  float64
+	GapXMM       float64
+	GapYMM       float64
+}
+
+const pointsPerMM = 72 / 25.4
+
+func mm(v float64) float64 {
+	return v * pointsPerMM
+}
+
+// Validate checks that the grid fits on an A4 page
+func (l LabelLayout) Validate() error {
+	if l.WidthMM <= 0 || l.HeightMM <= 0 || l.Columns <= 0 || l.Rows <= 0 {
+		return fmt.Errorf("label size and grid must be positive")
+	}
+	w := l.MarginLeftMM + float64(l.Columns)*l.WidthMM + float64(l.Columns-1)*l.GapXMM
+	h := l.MarginTopMM + float64(l.Rows)*l.HeightMM + float64(l.Rows-1)*l.GapYMM
+	if mm(w) > PageWidth+0.5 || mm(h) > PageHeight+0.5 {
+		return fmt.Errorf("%d x %d labels of %gx%g mm do not fit on an A4 sheet", l.Columns, l.Rows, l.WidthMM, l.HeightMM)
+	}
+	return nil
+}
+
+// RenderLabelSheets lays labels out row by row on as many A4 sheets as needed
+func RenderLabelSheets(labels []Label, layout LabelLayout, fonts *FontSet) ([]byte, error) {
+	if err := layout.Validate(); err != nil {
+		return nil, err
+	}
+	p := NewPDF(fonts)
+	perPage := layout.Columns * layout.Rows
+	for i, label := range labels {
+		if i%perPage == 0 {
+			p.AddPage()
+		}
+		cell := i % perPage
+		col, row := cell%layout.Columns, cell/layout.Columns
+		x := mm(layout.MarginLeftMM + float64(col)*(layout.WidthMM+layout.GapXMM))
+		y := mm(layout.MarginTopMM + float64(row)*(layout.HeightMM+layout.GapYMM))
+		if err := drawLabel(p, label, x, y, mm(layout.WidthMM), mm(layout.HeightMM)); err != nil {
+			return nil, err
+		}
+	}
+	return p.Bytes()
+}
+
+// drawLabel prints the title, then the text lines, and the barcode with its text along the bottom
+func drawLabel(p *PDF, label Label, x, y, w, h float64) error {
+	pad := mm(2)
+	inner := w - 2*pad
+
+	barH := h * 0.3
+	textSize := clamp(h/14, 5, 8)
+	barTop := y + h - pad - textSize - 1 - barH
+	var modules []int
+	var err error
+	if label.Barcode != "" {
+		if IsGS1Bracketed(label.Barcode) {
+			modules, err = EncodeGS1128(label.Barcode)
+		} else {
+			modules, err = EncodeCode128(label.Barcode)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	titleSize := clamp(h/7, 7, 14)
+	p.SetFont(true, titleSize)
+	cursor := y + pad + titleSize
+	p.Text(x+pad, cursor, fitText(p, label.Title, inner))
+
+	p.SetFont(false, textSize)
+	limit := barTop - 1
+	if modules == nil {
+		limit = y + h - pad
+	}
+	for _, line := range label.Lines {
+		if cursor+textSize*leading > limit {
+			break
+		}
+		cursor += textSize * leading
+		p.Text(x+pad, cursor, fitText(p, line, inner))
+	}
+
+	if modules != nil {
+		p.Barcode(x+pad, barTop, inner, barH, modules)
+		p.Text(x+pad+(inner-p.TextWidth(label.Barcode))/2, y+h-pad, fitText(p, label.Barcode, inner))
+	}
+	return nil
+}
+
+// fitText cuts s so that it fits in width with the current font
+func fitText(p *PDF, s string, width float64) string {
+	if p.TextWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && p.TextWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
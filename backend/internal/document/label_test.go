@@ -0,0 +1,146 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// code128Values decodes module widths back to symbol values
+func code128Values(t *testing.T, modules []int) []int {
+	t.Helper()
+	lookup := make(map[string]int, len(code128Patterns))
+	for v, p := range code128Patterns {
+		lookup[p] = v
+	}
+	var values []int
+	for i := 0; i < len(modules); {
+		n := 6
+		if len(modules)-i == 7 {
+			n = 7
+		}
+		var b strings.Builder
+		for _, m := range modules[i : i+n] {
+			b.WriteByte(byte('0' + m))
+		}
+		v, ok := lookup[b.String()]
+		if !ok {
+			t.Fatalf("unknown pattern %s", b.String())
+		}
+		values = append(values, v)
+		i += n
+	}
+	return values
+}
+
+func TestCode128Patterns(t *testing.T) {
+	for v, p := range code128Patterns {
+		bars, spaces := 0, 0
+		for i, c := range p {
+			if i%2 == 0 {
+				bars += int(c - '0')
+			} else {
+				spaces += int(c - '0')
+			}
+		}
+		width := 11
+		if v == code128Stop {
+			width = 13
+		}
+		if bars+spaces != width || bars%2 != 0 {
+			t.Errorf("pattern %d (%s) is malformed", v, p)
+		}
+	}
+}
+
+func TestEncodeGS1128(t *testing.T) {
+	modules, err := EncodeGS1128("(01)04006381333931(10)AB(17)261231")
+	if err != nil {
+		t.Fatalf("EncodeGS1128: %v", err)
+	}
+	got := code128Values(t, modules)
+	// start C, FNC1, 01 04 00 63 81 33 39 31 10, code B, A B, FNC1, code C, 17 26 12 31
+	want := []int{105, 102, 1, 4, 0, 63, 81, 33, 39, 31, 10, 100, 33, 34, 102, 99, 17, 26, 12, 31}
+	sum := want[0]
+	for i := 1; i < len(want); i++ {
+		sum += i * want[i]
+	}
+	want = append(want, sum%103, code128Stop)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("values = %v, want %v", got, want)
+	}
+
+	if _, err := EncodeGS1128("LOC:A-01"); err == nil {
+		t.Errorf("expected non-GS1 data to be rejected")
+	}
+}
+
+func TestEncodeCode128SwitchesSubsets(t *testing.T) {
+	modules, err := EncodeCode128("LOC:A-12345")
+	if err != nil {
+		t.Fatalf("EncodeCode128: %v", err)
+	}
+	got := code128Values(t, modules)
+	// B: L O C : A - 1, then C: 23 45
+	want := []int{104, 44, 47, 35, 26, 33, 13, 17, 99, 23, 45}
+	if fmt.Sprint(got[:len(want)]) != fmt.Sprint(want) {
+		t.Errorf("values = %v, want prefix %v", got, want)
+	}
+	if _, err := EncodeCode128("Kho Đông"); err == nil {
+		t.Errorf("expected non-ASCII data to be rejected")
+	}
+}
+
+func TestRenderLabelSheets(t *testing.T) {
+	layout := LabelLayout{WidthMM: 70, HeightMM: 37, Columns: 3, Rows: 8}
+	labels := make([]Label, 30)
+	for i := range labels {
+		labels[i] = Label{Title: "MAT-001", Lines: []string{"Glycerin", "Số lô: L1"}, Barcode: "(240)MAT-001(10)L1"}
+	}
+	out, err := RenderLabelSheets(labels, layout, nil)
+	if err != nil {
+		t.Fatalf("RenderLabelSheets: %v", err)
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) {
+		t.Errorf("expected 30 labels of 24 per sheet to span 2 pages")
+	}
+
+	layout.Rows = 9
+	if _, err := RenderLabelSheets(labels, layout, nil); err == nil {
+		t.Errorf("expected a grid taller than A4 to be rejected")
+	}
+}
+
+func TestRenderZPL(t *testing.T) {
+	labels := []Label{
+		{Title: "A-01_02", Lines: []string{"Kệ A"}, Barcode: "LOC:A-01_02"},
+		{Title: "MAT-001", Barcode: "(240)MAT-001(10)L1"},
+	}
+	out, err := RenderZPL(labels, "", 100, 50, 8, BarcodeCode128)
+	if err != nil {
+		t.Fatalf("RenderZPL: %v", err)
+	}
+	zpl := string(out)
+	if strings.Count(zpl, "^XA") != 2 || !strings.Contains(zpl, "^PW800") {
+		t.Errorf("unexpected ZPL:\n%s", zpl)
+	}
+	if !strings.Contains(zpl, "^FDA-01_5F02^FS") || !strings.Contains(zpl, "^FDLOC:A-01_5F02^FS") {
+		t.Errorf("field data not escaped:\n%s", zpl)
+	}
+	if !strings.Contains(zpl, "^BCN,120,Y,N,N,D^FD(240)MAT-001(10)L1^FS") {
+		t.Errorf("GS1 data not printed in UCC/EAN mode:\n%s", zpl)
+	}
+
+	out, err = RenderZPL(labels[:1], "^XA^FD{{zpl .Title}}/{{.Dots 10}}^FS^XZ", 50, 25, 12, BarcodeQR)
+	if err != nil {
+		t.Fatalf("RenderZPL custom template: %v", err)
+	}
+	if string(out) != "^XA^FDA-01_5F02/120^FS^XZ" {
+		t.Errorf("custom template output = %q", out)
+	}
+
+	if _, err := ParseZPLTemplate("^XA{{.Title"); err == nil {
+		t.Errorf("expected a broken template to be rejected")
+	}
+}
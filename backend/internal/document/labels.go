@@ -0,0 +1,224 @@
+package document
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported document languages
+const (
+	LangVI = "vi"
+	LangEN = "en"
+)
+
+// NormalizeLang maps a requested language to a supported one; anything unknown is Vietnamese
+func NormalizeLang(lang string) string {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(lang)), LangEN) {
+		return LangEN
+	}
+	return LangVI
+}
+
+// T returns the label for key in lang, falling back to the key itself
+func T(lang, key string) string {
+	if s, ok := labels[NormalizeLang(lang)][key]; ok {
+		return s
+	}
+	return key
+}
+
+var labels = map[string]map[string]string{
+	LangVI: {
+		"address":        "Địa chỉ",
+		"tax_code":       "MST",
+		"phone":          "Điện thoại",
+		"number":         "Số",
+		"date":           "Ngày",
+		"page":           "Trang",
+		"notes":          "Ghi chú",
+		"signature_hint": "(Ký, ghi rõ họ tên)",
+
+		"title_po":   "Đơn đặt hàng",
+		"title_grn":  "Phiếu nhập kho",
+		"title_min":  "Phiếu xuất kho",
+		"title_do":   "Phiếu giao hàng",
+		"title_st":   "Phiếu chuyển kho",
+		"title_fprn": "Phiếu nhập thành phẩm",
+
+		"supplier":          "Nhà cung cấp",
+		"supplier_address":  "Địa chỉ NCC",
+		"supplier_tax_code": "MST NCC",
+		"contact":           "Người liên hệ",
+		"warehouse":         "Kho",
+		"from_warehouse":    "Kho xuất",
+		"to_warehouse":      "Kho nhập",
+		"order_date":        "Ngày đặt hàng",
+		"expected_date":     "Ngày giao dự kiến",
+		"payment_terms":     "Điều khoản TT",
+		"shipping_method":   "Vận chuyển",
+		"currency":          "Tiền tệ",
+		"po_number":         "Đơn hàng",
+		"receipt_date":      "Ngày nhập",
+		"qc_status":         "Kiểm tra CL",
+		"issue_date":        "Ngày xuất",
+		"production_plan":   "Kế hoạch SX",
+		"customer":          "Khách hàng",
+		"customer_address":  "Địa chỉ giao",
+		"delivery_date":     "Ngày giao",
+		"tracking_number":   "Mã vận đơn",
+		"transfer_date":     "Ngày chuyển",
+		"status":            "Trạng thái",
+		"pallet":            "Pallet",
+
+		"col_no":           "STT",
+		"col_code":         "Mã hàng",
+		"col_name":         "Tên hàng",
+		"col_unit":         "ĐVT",
+		"col_qty":          "Số lượng",
+		"col_unit_price":   "Đơn giá",
+		"col_tax":          "Thuế (%)",
+		"col_amount":       "Thành tiền",
+		"col_batch":        "Số lô",
+		"col_expiry":       "Hạn dùng",
+		"col_mfg_date":     "Ngày SX",
+		"col_received":     "Thực nhận",
+		"col_accepted":     "Đạt",
+		"col_rejected":     "Không đạt",
+		"col_location":     "Vị trí",
+		"col_from":         "Từ vị trí",
+		"col_to":           "Đến vị trí",
+		"subtotal":         "Cộng tiền hàng",
+		"discount":         "Chiết khấu",
+		"tax":              "Tiền thuế",
+		"total":            "Tổng cộng",
+		"total_qty":        "Tổng số lượng",
+		"sign_preparer":    "Người lập phiếu",
+		"sign_purchasing":  "Trưởng bộ phận mua hàng",
+		"sign_director":    "Giám đốc",
+		"sign_deliverer":   "Người giao hàng",
+		"sign_storekeeper": "Thủ kho",
+		"sign_accountant":  "Kế toán trưởng",
+		"sign_receiver":    "Người nhận hàng",
+		"sign_from_store":  "Thủ kho xuất",
+		"sign_to_store":    "Thủ kho nhập",
+	},
+	LangEN: {
+		"address":        "Address",
+		"tax_code":       "Tax code",
+		"phone":          "Phone",
+		"number":         "No.",
+		"date":           "Date",
+		"page":           "Page",
+		"notes":          "Notes",
+		"signature_hint": "(Signature, full name)",
+
+		"title_po":   "Purchase Order",
+		"title_grn":  "Goods Receipt Note",
+		"title_min":  "Material Issue Note",
+		"title_do":   "Delivery Order",
+		"title_st":   "Stock Transfer",
+		"title_fprn": "Finished Goods Receipt",
+
+		"supplier":          "Supplier",
+		"supplier_address":  "Supplier address",
+		"supplier_tax_code": "Supplier tax code",
+		"contact":           "Contact",
+		"warehouse":         "Warehouse",
+		"from_warehouse":    "From warehouse",
+		"to_warehouse":      "To warehouse",
+		"order_date":        "Order date",
+		"expected_date":     "Expected delivery",
+		"payment_terms":     "Payment terms",
+		"shipping_method":   "Shipping",
+		"currency":          "Currency",
+		"po_number":         "Purchase order",
+		"receipt_date":      "Receipt date",
+		"qc_status":         "QC status",
+		"issue_date":        "Issue date",
+		"production_plan":   "Production plan",
+		"customer":          "Customer",
+		"customer_address":  "Ship to",
+		"delivery_date":     "Delivery date",
+		"tracking_number":   "Tracking no.",
+		"transfer_date":     "Transfer date",
+		"status":            "Status",
+		"pallet":            "Pallet",
+
+		"col_no":           "No.",
+		"col_code":         "Code",
+		"col_name":         "Description",
+		"col_unit":         "Unit",
+		"col_qty":          "Quantity",
+		"col_unit_price":   "Unit price",
+		"col_tax":          "Tax (%)",
+		"col_amount":       "Amount",
+		"col_batch":        "Batch",
+		"col_expiry":       "Expiry",
+		"col_mfg_date":     "Mfg date",
+		"col_received":     "Received",
+		"col_accepted":     "Accepted",
+		"col_rejected":     "Rejected",
+		"col_location":     "Location",
+		"col_from":         "From",
+		"col_to":           "To",
+		"subtotal":         "Subtotal",
+		"discount":         "Discount",
+		"tax":              "Tax",
+		"total":            "Total",
+		"total_qty":        "Total quantity",
+		"sign_preparer":    "Prepared by",
+		"sign_purchasing":  "Purchasing manager",
+		"sign_director":    "Director",
+		"sign_deliverer":   "Delivered by",
+		"sign_storekeeper": "Storekeeper",
+		"sign_accountant":  "Chief accountant",
+		"sign_receiver":    "Received by",
+		"sign_from_store":  "Issuing storekeeper",
+		"sign_to_store":    "Receiving storekeeper",
+	},
+}
+
+// FormatNumber groups thousands the local way: 1.234.567,5 (vi) or 1,234,567.5 (en).
+// Trailing zeros after the decimal point are dropped.
+func FormatNumber(lang string, v float64, decimals int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	intPart, frac, _ := strings.Cut(s, ".")
+	frac = strings.TrimRight(frac, "0")
+
+	group, point := ",", "."
+	if NormalizeLang(lang) == LangVI {
+		group, point = ".", ","
+	}
+	var b strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(group)
+		}
+		b.WriteRune(c)
+	}
+	if frac != "" {
+		b.WriteString(point)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// FormatDate prints a YYYY-MM-DD (or RFC 3339) date as 31/01/2026 (vi) or 31 Jan 2026 (en)
+func FormatDate(lang, s string) string {
+	if len(s) < 10 {
+		return s
+	}
+	t, err := time.Parse("2006-01-02", s[:10])
+	if err != nil {
+		return s
+	}
+	if NormalizeLang(lang) == LangEN {
+		return t.Format("02 Jan 2006")
+	}
+	return t.Format("02/01/2006")
+}
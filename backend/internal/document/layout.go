@@ -0,0 +1,409 @@
+package document
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Align is the horizontal alignment of a table column
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+	AlignCenter
+)
+
+// Company is printed in the document header
+type Company struct {
+	Name    string
+	Address string
+	TaxCode string
+	Phone   string
+}
+
+// Field is a label/value pair (info block and totals)
+type Field struct {
+	Label string
+	Value string
+}
+
+// Column describes a table column; Width is relative to the other columns
+type Column struct {
+	Title string
+	Width float64
+	Align Align
+}
+
+// Document is a printable business document: header, info block, line table, totals,
+// notes and a signatures block. Text is laid out on as many A4 pages as needed.
+type Document struct {
+	Lang       string
+	Company    Company
+	Title      string
+	Number     string
+	Date       string
+	Fields     []Field
+	Columns    []Column
+	Rows       [][]string
+	Totals     []Field
+	Notes      string
+	Signatures []string
+}
+
+const (
+	margin      = 40.0
+	footerSpace = 28.0
+	cellPadding = 3.0
+	leading     = 1.3
+	tableFont   = 8.5
+	bodyFont    = 9.0
+)
+
+type renderer struct {
+	pdf  *PDF
+	doc  *Document
+	y    float64
+	colW []float64
+}
+
+// Render lays out doc and returns the PDF bytes; fonts may be nil to use built-in fonts
+func Render(doc *Document, fonts *FontSet) ([]byte, error) {
+	r := &renderer{pdf: NewPDF(fonts), doc: doc}
+	r.pdf.AddPage()
+	r.y = margin
+	r.header()
+	r.fields()
+	r.table()
+	r.totals()
+	r.notes()
+	r.signatures()
+	r.footers()
+	return r.pdf.Bytes()
+}
+
+func contentWidth() float64 {
+	return PageWidth - 2*margin
+}
+
+func (r *renderer) bottom() float64 {
+	return PageHeight - margin - footerSpace
+}
+
+// ensure starts a new page when h points do not fit on the current one
+func (r *renderer) ensure(h float64) bool {
+	if r.y+h <= r.bottom() {
+		return false
+	}
+	r.pdf.AddPage()
+	r.y = margin
+	return true
+}
+
+func (r *renderer) header() {
+	p, c := r.pdf, r.doc.Company
+	if c.Name != "" {
+		p.SetFont(true, 11)
+		r.y += 11
+		p.Text(margin, r.y, c.Name)
+		r.y += 3
+	}
+	p.SetFont(false, 8.5)
+	lines := []string{}
+	if c.Address != "" {
+		lines = append(lines, T(r.doc.Lang, "address")+": "+c.Address)
+	}
+	var contact []string
+	if c.TaxCode != "" {
+		contact = append(contact, T(r.doc.Lang, "tax_code")+": "+c.TaxCode)
+	}
+	if c.Phone != "" {
+		contact = append(contact, T(r.doc.Lang, "phone")+": "+c.Phone)
+	}
+	if len(contact) > 0 {
+		lines = append(lines, strings.Join(contact, "   "))
+	}
+	for _, s := range lines {
+		for _, l := range wrap(p, s, contentWidth()) {
+			r.y += 8.5 * leading
+			p.Text(margin, r.y, l)
+		}
+	}
+	r.y += 6
+	p.Line(margin, r.y, PageWidth-margin, r.y, 0.6)
+
+	r.y += 26
+	p.SetFont(true, 16)
+	for _, l := range wrap(p, strings.ToUpper(r.doc.Title), contentWidth()) {
+		p.Text((PageWidth-p.TextWidth(l))/2, r.y, l)
+		r.y += 18
+	}
+
+	var sub []string
+	if r.doc.Number != "" {
+		sub = append(sub, T(r.doc.Lang, "number")+": "+r.doc.Number)
+	}
+	if r.doc.Date != "" {
+		sub = append(sub, T(r.doc.Lang, "date")+": "+r.doc.Date)
+	}
+	if len(sub) > 0 {
+		p.SetFont(false, bodyFont)
+		s := strings.Join(sub, "     ")
+		p.Text((PageWidth-p.TextWidth(s))/2, r.y, s)
+		r.y += bodyFont * leading
+	}
+	r.y += 10
+}
+
+// fields prints the info block two pairs per row
+func (r *renderer) fields() {
+	if len(r.doc.Fields) == 0 {
+		return
+	}
+	p := r.pdf
+	half := contentWidth() / 2
+	labelW := 90.0
+	lh := bodyFont * leading
+	for i := 0; i < len(r.doc.Fields); i += 2 {
+		pair := r.doc.Fields[i:min(i+2, len(r.doc.Fields))]
+		type cell struct{ label, value []string }
+		cells := make([]cell, len(pair))
+		rows := 1
+		for j, f := range pair {
+			p.SetFont(true, bodyFont)
+			cells[j].label = wrap(p, f.Label+":", labelW-4)
+			p.SetFont(false, bodyFont)
+			cells[j].value = wrap(p, f.Value, half-labelW-8)
+			rows = max(rows, len(cells[j].label), len(cells[j].value))
+		}
+		r.ensure(float64(rows) * lh)
+		for j, c := range cells {
+			x := margin + float64(j)*half
+			p.SetFont(true, bodyFont)
+			for k, l := range c.label {
+				p.Text(x, r.y+lh*float64(k+1)-2, l)
+			}
+			p.SetFont(false, bodyFont)
+			for k, l := range c.value {
+				p.Text(x+labelW, r.y+lh*float64(k+1)-2, l)
+			}
+		}
+		r.y += float64(rows) * lh
+	}
+	r.y += 10
+}
+
+func (r *renderer) table() {
+	if len(r.doc.Columns) == 0 {
+		return
+	}
+	total := 0.0
+	for _, c := range r.doc.Columns {
+		total += c.Width
+	}
+	r.colW = make([]float64, len(r.doc.Columns))
+	for i, c := range r.doc.Columns {
+		r.colW[i] = contentWidth() * c.Width / total
+	}
+
+	titles := make([]string, len(r.doc.Columns))
+	for i, c := range r.doc.Columns {
+		titles[i] = c.Title
+	}
+	r.ensure(r.rowHeight(titles, true) + r.rowHeight(firstRow(r.doc.Rows), false))
+	r.row(titles, true)
+	for _, cells := range r.doc.Rows {
+		if r.ensure(r.rowHeight(cells, false)) {
+			r.row(titles, true)
+		}
+		r.row(cells, false)
+	}
+	r.y += 8
+}
+
+func firstRow(rows [][]string) []string {
+	if len(rows) == 0 {
+		return nil
+	}
+	return rows[0]
+}
+
+func (r *renderer) cellLines(cells []string, header bool) [][]string {
+	r.pdf.SetFont(header, tableFont)
+	lines := make([][]string, len(r.colW))
+	for i := range r.colW {
+		if i < len(cells) {
+			lines[i] = wrap(r.pdf, cells[i], r.colW[i]-2*cellPadding)
+		}
+	}
+	return lines
+}
+
+func (r *renderer) rowHeight(cells []string, header bool) float64 {
+	n := 1
+	for _, l := range r.cellLines(cells, header) {
+		n = max(n, len(l))
+	}
+	return float64(n)*tableFont*leading + 2*cellPadding
+}
+
+func (r *renderer) row(cells []string, header bool) {
+	p := r.pdf
+	lines := r.cellLines(cells, header)
+	h := r.rowHeight(cells, header)
+	fill := -1.0
+	if header {
+		fill = 0.9
+	}
+	x := margin
+	for i, w := range r.colW {
+		p.Rect(x, r.y, w, h, 0.5, fill)
+		align := r.doc.Columns[i].Align
+		if header {
+			align = AlignCenter
+		}
+		for k, l := range lines[i] {
+			ty := r.y + cellPadding + tableFont*leading*float64(k+1) - 2.5
+			tx := x + cellPadding
+			switch align {
+			case AlignRight:
+				tx = x + w - cellPadding - p.TextWidth(l)
+			case AlignCenter:
+				tx = x + (w-p.TextWidth(l))/2
+			}
+			p.Text(tx, ty, l)
+		}
+		x += w
+	}
+	r.y += h
+}
+
+// totals are right-aligned under the table
+func (r *renderer) totals() {
+	if len(r.doc.Totals) == 0 {
+		return
+	}
+	p := r.pdf
+	lh := bodyFont * leading
+	right := PageWidth - margin
+	for _, t := range r.doc.Totals {
+		r.ensure(lh)
+		r.y += lh
+		p.SetFont(false, bodyFont)
+		p.Text(right-p.TextWidth(t.Value), r.y, t.Value)
+		p.SetFont(true, bodyFont)
+		p.Text(right-230, r.y, t.Label+":")
+	}
+	r.y += 10
+}
+
+func (r *renderer) notes() {
+	if strings.TrimSpace(r.doc.Notes) == "" {
+		return
+	}
+	p := r.pdf
+	lh := bodyFont * leading
+	p.SetFont(true, bodyFont)
+	label := T(r.doc.Lang, "notes") + ": "
+	labelW := p.TextWidth(label)
+	p.SetFont(false, bodyFont)
+	lines := wrap(p, r.doc.Notes, contentWidth()-labelW)
+	for i, l := range lines {
+		r.ensure(lh)
+		r.y += lh
+		if i == 0 {
+			p.SetFont(true, bodyFont)
+			p.Text(margin, r.y, label)
+			p.SetFont(false, bodyFont)
+		}
+		p.Text(margin+labelW, r.y, l)
+	}
+	r.y += 10
+}
+
+// signatures prints one column per signer with room to sign
+func (r *renderer) signatures() {
+	n := len(r.doc.Signatures)
+	if n == 0 {
+		return
+	}
+	p := r.pdf
+	r.ensure(90)
+	r.y += 14
+	w := contentWidth() / float64(n)
+	hint := T(r.doc.Lang, "signature_hint")
+	maxLines := 1
+	for i, s := range r.doc.Signatures {
+		x := margin + float64(i)*w
+		p.SetFont(true, bodyFont)
+		lines := wrap(p, s, w-6)
+		maxLines = max(maxLines, len(lines))
+		for k, l := range lines {
+			p.Text(x+(w-p.TextWidth(l))/2, r.y+bodyFont*leading*float64(k), l)
+		}
+		p.SetFont(false, 7.5)
+		p.Text(x+(w-p.TextWidth(hint))/2, r.y+bodyFont*leading*float64(len(lines)), hint)
+	}
+	r.y += bodyFont*leading*float64(maxLines+1) + 60
+}
+
+// footers adds "Page x/y" and the document number to every page
+func (r *renderer) footers() {
+	p := r.pdf
+	count := p.PageCount()
+	y := PageHeight - margin + 8
+	for i := 0; i < count; i++ {
+		p.SelectPage(i)
+		p.Line(margin, y-10, PageWidth-margin, y-10, 0.3)
+		p.SetFont(false, 7.5)
+		if r.doc.Number != "" {
+			p.Text(margin, y, r.doc.Number)
+		}
+		s := fmt.Sprintf("%s %d/%d", T(r.doc.Lang, "page"), i+1, count)
+		p.Text(PageWidth-margin-p.TextWidth(s), y, s)
+	}
+}
+
+// wrap breaks s into lines no wider than width using the current font; explicit newlines are kept
+// and words longer than a line are split
+func wrap(p *PDF, s string, width float64) []string {
+	var out []string
+	for _, para := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if p.TextWidth(candidate) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				out = append(out, line)
+				line = ""
+			}
+			for p.TextWidth(word) > width {
+				cut := splitAt(p, word, width)
+				out = append(out, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// splitAt returns the byte index of the longest prefix of word that fits (at least one rune)
+func splitAt(p *PDF, word string, width float64) int {
+	cut := 0
+	for i, rn := range word {
+		end := i + len(string(rn))
+		if cut > 0 && p.TextWidth(word[:end]) > width {
+			break
+		}
+		cut = end
+	}
+	return cut
+}
@@ -0,0 +1,220 @@
+// Package document renders printable business documents (PO, GRN, MIN, DO, transfers) as PDF.
+// It has no external dependencies: pdf.go writes the PDF objects, fonts.go and truetype.go
+// provide text encoding and metrics, layout.go lays out a Document on A4 pages. Labels are
+// rendered as A4 sheets (label.go, with barcode.go for Code 128) or as ZPL for thermal printers (zpl.go).
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+// A4 portrait in points
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// pdfFont encodes text for a content stream and measures it
+type pdfFont interface {
+	// encode returns the PDF string operand for s, e.g. (abc) or <0012003A>
+	encode(s string) string
+	// width returns the advance width of s in 1/1000 of the font size
+	width(s string) float64
+	// write emits the font objects and returns the font dictionary object number
+	write(w *objWriter) int
+}
+
+// PDF is a minimal multi-page PDF writer. Coordinates are in points from the top-left corner.
+type PDF struct {
+	regular pdfFont
+	bold    pdfFont
+	font    pdfFont
+	size    float64
+	pages   []*bytes.Buffer
+	page    *bytes.Buffer
+}
+
+// NewPDF starts an empty document using the given font set (nil = built-in Helvetica)
+func NewPDF(fonts *FontSet) *PDF {
+	p := &PDF{size: 10}
+	if fonts != nil && fonts.Regular != nil {
+		p.regular = newTrueTypeFont(fonts.Regular, "F1")
+		bold := fonts.Bold
+		if bold == nil {
+			bold = fonts.Regular
+		}
+		p.bold = newTrueTypeFont(bold, "F2")
+	} else {
+		p.regular = newStandardFont("Helvetica", helveticaWidths)
+		p.bold = newStandardFont("Helvetica-Bold", helveticaBoldWidths)
+	}
+	p.font = p.regular
+	return p
+}
+
+// AddPage starts a new page
+func (p *PDF) AddPage() {
+	p.page = &bytes.Buffer{}
+	p.pages = append(p.pages, p.page)
+}
+
+// PageCount returns the number of pages added so far
+func (p *PDF) PageCount() int {
+	return len(p.pages)
+}
+
+// SelectPage makes page i (0-based) current again, e.g. to add footers once the page count is known
+func (p *PDF) SelectPage(i int) {
+	p.page = p.pages[i]
+}
+
+// SetFont selects the regular or bold face and the size in points
+func (p *PDF) SetFont(bold bool, size float64) {
+	if bold {
+		p.font = p.bold
+	} else {
+		p.font = p.regular
+	}
+	p.size = size
+}
+
+// FontSize returns the current font size in points
+func (p *PDF) FontSize() float64 {
+	return p.size
+}
+
+// TextWidth measures s in points with the current font
+func (p *PDF) TextWidth(s string) float64 {
+	return p.font.width(s) * p.size / 1000
+}
+
+// Text draws s with its baseline at y
+func (p *PDF) Text(x, y float64, s string) {
+	if s == "" {
+		return
+	}
+	name := "F1"
+	if p.font == p.bold {
+		name = "F2"
+	}
+	fmt.Fprintf(p.page, "BT /%s %s Tf %s %s Td %s Tj ET\n", name, num(p.size), num(x), num(PageHeight-y), p.font.encode(s))
+}
+
+// Line draws a line segment
+func (p *PDF) Line(x1, y1, x2, y2, lineWidth float64) {
+	fmt.Fprintf(p.page, "%s w %s %s m %s %s l S\n", num(lineWidth), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Rect draws a rectangle outline, or fills it with a grey level (0 = black, 1 = white) when fill >= 0
+func (p *PDF) Rect(x, y, w, h, lineWidth, fill float64) {
+	if fill >= 0 {
+		fmt.Fprintf(p.page, "q %s g %s %s %s %s re f Q\n", num(fill), num(x), num(PageHeight-y-h), num(w), num(h))
+	}
+	fmt.Fprintf(p.page, "%s w %s %s %s %s re S\n", num(lineWidth), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// FillRect fills a rectangle in black without an outline
+func (p *PDF) FillRect(x, y, w, h float64) {
+	fmt.Fprintf(p.page, "%s %s %s %s re f\n", num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// Bytes serialises the document
+func (p *PDF) Bytes() ([]byte, error) {
+	if len(p.pages) == 0 {
+		p.AddPage()
+	}
+	w := newObjWriter()
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	catalogID := w.reserve()
+	pagesID := w.reserve()
+	f1 := p.regular.write(w)
+	f2 := p.bold.write(w)
+
+	kids := make([]string, 0, len(p.pages))
+	for _, content := range p.pages {
+		contentID := w.stream("", content.Bytes(), true)
+		pageID := w.object(fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesID, num(PageWidth), num(PageHeight), f1, f2, contentID))
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+	}
+	w.objectAt(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	w.objectAt(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	return w.finish(catalogID)
+}
+
+// objWriter appends numbered objects and builds the cross-reference table
+type objWriter struct {
+	buf     bytes.Buffer
+	offsets map[int]int
+	next    int
+}
+
+func newObjWriter() *objWriter {
+	return &objWriter{offsets: make(map[int]int), next: 1}
+}
+
+func (w *objWriter) reserve() int {
+	id := w.next
+	w.next++
+	return id
+}
+
+func (w *objWriter) object(body string) int {
+	id := w.reserve()
+	w.objectAt(id, body)
+	return id
+}
+
+func (w *objWriter) objectAt(id int, body string) {
+	w.offsets[id] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream writes a stream object; extraDict is added to the stream dictionary
+func (w *objWriter) stream(extraDict string, data []byte, compress bool) int {
+	id := w.reserve()
+	filter := ""
+	if compress {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		_, _ = zw.Write(data)
+		_ = zw.Close()
+		data = z.Bytes()
+		filter = " /Filter /FlateDecode"
+	}
+	w.offsets[id] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< /Length %d%s%s >>\nstream\n", id, len(data), filter, extraDict)
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+	return id
+}
+
+func (w *objWriter) finish(rootID int) ([]byte, error) {
+	count := w.next
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", count)
+	for id := 1; id < count; id++ {
+		offset, ok := w.offsets[id]
+		if !ok {
+			return nil, fmt.Errorf("pdf: object %d was reserved but never written", id)
+		}
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", count, rootID, xref)
+	return w.buf.Bytes(), nil
+}
+
+// num formats a coordinate compactly
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,335 @@
+package document
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TrueType is a parsed TrueType (glyf-based) font: enough of the tables to map runes to
+// glyphs, measure text and embed the font file.
+type TrueType struct {
+	data       []byte
+	name       string
+	unitsPerEm int
+	bbox       [4]int
+	ascent     int
+	descent    int
+	advances   []uint16        // per glyph, font units
+	cmap       map[rune]uint16 // rune -> glyph ID
+}
+
+// ParseTrueType reads the tables needed for embedding. CFF-based OpenType fonts are not supported.
+func ParseTrueType(data []byte) (*TrueType, error) {
+	if len(data) < 12 {
+		return nil, errors.New("not a TrueType font")
+	}
+	if v := binary.BigEndian.Uint32(data); v != 0x00010000 && v != 0x74727565 {
+		return nil, errors.New("not a TrueType font (CFF outlines are not supported)")
+	}
+	tables := make(map[string][]byte)
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(data) {
+			return nil, errors.New("truncated table directory")
+		}
+		tag := string(data[rec : rec+4])
+		offset := int(binary.BigEndian.Uint32(data[rec+8:]))
+		length := int(binary.BigEndian.Uint32(data[rec+12:]))
+		if offset+length > len(data) {
+			return nil, fmt.Errorf("table %s out of range", tag)
+		}
+		tables[tag] = data[offset : offset+length]
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap", "glyf"} {
+		if tables[tag] == nil {
+			return nil, fmt.Errorf("missing %s table", tag)
+		}
+	}
+
+	f := &TrueType{data: data}
+	head := tables["head"]
+	if len(head) < 54 {
+		return nil, errors.New("invalid head table")
+	}
+	f.unitsPerEm = int(binary.BigEndian.Uint16(head[18:]))
+	if f.unitsPerEm == 0 {
+		return nil, errors.New("invalid unitsPerEm")
+	}
+	for i := 0; i < 4; i++ {
+		f.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+2*i:])))
+	}
+
+	hhea := tables["hhea"]
+	if len(hhea) < 36 {
+		return nil, errors.New("invalid hhea table")
+	}
+	f.ascent = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	f.descent = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	numHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+
+	maxp := tables["maxp"]
+	if len(maxp) < 6 {
+		return nil, errors.New("invalid maxp table")
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+
+	hmtx := tables["hmtx"]
+	if numHMetrics == 0 || len(hmtx) < 4*numHMetrics {
+		return nil, errors.New("invalid hmtx table")
+	}
+	f.advances = make([]uint16, numGlyphs)
+	for g := 0; g < numGlyphs; g++ {
+		if g < numHMetrics {
+			f.advances[g] = binary.BigEndian.Uint16(hmtx[4*g:])
+		} else {
+			f.advances[g] = f.advances[numHMetrics-1]
+		}
+	}
+
+	var err error
+	if f.cmap, err = parseCmap(tables["cmap"]); err != nil {
+		return nil, err
+	}
+	f.name = postScriptName(tables["name"])
+	return f, nil
+}
+
+// parseCmap reads a Unicode subtable: format 12 (full Unicode) preferred, else format 4 (BMP)
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, errors.New("invalid cmap table")
+	}
+	var fmt4, fmt12 []byte
+	n := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < n; i++ {
+		rec := 4 + 8*i
+		if rec+8 > len(cmap) {
+			break
+		}
+		platform := binary.BigEndian.Uint16(cmap[rec:])
+		encoding := binary.BigEndian.Uint16(cmap[rec+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[rec+4:]))
+		if offset+4 > len(cmap) {
+			continue
+		}
+		sub := cmap[offset:]
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		switch binary.BigEndian.Uint16(sub) {
+		case 4:
+			fmt4 = sub
+		case 12:
+			fmt12 = sub
+		}
+	}
+
+	m := make(map[rune]uint16)
+	switch {
+	case fmt12 != nil && len(fmt12) >= 16:
+		groups := int(binary.BigEndian.Uint32(fmt12[12:]))
+		for i := 0; i < groups && 16+12*i+12 <= len(fmt12); i++ {
+			g := fmt12[16+12*i:]
+			start := binary.BigEndian.Uint32(g)
+			end := binary.BigEndian.Uint32(g[4:])
+			glyph := binary.BigEndian.Uint32(g[8:])
+			for c := start; c <= end && c <= 0x10FFFF; c++ {
+				m[rune(c)] = uint16(glyph + c - start)
+			}
+		}
+	case fmt4 != nil && len(fmt4) >= 14:
+		segCount := int(binary.BigEndian.Uint16(fmt4[6:])) / 2
+		ends := 14
+		starts := ends + 2*segCount + 2
+		deltas := starts + 2*segCount
+		rangeOffsets := deltas + 2*segCount
+		if rangeOffsets+2*segCount > len(fmt4) {
+			return nil, errors.New("invalid cmap format 4")
+		}
+		for s := 0; s < segCount; s++ {
+			end := int(binary.BigEndian.Uint16(fmt4[ends+2*s:]))
+			start := int(binary.BigEndian.Uint16(fmt4[starts+2*s:]))
+			delta := int(binary.BigEndian.Uint16(fmt4[deltas+2*s:]))
+			ro := int(binary.BigEndian.Uint16(fmt4[rangeOffsets+2*s:]))
+			for c := start; c <= end && c != 0xFFFF; c++ {
+				var glyph int
+				if ro == 0 {
+					glyph = (c + delta) & 0xFFFF
+				} else {
+					pos := rangeOffsets + 2*s + ro + 2*(c-start)
+					if pos+2 > len(fmt4) {
+						continue
+					}
+					glyph = int(binary.BigEndian.Uint16(fmt4[pos:]))
+					if glyph != 0 {
+						glyph = (glyph + delta) & 0xFFFF
+					}
+				}
+				if glyph != 0 {
+					m[rune(c)] = uint16(glyph)
+				}
+			}
+		}
+	default:
+		return nil, errors.New("no Unicode cmap subtable")
+	}
+	return m, nil
+}
+
+// postScriptName reads name ID 6, falling back to a generic name
+func postScriptName(name []byte) string {
+	if len(name) >= 6 {
+		count := int(binary.BigEndian.Uint16(name[2:]))
+		storage := int(binary.BigEndian.Uint16(name[4:]))
+		for i := 0; i < count; i++ {
+			rec := 6 + 12*i
+			if rec+12 > len(name) {
+				break
+			}
+			platform := binary.BigEndian.Uint16(name[rec:])
+			nameID := binary.BigEndian.Uint16(name[rec+6:])
+			length := int(binary.BigEndian.Uint16(name[rec+8:]))
+			offset := storage + int(binary.BigEndian.Uint16(name[rec+10:]))
+			if nameID != 6 || offset+length > len(name) {
+				continue
+			}
+			raw := name[offset : offset+length]
+			var b strings.Builder
+			if platform == 3 || platform == 0 { // UTF-16BE
+				for j := 0; j+1 < len(raw); j += 2 {
+					b.WriteRune(rune(binary.BigEndian.Uint16(raw[j:])))
+				}
+			} else {
+				b.Write(raw)
+			}
+			if s := sanitizeName(b.String()); s != "" {
+				return s
+			}
+		}
+	}
+	return "EmbeddedFont"
+}
+
+func sanitizeName(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 32 && r < 127 && !strings.ContainsRune("[](){}<>/%#", r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// trueTypeFont embeds a TrueType font as a Type0/CIDFontType2 font with Identity-H encoding;
+// text is written as glyph IDs and a ToUnicode map keeps it searchable
+type trueTypeFont struct {
+	font *TrueType
+	tag  string
+	used map[uint16]rune
+}
+
+func newTrueTypeFont(font *TrueType, tag string) *trueTypeFont {
+	return &trueTypeFont{font: font, tag: tag, used: make(map[uint16]rune)}
+}
+
+func (f *trueTypeFont) glyph(r rune) uint16 {
+	if r == '\t' || r == '\n' {
+		r = ' '
+	}
+	g, ok := f.font.cmap[r]
+	if !ok {
+		g = f.font.cmap['?']
+	}
+	return g
+}
+
+func (f *trueTypeFont) encode(s string) string {
+	var b strings.Builder
+	b.WriteByte('<')
+	for _, r := range s {
+		g := f.glyph(r)
+		if _, ok := f.used[g]; !ok {
+			f.used[g] = r
+		}
+		fmt.Fprintf(&b, "%04X", g)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+func (f *trueTypeFont) width(s string) float64 {
+	total := 0.0
+	for _, r := range s {
+		g := f.glyph(r)
+		if int(g) < len(f.font.advances) {
+			total += float64(f.font.advances[g])
+		}
+	}
+	return total * 1000 / float64(f.font.unitsPerEm)
+}
+
+func (f *trueTypeFont) scale(v int) int {
+	return v * 1000 / f.font.unitsPerEm
+}
+
+func (f *trueTypeFont) write(w *objWriter) int {
+	tt := f.font
+	// Tag prefix keeps the two embedded faces distinct even when they share a file (F1 -> AAAAAA+, F2 -> BAAAAA+)
+	baseFont := fmt.Sprintf("%cAAAAA+%s", 'A'+f.tag[1]-'1', tt.name)
+
+	fileID := w.stream(fmt.Sprintf(" /Length1 %d", len(tt.data)), tt.data, true)
+	descriptorID := w.object(fmt.Sprintf(
+		"<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		baseFont, f.scale(tt.bbox[0]), f.scale(tt.bbox[1]), f.scale(tt.bbox[2]), f.scale(tt.bbox[3]),
+		f.scale(tt.ascent), f.scale(tt.descent), f.scale(tt.ascent), fileID))
+
+	glyphs := make([]int, 0, len(f.used))
+	for g := range f.used {
+		glyphs = append(glyphs, int(g))
+	}
+	sort.Ints(glyphs)
+
+	var widths strings.Builder
+	for _, g := range glyphs {
+		fmt.Fprintf(&widths, "%d [%d] ", g, f.scale(int(tt.advances[g])))
+	}
+	cidFontID := w.object(fmt.Sprintf(
+		"<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /DW 1000 /W [%s] /CIDToGIDMap /Identity >>",
+		baseFont, descriptorID, strings.TrimSpace(widths.String())))
+
+	var cmap strings.Builder
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	cmap.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	cmap.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	cmap.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for i := 0; i < len(glyphs); i += 100 {
+		end := i + 100
+		if end > len(glyphs) {
+			end = len(glyphs)
+		}
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", end-i)
+		for _, g := range glyphs[i:end] {
+			fmt.Fprintf(&cmap, "<%04X> <%s>\n", g, utf16Hex(f.used[uint16(g)]))
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	toUnicodeID := w.stream("", []byte(cmap.String()), true)
+
+	return w.object(fmt.Sprintf(
+		"<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		baseFont, cidFontID, toUnicodeID))
+}
+
+func utf16Hex(r rune) string {
+	if r < 0x10000 {
+		return fmt.Sprintf("%04X", r)
+	}
+	r -= 0x10000
+	return fmt.Sprintf("%04X%04X", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+}
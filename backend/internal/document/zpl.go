@@ -0,0 +1,125 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+)
+
+// Barcode symbologies for ZPL labels. PDF sheets always print Code 128 / GS1-128.
+const (
+	BarcodeCode128    = "code128"
+	BarcodeQR         = "qr"
+	BarcodeDataMatrix = "datamatrix"
+)
+
+// DefaultZPLTemplate prints the title, up to the lines that fit and the barcode at the bottom
+const DefaultZPLTemplate = `^XA
+^CI28
+^PW{{.WidthDots}}
+^LL{{.HeightDots}}
+^FO{{.Dots 2}},{{.Dots 2}}^A0N,{{.Dots 5}},{{.Dots 5}}^FH^FD{{zpl .Title}}^FS
+{{range $i, $line := .Lines}}{{if $.LineFits $i}}^FO{{$.Dots 2}},{{$.LineY $i}}^A0N,{{$.Dots 3}},{{$.Dots 3}}^FH^FD{{zpl $line}}^FS
+{{end}}{{end}}^FO{{.Dots 2}},{{.BarcodeY}}{{.BarcodeField}}
+^XZ
+`
+
+// ZPLLabel is the data a ZPL template is executed with. Sizes are in dots; Dots converts mm.
+type ZPLLabel struct {
+	Label
+	WidthDots  int
+	HeightDots int
+	Dpmm       int
+	Symbology  string
+	// BarcodeField is the complete barcode command (^BC/^BQ/^BX ... ^FS) for the payload
+	BarcodeField string
+	// BarcodeY is the top of the barcode in the default layout
+	BarcodeY int
+}
+
+// Dots converts millimetres to printer dots
+func (l ZPLLabel) Dots(mm float64) int {
+	return int(math.Round(mm * float64(l.Dpmm)))
+}
+
+// LineY is the top of text line i below the title
+func (l ZPLLabel) LineY(i int) int {
+	return l.Dots(8.5 + float64(i)*3.8)
+}
+
+// LineFits reports whether text line i ends above the barcode
+func (l ZPLLabel) LineFits(i int) bool {
+	return l.LineY(i)+l.Dots(3) < l.BarcodeY
+}
+
+var zplFuncs = template.FuncMap{"zpl": zplEscape}
+
+// ParseZPLTemplate checks a ZPL template body; an empty body means DefaultZPLTemplate
+func ParseZPLTemplate(body string) (*template.Template, error) {
+	if strings.TrimSpace(body) == "" {
+		body = DefaultZPLTemplate
+	}
+	return template.New("label").Funcs(zplFuncs).Parse(body)
+}
+
+// RenderZPL executes the template once per label of widthMM x heightMM at dpmm dots per mm
+// (8 = 203 dpi, 12 = 300 dpi) and concatenates the ^XA...^XZ blocks
+func RenderZPL(labels []Label, body string, widthMM, heightMM float64, dpmm int, symbology string) ([]byte, error) {
+	tmpl, err := ParseZPLTemplate(body)
+	if err != nil {
+		return nil, err
+	}
+	if dpmm <= 0 {
+		dpmm = 8
+	}
+	var out bytes.Buffer
+	for _, label := range labels {
+		data := ZPLLabel{Label: label, Dpmm: dpmm, Symbology: symbology}
+		data.WidthDots = data.Dots(widthMM)
+		data.HeightDots = data.Dots(heightMM)
+		data.BarcodeField, data.BarcodeY = zplBarcode(data, heightMM)
+		if err := tmpl.Execute(&out, data); err != nil {
+			return nil, fmt.Errorf("label template: %w", err)
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// zplBarcode builds the barcode command; GS1-128 uses ^BC mode D, which takes the bracketed
+// form and inserts FNC1 itself. QR and DataMatrix carry the bracketed form as text.
+func zplBarcode(l ZPLLabel, heightMM float64) (string, int) {
+	if l.Barcode == "" {
+		return "", l.HeightDots
+	}
+	switch l.Symbology {
+	case BarcodeQR:
+		magnification := 2 + int(heightMM/25)
+		if magnification > 10 {
+			magnification = 10
+		}
+		size := magnification * 33 // a version 4 symbol including the quiet zone
+		return fmt.Sprintf("^BQN,2,%d^FH^FDQA,%s^FS", magnification, zplEscape(l.Barcode)), l.HeightDots - size
+	case BarcodeDataMatrix:
+		module := l.Dots(0.5)
+		if module < 2 {
+			module = 2
+		}
+		size := module * 26
+		return fmt.Sprintf("^BXN,%d,200^FH^FD%s^FS", module, zplEscape(l.Barcode)), l.HeightDots - l.Dots(2) - size
+	default:
+		height := l.Dots(heightMM * 0.3)
+		top := l.HeightDots - l.Dots(2) - l.Dots(3) - height
+		if IsGS1Bracketed(l.Barcode) {
+			return fmt.Sprintf("^BY2^BCN,%d,Y,N,N,D^FD%s^FS", height, l.Barcode), top
+		}
+		return fmt.Sprintf("^BY2^BCN,%d,Y,N,N,A^FH^FD%s^FS", height, zplEscape(l.Barcode)), top
+	}
+}
+
+// zplEscape hex-escapes the characters that would end or redirect a ^FH^FD field
+func zplEscape(s string) string {
+	r := strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E")
+	return r.Replace(s)
+}
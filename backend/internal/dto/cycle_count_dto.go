@@ -0,0 +1,63 @@
+package dto
+
+// SaveCycleCountPolicyRequest creates or updates the cycle count policy of a warehouse;
+// omitted fields keep their current (or default) value
+type SaveCycleCountPolicyRequest struct {
+	WarehouseID         uint     `json:"warehouse_id" binding:"required"`
+	AValueShare         *float64 `json:"a_value_share" binding:"omitempty,gt=0,lte=100"`
+	BValueShare         *float64 `json:"b_value_share" binding:"omitempty,gt=0,lte=100"`
+	AFrequencyDays      *int     `json:"a_frequency_days" binding:"omitempty,min=1"`
+	BFrequencyDays      *int     `json:"b_frequency_days" binding:"omitempty,min=1"`
+	CFrequencyDays      *int     `json:"c_frequency_days" binding:"omitempty,min=1"`
+	LookbackDays        *int     `json:"lookback_days" binding:"omitempty,min=1"`
+	RecountThresholdPct *float64 `json:"recount_threshold_pct" binding:"omitempty,gte=0"`
+	RecountThresholdQty *float64 `json:"recount_threshold_qty" binding:"omitempty,gte=0"`
+	AutoSchedule        *bool    `json:"auto_schedule"`
+}
+
+// ABCClassificationResult summarises an ABC run over a warehouse
+type ABCClassificationResult struct {
+	WarehouseID uint           `json:"warehouse_id"`
+	Since       string         `json:"since"`
+	TotalValue  float64        `json:"total_value"`
+	Items       int            `json:"items"`
+	Classes     map[string]int `json:"classes"`
+}
+
+// GenerateCycleCountsRequest creates count tasks for the items of a warehouse that are due.
+// ABCClass limits the run to one class; ScheduledDate defaults to today (YYYY-MM-DD).
+type GenerateCycleCountsRequest struct {
+	WarehouseID   uint   `json:"warehouse_id" binding:"required"`
+	ABCClass      string `json:"abc_class" binding:"omitempty,oneof=A B C"`
+	ScheduledDate string `json:"scheduled_date"`
+	MaxTasks      int    `json:"max_tasks" binding:"omitempty,min=1"`
+}
+
+// GenerateCycleCountsResult reports the tasks created by a generation run
+type GenerateCycleCountsResult struct {
+	WarehouseID uint `json:"warehouse_id"`
+	Items       int  `json:"items"`
+	Tasks       int  `json:"tasks"`
+}
+
+// EnterCycleCountRequest records a blind count of a task
+type EnterCycleCountRequest struct {
+	CountedQuantity *float64 `json:"counted_quantity" binding:"required,gte=0"`
+	SerialNumbers   []string `json:"serial_numbers"` // serials counted of a serial-tracked item
+	Notes           string   `json:"notes"`
+}
+
+// ApproveCycleCountsRequest approves counted tasks of one warehouse; their variances go
+// into a single draft stock adjustment
+type ApproveCycleCountsRequest struct {
+	TaskIDs []uint `json:"task_ids" binding:"required,min=1"`
+	Reason  string `json:"reason"`
+	Notes   string `json:"notes"`
+}
+
+// CycleCountApprovalResult is the draft adjustment created for the approved variances
+type CycleCountApprovalResult struct {
+	Approved          int    `json:"approved"`
+	StockAdjustmentID uint   `json:"stock_adjustment_id"`
+	AdjustmentNumber  string `json:"adjustment_number"`
+}
@@ -18,6 +18,10 @@ type CreateDeliveryOrderItem struct {
 	WarehouseLocationID *uint  `json:"warehouse_location_id"`
 	BatchNumber       string  `json:"batch_number"`
 	LotNumber         string  `json:"lot_number"`
+	// For a kit: pick the components instead of assembled kits (nil = the kit's own setting)
+	ExplodeKit        *bool   `json:"explode_kit"`
+	// Serials selected for serial-tracked products (for an exploded kit, its components' serials)
+	SerialNumbers     []string `json:"serial_numbers"`
 	Notes             string  `json:"notes"`
 }
 
@@ -47,4 +51,6 @@ type UpdateDeliveryOrderRequest struct {
 type ShipDeliveryOrderRequest struct {
 	TrackingNumber string `json:"tracking_number"`
 	Notes          string `json:"notes"`
+	// Serials scanned at dispatch; when given they replace the ones selected on the lines
+	SerialNumbers []string `json:"serial_numbers"`
 }
@@ -0,0 +1,33 @@
+package dto
+
+// UpsertExchangeRateRequest creates or overwrites the rate for a currency on a date
+type UpsertExchangeRateRequest struct {
+	Currency string  `json:"currency" binding:"required,len=3"`
+	RateDate string  `json:"rate_date" binding:"required"` // YYYY-MM-DD
+	Rate     float64 `json:"rate" binding:"required,gt=0"` // VND per 1 unit
+	Notes    string  `json:"notes"`
+}
+
+// ExchangeRateImportResult summarizes a rate file import
+type ExchangeRateImportResult struct {
+	Imported int      `json:"imported"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// PurchaseCurrencyReportRow shows a PO in its document currency and in VND
+type PurchaseCurrencyReportRow struct {
+	PurchaseOrderID  uint    `json:"purchase_order_id"`
+	PONumber         string  `json:"po_number"`
+	SupplierCode     string  `json:"supplier_code"`
+	SupplierName     string  `json:"supplier_name"`
+	OrderDate        string  `json:"order_date"`
+	Currency         string  `json:"currency"`
+	OrderRate        float64 `json:"order_rate"`
+	TotalAmount      float64 `json:"total_amount"`       // document currency
+	TotalBaseAmount  float64 `json:"total_base_amount"`  // VND at order rate
+	ReceivedAmount   float64 `json:"received_amount"`    // posted GRNs, document currency
+	ReceivedBase     float64 `json:"received_base"`      // posted GRNs, VND at GRN rates (inventory cost)
+	PaidAmount       float64 `json:"paid_amount"`        // document currency
+	PaidBase         float64 `json:"paid_base"`          // VND actually paid
+	RealizedFXAmount float64 `json:"realized_fx_amount"` // > 0 loss, < 0 gain
+}
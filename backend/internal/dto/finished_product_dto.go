@@ -17,6 +17,9 @@ type CreateFinishedProductRequest struct {
 	NetWeight   *float64 `json:"net_weight" binding:"omitempty,min=0"`
 	GrossWeight *float64 `json:"gross_weight" binding:"omitempty,min=0"`
 	Volume      *float64 `json:"volume" binding:"omitempty,min=0"`
+
+	UnitVolumeM3   *float64 `json:"unit_volume_m3" binding:"omitempty,gt=0"`
+	UnitsPerPallet *float64 `json:"units_per_pallet" binding:"omitempty,gt=0"`
 	
 	// Stock control
 	MinStockLevel *float64 `json:"min_stock_level" binding:"omitempty,min=0"`
@@ -27,6 +30,7 @@ type CreateFinishedProductRequest struct {
 	ShelfLifeDays     *int   `json:"shelf_life_days" binding:"omitempty,min=0"`
 	StorageConditions string `json:"storage_conditions" binding:"omitempty"`
 	Barcode           string `json:"barcode" binding:"omitempty,max=100"`
+	SerialTracked     bool   `json:"serial_tracked"`
 	
 	// Status
 	IsActive bool   `json:"is_active"`
@@ -50,6 +54,9 @@ type UpdateFinishedProductRequest struct {
 	NetWeight   *float64 `json:"net_weight" binding:"omitempty,min=0"`
 	GrossWeight *float64 `json:"gross_weight" binding:"omitempty,min=0"`
 	Volume      *float64 `json:"volume" binding:"omitempty,min=0"`
+
+	UnitVolumeM3   *float64 `json:"unit_volume_m3" binding:"omitempty,gt=0"`
+	UnitsPerPallet *float64 `json:"units_per_pallet" binding:"omitempty,gt=0"`
 	
 	// Stock control
 	MinStockLevel *float64 `json:"min_stock_level" binding:"omitempty,min=0"`
@@ -60,6 +67,7 @@ type UpdateFinishedProductRequest struct {
 	ShelfLifeDays     *int   `json:"shelf_life_days" binding:"omitempty,min=0"`
 	StorageConditions string `json:"storage_conditions" binding:"omitempty"`
 	Barcode           string `json:"barcode" binding:"omitempty,max=100"`
+	SerialTracked     *bool  `json:"serial_tracked"`
 	
 	// Status
 	IsActive *bool  `json:"is_active" binding:"omitempty"`
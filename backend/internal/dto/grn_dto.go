@@ -13,17 +13,20 @@ type CreateGRNItemRequest struct {
 	LotNumber           string  `json:"lot_number"`
 	ManufactureDate     string  `json:"manufacture_date"` // YYYY-MM-DD
 	ExpiryDate          string  `json:"expiry_date"`      // YYYY-MM-DD
+	// One per accepted unit for a serial-tracked material; may also be given at QC
+	SerialNumbers       []string `json:"serial_numbers"`
 	Notes               string  `json:"notes"`
 }
 
 // CreateGRNRequest represents the request to create a GRN
 type CreateGRNRequest struct {
-	GRNNumber       string                 `json:"grn_number" binding:"required,min=2,max=50"`
-	PurchaseOrderID uint                   `json:"purchase_order_id" binding:"required"`
-	WarehouseID     uint                   `json:"warehouse_id" binding:"required"`
-	ReceiptDate     string                 `json:"receipt_date" binding:"required"` // YYYY-MM-DD
-	Notes           string                 `json:"notes"`
-	Items           []CreateGRNItemRequest `json:"items" binding:"required,min=1,dive"`
+	GRNNumber        string                 `json:"grn_number" binding:"required,min=2,max=50"`
+	PurchaseOrderID  uint                   `json:"purchase_order_id" binding:"required"`
+	WarehouseID      uint                   `json:"warehouse_id" binding:"required"`
+	ReceiptDate      string                 `json:"receipt_date" binding:"required"` // YYYY-MM-DD
+	PurchaseReturnID *uint                  `json:"purchase_return_id"`              // replacement delivery for a purchase return
+	Notes            string                 `json:"notes"`
+	Items            []CreateGRNItemRequest `json:"items" binding:"required,min=1,dive"`
 }
 
 // UpdateGRNQCItemRequest represents the QC update for a single item
@@ -33,6 +36,7 @@ type UpdateGRNQCItemRequest struct {
 	RejectedQuantity float64  `json:"rejected_quantity" binding:"required,gte=0"`
 	QCStatus         string   `json:"qc_status" binding:"required"` // pass, fail, partial
 	QCNotes          string   `json:"qc_notes"`
+	SerialNumbers    []string `json:"serial_numbers"` // serials of the accepted units (nil = keep those captured on the line)
 }
 
 // UpdateGRNQCRequest represents the bulk QC update for a GRN
@@ -0,0 +1,42 @@
+package dto
+
+// KitComponentRequest is the quantity of a component product that goes into one kit
+type KitComponentRequest struct {
+	ComponentProductID uint    `json:"component_product_id" binding:"required"`
+	Quantity           float64 `json:"quantity" binding:"required,gt=0"`
+	Notes              string  `json:"notes" binding:"max=1000"`
+}
+
+// CreateKitRequest defines a finished product as a kit of other finished products
+type CreateKitRequest struct {
+	KitProductID  uint                  `json:"kit_product_id" binding:"required"`
+	ExplodeOnPick bool                  `json:"explode_on_pick"`
+	IsActive      *bool                 `json:"is_active"`
+	Components    []KitComponentRequest `json:"components" binding:"required,min=1,dive"`
+	Notes         string                `json:"notes" binding:"max=1000"`
+}
+
+// UpdateKitRequest changes a kit; when Components is given it replaces the whole list. Orders
+// already created keep the components they were created with.
+type UpdateKitRequest struct {
+	ExplodeOnPick *bool                 `json:"explode_on_pick"`
+	IsActive      *bool                 `json:"is_active"`
+	Components    []KitComponentRequest `json:"components" binding:"omitempty,min=1,dive"`
+	Notes         *string               `json:"notes" binding:"omitempty,max=1000"`
+}
+
+// CreateKitOrderRequest assembles or disassembles a quantity of kits. For an assembly the kits are
+// received at KitLocationID under KitBatchNumber (default: the order number) and the components are
+// taken FEFO, from ComponentLocationID only when it is given. A disassembly takes the kits FEFO,
+// or from the given location/batch, and puts the components at ComponentLocationID.
+type CreateKitOrderRequest struct {
+	OrderType           string  `json:"order_type" binding:"required,oneof=assembly disassembly"`
+	KitID               uint    `json:"kit_id" binding:"required"`
+	WarehouseID         uint    `json:"warehouse_id" binding:"required"`
+	Quantity            float64 `json:"quantity" binding:"required,gt=0"`
+	KitLocationID       *uint   `json:"kit_location_id"`
+	KitBatchNumber      string  `json:"kit_batch_number" binding:"max=100"`
+	KitLotNumber        string  `json:"kit_lot_number" binding:"max=100"`
+	ComponentLocationID *uint   `json:"component_location_id"`
+	Notes               string  `json:"notes" binding:"max=1000"`
+}
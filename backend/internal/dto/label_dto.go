@@ -0,0 +1,61 @@
+package dto
+
+// SaveLabelTemplateRequest creates or replaces a label template. Body is a ZPL template
+// (Go text/template, empty = built-in layout); the sheet fields place PDF labels on A4.
+type SaveLabelTemplateRequest struct {
+	Code         string  `json:"code" binding:"required,max=50"`
+	Name         string  `json:"name" binding:"required"`
+	LabelType    string  `json:"label_type" binding:"required,oneof=grn_item fprn_batch location pallet"`
+	Format       string  `json:"format" binding:"required,oneof=zpl pdf"`
+	WidthMM      float64 `json:"width_mm" binding:"required,gt=0"`
+	HeightMM     float64 `json:"height_mm" binding:"required,gt=0"`
+	Dpmm         int     `json:"dpmm" binding:"omitempty,oneof=6 8 12 24"`
+	BarcodeType  string  `json:"barcode_type" binding:"omitempty,oneof=code128 qr datamatrix"`
+	Body         string  `json:"body"`
+	SheetColumns int     `json:"sheet_columns" binding:"omitempty,min=1"`
+	SheetRows    int     `json:"sheet_rows" binding:"omitempty,min=1"`
+	MarginLeftMM float64 `json:"margin_left_mm" binding:"gte=0"`
+	MarginTopMM  float64 `json:"margin_top_mm" binding:"gte=0"`
+	GapXMM       float64 `json:"gap_x_mm" binding:"gte=0"`
+	GapYMM       float64 `json:"gap_y_mm" binding:"gte=0"`
+	IsDefault    bool    `json:"is_default"`
+	IsActive     *bool   `json:"is_active"`
+}
+
+// PalletLabelRequest describes the pallets to label; each pallet gets its own SSCC
+type PalletLabelRequest struct {
+	ItemType    string  `json:"item_type" binding:"required,oneof=material finished_product"`
+	ItemID      uint    `json:"item_id" binding:"required"`
+	BatchNumber string  `json:"batch_number"`
+	ExpiryDate  *string `json:"expiry_date"`
+	Quantity    float64 `json:"quantity" binding:"required,gt=0"` // per pallet
+	Pallets     int     `json:"pallets" binding:"omitempty,min=1,max=500"`
+}
+
+// PrintLabelsRequest renders labels of one type as ZPL or PDF.
+//   - grn_item: GRNID, optionally limited to ItemIDs
+//   - fprn_batch: FPRNID, optionally limited to ItemIDs
+//   - location: LocationIDs, or every active location of WarehouseID
+//   - pallet: Pallet
+//
+// Format defaults to the template's format, then zpl; Copies prints each label several times.
+type PrintLabelsRequest struct {
+	LabelType   string              `json:"label_type" binding:"required,oneof=grn_item fprn_batch location pallet"`
+	Format      string              `json:"format" binding:"omitempty,oneof=zpl pdf"`
+	TemplateID  *uint               `json:"template_id"`
+	Lang        string              `json:"lang"`
+	Copies      int                 `json:"copies" binding:"omitempty,min=1,max=100"`
+	GRNID       uint                `json:"grn_id"`
+	FPRNID      uint                `json:"fprn_id"`
+	ItemIDs     []uint              `json:"item_ids"`
+	LocationIDs []uint              `json:"location_ids"`
+	WarehouseID uint                `json:"warehouse_id"`
+	Pallet      *PalletLabelRequest `json:"pallet"`
+}
+
+// ReprintLabelsRequest prints a logged job again with the same content
+type ReprintLabelsRequest struct {
+	Reason     string `json:"reason" binding:"required"`
+	Copies     int    `json:"copies" binding:"omitempty,min=1,max=100"`
+	TemplateID *uint  `json:"template_id"` // another template of the same type, e.g. after a printer change
+}
@@ -0,0 +1,19 @@
+package dto
+
+// LandedCostChargeRequest represents a single charge line on a landed cost voucher
+type LandedCostChargeRequest struct {
+	ChargeType  string  `json:"charge_type" binding:"required,oneof=freight import_duty customs_fee insurance other"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// CreateLandedCostVoucherRequest represents the request to create a landed cost voucher
+type CreateLandedCostVoucherRequest struct {
+	VoucherDate      string                    `json:"voucher_date" binding:"required"` // YYYY-MM-DD
+	AllocationMethod string                    `json:"allocation_method" binding:"required,oneof=quantity value weight"`
+	GRNIDs           []uint                    `json:"grn_ids" binding:"required,min=1"`
+	Charges          []LandedCostChargeRequest `json:"charges" binding:"required,min=1,dive"`
+	// Weights keyed by GRN item ID, required when allocation_method = weight
+	Weights map[uint]float64 `json:"weights"`
+	Notes   string           `json:"notes"`
+}
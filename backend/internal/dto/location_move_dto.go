@@ -0,0 +1,75 @@
+package dto
+
+// LocationMoveItemRequest moves one item, batch and lot from one location to another. Moving only
+// part of the serials of a serial-tracked item at the source needs the serials of the units moved.
+type LocationMoveItemRequest struct {
+	ItemType       string   `json:"item_type" binding:"required,oneof=material finished_product"`
+	ItemID         uint     `json:"item_id" binding:"required"`
+	BatchNumber    string   `json:"batch_number" binding:"max=100"`
+	LotNumber      string   `json:"lot_number" binding:"max=100"`
+	FromLocationID uint     `json:"from_location_id" binding:"required"`
+	ToLocationID   uint     `json:"to_location_id" binding:"required"`
+	Quantity       float64  `json:"quantity" binding:"required,gt=0"`
+	SerialNumbers  []string `json:"serial_numbers"`
+	Notes          string   `json:"notes" binding:"max=1000"`
+}
+
+// CreateLocationMoveRequest creates a move between locations of one warehouse. With Post the
+// stock moves right away; otherwise the move stays a draft until it is posted.
+type CreateLocationMoveRequest struct {
+	WarehouseID uint                      `json:"warehouse_id" binding:"required"`
+	Source      string                    `json:"source" binding:"omitempty,oneof=manual consolidation"`
+	Items       []LocationMoveItemRequest `json:"items" binding:"required,min=1,dive"`
+	Notes       string                    `json:"notes" binding:"max=1000"`
+	Post        bool                      `json:"post"`
+}
+
+// ScanLocationMoveRequest moves what the operator scanned: the source location, the item (or lot
+// label) and the target location. Quantity defaults to everything movable of the item at the
+// source; when the source holds several batches, scan a label that carries the batch.
+type ScanLocationMoveRequest struct {
+	WarehouseID  uint     `json:"warehouse_id" binding:"required"`
+	FromLocation string   `json:"from_location" binding:"required"`
+	ItemScan     string   `json:"item_scan" binding:"required"`
+	ToLocation   string   `json:"to_location" binding:"required"`
+	Quantity     *float64 `json:"quantity" binding:"omitempty,gt=0"`
+	// SerialNumbers of the units moved, for part of a serial-tracked item
+	SerialNumbers []string `json:"serial_numbers"`
+	Notes         string   `json:"notes" binding:"max=1000"`
+}
+
+// ConsolidationFilterRequest selects the stock to look at for consolidation. An item counts when
+// it is spread over at least MinLocations storage locations (default 2).
+type ConsolidationFilterRequest struct {
+	WarehouseID  uint   `form:"warehouse_id" binding:"required"`
+	ItemType     string `form:"item_type" binding:"omitempty,oneof=material finished_product"`
+	ItemID       uint   `form:"item_id"`
+	MinLocations int    `form:"min_locations" binding:"omitempty,min=2"`
+}
+
+// ConsolidationLocation is one of the locations holding the item
+type ConsolidationLocation struct {
+	LocationID   uint     `json:"location_id"`
+	LocationCode string   `json:"location_code"`
+	Quantity     float64  `json:"quantity"`
+	Movable      float64  `json:"movable"`                // not reserved or allocated to pick lists
+	FillPercent  *float64 `json:"fill_percent,omitempty"` // nil when the location has no capacity limit
+}
+
+// ConsolidationSuggestion gathers an item, batch and lot spread over several partially-filled
+// locations into the one holding most of it. Moves can be posted as they are with
+// CreateLocationMoveRequest (source "consolidation"); LocationsFreed counts the locations left empty.
+type ConsolidationSuggestion struct {
+	ItemType           string                    `json:"item_type"`
+	ItemID             uint                      `json:"item_id"`
+	ItemCode           string                    `json:"item_code"`
+	ItemName           string                    `json:"item_name"`
+	BatchNumber        string                    `json:"batch_number,omitempty"`
+	LotNumber          string                    `json:"lot_number,omitempty"`
+	TotalQuantity      float64                   `json:"total_quantity"`
+	Locations          []ConsolidationLocation   `json:"locations"`
+	TargetLocationID   uint                      `json:"target_location_id"`
+	TargetLocationCode string                    `json:"target_location_code"`
+	Moves              []LocationMoveItemRequest `json:"moves"`
+	LocationsFreed     int                       `json:"locations_freed"`
+}
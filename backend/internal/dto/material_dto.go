@@ -2,11 +2,13 @@ package dto
 
 // MaterialSupplierInput represents a supplier entry when creating/updating a material
 type MaterialSupplierInput struct {
-	SupplierID   int64    `json:"supplier_id" binding:"required"`
-	Priority     int      `json:"priority" binding:"required,gte=1"`
-	UnitPrice    *float64 `json:"unit_price" binding:"omitempty,gte=0"`
-	LeadTimeDays *int     `json:"lead_time_days" binding:"omitempty,gte=0"`
-	Notes        *string  `json:"notes"`
+	SupplierID       int64    `json:"supplier_id" binding:"required"`
+	Priority         int      `json:"priority" binding:"required,gte=1"`
+	UnitPrice        *float64 `json:"unit_price" binding:"omitempty,gte=0"`
+	LeadTimeDays     *int     `json:"lead_time_days" binding:"omitempty,gte=0"`
+	MinOrderQuantity *float64 `json:"min_order_quantity" binding:"omitempty,gte=0"`
+	PackSize         *float64 `json:"pack_size" binding:"omitempty,gt=0"`
+	Notes            *string  `json:"notes"`
 }
 
 // CreateMaterialRequest represents the request body for creating a material
@@ -29,9 +31,18 @@ type CreateMaterialRequest struct {
 	ShelfLifeDays     *int                    `json:"shelf_life_days" binding:"omitempty,gte=0"`
 	StorageConditions *string                 `json:"storage_conditions"`
 	Hazardous         bool                    `json:"hazardous"`
+	SerialTracked     bool                    `json:"serial_tracked"`
 	IsActive          bool                    `json:"is_active"`
 	Notes             *string                 `json:"notes"`
 	Suppliers         []MaterialSupplierInput `json:"suppliers"`
+
+	// Receiving tolerances in percent; nil = use the supplier's
+	OverReceiptTolerance  *float64 `json:"over_receipt_tolerance" binding:"omitempty,gte=0,lte=100"`
+	UnderReceiptTolerance *float64 `json:"under_receipt_tolerance" binding:"omitempty,gte=0,lte=100"`
+
+	UnitWeightKg   *float64 `json:"unit_weight_kg" binding:"omitempty,gt=0"`
+	UnitVolumeM3   *float64 `json:"unit_volume_m3" binding:"omitempty,gt=0"`
+	UnitsPerPallet *float64 `json:"units_per_pallet" binding:"omitempty,gt=0"`
 }
 
 // UpdateMaterialRequest represents the request body for updating a material
@@ -53,9 +64,18 @@ type UpdateMaterialRequest struct {
 	ShelfLifeDays     *int                     `json:"shelf_life_days" binding:"omitempty,gte=0"`
 	StorageConditions *string                  `json:"storage_conditions"`
 	Hazardous         *bool                    `json:"hazardous"`
+	SerialTracked     *bool                    `json:"serial_tracked"`
 	IsActive          *bool                    `json:"is_active"`
 	Notes             *string                  `json:"notes"`
 	Suppliers         *[]MaterialSupplierInput `json:"suppliers"` // nil = don't touch; empty slice = remove all
+
+	// Receiving tolerances in percent; nil = use the supplier's
+	OverReceiptTolerance  *float64 `json:"over_receipt_tolerance" binding:"omitempty,gte=0,lte=100"`
+	UnderReceiptTolerance *float64 `json:"under_receipt_tolerance" binding:"omitempty,gte=0,lte=100"`
+
+	UnitWeightKg   *float64 `json:"unit_weight_kg" binding:"omitempty,gt=0"`
+	UnitVolumeM3   *float64 `json:"unit_volume_m3" binding:"omitempty,gt=0"`
+	UnitsPerPallet *float64 `json:"units_per_pallet" binding:"omitempty,gt=0"`
 }
 
 // MaterialFilterRequest represents query parameters for filtering materials
@@ -0,0 +1,105 @@
+package dto
+
+import "time"
+
+// MobileItem is the item of a task or step, as shown on a handheld
+type MobileItem struct {
+	Type string `json:"type"` // material, finished_product
+	ID   uint   `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// MobileTask is the compact list entry of a putaway task, pick list, cycle count task or stock transfer.
+// Item, From, To, Batch and Quantity are filled for single-step tasks; counts never carry a quantity
+// because they are counted blind.
+type MobileTask struct {
+	Type           string      `json:"type"` // putaway, pick, count, transfer, receive
+	ID             uint        `json:"id"`
+	Number         string      `json:"number"`
+	Status         string      `json:"status"`
+	WarehouseID    uint        `json:"warehouse_id"`
+	AssignedTo     *uint       `json:"assigned_to,omitempty"`
+	AssignedToName string      `json:"assigned_to_name,omitempty"`
+	Item           *MobileItem `json:"item,omitempty"`
+	From           string      `json:"from,omitempty"`
+	To             string      `json:"to,omitempty"`
+	Batch          string      `json:"batch,omitempty"`
+	Quantity       *float64    `json:"quantity,omitempty"`
+	StepCount      int         `json:"step_count"`
+	StepsDone      int         `json:"steps_done"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// MobileStep is one thing to scan and confirm: the putaway or count itself (ID 0),
+// a pick list line or a stock transfer item
+type MobileStep struct {
+	ID           uint        `json:"id"`
+	Sequence     int         `json:"seq"`
+	Item         *MobileItem `json:"item,omitempty"`
+	From         string      `json:"from,omitempty"`
+	To           string      `json:"to,omitempty"`
+	Batch        string      `json:"batch,omitempty"`
+	Lot          string      `json:"lot,omitempty"`
+	Expiry       *string     `json:"expiry,omitempty"`
+	Quantity     *float64    `json:"quantity,omitempty"`
+	DoneQuantity *float64    `json:"done_quantity,omitempty"`
+	Status       string      `json:"status"` // pending, done, short
+	Reference    string      `json:"ref,omitempty"`
+}
+
+// MobileTaskDetail is a task with its steps
+type MobileTaskDetail struct {
+	MobileTask
+	Steps []MobileStep `json:"steps"`
+}
+
+// AssignMobileTaskRequest hands a task to an operator
+type AssignMobileTaskRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// ConfirmMobileStepRequest confirms one step with what the operator scanned. RequestID is generated
+// by the device and reused on every retry of the same step (the Idempotency-Key header works too).
+//   - putaway: LocationScan of the storage location; ItemScan optional; Quantity defaults to the remainder
+//   - pick: StepID = line, ItemScan required; another location or batch than allocated is a substitution,
+//     a lower Quantity a short pick
+//   - count: ItemScan, LocationScan when the task has a location, Quantity = counted quantity
+//   - transfer: StepID = item, ItemScan required; the transfer ships when the last item is confirmed
+//   - receive: StepID = item, ItemScan required, LocationScan optional to put it elsewhere than planned;
+//     Quantity defaults to what was shipped, a lower one needs a ShortReason; the transfer is received
+//     with the last item
+type ConfirmMobileStepRequest struct {
+	RequestID    string   `json:"request_id" binding:"max=64"`
+	StepID       uint     `json:"step_id"`
+	LocationScan string   `json:"location_scan"`
+	ItemScan     string   `json:"item_scan"`
+	Quantity     *float64 `json:"quantity" binding:"omitempty,gte=0"`
+	ShortReason  string   `json:"short_reason" binding:"max=255"`
+	Notes        string   `json:"notes" binding:"max=1000"`
+}
+
+// MobileStepResult is the answer to a step confirmation; Replayed is set when it was
+// returned from an earlier confirmation with the same request ID
+type MobileStepResult struct {
+	Task      MobileTask `json:"task"`
+	StepID    uint       `json:"step_id"`
+	Completed bool       `json:"completed"`
+	Replayed  bool       `json:"replayed"`
+}
+
+// ReportMobileExceptionRequest reports a problem with a task or one of its steps.
+// Release hands the task back so that someone else can pick it up.
+type ReportMobileExceptionRequest struct {
+	ExceptionType string   `json:"exception_type" binding:"required,oneof=damaged missing wrong_item location_blocked short other"`
+	StepID        uint     `json:"step_id"`
+	Quantity      *float64 `json:"quantity" binding:"omitempty,gte=0"`
+	ScannedCode   string   `json:"scanned_code" binding:"max=255"`
+	Notes         string   `json:"notes" binding:"max=1000"`
+	Release       bool     `json:"release"`
+}
+
+// ResolveMobileExceptionRequest closes a reported exception
+type ResolveMobileExceptionRequest struct {
+	Resolution string `json:"resolution" binding:"required"`
+}
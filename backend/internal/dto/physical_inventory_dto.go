@@ -0,0 +1,56 @@
+package dto
+
+// CreatePhysicalInventoryRequest snapshots the stock of a warehouse and freezes its postings
+type CreatePhysicalInventoryRequest struct {
+	WarehouseID uint   `json:"warehouse_id" binding:"required"`
+	Notes       string `json:"notes"`
+}
+
+// PhysicalCountLineRequest is one counted quantity. LineID refers to a snapshotted line;
+// without it the item/location/batch identifies stock found that was not in the snapshot.
+type PhysicalCountLineRequest struct {
+	LineID          uint     `json:"line_id"`
+	ItemType        string   `json:"item_type" binding:"omitempty,oneof=material finished_product"`
+	ItemID          uint     `json:"item_id"`
+	LocationID      *uint    `json:"location_id"`
+	BatchNumber     string   `json:"batch_number"`
+	LotNumber       string   `json:"lot_number"`
+	CountedQuantity *float64 `json:"counted_quantity" binding:"required,gte=0"`
+	SerialNumbers   []string `json:"serial_numbers"` // serials counted of a serial-tracked item
+	Notes           string   `json:"notes"`
+}
+
+// RecordPhysicalCountsRequest records a counting round for one or more lines;
+// each submission for a line is a new round and the latest round counts
+type RecordPhysicalCountsRequest struct {
+	Lines []PhysicalCountLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+// FinalizePhysicalInventoryRequest closes the stocktake. Lines never counted are rejected
+// unless ZeroUncounted treats them as counted at zero.
+type FinalizePhysicalInventoryRequest struct {
+	Reason        string `json:"reason"`
+	Notes         string `json:"notes"`
+	ZeroUncounted bool   `json:"zero_uncounted"`
+}
+
+// CountSheetLine is a line of a count sheet; system quantities are not printed
+type CountSheetLine struct {
+	LineID      uint    `json:"line_id"`
+	ItemType    string  `json:"item_type"`
+	ItemID      uint    `json:"item_id"`
+	ItemCode    string  `json:"item_code"`
+	ItemName    string  `json:"item_name"`
+	BatchNumber string  `json:"batch_number,omitempty"`
+	LotNumber   string  `json:"lot_number,omitempty"`
+	ExpiryDate  *string `json:"expiry_date,omitempty"`
+	CountRounds int     `json:"count_rounds"`
+}
+
+// CountSheet lists the lines to count at one location
+type CountSheet struct {
+	SheetNumber  int              `json:"sheet_number"`
+	LocationID   *uint            `json:"location_id,omitempty"`
+	LocationPath string           `json:"location_path"`
+	Lines        []CountSheetLine `json:"lines"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+// GeneratePickListRequest creates one pick list for draft delivery orders of the same warehouse
+type GeneratePickListRequest struct {
+	DeliveryOrderIDs []uint `json:"delivery_order_ids" binding:"required,min=1"`
+	Notes            string `json:"notes"`
+}
+
+// ConfirmPickLineRequest reports what was actually picked for a line. Leaving PickedQuantity empty
+// confirms the full quantity; a lower quantity is a short pick. LocationID/BatchNumber/LotNumber
+// record a substitution when the stock was taken from elsewhere.
+type ConfirmPickLineRequest struct {
+	LineID         uint     `json:"line_id" binding:"required"`
+	PickedQuantity *float64 `json:"picked_quantity" binding:"omitempty,gte=0"`
+	LocationID     *uint    `json:"location_id"`
+	BatchNumber    *string  `json:"batch_number"`
+	LotNumber      *string  `json:"lot_number"`
+	ShortReason    string   `json:"short_reason" binding:"max=255"`
+	Notes          string   `json:"notes"`
+}
+
+// ConfirmPicksRequest confirms one or more lines of a pick list
+type ConfirmPicksRequest struct {
+	Lines []ConfirmPickLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
@@ -0,0 +1,52 @@
+package dto
+
+// CreatePickWaveRequest groups the open delivery orders of a warehouse into a wave.
+// All criteria are optional; DeliveryOrderIDs restricts the wave to the listed orders.
+type CreatePickWaveRequest struct {
+	WarehouseID      uint   `json:"warehouse_id" binding:"required"`
+	CarrierID        *uint  `json:"carrier_id"`
+	SalesChannelID   *uint  `json:"sales_channel_id"`
+	Cutoff           string `json:"cutoff"`        // orders created up to this time: RFC3339 or "YYYY-MM-DD HH:MM"
+	DeliveryDate     string `json:"delivery_date"` // orders due on or before this date (YYYY-MM-DD)
+	DeliveryOrderIDs []uint `json:"delivery_order_ids"`
+	MaxOrders        int    `json:"max_orders" binding:"omitempty,min=1"`
+	Notes            string `json:"notes"`
+}
+
+// ConfirmWavePickLineRequest reports the picked total of a consolidated wave line;
+// empty PickedQuantity confirms the full quantity
+type ConfirmWavePickLineRequest struct {
+	LineID         uint     `json:"line_id" binding:"required"`
+	PickedQuantity *float64 `json:"picked_quantity" binding:"omitempty,gte=0"`
+	ShortReason    string   `json:"short_reason" binding:"max=255"`
+}
+
+// ConfirmWavePicksRequest confirms one or more consolidated lines of a wave
+type ConfirmWavePicksRequest struct {
+	Lines []ConfirmWavePickLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+// ShipWaveRequest ships every packed delivery order of the wave.
+// TrackingNumbers is keyed by delivery order ID.
+type ShipWaveRequest struct {
+	TrackingNumbers map[uint]string `json:"tracking_numbers"`
+	Notes           string          `json:"notes"`
+}
+
+// WaveShipResult is the outcome of shipping one delivery order of a wave
+type WaveShipResult struct {
+	DeliveryOrderID uint   `json:"delivery_order_id"`
+	DONumber        string `json:"do_number"`
+	Success         bool   `json:"success"`
+	Error           string `json:"error,omitempty"`
+}
+
+// WaveShipResponse summarises a bulk wave shipment
+type WaveShipResponse struct {
+	WaveID     uint             `json:"wave_id"`
+	WaveNumber string           `json:"wave_number"`
+	Status     string           `json:"status"`
+	Shipped    int              `json:"shipped"`
+	Failed     int              `json:"failed"`
+	Results    []WaveShipResult `json:"results"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+// SendPOEmailRequest sends a purchase order or delivery reminder to the supplier.
+// To defaults to the supplier's e-mail; Message is appended to the templated text.
+type SendPOEmailRequest struct {
+	To      []string `json:"to" binding:"omitempty,dive,email"`
+	Cc      []string `json:"cc" binding:"omitempty,dive,email"`
+	Message string   `json:"message" binding:"max=2000"`
+	Lang    string   `json:"lang" binding:"omitempty,oneof=vi en"`
+}
+
+// RunDeliveryRemindersRequest reminds suppliers of approved POs due within DaysAhead days
+type RunDeliveryRemindersRequest struct {
+	DaysAhead *int `json:"days_ahead" binding:"omitempty,min=0,max=60"` // default from config
+}
+
+// DeliveryReminderOutcome is the result for one PO in a reminder run
+type DeliveryReminderOutcome struct {
+	PurchaseOrderID uint   `json:"purchase_order_id"`
+	PONumber        string `json:"po_number"`
+	ExpectedDate    string `json:"expected_date"`
+	Status          string `json:"status"` // sent, failed, skipped
+	Reason          string `json:"reason,omitempty"`
+}
+
+// DeliveryReminderRunResult summarises a reminder run
+type DeliveryReminderRunResult struct {
+	FromDate string                    `json:"from_date"`
+	ToDate   string                    `json:"to_date"`
+	Sent     int                       `json:"sent"`
+	Failed   int                       `json:"failed"`
+	Skipped  int                       `json:"skipped"`
+	Results  []DeliveryReminderOutcome `json:"results"`
+}
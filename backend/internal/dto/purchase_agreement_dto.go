@@ -0,0 +1,53 @@
+package dto
+
+// PurchaseAgreementItemRequest represents one material line on a blanket agreement
+type PurchaseAgreementItemRequest struct {
+	MaterialID        uint    `json:"material_id" binding:"required"`
+	AgreedPrice       float64 `json:"agreed_price" binding:"gte=0"`
+	TaxRate           float64 `json:"tax_rate" binding:"gte=0,lte=100"`
+	DiscountRate      float64 `json:"discount_rate" binding:"gte=0,lte=100"`
+	CommittedQuantity float64 `json:"committed_quantity" binding:"required,gt=0"`
+	Notes             string  `json:"notes"`
+}
+
+// CreatePurchaseAgreementRequest represents the request to create a blanket purchase agreement
+type CreatePurchaseAgreementRequest struct {
+	SupplierID   uint                           `json:"supplier_id" binding:"required"`
+	StartDate    string                         `json:"start_date" binding:"required"`      // YYYY-MM-DD
+	EndDate      string                         `json:"end_date" binding:"required"`        // YYYY-MM-DD
+	Currency     string                         `json:"currency" binding:"omitempty,len=3"` // defaults to the supplier currency
+	PaymentTerms string                         `json:"payment_terms"`
+	Notes        string                         `json:"notes"`
+	Items        []PurchaseAgreementItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// UpdatePurchaseAgreementRequest represents the request to update a draft agreement
+type UpdatePurchaseAgreementRequest struct {
+	StartDate    string                         `json:"start_date"`
+	EndDate      string                         `json:"end_date"`
+	Currency     string                         `json:"currency" binding:"omitempty,len=3"`
+	PaymentTerms string                         `json:"payment_terms"`
+	Notes        string                         `json:"notes"`
+	Items        []PurchaseAgreementItemRequest `json:"items" binding:"omitempty,dive"`
+}
+
+// CallOffItemRequest represents one line on a call-off order; price comes from the agreement
+type CallOffItemRequest struct {
+	MaterialID           uint    `json:"material_id" binding:"required"`
+	Quantity             float64 `json:"quantity" binding:"required,gt=0"`
+	Notes                string  `json:"notes"`
+	ExpectedDeliveryDate string  `json:"expected_delivery_date"` // YYYY-MM-DD
+}
+
+// CreateCallOffRequest represents the request to create a call-off PO against an agreement
+type CreateCallOffRequest struct {
+	PONumber             string               `json:"po_number" binding:"required,min=2,max=50"`
+	WarehouseID          uint                 `json:"warehouse_id" binding:"required"`
+	OrderDate            string               `json:"order_date" binding:"required"` // YYYY-MM-DD
+	ExpectedDeliveryDate string               `json:"expected_delivery_date"`
+	ShippingMethod       string               `json:"shipping_method"`
+	Notes                string               `json:"notes"`
+	AssignedTo           *uint                `json:"assigned_to"`
+	AllowOverCommitment  bool                 `json:"allow_over_commitment"` // accept exceeding the committed quantity or value
+	Items                []CallOffItemRequest `json:"items" binding:"required,min=1,dive"`
+}
@@ -25,6 +25,10 @@ type CreatePurchaseOrderRequest struct {
 	ShippingMethod       string                            `json:"shipping_method"`
 	Notes                string                            `json:"notes"`
 	AssignedTo           *uint                             `json:"assigned_to"`
+	AgreementID          *uint                             `json:"agreement_id"` // call-off: prices taken from the agreement
+	AllowOverCommitment  bool                              `json:"allow_over_commitment"` // call-off: accept exceeding the committed quantity or value
+	Currency             string                            `json:"currency" binding:"omitempty,len=3"` // defaults to the supplier currency
+	Source               string                            `json:"-"`                                  // set by the replenishment engine; manual otherwise
 	Items                []CreatePurchaseOrderItemRequest  `json:"items" binding:"required,min=1,dive"`
 }
 
@@ -51,6 +55,7 @@ type UpdatePurchaseOrderRequest struct {
 	ShippingMethod       string                            `json:"shipping_method"`
 	Notes                string                            `json:"notes"`
 	AssignedTo           *uint                             `json:"assigned_to"`
+	AllowOverCommitment  bool                              `json:"allow_over_commitment"` // call-off: accept exceeding the committed quantity or value
 	Items                []UpdatePurchaseOrderItemRequest  `json:"items" binding:"omitempty,dive"`
 }
 
@@ -59,6 +64,12 @@ type AssignPORequest struct {
 	AssignedTo *uint `json:"assigned_to"` // nil = unassign
 }
 
+// ClosePOShortRequest closes PO lines that will not be delivered in full
+type ClosePOShortRequest struct {
+	ItemIDs []uint `json:"item_ids"` // empty = every open line
+	Reason  string `json:"reason" binding:"required,max=500"`
+}
+
 // PurchaseOrderFilterRequest represents the filter for listing purchase orders
 type PurchaseOrderFilterRequest struct {
 	Search        string `form:"search"`
@@ -67,6 +78,8 @@ type PurchaseOrderFilterRequest struct {
 	Status        string `form:"status"`
 	PaymentStatus string `form:"payment_status"`
 	AssignedTo    *uint  `form:"assigned_to"`
+	AgreementID   *uint  `form:"agreement_id"`
+	Source        string `form:"source"` // manual, replenishment
 	OrderDateFrom string `form:"order_date_from"` // YYYY-MM-DD
 	OrderDateTo   string `form:"order_date_to"`   // YYYY-MM-DD
 	Page          int    `form:"page"`
@@ -87,6 +100,15 @@ type UpdatePaymentStatusRequest struct {
 	Notes         string `json:"notes"`
 }
 
+// RecordPOPaymentRequest records a supplier payment in the PO currency
+type RecordPOPaymentRequest struct {
+	PaymentDate  string  `json:"payment_date" binding:"required"` // YYYY-MM-DD
+	Amount       float64 `json:"amount" binding:"required,gt=0"`
+	ExchangeRate float64 `json:"exchange_rate" binding:"omitempty,gt=0"` // defaults to the rate table on payment_date
+	Reference    string  `json:"reference"`
+	Notes        string  `json:"notes"`
+}
+
 // UpdateInvoiceStatusRequest represents the request to update PO invoice status (B6)
 type UpdateInvoiceStatusRequest struct {
 	InvoiceStatus string `json:"invoice_status" binding:"required,oneof=pending received"`
@@ -0,0 +1,29 @@
+package dto
+
+// PurchaseReturnItemRequest is one line on a purchase return.
+// For grn_rejected returns GRNItemID is required; for stock returns the batch may be given
+// directly and the originating GRN line is looked up by supplier, material and batch.
+type PurchaseReturnItemRequest struct {
+	GRNItemID           *uint    `json:"grn_item_id"`
+	MaterialID          uint     `json:"material_id"`
+	WarehouseLocationID *uint    `json:"warehouse_location_id"`
+	BatchNumber         string   `json:"batch_number"`
+	LotNumber           string   `json:"lot_number"`
+	Quantity            float64  `json:"quantity" binding:"required,gt=0"`
+	Resolution          string   `json:"resolution" binding:"omitempty,oneof=credit replacement"`
+	SerialNumbers       []string `json:"serial_numbers"` // stock returns of a serial-tracked material
+	Reason              string   `json:"reason"`
+}
+
+// CreatePurchaseReturnRequest represents the request to create a purchase return
+type CreatePurchaseReturnRequest struct {
+	Source          string                      `json:"source" binding:"required,oneof=grn_rejected stock"`
+	SupplierID      uint                        `json:"supplier_id"` // required for stock returns without a PO
+	PurchaseOrderID *uint                       `json:"purchase_order_id"`
+	GRNID           *uint                       `json:"grn_id"` // required for grn_rejected
+	WarehouseID     uint                        `json:"warehouse_id"`
+	ReturnDate      string                      `json:"return_date" binding:"required"` // YYYY-MM-DD
+	Reason          string                      `json:"reason"`
+	Notes           string                      `json:"notes"`
+	Items           []PurchaseReturnItemRequest `json:"items" binding:"required,min=1,dive"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+// ConfirmPutawayRequest moves (part of) a putaway task into a storage location.
+// Quantity defaults to everything still waiting; a smaller quantity splits the task.
+// A serial-tracked item moved only in part needs the serials of the units put away.
+type ConfirmPutawayRequest struct {
+	LocationID    uint     `json:"location_id" binding:"required"`
+	Quantity      *float64 `json:"quantity" binding:"omitempty,gt=0"`
+	SerialNumbers []string `json:"serial_numbers"`
+	Notes         string   `json:"notes" binding:"omitempty,max=1000"`
+}
+
+// PutawaySuggestion is a candidate location ranked by the putaway rules
+type PutawaySuggestion struct {
+	LocationID   uint     `json:"location_id"`
+	Code         string   `json:"code"`
+	Name         string   `json:"name"`
+	LocationType string   `json:"location_type"`
+	Score        int      `json:"score"`
+	FreeCapacity *float64 `json:"free_capacity,omitempty"` // nil when the location has no capacity limit
+	Reasons      []string `json:"reasons"`
+}
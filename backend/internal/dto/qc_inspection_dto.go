@@ -0,0 +1,123 @@
+package dto
+
+import "time"
+
+// QCTemplateTestRequest is one test on an inspection template
+type QCTemplateTestRequest struct {
+	TestCode     string   `json:"test_code" binding:"required,max=50"`
+	TestName     string   `json:"test_name" binding:"required,max=255"`
+	Category     string   `json:"category" binding:"omitempty,max=50"`
+	ResultType   string   `json:"result_type" binding:"required,oneof=numeric text"`
+	Unit         string   `json:"unit" binding:"omitempty,max=20"`
+	MinValue     *float64 `json:"min_value"`
+	MaxValue     *float64 `json:"max_value"`
+	ExpectedText string   `json:"expected_text" binding:"omitempty,max=255"`
+	Method       string   `json:"method" binding:"omitempty,max=255"`
+	IsRequired   *bool    `json:"is_required"` // default true
+	SortOrder    int      `json:"sort_order"`
+}
+
+// CreateQCTemplateRequest creates an inspection template; an active template replaces the
+// material's current one as a new version
+type CreateQCTemplateRequest struct {
+	Code             string                  `json:"code" binding:"required,max=50"`
+	Name             string                  `json:"name" binding:"required,max=255"`
+	MaterialID       uint                    `json:"material_id" binding:"required"`
+	SamplingRule     string                  `json:"sampling_rule" binding:"required,oneof=fixed percent sqrt"`
+	SampleValue      float64                 `json:"sample_value" binding:"gte=0"` // fixed qty or percent; unused for sqrt
+	MinSample        *float64                `json:"min_sample" binding:"omitempty,gte=0"`
+	MaxSample        *float64                `json:"max_sample" binding:"omitempty,gt=0"`
+	RetainedQuantity float64                 `json:"retained_quantity" binding:"gte=0"`
+	RetentionDays    int                     `json:"retention_days" binding:"gte=0"`
+	IsActive         *bool                   `json:"is_active"` // default true
+	Notes            string                  `json:"notes"`
+	Tests            []QCTemplateTestRequest `json:"tests" binding:"required,min=1,dive"`
+}
+
+// UpdateQCTemplateRequest updates a template; Tests replaces the test list when given
+type UpdateQCTemplateRequest struct {
+	Name             *string                  `json:"name" binding:"omitempty,max=255"`
+	SamplingRule     *string                  `json:"sampling_rule" binding:"omitempty,oneof=fixed percent sqrt"`
+	SampleValue      *float64                 `json:"sample_value" binding:"omitempty,gte=0"`
+	MinSample        *float64                 `json:"min_sample" binding:"omitempty,gte=0"`
+	MaxSample        *float64                 `json:"max_sample" binding:"omitempty,gt=0"`
+	RetainedQuantity *float64                 `json:"retained_quantity" binding:"omitempty,gte=0"`
+	RetentionDays    *int                     `json:"retention_days" binding:"omitempty,gte=0"`
+	IsActive         *bool                    `json:"is_active"`
+	Notes            *string                  `json:"notes"`
+	Tests            *[]QCTemplateTestRequest `json:"tests" binding:"omitempty,min=1,dive"`
+}
+
+// CreateQCInspectionRequest opens an inspection for a GRN line using the material's active template
+type CreateQCInspectionRequest struct {
+	GRNItemID      uint   `json:"grn_item_id" binding:"required"`
+	ContainerCount *int   `json:"container_count" binding:"omitempty,gt=0"` // required by the sqrt sampling rule
+	Notes          string `json:"notes"`
+}
+
+// QCResultEntry is a measured value for one inspection result line
+type QCResultEntry struct {
+	ResultID     uint     `json:"result_id" binding:"required"`
+	NumericValue *float64 `json:"numeric_value"`
+	TextValue    *string  `json:"text_value" binding:"omitempty,max=255"`
+	Notes        string   `json:"notes"`
+}
+
+// RecordQCResultsRequest records results; the inspection completes once every required test has a value
+type RecordQCResultsRequest struct {
+	Results []QCResultEntry `json:"results" binding:"required,min=1,dive"`
+	Notes   *string         `json:"notes"`
+}
+
+// CreateRetainedSampleRequest logs a retained sample for an inspected batch
+type CreateRetainedSampleRequest struct {
+	Quantity        float64 `json:"quantity" binding:"omitempty,gt=0"` // default: template retained_quantity
+	StorageLocation string  `json:"storage_location" binding:"omitempty,max=255"`
+	RetainedAt      string  `json:"retained_at"`  // YYYY-MM-DD, default today
+	RetainUntil     string  `json:"retain_until"` // YYYY-MM-DD, default from batch expiry + template retention days
+	Notes           string  `json:"notes"`
+}
+
+// DisposeRetainedSampleRequest records disposal of a retained sample
+type DisposeRetainedSampleRequest struct {
+	Notes string `json:"notes"`
+}
+
+// COATestLine is one test on a certificate of analysis
+type COATestLine struct {
+	TestCode      string `json:"test_code"`
+	TestName      string `json:"test_name"`
+	Category      string `json:"category,omitempty"`
+	Method        string `json:"method,omitempty"`
+	Specification string `json:"specification"`
+	Result        string `json:"result"`
+	Unit          string `json:"unit,omitempty"`
+	Conclusion    string `json:"conclusion"` // pass, fail
+}
+
+// CertificateOfAnalysis is the COA for an inspected batch
+type CertificateOfAnalysis struct {
+	InspectionID     uint          `json:"inspection_id"`
+	InspectionNumber string        `json:"inspection_number"`
+	MaterialCode     string        `json:"material_code"`
+	MaterialName     string        `json:"material_name"`
+	InciName         string        `json:"inci_name,omitempty"`
+	SupplierName     string        `json:"supplier_name,omitempty"`
+	GRNNumber        string        `json:"grn_number"`
+	ReceiptDate      string        `json:"receipt_date"`
+	BatchNumber      string        `json:"batch_number,omitempty"`
+	LotNumber        string        `json:"lot_number,omitempty"`
+	ManufactureDate  string        `json:"manufacture_date,omitempty"`
+	ExpiryDate       string        `json:"expiry_date,omitempty"`
+	ReceivedQuantity float64       `json:"received_quantity"`
+	Unit             string        `json:"unit"`
+	SampleSize       float64       `json:"sample_size"`
+	SampleUnit       string        `json:"sample_unit"`
+	TemplateCode     string        `json:"template_code"`
+	TemplateVersion  int           `json:"template_version"`
+	Tests            []COATestLine `json:"tests"`
+	Result           string        `json:"result"` // pass, fail
+	InspectedBy      string        `json:"inspected_by,omitempty"`
+	InspectedAt      *time.Time    `json:"inspected_at,omitempty"`
+	Notes            string        `json:"notes,omitempty"`
+}
@@ -0,0 +1,48 @@
+package dto
+
+// ReplenishmentRequest selects what the replenishment engine should look at
+type ReplenishmentRequest struct {
+	WarehouseID uint   `json:"warehouse_id" form:"warehouse_id" binding:"required"`
+	MaterialIDs []uint `json:"material_ids" form:"material_ids"` // empty = all materials with a reorder point
+	OrderDate   string `json:"order_date" form:"order_date"`     // YYYY-MM-DD, defaults to today
+}
+
+// ReplenishmentLine is one material below its reorder point.
+// Position = on hand - reserved + on order; OrderQuantity is rounded to the supplier MOQ and pack size.
+type ReplenishmentLine struct {
+	MaterialID      uint    `json:"material_id"`
+	MaterialCode    string  `json:"material_code"`
+	MaterialName    string  `json:"material_name"`
+	Unit            string  `json:"unit"`
+	ReorderPoint    float64 `json:"reorder_point"`
+	ReorderQuantity float64 `json:"reorder_quantity"`
+	MaxStockLevel   float64 `json:"max_stock_level"`
+	OnHand          float64 `json:"on_hand"`
+	Reserved        float64 `json:"reserved"`
+	OnOrder         float64 `json:"on_order"`
+	Position        float64 `json:"position"`
+
+	SuggestedQuantity float64 `json:"suggested_quantity"`
+	MinOrderQuantity  float64 `json:"min_order_quantity,omitempty"`
+	PackSize          float64 `json:"pack_size,omitempty"`
+	OrderQuantity     float64 `json:"order_quantity"`
+	UnitPrice         float64 `json:"unit_price"`
+	LeadTimeDays      int     `json:"lead_time_days,omitempty"`
+}
+
+// ReplenishmentProposal groups lines for one preferred supplier (one draft PO)
+type ReplenishmentProposal struct {
+	SupplierID     uint                `json:"supplier_id"`
+	SupplierCode   string              `json:"supplier_code"`
+	SupplierName   string              `json:"supplier_name"`
+	Lines          []ReplenishmentLine `json:"lines"`
+	EstimatedValue float64             `json:"estimated_value"`
+}
+
+// ReplenishmentPlan is the outcome of a replenishment check for a warehouse
+type ReplenishmentPlan struct {
+	WarehouseID uint                    `json:"warehouse_id"`
+	OrderDate   string                  `json:"order_date"`
+	Proposals   []ReplenishmentProposal `json:"proposals"`
+	Unassigned  []ReplenishmentLine     `json:"unassigned"` // no active supplier in material_suppliers
+}
@@ -13,11 +13,12 @@ type CreateReturnOrderRequest struct {
 }
 
 type CreateReturnOrderItemReq struct {
-	DeliveryOrderItemID *uint  `json:"delivery_order_item_id"`
-	FinishedProductID   uint   `json:"finished_product_id" binding:"required"`
-	QuantityReturned    int    `json:"quantity_returned" binding:"required,min=1"`
-	Reason              string `json:"reason"`
-	Notes               string `json:"notes"`
+	DeliveryOrderItemID *uint    `json:"delivery_order_item_id"`
+	FinishedProductID   uint     `json:"finished_product_id" binding:"required"`
+	QuantityReturned    int      `json:"quantity_returned" binding:"required,min=1"`
+	SerialNumbers       []string `json:"serial_numbers"` // one per unit for a serial-tracked product, as shipped on the DO
+	Reason              string   `json:"reason"`
+	Notes               string   `json:"notes"`
 }
 
 type UpdateReturnOrderRequest struct {
@@ -0,0 +1,62 @@
+package dto
+
+// ScanRequest resolves one raw scanned string. Control characters such as the GS1 group
+// separator must be sent as-is (JSON "\u001d"). WarehouseID adds the item's balances in that warehouse.
+type ScanRequest struct {
+	Code        string `json:"code" binding:"required"`
+	WarehouseID uint   `json:"warehouse_id"`
+}
+
+// ScanItem is the material or finished product a scan resolved to
+type ScanItem struct {
+	ItemType string `json:"item_type"` // material, finished_product
+	ItemID   uint   `json:"item_id"`
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Barcode  string `json:"barcode,omitempty"`
+}
+
+// ScanLocation is the warehouse location a scan resolved to
+type ScanLocation struct {
+	ID          uint   `json:"id"`
+	WarehouseID uint   `json:"warehouse_id"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+}
+
+// ScanDocument is the warehouse document a scan resolved to
+type ScanDocument struct {
+	DocumentType string `json:"document_type"` // grn, delivery_order, pick_list, ...
+	ID           uint   `json:"id"`
+	Number       string `json:"number"`
+	Status       string `json:"status"`
+}
+
+// ScanBalance is stock of the scanned item (and batch, when scanned) in the requested warehouse
+type ScanBalance struct {
+	LocationID        *uint   `json:"location_id,omitempty"`
+	LocationPath      string  `json:"location_path,omitempty"`
+	BatchNumber       string  `json:"batch_number,omitempty"`
+	LotNumber         string  `json:"lot_number,omitempty"`
+	ExpiryDate        *string `json:"expiry_date,omitempty"`
+	Quantity          float64 `json:"quantity"`
+	AvailableQuantity float64 `json:"available_quantity"`
+}
+
+// ScanResult is what a scan means. Type is finished_product, material, location or document;
+// GS1 fields are filled from the scanned application identifiers.
+type ScanResult struct {
+	Code      string            `json:"code"`
+	Symbology string            `json:"symbology"` // gs1, gtin, text
+	Type      string            `json:"type"`
+	GTIN      string            `json:"gtin,omitempty"`
+	Batch     string            `json:"batch,omitempty"`
+	Serial    string            `json:"serial,omitempty"`
+	Expiry    string            `json:"expiry,omitempty"`
+	AIs       map[string]string `json:"ais,omitempty"`
+	Item      *ScanItem         `json:"item,omitempty"`
+	Location  *ScanLocation     `json:"location,omitempty"`
+	Document  *ScanDocument     `json:"document,omitempty"`
+	Balances  []ScanBalance     `json:"balances,omitempty"`
+}
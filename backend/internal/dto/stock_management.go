@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // StockAdjustmentItemRequest represents a single item in an adjustment request
 type StockAdjustmentItemRequest struct {
 	ItemType           string  `json:"item_type" binding:"required,oneof=material finished_product"`
@@ -8,6 +10,7 @@ type StockAdjustmentItemRequest struct {
 	BatchNumber       string  `json:"batch_number"`
 	LotNumber         string  `json:"lot_number"`
 	AdjustmentQuantity float64 `json:"adjustment_quantity" binding:"required"`
+	SerialNumbers     []string `json:"serial_numbers"` // units removed or added of a serial-tracked item
 	Notes             string  `json:"notes"`
 }
 
@@ -40,6 +43,7 @@ type StockTransferItemRequest struct {
 	BatchNumber    string  `json:"batch_number"`
 	LotNumber      string  `json:"lot_number"`
 	Quantity       float64 `json:"quantity" binding:"required,gt=0"`
+	SerialNumbers  []string `json:"serial_numbers"` // one per unit for a serial-tracked item
 	Notes          string  `json:"notes"`
 }
 
@@ -61,3 +65,69 @@ type StockTransferFilterRequest struct {
 	Offset          int    `json:"offset"`
 	Limit           int    `json:"limit"`
 }
+
+// ShipStockTransferRequest ships a transfer: the stock leaves the source warehouse and waits in the
+// in-transit location of the destination until it is received
+type ShipStockTransferRequest struct {
+	Carrier             string `json:"carrier" binding:"max=100"`
+	TrackingNumber      string `json:"tracking_number" binding:"max=100"`
+	ExpectedArrivalDate string `json:"expected_arrival_date"` // YYYY-MM-DD
+	Notes               string `json:"notes"`
+}
+
+// ReceiveStockTransferItemRequest is what arrived of one transfer item. Less than shipped is a
+// discrepancy and needs a reason; ToLocationID overrides the planned destination location.
+type ReceiveStockTransferItemRequest struct {
+	ItemID            uint     `json:"item_id" binding:"required"`
+	ReceivedQuantity  *float64 `json:"received_quantity" binding:"required,gte=0"`
+	ToLocationID      *uint    `json:"to_location_id"`
+	DiscrepancyReason string   `json:"discrepancy_reason"`
+	// Serials that arrived; required for a serial-tracked item received short
+	SerialNumbers []string `json:"serial_numbers"`
+}
+
+// ReceiveStockTransferRequest receives a shipped transfer at the destination; items left out
+// arrived in full
+type ReceiveStockTransferRequest struct {
+	Items []ReceiveStockTransferItemRequest `json:"items" binding:"dive"`
+	Notes string                            `json:"notes"`
+}
+
+// InTransitFilterRequest selects shipped transfers for the in-transit report
+type InTransitFilterRequest struct {
+	FromWarehouseID uint `form:"from_warehouse_id" json:"from_warehouse_id"`
+	ToWarehouseID   uint `form:"to_warehouse_id" json:"to_warehouse_id"`
+}
+
+// InTransitItem is stock of one transfer item still on its way
+type InTransitItem struct {
+	ItemID      uint    `json:"item_id"` // transfer item
+	ItemType    string  `json:"item_type"`
+	ProductID   uint    `json:"product_id"` // material or finished product
+	ItemCode    string  `json:"item_code"`
+	ItemName    string  `json:"item_name"`
+	BatchNumber string  `json:"batch_number,omitempty"`
+	LotNumber   string  `json:"lot_number,omitempty"`
+	Quantity    float64 `json:"quantity"`
+	UnitCost    float64 `json:"unit_cost"`
+	Value       float64 `json:"value"`
+}
+
+// InTransitTransfer is a shipped transfer that has not been received yet
+type InTransitTransfer struct {
+	TransferID          uint            `json:"transfer_id"`
+	TransferNumber      string          `json:"transfer_number"`
+	FromWarehouseID     uint            `json:"from_warehouse_id"`
+	FromWarehouseName   string          `json:"from_warehouse_name"`
+	ToWarehouseID       uint            `json:"to_warehouse_id"`
+	ToWarehouseName     string          `json:"to_warehouse_name"`
+	ShippedAt           *time.Time      `json:"shipped_at,omitempty"`
+	ExpectedArrivalDate *time.Time      `json:"expected_arrival_date,omitempty"`
+	Carrier             string          `json:"carrier,omitempty"`
+	TrackingNumber      string          `json:"tracking_number,omitempty"`
+	DaysInTransit       int             `json:"days_in_transit"`
+	Overdue             bool            `json:"overdue"`
+	TotalQuantity       float64         `json:"total_quantity"`
+	TotalValue          float64         `json:"total_value"`
+	Items               []InTransitItem `json:"items"`
+}
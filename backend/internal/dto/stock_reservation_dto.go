@@ -0,0 +1,65 @@
+package dto
+
+import "time"
+
+// CreateReservationRequest reserves stock of an item for a customer order or a delivery order.
+// Without a location or batch the quantity is reserved FEFO over the available stock, one
+// reservation per balance; with them only the matching stock is reserved.
+type CreateReservationRequest struct {
+	ItemType        string     `json:"item_type" binding:"required,oneof=material finished_product"`
+	ItemID          uint       `json:"item_id" binding:"required"`
+	WarehouseID     uint       `json:"warehouse_id" binding:"required"`
+	Quantity        float64    `json:"quantity" binding:"required,gt=0"`
+	LocationID      *uint      `json:"location_id"`
+	BatchNumber     string     `json:"batch_number" binding:"max=100"`
+	LotNumber       string     `json:"lot_number" binding:"max=100"`
+	ReferenceType   string     `json:"reference_type" binding:"required,oneof=customer_order delivery_order"`
+	ReferenceID     uint       `json:"reference_id"`
+	ReferenceNumber string     `json:"reference_number" binding:"max=100"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	Notes           string     `json:"notes" binding:"max=1000"`
+}
+
+// ReallocateReservationRequest moves what a reservation still holds, or Quantity of it, to another
+// location and/or batch of the same item and warehouse. A partial move splits the reservation.
+type ReallocateReservationRequest struct {
+	LocationID  *uint    `json:"location_id"`
+	BatchNumber string   `json:"batch_number" binding:"max=100"`
+	LotNumber   string   `json:"lot_number" binding:"max=100"`
+	Quantity    *float64 `json:"quantity" binding:"omitempty,gt=0"`
+	Notes       string   `json:"notes" binding:"max=1000"`
+}
+
+// ReleaseReservationRequest gives back Quantity of a reservation, by default all it still holds
+type ReleaseReservationRequest struct {
+	Quantity *float64 `json:"quantity" binding:"omitempty,gt=0"`
+	Reason   string   `json:"reason" binding:"max=1000"`
+}
+
+// ReservationReportFilterRequest selects the items of the reserved vs available report
+type ReservationReportFilterRequest struct {
+	ItemType    string `form:"item_type" binding:"omitempty,oneof=material finished_product"`
+	ItemID      uint   `form:"item_id"`
+	WarehouseID uint   `form:"warehouse_id"`
+	// OnlyReserved keeps the items with open reservations
+	OnlyReserved bool `form:"only_reserved"`
+}
+
+// ReservationReportRow compares the reserved and available stock of an item in a warehouse.
+// Available is what is on hand outside quarantine and transit and not reserved.
+type ReservationReportRow struct {
+	ItemType         string  `json:"item_type"`
+	ItemID           uint    `json:"item_id"`
+	ItemCode         string  `json:"item_code"`
+	ItemName         string  `json:"item_name"`
+	WarehouseID      uint    `json:"warehouse_id"`
+	WarehouseName    string  `json:"warehouse_name"`
+	OnHand           float64 `json:"on_hand"`
+	InTransit        float64 `json:"in_transit"`
+	Quarantined      float64 `json:"quarantined"`
+	Reserved         float64 `json:"reserved"`
+	Available        float64 `json:"available"`
+	OpenReservations int     `json:"open_reservations"`
+	// Unbacked is reserved on active reservations but no longer held on the balances
+	Unbacked float64 `json:"unbacked,omitempty"`
+}
@@ -14,7 +14,12 @@ type CreateSupplierRequest struct {
 	Country       string   `json:"country" binding:"max=100"`
 	PaymentTerms  *string  `json:"payment_terms" binding:"omitempty,max=100"`
 	CreditLimit   *float64 `json:"credit_limit" binding:"omitempty,min=0"`
+	Currency      string   `json:"currency" binding:"omitempty,len=3"`
 	IsActive      bool     `json:"is_active"`
+
+	OverReceiptTolerance  *float64 `json:"over_receipt_tolerance" binding:"omitempty,gte=0,lte=100"`
+	UnderReceiptTolerance *float64 `json:"under_receipt_tolerance" binding:"omitempty,gte=0,lte=100"`
+
 	Notes         *string  `json:"notes"`
 }
 
@@ -32,7 +37,12 @@ type UpdateSupplierRequest struct {
 	Country       string   `json:"country" binding:"omitempty,max=100"`
 	PaymentTerms  *string  `json:"payment_terms" binding:"omitempty,max=100"`
 	CreditLimit   *float64 `json:"credit_limit" binding:"omitempty,min=0"`
+	Currency      string   `json:"currency" binding:"omitempty,len=3"`
 	IsActive      *bool    `json:"is_active"`
+
+	OverReceiptTolerance  *float64 `json:"over_receipt_tolerance" binding:"omitempty,gte=0,lte=100"`
+	UnderReceiptTolerance *float64 `json:"under_receipt_tolerance" binding:"omitempty,gte=0,lte=100"`
+
 	Notes         *string  `json:"notes"`
 }
 
@@ -47,3 +57,14 @@ type SupplierFilterRequest struct {
 	SortBy   string `form:"sort_by" binding:"omitempty,oneof=id code name city country created_at updated_at"`
 	SortOrder string `form:"sort_order" binding:"omitempty,oneof=asc desc"`
 }
+
+// UpdateSupplierDocumentRequest changes the metadata of an uploaded supplier document. Only the
+// fields sent are changed; an empty string clears an optional field and material_id 0 unlinks the material.
+type UpdateSupplierDocumentRequest struct {
+	DocumentType   *string `json:"document_type" binding:"omitempty,max=30"`
+	DocumentNumber *string `json:"document_number" binding:"omitempty,max=100"`
+	MaterialID     *uint   `json:"material_id"`
+	IssueDate      *string `json:"issue_date"`
+	ExpiryDate     *string `json:"expiry_date"`
+	Notes          *string `json:"notes"`
+}
@@ -0,0 +1,48 @@
+package dto
+
+// SupplierScorecard holds supplier performance KPIs for a period.
+// Rates are fractions (0..1); a nil rate means there was no data for that KPI in the period.
+type SupplierScorecard struct {
+	SupplierID   uint   `json:"supplier_id"`
+	SupplierCode string `json:"supplier_code"`
+	SupplierName string `json:"supplier_name"`
+	PeriodFrom   string `json:"period_from"`
+	PeriodTo     string `json:"period_to"`
+
+	// On-time delivery: posted GRNs received on or before PO expected_delivery_date
+	Receipts       int64    `json:"receipts"`
+	OnTimeReceipts int64    `json:"on_time_receipts"`
+	OnTimeRate     *float64 `json:"on_time_rate"`
+
+	// Fill rate: received vs ordered quantity on POs due in the period
+	OrderedQuantity  float64  `json:"ordered_quantity"`
+	ReceivedQuantity float64  `json:"received_quantity"`
+	FillRate         *float64 `json:"fill_rate"`
+
+	// QC rejection: rejected vs inspected quantity on GRNs in the period
+	AcceptedQuantity float64  `json:"accepted_quantity"`
+	RejectedQuantity float64  `json:"rejected_quantity"`
+	RejectionRate    *float64 `json:"rejection_rate"`
+
+	// Price variance: PO prices vs agreed price (material_suppliers.unit_price, fallback materials.standard_cost).
+	// Positive = paying more than reference.
+	ActualAmount    float64  `json:"actual_amount"`
+	ReferenceAmount float64  `json:"reference_amount"`
+	PriceVariance   *float64 `json:"price_variance"`
+
+	Score float64 `json:"score"` // 0..100
+	Grade string  `json:"grade"` // A, B, C, D, N/A
+	Rank  int     `json:"rank,omitempty"`
+}
+
+// SupplierPrioritySuggestion suggests MaterialSupplier.Priority from scorecard ranking
+type SupplierPrioritySuggestion struct {
+	MaterialSupplierID int64   `json:"material_supplier_id"`
+	SupplierID         uint    `json:"supplier_id"`
+	SupplierCode       string  `json:"supplier_code"`
+	SupplierName       string  `json:"supplier_name"`
+	CurrentPriority    int     `json:"current_priority"`
+	SuggestedPriority  int     `json:"suggested_priority"`
+	Score              float64 `json:"score"`
+	Grade              string  `json:"grade"`
+}
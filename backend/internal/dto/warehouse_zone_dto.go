@@ -0,0 +1,70 @@
+package dto
+
+// CreateWarehouseZoneRequest represents the request body for creating a warehouse zone
+type CreateWarehouseZoneRequest struct {
+	WarehouseID      uint     `json:"warehouse_id" binding:"required"`
+	Code             string   `json:"code" binding:"required,min=1,max=50"`
+	Name             string   `json:"name" binding:"required,min=1,max=255"`
+	ZoneType         string   `json:"zone_type" binding:"omitempty,oneof=ambient cold flammable corrosive hazardous general"`
+	MinTemperature   *float64 `json:"min_temperature"`
+	MaxTemperature   *float64 `json:"max_temperature"`
+	HazardousAllowed bool     `json:"hazardous_allowed"`
+	IsActive         *bool    `json:"is_active"`
+	Notes            string   `json:"notes"`
+}
+
+// UpdateWarehouseZoneRequest represents the request body for updating a warehouse zone
+type UpdateWarehouseZoneRequest struct {
+	Code             string   `json:"code" binding:"omitempty,min=1,max=50"`
+	Name             string   `json:"name" binding:"omitempty,min=1,max=255"`
+	ZoneType         string   `json:"zone_type" binding:"omitempty,oneof=ambient cold flammable corrosive hazardous general"`
+	MinTemperature   *float64 `json:"min_temperature"`
+	MaxTemperature   *float64 `json:"max_temperature"`
+	ClearTemperature bool     `json:"clear_temperature"` // remove temperature control from the zone
+	HazardousAllowed *bool    `json:"hazardous_allowed"`
+	IsActive         *bool    `json:"is_active"`
+	Notes            *string  `json:"notes"`
+}
+
+// LocationUtilization is the load of one location against its configured capacity.
+// UtilizationPct is the highest percentage over the limited dimensions (nil when unlimited).
+type LocationUtilization struct {
+	LocationID     uint     `json:"location_id"`
+	Code           string   `json:"code"`
+	Name           string   `json:"name"`
+	LocationType   string   `json:"location_type"`
+	ZoneID         *uint    `json:"zone_id,omitempty"`
+	ZoneCode       string   `json:"zone_code,omitempty"`
+	Quantity       float64  `json:"quantity"`
+	MaxQuantity    *float64 `json:"max_quantity,omitempty"`
+	WeightKg       float64  `json:"weight_kg"`
+	MaxWeightKg    *float64 `json:"max_weight_kg,omitempty"`
+	VolumeM3       float64  `json:"volume_m3"`
+	MaxVolumeM3    *float64 `json:"max_volume_m3,omitempty"`
+	Pallets        float64  `json:"pallets"`
+	MaxPallets     *int     `json:"max_pallets,omitempty"`
+	UtilizationPct *float64 `json:"utilization_pct,omitempty"`
+	Status         string   `json:"status"` // empty, available, near_full, full, over_capacity, unlimited
+}
+
+// ZoneUtilization summarises the locations of one zone ("" code = locations without a zone)
+type ZoneUtilization struct {
+	ZoneID            *uint    `json:"zone_id,omitempty"`
+	ZoneCode          string   `json:"zone_code"`
+	ZoneName          string   `json:"zone_name"`
+	Locations         int      `json:"locations"`
+	OccupiedLocations int      `json:"occupied_locations"`
+	FullLocations     int      `json:"full_locations"`
+	WeightKg          float64  `json:"weight_kg"`
+	VolumeM3          float64  `json:"volume_m3"`
+	Pallets           float64  `json:"pallets"`
+	AvgUtilizationPct *float64 `json:"avg_utilization_pct,omitempty"` // over locations with a capacity limit
+}
+
+// WarehouseUtilizationReport is the capacity utilization of every location of a warehouse
+type WarehouseUtilizationReport struct {
+	WarehouseID uint                  `json:"warehouse_id"`
+	Summary     ZoneUtilization       `json:"summary"`
+	Zones       []ZoneUtilization     `json:"zones"`
+	Locations   []LocationUtilization `json:"locations"`
+}
@@ -0,0 +1,243 @@
+// Package mail sends outbound e-mail over SMTP. Messages are built as MIME multipart with
+// optional attachments; templates.go holds the Vietnamese/English message templates.
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Attachment is a file sent with a message
+type Attachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is an outbound e-mail; Text is the plain-text body
+type Message struct {
+	To          []string
+	Cc          []string
+	Bcc         []string
+	ReplyTo     string
+	Subject     string
+	Text        string
+	Attachments []Attachment
+}
+
+// Recipients returns every envelope recipient (To, Cc and Bcc)
+func (m *Message) Recipients() []string {
+	out := make([]string, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	out = append(out, m.To...)
+	out = append(out, m.Cc...)
+	return append(out, m.Bcc...)
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(msg *Message) error
+}
+
+// Config holds the SMTP server and sender identity
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string // address
+	FromName string
+	// TLS selects implicit TLS (port 465). Otherwise STARTTLS is used when the server offers it.
+	TLS     bool
+	Timeout time.Duration
+}
+
+// SMTPSender sends through an SMTP server. Against a local sink (MailHog, smtp4dev) leave
+// Username empty so no AUTH is attempted.
+type SMTPSender struct {
+	cfg Config
+}
+
+// NewSMTPSender validates the configuration and returns a sender
+func NewSMTPSender(cfg Config) (*SMTPSender, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("mail: SMTP host is not configured")
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("mail: invalid sender address %q", cfg.From)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &SMTPSender{cfg: cfg}, nil
+}
+
+// Send delivers msg to all its recipients in one SMTP transaction
+func (s *SMTPSender) Send(msg *Message) error {
+	rcpts := msg.Recipients()
+	if len(rcpts) == 0 {
+		return errors.New("mail: no recipients")
+	}
+	for _, r := range rcpts {
+		if _, err := mail.ParseAddress(r); err != nil {
+			return fmt.Errorf("mail: invalid recipient %q", r)
+		}
+	}
+	body, err := Build(s.cfg.From, s.cfg.FromName, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, fmt.Sprint(s.cfg.Port))
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: s.cfg.Timeout}
+	if s.cfg.TLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: s.cfg.Host})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("mail: connect %s: %w", addr, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(s.cfg.Timeout))
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mail: %w", err)
+	}
+	defer c.Close()
+
+	if !s.cfg.TLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+				return fmt.Errorf("mail: starttls: %w", err)
+			}
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("mail: auth: %w", err)
+		}
+	}
+	if err := c.Mail(s.cfg.From); err != nil {
+		return fmt.Errorf("mail: MAIL FROM: %w", err)
+	}
+	for _, r := range rcpts {
+		if err := c.Rcpt(r); err != nil {
+			return fmt.Errorf("mail: RCPT TO %s: %w", r, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("mail: DATA: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("mail: write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mail: %w", err)
+	}
+	return c.Quit()
+}
+
+// Build renders msg as an RFC 5322 message. Bcc recipients are not written to the headers.
+func Build(from, fromName string, msg *Message, date time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	header := func(k, v string) {
+		fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+	}
+	sender := (&mail.Address{Name: fromName, Address: from}).String()
+	header("From", sender)
+	header("To", addressList(msg.To))
+	if len(msg.Cc) > 0 {
+		header("Cc", addressList(msg.Cc))
+	}
+	if msg.ReplyTo != "" {
+		header("Reply-To", msg.ReplyTo)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from))
+	header("MIME-Version", "1.0")
+
+	if len(msg.Attachments) == 0 {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "base64")
+		b.WriteString("\r\n")
+		writeBase64(&b, []byte(msg.Text))
+		return b.Bytes(), nil
+	}
+
+	boundary := "==vyvy_" + randomHex(12)
+	header("Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", boundary))
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: base64\r\n\r\n")
+	writeBase64(&b, []byte(msg.Text))
+	for _, a := range msg.Attachments {
+		ct := a.ContentType
+		if ct == "" {
+			ct = "application/octet-stream"
+		}
+		name := mime.QEncoding.Encode("utf-8", a.FileName)
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		fmt.Fprintf(&b, "Content-Type: %s; name=%q\r\n", ct, name)
+		fmt.Fprintf(&b, "Content-Disposition: attachment; filename=%q\r\n", name)
+		b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+		writeBase64(&b, a.Data)
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.Bytes(), nil
+}
+
+func addressList(list []string) string {
+	out := make([]string, 0, len(list))
+	for _, a := range list {
+		if parsed, err := mail.ParseAddress(a); err == nil {
+			out = append(out, parsed.String())
+		} else {
+			out = append(out, a)
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// writeBase64 writes data base64-encoded in 76-character lines
+func writeBase64(b *bytes.Buffer, data []byte) {
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		b.WriteString(enc[:76])
+		b.WriteString("\r\n")
+		enc = enc[76:]
+	}
+	b.WriteString(enc)
+	b.WriteString("\r\n")
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), randomHex(6), domain)
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%x", buf)
+}
@@ -0,0 +1,188 @@
+package mail
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net"
+	gomail "net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSink is a minimal local SMTP server that records one message
+type smtpSink struct {
+	ln    net.Listener
+	from  string
+	rcpts []string
+	data  chan string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpSink{ln: ln, data: make(chan string, 1)}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *smtpSink) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) serve() {
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+	reply("220 sink ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250-sink")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.from = envelopeAddress(line[10:])
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.rcpts = append(s.rcpts, envelopeAddress(line[8:]))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.data <- b.String()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// envelopeAddress extracts the address from "<a@b> PARAM=..."
+func envelopeAddress(arg string) string {
+	arg = strings.TrimSpace(arg)
+	if i := strings.Index(arg, ">"); i >= 0 {
+		arg = arg[:i]
+	}
+	return strings.TrimPrefix(arg, "<")
+}
+
+func TestSMTPSenderDeliversToLocalSink(t *testing.T) {
+	sink := newSMTPSink(t)
+	sender, err := NewSMTPSender(Config{Host: "127.0.0.1", Port: sink.port(), From: "purchasing@vyvy.vn", FromName: "VyVy Mua hàng", Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("NewSMTPSender: %v", err)
+	}
+	msg := &Message{
+		To:      []string{"sales@supplier.vn"},
+		Cc:      []string{"buyer@vyvy.vn"},
+		Bcc:     []string{"archive@vyvy.vn"},
+		Subject: "Đơn đặt hàng PO-2026-000001",
+		Text:    "Kính gửi Quý công ty",
+		Attachments: []Attachment{
+			{FileName: "PO-2026-000001.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4 test")},
+		},
+	}
+	if err := sender.Send(msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	var raw string
+	select {
+	case raw = <-sink.data:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sink received no message")
+	}
+	if sink.from != "purchasing@vyvy.vn" {
+		t.Errorf("MAIL FROM = %q", sink.from)
+	}
+	if strings.Join(sink.rcpts, ",") != "sales@supplier.vn,buyer@vyvy.vn,archive@vyvy.vn" {
+		t.Errorf("RCPT TO = %v", sink.rcpts)
+	}
+
+	parsed, err := gomail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != msg.Subject {
+		t.Errorf("Subject = %q", subject)
+	}
+	if parsed.Header.Get("Bcc") != "" || strings.Contains(raw, "archive@vyvy.vn") {
+		t.Error("Bcc recipient leaked into headers")
+	}
+	if !strings.HasPrefix(parsed.Header.Get("Content-Type"), "multipart/mixed") {
+		t.Errorf("Content-Type = %q", parsed.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(raw, `filename="PO-2026-000001.pdf"`) {
+		t.Error("attachment missing")
+	}
+}
+
+func TestSendRejectsInvalidRecipient(t *testing.T) {
+	sender, err := NewSMTPSender(Config{Host: "127.0.0.1", Port: 1, From: "a@b.vn"})
+	if err != nil {
+		t.Fatalf("NewSMTPSender: %v", err)
+	}
+	if err := sender.Send(&Message{To: []string{"not an address"}}); err == nil || !strings.Contains(err.Error(), "invalid recipient") {
+		t.Errorf("expected invalid recipient error, got %v", err)
+	}
+	if _, err := NewSMTPSender(Config{From: "a@b.vn"}); err == nil {
+		t.Error("expected error without host")
+	}
+}
+
+func TestRenderTemplates(t *testing.T) {
+	data := PurchaseOrderData{
+		CompanyName:          "VyVy",
+		SupplierName:         "Hoá chất A",
+		PONumber:             "PO-2026-000007",
+		OrderDate:            "01/03/2026",
+		ExpectedDeliveryDate: "10/03/2026",
+		DaysUntilDelivery:    2,
+		OpenLines:            []OpenLine{{Code: "GLY", Name: "Glycerin", Unit: "KG", Ordered: "100", Received: "40", Outstanding: "60"}},
+	}
+	subject, body, err := Render(TemplateDeliveryReminder, "vi", data)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if subject != "[VyVy] Nhắc lịch giao hàng đơn PO-2026-000007 - 10/03/2026" {
+		t.Errorf("subject = %q", subject)
+	}
+	if !strings.Contains(body, "còn 2 ngày") || !strings.Contains(body, "GLY Glycerin: còn 60 KG") {
+		t.Errorf("body = %q", body)
+	}
+
+	subject, _, err = Render(TemplatePurchaseOrder, "en-GB", data)
+	if err != nil || subject != "[VyVy] Purchase order PO-2026-000007" {
+		t.Errorf("en subject = %q, err %v", subject, err)
+	}
+	if _, _, err := Render("unknown", "vi", data); err == nil {
+		t.Error("expected error for unknown template")
+	}
+}
@@ -0,0 +1,160 @@
+package mail
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Template names
+const (
+	TemplatePurchaseOrder    = "purchase_order"
+	TemplateDeliveryReminder = "delivery_reminder"
+)
+
+// OpenLine is an outstanding PO line listed in a delivery reminder
+type OpenLine struct {
+	Code        string
+	Name        string
+	Unit        string
+	Ordered     string
+	Received    string
+	Outstanding string
+}
+
+// PurchaseOrderData fills the purchase order and reminder templates. Amounts and dates are
+// pre-formatted by the caller.
+type PurchaseOrderData struct {
+	CompanyName          string
+	SupplierName         string
+	ContactPerson        string
+	PONumber             string
+	OrderDate            string
+	ExpectedDeliveryDate string
+	DaysUntilDelivery    int
+	TotalAmount          string
+	Currency             string
+	Message              string // free text added by the sender
+	SenderName           string
+	OpenLines            []OpenLine
+}
+
+type messageTemplate struct {
+	subject string
+	body    string
+}
+
+var templates = map[string]map[string]messageTemplate{
+	"vi": {
+		TemplatePurchaseOrder: {
+			subject: `[{{.CompanyName}}] Đơn đặt hàng {{.PONumber}}`,
+			body: `Kính gửi {{if .ContactPerson}}{{.ContactPerson}} - {{end}}{{.SupplierName}},
+
+{{.CompanyName}} gửi Quý công ty đơn đặt hàng số {{.PONumber}} ngày {{.OrderDate}}{{if .TotalAmount}}, tổng giá trị {{.TotalAmount}} {{.Currency}}{{end}}.
+{{- if .ExpectedDeliveryDate}}
+Ngày giao hàng dự kiến: {{.ExpectedDeliveryDate}}.{{end}}
+Chi tiết đơn hàng trong tệp PDF đính kèm. Vui lòng xác nhận đơn hàng và ngày giao bằng cách trả lời email này.
+{{- if .Message}}
+
+{{.Message}}{{end}}
+
+Trân trọng,
+{{if .SenderName}}{{.SenderName}}
+{{end}}{{.CompanyName}}
+`,
+		},
+		TemplateDeliveryReminder: {
+			subject: `[{{.CompanyName}}] Nhắc lịch giao hàng đơn {{.PONumber}} - {{.ExpectedDeliveryDate}}`,
+			body: `Kính gửi {{if .ContactPerson}}{{.ContactPerson}} - {{end}}{{.SupplierName}},
+
+Đơn đặt hàng {{.PONumber}} có ngày giao dự kiến {{.ExpectedDeliveryDate}}{{if eq .DaysUntilDelivery 0}} (hôm nay){{else}} (còn {{.DaysUntilDelivery}} ngày){{end}}.
+{{- if .OpenLines}}
+Các mặt hàng chưa giao:
+{{range .OpenLines}}  - {{.Code}} {{.Name}}: còn {{.Outstanding}} {{.Unit}} (đặt {{.Ordered}}, đã nhận {{.Received}})
+{{end}}{{end}}
+Vui lòng xác nhận kế hoạch giao hàng hoặc thông báo sớm nếu có thay đổi.
+{{- if .Message}}
+
+{{.Message}}{{end}}
+
+Trân trọng,
+{{if .SenderName}}{{.SenderName}}
+{{end}}{{.CompanyName}}
+`,
+		},
+	},
+	"en": {
+		TemplatePurchaseOrder: {
+			subject: `[{{.CompanyName}}] Purchase order {{.PONumber}}`,
+			body: `Dear {{if .ContactPerson}}{{.ContactPerson}}, {{end}}{{.SupplierName}},
+
+Please find attached purchase order {{.PONumber}} dated {{.OrderDate}}{{if .TotalAmount}}, total {{.TotalAmount}} {{.Currency}}{{end}}.
+{{- if .ExpectedDeliveryDate}}
+Requested delivery date: {{.ExpectedDeliveryDate}}.{{end}}
+Please confirm the order and delivery date by replying to this e-mail.
+{{- if .Message}}
+
+{{.Message}}{{end}}
+
+Best regards,
+{{if .SenderName}}{{.SenderName}}
+{{end}}{{.CompanyName}}
+`,
+		},
+		TemplateDeliveryReminder: {
+			subject: `[{{.CompanyName}}] Delivery reminder for {{.PONumber}} - {{.ExpectedDeliveryDate}}`,
+			body: `Dear {{if .ContactPerson}}{{.ContactPerson}}, {{end}}{{.SupplierName}},
+
+Purchase order {{.PONumber}} is due for delivery on {{.ExpectedDeliveryDate}}{{if eq .DaysUntilDelivery 0}} (today){{else}} (in {{.DaysUntilDelivery}} days){{end}}.
+{{- if .OpenLines}}
+Outstanding items:
+{{range .OpenLines}}  - {{.Code}} {{.Name}}: {{.Outstanding}} {{.Unit}} outstanding (ordered {{.Ordered}}, received {{.Received}})
+{{end}}{{end}}
+Please confirm the delivery schedule or let us know as soon as possible if it changes.
+{{- if .Message}}
+
+{{.Message}}{{end}}
+
+Best regards,
+{{if .SenderName}}{{.SenderName}}
+{{end}}{{.CompanyName}}
+`,
+		},
+	},
+}
+
+var parsed = func() map[string]map[string][2]*template.Template {
+	out := make(map[string]map[string][2]*template.Template)
+	for lang, set := range templates {
+		out[lang] = make(map[string][2]*template.Template)
+		for name, t := range set {
+			out[lang][name] = [2]*template.Template{
+				template.Must(template.New(name + ".subject").Parse(t.subject)),
+				template.Must(template.New(name + ".body").Parse(t.body)),
+			}
+		}
+	}
+	return out
+}()
+
+// Render fills template name in lang ("vi" or "en"; anything else falls back to Vietnamese)
+func Render(name, lang string, data interface{}) (subject, body string, err error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if !strings.HasPrefix(lang, "en") {
+		lang = "vi"
+	} else {
+		lang = "en"
+	}
+	t, ok := parsed[lang][name]
+	if !ok {
+		return "", "", fmt.Errorf("mail: unknown template %q", name)
+	}
+	var sb, bb strings.Builder
+	if err := t[0].Execute(&sb, data); err != nil {
+		return "", "", err
+	}
+	if err := t[1].Execute(&bb, data); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(sb.String()), bb.String(), nil
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// CycleCountPolicy holds the ABC thresholds, count frequencies and recount tolerance of a warehouse
+type CycleCountPolicy struct {
+	ID                  uint      `gorm:"primaryKey" json:"id"`
+	WarehouseID         uint      `gorm:"column:warehouse_id;uniqueIndex;not null" json:"warehouse_id"`
+	AValueShare         float64   `gorm:"column:a_value_share;type:decimal(5,2);not null;default:80" json:"a_value_share"`
+	BValueShare         float64   `gorm:"column:b_value_share;type:decimal(5,2);not null;default:95" json:"b_value_share"`
+	AFrequencyDays      int       `gorm:"column:a_frequency_days;not null;default:30" json:"a_frequency_days"`
+	BFrequencyDays      int       `gorm:"column:b_frequency_days;not null;default:90" json:"b_frequency_days"`
+	CFrequencyDays      int       `gorm:"column:c_frequency_days;not null;default:180" json:"c_frequency_days"`
+	LookbackDays        int       `gorm:"column:lookback_days;not null;default:365" json:"lookback_days"`
+	RecountThresholdPct float64   `gorm:"column:recount_threshold_pct;type:decimal(7,2);not null;default:2" json:"recount_threshold_pct"`
+	RecountThresholdQty float64   `gorm:"column:recount_threshold_qty;type:decimal(15,3);not null;default:0" json:"recount_threshold_qty"`
+	AutoSchedule        bool      `gorm:"column:auto_schedule;not null;default:false" json:"auto_schedule"`
+	CreatedAt           time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+func (CycleCountPolicy) TableName() string {
+	return "cycle_count_policies"
+}
+
+// ItemABCClass is the ABC class of an item in a warehouse, ranked by consumption value
+type ItemABCClass struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	WarehouseID      uint       `gorm:"column:warehouse_id;not null" json:"warehouse_id"`
+	ItemType         string     `gorm:"column:item_type;size:20;not null" json:"item_type"` // material, finished_product
+	ItemID           uint       `gorm:"column:item_id;not null" json:"item_id"`
+	ABCClass         string     `gorm:"column:abc_class;size:1;not null;default:C" json:"abc_class"`
+	ConsumptionQty   float64    `gorm:"column:consumption_qty;type:decimal(15,3);not null;default:0" json:"consumption_qty"`
+	ConsumptionValue float64    `gorm:"column:consumption_value;type:decimal(18,2);not null;default:0" json:"consumption_value"`
+	CumulativeShare  float64    `gorm:"column:cumulative_share;type:decimal(7,2);not null;default:0" json:"cumulative_share"`
+	ClassifiedAt     *time.Time `gorm:"column:classified_at" json:"classified_at,omitempty"`
+	LastCountedAt    *time.Time `gorm:"column:last_counted_at" json:"last_counted_at,omitempty"`
+	CreatedAt        time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+func (ItemABCClass) TableName() string {
+	return "item_abc_classes"
+}
+
+// CycleCountTask is the count of one stock balance (item/location/batch). The system quantity is
+// snapshotted when the count is entered and is not shown to the counter.
+type CycleCountTask struct {
+	ID                   uint       `gorm:"primaryKey" json:"id"`
+	TaskNumber           string     `gorm:"column:task_number;uniqueIndex;size:50;not null" json:"task_number"`
+	WarehouseID          uint       `gorm:"column:warehouse_id;not null" json:"warehouse_id"`
+	ItemType             string     `gorm:"column:item_type;size:20;not null" json:"item_type"`
+	ItemID               uint       `gorm:"column:item_id;not null" json:"item_id"`
+	WarehouseLocationID  *uint      `gorm:"column:warehouse_location_id" json:"warehouse_location_id,omitempty"`
+	BatchNumber          string     `gorm:"column:batch_number;size:100" json:"batch_number,omitempty"`
+	LotNumber            string     `gorm:"column:lot_number;size:100" json:"lot_number,omitempty"`
+	ABCClass             string     `gorm:"column:abc_class;size:1" json:"abc_class,omitempty"`
+	ScheduledDate        string     `gorm:"column:scheduled_date;type:date;not null" json:"scheduled_date"`
+	Status               string     `gorm:"column:status;size:20;not null;default:pending" json:"status"` // pending, recount, counted, adjusted, closed, cancelled
+	SystemQuantity       *float64   `gorm:"column:system_quantity;type:decimal(15,3)" json:"system_quantity,omitempty"`
+	CountedQuantity      *float64   `gorm:"column:counted_quantity;type:decimal(15,3)" json:"counted_quantity,omitempty"`
+	FirstCountedQuantity *float64   `gorm:"column:first_counted_quantity;type:decimal(15,3)" json:"first_counted_quantity,omitempty"`
+	VarianceQuantity     *float64   `gorm:"column:variance_quantity;type:decimal(15,3)" json:"variance_quantity,omitempty"`
+	VariancePct          *float64   `gorm:"column:variance_pct;type:decimal(9,2)" json:"variance_pct,omitempty"`
+	UnitCost             float64    `gorm:"column:unit_cost;type:decimal(15,2);not null;default:0" json:"unit_cost"`
+	VarianceValue        *float64   `gorm:"column:variance_value;type:decimal(18,2)" json:"variance_value,omitempty"`
+	RecountCount         int        `gorm:"column:recount_count;not null;default:0" json:"recount_count"`
+	CountedBy            *uint      `gorm:"column:counted_by" json:"counted_by,omitempty"`
+	CountedAt            *time.Time `gorm:"column:counted_at" json:"counted_at,omitempty"`
+	ApprovedBy           *uint      `gorm:"column:approved_by" json:"approved_by,omitempty"`
+	ApprovedAt           *time.Time `gorm:"column:approved_at" json:"approved_at,omitempty"`
+	StockAdjustmentID    *uint      `gorm:"column:stock_adjustment_id" json:"stock_adjustment_id,omitempty"`
+	Notes                string     `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	// SerialNumbers counted of a serial-tracked item
+	SerialNumbers StringSlice `gorm:"column:serial_numbers;type:jsonb" json:"serial_numbers,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	Warehouse *Warehouse         `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+	Location  *WarehouseLocation `gorm:"foreignKey:WarehouseLocationID" json:"location,omitempty"`
+}
+
+func (CycleCountTask) TableName() string {
+	return "cycle_count_tasks"
+}
@@ -74,6 +74,14 @@ type DeliveryOrderItem struct {
 	
 	// Costing
 	UnitCost         *float64  `gorm:"column:unit_cost;type:decimal(15,2)" json:"unit_cost,omitempty"`
+
+	// Kits: ExplodeKit overrides the kit's explode-on-pick setting (nil = use the kit's);
+	// KitProductID is the kit a line was exploded from when it was picked as a component
+	ExplodeKit       *bool     `gorm:"column:explode_kit" json:"explode_kit,omitempty"`
+	KitProductID     *uint     `gorm:"column:kit_product_id" json:"kit_product_id,omitempty"`
+
+	// Serials shipped on the line, for serial-tracked products
+	SerialNumbers    StringSlice `gorm:"column:serial_numbers;type:jsonb" json:"serial_numbers,omitempty"`
 	
 	// Additional info
 	Notes            string    `gorm:"column:notes;type:text" json:"notes,omitempty"`
@@ -134,6 +142,9 @@ type SafeDeliveryOrderItem struct {
 	ExpiryDate          *time.Time `json:"expiry_date,omitempty"`
 	Quantity            float64   `json:"quantity"`
 	UnitCost            *float64  `json:"unit_cost,omitempty"`
+	ExplodeKit          *bool     `json:"explode_kit,omitempty"`
+	KitProductID        *uint     `json:"kit_product_id,omitempty"`
+	SerialNumbers       []string  `json:"serial_numbers,omitempty"`
 	Notes               string    `json:"notes,omitempty"`
 }
 
@@ -187,6 +198,9 @@ func (do *DeliveryOrder) ToSafe() *SafeDeliveryOrder {
 				ExpiryDate:        item.ExpiryDate,
 				Quantity:          item.Quantity,
 				UnitCost:          item.UnitCost,
+				ExplodeKit:        item.ExplodeKit,
+				KitProductID:      item.KitProductID,
+				SerialNumbers:     item.SerialNumbers,
 				Notes:             item.Notes,
 			}
 			if item.Location != nil {
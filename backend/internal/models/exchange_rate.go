@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// BaseCurrency is the accounting currency; inventory is always costed in VND
+const BaseCurrency = "VND"
+
+// ExchangeRate is the VND value of one unit of a foreign currency, effective from RateDate
+type ExchangeRate struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Currency  string    `gorm:"column:currency;size:3;not null" json:"currency"`
+	RateDate  string    `gorm:"column:rate_date;type:date;not null" json:"rate_date"`
+	Rate      float64   `gorm:"column:rate;type:decimal(18,6);not null" json:"rate"`
+	Source    string    `gorm:"column:source;size:20;not null;default:manual" json:"source"` // manual, import
+	Notes     string    `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	UpdatedBy *uint     `gorm:"column:updated_by" json:"updated_by,omitempty"`
+}
+
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// PurchaseOrderPayment records a supplier payment against a PO and its realized FX difference
+type PurchaseOrderPayment struct {
+	ID               uint    `gorm:"primaryKey" json:"id"`
+	PurchaseOrderID  uint    `gorm:"column:purchase_order_id;not null" json:"purchase_order_id"`
+	PaymentDate      string  `gorm:"column:payment_date;type:date;not null" json:"payment_date"`
+	Amount           float64 `gorm:"column:amount;type:decimal(15,2);not null" json:"amount"`
+	Currency         string  `gorm:"column:currency;size:3;not null;default:VND" json:"currency"`
+	ExchangeRate     float64 `gorm:"column:exchange_rate;type:decimal(18,6);not null;default:1" json:"exchange_rate"`
+	BaseAmount       float64 `gorm:"column:base_amount;type:decimal(15,2);not null" json:"base_amount"`
+	BookedRate       float64 `gorm:"column:booked_rate;type:decimal(18,6);not null;default:1" json:"booked_rate"`
+	BookedBaseAmount float64 `gorm:"column:booked_base_amount;type:decimal(15,2);not null" json:"booked_base_amount"`
+	FXDifference     float64 `gorm:"column:fx_difference;type:decimal(15,2);not null" json:"fx_difference"` // > 0 loss, < 0 gain
+	Reference        string  `gorm:"column:reference;size:100" json:"reference,omitempty"`
+	Notes            string  `gorm:"column:notes;type:text" json:"notes,omitempty"`
+
+	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy     *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	CreatedByUser *User     `gorm:"foreignKey:CreatedBy" json:"created_by_user,omitempty"`
+}
+
+func (PurchaseOrderPayment) TableName() string {
+	return "purchase_order_payments"
+}
@@ -25,6 +25,10 @@ type FinishedProduct struct {
 	GrossWeight *float64 `gorm:"column:gross_weight;type:decimal(10,3)" json:"gross_weight,omitempty"`
 	Volume      *float64 `gorm:"column:volume;type:decimal(10,3)" json:"volume,omitempty"`
 
+	// Storage specs for location capacity (GrossWeight is used as the weight per unit, in kg)
+	UnitVolumeM3   *float64 `gorm:"column:unit_volume_m3;type:decimal(15,6)" json:"unit_volume_m3,omitempty"`
+	UnitsPerPallet *float64 `gorm:"column:units_per_pallet;type:decimal(15,3)" json:"units_per_pallet,omitempty"`
+
 	// Stock control
 	MinStockLevel *float64 `gorm:"column:min_stock_level;type:decimal(15,3);default:0" json:"min_stock_level,omitempty"`
 	MaxStockLevel *float64 `gorm:"column:max_stock_level;type:decimal(15,3)" json:"max_stock_level,omitempty"`
@@ -34,6 +38,8 @@ type FinishedProduct struct {
 	ShelfLifeDays      *int   `gorm:"column:shelf_life_days" json:"shelf_life_days,omitempty"`
 	StorageConditions  string `gorm:"column:storage_conditions;type:text" json:"storage_conditions,omitempty"`
 	Barcode            string `gorm:"column:barcode;size:100" json:"barcode,omitempty"`
+	// Each unit carries its own serial number, captured on receipt and selected on shipping
+	SerialTracked bool `gorm:"column:serial_tracked;default:false" json:"serial_tracked"`
 
 	// Status
 	IsActive bool   `gorm:"column:is_active;default:true" json:"is_active"`
@@ -71,12 +77,15 @@ type SafeFinishedProduct struct {
 	NetWeight   *float64 `json:"net_weight,omitempty"`
 	GrossWeight *float64 `json:"gross_weight,omitempty"`
 	Volume      *float64 `json:"volume,omitempty"`
+	UnitVolumeM3   *float64 `json:"unit_volume_m3,omitempty"`
+	UnitsPerPallet *float64 `json:"units_per_pallet,omitempty"`
 	MinStockLevel *float64 `json:"min_stock_level,omitempty"`
 	MaxStockLevel *float64 `json:"max_stock_level,omitempty"`
 	ReorderPoint  *float64 `json:"reorder_point,omitempty"`
 	ShelfLifeDays *int     `json:"shelf_life_days,omitempty"`
 	StorageConditions string `json:"storage_conditions,omitempty"`
 	Barcode      string   `json:"barcode,omitempty"`
+	SerialTracked bool    `json:"serial_tracked"`
 	IsActive     bool     `json:"is_active"`
 	Notes        string   `json:"notes,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
@@ -100,12 +109,15 @@ func (fp *FinishedProduct) ToSafe() *SafeFinishedProduct {
 		NetWeight:   fp.NetWeight,
 		GrossWeight: fp.GrossWeight,
 		Volume:      fp.Volume,
+		UnitVolumeM3:   fp.UnitVolumeM3,
+		UnitsPerPallet: fp.UnitsPerPallet,
 		MinStockLevel: fp.MinStockLevel,
 		MaxStockLevel: fp.MaxStockLevel,
 		ReorderPoint:  fp.ReorderPoint,
 		ShelfLifeDays: fp.ShelfLifeDays,
 		StorageConditions: fp.StorageConditions,
 		Barcode:     fp.Barcode,
+		SerialTracked: fp.SerialTracked,
 		IsActive:    fp.IsActive,
 		Notes:       fp.Notes,
 		CreatedAt:   fp.CreatedAt,
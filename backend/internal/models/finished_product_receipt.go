@@ -42,6 +42,8 @@ type FinishedProductReceiptItem struct {
 	ManufactureDate    *string `gorm:"column:manufacture_date;type:date" json:"manufacture_date,omitempty"`
 	ExpiryDate         *string `gorm:"column:expiry_date;type:date" json:"expiry_date,omitempty"`
 	UnitCost           float64 `gorm:"column:unit_cost;type:decimal(15,2);default:0" json:"unit_cost"`
+	// Serials received on the line, for serial-tracked products
+	SerialNumbers      StringSlice `gorm:"column:serial_numbers;type:jsonb" json:"serial_numbers,omitempty"`
 	Notes              string  `gorm:"column:notes;type:text" json:"notes,omitempty"`
 
 	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
@@ -28,6 +28,13 @@ type GoodsReceiptNote struct {
 	PostedBy *uint      `gorm:"column:posted_by" json:"posted_by,omitempty"`
 	PostedAt *time.Time `gorm:"column:posted_at" json:"posted_at,omitempty"`
 
+	// Currency of item unit costs; ExchangeRate (VND per unit) is fixed at posting
+	Currency     string  `gorm:"column:currency;size:3;not null;default:VND" json:"currency"`
+	ExchangeRate float64 `gorm:"column:exchange_rate;type:decimal(18,6);not null;default:1" json:"exchange_rate"`
+
+	// Replacement delivery for a purchase return
+	PurchaseReturnID *uint `gorm:"column:purchase_return_id" json:"purchase_return_id,omitempty"`
+
 	// Additional info
 	Notes string `gorm:"column:notes;type:text" json:"notes,omitempty"`
 
@@ -54,46 +61,52 @@ func (GoodsReceiptNote) TableName() string {
 
 // SafeGoodsReceiptNote is a DTO that includes safe information
 type SafeGoodsReceiptNote struct {
-	ID              uint                        `json:"id"`
-	GRNNumber       string                      `json:"grn_number"`
-	PurchaseOrderID *uint                       `json:"purchase_order_id,omitempty"`
-	WarehouseID     uint                        `json:"warehouse_id"`
-	PurchaseOrder   *SafePurchaseOrder          `json:"purchase_order,omitempty"`
-	Warehouse       *SafeWarehouse              `json:"warehouse,omitempty"`
-	ReceiptDate     string                      `json:"receipt_date"`
-	Status          string                      `json:"status"`
-	QCStatus        string                      `json:"qc_status,omitempty"`
-	QCApprovedBy    *uint                       `json:"qc_approved_by,omitempty"`
-	QCApprovedAt    *time.Time                  `json:"qc_approved_at,omitempty"`
-	QCNotes         string                      `json:"qc_notes,omitempty"`
-	Posted          bool                        `json:"posted"`
-	PostedBy        *uint                       `json:"posted_by,omitempty"`
-	PostedAt        *time.Time                  `json:"posted_at,omitempty"`
-	Notes           string                      `json:"notes,omitempty"`
-	Items           []*SafeGoodsReceiptNoteItem `json:"items,omitempty"`
-	CreatedAt       time.Time                   `json:"created_at"`
-	UpdatedAt       time.Time                   `json:"updated_at"`
+	ID               uint                        `json:"id"`
+	GRNNumber        string                      `json:"grn_number"`
+	PurchaseOrderID  *uint                       `json:"purchase_order_id,omitempty"`
+	WarehouseID      uint                        `json:"warehouse_id"`
+	PurchaseOrder    *SafePurchaseOrder          `json:"purchase_order,omitempty"`
+	Warehouse        *SafeWarehouse              `json:"warehouse,omitempty"`
+	ReceiptDate      string                      `json:"receipt_date"`
+	Status           string                      `json:"status"`
+	QCStatus         string                      `json:"qc_status,omitempty"`
+	QCApprovedBy     *uint                       `json:"qc_approved_by,omitempty"`
+	QCApprovedAt     *time.Time                  `json:"qc_approved_at,omitempty"`
+	QCNotes          string                      `json:"qc_notes,omitempty"`
+	Posted           bool                        `json:"posted"`
+	PostedBy         *uint                       `json:"posted_by,omitempty"`
+	PostedAt         *time.Time                  `json:"posted_at,omitempty"`
+	Currency         string                      `json:"currency"`
+	PurchaseReturnID *uint                       `json:"purchase_return_id,omitempty"`
+	ExchangeRate     float64                     `json:"exchange_rate"`
+	Notes            string                      `json:"notes,omitempty"`
+	Items            []*SafeGoodsReceiptNoteItem `json:"items,omitempty"`
+	CreatedAt        time.Time                   `json:"created_at"`
+	UpdatedAt        time.Time                   `json:"updated_at"`
 }
 
 // ToSafe converts GoodsReceiptNote to SafeGoodsReceiptNote
 func (grn *GoodsReceiptNote) ToSafe() *SafeGoodsReceiptNote {
 	safe := &SafeGoodsReceiptNote{
-		ID:              grn.ID,
-		GRNNumber:       grn.GRNNumber,
-		PurchaseOrderID: grn.PurchaseOrderID,
-		WarehouseID:     grn.WarehouseID,
-		ReceiptDate:     grn.ReceiptDate,
-		Status:          grn.Status,
-		QCStatus:        grn.QCStatus,
-		QCApprovedBy:    grn.QCApprovedBy,
-		QCApprovedAt:    grn.QCApprovedAt,
-		QCNotes:         grn.QCNotes,
-		Posted:          grn.Posted,
-		PostedBy:        grn.PostedBy,
-		PostedAt:        grn.PostedAt,
-		Notes:           grn.Notes,
-		CreatedAt:       grn.CreatedAt,
-		UpdatedAt:       grn.UpdatedAt,
+		ID:               grn.ID,
+		GRNNumber:        grn.GRNNumber,
+		PurchaseOrderID:  grn.PurchaseOrderID,
+		WarehouseID:      grn.WarehouseID,
+		ReceiptDate:      grn.ReceiptDate,
+		Status:           grn.Status,
+		QCStatus:         grn.QCStatus,
+		QCApprovedBy:     grn.QCApprovedBy,
+		QCApprovedAt:     grn.QCApprovedAt,
+		QCNotes:          grn.QCNotes,
+		Posted:           grn.Posted,
+		PostedBy:         grn.PostedBy,
+		PostedAt:         grn.PostedAt,
+		Currency:         grn.Currency,
+		PurchaseReturnID: grn.PurchaseReturnID,
+		ExchangeRate:     grn.ExchangeRate,
+		Notes:            grn.Notes,
+		CreatedAt:        grn.CreatedAt,
+		UpdatedAt:        grn.UpdatedAt,
 	}
 
 	if grn.PurchaseOrder != nil {
@@ -23,8 +23,12 @@ type GoodsReceiptNoteItem struct {
 	ManufactureDate *string    `gorm:"column:manufacture_date;type:date" json:"manufacture_date,omitempty"`
 	ExpiryDate      *string    `gorm:"column:expiry_date;type:date" json:"expiry_date,omitempty"`
 
-	// Pricing
-	UnitCost float64 `gorm:"column:unit_cost;type:decimal(15,2);not null" json:"unit_cost"`
+	// Serials received on the line, for serial-tracked materials
+	SerialNumbers StringSlice `gorm:"column:serial_numbers;type:jsonb" json:"serial_numbers,omitempty"`
+
+	// Pricing: UnitCost is in the GRN currency, BaseUnitCost is the VND cost fixed at posting
+	UnitCost     float64  `gorm:"column:unit_cost;type:decimal(15,2);not null" json:"unit_cost"`
+	BaseUnitCost *float64 `gorm:"column:base_unit_cost;type:decimal(15,2)" json:"base_unit_cost,omitempty"`
 
 	// QC
 	QCStatus string `gorm:"column:qc_status;size:50" json:"qc_status,omitempty"`
@@ -68,7 +72,9 @@ type SafeGoodsReceiptNoteItem struct {
 	LotNumber           string               `json:"lot_number,omitempty"`
 	ManufactureDate     *string              `json:"manufacture_date,omitempty"`
 	ExpiryDate          *string              `json:"expiry_date,omitempty"`
+	SerialNumbers       []string             `json:"serial_numbers,omitempty"`
 	UnitCost            float64              `json:"unit_cost"`
+	BaseUnitCost        *float64             `json:"base_unit_cost,omitempty"`
 	QCStatus            string               `json:"qc_status,omitempty"`
 	QCNotes             string               `json:"qc_notes,omitempty"`
 	Notes               string               `json:"notes,omitempty"`
@@ -91,7 +97,9 @@ func (item *GoodsReceiptNoteItem) ToSafe() *SafeGoodsReceiptNoteItem {
 		LotNumber:           item.LotNumber,
 		ManufactureDate:     item.ManufactureDate,
 		ExpiryDate:          item.ExpiryDate,
+		SerialNumbers:       item.SerialNumbers,
 		UnitCost:            item.UnitCost,
+		BaseUnitCost:        item.BaseUnitCost,
 		QCStatus:            item.QCStatus,
 		QCNotes:             item.QCNotes,
 		Notes:               item.Notes,
@@ -115,3 +123,11 @@ func (item *GoodsReceiptNoteItem) ToSafe() *SafeGoodsReceiptNoteItem {
 
 	return safe
 }
+
+// CostInBase returns the VND unit cost: the posted base cost, or UnitCost for VND / unposted lines
+func (item *GoodsReceiptNoteItem) CostInBase() float64 {
+	if item.BaseUnitCost != nil {
+		return *item.BaseUnitCost
+	}
+	return item.UnitCost
+}
@@ -0,0 +1,99 @@
+package models
+
+import "time"
+
+// Kit makes a finished product (the kit, e.g. a cleanser + toner + serum combo) out of other
+// finished products. Kits are assembled in the warehouse with a KitOrder; with ExplodeOnPick a
+// delivery order line for the kit is picked as its components instead of assembled kits.
+type Kit struct {
+	ID            uint   `gorm:"primaryKey" json:"id"`
+	KitProductID  uint   `gorm:"column:kit_product_id;uniqueIndex;not null" json:"kit_product_id"`
+	ExplodeOnPick bool   `gorm:"column:explode_on_pick;not null;default:false" json:"explode_on_pick"`
+	IsActive      bool   `gorm:"column:is_active;not null;default:true" json:"is_active"`
+	Notes         string `gorm:"column:notes;type:text" json:"notes,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	UpdatedBy *uint     `gorm:"column:updated_by" json:"updated_by,omitempty"`
+
+	KitProduct *FinishedProduct `gorm:"foreignKey:KitProductID" json:"kit_product,omitempty"`
+	Components []*KitComponent  `gorm:"foreignKey:KitID" json:"components,omitempty"`
+}
+
+func (Kit) TableName() string {
+	return "kits"
+}
+
+// KitComponent is the quantity of a component product that goes into one kit
+type KitComponent struct {
+	ID                 uint    `gorm:"primaryKey" json:"id"`
+	KitID              uint    `gorm:"column:kit_id;not null" json:"kit_id"`
+	ComponentProductID uint    `gorm:"column:component_product_id;not null" json:"component_product_id"`
+	Quantity           float64 `gorm:"column:quantity;type:decimal(15,3);not null" json:"quantity"`
+	Sequence           int     `gorm:"column:sequence;not null;default:0" json:"sequence"`
+	Notes              string  `gorm:"column:notes;type:text" json:"notes,omitempty"`
+
+	Product *FinishedProduct `gorm:"foreignKey:ComponentProductID" json:"product,omitempty"`
+}
+
+func (KitComponent) TableName() string {
+	return "kit_components"
+}
+
+// KitOrder assembles kits from their components or takes kits apart again. Posting moves all the
+// stock at once: an assembly consumes the components and receives the kits at their rolled-up
+// cost, a disassembly does the reverse.
+type KitOrder struct {
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	OrderNumber         string     `gorm:"column:order_number;uniqueIndex;size:50;not null" json:"order_number"`
+	OrderType           string     `gorm:"column:order_type;size:20;not null" json:"order_type"` // assembly, disassembly
+	KitID               uint       `gorm:"column:kit_id;not null" json:"kit_id"`
+	KitProductID        uint       `gorm:"column:kit_product_id;not null" json:"kit_product_id"`
+	WarehouseID         uint       `gorm:"column:warehouse_id;not null" json:"warehouse_id"`
+	Quantity            float64    `gorm:"column:quantity;type:decimal(15,3);not null" json:"quantity"`
+	KitLocationID       *uint      `gorm:"column:kit_location_id" json:"kit_location_id,omitempty"`
+	KitBatchNumber      string     `gorm:"column:kit_batch_number;size:100" json:"kit_batch_number,omitempty"`
+	KitLotNumber        string     `gorm:"column:kit_lot_number;size:100" json:"kit_lot_number,omitempty"`
+	ComponentLocationID *uint      `gorm:"column:component_location_id" json:"component_location_id,omitempty"`
+	Status              string     `gorm:"column:status;size:20;not null;default:draft" json:"status"` // draft, posted, cancelled
+	UnitCost            float64    `gorm:"column:unit_cost;type:decimal(15,2);not null;default:0" json:"unit_cost"`
+	TotalCost           float64    `gorm:"column:total_cost;type:decimal(15,2);not null;default:0" json:"total_cost"`
+	ExpiryDate          *string    `gorm:"column:expiry_date;type:date" json:"expiry_date,omitempty"`
+	Notes               string     `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	PostedBy            *uint      `gorm:"column:posted_by" json:"posted_by,omitempty"`
+	PostedAt            *time.Time `gorm:"column:posted_at" json:"posted_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	Kit               *Kit               `gorm:"foreignKey:KitID" json:"-"`
+	KitProduct        *FinishedProduct   `gorm:"foreignKey:KitProductID" json:"kit_product,omitempty"`
+	Warehouse         *Warehouse         `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+	KitLocation       *WarehouseLocation `gorm:"foreignKey:KitLocationID" json:"kit_location,omitempty"`
+	ComponentLocation *WarehouseLocation `gorm:"foreignKey:ComponentLocationID" json:"component_location,omitempty"`
+	Lines             []*KitOrderLine    `gorm:"foreignKey:OrderID" json:"lines,omitempty"`
+}
+
+func (KitOrder) TableName() string {
+	return "kit_orders"
+}
+
+// KitOrderLine is a component of the order, copied from the kit when the order is created. The
+// costs are filled in when the order is posted.
+type KitOrderLine struct {
+	ID                 uint    `gorm:"primaryKey" json:"id"`
+	OrderID            uint    `gorm:"column:order_id;not null" json:"order_id"`
+	ComponentProductID uint    `gorm:"column:component_product_id;not null" json:"component_product_id"`
+	QuantityPerKit     float64 `gorm:"column:quantity_per_kit;type:decimal(15,3);not null" json:"quantity_per_kit"`
+	Quantity           float64 `gorm:"column:quantity;type:decimal(15,3);not null" json:"quantity"`
+	UnitCost           float64 `gorm:"column:unit_cost;type:decimal(15,2);not null;default:0" json:"unit_cost"`
+	TotalCost          float64 `gorm:"column:total_cost;type:decimal(15,2);not null;default:0" json:"total_cost"`
+
+	Product *FinishedProduct `gorm:"foreignKey:ComponentProductID" json:"product,omitempty"`
+}
+
+func (KitOrderLine) TableName() string {
+	return "kit_order_lines"
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Label types
+const (
+	LabelTypeGRNItem   = "grn_item"
+	LabelTypeFPRNBatch = "fprn_batch"
+	LabelTypeLocation  = "location"
+	LabelTypePallet    = "pallet"
+)
+
+// Label output formats
+const (
+	LabelFormatZPL = "zpl"
+	LabelFormatPDF = "pdf"
+)
+
+// LabelTemplate is the size and layout of a label for one label type: a ZPL template for thermal
+// printers or a grid of labels on A4 sheets for laser printers
+type LabelTemplate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Code         string    `gorm:"column:code;uniqueIndex;size:50;not null" json:"code"`
+	Name         string    `gorm:"column:name;size:255;not null" json:"name"`
+	LabelType    string    `gorm:"column:label_type;size:20;not null" json:"label_type"`
+	Format       string    `gorm:"column:format;size:10;not null" json:"format"`
+	WidthMM      float64   `gorm:"column:width_mm;type:decimal(7,2);not null" json:"width_mm"`
+	HeightMM     float64   `gorm:"column:height_mm;type:decimal(7,2);not null" json:"height_mm"`
+	Dpmm         int       `gorm:"column:dpmm;not null;default:8" json:"dpmm"`
+	BarcodeType  string    `gorm:"column:barcode_type;size:20;not null;default:code128" json:"barcode_type"`
+	Body         string    `gorm:"column:body;type:text" json:"body,omitempty"`
+	SheetColumns int       `gorm:"column:sheet_columns;not null;default:1" json:"sheet_columns"`
+	SheetRows    int       `gorm:"column:sheet_rows;not null;default:1" json:"sheet_rows"`
+	MarginLeftMM float64   `gorm:"column:margin_left_mm;type:decimal(7,2);not null;default:0" json:"margin_left_mm"`
+	MarginTopMM  float64   `gorm:"column:margin_top_mm;type:decimal(7,2);not null;default:0" json:"margin_top_mm"`
+	GapXMM       float64   `gorm:"column:gap_x_mm;type:decimal(7,2);not null;default:0" json:"gap_x_mm"`
+	GapYMM       float64   `gorm:"column:gap_y_mm;type:decimal(7,2);not null;default:0" json:"gap_y_mm"`
+	IsDefault    bool      `gorm:"column:is_default;not null;default:false" json:"is_default"`
+	IsActive     bool      `gorm:"column:is_active;not null" json:"is_active"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy    *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	UpdatedBy    *uint     `gorm:"column:updated_by" json:"updated_by,omitempty"`
+}
+
+func (LabelTemplate) TableName() string {
+	return "label_templates"
+}
+
+// LabelContent is the JSONB list of labels as printed
+type LabelContent []byte
+
+func (c LabelContent) Value() (driver.Value, error) {
+	if len(c) == 0 {
+		return "[]", nil
+	}
+	return string(c), nil
+}
+
+func (c *LabelContent) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+	case []byte:
+		*c = append(LabelContent(nil), v...)
+	case string:
+		*c = LabelContent(v)
+	default:
+		return fmt.Errorf("unsupported type: %T", value)
+	}
+	return nil
+}
+
+func (c LabelContent) MarshalJSON() ([]byte, error) {
+	if len(c) == 0 {
+		return []byte("[]"), nil
+	}
+	return c, nil
+}
+
+// LabelPrintLog records one print or reprint; Content keeps the labels so a reprint is identical,
+// including the SSCCs of pallet labels
+type LabelPrintLog struct {
+	ID            uint         `gorm:"primaryKey" json:"id"`
+	LabelType     string       `gorm:"column:label_type;size:20;not null" json:"label_type"`
+	Format        string       `gorm:"column:format;size:10;not null" json:"format"`
+	TemplateID    *uint        `gorm:"column:template_id" json:"template_id,omitempty"`
+	SourceType    string       `gorm:"column:source_type;size:30" json:"source_type,omitempty"` // grn, fprn, warehouse, location, item
+	SourceID      *uint        `gorm:"column:source_id" json:"source_id,omitempty"`
+	SourceNumber  string       `gorm:"column:source_number;size:50" json:"source_number,omitempty"`
+	LabelCount    int          `gorm:"column:label_count;not null;default:0" json:"label_count"`
+	Copies        int          `gorm:"column:copies;not null;default:1" json:"copies"`
+	Content       LabelContent `gorm:"column:content;type:jsonb;not null" json:"content"`
+	ReprintOfID   *uint        `gorm:"column:reprint_of_id" json:"reprint_of_id,omitempty"`
+	Reason        string       `gorm:"column:reason;type:text" json:"reason,omitempty"`
+	PrintedBy     *uint        `gorm:"column:printed_by" json:"printed_by,omitempty"`
+	PrintedByName string       `gorm:"column:printed_by_name;size:100" json:"printed_by_name,omitempty"`
+	PrintedAt     time.Time    `gorm:"column:printed_at;not null" json:"printed_at"`
+
+	Template *LabelTemplate `gorm:"foreignKey:TemplateID" json:"template,omitempty"`
+}
+
+func (LabelPrintLog) TableName() string {
+	return "label_print_logs"
+}
@@ -0,0 +1,101 @@
+package models
+
+import "time"
+
+// LandedCostVoucher collects freight, import duty and customs charges paid outside the PO
+// and allocates them onto the stock received by one or more posted GRNs
+type LandedCostVoucher struct {
+	ID               uint    `gorm:"primaryKey" json:"id"`
+	VoucherNumber    string  `gorm:"column:voucher_number;uniqueIndex;size:50;not null" json:"voucher_number"`
+	VoucherDate      string  `gorm:"column:voucher_date;type:date;not null" json:"voucher_date"`
+	AllocationMethod string  `gorm:"column:allocation_method;size:20;not null;default:value" json:"allocation_method"` // quantity, value, weight
+	Status           string  `gorm:"column:status;size:50;not null;default:draft" json:"status"`                       // draft, posted, cancelled
+	TotalAmount      float64 `gorm:"column:total_amount;type:decimal(15,2);not null;default:0" json:"total_amount"`
+
+	// Posting
+	Posted   bool       `gorm:"column:posted;default:false" json:"posted"`
+	PostedBy *uint      `gorm:"column:posted_by" json:"posted_by,omitempty"`
+	PostedAt *time.Time `gorm:"column:posted_at" json:"posted_at,omitempty"`
+
+	Notes string `gorm:"column:notes;type:text" json:"notes,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	UpdatedBy *uint     `gorm:"column:updated_by" json:"updated_by,omitempty"`
+
+	// Relationships
+	PostedByUser *User                   `gorm:"foreignKey:PostedBy" json:"posted_by_user,omitempty"`
+	GRNs         []*LandedCostVoucherGRN `gorm:"foreignKey:VoucherID" json:"grns,omitempty"`
+	Charges      []*LandedCostCharge     `gorm:"foreignKey:VoucherID" json:"charges,omitempty"`
+	Allocations  []*LandedCostAllocation `gorm:"foreignKey:VoucherID" json:"allocations,omitempty"`
+}
+
+func (LandedCostVoucher) TableName() string {
+	return "landed_cost_vouchers"
+}
+
+// LandedCostVoucherGRN links a voucher to a posted GRN
+type LandedCostVoucherGRN struct {
+	ID        uint `gorm:"primaryKey" json:"id"`
+	VoucherID uint `gorm:"column:voucher_id;not null" json:"voucher_id"`
+	GRNID     uint `gorm:"column:grn_id;not null" json:"grn_id"`
+
+	GRN *GoodsReceiptNote `gorm:"foreignKey:GRNID" json:"grn,omitempty"`
+}
+
+func (LandedCostVoucherGRN) TableName() string {
+	return "landed_cost_voucher_grns"
+}
+
+// LandedCostCharge is a single charge line on a voucher (freight, import_duty, customs_fee, insurance, other)
+type LandedCostCharge struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	VoucherID   uint      `gorm:"column:voucher_id;not null" json:"voucher_id"`
+	ChargeType  string    `gorm:"column:charge_type;size:50;not null" json:"charge_type"`
+	Description string    `gorm:"column:description;type:text" json:"description,omitempty"`
+	Amount      float64   `gorm:"column:amount;type:decimal(15,2);not null;default:0" json:"amount"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+func (LandedCostCharge) TableName() string {
+	return "landed_cost_charges"
+}
+
+// LandedCostAllocation is the share of the voucher total assigned to one GRN line.
+// Only the part still on hand in the receiving batch is capitalized into stock_balance;
+// the rest (already issued) is reported as expensed.
+type LandedCostAllocation struct {
+	ID                  uint   `gorm:"primaryKey" json:"id"`
+	VoucherID           uint   `gorm:"column:voucher_id;not null" json:"voucher_id"`
+	GRNID               uint   `gorm:"column:grn_id;not null" json:"grn_id"`
+	GRNItemID           uint   `gorm:"column:grn_item_id;not null" json:"grn_item_id"`
+	MaterialID          uint   `gorm:"column:material_id;not null" json:"material_id"`
+	WarehouseID         uint   `gorm:"column:warehouse_id;not null" json:"warehouse_id"`
+	WarehouseLocationID *uint  `gorm:"column:warehouse_location_id" json:"warehouse_location_id,omitempty"`
+	BatchNumber         string `gorm:"column:batch_number;size:100" json:"batch_number,omitempty"`
+	LotNumber           string `gorm:"column:lot_number;size:100" json:"lot_number,omitempty"`
+
+	// Allocation basis
+	BasisQuantity float64 `gorm:"column:basis_quantity;type:decimal(15,3)" json:"basis_quantity"`
+	BasisValue    float64 `gorm:"column:basis_value;type:decimal(15,2)" json:"basis_value"`
+	BasisWeight   float64 `gorm:"column:basis_weight;type:decimal(15,3)" json:"basis_weight"`
+
+	AllocatedAmount float64 `gorm:"column:allocated_amount;type:decimal(15,2)" json:"allocated_amount"`
+
+	// Posting result
+	CapitalizedAmount float64  `gorm:"column:capitalized_amount;type:decimal(15,2)" json:"capitalized_amount"`
+	ExpensedAmount    float64  `gorm:"column:expensed_amount;type:decimal(15,2)" json:"expensed_amount"`
+	OnHandQuantity    float64  `gorm:"column:on_hand_quantity;type:decimal(15,3)" json:"on_hand_quantity"`
+	PreviousUnitCost  *float64 `gorm:"column:previous_unit_cost;type:decimal(15,2)" json:"previous_unit_cost,omitempty"`
+	NewUnitCost       *float64 `gorm:"column:new_unit_cost;type:decimal(15,2)" json:"new_unit_cost,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+
+	// Relationships
+	Material *Material `gorm:"foreignKey:MaterialID" json:"material,omitempty"`
+}
+
+func (LandedCostAllocation) TableName() string {
+	return "landed_cost_allocations"
+}
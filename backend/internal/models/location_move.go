@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// LocationMove moves stock between locations of one warehouse. Unlike a StockTransfer it never
+// leaves the warehouse, so there is no shipping, receipt or in-transit stock.
+type LocationMove struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	MoveNumber  string     `gorm:"column:move_number;uniqueIndex;size:50;not null" json:"move_number"`
+	WarehouseID uint       `gorm:"column:warehouse_id;not null" json:"warehouse_id"`
+	Source      string     `gorm:"column:source;size:20;not null;default:manual" json:"source"` // manual, scan, consolidation
+	Status      string     `gorm:"column:status;size:20;not null;default:draft" json:"status"`  // draft, posted, cancelled
+	Notes       string     `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	PostedBy    *uint      `gorm:"column:posted_by" json:"posted_by,omitempty"`
+	PostedAt    *time.Time `gorm:"column:posted_at" json:"posted_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	Warehouse *Warehouse          `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+	Items     []*LocationMoveItem `gorm:"foreignKey:MoveID" json:"items,omitempty"`
+}
+
+func (LocationMove) TableName() string {
+	return "location_moves"
+}
+
+// LocationMoveItem is one item, batch and lot moved from one location to another
+type LocationMoveItem struct {
+	ID             uint    `gorm:"primaryKey" json:"id"`
+	MoveID         uint    `gorm:"column:move_id;not null" json:"move_id"`
+	ItemType       string  `gorm:"column:item_type;size:20;not null" json:"item_type"` // material, finished_product
+	ItemID         uint    `gorm:"column:item_id;not null" json:"item_id"`
+	BatchNumber    string  `gorm:"column:batch_number;size:100" json:"batch_number,omitempty"`
+	LotNumber      string  `gorm:"column:lot_number;size:100" json:"lot_number,omitempty"`
+	FromLocationID uint    `gorm:"column:from_location_id;not null" json:"from_location_id"`
+	ToLocationID   uint    `gorm:"column:to_location_id;not null" json:"to_location_id"`
+	Quantity       float64 `gorm:"column:quantity;type:decimal(15,3);not null" json:"quantity"`
+	UnitCost       float64 `gorm:"column:unit_cost;type:decimal(15,2);not null;default:0" json:"unit_cost"`
+	Notes          string  `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	// SerialNumbers of the units moved; without them the posting records the serials it took
+	SerialNumbers StringSlice `gorm:"column:serial_numbers;type:jsonb" json:"serial_numbers,omitempty"`
+
+	FromLocation *WarehouseLocation `gorm:"foreignKey:FromLocationID" json:"from_location,omitempty"`
+	ToLocation   *WarehouseLocation `gorm:"foreignKey:ToLocationID" json:"to_location,omitempty"`
+}
+
+func (LocationMoveItem) TableName() string {
+	return "location_move_items"
+}
@@ -28,11 +28,22 @@ type Material struct {
 	ReorderPoint    *float64 `gorm:"type:decimal(15,3)" json:"reorder_point,omitempty"`
 	ReorderQuantity *float64 `gorm:"type:decimal(15,3)" json:"reorder_quantity,omitempty"`
 
+	// Receiving tolerances in percent; nil = use the supplier's
+	OverReceiptTolerance  *float64 `gorm:"type:decimal(5,2)" json:"over_receipt_tolerance,omitempty"`
+	UnderReceiptTolerance *float64 `gorm:"type:decimal(5,2)" json:"under_receipt_tolerance,omitempty"`
+
 	// Quality & Safety
 	RequiresQC         bool    `gorm:"default:false" json:"requires_qc"`
 	ShelfLifeDays      *int    `json:"shelf_life_days,omitempty"`
 	StorageConditions  *string `gorm:"type:text" json:"storage_conditions,omitempty"`
 	Hazardous          bool    `gorm:"default:false" json:"hazardous"`
+	// Each unit carries its own serial number, captured on receipt and selected on issue
+	SerialTracked bool `gorm:"default:false" json:"serial_tracked"`
+
+	// Storage specs for location capacity; weight defaults from the unit (KG, G) when nil
+	UnitWeightKg   *float64 `gorm:"type:decimal(15,6)" json:"unit_weight_kg,omitempty"`
+	UnitVolumeM3   *float64 `gorm:"type:decimal(15,6)" json:"unit_volume_m3,omitempty"`
+	UnitsPerPallet *float64 `gorm:"type:decimal(15,3)" json:"units_per_pallet,omitempty"`
 
 	// Status
 	IsActive *bool   `gorm:"default:true;index" json:"is_active"`
@@ -86,10 +97,18 @@ type SafeMaterial struct {
 	MaxStockLevel      *float64 `json:"max_stock_level,omitempty"`
 	ReorderPoint       *float64 `json:"reorder_point,omitempty"`
 	ReorderQuantity    *float64 `json:"reorder_quantity,omitempty"`
+
+	OverReceiptTolerance  *float64 `json:"over_receipt_tolerance,omitempty"`
+	UnderReceiptTolerance *float64 `json:"under_receipt_tolerance,omitempty"`
+
 	RequiresQC         bool     `json:"requires_qc"`
 	ShelfLifeDays      *int     `json:"shelf_life_days,omitempty"`
 	StorageConditions  *string  `json:"storage_conditions,omitempty"`
 	Hazardous          bool     `json:"hazardous"`
+	SerialTracked      bool     `json:"serial_tracked"`
+	UnitWeightKg       *float64 `json:"unit_weight_kg,omitempty"`
+	UnitVolumeM3       *float64 `json:"unit_volume_m3,omitempty"`
+	UnitsPerPallet     *float64 `json:"units_per_pallet,omitempty"`
 	IsActive           *bool                  `json:"is_active"`
 	Notes              *string                `json:"notes,omitempty"`
 	Suppliers          []SafeMaterialSupplier `json:"suppliers,omitempty"`
@@ -115,10 +134,18 @@ func (m *Material) ToSafe() *SafeMaterial {
 		MaxStockLevel:     m.MaxStockLevel,
 		ReorderPoint:      m.ReorderPoint,
 		ReorderQuantity:   m.ReorderQuantity,
+
+		OverReceiptTolerance:  m.OverReceiptTolerance,
+		UnderReceiptTolerance: m.UnderReceiptTolerance,
+
 		RequiresQC:        m.RequiresQC,
 		ShelfLifeDays:     m.ShelfLifeDays,
 		StorageConditions: m.StorageConditions,
 		Hazardous:         m.Hazardous,
+		SerialTracked:     m.SerialTracked,
+		UnitWeightKg:      m.UnitWeightKg,
+		UnitVolumeM3:      m.UnitVolumeM3,
+		UnitsPerPallet:    m.UnitsPerPallet,
 		IsActive:          m.IsActive,
 		Notes:             m.Notes,
 		CreatedAt:         m.CreatedAt.Format(time.RFC3339),
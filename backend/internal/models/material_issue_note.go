@@ -61,6 +61,9 @@ type MaterialIssueNoteItem struct {
 	// Quantity
 	Quantity float64 `gorm:"column:quantity;type:decimal(15,3);not null" json:"quantity"`
 
+	// Serials issued on the line, for serial-tracked materials
+	SerialNumbers StringSlice `gorm:"column:serial_numbers;type:jsonb" json:"serial_numbers,omitempty"`
+
 	// Costing
 	UnitCost *float64 `gorm:"column:unit_cost;type:decimal(15,2)" json:"unit_cost,omitempty"`
 
@@ -115,6 +118,7 @@ type SafeMaterialIssueNoteItem struct {
 	LotNumber           string               `json:"lot_number,omitempty"`
 	ExpiryDate          *string              `json:"expiry_date,omitempty"`
 	Quantity            float64              `json:"quantity"`
+	SerialNumbers       []string             `json:"serial_numbers,omitempty"`
 	UnitCost            *float64             `json:"unit_cost,omitempty"`
 	Notes               string               `json:"notes,omitempty"`
 }
@@ -166,6 +170,7 @@ func (mini *MaterialIssueNoteItem) ToSafe() *SafeMaterialIssueNoteItem {
 		LotNumber:           mini.LotNumber,
 		ExpiryDate:          mini.ExpiryDate,
 		Quantity:            mini.Quantity,
+		SerialNumbers:       mini.SerialNumbers,
 		UnitCost:            mini.UnitCost,
 		Notes:               mini.Notes,
 	}
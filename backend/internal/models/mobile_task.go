@@ -0,0 +1,123 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Mobile task types: the handheld view of putaway tasks, pick lists, cycle count tasks and stock transfers.
+// A transfer is shipped from the source warehouse as a transfer task and received at the destination as a receive task.
+const (
+	MobileTaskPutaway  = "putaway"
+	MobileTaskPick     = "pick"
+	MobileTaskCount    = "count"
+	MobileTaskTransfer = "transfer"
+	MobileTaskReceive  = "receive"
+)
+
+// Mobile task assignment statuses
+const (
+	MobileAssignmentActive    = "active"
+	MobileAssignmentReleased  = "released"
+	MobileAssignmentCompleted = "completed"
+)
+
+// MobileTaskAssignment is the operator working a task. A task has at most one active assignment;
+// released and completed ones are kept as history.
+type MobileTaskAssignment struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	TaskType       string     `gorm:"column:task_type;size:20;not null" json:"task_type"`
+	TaskID         uint       `gorm:"column:task_id;not null" json:"task_id"`
+	WarehouseID    *uint      `gorm:"column:warehouse_id" json:"warehouse_id,omitempty"`
+	AssignedTo     uint       `gorm:"column:assigned_to;not null" json:"assigned_to"`
+	AssignedToName string     `gorm:"column:assigned_to_name;size:100" json:"assigned_to_name,omitempty"`
+	AssignedBy     *uint      `gorm:"column:assigned_by" json:"assigned_by,omitempty"`
+	Status         string     `gorm:"column:status;size:20;not null;default:active" json:"status"`
+	ClaimedAt      time.Time  `gorm:"column:claimed_at;not null" json:"claimed_at"`
+	ClosedAt       *time.Time `gorm:"column:closed_at" json:"closed_at,omitempty"`
+}
+
+func (MobileTaskAssignment) TableName() string {
+	return "mobile_task_assignments"
+}
+
+// MobileResponse is the JSONB response stored with a step confirmation and returned on a retry
+type MobileResponse []byte
+
+func (r MobileResponse) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return "{}", nil
+	}
+	return string(r), nil
+}
+
+func (r *MobileResponse) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*r = nil
+	case []byte:
+		*r = append(MobileResponse(nil), v...)
+	case string:
+		*r = MobileResponse(v)
+	default:
+		return fmt.Errorf("unsupported type: %T", value)
+	}
+	return nil
+}
+
+func (r MobileResponse) MarshalJSON() ([]byte, error) {
+	if len(r) == 0 {
+		return []byte("{}"), nil
+	}
+	return r, nil
+}
+
+// MobileStepConfirmation records a confirmed step under the device's request ID, so a retried
+// request is answered from here instead of being applied twice
+type MobileStepConfirmation struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	RequestID   string         `gorm:"column:request_id;uniqueIndex;size:64;not null" json:"request_id"`
+	UserID      uint           `gorm:"column:user_id;not null" json:"user_id"`
+	TaskType    string         `gorm:"column:task_type;size:20;not null" json:"task_type"`
+	TaskID      uint           `gorm:"column:task_id;not null" json:"task_id"`
+	StepID      uint           `gorm:"column:step_id;not null;default:0" json:"step_id"`
+	RequestHash string         `gorm:"column:request_hash;size:64;not null" json:"-"`
+	Response    MobileResponse `gorm:"column:response;type:jsonb;not null" json:"response"`
+	ConfirmedAt time.Time      `gorm:"column:confirmed_at;not null" json:"confirmed_at"`
+}
+
+func (MobileStepConfirmation) TableName() string {
+	return "mobile_step_confirmations"
+}
+
+// Mobile exception statuses
+const (
+	MobileExceptionOpen     = "open"
+	MobileExceptionResolved = "resolved"
+)
+
+// MobileTaskException is a problem reported from the floor while working a task
+type MobileTaskException struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	TaskType       string     `gorm:"column:task_type;size:20;not null" json:"task_type"`
+	TaskID         uint       `gorm:"column:task_id;not null" json:"task_id"`
+	TaskNumber     string     `gorm:"column:task_number;size:50" json:"task_number,omitempty"`
+	StepID         uint       `gorm:"column:step_id;not null;default:0" json:"step_id,omitempty"`
+	WarehouseID    *uint      `gorm:"column:warehouse_id" json:"warehouse_id,omitempty"`
+	ExceptionType  string     `gorm:"column:exception_type;size:30;not null" json:"exception_type"` // damaged, missing, wrong_item, location_blocked, short, other
+	Quantity       *float64   `gorm:"column:quantity;type:decimal(15,3)" json:"quantity,omitempty"`
+	ScannedCode    string     `gorm:"column:scanned_code;size:255" json:"scanned_code,omitempty"`
+	Notes          string     `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	Status         string     `gorm:"column:status;size:20;not null;default:open" json:"status"`
+	ReportedBy     *uint      `gorm:"column:reported_by" json:"reported_by,omitempty"`
+	ReportedByName string     `gorm:"column:reported_by_name;size:100" json:"reported_by_name,omitempty"`
+	ReportedAt     time.Time  `gorm:"column:reported_at;not null" json:"reported_at"`
+	ResolvedBy     *uint      `gorm:"column:resolved_by" json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time `gorm:"column:resolved_at" json:"resolved_at,omitempty"`
+	Resolution     string     `gorm:"column:resolution;type:text" json:"resolution,omitempty"`
+}
+
+func (MobileTaskException) TableName() string {
+	return "mobile_task_exceptions"
+}
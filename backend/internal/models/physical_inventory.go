@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// PhysicalInventory is a full stocktake of one warehouse. While it is counting, stock postings to
+// the warehouse are frozen; finalizing posts one physical_count adjustment for all variances.
+type PhysicalInventory struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	InventoryNumber   string     `gorm:"column:inventory_number;uniqueIndex;size:50;not null" json:"inventory_number"`
+	WarehouseID       uint       `gorm:"column:warehouse_id;not null" json:"warehouse_id"`
+	Status            string     `gorm:"column:status;size:20;not null;default:counting" json:"status"` // counting, finalized, cancelled
+	SnapshotAt        time.Time  `gorm:"column:snapshot_at;not null" json:"snapshot_at"`
+	Notes             string     `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	StockAdjustmentID *uint      `gorm:"column:stock_adjustment_id" json:"stock_adjustment_id,omitempty"`
+	GainValue         float64    `gorm:"column:gain_value;type:decimal(18,2);not null;default:0" json:"gain_value"`
+	LossValue         float64    `gorm:"column:loss_value;type:decimal(18,2);not null;default:0" json:"loss_value"`
+	NetValue          float64    `gorm:"column:net_value;type:decimal(18,2);not null;default:0" json:"net_value"`
+	FinalizedBy       *uint      `gorm:"column:finalized_by" json:"finalized_by,omitempty"`
+	FinalizedAt       *time.Time `gorm:"column:finalized_at" json:"finalized_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	Warehouse *Warehouse               `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+	Lines     []*PhysicalInventoryLine `gorm:"foreignKey:InventoryID" json:"lines,omitempty"`
+}
+
+func (PhysicalInventory) TableName() string {
+	return "physical_inventories"
+}
+
+// PhysicalInventoryLine is one snapshotted balance (location/item/batch) with its latest count.
+// Lines of the same location share a sheet number.
+type PhysicalInventoryLine struct {
+	ID                  uint     `gorm:"primaryKey" json:"id"`
+	InventoryID         uint     `gorm:"column:inventory_id;not null" json:"inventory_id"`
+	SheetNumber         int      `gorm:"column:sheet_number;not null;default:1" json:"sheet_number"`
+	WarehouseLocationID *uint    `gorm:"column:warehouse_location_id" json:"warehouse_location_id,omitempty"`
+	LocationPath        string   `gorm:"column:location_path;size:255" json:"location_path,omitempty"`
+	ItemType            string   `gorm:"column:item_type;size:20;not null" json:"item_type"`
+	ItemID              uint     `gorm:"column:item_id;not null" json:"item_id"`
+	BatchNumber         string   `gorm:"column:batch_number;size:100" json:"batch_number,omitempty"`
+	LotNumber           string   `gorm:"column:lot_number;size:100" json:"lot_number,omitempty"`
+	ExpiryDate          *string  `gorm:"column:expiry_date;type:date" json:"expiry_date,omitempty"`
+	SnapshotQuantity    float64  `gorm:"column:snapshot_quantity;type:decimal(15,3);not null;default:0" json:"snapshot_quantity"`
+	UnitCost            float64  `gorm:"column:unit_cost;type:decimal(15,2);not null;default:0" json:"unit_cost"`
+	CountedQuantity     *float64 `gorm:"column:counted_quantity;type:decimal(15,3)" json:"counted_quantity,omitempty"`
+	CountRounds         int      `gorm:"column:count_rounds;not null;default:0" json:"count_rounds"`
+	VarianceQuantity    *float64 `gorm:"column:variance_quantity;type:decimal(15,3)" json:"variance_quantity,omitempty"`
+	VarianceValue       *float64 `gorm:"column:variance_value;type:decimal(18,2)" json:"variance_value,omitempty"`
+	AddedDuringCount    bool     `gorm:"column:added_during_count;not null;default:false" json:"added_during_count"`
+	// SerialNumbers counted in the latest round of a serial-tracked item
+	SerialNumbers StringSlice `gorm:"column:serial_numbers;type:jsonb" json:"serial_numbers,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	Counts []*PhysicalInventoryCount `gorm:"foreignKey:LineID" json:"counts,omitempty"`
+}
+
+func (PhysicalInventoryLine) TableName() string {
+	return "physical_inventory_lines"
+}
+
+// PhysicalInventoryCount is one counting round of a line
+type PhysicalInventoryCount struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	LineID          uint      `gorm:"column:line_id;not null" json:"line_id"`
+	Round           int       `gorm:"column:round;not null" json:"round"`
+	CountedQuantity float64   `gorm:"column:counted_quantity;type:decimal(15,3);not null" json:"counted_quantity"`
+	CountedBy       *uint     `gorm:"column:counted_by" json:"counted_by,omitempty"`
+	CountedAt       time.Time `gorm:"column:counted_at;autoCreateTime" json:"counted_at"`
+	Notes           string    `gorm:"column:notes;type:text" json:"notes,omitempty"`
+}
+
+func (PhysicalInventoryCount) TableName() string {
+	return "physical_inventory_counts"
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// PickList groups the picking work for one or more delivery orders of a warehouse.
+// Lines are allocated by FEFO and ordered by location path.
+type PickList struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	PickNumber  string     `gorm:"column:pick_number;uniqueIndex;size:50;not null" json:"pick_number"`
+	WarehouseID uint       `gorm:"column:warehouse_id;not null" json:"warehouse_id"`
+	Status      string     `gorm:"column:status;size:20;not null;default:open" json:"status"` // open, in_progress, completed, cancelled
+	Notes       string     `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	CompletedBy *uint      `gorm:"column:completed_by" json:"completed_by,omitempty"`
+	CompletedAt *time.Time `gorm:"column:completed_at" json:"completed_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	Warehouse *Warehouse      `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+	Lines     []*PickListLine `gorm:"foreignKey:PickListID" json:"lines,omitempty"`
+}
+
+func (PickList) TableName() string {
+	return "pick_lists"
+}
+
+// PickListLine is one location/batch to pick for a delivery order item. A line without a
+// location and status "short" records quantity that could not be allocated.
+type PickListLine struct {
+	ID                  uint    `gorm:"primaryKey" json:"id"`
+	PickListID          uint    `gorm:"column:pick_list_id;not null" json:"pick_list_id"`
+	Sequence            int     `gorm:"column:sequence;not null;default:0" json:"sequence"`
+	DeliveryOrderID     uint    `gorm:"column:delivery_order_id;not null" json:"delivery_order_id"`
+	DeliveryOrderItemID uint    `gorm:"column:delivery_order_item_id;not null" json:"delivery_order_item_id"`
+	DONumber            string  `gorm:"column:do_number;size:50" json:"do_number,omitempty"`
+	FinishedProductID   uint    `gorm:"column:finished_product_id;not null" json:"finished_product_id"`
+	KitProductID        *uint   `gorm:"column:kit_product_id" json:"kit_product_id,omitempty"` // kit the component is picked for
+	LocationID          *uint   `gorm:"column:location_id" json:"location_id,omitempty"`
+	LocationPath        string  `gorm:"column:location_path;size:255" json:"location_path,omitempty"`
+	BatchNumber         string  `gorm:"column:batch_number;size:100" json:"batch_number,omitempty"`
+	LotNumber           string  `gorm:"column:lot_number;size:100" json:"lot_number,omitempty"`
+	ExpiryDate          *string `gorm:"column:expiry_date;type:date" json:"expiry_date,omitempty"`
+	Quantity            float64 `gorm:"column:quantity;type:decimal(15,3);not null" json:"quantity"`
+	PickedQuantity      float64 `gorm:"column:picked_quantity;type:decimal(15,3);not null;default:0" json:"picked_quantity"`
+	Status              string  `gorm:"column:status;size:20;not null;default:pending" json:"status"` // pending, picked, short
+	ShortReason         string  `gorm:"column:short_reason;size:255" json:"short_reason,omitempty"`
+
+	// Set when the picker took the stock from another location/batch than allocated
+	Substituted          bool   `gorm:"column:substituted;not null;default:false" json:"substituted"`
+	AllocatedLocationID  *uint  `gorm:"column:allocated_location_id" json:"allocated_location_id,omitempty"`
+	AllocatedBatchNumber string `gorm:"column:allocated_batch_number;size:100" json:"allocated_batch_number,omitempty"`
+	AllocatedLotNumber   string `gorm:"column:allocated_lot_number;size:100" json:"allocated_lot_number,omitempty"`
+
+	PickedBy  *uint      `gorm:"column:picked_by" json:"picked_by,omitempty"`
+	PickedAt  *time.Time `gorm:"column:picked_at" json:"picked_at,omitempty"`
+	Notes     string     `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	Product  *FinishedProduct   `gorm:"foreignKey:FinishedProductID" json:"product,omitempty"`
+	Location *WarehouseLocation `gorm:"foreignKey:LocationID" json:"location,omitempty"`
+}
+
+func (PickListLine) TableName() string {
+	return "pick_list_lines"
+}
@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// PickWave batches open delivery orders (by carrier, sales channel or cut-off time) into one
+// consolidated pick; picked stock is sorted back per order and the wave is shipped in one go.
+type PickWave struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	WaveNumber     string     `gorm:"column:wave_number;uniqueIndex;size:50;not null" json:"wave_number"`
+	WarehouseID    uint       `gorm:"column:warehouse_id;not null" json:"warehouse_id"`
+	PickListID     *uint      `gorm:"column:pick_list_id" json:"pick_list_id,omitempty"`
+	CarrierID      *uint      `gorm:"column:carrier_id" json:"carrier_id,omitempty"`
+	SalesChannelID *uint      `gorm:"column:sales_channel_id" json:"sales_channel_id,omitempty"`
+	CutoffAt       *time.Time `gorm:"column:cutoff_at" json:"cutoff_at,omitempty"`
+	DeliveryDate   *string    `gorm:"column:delivery_date;type:date" json:"delivery_date,omitempty"`
+	Status         string     `gorm:"column:status;size:20;not null;default:picking" json:"status"` // picking, picked, sorted, partially_shipped, shipped, cancelled
+	Notes          string     `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	PickedAt       *time.Time `gorm:"column:picked_at" json:"picked_at,omitempty"`
+	SortedAt       *time.Time `gorm:"column:sorted_at" json:"sorted_at,omitempty"`
+	ShippedAt      *time.Time `gorm:"column:shipped_at" json:"shipped_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	Warehouse    *Warehouse       `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+	Carrier      *Carrier         `gorm:"foreignKey:CarrierID" json:"carrier,omitempty"`
+	SalesChannel *SalesChannel    `gorm:"foreignKey:SalesChannelID" json:"sales_channel,omitempty"`
+	Orders       []*PickWaveOrder `gorm:"foreignKey:WaveID" json:"orders,omitempty"`
+	Lines        []*PickWaveLine  `gorm:"foreignKey:WaveID" json:"lines,omitempty"`
+}
+
+func (PickWave) TableName() string {
+	return "pick_waves"
+}
+
+// PickWaveOrder is a delivery order of the wave with its sort/pack and shipping result
+type PickWaveOrder struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	WaveID          uint       `gorm:"column:wave_id;not null" json:"wave_id"`
+	DeliveryOrderID uint       `gorm:"column:delivery_order_id;not null" json:"delivery_order_id"`
+	DONumber        string     `gorm:"column:do_number;size:50" json:"do_number,omitempty"`
+	Sequence        int        `gorm:"column:sequence;not null;default:0" json:"sequence"`
+	OrderedQuantity float64    `gorm:"column:ordered_quantity;type:decimal(15,3);not null;default:0" json:"ordered_quantity"`
+	PackedQuantity  float64    `gorm:"column:packed_quantity;type:decimal(15,3);not null;default:0" json:"packed_quantity"`
+	Status          string     `gorm:"column:status;size:20;not null;default:pending" json:"status"` // pending, packed, short, released, shipped, failed
+	ErrorMessage    string     `gorm:"column:error_message;type:text" json:"error_message,omitempty"`
+	ShippedAt       *time.Time `gorm:"column:shipped_at" json:"shipped_at,omitempty"`
+	CreatedAt       time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+func (PickWaveOrder) TableName() string {
+	return "pick_wave_orders"
+}
+
+// PickWaveLine is the consolidated quantity of one product/location/batch to pick for the whole wave
+type PickWaveLine struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	WaveID            uint       `gorm:"column:wave_id;not null" json:"wave_id"`
+	Sequence          int        `gorm:"column:sequence;not null;default:0" json:"sequence"`
+	FinishedProductID uint       `gorm:"column:finished_product_id;not null" json:"finished_product_id"`
+	LocationID        *uint      `gorm:"column:location_id" json:"location_id,omitempty"`
+	LocationPath      string     `gorm:"column:location_path;size:255" json:"location_path,omitempty"`
+	BatchNumber       string     `gorm:"column:batch_number;size:100" json:"batch_number,omitempty"`
+	LotNumber         string     `gorm:"column:lot_number;size:100" json:"lot_number,omitempty"`
+	ExpiryDate        *string    `gorm:"column:expiry_date;type:date" json:"expiry_date,omitempty"`
+	Quantity          float64    `gorm:"column:quantity;type:decimal(15,3);not null" json:"quantity"`
+	PickedQuantity    float64    `gorm:"column:picked_quantity;type:decimal(15,3);not null;default:0" json:"picked_quantity"`
+	Status            string     `gorm:"column:status;size:20;not null;default:pending" json:"status"` // pending, picked, short
+	ShortReason       string     `gorm:"column:short_reason;size:255" json:"short_reason,omitempty"`
+	PickedBy          *uint      `gorm:"column:picked_by" json:"picked_by,omitempty"`
+	PickedAt          *time.Time `gorm:"column:picked_at" json:"picked_at,omitempty"`
+	CreatedAt         time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	Product  *FinishedProduct   `gorm:"foreignKey:FinishedProductID" json:"product,omitempty"`
+	Location *WarehouseLocation `gorm:"foreignKey:LocationID" json:"location,omitempty"`
+}
+
+func (PickWaveLine) TableName() string {
+	return "pick_wave_lines"
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// POEmailLog records an e-mail sent to the supplier about a purchase order
+type POEmailLog struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	PurchaseOrderID uint       `gorm:"column:purchase_order_id;not null" json:"purchase_order_id"`
+	SupplierID      *uint      `gorm:"column:supplier_id" json:"supplier_id,omitempty"`
+	MessageType     string     `gorm:"column:message_type;size:30;not null" json:"message_type"` // purchase_order, delivery_reminder
+	Recipients      string     `gorm:"column:recipients;type:text;not null" json:"recipients"`
+	Cc              string     `gorm:"column:cc;type:text" json:"cc,omitempty"`
+	Subject         string     `gorm:"column:subject;size:500;not null" json:"subject"`
+	Body            string     `gorm:"column:body;type:text" json:"body,omitempty"`
+	AttachmentName  string     `gorm:"column:attachment_name;size:255" json:"attachment_name,omitempty"`
+	ReminderFor     *string    `gorm:"column:reminder_for;type:date" json:"reminder_for,omitempty"`
+	Status          string     `gorm:"column:status;size:20;not null" json:"status"` // sent, failed
+	ErrorMessage    string     `gorm:"column:error_message;type:text" json:"error_message,omitempty"`
+	SentBy          *uint      `gorm:"column:sent_by" json:"sent_by,omitempty"`
+	SentAt          *time.Time `gorm:"column:sent_at" json:"sent_at,omitempty"`
+	CreatedAt       time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+
+	PurchaseOrder *PurchaseOrder `gorm:"foreignKey:PurchaseOrderID" json:"-"`
+	SentByUser    *User          `gorm:"foreignKey:SentBy" json:"sent_by_user,omitempty"`
+}
+
+func (POEmailLog) TableName() string {
+	return "po_email_logs"
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// PurchaseAgreement is a blanket (yearly) contract with a supplier: fixed prices and
+// committed quantities per material, drawn down by call-off purchase orders
+type PurchaseAgreement struct {
+	ID              uint    `gorm:"primaryKey" json:"id"`
+	AgreementNumber string  `gorm:"column:agreement_number;uniqueIndex;size:50;not null" json:"agreement_number"`
+	SupplierID      uint    `gorm:"column:supplier_id;not null" json:"supplier_id"`
+	StartDate       string  `gorm:"column:start_date;type:date;not null" json:"start_date"`
+	EndDate         string  `gorm:"column:end_date;type:date;not null" json:"end_date"`
+	Status          string  `gorm:"column:status;size:50;not null;default:draft" json:"status"`  // draft, active, closed, cancelled
+	Currency        string  `gorm:"column:currency;size:3;not null;default:VND" json:"currency"` // of the agreed prices; call-off POs must use it
+	CommittedValue  float64 `gorm:"column:committed_value;type:decimal(15,2);not null;default:0" json:"committed_value"`
+	PaymentTerms    string  `gorm:"column:payment_terms;size:100" json:"payment_terms,omitempty"`
+	Notes           string  `gorm:"column:notes;type:text" json:"notes,omitempty"`
+
+	// Approval
+	ApprovedBy *uint      `gorm:"column:approved_by" json:"approved_by,omitempty"`
+	ApprovedAt *time.Time `gorm:"column:approved_at" json:"approved_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	UpdatedBy *uint     `gorm:"column:updated_by" json:"updated_by,omitempty"`
+
+	// Consumption by call-off POs (computed, not stored)
+	ConsumedValue  float64  `gorm:"-" json:"consumed_value"`
+	RemainingValue float64  `gorm:"-" json:"remaining_value"`
+	Warnings       []string `gorm:"-" json:"warnings,omitempty"`
+
+	// Relationships
+	Supplier       *Supplier                `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
+	ApprovedByUser *User                    `gorm:"foreignKey:ApprovedBy" json:"approved_by_user,omitempty"`
+	Items          []*PurchaseAgreementItem `gorm:"foreignKey:AgreementID" json:"items,omitempty"`
+}
+
+func (PurchaseAgreement) TableName() string {
+	return "purchase_agreements"
+}
+
+// ItemFor returns the agreement line for a material, or nil when the material is not covered
+func (a *PurchaseAgreement) ItemFor(materialID uint) *PurchaseAgreementItem {
+	for _, item := range a.Items {
+		if item.MaterialID == materialID {
+			return item
+		}
+	}
+	return nil
+}
+
+// PurchaseAgreementItem is an agreed price and committed quantity for one material
+type PurchaseAgreementItem struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	AgreementID       uint      `gorm:"column:agreement_id;not null" json:"agreement_id"`
+	MaterialID        uint      `gorm:"column:material_id;not null" json:"material_id"`
+	AgreedPrice       float64   `gorm:"column:agreed_price;type:decimal(15,2);not null" json:"agreed_price"`
+	TaxRate           float64   `gorm:"column:tax_rate;type:decimal(5,2);default:0" json:"tax_rate"`
+	DiscountRate      float64   `gorm:"column:discount_rate;type:decimal(5,2);default:0" json:"discount_rate"`
+	CommittedQuantity float64   `gorm:"column:committed_quantity;type:decimal(15,3);not null" json:"committed_quantity"`
+	Notes             string    `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	CreatedAt         time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+
+	// Consumption by call-off POs (computed, not stored)
+	ConsumedQuantity  float64 `gorm:"-" json:"consumed_quantity"`
+	ReceivedQuantity  float64 `gorm:"-" json:"received_quantity"`
+	RemainingQuantity float64 `gorm:"-" json:"remaining_quantity"`
+	ConsumedValue     float64 `gorm:"-" json:"consumed_value"`
+
+	Material *Material `gorm:"foreignKey:MaterialID" json:"material,omitempty"`
+}
+
+func (PurchaseAgreementItem) TableName() string {
+	return "purchase_agreement_items"
+}
@@ -12,6 +12,12 @@ type PurchaseOrder struct {
 	WarehouseID uint  `gorm:"column:warehouse_id;not null" json:"warehouse_id"`
 	POType     string `gorm:"column:po_type;size:50;default:material" json:"po_type,omitempty"`
 
+	// Call-off against a blanket purchase agreement (prices come from the agreement)
+	AgreementID *uint `gorm:"column:agreement_id" json:"agreement_id,omitempty"`
+
+	// Origin of the order: manual, replenishment (draft raised below reorder point)
+	Source string `gorm:"column:source;size:20;not null;default:manual" json:"source"`
+
 	// Dates
 	OrderDate            string  `gorm:"column:order_date;type:date;not null" json:"order_date"`
 	ExpectedDeliveryDate *string `gorm:"column:expected_delivery_date;type:date" json:"expected_delivery_date,omitempty"`
@@ -33,6 +39,10 @@ type PurchaseOrder struct {
 	TotalAmount    float64 `gorm:"column:total_amount;type:decimal(15,2);default:0" json:"total_amount"`
 	VATRate        float64 `gorm:"column:vat_rate;type:decimal(5,2);default:0" json:"vat_rate,omitempty"`
 
+	// Currency: amounts above are in Currency; ExchangeRate is VND per unit on the order date
+	Currency     string  `gorm:"column:currency;size:3;not null;default:VND" json:"currency"`
+	ExchangeRate float64 `gorm:"column:exchange_rate;type:decimal(18,6);not null;default:1" json:"exchange_rate"`
+
 	// Additional info
 	Description    string `gorm:"column:description;type:text" json:"description,omitempty"`
 	PaymentTerms   string `gorm:"column:payment_terms;size:100" json:"payment_terms,omitempty"`
@@ -76,6 +86,8 @@ type SafePurchaseOrder struct {
 	SupplierID           uint                      `json:"supplier_id"`
 	WarehouseID          uint                      `json:"warehouse_id"`
 	POType               string                    `json:"po_type,omitempty"`
+	AgreementID          *uint                     `json:"agreement_id,omitempty"`
+	Source               string                    `json:"source"`
 	Supplier             *SafeSupplier             `json:"supplier,omitempty"`
 	Warehouse            *SafeWarehouse            `json:"warehouse,omitempty"`
 	OrderDate            string                    `json:"order_date"`
@@ -91,6 +103,8 @@ type SafePurchaseOrder struct {
 	DiscountAmount       float64                   `json:"discount_amount"`
 	TotalAmount          float64                   `json:"total_amount"`
 	VATRate              float64                   `json:"vat_rate,omitempty"`
+	Currency             string                    `json:"currency"`
+	ExchangeRate         float64                   `json:"exchange_rate"`
 	Description          string                    `json:"description,omitempty"`
 	PaymentTerms         string                    `json:"payment_terms,omitempty"`
 	ShippingMethod       string                    `json:"shipping_method,omitempty"`
@@ -117,6 +131,8 @@ func (po *PurchaseOrder) ToSafe() *SafePurchaseOrder {
 		SupplierID:           po.SupplierID,
 		WarehouseID:          po.WarehouseID,
 		POType:               po.POType,
+		AgreementID:          po.AgreementID,
+		Source:               po.Source,
 		OrderDate:            po.OrderDate,
 		ExpectedDeliveryDate: po.ExpectedDeliveryDate,
 		Status:               po.Status,
@@ -130,6 +146,8 @@ func (po *PurchaseOrder) ToSafe() *SafePurchaseOrder {
 		DiscountAmount:       po.DiscountAmount,
 		TotalAmount:          po.TotalAmount,
 		VATRate:              po.VATRate,
+		Currency:             po.Currency,
+		ExchangeRate:         po.ExchangeRate,
 		Description:          po.Description,
 		PaymentTerms:         po.PaymentTerms,
 		ShippingMethod:       po.ShippingMethod,
@@ -20,6 +20,12 @@ type PurchaseOrderItem struct {
 	// Fulfillment tracking
 	ReceivedQuantity float64 `gorm:"column:received_quantity;type:decimal(15,3);default:0" json:"received_quantity"`
 
+	// Close short: the remaining quantity will not be delivered
+	ClosedShort      bool       `gorm:"column:closed_short;not null;default:false" json:"closed_short"`
+	ClosedShortAt    *time.Time `gorm:"column:closed_short_at" json:"closed_short_at,omitempty"`
+	ClosedShortBy    *uint      `gorm:"column:closed_short_by" json:"closed_short_by,omitempty"`
+	CloseShortReason string     `gorm:"column:close_short_reason;type:text" json:"close_short_reason,omitempty"`
+
 	// Additional info
 	Notes                string  `gorm:"column:notes;type:text" json:"notes,omitempty"`
 	ExpectedDeliveryDate *string `gorm:"column:expected_delivery_date;type:date" json:"expected_delivery_date,omitempty"`
@@ -53,6 +59,9 @@ type SafePurchaseOrderItem struct {
 	DiscountRate         float64        `json:"discount_rate"`
 	LineTotal            float64        `json:"line_total"`
 	ReceivedQuantity     float64        `json:"received_quantity"`
+	ClosedShort          bool           `json:"closed_short"`
+	ClosedShortAt        *time.Time     `json:"closed_short_at,omitempty"`
+	CloseShortReason     string         `json:"close_short_reason,omitempty"`
 	Notes                string         `json:"notes,omitempty"`
 	ExpectedDeliveryDate *string        `json:"expected_delivery_date,omitempty"`
 	Attachments          string         `json:"attachments,omitempty"`
@@ -72,6 +81,9 @@ func (item *PurchaseOrderItem) ToSafe() *SafePurchaseOrderItem {
 		DiscountRate:         item.DiscountRate,
 		LineTotal:            item.LineTotal,
 		ReceivedQuantity:     item.ReceivedQuantity,
+		ClosedShort:          item.ClosedShort,
+		ClosedShortAt:        item.ClosedShortAt,
+		CloseShortReason:     item.CloseShortReason,
 		Notes:                item.Notes,
 		ExpectedDeliveryDate: item.ExpectedDeliveryDate,
 		Attachments:          item.Attachments,
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// PurchaseReturn sends QC-rejected or posted stock back to the supplier. Lines resolved by
+// credit produce a debit note; lines resolved by replacement wait for a replacement GRN.
+type PurchaseReturn struct {
+	ID              uint   `gorm:"primaryKey" json:"id"`
+	ReturnNumber    string `gorm:"column:return_number;uniqueIndex;size:50;not null" json:"return_number"`
+	SupplierID      uint   `gorm:"column:supplier_id;not null" json:"supplier_id"`
+	PurchaseOrderID *uint  `gorm:"column:purchase_order_id" json:"purchase_order_id,omitempty"`
+	GRNID           *uint  `gorm:"column:grn_id" json:"grn_id,omitempty"`
+	WarehouseID     uint   `gorm:"column:warehouse_id;not null" json:"warehouse_id"`
+	ReturnDate      string `gorm:"column:return_date;type:date;not null" json:"return_date"`
+	Source          string `gorm:"column:source;size:20;not null;default:stock" json:"source"` // grn_rejected, stock
+	Status          string `gorm:"column:status;size:50;not null;default:draft" json:"status"` // draft, posted, cancelled
+	Reason          string `gorm:"column:reason;type:text" json:"reason,omitempty"`
+
+	// Debit note (lines with resolution = credit)
+	Currency            string  `gorm:"column:currency;size:3;not null;default:VND" json:"currency"`
+	ExchangeRate        float64 `gorm:"column:exchange_rate;type:decimal(18,6);not null;default:1" json:"exchange_rate"`
+	DebitNoteNumber     string  `gorm:"column:debit_note_number;size:50" json:"debit_note_number,omitempty"`
+	DebitNoteAmount     float64 `gorm:"column:debit_note_amount;type:decimal(15,2);not null;default:0" json:"debit_note_amount"`
+	DebitNoteBaseAmount float64 `gorm:"column:debit_note_base_amount;type:decimal(15,2);not null;default:0" json:"debit_note_base_amount"`
+
+	// Replacement tracking: none, pending, partial, received
+	ReplacementStatus string `gorm:"column:replacement_status;size:20;not null;default:none" json:"replacement_status"`
+
+	// Posting
+	Posted   bool       `gorm:"column:posted;default:false" json:"posted"`
+	PostedBy *uint      `gorm:"column:posted_by" json:"posted_by,omitempty"`
+	PostedAt *time.Time `gorm:"column:posted_at" json:"posted_at,omitempty"`
+
+	Notes string `gorm:"column:notes;type:text" json:"notes,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	UpdatedBy *uint     `gorm:"column:updated_by" json:"updated_by,omitempty"`
+
+	// Relationships
+	Supplier      *Supplier             `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
+	PurchaseOrder *PurchaseOrder        `gorm:"foreignKey:PurchaseOrderID" json:"purchase_order,omitempty"`
+	GRN           *GoodsReceiptNote     `gorm:"foreignKey:GRNID" json:"grn,omitempty"`
+	Warehouse     *Warehouse            `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+	PostedByUser  *User                 `gorm:"foreignKey:PostedBy" json:"posted_by_user,omitempty"`
+	Items         []*PurchaseReturnItem `gorm:"foreignKey:PurchaseReturnID" json:"items,omitempty"`
+}
+
+func (PurchaseReturn) TableName() string {
+	return "purchase_returns"
+}
+
+// PurchaseReturnItem is one returned material/batch line
+type PurchaseReturnItem struct {
+	ID                  uint      `gorm:"primaryKey" json:"id"`
+	PurchaseReturnID    uint      `gorm:"column:purchase_return_id;not null" json:"purchase_return_id"`
+	GRNItemID           *uint     `gorm:"column:grn_item_id" json:"grn_item_id,omitempty"`
+	POItemID            *uint     `gorm:"column:po_item_id" json:"po_item_id,omitempty"`
+	MaterialID          uint      `gorm:"column:material_id;not null" json:"material_id"`
+	WarehouseLocationID *uint     `gorm:"column:warehouse_location_id" json:"warehouse_location_id,omitempty"`
+	BatchNumber         string    `gorm:"column:batch_number;size:100" json:"batch_number,omitempty"`
+	LotNumber           string    `gorm:"column:lot_number;size:100" json:"lot_number,omitempty"`
+	Quantity            float64   `gorm:"column:quantity;type:decimal(15,3);not null" json:"quantity"`
+	UnitCost            float64   `gorm:"column:unit_cost;type:decimal(15,2);not null;default:0" json:"unit_cost"`
+	BaseUnitCost        *float64  `gorm:"column:base_unit_cost;type:decimal(15,2)" json:"base_unit_cost,omitempty"`
+	Resolution          string    `gorm:"column:resolution;size:20;not null;default:credit" json:"resolution"` // credit, replacement
+	ReplacedQuantity    float64   `gorm:"column:replaced_quantity;type:decimal(15,3);not null;default:0" json:"replaced_quantity"`
+	Reason              string    `gorm:"column:reason;type:text" json:"reason,omitempty"`
+	CreatedAt           time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+
+	// SerialNumbers of the units returned from stock of a serial-tracked material
+	SerialNumbers StringSlice `gorm:"column:serial_numbers;type:jsonb" json:"serial_numbers,omitempty"`
+
+	Material          *Material          `gorm:"foreignKey:MaterialID" json:"material,omitempty"`
+	WarehouseLocation *WarehouseLocation `gorm:"foreignKey:WarehouseLocationID" json:"warehouse_location,omitempty"`
+}
+
+func (PurchaseReturnItem) TableName() string {
+	return "purchase_return_items"
+}
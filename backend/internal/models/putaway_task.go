@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// PutawayTask moves stock received by a GRN or FPRN from the receiving area (or "no location")
+// to its final storage location. A task may be confirmed in several splits.
+type PutawayTask struct {
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	TaskNumber          string     `gorm:"column:task_number;uniqueIndex;size:50;not null" json:"task_number"`
+	WarehouseID         uint       `gorm:"column:warehouse_id;not null" json:"warehouse_id"`
+	SourceType          string     `gorm:"column:source_type;size:20;not null" json:"source_type"` // GRN, FPRN
+	SourceID            uint       `gorm:"column:source_id;not null" json:"source_id"`
+	SourceNumber        string     `gorm:"column:source_number;size:50" json:"source_number,omitempty"`
+	SourceItemID        *uint      `gorm:"column:source_item_id" json:"source_item_id,omitempty"`
+	ItemType            string     `gorm:"column:item_type;size:20;not null" json:"item_type"` // material, finished_product
+	ItemID              uint       `gorm:"column:item_id;not null" json:"item_id"`
+	BatchNumber         string     `gorm:"column:batch_number;size:100" json:"batch_number,omitempty"`
+	LotNumber           string     `gorm:"column:lot_number;size:100" json:"lot_number,omitempty"`
+	ExpiryDate          *string    `gorm:"column:expiry_date;type:date" json:"expiry_date,omitempty"`
+	Quantity            float64    `gorm:"column:quantity;type:decimal(15,3);not null" json:"quantity"`
+	PutawayQuantity     float64    `gorm:"column:putaway_quantity;type:decimal(15,3);not null;default:0" json:"putaway_quantity"`
+	FromLocationID      *uint      `gorm:"column:from_location_id" json:"from_location_id,omitempty"`
+	SuggestedLocationID *uint      `gorm:"column:suggested_location_id" json:"suggested_location_id,omitempty"`
+	Status              string     `gorm:"column:status;size:20;not null;default:pending" json:"status"` // pending, in_progress, completed, cancelled
+	CompletedBy         *uint      `gorm:"column:completed_by" json:"completed_by,omitempty"`
+	CompletedAt         *time.Time `gorm:"column:completed_at" json:"completed_at,omitempty"`
+	Notes               string     `gorm:"column:notes;type:text" json:"notes,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	Warehouse         *Warehouse             `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+	FromLocation      *WarehouseLocation     `gorm:"foreignKey:FromLocationID" json:"from_location,omitempty"`
+	SuggestedLocation *WarehouseLocation     `gorm:"foreignKey:SuggestedLocationID" json:"suggested_location,omitempty"`
+	Confirmations     []*PutawayConfirmation `gorm:"foreignKey:TaskID" json:"confirmations,omitempty"`
+}
+
+func (PutawayTask) TableName() string {
+	return "putaway_tasks"
+}
+
+// RemainingQuantity is the quantity still waiting at the source location
+func (t *PutawayTask) RemainingQuantity() float64 {
+	return t.Quantity - t.PutawayQuantity
+}
+
+// PutawayConfirmation records one move of (part of) a task into a storage location
+type PutawayConfirmation struct {
+	ID            uint        `gorm:"primaryKey" json:"id"`
+	TaskID        uint        `gorm:"column:task_id;not null" json:"task_id"`
+	ToLocationID  uint        `gorm:"column:to_location_id;not null" json:"to_location_id"`
+	Quantity      float64     `gorm:"column:quantity;type:decimal(15,3);not null" json:"quantity"`
+	SerialNumbers StringSlice `gorm:"column:serial_numbers;type:jsonb" json:"serial_numbers,omitempty"`
+	ConfirmedBy   *uint       `gorm:"column:confirmed_by" json:"confirmed_by,omitempty"`
+	ConfirmedAt   time.Time   `gorm:"column:confirmed_at;autoCreateTime" json:"confirmed_at"`
+
+	ToLocation *WarehouseLocation `gorm:"foreignKey:ToLocationID" json:"to_location,omitempty"`
+}
+
+func (PutawayConfirmation) TableName() string {
+	return "putaway_confirmations"
+}
@@ -0,0 +1,145 @@
+package models
+
+import "time"
+
+// QCTemplate is the incoming inspection plan for a material: sampling rule plus the tests and spec limits.
+// Only one template per material is active; a new version deactivates the previous one.
+type QCTemplate struct {
+	ID               uint     `gorm:"primaryKey" json:"id"`
+	Code             string   `gorm:"column:code;uniqueIndex;size:50;not null" json:"code"`
+	Name             string   `gorm:"column:name;size:255;not null" json:"name"`
+	MaterialID       uint     `gorm:"column:material_id;not null" json:"material_id"`
+	Version          int      `gorm:"column:version;not null;default:1" json:"version"`
+	SamplingRule     string   `gorm:"column:sampling_rule;size:20;not null;default:fixed" json:"sampling_rule"` // fixed, percent, sqrt
+	SampleValue      float64  `gorm:"column:sample_value;type:decimal(15,3);not null;default:0" json:"sample_value"`
+	MinSample        *float64 `gorm:"column:min_sample;type:decimal(15,3)" json:"min_sample,omitempty"`
+	MaxSample        *float64 `gorm:"column:max_sample;type:decimal(15,3)" json:"max_sample,omitempty"`
+	RetainedQuantity float64  `gorm:"column:retained_quantity;type:decimal(15,3);not null;default:0" json:"retained_quantity"`
+	RetentionDays    int      `gorm:"column:retention_days;not null;default:0" json:"retention_days"`
+	IsActive         bool     `gorm:"column:is_active;not null;default:true" json:"is_active"`
+	Notes            string   `gorm:"column:notes;type:text" json:"notes,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	UpdatedBy *uint     `gorm:"column:updated_by" json:"updated_by,omitempty"`
+
+	Material *Material         `gorm:"foreignKey:MaterialID" json:"material,omitempty"`
+	Tests    []*QCTemplateTest `gorm:"foreignKey:TemplateID" json:"tests,omitempty"`
+}
+
+func (QCTemplate) TableName() string {
+	return "qc_templates"
+}
+
+// QCTemplateTest is one test with its spec: numeric tests pass within [MinValue, MaxValue],
+// text tests pass when the observation matches ExpectedText
+type QCTemplateTest struct {
+	ID           uint     `gorm:"primaryKey" json:"id"`
+	TemplateID   uint     `gorm:"column:template_id;not null" json:"template_id"`
+	TestCode     string   `gorm:"column:test_code;size:50;not null" json:"test_code"`
+	TestName     string   `gorm:"column:test_name;size:255;not null" json:"test_name"`
+	Category     string   `gorm:"column:category;size:50" json:"category,omitempty"`                      // physical, chemical, appearance, microbiology
+	ResultType   string   `gorm:"column:result_type;size:20;not null;default:numeric" json:"result_type"` // numeric, text
+	Unit         string   `gorm:"column:unit;size:20" json:"unit,omitempty"`
+	MinValue     *float64 `gorm:"column:min_value;type:decimal(18,6)" json:"min_value,omitempty"`
+	MaxValue     *float64 `gorm:"column:max_value;type:decimal(18,6)" json:"max_value,omitempty"`
+	ExpectedText string   `gorm:"column:expected_text;size:255" json:"expected_text,omitempty"`
+	Method       string   `gorm:"column:method;size:255" json:"method,omitempty"`
+	IsRequired   bool     `gorm:"column:is_required;not null;default:true" json:"is_required"`
+	SortOrder    int      `gorm:"column:sort_order;not null;default:0" json:"sort_order"`
+}
+
+func (QCTemplateTest) TableName() string {
+	return "qc_template_tests"
+}
+
+// QCInspection records the inspection of one GRN line (batch) against a template
+type QCInspection struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	InspectionNumber string     `gorm:"column:inspection_number;uniqueIndex;size:50;not null" json:"inspection_number"`
+	GRNID            uint       `gorm:"column:grn_id;not null" json:"grn_id"`
+	GRNItemID        uint       `gorm:"column:grn_item_id;not null" json:"grn_item_id"`
+	MaterialID       uint       `gorm:"column:material_id;not null" json:"material_id"`
+	TemplateID       uint       `gorm:"column:template_id;not null" json:"template_id"`
+	BatchNumber      string     `gorm:"column:batch_number;size:100" json:"batch_number,omitempty"`
+	LotNumber        string     `gorm:"column:lot_number;size:100" json:"lot_number,omitempty"`
+	ReceivedQuantity float64    `gorm:"column:received_quantity;type:decimal(15,3);not null;default:0" json:"received_quantity"`
+	ContainerCount   *int       `gorm:"column:container_count" json:"container_count,omitempty"`
+	SampleSize       float64    `gorm:"column:sample_size;type:decimal(15,3);not null;default:0" json:"sample_size"`
+	SampleUnit       string     `gorm:"column:sample_unit;size:20" json:"sample_unit,omitempty"`
+	Status           string     `gorm:"column:status;size:20;not null;default:pending" json:"status"` // pending, completed
+	Result           string     `gorm:"column:result;size:20" json:"result,omitempty"`                // pass, fail
+	InspectedBy      *uint      `gorm:"column:inspected_by" json:"inspected_by,omitempty"`
+	InspectedAt      *time.Time `gorm:"column:inspected_at" json:"inspected_at,omitempty"`
+	Notes            string     `gorm:"column:notes;type:text" json:"notes,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	UpdatedBy *uint     `gorm:"column:updated_by" json:"updated_by,omitempty"`
+
+	GRN             *GoodsReceiptNote     `gorm:"foreignKey:GRNID" json:"grn,omitempty"`
+	GRNItem         *GoodsReceiptNoteItem `gorm:"foreignKey:GRNItemID" json:"grn_item,omitempty"`
+	Material        *Material             `gorm:"foreignKey:MaterialID" json:"material,omitempty"`
+	Template        *QCTemplate           `gorm:"foreignKey:TemplateID" json:"template,omitempty"`
+	InspectedByUser *User                 `gorm:"foreignKey:InspectedBy" json:"inspected_by_user,omitempty"`
+	Results         []*QCInspectionResult `gorm:"foreignKey:InspectionID" json:"results,omitempty"`
+	RetainedSamples []*QCRetainedSample   `gorm:"foreignKey:InspectionID" json:"retained_samples,omitempty"`
+}
+
+func (QCInspection) TableName() string {
+	return "qc_inspections"
+}
+
+// QCInspectionResult is a measured result; the spec is copied from the template test
+type QCInspectionResult struct {
+	ID             uint     `gorm:"primaryKey" json:"id"`
+	InspectionID   uint     `gorm:"column:inspection_id;not null" json:"inspection_id"`
+	TemplateTestID *uint    `gorm:"column:template_test_id" json:"template_test_id,omitempty"`
+	TestCode       string   `gorm:"column:test_code;size:50;not null" json:"test_code"`
+	TestName       string   `gorm:"column:test_name;size:255;not null" json:"test_name"`
+	Category       string   `gorm:"column:category;size:50" json:"category,omitempty"`
+	ResultType     string   `gorm:"column:result_type;size:20;not null;default:numeric" json:"result_type"`
+	Unit           string   `gorm:"column:unit;size:20" json:"unit,omitempty"`
+	MinValue       *float64 `gorm:"column:min_value;type:decimal(18,6)" json:"min_value,omitempty"`
+	MaxValue       *float64 `gorm:"column:max_value;type:decimal(18,6)" json:"max_value,omitempty"`
+	ExpectedText   string   `gorm:"column:expected_text;size:255" json:"expected_text,omitempty"`
+	Method         string   `gorm:"column:method;size:255" json:"method,omitempty"`
+	IsRequired     bool     `gorm:"column:is_required;not null;default:true" json:"is_required"`
+	SortOrder      int      `gorm:"column:sort_order;not null;default:0" json:"sort_order"`
+	NumericValue   *float64 `gorm:"column:numeric_value;type:decimal(18,6)" json:"numeric_value,omitempty"`
+	TextValue      *string  `gorm:"column:text_value;size:255" json:"text_value,omitempty"`
+	Result         string   `gorm:"column:result;size:20;not null;default:pending" json:"result"` // pending, pass, fail
+	Notes          string   `gorm:"column:notes;type:text" json:"notes,omitempty"`
+}
+
+func (QCInspectionResult) TableName() string {
+	return "qc_inspection_results"
+}
+
+// QCRetainedSample is a batch sample kept by QA (mẫu lưu) until RetainUntil
+type QCRetainedSample struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	InspectionID    uint       `gorm:"column:inspection_id;not null" json:"inspection_id"`
+	MaterialID      uint       `gorm:"column:material_id;not null" json:"material_id"`
+	BatchNumber     string     `gorm:"column:batch_number;size:100" json:"batch_number,omitempty"`
+	LotNumber       string     `gorm:"column:lot_number;size:100" json:"lot_number,omitempty"`
+	Quantity        float64    `gorm:"column:quantity;type:decimal(15,3);not null" json:"quantity"`
+	StorageLocation string     `gorm:"column:storage_location;size:255" json:"storage_location,omitempty"`
+	RetainedAt      string     `gorm:"column:retained_at;type:date;not null" json:"retained_at"`
+	RetainUntil     *string    `gorm:"column:retain_until;type:date" json:"retain_until,omitempty"`
+	Status          string     `gorm:"column:status;size:20;not null;default:retained" json:"status"` // retained, disposed
+	DisposedAt      *time.Time `gorm:"column:disposed_at" json:"disposed_at,omitempty"`
+	DisposedBy      *uint      `gorm:"column:disposed_by" json:"disposed_by,omitempty"`
+	DisposalNotes   string     `gorm:"column:disposal_notes;type:text" json:"disposal_notes,omitempty"`
+	Notes           string     `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	CreatedAt       time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy       *uint      `gorm:"column:created_by" json:"created_by,omitempty"`
+
+	Material *Material `gorm:"foreignKey:MaterialID" json:"material,omitempty"`
+}
+
+func (QCRetainedSample) TableName() string {
+	return "qc_retained_samples"
+}
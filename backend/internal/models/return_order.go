@@ -46,6 +46,7 @@ type ReturnOrderItem struct {
 	QuantityScrapped    int       `gorm:"column:quantity_scrapped;default:0" json:"quantity_scrapped"`
 	Condition           string    `gorm:"column:condition;size:50;default:pending_inspection" json:"condition"`
 	WarehouseID         *uint     `gorm:"column:warehouse_id" json:"warehouse_id,omitempty"`
+	SerialNumbers       StringSlice `gorm:"column:serial_numbers;type:jsonb" json:"serial_numbers,omitempty"` // serials received back, for serial-tracked products
 	Reason              string    `gorm:"column:reason;type:text" json:"reason,omitempty"`
 	Notes               string    `gorm:"column:notes;type:text" json:"notes,omitempty"`
 	CreatedAt           time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
@@ -94,6 +95,7 @@ type ReturnOrderItemSafeDTO struct {
 	Condition           string  `json:"condition"`
 	WarehouseID         *uint   `json:"warehouse_id,omitempty"`
 	WarehouseName       string  `json:"warehouse_name,omitempty"`
+	SerialNumbers       []string `json:"serial_numbers,omitempty"`
 	Reason              string  `json:"reason,omitempty"`
 	Notes               string  `json:"notes,omitempty"`
 }
@@ -138,6 +140,7 @@ func (r *ReturnOrder) ToSafe() ReturnOrderSafeDTO {
 			QuantityScrapped:    item.QuantityScrapped,
 			Condition:           item.Condition,
 			WarehouseID:         item.WarehouseID,
+			SerialNumbers:       item.SerialNumbers,
 			Reason:              item.Reason,
 			Notes:               item.Notes,
 		}
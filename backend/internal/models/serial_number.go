@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// SerialNumber is one unit of a serial-tracked material or finished product. The warehouse,
+// location and batch are those recorded by the last stock posting that carried the serial.
+type SerialNumber struct {
+	ID                  uint   `gorm:"primaryKey" json:"id"`
+	ItemType            string `gorm:"column:item_type;size:20;not null" json:"item_type"` // material, finished_product
+	ItemID              uint   `gorm:"column:item_id;not null" json:"item_id"`
+	SerialNumber        string `gorm:"column:serial_number;size:100;not null" json:"serial_number"`
+	Status              string `gorm:"column:status;size:20;not null;default:in_stock" json:"status"` // in_stock, issued, shipped, returned
+	WarehouseID         *uint  `gorm:"column:warehouse_id" json:"warehouse_id,omitempty"`
+	WarehouseLocationID *uint  `gorm:"column:warehouse_location_id" json:"warehouse_location_id,omitempty"`
+	BatchNumber         string `gorm:"column:batch_number;size:100" json:"batch_number,omitempty"`
+	LotNumber           string `gorm:"column:lot_number;size:100" json:"lot_number,omitempty"`
+	LastReferenceType   string `gorm:"column:last_reference_type;size:50" json:"last_reference_type,omitempty"`
+	LastReferenceID     *uint  `gorm:"column:last_reference_id" json:"last_reference_id,omitempty"`
+
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+
+	Warehouse         *Warehouse         `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+	WarehouseLocation *WarehouseLocation `gorm:"foreignKey:WarehouseLocationID" json:"warehouse_location,omitempty"`
+}
+
+func (SerialNumber) TableName() string {
+	return "serial_numbers"
+}
+
+// StockLedgerSerial links a stock ledger entry to the serials it moved
+type StockLedgerSerial struct {
+	ID             uint `gorm:"primaryKey" json:"id"`
+	StockLedgerID  uint `gorm:"column:stock_ledger_id;not null" json:"stock_ledger_id"`
+	SerialNumberID uint `gorm:"column:serial_number_id;not null" json:"serial_number_id"`
+}
+
+func (StockLedgerSerial) TableName() string {
+	return "stock_ledger_serials"
+}
@@ -73,6 +73,9 @@ type StockAdjustmentItem struct {
 	// Costing
 	UnitCost           float64   `gorm:"column:unit_cost;type:decimal(15,2)" json:"unit_cost"`
 	
+	// Serials of the units removed or added; a removal without them takes every serial held
+	SerialNumbers      StringSlice `gorm:"column:serial_numbers;type:jsonb" json:"serial_numbers,omitempty"`
+
 	// Additional info
 	Notes              string    `gorm:"column:notes;type:text" json:"notes,omitempty"`
 	
@@ -127,5 +130,6 @@ type SafeStockAdjustmentItem struct {
 	PreviousQuantity   float64   `json:"previous_quantity"`
 	NewQuantity        float64   `json:"new_quantity"`
 	UnitCost           float64   `json:"unit_cost"`
+	SerialNumbers      []string  `json:"serial_numbers,omitempty"`
 	Notes              string    `json:"notes,omitempty"`
 }
@@ -4,7 +4,9 @@ import (
 	"time"
 )
 
-// StockReservation represents a hold on inventory for a specific requirement (e.g., MR)
+// StockReservation represents a hold on inventory for a specific requirement (e.g., MR). The
+// quantity still held is ReservedQuantity - FulfilledQuantity; a release lowers ReservedQuantity
+// and adds to ReleasedQuantity.
 type StockReservation struct {
 	ID                  uint      `gorm:"primaryKey" json:"id"`
 	ItemType           string    `gorm:"column:item_type;size:20;not null" json:"item_type"` // material, finished_product
@@ -19,10 +21,12 @@ type StockReservation struct {
 	// Reservation details
 	ReservedQuantity   float64   `gorm:"column:reserved_quantity;type:decimal(15,3);not null" json:"reserved_quantity"`
 	FulfilledQuantity  float64   `gorm:"column:fulfilled_quantity;type:decimal(15,3);default:0" json:"fulfilled_quantity"`
+	ReleasedQuantity   float64   `gorm:"column:released_quantity;type:decimal(15,3);not null;default:0" json:"released_quantity"`
 	
 	// Reference (what is reserving this stock)
-	ReferenceType      string    `gorm:"column:reference_type;size:50;not null" json:"reference_type"` // material_request
+	ReferenceType      string    `gorm:"column:reference_type;size:50;not null" json:"reference_type"` // production_plan, delivery_order, customer_order
 	ReferenceID        uint      `gorm:"column:reference_id;not null" json:"reference_id"`
+	ReferenceNumber    string    `gorm:"column:reference_number;size:100" json:"reference_number,omitempty"` // customer order number
 	
 	// Status: active, fulfilled, cancelled, expired
 	Status             string    `gorm:"column:status;size:50;not null;default:active" json:"status"`
@@ -36,6 +40,10 @@ type StockReservation struct {
 	CreatedBy          *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
 	UpdatedAt          time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
 	UpdatedBy          *uint     `gorm:"column:updated_by" json:"updated_by,omitempty"`
+
+	// Relationships
+	Warehouse          *Warehouse         `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+	WarehouseLocation  *WarehouseLocation `gorm:"foreignKey:WarehouseLocationID" json:"warehouse_location,omitempty"`
 }
 
 // TableName specifies the table name for StockReservation model
@@ -22,6 +22,11 @@ type StockTransfer struct {
 	// Shipping
 	ShippedBy       *uint      `gorm:"column:shipped_by" json:"shipped_by,omitempty"`
 	ShippedAt       *time.Time `gorm:"column:shipped_at" json:"shipped_at,omitempty"`
+	// In transit: shipped stock waits in the in-transit location of the destination until received
+	TransitLocationID   *uint      `gorm:"column:transit_location_id" json:"transit_location_id,omitempty"`
+	Carrier             string     `gorm:"column:carrier;size:100" json:"carrier,omitempty"`
+	TrackingNumber      string     `gorm:"column:tracking_number;size:100" json:"tracking_number,omitempty"`
+	ExpectedArrivalDate *time.Time `gorm:"column:expected_arrival_date;type:date" json:"expected_arrival_date,omitempty"`
 	
 	// Receipt
 	ReceivedBy      *uint      `gorm:"column:received_by" json:"received_by,omitempty"`
@@ -44,6 +49,7 @@ type StockTransfer struct {
 	// Relationships
 	FromWarehouse   Warehouse           `gorm:"foreignKey:FromWarehouseID" json:"from_warehouse,omitempty"`
 	ToWarehouse     Warehouse           `gorm:"foreignKey:ToWarehouseID" json:"to_warehouse,omitempty"`
+	TransitLocation *WarehouseLocation  `gorm:"foreignKey:TransitLocationID" json:"transit_location,omitempty"`
 	ApprovedByUser  *User               `gorm:"foreignKey:ApprovedBy" json:"approved_by_user,omitempty"`
 	ShippedByUser   *User               `gorm:"foreignKey:ShippedBy" json:"shipped_by_user,omitempty"`
 	ReceivedByUser  *User               `gorm:"foreignKey:ReceivedBy" json:"received_by_user,omitempty"`
@@ -77,7 +83,15 @@ type StockTransferItem struct {
 	
 	// Quantity
 	Quantity         float64   `gorm:"column:quantity;type:decimal(15,3);not null" json:"quantity"`
+	ShippedQuantity  float64   `gorm:"column:shipped_quantity;type:decimal(15,3);not null;default:0" json:"shipped_quantity"`
 	ReceivedQuantity float64   `gorm:"column:received_quantity;type:decimal(15,3);default:0" json:"received_quantity"`
+	// Shipped but not received; written off from the in-transit location when the transfer is received
+	DiscrepancyQuantity float64 `gorm:"column:discrepancy_quantity;type:decimal(15,3);not null;default:0" json:"discrepancy_quantity"`
+	DiscrepancyReason   string  `gorm:"column:discrepancy_reason;type:text" json:"discrepancy_reason,omitempty"`
+
+	// Serials sent on the line and, for two-step transfers, the ones that arrived
+	SerialNumbers         StringSlice `gorm:"column:serial_numbers;type:jsonb" json:"serial_numbers,omitempty"`
+	ReceivedSerialNumbers StringSlice `gorm:"column:received_serial_numbers;type:jsonb" json:"received_serial_numbers,omitempty"`
 	
 	// Costing (at time of transfer)
 	UnitCost         float64   `gorm:"column:unit_cost;type:decimal(15,2)" json:"unit_cost"`
@@ -116,7 +130,13 @@ type SafeStockTransfer struct {
 	PostedAt          *time.Time              `json:"posted_at,omitempty"`
 	ApprovedByName    string                 `json:"approved_by_name,omitempty"`
 	ShippedByName     string                 `json:"shipped_by_name,omitempty"`
+	ShippedAt         *time.Time             `json:"shipped_at,omitempty"`
+	Carrier           string                 `json:"carrier,omitempty"`
+	TrackingNumber    string                 `json:"tracking_number,omitempty"`
+	ExpectedArrivalDate *time.Time           `json:"expected_arrival_date,omitempty"`
+	TransitLocationCode string               `json:"transit_location_code,omitempty"`
 	ReceivedByName    string                 `json:"received_by_name,omitempty"`
+	ReceivedAt        *time.Time             `json:"received_at,omitempty"`
 	Notes             string                 `json:"notes,omitempty"`
 	CreatedAt         time.Time              `json:"created_at"`
 	CreatedByName     string                 `json:"created_by_name,omitempty"`
@@ -137,6 +157,11 @@ type SafeStockTransferItem struct {
 	BatchNumber      string    `json:"batch_number,omitempty"`
 	LotNumber        string    `json:"lot_number,omitempty"`
 	Quantity         float64   `json:"quantity"`
+	ShippedQuantity  float64   `json:"shipped_quantity"`
 	ReceivedQuantity float64   `json:"received_quantity"`
+	DiscrepancyQuantity float64 `json:"discrepancy_quantity"`
+	DiscrepancyReason   string  `json:"discrepancy_reason,omitempty"`
+	SerialNumbers         []string `json:"serial_numbers,omitempty"`
+	ReceivedSerialNumbers []string `json:"received_serial_numbers,omitempty"`
 	UnitCost         float64   `json:"unit_cost"`
 }
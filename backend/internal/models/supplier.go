@@ -19,7 +19,13 @@ type Supplier struct {
 	Country       string     `json:"country" gorm:"default:'Vietnam'"`
 	PaymentTerms  *string    `json:"payment_terms"`
 	CreditLimit   *float64   `json:"credit_limit" gorm:"type:decimal(15,2)"`
+	Currency      string     `json:"currency" gorm:"size:3;default:VND"` // default PO currency
 	SupplierGroup *string    `json:"supplier_group" gorm:"type:varchar(50);index"`
+
+	// Receiving tolerances in percent of the PO line quantity (materials may override)
+	OverReceiptTolerance  float64 `json:"over_receipt_tolerance" gorm:"type:decimal(5,2);not null;default:0"`
+	UnderReceiptTolerance float64 `json:"under_receipt_tolerance" gorm:"type:decimal(5,2);not null;default:0"`
+
 	IsActive      *bool      `json:"is_active" gorm:"default:true"`
 	Notes         *string    `json:"notes"`
 	CreatedAt     time.Time  `json:"created_at"`
@@ -48,7 +54,12 @@ type SafeSupplier struct {
 	Country       string    `json:"country"`
 	PaymentTerms  *string   `json:"payment_terms"`
 	CreditLimit   *float64  `json:"credit_limit"`
+	Currency      string    `json:"currency"`
 	SupplierGroup *string   `json:"supplier_group,omitempty"`
+
+	OverReceiptTolerance  float64 `json:"over_receipt_tolerance"`
+	UnderReceiptTolerance float64 `json:"under_receipt_tolerance"`
+
 	IsActive      *bool     `json:"is_active"`
 	Notes         *string   `json:"notes"`
 	CreatedAt     time.Time `json:"created_at"`
@@ -71,7 +82,12 @@ func (s *Supplier) ToSafe() *SafeSupplier {
 		Country:       s.Country,
 		PaymentTerms:  s.PaymentTerms,
 		CreditLimit:   s.CreditLimit,
+		Currency:      s.Currency,
 		SupplierGroup: s.SupplierGroup,
+
+		OverReceiptTolerance:  s.OverReceiptTolerance,
+		UnderReceiptTolerance: s.UnderReceiptTolerance,
+
 		IsActive:      s.IsActive,
 		Notes:         s.Notes,
 		CreatedAt:     s.CreatedAt,
@@ -2,6 +2,16 @@ package models
 
 import "time"
 
+// Supplier document types
+const (
+	SupplierDocCOA             = "coa"
+	SupplierDocMSDS            = "msds"
+	SupplierDocBusinessLicence = "business_licence"
+	SupplierDocGMP             = "gmp"
+	SupplierDocHalal           = "halal"
+	SupplierDocOther           = "other"
+)
+
 // SupplierDocument represents an uploaded document for a supplier
 type SupplierDocument struct {
 	ID           uint      `json:"id" gorm:"primaryKey"`
@@ -10,9 +20,37 @@ type SupplierDocument struct {
 	OriginalName string    `json:"original_name" gorm:"not null"` // user's original filename
 	FileSize     int64     `json:"file_size" gorm:"not null"`
 	MimeType     string    `json:"mime_type" gorm:"not null"`
+
+	// Typed document with validity (coa, msds, business_licence, gmp, halal, other)
+	DocumentType   string  `json:"document_type" gorm:"size:30;not null;default:other"`
+	DocumentNumber *string `json:"document_number,omitempty" gorm:"size:100"`
+	MaterialID     *uint   `json:"material_id,omitempty"` // COA/MSDS are issued per material
+	IssueDate      *string `json:"issue_date,omitempty" gorm:"type:date"`
+	ExpiryDate     *string `json:"expiry_date,omitempty" gorm:"type:date"`
+	Notes          *string `json:"notes,omitempty" gorm:"type:text"`
+
 	UploadedBy   *uint     `json:"uploaded_by"`
 	UploadedByUser *User   `json:"uploaded_by_user,omitempty" gorm:"foreignKey:UploadedBy"`
 	CreatedAt    time.Time `json:"created_at"`
 }
 
 func (SupplierDocument) TableName() string { return "supplier_documents" }
+
+// SupplierDocumentRequirement configures a mandatory document type and the workflow steps it blocks
+type SupplierDocumentRequirement struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	DocumentType    string    `json:"document_type" gorm:"size:30;not null"`
+	Scope           string    `json:"scope" gorm:"size:20;not null;default:supplier"` // supplier, material
+	SupplierGroup   *string   `json:"supplier_group,omitempty" gorm:"size:50"`        // NULL = all groups
+	MaterialID      *uint     `json:"material_id,omitempty"`                          // NULL = all materials
+	BlockPOApproval bool      `json:"block_po_approval" gorm:"column:block_po_approval;not null;default:true"`
+	BlockGRNPost    bool      `json:"block_grn_post" gorm:"column:block_grn_post;not null;default:true"`
+	IsActive        bool      `json:"is_active" gorm:"not null;default:true"`
+	Notes           *string   `json:"notes,omitempty" gorm:"type:text"`
+	CreatedAt       time.Time `json:"created_at"`
+	CreatedBy       *uint     `json:"created_by,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	UpdatedBy       *uint     `json:"updated_by,omitempty"`
+}
+
+func (SupplierDocumentRequirement) TableName() string { return "supplier_document_requirements" }
@@ -6,24 +6,35 @@ import (
 
 // WarehouseLocation represents a warehouse location entity
 type WarehouseLocation struct {
-	ID           uint       `json:"id" gorm:"primaryKey"`
-	WarehouseID  uint       `json:"warehouse_id" gorm:"not null"`
-	Code         string     `json:"code" gorm:"uniqueIndex;not null"`
-	Name         string     `json:"name" gorm:"not null"`
-	Aisle        *string    `json:"aisle"`
-	Rack         *string    `json:"rack"`
-	Shelf        *string    `json:"shelf"`
-	Bin          *string    `json:"bin"`
-	LocationType string     `json:"location_type" gorm:"default:'storage'"`
-	IsActive     *bool      `json:"is_active" gorm:"default:true"`
-	Notes        *string    `json:"notes"`
-	CreatedAt    time.Time  `json:"created_at"`
-	CreatedBy    *uint      `json:"created_by"`
-	UpdatedAt    time.Time  `json:"updated_at"`
-	UpdatedBy    *uint      `json:"updated_by"`
+	ID           uint    `json:"id" gorm:"primaryKey"`
+	WarehouseID  uint    `json:"warehouse_id" gorm:"not null"`
+	Code         string  `json:"code" gorm:"uniqueIndex;not null"`
+	Name         string  `json:"name" gorm:"not null"`
+	Aisle        *string `json:"aisle"`
+	Rack         *string `json:"rack"`
+	Shelf        *string `json:"shelf"`
+	Bin          *string `json:"bin"`
+	LocationType string  `json:"location_type" gorm:"default:'storage'"`
+	// Putaway constraints: capacity in stock units (nil = unlimited) and whether hazardous materials may be stored
+	MaxQuantity      *float64 `json:"max_quantity" gorm:"type:decimal(15,3)"`
+	HazardousAllowed bool     `json:"hazardous_allowed" gorm:"not null;default:false"`
+	// Zone and storage constraints checked on every posting into the location (nil = not limited)
+	ZoneID         *uint     `json:"zone_id"`
+	MaxWeightKg    *float64  `json:"max_weight_kg" gorm:"type:decimal(15,3)"`
+	MaxVolumeM3    *float64  `json:"max_volume_m3" gorm:"type:decimal(15,3)"`
+	MaxPallets     *int      `json:"max_pallets"`
+	MinTemperature *float64  `json:"min_temperature" gorm:"type:decimal(6,2)"`
+	MaxTemperature *float64  `json:"max_temperature" gorm:"type:decimal(6,2)"`
+	IsActive       *bool     `json:"is_active" gorm:"default:true"`
+	Notes          *string   `json:"notes"`
+	CreatedAt      time.Time `json:"created_at"`
+	CreatedBy      *uint     `json:"created_by"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	UpdatedBy      *uint     `json:"updated_by"`
 
 	// Relationship
-	Warehouse *Warehouse `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
+	Warehouse *Warehouse     `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"`
+	Zone      *WarehouseZone `json:"zone,omitempty" gorm:"foreignKey:ZoneID"`
 }
 
 // TableName specifies the table name for WarehouseLocation
@@ -33,37 +44,53 @@ func (WarehouseLocation) TableName() string {
 
 // SafeWarehouseLocation represents warehouse location data safe for API responses
 type SafeWarehouseLocation struct {
-	ID           uint      `json:"id"`
-	WarehouseID  uint      `json:"warehouse_id"`
-	Code         string    `json:"code"`
-	Name         string    `json:"name"`
-	Aisle        *string   `json:"aisle"`
-	Rack         *string   `json:"rack"`
-	Shelf        *string   `json:"shelf"`
-	Bin          *string   `json:"bin"`
-	LocationType string    `json:"location_type"`
-	IsActive     *bool     `json:"is_active"`
-	Notes        *string   `json:"notes"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID               uint      `json:"id"`
+	WarehouseID      uint      `json:"warehouse_id"`
+	Code             string    `json:"code"`
+	Name             string    `json:"name"`
+	Aisle            *string   `json:"aisle"`
+	Rack             *string   `json:"rack"`
+	Shelf            *string   `json:"shelf"`
+	Bin              *string   `json:"bin"`
+	LocationType     string    `json:"location_type"`
+	MaxQuantity      *float64  `json:"max_quantity"`
+	HazardousAllowed bool      `json:"hazardous_allowed"`
+	ZoneID           *uint     `json:"zone_id"`
+	MaxWeightKg      *float64  `json:"max_weight_kg"`
+	MaxVolumeM3      *float64  `json:"max_volume_m3"`
+	MaxPallets       *int      `json:"max_pallets"`
+	MinTemperature   *float64  `json:"min_temperature"`
+	MaxTemperature   *float64  `json:"max_temperature"`
+	IsActive         *bool     `json:"is_active"`
+	Notes            *string   `json:"notes"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 // ToSafe converts WarehouseLocation to SafeWarehouseLocation
 func (wl *WarehouseLocation) ToSafe() *SafeWarehouseLocation {
 	return &SafeWarehouseLocation{
-		ID:           wl.ID,
-		WarehouseID:  wl.WarehouseID,
-		Code:         wl.Code,
-		Name:         wl.Name,
-		Aisle:        wl.Aisle,
-		Rack:         wl.Rack,
-		Shelf:        wl.Shelf,
-		Bin:          wl.Bin,
-		LocationType: wl.LocationType,
-		IsActive:     wl.IsActive,
-		Notes:        wl.Notes,
-		CreatedAt:    wl.CreatedAt,
-		UpdatedAt:    wl.UpdatedAt,
+		ID:               wl.ID,
+		WarehouseID:      wl.WarehouseID,
+		Code:             wl.Code,
+		Name:             wl.Name,
+		Aisle:            wl.Aisle,
+		Rack:             wl.Rack,
+		Shelf:            wl.Shelf,
+		Bin:              wl.Bin,
+		LocationType:     wl.LocationType,
+		MaxQuantity:      wl.MaxQuantity,
+		HazardousAllowed: wl.HazardousAllowed,
+		ZoneID:           wl.ZoneID,
+		MaxWeightKg:      wl.MaxWeightKg,
+		MaxVolumeM3:      wl.MaxVolumeM3,
+		MaxPallets:       wl.MaxPallets,
+		MinTemperature:   wl.MinTemperature,
+		MaxTemperature:   wl.MaxTemperature,
+		IsActive:         wl.IsActive,
+		Notes:            wl.Notes,
+		CreatedAt:        wl.CreatedAt,
+		UpdatedAt:        wl.UpdatedAt,
 	}
 }
 
@@ -82,11 +109,11 @@ func (wl *WarehouseLocation) GetFullLocation() string {
 	if wl.Bin != nil && *wl.Bin != "" {
 		parts = append(parts, *wl.Bin)
 	}
-	
+
 	if len(parts) == 0 {
 		return wl.Code
 	}
-	
+
 	result := ""
 	for i, part := range parts {
 		if i > 0 {
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// WarehouseZone groups locations sharing storage conditions (cold room, flammables store...).
+// Locations inherit the temperature range and hazardous approval of their zone.
+type WarehouseZone struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	WarehouseID      uint      `gorm:"column:warehouse_id;not null" json:"warehouse_id"`
+	Code             string    `gorm:"column:code;size:50;not null" json:"code"`
+	Name             string    `gorm:"column:name;size:255;not null" json:"name"`
+	ZoneType         string    `gorm:"column:zone_type;size:30;not null;default:ambient" json:"zone_type"` // ambient, cold, flammable, corrosive, hazardous, general
+	MinTemperature   *float64  `gorm:"column:min_temperature;type:decimal(6,2)" json:"min_temperature,omitempty"`
+	MaxTemperature   *float64  `gorm:"column:max_temperature;type:decimal(6,2)" json:"max_temperature,omitempty"`
+	HazardousAllowed bool      `gorm:"column:hazardous_allowed;not null;default:false" json:"hazardous_allowed"`
+	IsActive         bool      `gorm:"column:is_active;not null;default:true" json:"is_active"`
+	Notes            string    `gorm:"column:notes;type:text" json:"notes,omitempty"`
+	CreatedAt        time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	CreatedBy        *uint     `gorm:"column:created_by" json:"created_by,omitempty"`
+	UpdatedAt        time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	UpdatedBy        *uint     `gorm:"column:updated_by" json:"updated_by,omitempty"`
+
+	Warehouse *Warehouse `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+}
+
+func (WarehouseZone) TableName() string {
+	return "warehouse_zones"
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ItemConsumption is the outbound quantity and value of an item in a warehouse over a period
+type ItemConsumption struct {
+	ItemType string  `gorm:"column:item_type"`
+	ItemID   uint    `gorm:"column:item_id"`
+	Quantity float64 `gorm:"column:quantity"`
+	Value    float64 `gorm:"column:value"`
+}
+
+// CycleCountRepository defines data operations for ABC classification and cycle count tasks
+type CycleCountRepository interface {
+	GetPolicy(warehouseID uint) (*models.CycleCountPolicy, error)
+	SavePolicy(policy *models.CycleCountPolicy) error
+	AutoScheduledPolicies() ([]*models.CycleCountPolicy, error)
+
+	// Consumption sums issues (delivery orders, material issue notes) per item since the given time
+	Consumption(warehouseID uint, since time.Time) ([]ItemConsumption, error)
+	// StockedItems returns the items holding stock in the warehouse, as zero-consumption rows
+	StockedItems(warehouseID uint) ([]ItemConsumption, error)
+	UpsertClass(class *models.ItemABCClass) error
+	ClassesByWarehouse(warehouseID uint) ([]*models.ItemABCClass, error)
+	ListClasses(filters map[string]interface{}, offset, limit int) ([]*models.ItemABCClass, int64, error)
+	MarkCounted(warehouseID uint, itemType string, itemID uint, at time.Time) error
+
+	CreateTasks(tasks []*models.CycleCountTask) error
+	GetTask(id uint) (*models.CycleCountTask, error)
+	ListTasks(filters map[string]interface{}, offset, limit int) ([]*models.CycleCountTask, int64, error)
+	UpdateTask(task *models.CycleCountTask) error
+	CountByTaskNumber(prefix string) (int64, error)
+	// OpenTaskItems returns the items of the warehouse that still have a task waiting to be counted or approved
+	OpenTaskItems(warehouseID uint) ([]ItemConsumption, error)
+	// CountableBalances returns the non-zero balances of an item, one per location/batch/lot
+	CountableBalances(warehouseID uint, itemType string, itemID uint) ([]*models.StockBalance, error)
+	// BalanceFor returns the balance a task counts, or nil when it no longer exists
+	BalanceFor(task *models.CycleCountTask) (*models.StockBalance, error)
+}
+
+type cycleCountRepository struct {
+	db *gorm.DB
+}
+
+func NewCycleCountRepository(db *gorm.DB) CycleCountRepository {
+	return &cycleCountRepository{db: db}
+}
+
+func (r *cycleCountRepository) GetPolicy(warehouseID uint) (*models.CycleCountPolicy, error) {
+	var policy models.CycleCountPolicy
+	if err := r.db.Where("warehouse_id = ?", warehouseID).First(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *cycleCountRepository) SavePolicy(policy *models.CycleCountPolicy) error {
+	return r.db.Save(policy).Error
+}
+
+func (r *cycleCountRepository) AutoScheduledPolicies() ([]*models.CycleCountPolicy, error) {
+	var policies []*models.CycleCountPolicy
+	err := r.db.Where("auto_schedule = ?", true).Order("warehouse_id").Find(&policies).Error
+	return policies, err
+}
+
+func (r *cycleCountRepository) Consumption(warehouseID uint, since time.Time) ([]ItemConsumption, error) {
+	var rows []ItemConsumption
+	err := r.db.Model(&models.StockLedger{}).
+		Select("item_type, item_id, SUM(-quantity) AS quantity, "+
+			"SUM(CASE WHEN total_cost <> 0 THEN ABS(total_cost) ELSE -quantity * COALESCE(unit_cost, 0) END) AS value").
+		Where("warehouse_id = ? AND transaction_date >= ? AND quantity < 0", warehouseID, since).
+		Where("transaction_type IN ?", []string{"issue", "MIN"}).
+		Group("item_type, item_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *cycleCountRepository) StockedItems(warehouseID uint) ([]ItemConsumption, error) {
+	var rows []ItemConsumption
+	err := r.db.Model(&models.StockBalance{}).
+		Select("DISTINCT item_type, item_id").
+		Where("warehouse_id = ? AND quantity <> 0", warehouseID).
+		Where(notInTransit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *cycleCountRepository) UpsertClass(class *models.ItemABCClass) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "warehouse_id"}, {Name: "item_type"}, {Name: "item_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"abc_class", "consumption_qty", "consumption_value", "cumulative_share", "classified_at", "updated_at"}),
+	}).Create(class).Error
+}
+
+func (r *cycleCountRepository) ClassesByWarehouse(warehouseID uint) ([]*models.ItemABCClass, error) {
+	var classes []*models.ItemABCClass
+	err := r.db.Where("warehouse_id = ?", warehouseID).Order("consumption_value DESC, id").Find(&classes).Error
+	return classes, err
+}
+
+func (r *cycleCountRepository) ListClasses(filters map[string]interface{}, offset, limit int) ([]*models.ItemABCClass, int64, error) {
+	var classes []*models.ItemABCClass
+	var total int64
+
+	query := r.db.Model(&models.ItemABCClass{})
+	if warehouseID, ok := filters["warehouse_id"].(uint); ok && warehouseID > 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if class, ok := filters["abc_class"].(string); ok && class != "" {
+		query = query.Where("abc_class = ?", class)
+	}
+	if itemType, ok := filters["item_type"].(string); ok && itemType != "" {
+		query = query.Where("item_type = ?", itemType)
+	}
+
+	query.Count(&total)
+	err := query.Order("warehouse_id, consumption_value DESC, id").Offset(offset).Limit(limit).Find(&classes).Error
+	return classes, total, err
+}
+
+func (r *cycleCountRepository) MarkCounted(warehouseID uint, itemType string, itemID uint, at time.Time) error {
+	return r.db.Model(&models.ItemABCClass{}).
+		Where("warehouse_id = ? AND item_type = ? AND item_id = ?", warehouseID, itemType, itemID).
+		Update("last_counted_at", at).Error
+}
+
+func (r *cycleCountRepository) CreateTasks(tasks []*models.CycleCountTask) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	return r.db.Omit("Warehouse", "Location").Create(&tasks).Error
+}
+
+func (r *cycleCountRepository) GetTask(id uint) (*models.CycleCountTask, error) {
+	var task models.CycleCountTask
+	if err := r.db.Preload("Warehouse").Preload("Location").First(&task, id).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (r *cycleCountRepository) ListTasks(filters map[string]interface{}, offset, limit int) ([]*models.CycleCountTask, int64, error) {
+	var tasks []*models.CycleCountTask
+	var total int64
+
+	query := r.db.Model(&models.CycleCountTask{})
+	if warehouseID, ok := filters["warehouse_id"].(uint); ok && warehouseID > 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if class, ok := filters["abc_class"].(string); ok && class != "" {
+		query = query.Where("abc_class = ?", class)
+	}
+	if itemType, ok := filters["item_type"].(string); ok && itemType != "" {
+		query = query.Where("item_type = ?", itemType)
+	}
+	if itemID, ok := filters["item_id"].(uint); ok && itemID > 0 {
+		query = query.Where("item_id = ?", itemID)
+	}
+	if locationID, ok := filters["location_id"].(uint); ok && locationID > 0 {
+		query = query.Where("warehouse_location_id = ?", locationID)
+	}
+	if due, ok := filters["due_by"].(string); ok && due != "" {
+		query = query.Where("scheduled_date <= ?", due)
+	}
+
+	query.Count(&total)
+	err := query.Order("scheduled_date, id").Offset(offset).Limit(limit).
+		Preload("Location").Find(&tasks).Error
+	return tasks, total, err
+}
+
+func (r *cycleCountRepository) UpdateTask(task *models.CycleCountTask) error {
+	return r.db.Omit("Warehouse", "Location").Save(task).Error
+}
+
+func (r *cycleCountRepository) CountByTaskNumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.CycleCountTask{}).Where("task_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
+
+func (r *cycleCountRepository) OpenTaskItems(warehouseID uint) ([]ItemConsumption, error) {
+	var rows []ItemConsumption
+	err := r.db.Model(&models.CycleCountTask{}).
+		Select("DISTINCT item_type, item_id").
+		Where("warehouse_id = ? AND status IN ?", warehouseID, []string{"pending", "recount", "counted"}).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *cycleCountRepository) CountableBalances(warehouseID uint, itemType string, itemID uint) ([]*models.StockBalance, error) {
+	var balances []*models.StockBalance
+	err := r.db.Where("warehouse_id = ? AND item_type = ? AND item_id = ? AND quantity <> 0", warehouseID, itemType, itemID).
+		Where(notInTransit).
+		Order("warehouse_location_id, batch_number, lot_number").
+		Find(&balances).Error
+	return balances, err
+}
+
+func (r *cycleCountRepository) BalanceFor(task *models.CycleCountTask) (*models.StockBalance, error) {
+	var balances []*models.StockBalance
+	err := r.db.Where("item_type = ? AND item_id = ? AND warehouse_id = ?", task.ItemType, task.ItemID, task.WarehouseID).
+		Where("COALESCE(warehouse_location_id, 0) = COALESCE(?, 0)", task.WarehouseLocationID).
+		Where("COALESCE(batch_number, '') = ? AND COALESCE(lot_number, '') = ?", task.BatchNumber, task.LotNumber).
+		Limit(1).Find(&balances).Error
+	if err != nil || len(balances) == 0 {
+		return nil, err
+	}
+	return balances[0], nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExchangeRateRepository defines exchange rate data operations
+type ExchangeRateRepository interface {
+	List(filters map[string]interface{}, offset, limit int) ([]*models.ExchangeRate, int64, error)
+	GetByID(id uint) (*models.ExchangeRate, error)
+	Upsert(rate *models.ExchangeRate) error
+	Delete(id uint) error
+	// GetEffective returns the latest rate for currency on or before date
+	GetEffective(currency string, date string) (*models.ExchangeRate, error)
+}
+
+type exchangeRateRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateRepository(db *gorm.DB) ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+func (r *exchangeRateRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.ExchangeRate, int64, error) {
+	var rates []*models.ExchangeRate
+	var total int64
+
+	query := r.db.Model(&models.ExchangeRate{})
+	if currency, ok := filters["currency"].(string); ok && currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+	if from, ok := filters["from"].(string); ok && from != "" {
+		query = query.Where("rate_date >= ?", from)
+	}
+	if to, ok := filters["to"].(string); ok && to != "" {
+		query = query.Where("rate_date <= ?", to)
+	}
+
+	query.Count(&total)
+	err := query.Order("rate_date DESC, currency").Offset(offset).Limit(limit).Find(&rates).Error
+	return rates, total, err
+}
+
+func (r *exchangeRateRepository) GetByID(id uint) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := r.db.First(&rate, id).Error
+	return &rate, err
+}
+
+// Upsert inserts a rate or overwrites the existing one for the same currency and date
+func (r *exchangeRateRepository) Upsert(rate *models.ExchangeRate) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}, {Name: "rate_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "notes", "updated_by", "updated_at"}),
+	}).Create(rate).Error
+}
+
+func (r *exchangeRateRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.ExchangeRate{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *exchangeRateRepository) GetEffective(currency string, date string) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := r.db.Where("currency = ? AND rate_date <= ?", currency, date).
+		Order("rate_date DESC").First(&rate).Error
+	return &rate, err
+}
@@ -16,6 +16,7 @@ type GoodsReceiptNoteItemRepository interface {
 	Delete(id uint) error
 	DeleteByGRNID(grnID uint) error
 	UpdateQC(id uint, receivedQty *float64, acceptedQty float64, rejectedQty float64, status string, notes string) error
+	SumUnpostedByPOItem(poItemID uint) (float64, error)
 }
 
 type goodsReceiptNoteItemRepository struct {
@@ -72,3 +73,13 @@ func (r *goodsReceiptNoteItemRepository) UpdateQC(id uint, receivedQty *float64,
 	}
 	return r.db.Model(&models.GoodsReceiptNoteItem{}).Where("id = ?", id).Updates(updates).Error
 }
+
+// SumUnpostedByPOItem sums quantities on GRNs not yet posted for a PO line
+func (r *goodsReceiptNoteItemRepository) SumUnpostedByPOItem(poItemID uint) (float64, error) {
+	var total float64
+	err := r.db.Model(&models.GoodsReceiptNoteItem{}).
+		Joins("JOIN goods_receipt_notes g ON g.id = goods_receipt_note_items.grn_id").
+		Where("goods_receipt_note_items.po_item_id = ? AND g.posted = false", poItemID).
+		Select("COALESCE(SUM(goods_receipt_note_items.quantity), 0)").Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// KitRepository defines data operations for kits and their assembly/disassembly orders
+type KitRepository interface {
+	Create(kit *models.Kit) error
+	GetByID(id uint) (*models.Kit, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.Kit, int64, error)
+	Update(kit *models.Kit) error
+	ReplaceComponents(kitID uint, components []*models.KitComponent) error
+	// ActiveByProductIDs returns the active kits, with their components, made as the given products
+	ActiveByProductIDs(productIDs []uint) (map[uint]*models.Kit, error)
+	// KitProductIDs returns which of the products are made as a kit
+	KitProductIDs(productIDs []uint) ([]uint, error)
+	// KitsUsingComponent returns the kits, other than excludeKitID, that have the product as a component
+	KitsUsingComponent(productID, excludeKitID uint) ([]uint, error)
+	GetProducts(ids []uint) (map[uint]*models.FinishedProduct, error)
+	GetLocations(ids []uint) (map[uint]*models.WarehouseLocation, error)
+
+	CreateOrder(order *models.KitOrder) error
+	GetOrder(id uint) (*models.KitOrder, error)
+	ListOrders(filters map[string]interface{}, offset, limit int) ([]*models.KitOrder, int64, error)
+	UpdateOrder(order *models.KitOrder) error
+	UpdateOrderLine(line *models.KitOrderLine) error
+	CountByOrderNumber(prefix string) (int64, error)
+	// AverageCosts returns the weighted average unit cost of the products in stock in a warehouse
+	AverageCosts(warehouseID uint, productIDs []uint) (map[uint]float64, error)
+}
+
+type kitRepository struct {
+	db *gorm.DB
+}
+
+func NewKitRepository(db *gorm.DB) KitRepository {
+	return &kitRepository{db: db}
+}
+
+func (r *kitRepository) Create(kit *models.Kit) error {
+	return r.db.Omit("KitProduct", "Components.Product").Create(kit).Error
+}
+
+func (r *kitRepository) GetByID(id uint) (*models.Kit, error) {
+	var kit models.Kit
+	err := r.db.Preload("KitProduct").
+		Preload("Components", func(db *gorm.DB) *gorm.DB { return db.Order("sequence, id") }).
+		Preload("Components.Product").
+		First(&kit, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &kit, nil
+}
+
+func (r *kitRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.Kit, int64, error) {
+	var kits []*models.Kit
+	var total int64
+
+	query := r.db.Model(&models.Kit{})
+	if isActive, ok := filters["is_active"].(bool); ok {
+		query = query.Where("is_active = ?", isActive)
+	}
+	if productID, ok := filters["product_id"].(uint); ok && productID > 0 {
+		query = query.Where("kit_product_id = ? OR EXISTS (SELECT 1 FROM kit_components c WHERE c.kit_id = kits.id AND c.component_product_id = ?)", productID, productID)
+	}
+	if search, ok := filters["search"].(string); ok && search != "" {
+		like := "%" + search + "%"
+		query = query.Where("EXISTS (SELECT 1 FROM finished_products fp WHERE fp.id = kits.kit_product_id AND (fp.code ILIKE ? OR fp.name ILIKE ?))", like, like)
+	}
+
+	query.Count(&total)
+	err := query.Order("id DESC").Offset(offset).Limit(limit).
+		Preload("KitProduct").
+		Preload("Components", func(db *gorm.DB) *gorm.DB { return db.Order("sequence, id") }).
+		Find(&kits).Error
+	return kits, total, err
+}
+
+func (r *kitRepository) Update(kit *models.Kit) error {
+	return r.db.Omit("KitProduct", "Components").Save(kit).Error
+}
+
+func (r *kitRepository) ReplaceComponents(kitID uint, components []*models.KitComponent) error {
+	if err := r.db.Where("kit_id = ?", kitID).Delete(&models.KitComponent{}).Error; err != nil {
+		return err
+	}
+	for _, c := range components {
+		c.ID = 0
+		c.KitID = kitID
+	}
+	if len(components) == 0 {
+		return nil
+	}
+	return r.db.Omit("Product").Create(&components).Error
+}
+
+func (r *kitRepository) ActiveByProductIDs(productIDs []uint) (map[uint]*models.Kit, error) {
+	kits := make(map[uint]*models.Kit)
+	if len(productIDs) == 0 {
+		return kits, nil
+	}
+	var rows []*models.Kit
+	err := r.db.Where("kit_product_id IN ? AND is_active", productIDs).
+		Preload("Components", func(db *gorm.DB) *gorm.DB { return db.Order("sequence, id") }).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, kit := range rows {
+		kits[kit.KitProductID] = kit
+	}
+	return kits, nil
+}
+
+func (r *kitRepository) KitProductIDs(productIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(productIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Model(&models.Kit{}).Where("kit_product_id IN ?", productIDs).Pluck("kit_product_id", &ids).Error
+	return ids, err
+}
+
+func (r *kitRepository) KitsUsingComponent(productID, excludeKitID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.KitComponent{}).
+		Where("component_product_id = ? AND kit_id <> ?", productID, excludeKitID).
+		Distinct().Pluck("kit_id", &ids).Error
+	return ids, err
+}
+
+func (r *kitRepository) GetProducts(ids []uint) (map[uint]*models.FinishedProduct, error) {
+	products := make(map[uint]*models.FinishedProduct, len(ids))
+	if len(ids) == 0 {
+		return products, nil
+	}
+	var rows []*models.FinishedProduct
+	if err := r.db.Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range rows {
+		products[p.ID] = p
+	}
+	return products, nil
+}
+
+func (r *kitRepository) GetLocations(ids []uint) (map[uint]*models.WarehouseLocation, error) {
+	locations := make(map[uint]*models.WarehouseLocation, len(ids))
+	if len(ids) == 0 {
+		return locations, nil
+	}
+	var rows []*models.WarehouseLocation
+	if err := r.db.Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, loc := range rows {
+		locations[loc.ID] = loc
+	}
+	return locations, nil
+}
+
+func (r *kitRepository) CreateOrder(order *models.KitOrder) error {
+	return r.db.Omit("Kit", "KitProduct", "Warehouse", "KitLocation", "ComponentLocation", "Lines.Product").Create(order).Error
+}
+
+func (r *kitRepository) GetOrder(id uint) (*models.KitOrder, error) {
+	var order models.KitOrder
+	err := r.db.Preload("KitProduct").
+		Preload("Warehouse").
+		Preload("KitLocation").
+		Preload("ComponentLocation").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Lines.Product").
+		First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *kitRepository) ListOrders(filters map[string]interface{}, offset, limit int) ([]*models.KitOrder, int64, error) {
+	var orders []*models.KitOrder
+	var total int64
+
+	query := r.db.Model(&models.KitOrder{})
+	if warehouseID, ok := filters["warehouse_id"].(uint); ok && warehouseID > 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if kitID, ok := filters["kit_id"].(uint); ok && kitID > 0 {
+		query = query.Where("kit_id = ?", kitID)
+	}
+	if orderType, ok := filters["order_type"].(string); ok && orderType != "" {
+		query = query.Where("order_type = ?", orderType)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query.Count(&total)
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).
+		Preload("KitProduct").
+		Preload("Lines").
+		Find(&orders).Error
+	return orders, total, err
+}
+
+func (r *kitRepository) UpdateOrder(order *models.KitOrder) error {
+	return r.db.Omit("Kit", "KitProduct", "Warehouse", "KitLocation", "ComponentLocation", "Lines").Save(order).Error
+}
+
+func (r *kitRepository) UpdateOrderLine(line *models.KitOrderLine) error {
+	return r.db.Omit("Product").Save(line).Error
+}
+
+func (r *kitRepository) CountByOrderNumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.KitOrder{}).Where("order_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
+
+func (r *kitRepository) AverageCosts(warehouseID uint, productIDs []uint) (map[uint]float64, error) {
+	costs := make(map[uint]float64, len(productIDs))
+	if len(productIDs) == 0 {
+		return costs, nil
+	}
+	var rows []struct {
+		ItemID   uint
+		UnitCost float64
+	}
+	err := r.db.Table("stock_balance").
+		Select("item_id, SUM(quantity * unit_cost) / SUM(quantity) AS unit_cost").
+		Where("item_type = ? AND warehouse_id = ? AND item_id IN ? AND quantity > 0", "finished_product", warehouseID, productIDs).
+		Group("item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		costs[row.ItemID] = row.UnitCost
+	}
+	return costs, nil
+}
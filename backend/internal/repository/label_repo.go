@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// LabelRepository defines data operations for label templates, the print log and label sources
+type LabelRepository interface {
+	ListTemplates(filters map[string]interface{}) ([]*models.LabelTemplate, error)
+	GetTemplate(id uint) (*models.LabelTemplate, error)
+	// DefaultTemplate returns the active default template for a label type and format, or nil
+	DefaultTemplate(labelType, format string) (*models.LabelTemplate, error)
+	CreateTemplate(t *models.LabelTemplate) error
+	UpdateTemplate(t *models.LabelTemplate) error
+	// ClearDefault unsets is_default on the other templates of the same type and format
+	ClearDefault(labelType, format string, exceptID uint) error
+
+	CreateLog(log *models.LabelPrintLog) error
+	GetLog(id uint) (*models.LabelPrintLog, error)
+	ListLogs(filters map[string]interface{}, offset, limit int) ([]*models.LabelPrintLog, int64, error)
+	// NextSSCCSerial returns the next pallet serial reference
+	NextSSCCSerial() (int64, error)
+
+	GetGRN(id uint) (*models.GoodsReceiptNote, error)
+	GetFPRN(id uint) (*models.FinishedProductReceipt, error)
+	// Locations returns the given locations, or all active locations of the warehouse when ids is empty
+	Locations(ids []uint, warehouseID uint) ([]*models.WarehouseLocation, error)
+	GetMaterial(id uint) (*models.Material, error)
+	GetFinishedProduct(id uint) (*models.FinishedProduct, error)
+}
+
+type labelRepository struct {
+	db *gorm.DB
+}
+
+func NewLabelRepository(db *gorm.DB) LabelRepository {
+	return &labelRepository{db: db}
+}
+
+func (r *labelRepository) ListTemplates(filters map[string]interface{}) ([]*models.LabelTemplate, error) {
+	var templates []*models.LabelTemplate
+	query := r.db.Model(&models.LabelTemplate{})
+	if labelType, ok := filters["label_type"].(string); ok && labelType != "" {
+		query = query.Where("label_type = ?", labelType)
+	}
+	if format, ok := filters["format"].(string); ok && format != "" {
+		query = query.Where("format = ?", format)
+	}
+	if active, ok := filters["is_active"].(bool); ok {
+		query = query.Where("is_active = ?", active)
+	}
+	err := query.Order("label_type, format, is_default DESC, code").Find(&templates).Error
+	return templates, err
+}
+
+func (r *labelRepository) GetTemplate(id uint) (*models.LabelTemplate, error) {
+	var t models.LabelTemplate
+	if err := r.db.First(&t, id).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *labelRepository) DefaultTemplate(labelType, format string) (*models.LabelTemplate, error) {
+	var templates []*models.LabelTemplate
+	err := r.db.Where("label_type = ? AND format = ? AND is_default AND is_active", labelType, format).
+		Limit(1).Find(&templates).Error
+	if err != nil || len(templates) == 0 {
+		return nil, err
+	}
+	return templates[0], nil
+}
+
+func (r *labelRepository) CreateTemplate(t *models.LabelTemplate) error {
+	return r.db.Create(t).Error
+}
+
+func (r *labelRepository) UpdateTemplate(t *models.LabelTemplate) error {
+	return r.db.Save(t).Error
+}
+
+func (r *labelRepository) ClearDefault(labelType, format string, exceptID uint) error {
+	return r.db.Model(&models.LabelTemplate{}).
+		Where("label_type = ? AND format = ? AND id <> ? AND is_default", labelType, format, exceptID).
+		Update("is_default", false).Error
+}
+
+func (r *labelRepository) CreateLog(log *models.LabelPrintLog) error {
+	return r.db.Omit("Template").Create(log).Error
+}
+
+func (r *labelRepository) GetLog(id uint) (*models.LabelPrintLog, error) {
+	var log models.LabelPrintLog
+	if err := r.db.Preload("Template").First(&log, id).Error; err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+func (r *labelRepository) ListLogs(filters map[string]interface{}, offset, limit int) ([]*models.LabelPrintLog, int64, error) {
+	var logs []*models.LabelPrintLog
+	var total int64
+
+	query := r.db.Model(&models.LabelPrintLog{})
+	if labelType, ok := filters["label_type"].(string); ok && labelType != "" {
+		query = query.Where("label_type = ?", labelType)
+	}
+	if sourceType, ok := filters["source_type"].(string); ok && sourceType != "" {
+		query = query.Where("source_type = ?", sourceType)
+	}
+	if sourceID, ok := filters["source_id"].(uint); ok && sourceID > 0 {
+		query = query.Where("source_id = ?", sourceID)
+	}
+	if reprints, ok := filters["reprints"].(bool); ok {
+		if reprints {
+			query = query.Where("reprint_of_id IS NOT NULL")
+		} else {
+			query = query.Where("reprint_of_id IS NULL")
+		}
+	}
+
+	query.Count(&total)
+	err := query.Omit("content").Order("printed_at DESC, id DESC").Offset(offset).Limit(limit).Find(&logs).Error
+	return logs, total, err
+}
+
+func (r *labelRepository) NextSSCCSerial() (int64, error) {
+	var serial int64
+	err := r.db.Raw("SELECT nextval('label_sscc_seq')").Scan(&serial).Error
+	return serial, err
+}
+
+func (r *labelRepository) GetGRN(id uint) (*models.GoodsReceiptNote, error) {
+	var grn models.GoodsReceiptNote
+	err := r.db.Preload("Warehouse").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Material").
+		Preload("Items.WarehouseLocation").
+		First(&grn, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &grn, nil
+}
+
+func (r *labelRepository) GetFPRN(id uint) (*models.FinishedProductReceipt, error) {
+	var fprn models.FinishedProductReceipt
+	err := r.db.Preload("Warehouse").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.FinishedProduct").
+		Preload("Items.WarehouseLocation").
+		First(&fprn, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &fprn, nil
+}
+
+func (r *labelRepository) Locations(ids []uint, warehouseID uint) ([]*models.WarehouseLocation, error) {
+	var locations []*models.WarehouseLocation
+	query := r.db.Model(&models.WarehouseLocation{})
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	} else {
+		query = query.Where("warehouse_id = ? AND COALESCE(is_active, TRUE)", warehouseID)
+	}
+	err := query.Order("code").Find(&locations).Error
+	return locations, err
+}
+
+func (r *labelRepository) GetMaterial(id uint) (*models.Material, error) {
+	var m models.Material
+	if err := r.db.First(&m, id).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *labelRepository) GetFinishedProduct(id uint) (*models.FinishedProduct, error) {
+	var fp models.FinishedProduct
+	if err := r.db.First(&fp, id).Error; err != nil {
+		return nil, err
+	}
+	return &fp, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// LandedCostRepository defines landed cost voucher data operations
+type LandedCostRepository interface {
+	Create(voucher *models.LandedCostVoucher) error
+	GetByID(id uint) (*models.LandedCostVoucher, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.LandedCostVoucher, int64, error)
+	UpdateStatus(id uint, status string) error
+	CountByVoucherNumber(prefix string) (int64, error)
+}
+
+type landedCostRepository struct {
+	db *gorm.DB
+}
+
+func NewLandedCostRepository(db *gorm.DB) LandedCostRepository {
+	return &landedCostRepository{db: db}
+}
+
+func (r *landedCostRepository) Create(voucher *models.LandedCostVoucher) error {
+	return r.db.Create(voucher).Error
+}
+
+func (r *landedCostRepository) GetByID(id uint) (*models.LandedCostVoucher, error) {
+	var voucher models.LandedCostVoucher
+	err := r.db.
+		Preload("PostedByUser").
+		Preload("GRNs").
+		Preload("GRNs.GRN").
+		Preload("Charges").
+		Preload("Allocations").
+		Preload("Allocations.Material").
+		First(&voucher, id).Error
+	return &voucher, err
+}
+
+func (r *landedCostRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.LandedCostVoucher, int64, error) {
+	var vouchers []*models.LandedCostVoucher
+	var total int64
+
+	query := r.db.Model(&models.LandedCostVoucher{})
+
+	if search, ok := filters["search"].(string); ok && search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("unaccent(voucher_number) ILIKE unaccent(?) OR unaccent(notes) ILIKE unaccent(?)", pattern, pattern)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if grnID, ok := filters["grn_id"].(uint); ok && grnID > 0 {
+		query = query.Where("id IN (SELECT voucher_id FROM landed_cost_voucher_grns WHERE grn_id = ?)", grnID)
+	}
+
+	query.Count(&total)
+	query = query.Order("voucher_date DESC, id DESC").Offset(offset).Limit(limit)
+	err := query.Preload("Charges").Find(&vouchers).Error
+	return vouchers, total, err
+}
+
+func (r *landedCostRepository) UpdateStatus(id uint, status string) error {
+	return r.db.Model(&models.LandedCostVoucher{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (r *landedCostRepository) CountByVoucherNumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.LandedCostVoucher{}).
+		Where("voucher_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
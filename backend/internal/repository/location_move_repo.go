@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ConsolidationRow is the stock of one item, batch and lot in one storage location, with what the
+// location holds in total
+type ConsolidationRow struct {
+	ItemType         string   `gorm:"column:item_type"`
+	ItemID           uint     `gorm:"column:item_id"`
+	ItemCode         string   `gorm:"column:item_code"`
+	ItemName         string   `gorm:"column:item_name"`
+	BatchNumber      string   `gorm:"column:batch_number"`
+	LotNumber        string   `gorm:"column:lot_number"`
+	LocationID       uint     `gorm:"column:location_id"`
+	LocationCode     string   `gorm:"column:location_code"`
+	MaxQuantity      *float64 `gorm:"column:max_quantity"`
+	Quantity         float64  `gorm:"column:quantity"`
+	ReservedQuantity float64  `gorm:"column:reserved_quantity"`
+	LocationQuantity float64  `gorm:"column:location_quantity"` // every item in the location
+}
+
+// LocationMoveRepository defines data operations for internal location moves
+type LocationMoveRepository interface {
+	Create(move *models.LocationMove) error
+	GetByID(id uint) (*models.LocationMove, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.LocationMove, int64, error)
+	Update(move *models.LocationMove) error
+	UpdateItem(item *models.LocationMoveItem) error
+	CountByMoveNumber(prefix string) (int64, error)
+
+	GetLocations(ids []uint) (map[uint]*models.WarehouseLocation, error)
+	// ConsolidationRows returns the stock held in the active storage locations of a warehouse,
+	// grouped by item, batch and lot and largest quantity first
+	ConsolidationRows(warehouseID uint, itemType string, itemID uint) ([]ConsolidationRow, error)
+}
+
+type locationMoveRepository struct {
+	db *gorm.DB
+}
+
+func NewLocationMoveRepository(db *gorm.DB) LocationMoveRepository {
+	return &locationMoveRepository{db: db}
+}
+
+func (r *locationMoveRepository) Create(move *models.LocationMove) error {
+	return r.db.Omit("Warehouse", "Items.FromLocation", "Items.ToLocation").Create(move).Error
+}
+
+func (r *locationMoveRepository) GetByID(id uint) (*models.LocationMove, error) {
+	var move models.LocationMove
+	err := r.db.Preload("Warehouse").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.FromLocation").
+		Preload("Items.ToLocation").
+		First(&move, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &move, nil
+}
+
+func (r *locationMoveRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.LocationMove, int64, error) {
+	var moves []*models.LocationMove
+	var total int64
+
+	query := r.db.Model(&models.LocationMove{})
+	if warehouseID, ok := filters["warehouse_id"].(uint); ok && warehouseID > 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if source, ok := filters["source"].(string); ok && source != "" {
+		query = query.Where("source = ?", source)
+	}
+	if locationID, ok := filters["location_id"].(uint); ok && locationID > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM location_move_items i WHERE i.move_id = location_moves.id AND (i.from_location_id = ? OR i.to_location_id = ?))", locationID, locationID)
+	}
+
+	query.Count(&total)
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).
+		Preload("Items").Find(&moves).Error
+	return moves, total, err
+}
+
+func (r *locationMoveRepository) Update(move *models.LocationMove) error {
+	return r.db.Omit("Warehouse", "Items").Save(move).Error
+}
+
+func (r *locationMoveRepository) UpdateItem(item *models.LocationMoveItem) error {
+	return r.db.Omit("FromLocation", "ToLocation").Save(item).Error
+}
+
+func (r *locationMoveRepository) CountByMoveNumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.LocationMove{}).Where("move_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
+
+func (r *locationMoveRepository) GetLocations(ids []uint) (map[uint]*models.WarehouseLocation, error) {
+	locations := make(map[uint]*models.WarehouseLocation, len(ids))
+	if len(ids) == 0 {
+		return locations, nil
+	}
+	var rows []*models.WarehouseLocation
+	if err := r.db.Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, loc := range rows {
+		locations[loc.ID] = loc
+	}
+	return locations, nil
+}
+
+func (r *locationMoveRepository) ConsolidationRows(warehouseID uint, itemType string, itemID uint) ([]ConsolidationRow, error) {
+	var rows []ConsolidationRow
+	query := r.db.Table("stock_balance sb").
+		Select(`sb.item_type, sb.item_id,
+			COALESCE(m.code, fp.code, '') AS item_code, COALESCE(m.trading_name, fp.name, '') AS item_name,
+			COALESCE(sb.batch_number, '') AS batch_number, COALESCE(sb.lot_number, '') AS lot_number,
+			wl.id AS location_id, wl.code AS location_code, wl.max_quantity,
+			sb.quantity, COALESCE(sb.reserved_quantity, 0) AS reserved_quantity,
+			(SELECT COALESCE(SUM(o.quantity), 0) FROM stock_balance o
+			 WHERE o.warehouse_location_id = wl.id AND o.quantity > 0) AS location_quantity`).
+		Joins("JOIN warehouse_locations wl ON wl.id = sb.warehouse_location_id").
+		Joins("LEFT JOIN materials m ON sb.item_type = 'material' AND m.id = sb.item_id").
+		Joins("LEFT JOIN finished_products fp ON sb.item_type = 'finished_product' AND fp.id = sb.item_id").
+		Where("sb.warehouse_id = ? AND sb.quantity > 0 AND COALESCE(wl.is_active, TRUE)", warehouseID).
+		Where("COALESCE(wl.location_type, 'storage') NOT IN ?", []string{"receiving", "shipping", "quarantine", "in_transit"})
+	if itemType != "" {
+		query = query.Where("sb.item_type = ?", itemType)
+	}
+	if itemID > 0 {
+		query = query.Where("sb.item_id = ?", itemID)
+	}
+	err := query.Order("sb.item_type, sb.item_id, batch_number, lot_number, sb.quantity DESC, wl.id").
+		Scan(&rows).Error
+	return rows, err
+}
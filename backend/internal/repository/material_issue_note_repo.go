@@ -31,7 +31,7 @@ func (r *materialIssueNoteRepository) GetByID(id uint) (*models.MaterialIssueNot
 	err := r.db.Preload("Items.Material").
 		Preload("Items.WarehouseLocation").
 		Preload("Warehouse").
-		Preload("MaterialRequest").
+		Preload("ProductionPlan").
 		First(&min, id).Error
 	if err != nil {
 		return nil, err
@@ -44,7 +44,7 @@ func (r *materialIssueNoteRepository) GetByNumber(minNumber string) (*models.Mat
 	err := r.db.Preload("Items.Material").
 		Preload("Items.WarehouseLocation").
 		Preload("Warehouse").
-		Preload("MaterialRequest").
+		Preload("ProductionPlan").
 		Where("min_number = ?", minNumber).First(&min).Error
 	if err != nil {
 		return nil, err
@@ -77,7 +77,7 @@ func (r *materialIssueNoteRepository) List(filters map[string]interface{}, offse
 	}
 
 	err = query.Preload("Warehouse").
-		Preload("MaterialRequest").
+		Preload("ProductionPlan").
 		Offset(offset).Limit(limit).
 		Order("created_at DESC").
 		Find(&mins).Error
@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Task list scopes for the handheld API
+const (
+	MobileScopeOpen      = "open"      // mine and unassigned
+	MobileScopeMine      = "mine"      // assigned to the user
+	MobileScopeAvailable = "available" // unassigned
+	MobileScopeAll       = "all"       // everything open, whoever has it
+)
+
+// MobileTaskQuery selects open tasks of one type for a user
+type MobileTaskQuery struct {
+	Scope       string
+	UserID      uint
+	WarehouseID uint
+	Limit       int
+}
+
+// mobileTaskTables are the tables behind the handheld task types
+var mobileTaskTables = map[string]string{
+	models.MobileTaskPutaway:  "putaway_tasks",
+	models.MobileTaskPick:     "pick_lists",
+	models.MobileTaskCount:    "cycle_count_tasks",
+	models.MobileTaskTransfer: "stock_transfers",
+	models.MobileTaskReceive:  "stock_transfers",
+}
+
+// MobileItemName is the code and name of a material or finished product
+type MobileItemName struct {
+	ID   uint   `gorm:"column:id"`
+	Code string `gorm:"column:code"`
+	Name string `gorm:"column:name"`
+}
+
+// MobileTaskRepository defines data operations for handheld tasks, their assignments,
+// step confirmations and reported exceptions
+type MobileTaskRepository interface {
+	OpenPutawayTasks(q MobileTaskQuery) ([]*models.PutawayTask, error)
+	OpenPickLists(q MobileTaskQuery) ([]*models.PickList, error)
+	OpenCountTasks(q MobileTaskQuery) ([]*models.CycleCountTask, error)
+	// OpenTransfers returns the transfers still to be shipped from their source warehouse
+	OpenTransfers(q MobileTaskQuery) ([]*models.StockTransfer, error)
+	// OpenReceipts returns the shipped transfers still to be received at their destination
+	OpenReceipts(q MobileTaskQuery) ([]*models.StockTransfer, error)
+	// LockTask locks the task row for the rest of the transaction, so steps of one task are confirmed one at a time
+	LockTask(taskType string, id uint) error
+	GetPutawayTask(id uint) (*models.PutawayTask, error)
+	GetPickList(id uint) (*models.PickList, error)
+	GetCountTask(id uint) (*models.CycleCountTask, error)
+	GetTransfer(id uint) (*models.StockTransfer, error)
+	// ItemNames returns the code and name of materials or finished products by ID
+	ItemNames(itemType string, ids []uint) (map[uint]MobileItemName, error)
+	UserName(id uint) (string, error)
+
+	// ActiveAssignments returns the active assignments of the given tasks of one type
+	ActiveAssignments(taskType string, taskIDs []uint) ([]*models.MobileTaskAssignment, error)
+	// CreateAssignment returns false when the task already has an active assignment
+	CreateAssignment(a *models.MobileTaskAssignment) (bool, error)
+	UpdateAssignment(a *models.MobileTaskAssignment) error
+
+	// GetConfirmation returns the confirmation stored under a request ID, or nil
+	GetConfirmation(requestID string) (*models.MobileStepConfirmation, error)
+	// CreateConfirmation returns false when the request ID is already taken; a concurrent insert
+	// of the same request ID waits for the other transaction to finish first
+	CreateConfirmation(c *models.MobileStepConfirmation) (bool, error)
+	SaveConfirmationResponse(id uint, response models.MobileResponse) error
+	// ConfirmedSteps returns the step IDs confirmed for a task
+	ConfirmedSteps(taskType string, taskID uint) ([]uint, error)
+
+	CreateException(e *models.MobileTaskException) error
+	GetException(id uint) (*models.MobileTaskException, error)
+	UpdateException(e *models.MobileTaskException) error
+	ListExceptions(filters map[string]interface{}, offset, limit int) ([]*models.MobileTaskException, int64, error)
+}
+
+type mobileTaskRepository struct {
+	db *gorm.DB
+}
+
+func NewMobileTaskRepository(db *gorm.DB) MobileTaskRepository {
+	return &mobileTaskRepository{db: db}
+}
+
+// scoped limits a task query to the assignment scope; table is the task table of taskType
+func (r *mobileTaskRepository) scoped(taskType, table string, q MobileTaskQuery) *gorm.DB {
+	active := "SELECT 1 FROM mobile_task_assignments a WHERE a.task_type = ? AND a.task_id = " + table + ".id AND a.status = 'active'"
+	query := r.db.Table(table)
+	switch q.Scope {
+	case MobileScopeMine:
+		query = query.Where("EXISTS ("+active+" AND a.assigned_to = ?)", taskType, q.UserID)
+	case MobileScopeAvailable:
+		query = query.Where("NOT EXISTS ("+active+")", taskType)
+	case MobileScopeAll:
+	default:
+		query = query.Where("NOT EXISTS ("+active+" AND a.assigned_to <> ?)", taskType, q.UserID)
+	}
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit)
+	}
+	return query
+}
+
+func (r *mobileTaskRepository) OpenPutawayTasks(q MobileTaskQuery) ([]*models.PutawayTask, error) {
+	var tasks []*models.PutawayTask
+	query := r.scoped(models.MobileTaskPutaway, "putaway_tasks", q).
+		Where("putaway_tasks.status IN ?", []string{"pending", "in_progress"})
+	if q.WarehouseID > 0 {
+		query = query.Where("putaway_tasks.warehouse_id = ?", q.WarehouseID)
+	}
+	err := query.Preload("FromLocation").Preload("SuggestedLocation").
+		Order("putaway_tasks.created_at, putaway_tasks.id").Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *mobileTaskRepository) OpenPickLists(q MobileTaskQuery) ([]*models.PickList, error) {
+	var lists []*models.PickList
+	query := r.scoped(models.MobileTaskPick, "pick_lists", q).
+		Where("pick_lists.status IN ?", []string{"open", "in_progress"})
+	if q.WarehouseID > 0 {
+		query = query.Where("pick_lists.warehouse_id = ?", q.WarehouseID)
+	}
+	err := query.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence, id")
+	}).Order("pick_lists.created_at, pick_lists.id").Find(&lists).Error
+	return lists, err
+}
+
+func (r *mobileTaskRepository) OpenCountTasks(q MobileTaskQuery) ([]*models.CycleCountTask, error) {
+	var tasks []*models.CycleCountTask
+	query := r.scoped(models.MobileTaskCount, "cycle_count_tasks", q).
+		Where("cycle_count_tasks.status IN ?", []string{"pending", "recount"})
+	if q.WarehouseID > 0 {
+		query = query.Where("cycle_count_tasks.warehouse_id = ?", q.WarehouseID)
+	}
+	err := query.Preload("Location").
+		Order("cycle_count_tasks.scheduled_date, cycle_count_tasks.id").Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *mobileTaskRepository) OpenTransfers(q MobileTaskQuery) ([]*models.StockTransfer, error) {
+	var transfers []*models.StockTransfer
+	query := r.scoped(models.MobileTaskTransfer, "stock_transfers", q).
+		Where("stock_transfers.posted = ? AND stock_transfers.status IN ?", false, []string{"draft", "approved"})
+	if q.WarehouseID > 0 {
+		query = query.Where("stock_transfers.from_warehouse_id = ?", q.WarehouseID)
+	}
+	err := query.Preload("Items.FromLocation").Preload("Items.ToLocation").
+		Order("stock_transfers.transfer_date, stock_transfers.id").Find(&transfers).Error
+	return transfers, err
+}
+
+func (r *mobileTaskRepository) OpenReceipts(q MobileTaskQuery) ([]*models.StockTransfer, error) {
+	var transfers []*models.StockTransfer
+	query := r.scoped(models.MobileTaskReceive, "stock_transfers", q).
+		Where("stock_transfers.status = ?", "shipped")
+	if q.WarehouseID > 0 {
+		query = query.Where("stock_transfers.to_warehouse_id = ?", q.WarehouseID)
+	}
+	err := query.Preload("TransitLocation").Preload("Items.FromLocation").Preload("Items.ToLocation").
+		Order("stock_transfers.shipped_at, stock_transfers.id").Find(&transfers).Error
+	return transfers, err
+}
+
+func (r *mobileTaskRepository) LockTask(taskType string, id uint) error {
+	table, ok := mobileTaskTables[taskType]
+	if !ok {
+		return fmt.Errorf("unknown task type %q", taskType)
+	}
+	var ids []uint
+	if err := r.db.Raw("SELECT id FROM "+table+" WHERE id = ? FOR UPDATE", id).Scan(&ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *mobileTaskRepository) GetPutawayTask(id uint) (*models.PutawayTask, error) {
+	var task models.PutawayTask
+	if err := r.db.Preload("FromLocation").Preload("SuggestedLocation").First(&task, id).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (r *mobileTaskRepository) GetPickList(id uint) (*models.PickList, error) {
+	var list models.PickList
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence, id")
+	}).Preload("Lines.Location").First(&list, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *mobileTaskRepository) GetCountTask(id uint) (*models.CycleCountTask, error) {
+	var task models.CycleCountTask
+	if err := r.db.Preload("Location").First(&task, id).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (r *mobileTaskRepository) GetTransfer(id uint) (*models.StockTransfer, error) {
+	var st models.StockTransfer
+	if err := r.db.Preload("TransitLocation").Preload("Items.FromLocation").Preload("Items.ToLocation").First(&st, id).Error; err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (r *mobileTaskRepository) ItemNames(itemType string, ids []uint) (map[uint]MobileItemName, error) {
+	names := make(map[uint]MobileItemName, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	var rows []MobileItemName
+	query := r.db.Table("finished_products").Select("id, code, name")
+	if itemType == "material" {
+		query = r.db.Table("materials").Select("id, code, trading_name AS name")
+	}
+	if err := query.Where("id IN ?", ids).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		names[row.ID] = row
+	}
+	return names, nil
+}
+
+func (r *mobileTaskRepository) UserName(id uint) (string, error) {
+	var user models.User
+	if err := r.db.Select("id, username").First(&user, id).Error; err != nil {
+		return "", err
+	}
+	return user.Username, nil
+}
+
+func (r *mobileTaskRepository) ActiveAssignments(taskType string, taskIDs []uint) ([]*models.MobileTaskAssignment, error) {
+	var assignments []*models.MobileTaskAssignment
+	if len(taskIDs) == 0 {
+		return assignments, nil
+	}
+	err := r.db.Where("task_type = ? AND task_id IN ? AND status = ?", taskType, taskIDs, models.MobileAssignmentActive).
+		Find(&assignments).Error
+	return assignments, err
+}
+
+func (r *mobileTaskRepository) CreateAssignment(a *models.MobileTaskAssignment) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(a)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *mobileTaskRepository) UpdateAssignment(a *models.MobileTaskAssignment) error {
+	return r.db.Save(a).Error
+}
+
+func (r *mobileTaskRepository) GetConfirmation(requestID string) (*models.MobileStepConfirmation, error) {
+	var confirmations []*models.MobileStepConfirmation
+	if err := r.db.Where("request_id = ?", requestID).Limit(1).Find(&confirmations).Error; err != nil {
+		return nil, err
+	}
+	if len(confirmations) == 0 {
+		return nil, nil
+	}
+	return confirmations[0], nil
+}
+
+func (r *mobileTaskRepository) CreateConfirmation(c *models.MobileStepConfirmation) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "request_id"}},
+		DoNothing: true,
+	}).Create(c)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *mobileTaskRepository) SaveConfirmationResponse(id uint, response models.MobileResponse) error {
+	return r.db.Model(&models.MobileStepConfirmation{}).Where("id = ?", id).Update("response", response).Error
+}
+
+func (r *mobileTaskRepository) ConfirmedSteps(taskType string, taskID uint) ([]uint, error) {
+	var steps []uint
+	err := r.db.Model(&models.MobileStepConfirmation{}).
+		Where("task_type = ? AND task_id = ? AND step_id > 0", taskType, taskID).
+		Distinct().Pluck("step_id", &steps).Error
+	return steps, err
+}
+
+func (r *mobileTaskRepository) CreateException(e *models.MobileTaskException) error {
+	return r.db.Create(e).Error
+}
+
+func (r *mobileTaskRepository) GetException(id uint) (*models.MobileTaskException, error) {
+	var e models.MobileTaskException
+	if err := r.db.First(&e, id).Error; err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *mobileTaskRepository) UpdateException(e *models.MobileTaskException) error {
+	return r.db.Save(e).Error
+}
+
+func (r *mobileTaskRepository) ListExceptions(filters map[string]interface{}, offset, limit int) ([]*models.MobileTaskException, int64, error) {
+	var exceptions []*models.MobileTaskException
+	var total int64
+
+	query := r.db.Model(&models.MobileTaskException{})
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if taskType, ok := filters["task_type"].(string); ok && taskType != "" {
+		query = query.Where("task_type = ?", taskType)
+	}
+	if warehouseID, ok := filters["warehouse_id"].(uint); ok && warehouseID > 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if reportedBy, ok := filters["reported_by"].(uint); ok && reportedBy > 0 {
+		query = query.Where("reported_by = ?", reportedBy)
+	}
+
+	query.Count(&total)
+	err := query.Order("reported_at DESC, id DESC").Offset(offset).Limit(limit).Find(&exceptions).Error
+	return exceptions, total, err
+}
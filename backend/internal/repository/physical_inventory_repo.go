@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ItemLabel is the code and name of a material or finished product for count sheets
+type ItemLabel struct {
+	ID           uint     `gorm:"column:id"`
+	Code         string   `gorm:"column:code"`
+	Name         string   `gorm:"column:name"`
+	StandardCost *float64 `gorm:"column:standard_cost"`
+}
+
+// PhysicalInventoryRepository defines data operations for stocktakes
+type PhysicalInventoryRepository interface {
+	Create(pi *models.PhysicalInventory) error
+	GetByID(id uint) (*models.PhysicalInventory, error)
+	// Lock takes a row lock on the stocktake until the transaction ends, so counting, finalizing
+	// and cancelling run one at a time
+	Lock(id uint) error
+	List(filters map[string]interface{}, offset, limit int) ([]*models.PhysicalInventory, int64, error)
+	Update(pi *models.PhysicalInventory) error
+	CreateLine(line *models.PhysicalInventoryLine) error
+	UpdateLine(line *models.PhysicalInventoryLine) error
+	CreateCount(count *models.PhysicalInventoryCount) error
+	CountByInventoryNumber(prefix string) (int64, error)
+
+	// Snapshot returns the non-zero balances of the warehouse with their locations
+	Snapshot(warehouseID uint) ([]*models.StockBalance, error)
+	// CountingInventories returns the stocktakes still counting in any of the warehouses
+	CountingInventories(warehouseIDs []uint) ([]*models.PhysicalInventory, error)
+	GetLocation(id uint) (*models.WarehouseLocation, error)
+	// ItemLabels returns code, name and standard cost of the given materials or finished products
+	ItemLabels(itemType string, ids []uint) (map[uint]ItemLabel, error)
+}
+
+type physicalInventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPhysicalInventoryRepository(db *gorm.DB) PhysicalInventoryRepository {
+	return &physicalInventoryRepository{db: db}
+}
+
+func (r *physicalInventoryRepository) Create(pi *models.PhysicalInventory) error {
+	return r.db.Omit("Warehouse").Create(pi).Error
+}
+
+func (r *physicalInventoryRepository) GetByID(id uint) (*models.PhysicalInventory, error) {
+	var pi models.PhysicalInventory
+	err := r.db.Preload("Warehouse").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("sheet_number, location_path, item_type, item_id, id") }).
+		Preload("Lines.Counts", func(db *gorm.DB) *gorm.DB { return db.Order("round") }).
+		First(&pi, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pi, nil
+}
+
+func (r *physicalInventoryRepository) Lock(id uint) error {
+	var ids []uint
+	if err := r.db.Raw("SELECT id FROM physical_inventories WHERE id = ? FOR UPDATE", id).Scan(&ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *physicalInventoryRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.PhysicalInventory, int64, error) {
+	var inventories []*models.PhysicalInventory
+	var total int64
+
+	query := r.db.Model(&models.PhysicalInventory{})
+	if warehouseID, ok := filters["warehouse_id"].(uint); ok && warehouseID > 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query.Count(&total)
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).
+		Preload("Warehouse").Find(&inventories).Error
+	return inventories, total, err
+}
+
+func (r *physicalInventoryRepository) Update(pi *models.PhysicalInventory) error {
+	return r.db.Omit("Warehouse", "Lines").Save(pi).Error
+}
+
+func (r *physicalInventoryRepository) CreateLine(line *models.PhysicalInventoryLine) error {
+	return r.db.Omit("Counts").Create(line).Error
+}
+
+func (r *physicalInventoryRepository) UpdateLine(line *models.PhysicalInventoryLine) error {
+	return r.db.Omit("Counts").Save(line).Error
+}
+
+func (r *physicalInventoryRepository) CreateCount(count *models.PhysicalInventoryCount) error {
+	return r.db.Create(count).Error
+}
+
+func (r *physicalInventoryRepository) CountByInventoryNumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PhysicalInventory{}).Where("inventory_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
+
+func (r *physicalInventoryRepository) Snapshot(warehouseID uint) ([]*models.StockBalance, error) {
+	var balances []*models.StockBalance
+	err := r.db.Preload("WarehouseLocation").
+		Where("warehouse_id = ? AND quantity <> 0", warehouseID).
+		Where(notInTransit).
+		Order("warehouse_location_id, item_type, item_id, batch_number, lot_number").
+		Find(&balances).Error
+	return balances, err
+}
+
+func (r *physicalInventoryRepository) CountingInventories(warehouseIDs []uint) ([]*models.PhysicalInventory, error) {
+	var inventories []*models.PhysicalInventory
+	err := r.db.Where("warehouse_id IN ? AND status = ?", warehouseIDs, "counting").Find(&inventories).Error
+	return inventories, err
+}
+
+func (r *physicalInventoryRepository) GetLocation(id uint) (*models.WarehouseLocation, error) {
+	var location models.WarehouseLocation
+	if err := r.db.First(&location, id).Error; err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (r *physicalInventoryRepository) ItemLabels(itemType string, ids []uint) (map[uint]ItemLabel, error) {
+	labels := make(map[uint]ItemLabel, len(ids))
+	if len(ids) == 0 {
+		return labels, nil
+	}
+	var rows []ItemLabel
+	var err error
+	if itemType == "material" {
+		err = r.db.Model(&models.Material{}).Select("id, code, trading_name AS name, standard_cost").
+			Where("id IN ?", ids).Scan(&rows).Error
+	} else {
+		err = r.db.Model(&models.FinishedProduct{}).Select("id, code, name, standard_cost").
+			Where("id IN ?", ids).Scan(&rows).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		labels[row.ID] = row
+	}
+	return labels, nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// PickAllocation is stock already promised to pick lines that have not been shipped yet
+type PickAllocation struct {
+	FinishedProductID uint    `gorm:"column:finished_product_id"`
+	LocationID        *uint   `gorm:"column:location_id"`
+	BatchNumber       string  `gorm:"column:batch_number"`
+	LotNumber         string  `gorm:"column:lot_number"`
+	Quantity          float64 `gorm:"column:quantity"`
+}
+
+// PickListRepository defines data operations for pick lists and the stock they allocate
+type PickListRepository interface {
+	Create(list *models.PickList) error
+	GetByID(id uint) (*models.PickList, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.PickList, int64, error)
+	Update(list *models.PickList) error
+	UpdateLine(line *models.PickListLine) error
+	CountByPickNumber(prefix string) (int64, error)
+
+	// PickableBalances returns the unexpired stock of a product outside quarantine and transit, FEFO first.
+	// Reserved stock is included: it is pickable for the delivery orders holding the reservation.
+	PickableBalances(warehouseID, productID uint) ([]*models.StockBalance, error)
+	AllocatedQuantities(warehouseID uint) ([]PickAllocation, error)
+	// LinesForDeliveryOrder returns the lines of a delivery order on pick lists that were not cancelled
+	LinesForDeliveryOrder(deliveryOrderID uint) ([]*models.PickListLine, error)
+	// SetDeliveryOrderStatus moves the given delivery orders from one status to another
+	SetDeliveryOrderStatus(ids []uint, from, to string) error
+	// ActiveDeliveryOrderIDs returns which of the delivery orders are still on an active pick list
+	ActiveDeliveryOrderIDs(ids []uint) ([]uint, error)
+	ShippedDeliveryOrderNumbers(ids []uint) ([]string, error)
+	DeleteLinesForDeliveryOrder(pickListID, deliveryOrderID uint) error
+}
+
+type pickListRepository struct {
+	db *gorm.DB
+}
+
+func NewPickListRepository(db *gorm.DB) PickListRepository {
+	return &pickListRepository{db: db}
+}
+
+func (r *pickListRepository) Create(list *models.PickList) error {
+	return r.db.Omit("Warehouse", "Lines.Product", "Lines.Location").Create(list).Error
+}
+
+func (r *pickListRepository) GetByID(id uint) (*models.PickList, error) {
+	var list models.PickList
+	err := r.db.Preload("Warehouse").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("sequence, id") }).
+		Preload("Lines.Product").
+		Preload("Lines.Location").
+		First(&list, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *pickListRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.PickList, int64, error) {
+	var lists []*models.PickList
+	var total int64
+
+	query := r.db.Model(&models.PickList{})
+	if warehouseID, ok := filters["warehouse_id"].(uint); ok && warehouseID > 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if doID, ok := filters["delivery_order_id"].(uint); ok && doID > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM pick_list_lines l WHERE l.pick_list_id = pick_lists.id AND l.delivery_order_id = ?)", doID)
+	}
+
+	query.Count(&total)
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).
+		Preload("Warehouse").Find(&lists).Error
+	return lists, total, err
+}
+
+func (r *pickListRepository) Update(list *models.PickList) error {
+	return r.db.Omit("Warehouse", "Lines").Save(list).Error
+}
+
+func (r *pickListRepository) UpdateLine(line *models.PickListLine) error {
+	return r.db.Omit("Product", "Location").Save(line).Error
+}
+
+func (r *pickListRepository) CountByPickNumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PickList{}).Where("pick_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
+
+func (r *pickListRepository) PickableBalances(warehouseID, productID uint) ([]*models.StockBalance, error) {
+	var balances []*models.StockBalance
+	err := r.db.Preload("WarehouseLocation").
+		Where("stock_balance.item_type = ? AND stock_balance.item_id = ? AND stock_balance.warehouse_id = ?", "finished_product", productID, warehouseID).
+		Where("stock_balance.quantity > 0").
+		Where("stock_balance.expiry_date IS NULL OR stock_balance.expiry_date >= CURRENT_DATE").
+		Where(`stock_balance.warehouse_location_id IS NULL OR NOT EXISTS (
+			SELECT 1 FROM warehouse_locations wl
+			WHERE wl.id = stock_balance.warehouse_location_id
+			  AND (wl.location_type IN ('quarantine', 'in_transit') OR NOT COALESCE(wl.is_active, TRUE)))`).
+		Order("stock_balance.expiry_date ASC NULLS LAST, stock_balance.created_at ASC, stock_balance.id ASC").
+		Find(&balances).Error
+	return balances, err
+}
+
+// AllocatedQuantities sums pending lines (allocated quantity) and picked lines (picked quantity)
+// of active pick lists whose delivery order is neither shipped nor cancelled
+func (r *pickListRepository) AllocatedQuantities(warehouseID uint) ([]PickAllocation, error) {
+	var rows []PickAllocation
+	err := r.db.Table("pick_list_lines l").
+		Select(`l.finished_product_id, l.location_id, COALESCE(l.batch_number, '') AS batch_number,
+			COALESCE(l.lot_number, '') AS lot_number,
+			SUM(CASE WHEN l.status = 'pending' THEN l.quantity ELSE l.picked_quantity END) AS quantity`).
+		Joins("JOIN pick_lists p ON p.id = l.pick_list_id").
+		Joins("JOIN delivery_orders d ON d.id = l.delivery_order_id").
+		Where("p.warehouse_id = ? AND p.status <> ?", warehouseID, "cancelled").
+		Where("NOT COALESCE(d.posted, FALSE) AND d.status <> ?", "cancelled").
+		Group("l.finished_product_id, l.location_id, l.batch_number, l.lot_number").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *pickListRepository) LinesForDeliveryOrder(deliveryOrderID uint) ([]*models.PickListLine, error) {
+	var lines []*models.PickListLine
+	err := r.db.Joins("JOIN pick_lists p ON p.id = pick_list_lines.pick_list_id").
+		Where("pick_list_lines.delivery_order_id = ? AND p.status <> ?", deliveryOrderID, "cancelled").
+		Order("pick_list_lines.delivery_order_item_id, pick_list_lines.sequence, pick_list_lines.id").
+		Find(&lines).Error
+	return lines, err
+}
+
+func (r *pickListRepository) SetDeliveryOrderStatus(ids []uint, from, to string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.DeliveryOrder{}).
+		Where("id IN ? AND status = ? AND NOT COALESCE(posted, FALSE)", ids, from).
+		Update("status", to).Error
+}
+
+func (r *pickListRepository) ActiveDeliveryOrderIDs(ids []uint) ([]uint, error) {
+	var active []uint
+	if len(ids) == 0 {
+		return active, nil
+	}
+	err := r.db.Table("pick_list_lines l").
+		Joins("JOIN pick_lists p ON p.id = l.pick_list_id").
+		Where("l.delivery_order_id IN ? AND p.status <> ?", ids, "cancelled").
+		Distinct("l.delivery_order_id").
+		Pluck("l.delivery_order_id", &active).Error
+	return active, err
+}
+
+func (r *pickListRepository) ShippedDeliveryOrderNumbers(ids []uint) ([]string, error) {
+	var numbers []string
+	if len(ids) == 0 {
+		return numbers, nil
+	}
+	err := r.db.Model(&models.DeliveryOrder{}).
+		Where("id IN ? AND COALESCE(posted, FALSE)", ids).
+		Order("do_number").
+		Pluck("do_number", &numbers).Error
+	return numbers, err
+}
+
+func (r *pickListRepository) DeleteLinesForDeliveryOrder(pickListID, deliveryOrderID uint) error {
+	return r.db.Where("pick_list_id = ? AND delivery_order_id = ?", pickListID, deliveryOrderID).
+		Delete(&models.PickListLine{}).Error
+}
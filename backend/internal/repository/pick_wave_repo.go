@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// WaveCriteria selects the open delivery orders that go into a wave
+type WaveCriteria struct {
+	WarehouseID    uint
+	CarrierID      *uint
+	SalesChannelID *uint
+	CutoffAt       *time.Time // created at or before
+	DeliveryDate   string     // due on or before (YYYY-MM-DD)
+	IDs            []uint
+	MaxOrders      int
+}
+
+// PickWaveRepository defines data operations for pick waves
+type PickWaveRepository interface {
+	Create(wave *models.PickWave) error
+	GetByID(id uint) (*models.PickWave, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.PickWave, int64, error)
+	Update(wave *models.PickWave) error
+	UpdateOrder(order *models.PickWaveOrder) error
+	UpdateLine(line *models.PickWaveLine) error
+	CountByWaveNumber(prefix string) (int64, error)
+	// FindOpenDeliveryOrders returns draft, unposted delivery orders matching the criteria, earliest due first
+	FindOpenDeliveryOrders(c WaveCriteria) ([]*models.DeliveryOrder, error)
+}
+
+type pickWaveRepository struct {
+	db *gorm.DB
+}
+
+func NewPickWaveRepository(db *gorm.DB) PickWaveRepository {
+	return &pickWaveRepository{db: db}
+}
+
+func (r *pickWaveRepository) Create(wave *models.PickWave) error {
+	return r.db.Omit("Warehouse", "Carrier", "SalesChannel", "Lines.Product", "Lines.Location").Create(wave).Error
+}
+
+func (r *pickWaveRepository) GetByID(id uint) (*models.PickWave, error) {
+	var wave models.PickWave
+	err := r.db.Preload("Warehouse").Preload("Carrier").Preload("SalesChannel").
+		Preload("Orders", func(db *gorm.DB) *gorm.DB { return db.Order("sequence, id") }).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("sequence, id") }).
+		Preload("Lines.Product").
+		Preload("Lines.Location").
+		First(&wave, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &wave, nil
+}
+
+func (r *pickWaveRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.PickWave, int64, error) {
+	var waves []*models.PickWave
+	var total int64
+
+	query := r.db.Model(&models.PickWave{})
+	if warehouseID, ok := filters["warehouse_id"].(uint); ok && warehouseID > 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if carrierID, ok := filters["carrier_id"].(uint); ok && carrierID > 0 {
+		query = query.Where("carrier_id = ?", carrierID)
+	}
+	if channelID, ok := filters["sales_channel_id"].(uint); ok && channelID > 0 {
+		query = query.Where("sales_channel_id = ?", channelID)
+	}
+
+	query.Count(&total)
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).
+		Preload("Warehouse").Preload("Carrier").Preload("SalesChannel").Find(&waves).Error
+	return waves, total, err
+}
+
+func (r *pickWaveRepository) Update(wave *models.PickWave) error {
+	return r.db.Omit("Warehouse", "Carrier", "SalesChannel", "Orders", "Lines").Save(wave).Error
+}
+
+func (r *pickWaveRepository) UpdateOrder(order *models.PickWaveOrder) error {
+	return r.db.Save(order).Error
+}
+
+func (r *pickWaveRepository) UpdateLine(line *models.PickWaveLine) error {
+	return r.db.Omit("Product", "Location").Save(line).Error
+}
+
+func (r *pickWaveRepository) CountByWaveNumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PickWave{}).Where("wave_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
+
+func (r *pickWaveRepository) FindOpenDeliveryOrders(c WaveCriteria) ([]*models.DeliveryOrder, error) {
+	var orders []*models.DeliveryOrder
+	query := r.db.Where("warehouse_id = ? AND status = ? AND NOT COALESCE(posted, FALSE)", c.WarehouseID, "draft")
+	if c.CarrierID != nil {
+		query = query.Where("carrier_id = ?", *c.CarrierID)
+	}
+	if c.SalesChannelID != nil {
+		query = query.Where("sales_channel_id = ?", *c.SalesChannelID)
+	}
+	if c.CutoffAt != nil {
+		query = query.Where("created_at <= ?", *c.CutoffAt)
+	}
+	if c.DeliveryDate != "" {
+		query = query.Where("delivery_date <= ?", c.DeliveryDate)
+	}
+	if len(c.IDs) > 0 {
+		query = query.Where("id IN ?", c.IDs)
+	}
+	if c.MaxOrders > 0 {
+		query = query.Limit(c.MaxOrders)
+	}
+	err := query.Preload("Items").Order("delivery_date ASC, created_at ASC, id ASC").Find(&orders).Error
+	return orders, err
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// POEmailLogRepository stores the supplier e-mail log and finds POs due for a delivery reminder
+type POEmailLogRepository interface {
+	Create(log *models.POEmailLog) error
+	List(filters map[string]interface{}, offset, limit int) ([]*models.POEmailLog, int64, error)
+	ReminderSent(poID uint, reminderFor string) (bool, error)
+	ListReminderCandidates(fromDate, toDate string) ([]uint, error)
+}
+
+type poEmailLogRepository struct {
+	db *gorm.DB
+}
+
+func NewPOEmailLogRepository(db *gorm.DB) POEmailLogRepository {
+	return &poEmailLogRepository{db: db}
+}
+
+func (r *poEmailLogRepository) Create(log *models.POEmailLog) error {
+	return r.db.Omit("PurchaseOrder", "SentByUser").Create(log).Error
+}
+
+func (r *poEmailLogRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.POEmailLog, int64, error) {
+	var logs []*models.POEmailLog
+	var total int64
+
+	query := r.db.Model(&models.POEmailLog{})
+	if poID, ok := filters["purchase_order_id"].(uint); ok && poID > 0 {
+		query = query.Where("purchase_order_id = ?", poID)
+	}
+	if supplierID, ok := filters["supplier_id"].(uint); ok && supplierID > 0 {
+		query = query.Where("supplier_id = ?", supplierID)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if messageType, ok := filters["message_type"].(string); ok && messageType != "" {
+		query = query.Where("message_type = ?", messageType)
+	}
+
+	query.Count(&total)
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).
+		Preload("SentByUser").Find(&logs).Error
+	return logs, total, err
+}
+
+// ReminderSent reports whether a delivery reminder was already sent for the PO's expected date
+func (r *poEmailLogRepository) ReminderSent(poID uint, reminderFor string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.POEmailLog{}).
+		Where("purchase_order_id = ? AND message_type = ? AND reminder_for = ? AND status = ?",
+			poID, "delivery_reminder", reminderFor, "sent").
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListReminderCandidates returns approved POs still awaiting goods whose expected delivery
+// date falls within [fromDate, toDate]
+func (r *poEmailLogRepository) ListReminderCandidates(fromDate, toDate string) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.PurchaseOrder{}).
+		Where("status = ? AND receipt_status IN ?", "approved", []string{"pending", "partial"}).
+		Where("expected_delivery_date BETWEEN ? AND ?", fromDate, toDate).
+		Order("expected_delivery_date, id").
+		Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AgreementConsumptionRow aggregates call-off PO lines per material
+type AgreementConsumptionRow struct {
+	MaterialID       uint
+	Quantity         float64
+	ReceivedQuantity float64
+	Value            float64
+}
+
+// PurchaseAgreementRepository defines blanket purchase agreement data operations
+type PurchaseAgreementRepository interface {
+	Create(agreement *models.PurchaseAgreement) error
+	GetByID(id uint) (*models.PurchaseAgreement, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.PurchaseAgreement, int64, error)
+	Update(agreement *models.PurchaseAgreement) error
+	ReplaceItems(agreementID uint, items []*models.PurchaseAgreementItem) error
+	UpdateStatus(id uint, status string, updatedBy uint) error
+	CountByAgreementNumber(prefix string) (int64, error)
+	GetConsumption(agreementID uint, excludePOID uint) ([]AgreementConsumptionRow, error)
+	CountOpenCallOffs(agreementID uint) (int64, error)
+}
+
+type purchaseAgreementRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseAgreementRepository(db *gorm.DB) PurchaseAgreementRepository {
+	return &purchaseAgreementRepository{db: db}
+}
+
+func (r *purchaseAgreementRepository) Create(agreement *models.PurchaseAgreement) error {
+	return r.db.Create(agreement).Error
+}
+
+func (r *purchaseAgreementRepository) GetByID(id uint) (*models.PurchaseAgreement, error) {
+	var agreement models.PurchaseAgreement
+	err := r.db.
+		Preload("Supplier").
+		Preload("ApprovedByUser").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Material").
+		First(&agreement, id).Error
+	return &agreement, err
+}
+
+func (r *purchaseAgreementRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.PurchaseAgreement, int64, error) {
+	var agreements []*models.PurchaseAgreement
+	var total int64
+
+	query := r.db.Model(&models.PurchaseAgreement{})
+
+	if search, ok := filters["search"].(string); ok && search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("unaccent(agreement_number) ILIKE unaccent(?) OR unaccent(notes) ILIKE unaccent(?)", pattern, pattern)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if supplierID, ok := filters["supplier_id"].(uint); ok && supplierID > 0 {
+		query = query.Where("supplier_id = ?", supplierID)
+	}
+	if materialID, ok := filters["material_id"].(uint); ok && materialID > 0 {
+		query = query.Where("id IN (SELECT agreement_id FROM purchase_agreement_items WHERE material_id = ?)", materialID)
+	}
+
+	query.Count(&total)
+	query = query.Order("start_date DESC, id DESC").Offset(offset).Limit(limit)
+	err := query.Preload("Supplier").Find(&agreements).Error
+	return agreements, total, err
+}
+
+// Update saves header fields only; items are replaced through ReplaceItems
+func (r *purchaseAgreementRepository) Update(agreement *models.PurchaseAgreement) error {
+	return r.db.Omit("Supplier", "ApprovedByUser", "Items").Save(agreement).Error
+}
+
+func (r *purchaseAgreementRepository) ReplaceItems(agreementID uint, items []*models.PurchaseAgreementItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("agreement_id = ?", agreementID).Delete(&models.PurchaseAgreementItem{}).Error; err != nil {
+			return err
+		}
+		for _, item := range items {
+			item.AgreementID = agreementID
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Omit("Material").Create(&items).Error
+	})
+}
+
+func (r *purchaseAgreementRepository) UpdateStatus(id uint, status string, updatedBy uint) error {
+	return r.db.Model(&models.PurchaseAgreement{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "updated_by": updatedBy}).Error
+}
+
+func (r *purchaseAgreementRepository) CountByAgreementNumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PurchaseAgreement{}).
+		Where("agreement_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
+
+// GetConsumption sums call-off PO lines per material, ignoring cancelled POs and the PO
+// excludePOID (0 for none). Lines closed short consume only what was received.
+func (r *purchaseAgreementRepository) GetConsumption(agreementID uint, excludePOID uint) ([]AgreementConsumptionRow, error) {
+	var rows []AgreementConsumptionRow
+	err := r.db.Raw(`
+		SELECT poi.material_id,
+		       COALESCE(SUM(CASE WHEN poi.closed_short THEN poi.received_quantity ELSE poi.quantity END), 0) AS quantity,
+		       COALESCE(SUM(poi.received_quantity), 0) AS received_quantity,
+		       COALESCE(SUM(CASE WHEN poi.closed_short THEN poi.received_quantity ELSE poi.quantity END * poi.unit_price), 0) AS value
+		FROM purchase_order_items poi
+		JOIN purchase_orders po ON po.id = poi.purchase_order_id
+		WHERE po.agreement_id = ? AND po.status <> 'cancelled' AND po.id <> ?
+		GROUP BY poi.material_id`, agreementID, excludePOID).Scan(&rows).Error
+	return rows, err
+}
+
+// CountOpenCallOffs counts call-off POs that are not cancelled
+func (r *purchaseAgreementRepository) CountOpenCallOffs(agreementID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PurchaseOrder{}).
+		Where("agreement_id = ? AND status <> 'cancelled'", agreementID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// PurchaseOrderPaymentRepository defines PO payment data operations
+type PurchaseOrderPaymentRepository interface {
+	Create(payment *models.PurchaseOrderPayment) error
+	ListByPO(poID uint) ([]*models.PurchaseOrderPayment, error)
+	SumByPO(poID uint) (amount float64, baseAmount float64, err error)
+}
+
+type purchaseOrderPaymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseOrderPaymentRepository(db *gorm.DB) PurchaseOrderPaymentRepository {
+	return &purchaseOrderPaymentRepository{db: db}
+}
+
+func (r *purchaseOrderPaymentRepository) Create(payment *models.PurchaseOrderPayment) error {
+	return r.db.Create(payment).Error
+}
+
+func (r *purchaseOrderPaymentRepository) ListByPO(poID uint) ([]*models.PurchaseOrderPayment, error) {
+	var payments []*models.PurchaseOrderPayment
+	err := r.db.Preload("CreatedByUser").
+		Where("purchase_order_id = ?", poID).
+		Order("payment_date, id").Find(&payments).Error
+	return payments, err
+}
+
+func (r *purchaseOrderPaymentRepository) SumByPO(poID uint) (float64, float64, error) {
+	var row struct {
+		Amount     float64
+		BaseAmount float64
+	}
+	err := r.db.Model(&models.PurchaseOrderPayment{}).
+		Select("COALESCE(SUM(amount), 0) AS amount, COALESCE(SUM(base_amount), 0) AS base_amount").
+		Where("purchase_order_id = ?", poID).Scan(&row).Error
+	return row.Amount, row.BaseAmount, err
+}
@@ -21,7 +21,8 @@ type PurchaseOrderRepository interface {
 	CalculateTotals(poID uint) error
 	UpdateWorkflowStatus(id uint, field string, value string, notes string, updatedBy uint) error
 	UpdateInvoiceInfo(id uint, invoiceStatus string, invoiceNumber string, invoiceDate string, updatedBy uint) error
-	CompleteIfFullyReceived(id uint, updatedBy uint) error
+	UpdateReceiptStatus(id uint, receiptStatus string, fulfilled bool, updatedBy uint) error
+	CloseShortItems(poID uint, itemIDs []uint, reason string, closedBy uint, closedAt time.Time) error
 	Assign(id uint, assignedTo *uint, updatedBy uint) error
 }
 
@@ -126,6 +127,16 @@ func (r *purchaseOrderRepository) List(filter *dto.PurchaseOrderFilterRequest) (
 		query = query.Where("assigned_to = ?", *filter.AssignedTo)
 	}
 
+	// Apply agreement filter (call-off orders)
+	if filter.AgreementID != nil {
+		query = query.Where("agreement_id = ?", *filter.AgreementID)
+	}
+
+	// Apply source filter (replenishment drafts)
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+
 	if filter.OrderDateFrom != "" {
 		query = query.Where("order_date >= ?", filter.OrderDateFrom)
 	}
@@ -273,31 +284,38 @@ func (r *purchaseOrderRepository) UpdateInvoiceInfo(id uint, invoiceStatus strin
 	return r.db.Model(&models.PurchaseOrder{}).Where("id = ?", id).Updates(updates).Error
 }
 
-// CompleteIfFullyReceived checks if all PO items are fully received and marks PO as completed
-func (r *purchaseOrderRepository) CompleteIfFullyReceived(id uint, updatedBy uint) error {
+// UpdateReceiptStatus stores the receipt status; a fulfilled approved PO is completed and
+// a completed PO that is no longer fulfilled (e.g. goods returned for replacement) is reopened
+func (r *purchaseOrderRepository) UpdateReceiptStatus(id uint, receiptStatus string, fulfilled bool, updatedBy uint) error {
 	var po models.PurchaseOrder
-	if err := r.db.Preload("Items").First(&po, id).Error; err != nil {
+	if err := r.db.Select("id", "status").First(&po, id).Error; err != nil {
 		return err
 	}
-	if po.Status != "approved" {
-		return nil // Only auto-complete approved POs
+	updates := map[string]interface{}{
+		"receipt_status": receiptStatus,
+		"updated_by":     updatedBy,
 	}
-	allReceived := true
-	for _, item := range po.Items {
-		if item.ReceivedQuantity < item.Quantity {
-			allReceived = false
-			break
-		}
+	if fulfilled && po.Status == "approved" {
+		updates["status"] = "completed"
+	} else if !fulfilled && po.Status == "completed" {
+		updates["status"] = "approved"
 	}
-	if allReceived && len(po.Items) > 0 {
-		return r.db.Model(&models.PurchaseOrder{}).Where("id = ?", id).Updates(map[string]interface{}{
-			"status":         "completed",
-			"receipt_status": "completed",
-			"updated_by":     updatedBy,
+	return r.db.Model(&models.PurchaseOrder{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// CloseShortItems marks PO lines as closed short (no further deliveries expected)
+func (r *purchaseOrderRepository) CloseShortItems(poID uint, itemIDs []uint, reason string, closedBy uint, closedAt time.Time) error {
+	return r.db.Model(&models.PurchaseOrderItem{}).
+		Where("purchase_order_id = ? AND id IN ?", poID, itemIDs).
+		Updates(map[string]interface{}{
+			"closed_short":       true,
+			"closed_short_at":    closedAt,
+			"closed_short_by":    closedBy,
+			"close_short_reason": reason,
+			"updated_by":         closedBy,
 		}).Error
-	}
-	return nil
 }
+
 // Assign sets (or clears) the person responsible for a PO
 func (r *purchaseOrderRepository) Assign(id uint, assignedTo *uint, updatedBy uint) error {
 	updates := map[string]interface{}{
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// PurchaseReturnRepository defines purchase return (trả hàng NCC) data operations
+type PurchaseReturnRepository interface {
+	Create(ret *models.PurchaseReturn) error
+	GetByID(id uint) (*models.PurchaseReturn, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.PurchaseReturn, int64, error)
+	Update(ret *models.PurchaseReturn) error
+	UpdateItem(item *models.PurchaseReturnItem) error
+	CountByReturnNumber(prefix string) (int64, error)
+	CountByDebitNoteNumber(prefix string) (int64, error)
+	SumReturnedByGRNItem(grnItemID uint) (float64, error)
+	FindSourceGRNItem(supplierID uint, purchaseOrderID *uint, materialID uint, batchNumber, lotNumber string) (*models.GoodsReceiptNoteItem, error)
+}
+
+type purchaseReturnRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseReturnRepository(db *gorm.DB) PurchaseReturnRepository {
+	return &purchaseReturnRepository{db: db}
+}
+
+func (r *purchaseReturnRepository) Create(ret *models.PurchaseReturn) error {
+	return r.db.Omit("Items.Material", "Items.WarehouseLocation").Create(ret).Error
+}
+
+func (r *purchaseReturnRepository) GetByID(id uint) (*models.PurchaseReturn, error) {
+	var ret models.PurchaseReturn
+	err := r.db.
+		Preload("Supplier").
+		Preload("PurchaseOrder").
+		Preload("GRN").
+		Preload("Warehouse").
+		Preload("PostedByUser").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Material").
+		Preload("Items.WarehouseLocation").
+		First(&ret, id).Error
+	return &ret, err
+}
+
+func (r *purchaseReturnRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.PurchaseReturn, int64, error) {
+	var returns []*models.PurchaseReturn
+	var total int64
+
+	query := r.db.Model(&models.PurchaseReturn{})
+
+	if search, ok := filters["search"].(string); ok && search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("unaccent(return_number) ILIKE unaccent(?) OR unaccent(debit_note_number) ILIKE unaccent(?) OR unaccent(reason) ILIKE unaccent(?)", pattern, pattern, pattern)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if source, ok := filters["source"].(string); ok && source != "" {
+		query = query.Where("source = ?", source)
+	}
+	if replacement, ok := filters["replacement_status"].(string); ok && replacement != "" {
+		query = query.Where("replacement_status = ?", replacement)
+	}
+	if supplierID, ok := filters["supplier_id"].(uint); ok && supplierID > 0 {
+		query = query.Where("supplier_id = ?", supplierID)
+	}
+	if poID, ok := filters["purchase_order_id"].(uint); ok && poID > 0 {
+		query = query.Where("purchase_order_id = ?", poID)
+	}
+	if dateFrom, ok := filters["date_from"].(string); ok && dateFrom != "" {
+		query = query.Where("return_date >= ?", dateFrom)
+	}
+	if dateTo, ok := filters["date_to"].(string); ok && dateTo != "" {
+		query = query.Where("return_date <= ?", dateTo)
+	}
+
+	query.Count(&total)
+	query = query.Order("return_date DESC, id DESC").Offset(offset).Limit(limit)
+	err := query.Preload("Supplier").Preload("Warehouse").Find(&returns).Error
+	return returns, total, err
+}
+
+// Update saves header fields only
+func (r *purchaseReturnRepository) Update(ret *models.PurchaseReturn) error {
+	return r.db.Omit("Supplier", "PurchaseOrder", "GRN", "Warehouse", "PostedByUser", "Items").Save(ret).Error
+}
+
+func (r *purchaseReturnRepository) UpdateItem(item *models.PurchaseReturnItem) error {
+	return r.db.Omit("Material", "WarehouseLocation").Save(item).Error
+}
+
+func (r *purchaseReturnRepository) CountByReturnNumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PurchaseReturn{}).
+		Where("return_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
+
+func (r *purchaseReturnRepository) CountByDebitNoteNumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PurchaseReturn{}).
+		Where("debit_note_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
+
+// SumReturnedByGRNItem sums quantities already returned against a GRN line, ignoring cancelled returns
+func (r *purchaseReturnRepository) SumReturnedByGRNItem(grnItemID uint) (float64, error) {
+	var total float64
+	err := r.db.Model(&models.PurchaseReturnItem{}).
+		Joins("JOIN purchase_returns pr ON pr.id = purchase_return_items.purchase_return_id").
+		Where("purchase_return_items.grn_item_id = ? AND pr.status <> 'cancelled'", grnItemID).
+		Select("COALESCE(SUM(purchase_return_items.quantity), 0)").Scan(&total).Error
+	return total, err
+}
+
+// FindSourceGRNItem finds the latest posted GRN line from the supplier that received the batch
+func (r *purchaseReturnRepository) FindSourceGRNItem(supplierID uint, purchaseOrderID *uint, materialID uint, batchNumber, lotNumber string) (*models.GoodsReceiptNoteItem, error) {
+	var item models.GoodsReceiptNoteItem
+	query := r.db.Model(&models.GoodsReceiptNoteItem{}).
+		Joins("JOIN goods_receipt_notes g ON g.id = goods_receipt_note_items.grn_id").
+		Joins("JOIN purchase_orders po ON po.id = g.purchase_order_id").
+		Where("g.posted = true AND po.supplier_id = ? AND goods_receipt_note_items.material_id = ?", supplierID, materialID).
+		Where("COALESCE(goods_receipt_note_items.batch_number, '') = ? AND COALESCE(goods_receipt_note_items.lot_number, '') = ?", batchNumber, lotNumber)
+	if purchaseOrderID != nil {
+		query = query.Where("g.purchase_order_id = ?", *purchaseOrderID)
+	}
+	err := query.Order("g.receipt_date DESC, goods_receipt_note_items.id DESC").First(&item).Error
+	return &item, err
+}
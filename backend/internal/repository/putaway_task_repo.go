@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// LocationOccupancy is the stock currently held in a location, with the part matching the item being put away
+type LocationOccupancy struct {
+	LocationID   uint    `gorm:"column:location_id"`
+	Quantity     float64 `gorm:"column:quantity"`
+	ItemQuantity float64 `gorm:"column:item_quantity"` // same item, any batch
+	SameBatch    bool    `gorm:"column:same_batch"`
+}
+
+// PutawayTaskRepository defines data operations for putaway tasks and the data used to suggest locations
+type PutawayTaskRepository interface {
+	Create(task *models.PutawayTask) error
+	GetByID(id uint) (*models.PutawayTask, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.PutawayTask, int64, error)
+	Update(task *models.PutawayTask) error
+	CreateConfirmation(c *models.PutawayConfirmation) error
+	CountByTaskNumber(prefix string) (int64, error)
+
+	GetLocation(id uint) (*models.WarehouseLocation, error)
+	ListStorageLocations(warehouseID uint) ([]*models.WarehouseLocation, error)
+	LocationOccupancy(warehouseID uint, itemType string, itemID uint, batch string) ([]LocationOccupancy, error)
+	IsHazardous(itemType string, itemID uint) (bool, error)
+}
+
+type putawayTaskRepository struct {
+	db *gorm.DB
+}
+
+func NewPutawayTaskRepository(db *gorm.DB) PutawayTaskRepository {
+	return &putawayTaskRepository{db: db}
+}
+
+func (r *putawayTaskRepository) Create(task *models.PutawayTask) error {
+	return r.db.Omit("Warehouse", "FromLocation", "SuggestedLocation", "Confirmations").Create(task).Error
+}
+
+func (r *putawayTaskRepository) GetByID(id uint) (*models.PutawayTask, error) {
+	var task models.PutawayTask
+	err := r.db.Preload("Warehouse").Preload("FromLocation").Preload("SuggestedLocation").
+		Preload("Confirmations", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Confirmations.ToLocation").
+		First(&task, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (r *putawayTaskRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.PutawayTask, int64, error) {
+	var tasks []*models.PutawayTask
+	var total int64
+
+	query := r.db.Model(&models.PutawayTask{})
+	if warehouseID, ok := filters["warehouse_id"].(uint); ok && warehouseID > 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	} else if open, ok := filters["open"].(bool); ok && open {
+		query = query.Where("status IN ?", []string{"pending", "in_progress"})
+	}
+	if sourceType, ok := filters["source_type"].(string); ok && sourceType != "" {
+		query = query.Where("source_type = ?", sourceType)
+	}
+	if sourceID, ok := filters["source_id"].(uint); ok && sourceID > 0 {
+		query = query.Where("source_id = ?", sourceID)
+	}
+	if itemType, ok := filters["item_type"].(string); ok && itemType != "" {
+		query = query.Where("item_type = ?", itemType)
+	}
+	if itemID, ok := filters["item_id"].(uint); ok && itemID > 0 {
+		query = query.Where("item_id = ?", itemID)
+	}
+
+	query.Count(&total)
+	err := query.Order("created_at ASC, id ASC").Offset(offset).Limit(limit).
+		Preload("FromLocation").Preload("SuggestedLocation").Find(&tasks).Error
+	return tasks, total, err
+}
+
+func (r *putawayTaskRepository) Update(task *models.PutawayTask) error {
+	return r.db.Omit("Warehouse", "FromLocation", "SuggestedLocation", "Confirmations").Save(task).Error
+}
+
+func (r *putawayTaskRepository) CreateConfirmation(c *models.PutawayConfirmation) error {
+	return r.db.Omit("ToLocation").Create(c).Error
+}
+
+func (r *putawayTaskRepository) CountByTaskNumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PutawayTask{}).Where("task_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
+
+func (r *putawayTaskRepository) GetLocation(id uint) (*models.WarehouseLocation, error) {
+	var location models.WarehouseLocation
+	if err := r.db.First(&location, id).Error; err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// ListStorageLocations returns the active locations of a warehouse that can receive putaway
+func (r *putawayTaskRepository) ListStorageLocations(warehouseID uint) ([]*models.WarehouseLocation, error) {
+	var locations []*models.WarehouseLocation
+	err := r.db.Preload("Zone").Where("warehouse_id = ? AND COALESCE(is_active, TRUE)", warehouseID).
+		Where("COALESCE(location_type, 'storage') NOT IN ?", []string{"receiving", "shipping", "quarantine", "in_transit"}).
+		Order("code").Find(&locations).Error
+	return locations, err
+}
+
+// LocationOccupancy sums the stock per location of a warehouse, flagging what the item (and batch) already holds there
+func (r *putawayTaskRepository) LocationOccupancy(warehouseID uint, itemType string, itemID uint, batch string) ([]LocationOccupancy, error) {
+	var rows []LocationOccupancy
+	err := r.db.Model(&models.StockBalance{}).
+		Select(`warehouse_location_id AS location_id,
+			SUM(quantity) AS quantity,
+			SUM(CASE WHEN item_type = ? AND item_id = ? THEN quantity ELSE 0 END) AS item_quantity,
+			BOOL_OR(item_type = ? AND item_id = ? AND ? <> '' AND batch_number = ?) AS same_batch`,
+			itemType, itemID, itemType, itemID, batch, batch).
+		Where("warehouse_id = ? AND warehouse_location_id IS NOT NULL AND quantity > 0", warehouseID).
+		Group("warehouse_location_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// IsHazardous reports the hazardous flag of a material; finished products are not classified
+func (r *putawayTaskRepository) IsHazardous(itemType string, itemID uint) (bool, error) {
+	if itemType != "material" {
+		return false, nil
+	}
+	var hazardous bool
+	err := r.db.Model(&models.Material{}).Where("id = ?", itemID).Select("COALESCE(hazardous, FALSE)").Scan(&hazardous).Error
+	return hazardous, err
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// QCInspectionRepository defines data operations for incoming QC templates, inspections and retained samples
+type QCInspectionRepository interface {
+	CreateTemplate(t *models.QCTemplate) error
+	GetTemplateByID(id uint) (*models.QCTemplate, error)
+	GetActiveTemplate(materialID uint) (*models.QCTemplate, error)
+	ListTemplates(filters map[string]interface{}, offset, limit int) ([]*models.QCTemplate, int64, error)
+	UpdateTemplate(t *models.QCTemplate) error
+	ReplaceTemplateTests(templateID uint, tests []*models.QCTemplateTest) error
+	DeactivateTemplates(materialID uint, exceptID uint) error
+	MaxTemplateVersion(materialID uint) (int, error)
+
+	CreateInspection(inspection *models.QCInspection) error
+	GetInspectionByID(id uint) (*models.QCInspection, error)
+	ListInspections(filters map[string]interface{}, offset, limit int) ([]*models.QCInspection, int64, error)
+	UpdateInspection(inspection *models.QCInspection) error
+	UpdateResult(result *models.QCInspectionResult) error
+	CountByInspectionNumber(prefix string) (int64, error)
+	LatestCompletedByGRNItem(grnItemID uint) (*models.QCInspection, error)
+
+	CreateRetainedSample(sample *models.QCRetainedSample) error
+	GetRetainedSampleByID(id uint) (*models.QCRetainedSample, error)
+	ListRetainedSamples(filters map[string]interface{}, offset, limit int) ([]*models.QCRetainedSample, int64, error)
+	UpdateRetainedSample(sample *models.QCRetainedSample) error
+}
+
+type qcInspectionRepository struct {
+	db *gorm.DB
+}
+
+func NewQCInspectionRepository(db *gorm.DB) QCInspectionRepository {
+	return &qcInspectionRepository{db: db}
+}
+
+func orderTests(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order, id")
+}
+
+func (r *qcInspectionRepository) CreateTemplate(t *models.QCTemplate) error {
+	return r.db.Omit("Material").Create(t).Error
+}
+
+func (r *qcInspectionRepository) GetTemplateByID(id uint) (*models.QCTemplate, error) {
+	var t models.QCTemplate
+	err := r.db.Preload("Material").Preload("Tests", orderTests).First(&t, id).Error
+	return &t, err
+}
+
+func (r *qcInspectionRepository) GetActiveTemplate(materialID uint) (*models.QCTemplate, error) {
+	var t models.QCTemplate
+	err := r.db.Preload("Material").Preload("Tests", orderTests).
+		Where("material_id = ? AND is_active = true", materialID).First(&t).Error
+	return &t, err
+}
+
+func (r *qcInspectionRepository) ListTemplates(filters map[string]interface{}, offset, limit int) ([]*models.QCTemplate, int64, error) {
+	var templates []*models.QCTemplate
+	var total int64
+
+	query := r.db.Model(&models.QCTemplate{})
+	if search, ok := filters["search"].(string); ok && search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("unaccent(code) ILIKE unaccent(?) OR unaccent(name) ILIKE unaccent(?)", pattern, pattern)
+	}
+	if materialID, ok := filters["material_id"].(uint); ok && materialID > 0 {
+		query = query.Where("material_id = ?", materialID)
+	}
+	if isActive, ok := filters["is_active"].(bool); ok {
+		query = query.Where("is_active = ?", isActive)
+	}
+
+	query.Count(&total)
+	err := query.Order("material_id, version DESC").Offset(offset).Limit(limit).
+		Preload("Material").Preload("Tests", orderTests).Find(&templates).Error
+	return templates, total, err
+}
+
+// UpdateTemplate saves header fields only
+func (r *qcInspectionRepository) UpdateTemplate(t *models.QCTemplate) error {
+	return r.db.Omit("Material", "Tests").Save(t).Error
+}
+
+func (r *qcInspectionRepository) ReplaceTemplateTests(templateID uint, tests []*models.QCTemplateTest) error {
+	if err := r.db.Where("template_id = ?", templateID).Delete(&models.QCTemplateTest{}).Error; err != nil {
+		return err
+	}
+	for _, test := range tests {
+		test.ID = 0
+		test.TemplateID = templateID
+	}
+	return r.db.Create(&tests).Error
+}
+
+// DeactivateTemplates deactivates the material's active templates other than exceptID
+func (r *qcInspectionRepository) DeactivateTemplates(materialID uint, exceptID uint) error {
+	return r.db.Model(&models.QCTemplate{}).
+		Where("material_id = ? AND id <> ? AND is_active = true", materialID, exceptID).
+		Update("is_active", false).Error
+}
+
+func (r *qcInspectionRepository) MaxTemplateVersion(materialID uint) (int, error) {
+	var version int
+	err := r.db.Model(&models.QCTemplate{}).Where("material_id = ?", materialID).
+		Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+func (r *qcInspectionRepository) CreateInspection(inspection *models.QCInspection) error {
+	return r.db.Omit("GRN", "GRNItem", "Material", "Template", "InspectedByUser", "RetainedSamples").Create(inspection).Error
+}
+
+func (r *qcInspectionRepository) GetInspectionByID(id uint) (*models.QCInspection, error) {
+	var inspection models.QCInspection
+	err := r.db.
+		Preload("GRN").
+		Preload("GRN.PurchaseOrder").
+		Preload("GRN.PurchaseOrder.Supplier").
+		Preload("GRNItem").
+		Preload("Material").
+		Preload("Template").
+		Preload("InspectedByUser").
+		Preload("Results", orderTests).
+		Preload("RetainedSamples").
+		First(&inspection, id).Error
+	return &inspection, err
+}
+
+func (r *qcInspectionRepository) ListInspections(filters map[string]interface{}, offset, limit int) ([]*models.QCInspection, int64, error) {
+	var inspections []*models.QCInspection
+	var total int64
+
+	query := r.db.Model(&models.QCInspection{})
+	if search, ok := filters["search"].(string); ok && search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("inspection_number ILIKE ? OR batch_number ILIKE ? OR lot_number ILIKE ?", pattern, pattern, pattern)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if result, ok := filters["result"].(string); ok && result != "" {
+		query = query.Where("result = ?", result)
+	}
+	if grnID, ok := filters["grn_id"].(uint); ok && grnID > 0 {
+		query = query.Where("grn_id = ?", grnID)
+	}
+	if materialID, ok := filters["material_id"].(uint); ok && materialID > 0 {
+		query = query.Where("material_id = ?", materialID)
+	}
+	if batch, ok := filters["batch_number"].(string); ok && batch != "" {
+		query = query.Where("batch_number = ?", batch)
+	}
+
+	query.Count(&total)
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).
+		Preload("GRN").Preload("Material").Find(&inspections).Error
+	return inspections, total, err
+}
+
+// UpdateInspection saves header fields only
+func (r *qcInspectionRepository) UpdateInspection(inspection *models.QCInspection) error {
+	return r.db.Omit("GRN", "GRNItem", "Material", "Template", "InspectedByUser", "Results", "RetainedSamples").Save(inspection).Error
+}
+
+func (r *qcInspectionRepository) UpdateResult(result *models.QCInspectionResult) error {
+	return r.db.Save(result).Error
+}
+
+func (r *qcInspectionRepository) CountByInspectionNumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.QCInspection{}).
+		Where("inspection_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
+
+// LatestCompletedByGRNItem returns the most recent completed inspection of a GRN line
+func (r *qcInspectionRepository) LatestCompletedByGRNItem(grnItemID uint) (*models.QCInspection, error) {
+	var inspection models.QCInspection
+	err := r.db.Where("grn_item_id = ? AND status = 'completed'", grnItemID).
+		Order("inspected_at DESC, id DESC").First(&inspection).Error
+	return &inspection, err
+}
+
+func (r *qcInspectionRepository) CreateRetainedSample(sample *models.QCRetainedSample) error {
+	return r.db.Omit("Material").Create(sample).Error
+}
+
+func (r *qcInspectionRepository) GetRetainedSampleByID(id uint) (*models.QCRetainedSample, error) {
+	var sample models.QCRetainedSample
+	err := r.db.Preload("Material").First(&sample, id).Error
+	return &sample, err
+}
+
+func (r *qcInspectionRepository) ListRetainedSamples(filters map[string]interface{}, offset, limit int) ([]*models.QCRetainedSample, int64, error) {
+	var samples []*models.QCRetainedSample
+	var total int64
+
+	query := r.db.Model(&models.QCRetainedSample{})
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if materialID, ok := filters["material_id"].(uint); ok && materialID > 0 {
+		query = query.Where("material_id = ?", materialID)
+	}
+	if batch, ok := filters["batch_number"].(string); ok && batch != "" {
+		query = query.Where("batch_number = ?", batch)
+	}
+	// Samples due for disposal on or before the given date
+	if dueBy, ok := filters["due_by"].(string); ok && dueBy != "" {
+		query = query.Where("status = 'retained' AND retain_until <= ?", dueBy)
+	}
+
+	query.Count(&total)
+	err := query.Order("retain_until NULLS LAST, id").Offset(offset).Limit(limit).
+		Preload("Material").Find(&samples).Error
+	return samples, total, err
+}
+
+func (r *qcInspectionRepository) UpdateRetainedSample(sample *models.QCRetainedSample) error {
+	return r.db.Omit("Material").Save(sample).Error
+}
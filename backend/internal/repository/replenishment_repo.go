@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ReplenishmentPositionRow is a material's stock position in one warehouse against its reorder settings
+type ReplenishmentPositionRow struct {
+	MaterialID      uint
+	MaterialCode    string
+	MaterialName    string
+	Unit            string
+	MinStockLevel   float64
+	MaxStockLevel   float64
+	ReorderPoint    float64
+	ReorderQuantity float64
+	OnHand          float64
+	Reserved        float64
+	OnOrder         float64 // open PO quantity not yet received
+}
+
+// ReplenishmentRepository reads stock positions and supplier ordering rules for replenishment
+type ReplenishmentRepository interface {
+	ListPositions(warehouseID uint, materialIDs []uint) ([]ReplenishmentPositionRow, error)
+	ListMaterialSuppliers(materialIDs []uint) ([]models.MaterialSupplier, error)
+	CountByPONumber(prefix string) (int64, error)
+}
+
+type replenishmentRepository struct {
+	db *gorm.DB
+}
+
+func NewReplenishmentRepository(db *gorm.DB) ReplenishmentRepository {
+	return &replenishmentRepository{db: db}
+}
+
+// ListPositions returns active materials with a reorder point, with on-hand, reserved and
+// on-order quantities for the warehouse. Draft and approved POs count as on order.
+func (r *replenishmentRepository) ListPositions(warehouseID uint, materialIDs []uint) ([]ReplenishmentPositionRow, error) {
+	var rows []ReplenishmentPositionRow
+	query := r.db.Table("materials m").
+		Select(`
+			m.id AS material_id,
+			m.code AS material_code,
+			m.trading_name AS material_name,
+			m.unit,
+			COALESCE(m.min_stock_level, 0) AS min_stock_level,
+			COALESCE(m.max_stock_level, 0) AS max_stock_level,
+			COALESCE(m.reorder_point, 0) AS reorder_point,
+			COALESCE(m.reorder_quantity, 0) AS reorder_quantity,
+			COALESCE(sb.on_hand, 0) AS on_hand,
+			COALESCE(sb.reserved, 0) AS reserved,
+			COALESCE(oo.on_order, 0) AS on_order`).
+		Joins(`LEFT JOIN (
+			SELECT item_id, SUM(quantity) AS on_hand, SUM(reserved_quantity) AS reserved
+			FROM stock_balance
+			WHERE item_type = 'material' AND warehouse_id = ?
+			GROUP BY item_id
+		) sb ON sb.item_id = m.id`, warehouseID).
+		Joins(`LEFT JOIN (
+			SELECT poi.material_id, SUM(GREATEST(poi.quantity - poi.received_quantity, 0)) AS on_order
+			FROM purchase_order_items poi
+			JOIN purchase_orders po ON po.id = poi.purchase_order_id
+			WHERE po.warehouse_id = ? AND po.status NOT IN ('completed', 'cancelled') AND NOT poi.closed_short
+			GROUP BY poi.material_id
+		) oo ON oo.material_id = m.id`, warehouseID).
+		Where("m.is_active = ? AND COALESCE(m.reorder_point, 0) > 0", true)
+	if len(materialIDs) > 0 {
+		query = query.Where("m.id IN ?", materialIDs)
+	}
+	err := query.Order("m.code").Scan(&rows).Error
+	return rows, err
+}
+
+// ListMaterialSuppliers returns supplier links ordered by priority (1 = preferred)
+func (r *replenishmentRepository) ListMaterialSuppliers(materialIDs []uint) ([]models.MaterialSupplier, error) {
+	var links []models.MaterialSupplier
+	if len(materialIDs) == 0 {
+		return links, nil
+	}
+	err := r.db.Preload("Supplier").
+		Where("material_id IN ?", materialIDs).
+		Order("material_id, priority, id").
+		Find(&links).Error
+	return links, err
+}
+
+func (r *replenishmentRepository) CountByPONumber(prefix string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PurchaseOrder{}).
+		Where("po_number LIKE ?", prefix+"%").Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ScannedDocument is a warehouse document found by its number
+type ScannedDocument struct {
+	DocumentType string
+	ID           uint   `gorm:"column:id"`
+	Number       string `gorm:"column:number"`
+	Status       string `gorm:"column:status"`
+}
+
+// scanDocumentSources are the documents whose numbers are printed as barcodes, in lookup order
+var scanDocumentSources = []struct {
+	DocumentType string
+	Table        string
+	Column       string
+}{
+	{"purchase_order", "purchase_orders", "po_number"},
+	{"grn", "goods_receipt_notes", "grn_number"},
+	{"putaway_task", "putaway_tasks", "task_number"},
+	{"delivery_order", "delivery_orders", "do_number"},
+	{"pick_list", "pick_lists", "pick_number"},
+	{"pick_wave", "pick_waves", "wave_number"},
+	{"material_issue_note", "material_issue_notes", "min_number"},
+	{"finished_product_receipt", "finished_product_receipts", "fprn_number"},
+	{"stock_transfer", "stock_transfers", "transfer_number"},
+	{"stock_adjustment", "stock_adjustments", "adjustment_number"},
+	{"cycle_count_task", "cycle_count_tasks", "task_number"},
+	{"physical_inventory", "physical_inventories", "inventory_number"},
+	{"purchase_return", "purchase_returns", "return_number"},
+	{"return_order", "return_orders", "return_number"},
+	{"qc_inspection", "qc_inspections", "inspection_number"},
+	{"production_plan", "production_plans", "plan_number"},
+}
+
+// ScanRepository looks up what a scanned code refers to
+type ScanRepository interface {
+	FinishedProductsByBarcode(barcodes []string) ([]*models.FinishedProduct, error)
+	FinishedProductByCode(code string) (*models.FinishedProduct, error)
+	MaterialByCode(code string) (*models.Material, error)
+	LocationByCode(code string) (*models.WarehouseLocation, error)
+	// FindDocument returns the document with the given number, or nil
+	FindDocument(number string) (*ScannedDocument, error)
+	// Balances returns the non-zero stock of an item in a warehouse, limited to a batch when given
+	Balances(itemType string, itemID, warehouseID uint, batch string) ([]*models.StockBalance, error)
+}
+
+type scanRepository struct {
+	db *gorm.DB
+}
+
+func NewScanRepository(db *gorm.DB) ScanRepository {
+	return &scanRepository{db: db}
+}
+
+func (r *scanRepository) FinishedProductsByBarcode(barcodes []string) ([]*models.FinishedProduct, error) {
+	var products []*models.FinishedProduct
+	err := r.db.Where("barcode IN ?", barcodes).Order("id").Find(&products).Error
+	return products, err
+}
+
+func (r *scanRepository) FinishedProductByCode(code string) (*models.FinishedProduct, error) {
+	var products []*models.FinishedProduct
+	if err := r.db.Where("code = ? OR barcode = ?", code, code).Order("id").Limit(1).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, nil
+	}
+	return products[0], nil
+}
+
+func (r *scanRepository) MaterialByCode(code string) (*models.Material, error) {
+	var materials []*models.Material
+	if err := r.db.Where("code = ?", code).Limit(1).Find(&materials).Error; err != nil {
+		return nil, err
+	}
+	if len(materials) == 0 {
+		return nil, nil
+	}
+	return materials[0], nil
+}
+
+func (r *scanRepository) LocationByCode(code string) (*models.WarehouseLocation, error) {
+	var locations []*models.WarehouseLocation
+	if err := r.db.Where("code = ?", code).Limit(1).Find(&locations).Error; err != nil {
+		return nil, err
+	}
+	if len(locations) == 0 {
+		return nil, nil
+	}
+	return locations[0], nil
+}
+
+func (r *scanRepository) FindDocument(number string) (*ScannedDocument, error) {
+	for _, src := range scanDocumentSources {
+		var docs []ScannedDocument
+		err := r.db.Table(src.Table).
+			Select("id, "+src.Column+" AS number, status").
+			Where(src.Column+" = ?", number).
+			Limit(1).Scan(&docs).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(docs) > 0 {
+			docs[0].DocumentType = src.DocumentType
+			return &docs[0], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *scanRepository) Balances(itemType string, itemID, warehouseID uint, batch string) ([]*models.StockBalance, error) {
+	var balances []*models.StockBalance
+	query := r.db.Preload("WarehouseLocation").
+		Where("item_type = ? AND item_id = ? AND warehouse_id = ? AND quantity <> 0", itemType, itemID, warehouseID)
+	if batch != "" {
+		query = query.Where("batch_number = ?", batch)
+	}
+	err := query.Order("expiry_date ASC NULLS LAST, warehouse_location_id").Find(&balances).Error
+	return balances, err
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// SerialRepository defines data operations for the serial number register
+type SerialRepository interface {
+	GetByID(id uint) (*models.SerialNumber, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.SerialNumber, int64, error)
+	// FindByNumbers returns the registered serials of an item, keyed by serial number
+	FindByNumbers(itemType string, itemID uint, serials []string) (map[string]*models.SerialNumber, error)
+	// FindInItems returns the registered serials with the given numbers across several items
+	FindInItems(itemType string, itemIDs []uint, serials []string) ([]*models.SerialNumber, error)
+	Save(serial *models.SerialNumber) error
+	// Link records that a stock ledger entry moved the serial
+	Link(stockLedgerID, serialID uint) error
+	// History returns the stock ledger entries that moved the serial, oldest first
+	History(serialID uint) ([]*models.StockLedger, error)
+	// TrackedItems returns which of the items are serial-tracked
+	TrackedItems(itemType string, itemIDs []uint) (map[uint]bool, error)
+	// InStockAt returns the in-stock serials of an item, batch and lot at a location (nil: none)
+	InStockAt(itemType string, itemID, warehouseID uint, locationID *uint, batch, lot string) ([]*models.SerialNumber, error)
+}
+
+type serialRepository struct {
+	db *gorm.DB
+}
+
+func NewSerialRepository(db *gorm.DB) SerialRepository {
+	return &serialRepository{db: db}
+}
+
+func (r *serialRepository) GetByID(id uint) (*models.SerialNumber, error) {
+	var serial models.SerialNumber
+	err := r.db.Preload("Warehouse").Preload("WarehouseLocation").First(&serial, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &serial, nil
+}
+
+func (r *serialRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.SerialNumber, int64, error) {
+	var serials []*models.SerialNumber
+	var total int64
+
+	query := r.db.Model(&models.SerialNumber{})
+	if itemType, ok := filters["item_type"].(string); ok && itemType != "" {
+		query = query.Where("item_type = ?", itemType)
+	}
+	if itemID, ok := filters["item_id"].(uint); ok && itemID > 0 {
+		query = query.Where("item_id = ?", itemID)
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if warehouseID, ok := filters["warehouse_id"].(uint); ok && warehouseID > 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if search, ok := filters["search"].(string); ok && search != "" {
+		query = query.Where("serial_number ILIKE ?", "%"+search+"%")
+	}
+
+	query.Count(&total)
+	err := query.Order("updated_at DESC, id DESC").Offset(offset).Limit(limit).
+		Preload("Warehouse").
+		Preload("WarehouseLocation").
+		Find(&serials).Error
+	return serials, total, err
+}
+
+func (r *serialRepository) FindByNumbers(itemType string, itemID uint, serials []string) (map[string]*models.SerialNumber, error) {
+	found := make(map[string]*models.SerialNumber, len(serials))
+	if len(serials) == 0 {
+		return found, nil
+	}
+	var rows []*models.SerialNumber
+	err := r.db.Where("item_type = ? AND item_id = ? AND serial_number IN ?", itemType, itemID, serials).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		found[row.SerialNumber] = row
+	}
+	return found, nil
+}
+
+func (r *serialRepository) FindInItems(itemType string, itemIDs []uint, serials []string) ([]*models.SerialNumber, error) {
+	var rows []*models.SerialNumber
+	if len(itemIDs) == 0 || len(serials) == 0 {
+		return rows, nil
+	}
+	err := r.db.Where("item_type = ? AND item_id IN ? AND serial_number IN ?", itemType, itemIDs, serials).
+		Order("id").Find(&rows).Error
+	return rows, err
+}
+
+func (r *serialRepository) Save(serial *models.SerialNumber) error {
+	return r.db.Omit("Warehouse", "WarehouseLocation").Save(serial).Error
+}
+
+func (r *serialRepository) Link(stockLedgerID, serialID uint) error {
+	return r.db.Create(&models.StockLedgerSerial{StockLedgerID: stockLedgerID, SerialNumberID: serialID}).Error
+}
+
+func (r *serialRepository) History(serialID uint) ([]*models.StockLedger, error) {
+	var entries []*models.StockLedger
+	err := r.db.Joins("JOIN stock_ledger_serials sls ON sls.stock_ledger_id = stock_ledger.id").
+		Where("sls.serial_number_id = ?", serialID).
+		Preload("Warehouse").
+		Preload("WarehouseLocation").
+		Order("stock_ledger.transaction_date, stock_ledger.id").
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *serialRepository) TrackedItems(itemType string, itemIDs []uint) (map[uint]bool, error) {
+	tracked := make(map[uint]bool, len(itemIDs))
+	if len(itemIDs) == 0 {
+		return tracked, nil
+	}
+	table := "materials"
+	if itemType == "finished_product" {
+		table = "finished_products"
+	}
+	var ids []uint
+	if err := r.db.Table(table).Where("id IN ? AND serial_tracked", itemIDs).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		tracked[id] = true
+	}
+	return tracked, nil
+}
+
+func (r *serialRepository) InStockAt(itemType string, itemID, warehouseID uint, locationID *uint, batch, lot string) ([]*models.SerialNumber, error) {
+	var serials []*models.SerialNumber
+	err := r.db.Where("item_type = ? AND item_id = ? AND warehouse_id = ? AND status = ?", itemType, itemID, warehouseID, "in_stock").
+		Where("COALESCE(warehouse_location_id, 0) = COALESCE(?, 0)", locationID).
+		Where("COALESCE(batch_number, '') = ? AND COALESCE(lot_number, '') = ?", batch, lot).
+		Order("serial_number").
+		Find(&serials).Error
+	return serials, err
+}
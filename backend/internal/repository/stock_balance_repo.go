@@ -13,6 +13,8 @@ type StockBalanceRepository interface {
 	Update(balance *models.StockBalance) error
 	Upsert(balance *models.StockBalance) error
 	List(itemType string, itemID uint, warehouseID uint) ([]*models.StockBalance, error)
+	// ListBatch returns the balances of a batch and lot with stock on hand, at every location of the warehouse
+	ListBatch(itemType string, itemID uint, warehouseID uint, batch string, lot string) ([]*models.StockBalance, error)
 }
 
 type stockBalanceRepository struct {
@@ -80,3 +82,12 @@ func (r *stockBalanceRepository) List(itemType string, itemID uint, warehouseID
 	err := query.Order("expiry_date ASC NULLS LAST, created_at ASC").Find(&balances).Error
 	return balances, err
 }
+
+func (r *stockBalanceRepository) ListBatch(itemType string, itemID uint, warehouseID uint, batch string, lot string) ([]*models.StockBalance, error) {
+	var balances []*models.StockBalance
+	err := r.db.Where("item_type = ? AND item_id = ? AND warehouse_id = ?", itemType, itemID, warehouseID).
+		Where("COALESCE(batch_number, '') = ? AND COALESCE(lot_number, '') = ?", batch, lot).
+		Where("quantity > 0").
+		Order("id").Find(&balances).Error
+	return balances, err
+}
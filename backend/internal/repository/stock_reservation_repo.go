@@ -5,12 +5,44 @@ import (
 	"gorm.io/gorm"
 )
 
+// ReservationStockRow is the stock of one item in one warehouse: what is on hand outside transit,
+// in transit, in quarantine and reserved on the balances
+type ReservationStockRow struct {
+	ItemType      string  `gorm:"column:item_type"`
+	ItemID        uint    `gorm:"column:item_id"`
+	ItemCode      string  `gorm:"column:item_code"`
+	ItemName      string  `gorm:"column:item_name"`
+	WarehouseID   uint    `gorm:"column:warehouse_id"`
+	WarehouseName string  `gorm:"column:warehouse_name"`
+	OnHand        float64 `gorm:"column:on_hand"`
+	InTransit     float64 `gorm:"column:in_transit"`
+	Quarantined   float64 `gorm:"column:quarantined"`
+	Reserved      float64 `gorm:"column:reserved"`
+}
+
+// OpenReservationRow sums the quantity still held by the active reservations of one item in one warehouse
+type OpenReservationRow struct {
+	ItemType    string  `gorm:"column:item_type"`
+	ItemID      uint    `gorm:"column:item_id"`
+	WarehouseID uint    `gorm:"column:warehouse_id"`
+	Open        float64 `gorm:"column:open_quantity"`
+	Count       int     `gorm:"column:reservation_count"`
+}
+
 type StockReservationRepository interface {
 	Create(reservation *models.StockReservation) error
 	Update(reservation *models.StockReservation) error
 	GetByID(id uint) (*models.StockReservation, error)
 	ListByReference(refType string, refID uint) ([]*models.StockReservation, error)
 	CloseByReference(tx *gorm.DB, refType string, refID uint, status string) error
+	List(filters map[string]interface{}, offset, limit int) ([]*models.StockReservation, int64, error)
+	// ReservableBalances returns the unexpired stock of an item that is not yet reserved, outside
+	// quarantine and transit, FEFO first
+	ReservableBalances(itemType string, itemID, warehouseID uint) ([]*models.StockBalance, error)
+	// ActiveForReferences returns the active reservations of the given references of one type
+	ActiveForReferences(refType string, refIDs []uint) ([]*models.StockReservation, error)
+	StockRows(filters map[string]interface{}) ([]ReservationStockRow, error)
+	OpenRows(filters map[string]interface{}) ([]OpenReservationRow, error)
 }
 
 type stockReservationRepository struct {
@@ -22,16 +54,16 @@ func NewStockReservationRepository(db *gorm.DB) StockReservationRepository {
 }
 
 func (r *stockReservationRepository) Create(reservation *models.StockReservation) error {
-	return r.db.Create(reservation).Error
+	return r.db.Omit("Warehouse", "WarehouseLocation").Create(reservation).Error
 }
 
 func (r *stockReservationRepository) Update(reservation *models.StockReservation) error {
-	return r.db.Save(reservation).Error
+	return r.db.Omit("Warehouse", "WarehouseLocation").Save(reservation).Error
 }
 
 func (r *stockReservationRepository) GetByID(id uint) (*models.StockReservation, error) {
 	var res models.StockReservation
-	if err := r.db.First(&res, id).Error; err != nil {
+	if err := r.db.Preload("Warehouse").Preload("WarehouseLocation").First(&res, id).Error; err != nil {
 		return nil, err
 	}
 	return &res, nil
@@ -52,3 +84,107 @@ func (r *stockReservationRepository) CloseByReference(tx *gorm.DB, refType strin
 		Where("reference_type = ? AND reference_id = ? AND status = 'active'", refType, refID).
 		Update("status", status).Error
 }
+
+func (r *stockReservationRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.StockReservation, int64, error) {
+	var res []*models.StockReservation
+	var total int64
+
+	query := r.db.Model(&models.StockReservation{})
+	for _, key := range []string{"item_type", "reference_type", "status"} {
+		if v, ok := filters[key].(string); ok && v != "" {
+			query = query.Where(key+" = ?", v)
+		}
+	}
+	for _, key := range []string{"item_id", "warehouse_id", "reference_id", "warehouse_location_id"} {
+		if v, ok := filters[key].(uint); ok && v > 0 {
+			query = query.Where(key+" = ?", v)
+		}
+	}
+	if batch, ok := filters["batch_number"].(string); ok && batch != "" {
+		query = query.Where("batch_number = ?", batch)
+	}
+	if search, ok := filters["search"].(string); ok && search != "" {
+		like := "%" + search + "%"
+		query = query.Where("reference_number ILIKE ? OR batch_number ILIKE ? OR notes ILIKE ?", like, like, like)
+	}
+
+	query.Count(&total)
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).
+		Preload("Warehouse").
+		Preload("WarehouseLocation").
+		Find(&res).Error
+	return res, total, err
+}
+
+func (r *stockReservationRepository) ReservableBalances(itemType string, itemID, warehouseID uint) ([]*models.StockBalance, error) {
+	var balances []*models.StockBalance
+	err := r.db.Preload("WarehouseLocation").
+		Where("stock_balance.item_type = ? AND stock_balance.item_id = ? AND stock_balance.warehouse_id = ?", itemType, itemID, warehouseID).
+		Where("stock_balance.quantity - COALESCE(stock_balance.reserved_quantity, 0) > 0").
+		Where("stock_balance.expiry_date IS NULL OR stock_balance.expiry_date >= CURRENT_DATE").
+		Where(`stock_balance.warehouse_location_id IS NULL OR NOT EXISTS (
+			SELECT 1 FROM warehouse_locations wl
+			WHERE wl.id = stock_balance.warehouse_location_id
+			  AND (wl.location_type IN ('quarantine', 'in_transit') OR NOT COALESCE(wl.is_active, TRUE)))`).
+		Order("stock_balance.expiry_date ASC NULLS LAST, stock_balance.created_at ASC, stock_balance.id ASC").
+		Find(&balances).Error
+	return balances, err
+}
+
+func (r *stockReservationRepository) ActiveForReferences(refType string, refIDs []uint) ([]*models.StockReservation, error) {
+	var res []*models.StockReservation
+	if len(refIDs) == 0 {
+		return res, nil
+	}
+	err := r.db.Where("reference_type = ? AND reference_id IN ? AND status = 'active'", refType, refIDs).
+		Order("id").Find(&res).Error
+	return res, err
+}
+
+func (r *stockReservationRepository) StockRows(filters map[string]interface{}) ([]ReservationStockRow, error) {
+	var rows []ReservationStockRow
+	query := r.db.Table("stock_balance sb").
+		Select(`sb.item_type, sb.item_id,
+			COALESCE(m.code, fp.code, '') AS item_code, COALESCE(m.trading_name, fp.name, '') AS item_name,
+			sb.warehouse_id, w.name AS warehouse_name,
+			SUM(CASE WHEN wl.location_type = 'in_transit' THEN 0 ELSE sb.quantity END) AS on_hand,
+			SUM(CASE WHEN wl.location_type = 'in_transit' THEN sb.quantity ELSE 0 END) AS in_transit,
+			SUM(CASE WHEN wl.location_type = 'quarantine' THEN sb.quantity ELSE 0 END) AS quarantined,
+			SUM(COALESCE(sb.reserved_quantity, 0)) AS reserved`).
+		Joins("JOIN warehouses w ON w.id = sb.warehouse_id").
+		Joins("LEFT JOIN warehouse_locations wl ON wl.id = sb.warehouse_location_id").
+		Joins("LEFT JOIN materials m ON sb.item_type = 'material' AND m.id = sb.item_id").
+		Joins("LEFT JOIN finished_products fp ON sb.item_type = 'finished_product' AND fp.id = sb.item_id")
+	query = reservationReportFilters(query, "sb", filters)
+	err := query.Group("sb.item_type, sb.item_id, m.code, fp.code, m.trading_name, fp.name, sb.warehouse_id, w.name").
+		Having("SUM(sb.quantity) <> 0 OR SUM(COALESCE(sb.reserved_quantity, 0)) <> 0").
+		Order("sb.item_type, item_code, sb.warehouse_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *stockReservationRepository) OpenRows(filters map[string]interface{}) ([]OpenReservationRow, error) {
+	var rows []OpenReservationRow
+	query := r.db.Table("stock_reservations sr").
+		Select(`sr.item_type, sr.item_id, sr.warehouse_id,
+			SUM(sr.reserved_quantity - COALESCE(sr.fulfilled_quantity, 0)) AS open_quantity,
+			COUNT(*) AS reservation_count`).
+		Where("sr.status = 'active'")
+	query = reservationReportFilters(query, "sr", filters)
+	err := query.Group("sr.item_type, sr.item_id, sr.warehouse_id").Scan(&rows).Error
+	return rows, err
+}
+
+// reservationReportFilters applies the item/warehouse filters of the reservation report to a table alias
+func reservationReportFilters(query *gorm.DB, alias string, filters map[string]interface{}) *gorm.DB {
+	if itemType, ok := filters["item_type"].(string); ok && itemType != "" {
+		query = query.Where(alias+".item_type = ?", itemType)
+	}
+	if itemID, ok := filters["item_id"].(uint); ok && itemID > 0 {
+		query = query.Where(alias+".item_id = ?", itemID)
+	}
+	if warehouseID, ok := filters["warehouse_id"].(uint); ok && warehouseID > 0 {
+		query = query.Where(alias+".warehouse_id = ?", warehouseID)
+	}
+	return query
+}
@@ -1,16 +1,54 @@
 package repository
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/VyVy-ERP/warehouse-backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// InTransitRow is one shipped, not yet received transfer item with its transfer and item names
+// notInTransit keeps stock balances that are not in an in-transit location; shipped stock
+// belongs to neither warehouse's picking, counting or putaway until it is received
+const notInTransit = `stock_balance.warehouse_location_id IS NULL OR NOT EXISTS (
+	SELECT 1 FROM warehouse_locations wl
+	WHERE wl.id = stock_balance.warehouse_location_id AND wl.location_type = 'in_transit')`
+
+type InTransitRow struct {
+	TransferID          uint       `gorm:"column:transfer_id"`
+	TransferNumber      string     `gorm:"column:transfer_number"`
+	FromWarehouseID     uint       `gorm:"column:from_warehouse_id"`
+	FromWarehouseName   string     `gorm:"column:from_warehouse_name"`
+	ToWarehouseID       uint       `gorm:"column:to_warehouse_id"`
+	ToWarehouseName     string     `gorm:"column:to_warehouse_name"`
+	ShippedAt           *time.Time `gorm:"column:shipped_at"`
+	ExpectedArrivalDate *time.Time `gorm:"column:expected_arrival_date"`
+	Carrier             string     `gorm:"column:carrier"`
+	TrackingNumber      string     `gorm:"column:tracking_number"`
+	ItemID              uint       `gorm:"column:item_id"`
+	ItemType            string     `gorm:"column:item_type"`
+	ProductID           uint       `gorm:"column:product_id"`
+	ItemCode            string     `gorm:"column:item_code"`
+	ItemName            string     `gorm:"column:item_name"`
+	BatchNumber         string     `gorm:"column:batch_number"`
+	LotNumber           string     `gorm:"column:lot_number"`
+	Quantity            float64    `gorm:"column:quantity"`
+	UnitCost            float64    `gorm:"column:unit_cost"`
+}
+
 type StockTransferRepository interface {
 	Create(st *models.StockTransfer) error
 	GetByID(id uint) (*models.StockTransfer, error)
 	List(filters map[string]interface{}, offset, limit int) ([]*models.StockTransfer, int64, error)
 	Update(st *models.StockTransfer) error
 	Delete(id uint) error
+	UpdateItem(item *models.StockTransferItem) error
+	// TransitLocation returns the in-transit location of a warehouse, creating it on first use
+	TransitLocation(warehouse *models.Warehouse) (*models.WarehouseLocation, error)
+	// InTransit returns the items of shipped transfers, oldest shipment first
+	InTransit(fromWarehouseID, toWarehouseID uint) ([]InTransitRow, error)
 }
 
 type stockTransferRepository struct {
@@ -31,6 +69,7 @@ func (r *stockTransferRepository) GetByID(id uint) (*models.StockTransfer, error
 		Preload("Items.ToLocation").
 		Preload("FromWarehouse").
 		Preload("ToWarehouse").
+		Preload("TransitLocation").
 		Preload("ApprovedByUser").
 		Preload("ShippedByUser").
 		Preload("ReceivedByUser").
@@ -88,3 +127,80 @@ func (r *stockTransferRepository) Update(st *models.StockTransfer) error {
 func (r *stockTransferRepository) Delete(id uint) error {
 	return r.db.Delete(&models.StockTransfer{}, id).Error
 }
+
+func (r *stockTransferRepository) UpdateItem(item *models.StockTransferItem) error {
+	return r.db.Omit(clause.Associations).Save(item).Error
+}
+
+func (r *stockTransferRepository) TransitLocation(warehouse *models.Warehouse) (*models.WarehouseLocation, error) {
+	find := func() (*models.WarehouseLocation, error) {
+		var locations []*models.WarehouseLocation
+		err := r.db.Where("warehouse_id = ? AND location_type = ?", warehouse.ID, "in_transit").Limit(1).Find(&locations).Error
+		if err != nil || len(locations) == 0 {
+			return nil, err
+		}
+		return locations[0], nil
+	}
+	location, err := find()
+	if err != nil || location != nil {
+		return location, err
+	}
+
+	// Location codes are unique across warehouses; fall back to a code carrying the warehouse ID
+	// when another location already uses the plain one
+	code := "TRANSIT-" + warehouse.Code
+	var taken int64
+	if err := r.db.Model(&models.WarehouseLocation{}).Where("code = ?", code).Count(&taken).Error; err != nil {
+		return nil, err
+	}
+	if taken > 0 {
+		code = fmt.Sprintf("TRANSIT-%s-%d", warehouse.Code, warehouse.ID)
+	}
+
+	active := true
+	location = &models.WarehouseLocation{
+		WarehouseID:  warehouse.ID,
+		Code:         code,
+		Name:         "In transit to " + warehouse.Name,
+		LocationType: "in_transit",
+		IsActive:     &active,
+	}
+	// a concurrent shipment may have created it first
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(location).Error; err != nil {
+		return nil, err
+	}
+	location, err = find()
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return nil, fmt.Errorf("cannot create the in-transit location of warehouse %s: location code %s is already used by another location", warehouse.Code, code)
+	}
+	return location, nil
+}
+
+func (r *stockTransferRepository) InTransit(fromWarehouseID, toWarehouseID uint) ([]InTransitRow, error) {
+	var rows []InTransitRow
+	query := r.db.Table("stock_transfer_items sti").
+		Select(`st.id AS transfer_id, st.transfer_number, st.from_warehouse_id, fw.name AS from_warehouse_name,
+			st.to_warehouse_id, tw.name AS to_warehouse_name, st.shipped_at, st.expected_arrival_date,
+			COALESCE(st.carrier, '') AS carrier, COALESCE(st.tracking_number, '') AS tracking_number,
+			sti.id AS item_id, sti.item_type, sti.item_id AS product_id,
+			COALESCE(m.code, fp.code, '') AS item_code, COALESCE(m.trading_name, fp.name, '') AS item_name,
+			COALESCE(sti.batch_number, '') AS batch_number, COALESCE(sti.lot_number, '') AS lot_number,
+			sti.shipped_quantity AS quantity, COALESCE(sti.unit_cost, 0) AS unit_cost`).
+		Joins("JOIN stock_transfers st ON st.id = sti.stock_transfer_id").
+		Joins("JOIN warehouses fw ON fw.id = st.from_warehouse_id").
+		Joins("JOIN warehouses tw ON tw.id = st.to_warehouse_id").
+		Joins("LEFT JOIN materials m ON sti.item_type = 'material' AND m.id = sti.item_id").
+		Joins("LEFT JOIN finished_products fp ON sti.item_type = 'finished_product' AND fp.id = sti.item_id").
+		Where("st.status = ?", "shipped")
+	if fromWarehouseID > 0 {
+		query = query.Where("st.from_warehouse_id = ?", fromWarehouseID)
+	}
+	if toWarehouseID > 0 {
+		query = query.Where("st.to_warehouse_id = ?", toWarehouseID)
+	}
+	err := query.Order("st.shipped_at, st.id, sti.id").Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// StorageItemProfile holds what location constraints need to know about a material or finished product
+type StorageItemProfile struct {
+	ItemType          string   `gorm:"column:item_type"`
+	ItemID            uint     `gorm:"column:item_id"`
+	Code              string   `gorm:"column:code"`
+	Hazardous         bool     `gorm:"column:hazardous"`
+	StorageConditions string   `gorm:"column:storage_conditions"`
+	UnitWeightKg      *float64 `gorm:"column:unit_weight_kg"` // nil when unknown
+	UnitVolumeM3      *float64 `gorm:"column:unit_volume_m3"`
+	UnitsPerPallet    *float64 `gorm:"column:units_per_pallet"`
+}
+
+// LocationLoad is the stock currently held in a location, in every capacity dimension
+type LocationLoad struct {
+	LocationID uint    `gorm:"column:location_id"`
+	Quantity   float64 `gorm:"column:quantity"`
+	WeightKg   float64 `gorm:"column:weight_kg"`
+	VolumeM3   float64 `gorm:"column:volume_m3"`
+	Pallets    float64 `gorm:"column:pallets"`
+}
+
+// StorageCapacityRepository reads locations, item storage specs and current location loads
+type StorageCapacityRepository interface {
+	GetLocations(ids []uint) ([]*models.WarehouseLocation, error)
+	ListWarehouseLocations(warehouseID uint) ([]*models.WarehouseLocation, error)
+	ItemProfiles(itemType string, ids []uint) ([]StorageItemProfile, error)
+	LocationLoads(ids []uint) ([]LocationLoad, error)
+	WarehouseLoads(warehouseID uint) ([]LocationLoad, error)
+}
+
+type storageCapacityRepository struct {
+	db *gorm.DB
+}
+
+func NewStorageCapacityRepository(db *gorm.DB) StorageCapacityRepository {
+	return &storageCapacityRepository{db: db}
+}
+
+func (r *storageCapacityRepository) GetLocations(ids []uint) ([]*models.WarehouseLocation, error) {
+	var locations []*models.WarehouseLocation
+	err := r.db.Preload("Zone").Where("id IN ?", ids).Find(&locations).Error
+	return locations, err
+}
+
+func (r *storageCapacityRepository) ListWarehouseLocations(warehouseID uint) ([]*models.WarehouseLocation, error) {
+	var locations []*models.WarehouseLocation
+	err := r.db.Preload("Zone").Where("warehouse_id = ?", warehouseID).Order("code").Find(&locations).Error
+	return locations, err
+}
+
+// ItemProfiles loads storage specs. Material weight falls back to the unit of measure (KG, G);
+// finished products use their gross weight per unit.
+func (r *storageCapacityRepository) ItemProfiles(itemType string, ids []uint) ([]StorageItemProfile, error) {
+	var profiles []StorageItemProfile
+	var err error
+	switch itemType {
+	case "material":
+		err = r.db.Table("materials").
+			Select(`'material' AS item_type, id AS item_id, code, COALESCE(hazardous, FALSE) AS hazardous,
+				COALESCE(storage_conditions, '') AS storage_conditions,
+				COALESCE(unit_weight_kg, CASE UPPER(unit) WHEN 'KG' THEN 1 WHEN 'G' THEN 0.001 END) AS unit_weight_kg,
+				unit_volume_m3, units_per_pallet`).
+			Where("id IN ?", ids).Scan(&profiles).Error
+	case "finished_product":
+		err = r.db.Table("finished_products").
+			Select(`'finished_product' AS item_type, id AS item_id, code, FALSE AS hazardous,
+				COALESCE(storage_conditions, '') AS storage_conditions,
+				gross_weight AS unit_weight_kg, unit_volume_m3, units_per_pallet`).
+			Where("id IN ?", ids).Scan(&profiles).Error
+	}
+	return profiles, err
+}
+
+func (r *storageCapacityRepository) LocationLoads(ids []uint) ([]LocationLoad, error) {
+	var loads []LocationLoad
+	err := r.loadQuery().Where("sb.warehouse_location_id IN ?", ids).Scan(&loads).Error
+	return loads, err
+}
+
+func (r *storageCapacityRepository) WarehouseLoads(warehouseID uint) ([]LocationLoad, error) {
+	var loads []LocationLoad
+	err := r.loadQuery().Where("sb.warehouse_id = ? AND sb.warehouse_location_id IS NOT NULL", warehouseID).Scan(&loads).Error
+	return loads, err
+}
+
+// loadQuery sums stock per location; pallets are counted per balance row (item/batch), rounded up
+func (r *storageCapacityRepository) loadQuery() *gorm.DB {
+	return r.db.Table("stock_balance sb").
+		Select(`sb.warehouse_location_id AS location_id,
+			SUM(sb.quantity) AS quantity,
+			SUM(sb.quantity * COALESCE(m.unit_weight_kg, CASE UPPER(m.unit) WHEN 'KG' THEN 1 WHEN 'G' THEN 0.001 END, fp.gross_weight, 0)) AS weight_kg,
+			SUM(sb.quantity * COALESCE(m.unit_volume_m3, fp.unit_volume_m3, 0)) AS volume_m3,
+			SUM(CASE WHEN COALESCE(m.units_per_pallet, fp.units_per_pallet, 0) > 0
+				THEN CEIL(sb.quantity / COALESCE(m.units_per_pallet, fp.units_per_pallet)) ELSE 0 END) AS pallets`).
+		Joins("LEFT JOIN materials m ON sb.item_type = 'material' AND m.id = sb.item_id").
+		Joins("LEFT JOIN finished_products fp ON sb.item_type = 'finished_product' AND fp.id = sb.item_id").
+		Where("sb.quantity > 0").
+		Group("sb.warehouse_location_id")
+}
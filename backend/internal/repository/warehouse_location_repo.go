@@ -78,6 +78,11 @@ func (r *warehouseLocationRepository) List(filter *dto.WarehouseLocationFilterRe
 		query = query.Where("location_type = ?", filter.LocationType)
 	}
 
+	// Apply zone_id filter
+	if filter.ZoneID != nil {
+		query = query.Where("zone_id = ?", *filter.ZoneID)
+	}
+
 	// Apply is_active filter
 	if filter.IsActive != nil {
 		query = query.Where("is_active = ?", *filter.IsActive)
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// WarehouseZoneRepository defines data operations for warehouse zones
+type WarehouseZoneRepository interface {
+	Create(zone *models.WarehouseZone) error
+	GetByID(id uint) (*models.WarehouseZone, error)
+	GetByCode(warehouseID uint, code string) (*models.WarehouseZone, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.WarehouseZone, int64, error)
+	Update(zone *models.WarehouseZone) error
+	Delete(id uint) error
+	CountLocations(zoneID uint) (int64, error)
+}
+
+type warehouseZoneRepository struct {
+	db *gorm.DB
+}
+
+func NewWarehouseZoneRepository(db *gorm.DB) WarehouseZoneRepository {
+	return &warehouseZoneRepository{db: db}
+}
+
+func (r *warehouseZoneRepository) Create(zone *models.WarehouseZone) error {
+	return r.db.Omit("Warehouse").Create(zone).Error
+}
+
+func (r *warehouseZoneRepository) GetByID(id uint) (*models.WarehouseZone, error) {
+	var zone models.WarehouseZone
+	if err := r.db.Preload("Warehouse").First(&zone, id).Error; err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+func (r *warehouseZoneRepository) GetByCode(warehouseID uint, code string) (*models.WarehouseZone, error) {
+	var zone models.WarehouseZone
+	if err := r.db.Where("warehouse_id = ? AND code = ?", warehouseID, code).First(&zone).Error; err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+func (r *warehouseZoneRepository) List(filters map[string]interface{}, offset, limit int) ([]*models.WarehouseZone, int64, error) {
+	var zones []*models.WarehouseZone
+	var total int64
+
+	query := r.db.Model(&models.WarehouseZone{})
+	if warehouseID, ok := filters["warehouse_id"].(uint); ok && warehouseID > 0 {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if zoneType, ok := filters["zone_type"].(string); ok && zoneType != "" {
+		query = query.Where("zone_type = ?", zoneType)
+	}
+	if isActive, ok := filters["is_active"].(bool); ok {
+		query = query.Where("is_active = ?", isActive)
+	}
+
+	query.Count(&total)
+	err := query.Order("warehouse_id, code").Offset(offset).Limit(limit).Find(&zones).Error
+	return zones, total, err
+}
+
+func (r *warehouseZoneRepository) Update(zone *models.WarehouseZone) error {
+	return r.db.Omit("Warehouse").Save(zone).Error
+}
+
+func (r *warehouseZoneRepository) Delete(id uint) error {
+	return r.db.Delete(&models.WarehouseZone{}, id).Error
+}
+
+func (r *warehouseZoneRepository) CountLocations(zoneID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.WarehouseLocation{}).Where("zone_id = ?", zoneID).Count(&count).Error
+	return count, err
+}
@@ -22,19 +22,35 @@ type AlertItem struct {
 	DaysToExpiry int        `json:"days_to_expiry,omitempty"`
 }
 
+// DocumentAlertItem represents a supplier document that is expired or about to expire
+type DocumentAlertItem struct {
+	DocumentID     uint       `json:"document_id"`
+	SupplierID     uint       `json:"supplier_id"`
+	SupplierCode   string     `json:"supplier_code"`
+	SupplierName   string     `json:"supplier_name"`
+	DocumentType   string     `json:"document_type"`
+	DocumentNumber string     `json:"document_number,omitempty"`
+	MaterialCode   string     `json:"material_code,omitempty"`
+	ExpiryDate     *time.Time `json:"expiry_date,omitempty"`
+	DaysToExpiry   int        `json:"days_to_expiry"` // negative = already expired
+}
+
 // AlertSummary wraps alerts with counts
 type AlertSummary struct {
 	LowStockCount    int         `json:"low_stock_count"`
 	ExpiringSoonCount int        `json:"expiring_soon_count"`
+	ExpiringDocumentsCount int   `json:"expiring_documents_count"`
 	TotalAlerts      int         `json:"total_alerts"`
 	LowStockItems    []AlertItem `json:"low_stock_items"`
 	ExpiringSoonItems []AlertItem `json:"expiring_soon_items"`
+	ExpiringDocuments []DocumentAlertItem `json:"expiring_documents"`
 }
 
 type AlertService interface {
 	GetAlertSummary() (*AlertSummary, error)
 	GetLowStockAlerts() ([]AlertItem, error)
 	GetExpiringSoonAlerts(days int) ([]AlertItem, error)
+	GetExpiringDocumentAlerts(days int) ([]DocumentAlertItem, error)
 }
 
 type alertService struct {
@@ -56,12 +72,19 @@ func (s *alertService) GetAlertSummary() (*AlertSummary, error) {
 		return nil, err
 	}
 
+	documents, err := s.GetExpiringDocumentAlerts(30)
+	if err != nil {
+		return nil, err
+	}
+
 	return &AlertSummary{
 		LowStockCount:     len(lowStock),
 		ExpiringSoonCount:  len(expiring),
-		TotalAlerts:        len(lowStock) + len(expiring),
+		ExpiringDocumentsCount: len(documents),
+		TotalAlerts:        len(lowStock) + len(expiring) + len(documents),
 		LowStockItems:     lowStock,
 		ExpiringSoonItems:  expiring,
+		ExpiringDocuments:  documents,
 	}, nil
 }
 
@@ -126,3 +149,46 @@ func (s *alertService) GetExpiringSoonAlerts(days int) ([]AlertItem, error) {
 	}
 	return items, nil
 }
+
+// GetExpiringDocumentAlerts returns typed supplier documents expiring within N days (including already expired)
+func (s *alertService) GetExpiringDocumentAlerts(days int) ([]DocumentAlertItem, error) {
+	var items []DocumentAlertItem
+
+	limitDate := time.Now().AddDate(0, 0, days)
+
+	err := s.db.Table("supplier_documents").
+		Select(`
+			supplier_documents.id as document_id,
+			suppliers.id as supplier_id,
+			suppliers.code as supplier_code,
+			suppliers.name as supplier_name,
+			supplier_documents.document_type as document_type,
+			COALESCE(supplier_documents.document_number, '') as document_number,
+			COALESCE(materials.code, '') as material_code,
+			supplier_documents.expiry_date as expiry_date,
+			(supplier_documents.expiry_date - CURRENT_DATE)::int as days_to_expiry
+		`).
+		Joins("JOIN suppliers ON supplier_documents.supplier_id = suppliers.id").
+		Joins("LEFT JOIN materials ON supplier_documents.material_id = materials.id").
+		Where("supplier_documents.document_type <> ?", "other").
+		Where("supplier_documents.expiry_date IS NOT NULL AND supplier_documents.expiry_date <= ?", limitDate).
+		Where("suppliers.is_active = ?", true).
+		// Skip documents already superseded by a newer one of the same type
+		Where(`NOT EXISTS (
+			SELECT 1 FROM supplier_documents newer
+			WHERE newer.supplier_id = supplier_documents.supplier_id
+			  AND newer.document_type = supplier_documents.document_type
+			  AND COALESCE(newer.material_id, 0) = COALESCE(supplier_documents.material_id, 0)
+			  AND (newer.expiry_date IS NULL OR newer.expiry_date > ?)
+		)`, limitDate).
+		Order("supplier_documents.expiry_date ASC").
+		Scan(&items).Error
+
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []DocumentAlertItem{}
+	}
+	return items, nil
+}
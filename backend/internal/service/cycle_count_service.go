@@ -0,0 +1,704 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Cycle count task statuses
+const (
+	CycleCountPending   = "pending"
+	CycleCountRecount   = "recount"
+	CycleCountCounted   = "counted"
+	CycleCountAdjusted  = "adjusted"
+	CycleCountClosed    = "closed"
+	CycleCountCancelled = "cancelled"
+)
+
+// CycleCountService classifies items ABC by consumption value, schedules count tasks by class
+// frequency and turns approved count variances into draft stock adjustments
+type CycleCountService interface {
+	GetPolicy(warehouseID uint) (*models.CycleCountPolicy, error)
+	SavePolicy(req *dto.SaveCycleCountPolicyRequest, userID uint, username string) (*models.CycleCountPolicy, error)
+	Classify(warehouseID uint) (*dto.ABCClassificationResult, error)
+	ListClasses(filters map[string]interface{}, offset, limit int) ([]*models.ItemABCClass, int64, error)
+	Generate(req *dto.GenerateCycleCountsRequest, userID uint) (*dto.GenerateCycleCountsResult, error)
+	// RunScheduled reclassifies and generates due tasks for every warehouse with auto_schedule set
+	RunScheduled(userID uint) ([]*dto.GenerateCycleCountsResult, []string)
+
+	GetTask(id uint) (*models.CycleCountTask, error)
+	ListTasks(filters map[string]interface{}, offset, limit int) ([]*models.CycleCountTask, int64, error)
+	Count(id uint, req *dto.EnterCycleCountRequest, userID uint, username string) (*models.CycleCountTask, error)
+	Recount(id uint, userID uint, username string) (*models.CycleCountTask, error)
+	Approve(req *dto.ApproveCycleCountsRequest, userID uint, username string) (*dto.CycleCountApprovalResult, error)
+	Cancel(id uint, userID uint, username string) (*models.CycleCountTask, error)
+}
+
+type cycleCountService struct {
+	db       *gorm.DB
+	repo     repository.CycleCountRepository
+	auditSvc AuditLogService
+}
+
+func NewCycleCountService(db *gorm.DB, repo repository.CycleCountRepository, auditSvc AuditLogService) CycleCountService {
+	return &cycleCountService{db: db, repo: repo, auditSvc: auditSvc}
+}
+
+// defaultCycleCountPolicy mirrors the column defaults for warehouses without a saved policy
+func defaultCycleCountPolicy(warehouseID uint) *models.CycleCountPolicy {
+	return &models.CycleCountPolicy{
+		WarehouseID:         warehouseID,
+		AValueShare:         80,
+		BValueShare:         95,
+		AFrequencyDays:      30,
+		BFrequencyDays:      90,
+		CFrequencyDays:      180,
+		LookbackDays:        365,
+		RecountThresholdPct: 2,
+	}
+}
+
+// abcItem is an item ranked by consumption value; Share is the cumulative value share in percent
+type abcItem struct {
+	ItemType string
+	ItemID   uint
+	Quantity float64
+	Value    float64
+	Share    float64
+	Class    string
+}
+
+// classifyABC ranks the items by consumption value and assigns classes by cumulative share:
+// an item is A while the items ranked above it account for less than aShare percent of the total
+// value, B while they account for less than bShare, and C otherwise. Items without consumption are C.
+func classifyABC(items []*abcItem, aShare, bShare float64) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Value != items[j].Value {
+			return items[i].Value > items[j].Value
+		}
+		if items[i].ItemType != items[j].ItemType {
+			return items[i].ItemType < items[j].ItemType
+		}
+		return items[i].ItemID < items[j].ItemID
+	})
+
+	var total float64
+	for _, it := range items {
+		if it.Value > 0 {
+			total += it.Value
+		}
+	}
+
+	var cumulative float64
+	for _, it := range items {
+		if total <= 0 || it.Value <= 0 {
+			it.Class = "C"
+			it.Share = 100
+			if total <= 0 {
+				it.Share = 0
+			}
+			continue
+		}
+		before := cumulative / total * 100
+		cumulative += it.Value
+		it.Share = roundMoney(cumulative / total * 100)
+		switch {
+		case before < aShare:
+			it.Class = "A"
+		case before < bShare:
+			it.Class = "B"
+		default:
+			it.Class = "C"
+		}
+	}
+}
+
+// countFrequencyDays is how often items of the class are counted
+func countFrequencyDays(policy *models.CycleCountPolicy, class string) int {
+	switch class {
+	case "A":
+		return policy.AFrequencyDays
+	case "B":
+		return policy.BFrequencyDays
+	default:
+		return policy.CFrequencyDays
+	}
+}
+
+// isCountDue reports whether an item last counted at lastCounted is due on the given day
+func isCountDue(lastCounted *time.Time, frequencyDays int, day time.Time) bool {
+	if lastCounted == nil {
+		return true
+	}
+	due := lastCounted.AddDate(0, 0, frequencyDays)
+	return !dateOnlyTime(due).After(dateOnlyTime(day))
+}
+
+func dateOnlyTime(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// countVariance returns counted - system and the variance in percent of the system quantity
+// (100% when counting stock the system does not know about)
+func countVariance(system, counted float64) (float64, float64) {
+	variance := roundQty(counted - system)
+	if math.Abs(variance) <= qtyEpsilon {
+		return 0, 0
+	}
+	if math.Abs(system) <= qtyEpsilon {
+		return variance, 100
+	}
+	return variance, roundMoney(variance / math.Abs(system) * 100)
+}
+
+// needsRecount reports whether a variance is outside both recount thresholds of the policy
+func needsRecount(policy *models.CycleCountPolicy, variance, variancePct float64) bool {
+	if math.Abs(variance) <= qtyEpsilon {
+		return false
+	}
+	return math.Abs(variance) > policy.RecountThresholdQty && math.Abs(variancePct) > policy.RecountThresholdPct
+}
+
+// blindTask hides the system quantity and the previous count while a task still has to be counted
+func blindTask(task *models.CycleCountTask) *models.CycleCountTask {
+	if task.Status == CycleCountPending || task.Status == CycleCountRecount {
+		task.SystemQuantity = nil
+		task.CountedQuantity = nil
+		task.FirstCountedQuantity = nil
+		task.VarianceQuantity = nil
+		task.VariancePct = nil
+		task.VarianceValue = nil
+		task.SerialNumbers = nil
+	}
+	return task
+}
+
+func (s *cycleCountService) policyFor(warehouseID uint) (*models.CycleCountPolicy, error) {
+	policy, err := s.repo.GetPolicy(warehouseID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultCycleCountPolicy(warehouseID), nil
+	}
+	return policy, err
+}
+
+func (s *cycleCountService) GetPolicy(warehouseID uint) (*models.CycleCountPolicy, error) {
+	return s.policyFor(warehouseID)
+}
+
+func (s *cycleCountService) SavePolicy(req *dto.SaveCycleCountPolicyRequest, userID uint, username string) (*models.CycleCountPolicy, error) {
+	var warehouse models.Warehouse
+	if err := s.db.First(&warehouse, req.WarehouseID).Error; err != nil {
+		return nil, errors.New("warehouse not found")
+	}
+	policy, err := s.policyFor(req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	old := *policy
+
+	if req.AValueShare != nil {
+		policy.AValueShare = *req.AValueShare
+	}
+	if req.BValueShare != nil {
+		policy.BValueShare = *req.BValueShare
+	}
+	if req.AFrequencyDays != nil {
+		policy.AFrequencyDays = *req.AFrequencyDays
+	}
+	if req.BFrequencyDays != nil {
+		policy.BFrequencyDays = *req.BFrequencyDays
+	}
+	if req.CFrequencyDays != nil {
+		policy.CFrequencyDays = *req.CFrequencyDays
+	}
+	if req.LookbackDays != nil {
+		policy.LookbackDays = *req.LookbackDays
+	}
+	if req.RecountThresholdPct != nil {
+		policy.RecountThresholdPct = *req.RecountThresholdPct
+	}
+	if req.RecountThresholdQty != nil {
+		policy.RecountThresholdQty = *req.RecountThresholdQty
+	}
+	if req.AutoSchedule != nil {
+		policy.AutoSchedule = *req.AutoSchedule
+	}
+	if policy.AValueShare >= policy.BValueShare {
+		return nil, errors.New("a_value_share must be below b_value_share")
+	}
+
+	if err := s.repo.SavePolicy(policy); err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("cycle_count_policies", "UPDATE", int64(policy.ID), int64(userID), username, old, policy)
+	return policy, nil
+}
+
+func (s *cycleCountService) Classify(warehouseID uint) (*dto.ABCClassificationResult, error) {
+	policy, err := s.policyFor(warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	since := now.AddDate(0, 0, -policy.LookbackDays)
+
+	consumption, err := s.repo.Consumption(warehouseID, since)
+	if err != nil {
+		return nil, err
+	}
+	stocked, err := s.repo.StockedItems(warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.ClassesByWarehouse(warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stocked or previously classified items without consumption in the period fall to C
+	byItem := make(map[string]*abcItem)
+	var items []*abcItem
+	add := func(itemType string, itemID uint) *abcItem {
+		key := fmt.Sprintf("%s:%d", itemType, itemID)
+		if it, ok := byItem[key]; ok {
+			return it
+		}
+		it := &abcItem{ItemType: itemType, ItemID: itemID}
+		byItem[key] = it
+		items = append(items, it)
+		return it
+	}
+	for _, c := range consumption {
+		it := add(c.ItemType, c.ItemID)
+		it.Quantity = roundQty(c.Quantity)
+		it.Value = roundMoney(c.Value)
+	}
+	for _, c := range stocked {
+		add(c.ItemType, c.ItemID)
+	}
+	for _, c := range existing {
+		add(c.ItemType, c.ItemID)
+	}
+
+	classifyABC(items, policy.AValueShare, policy.BValueShare)
+
+	result := &dto.ABCClassificationResult{
+		WarehouseID: warehouseID,
+		Since:       since.Format("2006-01-02"),
+		Items:       len(items),
+		Classes:     map[string]int{"A": 0, "B": 0, "C": 0},
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewCycleCountRepository(tx)
+		for _, it := range items {
+			result.TotalValue += it.Value
+			result.Classes[it.Class]++
+			if err := txRepo.UpsertClass(&models.ItemABCClass{
+				WarehouseID:      warehouseID,
+				ItemType:         it.ItemType,
+				ItemID:           it.ItemID,
+				ABCClass:         it.Class,
+				ConsumptionQty:   it.Quantity,
+				ConsumptionValue: it.Value,
+				CumulativeShare:  it.Share,
+				ClassifiedAt:     &now,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.TotalValue = roundMoney(result.TotalValue)
+	return result, nil
+}
+
+func (s *cycleCountService) ListClasses(filters map[string]interface{}, offset, limit int) ([]*models.ItemABCClass, int64, error) {
+	return s.repo.ListClasses(filters, offset, limit)
+}
+
+func (s *cycleCountService) Generate(req *dto.GenerateCycleCountsRequest, userID uint) (*dto.GenerateCycleCountsResult, error) {
+	day := time.Now()
+	if req.ScheduledDate != "" {
+		d, err := time.ParseInLocation("2006-01-02", req.ScheduledDate, time.Local)
+		if err != nil {
+			return nil, errors.New("invalid scheduled_date format, use YYYY-MM-DD")
+		}
+		day = d
+	}
+	policy, err := s.policyFor(req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	classes, err := s.repo.ClassesByWarehouse(req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if len(classes) == 0 {
+		if _, err := s.Classify(req.WarehouseID); err != nil {
+			return nil, err
+		}
+		if classes, err = s.repo.ClassesByWarehouse(req.WarehouseID); err != nil {
+			return nil, err
+		}
+	}
+
+	openItems, err := s.repo.OpenTaskItems(req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	open := make(map[string]bool, len(openItems))
+	for _, it := range openItems {
+		open[fmt.Sprintf("%s:%d", it.ItemType, it.ItemID)] = true
+	}
+
+	result := &dto.GenerateCycleCountsResult{WarehouseID: req.WarehouseID}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewCycleCountRepository(tx)
+		prefix := fmt.Sprintf("CC-%s-", time.Now().Format("2006"))
+		seq, err := txRepo.CountByTaskNumber(prefix)
+		if err != nil {
+			return err
+		}
+
+		var tasks []*models.CycleCountTask
+		for _, class := range classes {
+			if req.ABCClass != "" && class.ABCClass != req.ABCClass {
+				continue
+			}
+			if open[fmt.Sprintf("%s:%d", class.ItemType, class.ItemID)] {
+				continue
+			}
+			if !isCountDue(class.LastCountedAt, countFrequencyDays(policy, class.ABCClass), day) {
+				continue
+			}
+			if req.MaxTasks > 0 && len(tasks) >= req.MaxTasks {
+				break
+			}
+			balances, err := txRepo.CountableBalances(req.WarehouseID, class.ItemType, class.ItemID)
+			if err != nil {
+				return err
+			}
+			if len(balances) == 0 {
+				continue
+			}
+			result.Items++
+			for _, b := range balances {
+				seq++
+				tasks = append(tasks, &models.CycleCountTask{
+					TaskNumber:          fmt.Sprintf("%s%06d", prefix, seq),
+					WarehouseID:         req.WarehouseID,
+					ItemType:            b.ItemType,
+					ItemID:              b.ItemID,
+					WarehouseLocationID: b.WarehouseLocationID,
+					BatchNumber:         b.BatchNumber,
+					LotNumber:           b.LotNumber,
+					ABCClass:            class.ABCClass,
+					ScheduledDate:       day.Format("2006-01-02"),
+					Status:              CycleCountPending,
+					UnitCost:            b.UnitCost,
+					CreatedBy:           &userID,
+				})
+			}
+		}
+		result.Tasks = len(tasks)
+		return txRepo.CreateTasks(tasks)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *cycleCountService) RunScheduled(userID uint) ([]*dto.GenerateCycleCountsResult, []string) {
+	policies, err := s.repo.AutoScheduledPolicies()
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+	var results []*dto.GenerateCycleCountsResult
+	var errs []string
+	for _, p := range policies {
+		if _, err := s.Classify(p.WarehouseID); err != nil {
+			errs = append(errs, fmt.Sprintf("warehouse %d: classify: %v", p.WarehouseID, err))
+			continue
+		}
+		result, err := s.Generate(&dto.GenerateCycleCountsRequest{WarehouseID: p.WarehouseID}, userID)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("warehouse %d: generate: %v", p.WarehouseID, err))
+			continue
+		}
+		results = append(results, result)
+	}
+	return results, errs
+}
+
+func (s *cycleCountService) GetTask(id uint) (*models.CycleCountTask, error) {
+	task, err := s.repo.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	return blindTask(task), nil
+}
+
+func (s *cycleCountService) ListTasks(filters map[string]interface{}, offset, limit int) ([]*models.CycleCountTask, int64, error) {
+	tasks, total, err := s.repo.ListTasks(filters, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, t := range tasks {
+		blindTask(t)
+	}
+	return tasks, total, nil
+}
+
+// Count records a blind count. The variance is taken against the balance at this moment; a variance
+// outside the recount thresholds sends a first count back for a recount, a zero variance closes the task.
+func (s *cycleCountService) Count(id uint, req *dto.EnterCycleCountRequest, userID uint, username string) (*models.CycleCountTask, error) {
+	task, err := s.repo.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != CycleCountPending && task.Status != CycleCountRecount {
+		return nil, fmt.Errorf("task is %s and cannot be counted", task.Status)
+	}
+	policy, err := s.policyFor(task.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewCycleCountRepository(tx)
+		balance, err := txRepo.BalanceFor(task)
+		if err != nil {
+			return err
+		}
+		var system float64
+		if balance != nil {
+			system = roundQty(balance.Quantity)
+			if balance.UnitCost > 0 {
+				task.UnitCost = balance.UnitCost
+			}
+		}
+
+		counted := roundQty(*req.CountedQuantity)
+		serials := cleanSerials(req.SerialNumbers)
+		if err := checkCountedSerials(tx, task.ItemType, task.ItemID, counted, serials, task.TaskNumber); err != nil {
+			return err
+		}
+		task.SerialNumbers = serials
+		variance, pct := countVariance(system, counted)
+		value := roundMoney(variance * task.UnitCost)
+		if task.Status == CycleCountRecount {
+			task.FirstCountedQuantity = task.CountedQuantity
+		}
+		task.SystemQuantity = &system
+		task.CountedQuantity = &counted
+		task.VarianceQuantity = &variance
+		task.VariancePct = &pct
+		task.VarianceValue = &value
+		task.CountedBy = &userID
+		task.CountedAt = &now
+		if req.Notes != "" {
+			task.Notes = req.Notes
+		}
+
+		switch {
+		case variance == 0:
+			task.Status = CycleCountClosed
+			if err := txRepo.MarkCounted(task.WarehouseID, task.ItemType, task.ItemID, now); err != nil {
+				return err
+			}
+		case task.RecountCount == 0 && needsRecount(policy, variance, pct):
+			task.Status = CycleCountRecount
+			task.RecountCount++
+		default:
+			task.Status = CycleCountCounted
+		}
+		return txRepo.UpdateTask(task)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("cycle_count_tasks", "COUNT", int64(task.ID), int64(userID), username, nil, map[string]interface{}{
+		"task_number":      task.TaskNumber,
+		"counted_quantity": *task.CountedQuantity,
+		"variance":         *task.VarianceQuantity,
+		"status":           task.Status,
+	})
+	return s.GetTask(task.ID)
+}
+
+// Recount sends a counted task back to be counted again blind
+func (s *cycleCountService) Recount(id uint, userID uint, username string) (*models.CycleCountTask, error) {
+	task, err := s.repo.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != CycleCountCounted {
+		return nil, errors.New("only counted tasks can be sent for a recount")
+	}
+	task.Status = CycleCountRecount
+	task.RecountCount++
+	if err := s.repo.UpdateTask(task); err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("cycle_count_tasks", "RECOUNT", int64(task.ID), int64(userID), username, nil, map[string]interface{}{
+		"task_number": task.TaskNumber,
+	})
+	return s.GetTask(task.ID)
+}
+
+// Approve accepts the variances of counted tasks and writes them into one draft cycle_count
+// stock adjustment, which is posted through the normal adjustment flow
+func (s *cycleCountService) Approve(req *dto.ApproveCycleCountsRequest, userID uint, username string) (*dto.CycleCountApprovalResult, error) {
+	ids := uniqueUints(req.TaskIDs)
+	tasks := make([]*models.CycleCountTask, 0, len(ids))
+	for _, id := range ids {
+		task, err := s.repo.GetTask(id)
+		if err != nil {
+			return nil, fmt.Errorf("cycle count task %d not found", id)
+		}
+		if task.Status != CycleCountCounted {
+			return nil, fmt.Errorf("task %s is %s, only counted tasks can be approved", task.TaskNumber, task.Status)
+		}
+		if len(tasks) > 0 && task.WarehouseID != tasks[0].WarehouseID {
+			return nil, errors.New("all tasks must belong to the same warehouse")
+		}
+		tasks = append(tasks, task)
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = "Cycle count variance"
+	}
+	numbers := make([]string, len(tasks))
+	for i, t := range tasks {
+		numbers[i] = t.TaskNumber
+	}
+	notes := "Cycle count tasks: " + strings.Join(numbers, ", ")
+	if req.Notes != "" {
+		notes = req.Notes + "\n" + notes
+	}
+
+	now := time.Now()
+	sa := &models.StockAdjustment{
+		WarehouseID:    tasks[0].WarehouseID,
+		AdjustmentDate: now,
+		AdjustmentType: "cycle_count",
+		Reason:         reason,
+		Notes:          notes,
+		Status:         "draft",
+		CreatedBy:      &userID,
+		UpdatedBy:      &userID,
+	}
+	for _, t := range tasks {
+		sa.Items = append(sa.Items, models.StockAdjustmentItem{
+			ItemType:            t.ItemType,
+			ItemID:              t.ItemID,
+			WarehouseLocationID: t.WarehouseLocationID,
+			BatchNumber:         t.BatchNumber,
+			LotNumber:           t.LotNumber,
+			AdjustmentQuantity:  *t.VarianceQuantity,
+			PreviousQuantity:    *t.SystemQuantity,
+			NewQuantity:         *t.CountedQuantity,
+			UnitCost:            t.UnitCost,
+			Notes:               t.TaskNumber,
+			CreatedBy:           &userID,
+			UpdatedBy:           &userID,
+		})
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i, t := range tasks {
+			serials, err := countedSerials(tx, t.ItemType, t.ItemID, t.WarehouseID, t.WarehouseLocationID,
+				t.BatchNumber, t.LotNumber, t.SerialNumbers, *t.VarianceQuantity)
+			if err != nil {
+				return err
+			}
+			sa.Items[i].SerialNumbers = serials
+		}
+		sa.AdjustmentNumber = generateAdjustmentNumber(tx)
+		if err := repository.NewStockAdjustmentRepository(tx).Create(sa); err != nil {
+			return err
+		}
+		txRepo := repository.NewCycleCountRepository(tx)
+		for _, t := range tasks {
+			t.Status = CycleCountAdjusted
+			t.ApprovedBy = &userID
+			t.ApprovedAt = &now
+			t.StockAdjustmentID = &sa.ID
+			if err := txRepo.UpdateTask(t); err != nil {
+				return err
+			}
+			if err := txRepo.MarkCounted(t.WarehouseID, t.ItemType, t.ItemID, *t.CountedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("stock_adjustments", "CREATE", int64(sa.ID), int64(userID), username, nil, map[string]interface{}{
+		"adjustment_number": sa.AdjustmentNumber,
+		"cycle_count_tasks": numbers,
+	})
+	return &dto.CycleCountApprovalResult{
+		Approved:          len(tasks),
+		StockAdjustmentID: sa.ID,
+		AdjustmentNumber:  sa.AdjustmentNumber,
+	}, nil
+}
+
+func (s *cycleCountService) Cancel(id uint, userID uint, username string) (*models.CycleCountTask, error) {
+	task, err := s.repo.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	switch task.Status {
+	case CycleCountPending, CycleCountRecount, CycleCountCounted:
+	default:
+		return nil, fmt.Errorf("task is %s and cannot be cancelled", task.Status)
+	}
+	task.Status = CycleCountCancelled
+	if err := s.repo.UpdateTask(task); err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("cycle_count_tasks", "CANCEL", int64(task.ID), int64(userID), username, nil, map[string]interface{}{
+		"task_number": task.TaskNumber,
+	})
+	return s.GetTask(task.ID)
+}
+
+// StartCycleCountScheduler reclassifies and generates due count tasks in the background every interval
+func StartCycleCountScheduler(svc CycleCountService, interval time.Duration, userID uint) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			results, errs := svc.RunScheduled(userID)
+			for _, r := range results {
+				log.Printf("cycle count: warehouse %d: %d tasks for %d items", r.WarehouseID, r.Tasks, r.Items)
+			}
+			for _, msg := range errs {
+				log.Printf("cycle count: %s", msg)
+			}
+		}
+	}()
+}
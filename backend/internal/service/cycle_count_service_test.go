@@ -0,0 +1,87 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyABC(t *testing.T) {
+	items := []*abcItem{
+		{ItemType: "material", ItemID: 3, Value: 50},
+		{ItemType: "material", ItemID: 1, Value: 700},
+		{ItemType: "finished_product", ItemID: 9, Value: 0},
+		{ItemType: "material", ItemID: 2, Value: 200},
+		{ItemType: "material", ItemID: 4, Value: 50},
+	}
+	classifyABC(items, 80, 95)
+
+	classes := make(map[uint]string)
+	for _, it := range items {
+		classes[it.ItemID] = it.Class
+	}
+	assert.Equal(t, uint(1), items[0].ItemID)
+	assert.Equal(t, 70.0, items[0].Share)
+	assert.Equal(t, "A", classes[1])
+	assert.Equal(t, "A", classes[2]) // 70% ranked above it
+	assert.Equal(t, "B", classes[3]) // 90% ranked above it
+	assert.Equal(t, "C", classes[4]) // 95% ranked above it
+	assert.Equal(t, "C", classes[9])
+}
+
+func TestClassifyABCWithoutConsumption(t *testing.T) {
+	items := []*abcItem{{ItemType: "material", ItemID: 1}, {ItemType: "material", ItemID: 2}}
+	classifyABC(items, 80, 95)
+	for _, it := range items {
+		assert.Equal(t, "C", it.Class)
+		assert.Equal(t, 0.0, it.Share)
+	}
+}
+
+func TestIsCountDue(t *testing.T) {
+	day := time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)
+	assert.True(t, isCountDue(nil, 30, day))
+
+	last := time.Date(2026, 9, 19, 17, 0, 0, 0, time.Local)
+	assert.True(t, isCountDue(&last, 30, day))
+	assert.False(t, isCountDue(&last, 31, day))
+}
+
+func TestCountVariance(t *testing.T) {
+	v, pct := countVariance(100, 97)
+	assert.Equal(t, -3.0, v)
+	assert.Equal(t, -3.0, pct)
+
+	v, pct = countVariance(0, 4)
+	assert.Equal(t, 4.0, v)
+	assert.Equal(t, 100.0, pct)
+
+	v, pct = countVariance(12.5, 12.5)
+	assert.Equal(t, 0.0, v)
+	assert.Equal(t, 0.0, pct)
+}
+
+func TestNeedsRecount(t *testing.T) {
+	policy := defaultCycleCountPolicy(1)
+	assert.True(t, needsRecount(policy, -3, -3))
+	assert.False(t, needsRecount(policy, -1, -1))
+	assert.False(t, needsRecount(policy, 0, 0))
+
+	policy.RecountThresholdQty = 5
+	assert.False(t, needsRecount(policy, -3, -3))
+	assert.True(t, needsRecount(policy, 6, 6))
+}
+
+func TestBlindTask(t *testing.T) {
+	system, counted := 10.0, 8.0
+	task := &models.CycleCountTask{Status: CycleCountRecount, SystemQuantity: &system, CountedQuantity: &counted}
+	blindTask(task)
+	assert.Nil(t, task.SystemQuantity)
+	assert.Nil(t, task.CountedQuantity)
+
+	task = &models.CycleCountTask{Status: CycleCountCounted, SystemQuantity: &system, CountedQuantity: &counted}
+	blindTask(task)
+	assert.Equal(t, 10.0, *task.SystemQuantity)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
@@ -97,6 +98,8 @@ func (s *deliveryOrderService) CreateDeliveryOrder(req *dto.CreateDeliveryOrderR
 			BatchNumber:       itemReq.BatchNumber,
 			LotNumber:         itemReq.LotNumber,
 			Quantity:          itemReq.Quantity,
+			ExplodeKit:        itemReq.ExplodeKit,
+			SerialNumbers:     cleanSerials(itemReq.SerialNumbers),
 			Notes:             itemReq.Notes,
 			CreatedBy:         &userID,
 			UpdatedBy:         &userID,
@@ -163,6 +166,9 @@ func (s *deliveryOrderService) UpdateDeliveryOrder(id uint, req *dto.UpdateDeliv
 	if do.IsPosted {
 		return nil, errors.New("cannot update a posted delivery order")
 	}
+	if len(req.Items) > 0 && do.Status == "picking" {
+		return nil, errors.New("cancel the pick list before changing the items of a delivery order being picked")
+	}
 
 	// Update fields
 	if req.CustomerName != "" {
@@ -213,6 +219,8 @@ func (s *deliveryOrderService) UpdateDeliveryOrder(id uint, req *dto.UpdateDeliv
 					BatchNumber:       itemReq.BatchNumber,
 					LotNumber:         itemReq.LotNumber,
 					Quantity:          itemReq.Quantity,
+					ExplodeKit:        itemReq.ExplodeKit,
+					SerialNumbers:     cleanSerials(itemReq.SerialNumbers),
 					Notes:             itemReq.Notes,
 					CreatedBy:         &userID,
 					UpdatedBy:         &userID,
@@ -249,10 +257,49 @@ func (s *deliveryOrderService) ShipDeliveryOrder(id uint, req *dto.ShipDeliveryO
 		return nil, errors.New("cannot ship a cancelled delivery order")
 	}
 
+	// Serials selected on the lines, unless others were scanned at dispatch
+	serials := cleanSerials(req.SerialNumbers)
+	if serials == nil {
+		for _, item := range do.Items {
+			serials = append(serials, item.SerialNumbers...)
+		}
+	}
+
 	now := time.Now()
 	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// 1. Update each item and stock
+		if err := checkWarehouseNotFrozen(tx, do.WarehouseID); err != nil {
+			return err
+		}
+
+		// 1. Ship what was confirmed on the pick lists, if the order was picked
+		picks, err := repository.NewPickListRepository(tx).LinesForDeliveryOrder(do.ID)
+		if err != nil {
+			return err
+		}
+		if len(picks) > 0 {
+			if err := s.applyPicks(tx, do, picks, userID); err != nil {
+				return err
+			}
+		}
+
+		// Spread the serials over the items that are shipped
+		lineSerials, err := deliverySerials(tx, do, serials)
+		if err != nil {
+			return err
+		}
+
+		// 2. Update each item and stock
 		for i, item := range do.Items {
+			if item.Quantity <= qtyEpsilon {
+				if len(item.SerialNumbers) > 0 {
+					if err := tx.Model(&models.DeliveryOrderItem{}).Where("id = ?", item.ID).
+						Update("serial_numbers", gorm.Expr("NULL")).Error; err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
 			// Get stock balance
 			var balance models.StockBalance
 			query := tx.Where("item_type = ? AND item_id = ? AND warehouse_id = ?", "finished_product", item.FinishedProductID, do.WarehouseID)
@@ -266,6 +313,9 @@ func (s *deliveryOrderService) ShipDeliveryOrder(id uint, req *dto.ShipDeliveryO
 			if item.BatchNumber != "" {
 				query = query.Where("batch_number = ?", item.BatchNumber)
 			}
+			if item.LotNumber != "" {
+				query = query.Where("lot_number = ?", item.LotNumber)
+			}
 			
 			if err := query.First(&balance).Error; err != nil {
 				return fmt.Errorf("insufficient stock for product %d in specified batch/location", item.FinishedProductID)
@@ -308,6 +358,9 @@ func (s *deliveryOrderService) ShipDeliveryOrder(id uint, req *dto.ShipDeliveryO
 			if err := tx.Create(&ledger).Error; err != nil {
 				return err
 			}
+			if err := postSerials(tx, &ledger, lineSerials[i], serialShip); err != nil {
+				return err
+			}
 
 			// Update stock balance
 			balance.Quantity -= item.Quantity
@@ -317,14 +370,24 @@ func (s *deliveryOrderService) ShipDeliveryOrder(id uint, req *dto.ShipDeliveryO
 				return err
 			}
 
-			// Record cost in item
+			// Record cost and serials in item
 			do.Items[i].UnitCost = &balance.UnitCost
+			do.Items[i].SerialNumbers = lineSerials[i]
 			if err := tx.Save(&do.Items[i]).Error; err != nil {
 				return err
 			}
 		}
 
-		// 2. Update DO status
+		// 3. Give back the stock reserved for the order, booking what was shipped
+		shipped := map[uint]float64{}
+		for _, item := range do.Items {
+			shipped[item.FinishedProductID] += item.Quantity
+		}
+		if err := closeReservations(tx, ReservationForDeliveryOrder, do.ID, shipped); err != nil {
+			return err
+		}
+
+		// 4. Update DO status
 		do.Status = "shipped"
 		do.IsPosted = true
 		do.PostedBy = &userID
@@ -350,6 +413,155 @@ func (s *deliveryOrderService) ShipDeliveryOrder(id uint, req *dto.ShipDeliveryO
 	return s.GetDeliveryOrderByID(id)
 }
 
+// applyPicks replaces the location, batch and quantity of the items with what was confirmed on the
+// pick lists. An item picked from several locations/batches is split into one item per pick and an
+// item nothing was picked for keeps a zero quantity. A kit picked as its components becomes one
+// item per component pick, each remembering the kit it belongs to.
+func (s *deliveryOrderService) applyPicks(tx *gorm.DB, do *models.DeliveryOrder, picks []*models.PickListLine, userID uint) error {
+	byItem := make(map[uint][]*models.PickListLine)
+	var picked float64
+	for _, p := range picks {
+		if p.Status == PickLinePending {
+			return fmt.Errorf("delivery order %s still has lines to pick", do.DONumber)
+		}
+		if p.PickedQuantity > qtyEpsilon {
+			byItem[p.DeliveryOrderItemID] = append(byItem[p.DeliveryOrderItemID], p)
+			picked += p.PickedQuantity
+		}
+	}
+	if picked <= qtyEpsilon {
+		return fmt.Errorf("nothing was picked for delivery order %s", do.DONumber)
+	}
+
+	var splits []models.DeliveryOrderItem
+	for i := range do.Items {
+		item := &do.Items[i]
+		lines := byItem[item.ID]
+		if len(lines) == 0 {
+			item.Quantity = 0
+			item.Notes = strings.TrimSpace(item.Notes + " [short picked]")
+		}
+		for n, p := range lines {
+			target := item
+			if n > 0 {
+				target = &models.DeliveryOrderItem{
+					DeliveryOrderID:   do.ID,
+					FinishedProductID: item.FinishedProductID,
+					Notes:             item.Notes,
+					CreatedBy:         &userID,
+				}
+			}
+			target.FinishedProductID = p.FinishedProductID
+			target.KitProductID = p.KitProductID
+			target.WarehouseLocationID = p.LocationID
+			target.BatchNumber = p.BatchNumber
+			target.LotNumber = p.LotNumber
+			target.ExpiryDate = pickExpiry(p.ExpiryDate)
+			target.Quantity = p.PickedQuantity
+			target.UpdatedBy = &userID
+			if n > 0 {
+				if err := tx.Omit("Product", "Location").Create(target).Error; err != nil {
+					return err
+				}
+				splits = append(splits, *target)
+			}
+		}
+		item.Location = nil
+		item.UpdatedBy = &userID
+		if err := tx.Omit("Product", "Location").Save(item).Error; err != nil {
+			return err
+		}
+	}
+	do.Items = append(do.Items, splits...)
+	return nil
+}
+
+// deliverySerials resolves the serials of a delivery order against the serials in stock in its
+// warehouse and spreads them over the items of each serial-tracked product, one serial per unit
+// shipped. Serials are pooled for the whole order because picking may split an item over several
+// batches or explode a kit into its components.
+func deliverySerials(tx *gorm.DB, do *models.DeliveryOrder, serials []string) ([][]string, error) {
+	out := make([][]string, len(do.Items))
+	var productIDs []uint
+	for _, item := range do.Items {
+		if item.Quantity > qtyEpsilon {
+			productIDs = append(productIDs, item.FinishedProductID)
+		}
+	}
+	productIDs = uniqueUints(productIDs)
+	tracked, err := serialTrackedItems(tx, "finished_product", productIDs)
+	if err != nil {
+		return nil, err
+	}
+	var trackedIDs []uint
+	for _, id := range productIDs {
+		if tracked[id] {
+			trackedIDs = append(trackedIDs, id)
+		}
+	}
+	if len(trackedIDs) == 0 {
+		if len(serials) > 0 {
+			return nil, fmt.Errorf("delivery order %s has no serial-tracked products to take serial numbers", do.DONumber)
+		}
+		return out, nil
+	}
+
+	registered, err := repository.NewSerialRepository(tx).FindInItems("finished_product", trackedIDs, serials)
+	if err != nil {
+		return nil, err
+	}
+	byProduct := make(map[uint][]*models.SerialNumber)
+	found := make(map[string]bool, len(serials))
+	for _, serial := range registered {
+		if serial.Status != SerialInStock || serial.WarehouseID == nil || *serial.WarehouseID != do.WarehouseID {
+			continue
+		}
+		if found[serial.SerialNumber] {
+			return nil, fmt.Errorf("serial %s is in stock for more than one product on the delivery order", serial.SerialNumber)
+		}
+		found[serial.SerialNumber] = true
+		byProduct[serial.ItemID] = append(byProduct[serial.ItemID], serial)
+	}
+	seen := make(map[string]bool, len(serials))
+	for _, s := range serials {
+		if seen[s] {
+			return nil, fmt.Errorf("serial %s is listed twice", s)
+		}
+		seen[s] = true
+		if !found[s] {
+			return nil, fmt.Errorf("serial %s is not in stock in the warehouse for a serial-tracked product on the delivery order", s)
+		}
+	}
+
+	for _, productID := range trackedIDs {
+		var idx []int
+		var slots []serialSlot
+		var qty float64
+		for i, item := range do.Items {
+			if item.FinishedProductID == productID && item.Quantity > qtyEpsilon {
+				idx = append(idx, i)
+				slots = append(slots, serialSlot{Quantity: item.Quantity, BatchNumber: item.BatchNumber, LotNumber: item.LotNumber})
+				qty += item.Quantity
+			}
+		}
+		numbers := make([]string, len(byProduct[productID]))
+		for i, serial := range byProduct[productID] {
+			numbers[i] = serial.SerialNumber
+		}
+		if err := checkSerialCount(true, qty, numbers, fmt.Sprintf("product %d", productID)); err != nil {
+			return nil, err
+		}
+		assigned, err := assignSerials(slots, byProduct[productID])
+		if err != nil {
+			return nil, err
+		}
+		for k, i := range idx {
+			out[i] = assigned[k]
+		}
+	}
+	return out, nil
+}
+
 func (s *deliveryOrderService) CancelDeliveryOrder(id uint, userID uint) (*models.SafeDeliveryOrder, error) {
 	do, err := s.doRepo.GetByID(id)
 	if err != nil {
@@ -362,7 +574,13 @@ func (s *deliveryOrderService) CancelDeliveryOrder(id uint, userID uint) (*model
 
 	do.Status = "cancelled"
 	do.UpdatedBy = &userID
-	if err := s.doRepo.Update(do); err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := closeReservations(tx, ReservationForDeliveryOrder, do.ID, nil); err != nil {
+			return err
+		}
+		return repository.NewDeliveryOrderRepository(tx).Update(do)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,525 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/config"
+	"github.com/VyVy-ERP/warehouse-backend/internal/document"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PODocumentDir is where purchase order attachments are stored (one sub-directory per PO)
+const PODocumentDir = "/app/uploads/po_documents"
+
+// RenderedDocument is a generated PDF ready to be served or stored
+type RenderedDocument struct {
+	FileName string
+	Content  []byte
+}
+
+// DocumentService renders printable PDFs (Vietnamese or English) for warehouse documents
+type DocumentService interface {
+	PurchaseOrderPDF(id uint, lang string) (*RenderedDocument, error)
+	GRNPDF(id uint, lang string) (*RenderedDocument, error)
+	MaterialIssueNotePDF(id uint, lang string) (*RenderedDocument, error)
+	DeliveryOrderPDF(id uint, lang string) (*RenderedDocument, error)
+	StockTransferPDF(id uint, lang string) (*RenderedDocument, error)
+	// AttachPurchaseOrderPDF renders the PO and files it under po_documents
+	AttachPurchaseOrderPDF(poID, userID uint) error
+}
+
+type documentService struct {
+	db      *gorm.DB
+	poRepo  repository.PurchaseOrderRepository
+	grnRepo repository.GoodsReceiptNoteRepository
+	minRepo repository.MaterialIssueNoteRepository
+	doRepo  repository.DeliveryOrderRepository
+	stRepo  repository.StockTransferRepository
+	company config.CompanyConfig
+	lang    string            // default language
+	fonts   *document.FontSet // nil = built-in fonts
+}
+
+// NewDocumentService creates a new DocumentService
+func NewDocumentService(
+	db *gorm.DB,
+	poRepo repository.PurchaseOrderRepository,
+	grnRepo repository.GoodsReceiptNoteRepository,
+	minRepo repository.MaterialIssueNoteRepository,
+	doRepo repository.DeliveryOrderRepository,
+	stRepo repository.StockTransferRepository,
+	company config.CompanyConfig,
+	defaultLang string,
+	fonts *document.FontSet,
+) DocumentService {
+	return &documentService{
+		db:      db,
+		poRepo:  poRepo,
+		grnRepo: grnRepo,
+		minRepo: minRepo,
+		doRepo:  doRepo,
+		stRepo:  stRepo,
+		company: company,
+		lang:    document.NormalizeLang(defaultLang),
+		fonts:   fonts,
+	}
+}
+
+func (s *documentService) newDocument(lang, titleKey, number, date string) *document.Document {
+	if lang == "" {
+		lang = s.lang
+	}
+	lang = document.NormalizeLang(lang)
+	return &document.Document{
+		Lang: lang,
+		Company: document.Company{
+			Name:    s.company.Name,
+			Address: s.company.Address,
+			TaxCode: s.company.TaxCode,
+			Phone:   s.company.Phone,
+		},
+		Title:  document.T(lang, titleKey),
+		Number: number,
+		Date:   document.FormatDate(lang, date),
+	}
+}
+
+func (s *documentService) render(doc *document.Document, number string) (*RenderedDocument, error) {
+	content, err := document.Render(doc, s.fonts)
+	if err != nil {
+		return nil, err
+	}
+	return &RenderedDocument{FileName: number + ".pdf", Content: content}, nil
+}
+
+// docFields builds the info block, skipping empty values
+func docFields(lang string, pairs ...string) []document.Field {
+	fields := make([]document.Field, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			fields = append(fields, document.Field{Label: document.T(lang, pairs[i]), Value: pairs[i+1]})
+		}
+	}
+	return fields
+}
+
+func docColumn(lang, key string, width float64, align document.Align) document.Column {
+	return document.Column{Title: document.T(lang, key), Width: width, Align: align}
+}
+
+func docSignatures(lang string, keys ...string) []string {
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = document.T(lang, k)
+	}
+	return out
+}
+
+func docQty(lang string, q float64) string {
+	return document.FormatNumber(lang, q, 3)
+}
+
+// docMoney prints VND without decimals and foreign currencies with two
+func docMoney(lang string, v float64, currency string) string {
+	if currency == "" || currency == models.BaseCurrency {
+		return document.FormatNumber(lang, v, 0)
+	}
+	return document.FormatNumber(lang, v, 2)
+}
+
+func docDate(lang string, s *string) string {
+	if s == nil {
+		return ""
+	}
+	return document.FormatDate(lang, *s)
+}
+
+func docTime(lang string, t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return document.FormatDate(lang, t.Format("2006-01-02"))
+}
+
+func strValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func (s *documentService) PurchaseOrderPDF(id uint, lang string) (*RenderedDocument, error) {
+	po, err := s.poRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("purchase order not found")
+		}
+		return nil, err
+	}
+	doc := s.newDocument(lang, "title_po", po.PONumber, po.OrderDate)
+	lang = doc.Lang
+	currency := po.Currency
+	if currency == "" {
+		currency = models.BaseCurrency
+	}
+
+	var supplierName, supplierAddress, supplierTax, contact, warehouseName string
+	if po.Supplier != nil {
+		supplierName = po.Supplier.Code + " - " + po.Supplier.Name
+		supplierAddress = strValue(po.Supplier.Address)
+		supplierTax = strValue(po.Supplier.TaxCode)
+		contact = strValue(po.Supplier.ContactPerson)
+		if phone := strValue(po.Supplier.Phone); phone != "" && contact != "" {
+			contact += " - " + phone
+		} else if phone != "" {
+			contact = phone
+		}
+	}
+	if po.Warehouse != nil {
+		warehouseName = po.Warehouse.Name
+	}
+	doc.Fields = docFields(lang,
+		"supplier", supplierName,
+		"warehouse", warehouseName,
+		"supplier_address", supplierAddress,
+		"expected_date", docDate(lang, po.ExpectedDeliveryDate),
+		"supplier_tax_code", supplierTax,
+		"payment_terms", po.PaymentTerms,
+		"contact", contact,
+		"currency", currency,
+	)
+	doc.Columns = []document.Column{
+		docColumn(lang, "col_no", 3, document.AlignCenter),
+		docColumn(lang, "col_code", 8, document.AlignLeft),
+		docColumn(lang, "col_name", 20, document.AlignLeft),
+		docColumn(lang, "col_unit", 5, document.AlignCenter),
+		docColumn(lang, "col_qty", 8, document.AlignRight),
+		docColumn(lang, "col_unit_price", 9, document.AlignRight),
+		docColumn(lang, "col_tax", 5, document.AlignRight),
+		docColumn(lang, "col_amount", 11, document.AlignRight),
+	}
+	for i, item := range po.Items {
+		var code, name, unit string
+		if item.Material != nil {
+			code, name, unit = item.Material.Code, item.Material.TradingName, item.Material.Unit
+		}
+		doc.Rows = append(doc.Rows, []string{
+			fmt.Sprint(i + 1), code, name, unit,
+			docQty(lang, item.Quantity),
+			docMoney(lang, item.UnitPrice, currency),
+			document.FormatNumber(lang, item.TaxRate, 2),
+			docMoney(lang, item.LineTotal, currency),
+		})
+	}
+	doc.Totals = []document.Field{{Label: document.T(lang, "subtotal"), Value: docMoney(lang, po.Subtotal, currency)}}
+	if po.DiscountAmount > 0 {
+		doc.Totals = append(doc.Totals, document.Field{Label: document.T(lang, "discount"), Value: docMoney(lang, po.DiscountAmount, currency)})
+	}
+	doc.Totals = append(doc.Totals,
+		document.Field{Label: document.T(lang, "tax"), Value: docMoney(lang, po.TaxAmount, currency)},
+		document.Field{Label: document.T(lang, "total"), Value: docMoney(lang, po.TotalAmount, currency) + " " + currency},
+	)
+	doc.Notes = po.Notes
+	doc.Signatures = docSignatures(lang, "sign_preparer", "sign_purchasing", "sign_director")
+	return s.render(doc, po.PONumber)
+}
+
+func (s *documentService) GRNPDF(id uint, lang string) (*RenderedDocument, error) {
+	grn, err := s.grnRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("GRN not found")
+		}
+		return nil, err
+	}
+	doc := s.newDocument(lang, "title_grn", grn.GRNNumber, grn.ReceiptDate)
+	lang = doc.Lang
+
+	var poNumber, supplierName, warehouseName string
+	if grn.PurchaseOrder != nil {
+		poNumber = grn.PurchaseOrder.PONumber
+		if grn.PurchaseOrder.Supplier != nil {
+			supplierName = grn.PurchaseOrder.Supplier.Code + " - " + grn.PurchaseOrder.Supplier.Name
+		}
+	}
+	if grn.Warehouse != nil {
+		warehouseName = grn.Warehouse.Name
+	}
+	doc.Fields = docFields(lang,
+		"supplier", supplierName,
+		"warehouse", warehouseName,
+		"po_number", poNumber,
+		"qc_status", grn.QCStatus,
+	)
+	doc.Columns = []document.Column{
+		docColumn(lang, "col_no", 3, document.AlignCenter),
+		docColumn(lang, "col_code", 8, document.AlignLeft),
+		docColumn(lang, "col_name", 17, document.AlignLeft),
+		docColumn(lang, "col_unit", 5, document.AlignCenter),
+		docColumn(lang, "col_batch", 8, document.AlignLeft),
+		docColumn(lang, "col_expiry", 7, document.AlignCenter),
+		docColumn(lang, "col_received", 7, document.AlignRight),
+		docColumn(lang, "col_accepted", 7, document.AlignRight),
+		docColumn(lang, "col_rejected", 7, document.AlignRight),
+		docColumn(lang, "col_location", 7, document.AlignLeft),
+	}
+	var received, accepted float64
+	for i, item := range grn.Items {
+		var code, name, unit, location string
+		if item.Material != nil {
+			code, name, unit = item.Material.Code, item.Material.TradingName, item.Material.Unit
+		}
+		if item.WarehouseLocation != nil {
+			location = item.WarehouseLocation.Code
+		}
+		doc.Rows = append(doc.Rows, []string{
+			fmt.Sprint(i + 1), code, name, unit, item.BatchNumber,
+			docDate(lang, item.ExpiryDate),
+			docQty(lang, item.Quantity),
+			docQty(lang, item.AcceptedQuantity),
+			docQty(lang, item.RejectedQuantity),
+			location,
+		})
+		received += item.Quantity
+		accepted += item.AcceptedQuantity
+	}
+	doc.Totals = []document.Field{
+		{Label: document.T(lang, "total_qty") + " (" + document.T(lang, "col_received") + ")", Value: docQty(lang, received)},
+		{Label: document.T(lang, "total_qty") + " (" + document.T(lang, "col_accepted") + ")", Value: docQty(lang, accepted)},
+	}
+	doc.Notes = grn.Notes
+	doc.Signatures = docSignatures(lang, "sign_preparer", "sign_deliverer", "sign_storekeeper", "sign_accountant")
+	return s.render(doc, grn.GRNNumber)
+}
+
+func (s *documentService) MaterialIssueNotePDF(id uint, lang string) (*RenderedDocument, error) {
+	min, err := s.minRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("material issue note not found")
+		}
+		return nil, err
+	}
+	doc := s.newDocument(lang, "title_min", min.MINNumber, min.IssueDate)
+	lang = doc.Lang
+
+	var warehouseName, planNumber string
+	if min.Warehouse != nil {
+		warehouseName = min.Warehouse.Name
+	}
+	if min.ProductionPlan != nil {
+		planNumber = min.ProductionPlan.PlanNumber
+	}
+	doc.Fields = docFields(lang,
+		"warehouse", warehouseName,
+		"production_plan", planNumber,
+	)
+	doc.Columns = []document.Column{
+		docColumn(lang, "col_no", 3, document.AlignCenter),
+		docColumn(lang, "col_code", 9, document.AlignLeft),
+		docColumn(lang, "col_name", 22, document.AlignLeft),
+		docColumn(lang, "col_unit", 5, document.AlignCenter),
+		docColumn(lang, "col_batch", 9, document.AlignLeft),
+		docColumn(lang, "col_expiry", 8, document.AlignCenter),
+		docColumn(lang, "col_location", 8, document.AlignLeft),
+		docColumn(lang, "col_qty", 8, document.AlignRight),
+	}
+	var total float64
+	for i, item := range min.Items {
+		var code, name, unit, location string
+		if item.Material != nil {
+			code, name, unit = item.Material.Code, item.Material.TradingName, item.Material.Unit
+		}
+		if item.WarehouseLocation != nil {
+			location = item.WarehouseLocation.Code
+		}
+		doc.Rows = append(doc.Rows, []string{
+			fmt.Sprint(i + 1), code, name, unit, item.BatchNumber,
+			docDate(lang, item.ExpiryDate), location,
+			docQty(lang, item.Quantity),
+		})
+		total += item.Quantity
+	}
+	doc.Totals = []document.Field{{Label: document.T(lang, "total_qty"), Value: docQty(lang, total)}}
+	doc.Notes = min.Notes
+	doc.Signatures = docSignatures(lang, "sign_preparer", "sign_receiver", "sign_storekeeper", "sign_accountant")
+	return s.render(doc, min.MINNumber)
+}
+
+func (s *documentService) DeliveryOrderPDF(id uint, lang string) (*RenderedDocument, error) {
+	do, err := s.doRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("delivery order not found")
+		}
+		return nil, err
+	}
+	doc := s.newDocument(lang, "title_do", do.DONumber, do.DeliveryDate.Format("2006-01-02"))
+	lang = doc.Lang
+
+	doc.Fields = docFields(lang,
+		"customer", do.CustomerName,
+		"warehouse", do.Warehouse.Name,
+		"customer_address", do.CustomerAddress,
+		"shipping_method", do.ShippingMethod,
+		"tracking_number", do.TrackingNumber,
+	)
+	doc.Columns = []document.Column{
+		docColumn(lang, "col_no", 3, document.AlignCenter),
+		docColumn(lang, "col_code", 9, document.AlignLeft),
+		docColumn(lang, "col_name", 22, document.AlignLeft),
+		docColumn(lang, "col_unit", 5, document.AlignCenter),
+		docColumn(lang, "col_batch", 9, document.AlignLeft),
+		docColumn(lang, "col_expiry", 8, document.AlignCenter),
+		docColumn(lang, "col_location", 8, document.AlignLeft),
+		docColumn(lang, "col_qty", 8, document.AlignRight),
+	}
+	var total float64
+	for i, item := range do.Items {
+		var location string
+		if item.Location != nil {
+			location = item.Location.Code
+		}
+		doc.Rows = append(doc.Rows, []string{
+			fmt.Sprint(i + 1), item.Product.Code, item.Product.Name, item.Product.Unit, item.BatchNumber,
+			docTime(lang, item.ExpiryDate), location,
+			docQty(lang, item.Quantity),
+		})
+		total += item.Quantity
+	}
+	doc.Totals = []document.Field{{Label: document.T(lang, "total_qty"), Value: docQty(lang, total)}}
+	doc.Notes = do.Notes
+	doc.Signatures = docSignatures(lang, "sign_preparer", "sign_storekeeper", "sign_deliverer", "sign_receiver")
+	return s.render(doc, do.DONumber)
+}
+
+func (s *documentService) StockTransferPDF(id uint, lang string) (*RenderedDocument, error) {
+	st, err := s.stRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("stock transfer not found")
+		}
+		return nil, err
+	}
+	doc := s.newDocument(lang, "title_st", st.TransferNumber, st.TransferDate.Format("2006-01-02"))
+	lang = doc.Lang
+
+	doc.Fields = docFields(lang,
+		"from_warehouse", st.FromWarehouse.Name,
+		"to_warehouse", st.ToWarehouse.Name,
+	)
+	doc.Columns = []document.Column{
+		docColumn(lang, "col_no", 3, document.AlignCenter),
+		docColumn(lang, "col_code", 9, document.AlignLeft),
+		docColumn(lang, "col_name", 20, document.AlignLeft),
+		docColumn(lang, "col_unit", 5, document.AlignCenter),
+		docColumn(lang, "col_batch", 9, document.AlignLeft),
+		docColumn(lang, "col_from", 8, document.AlignLeft),
+		docColumn(lang, "col_to", 8, document.AlignLeft),
+		docColumn(lang, "col_qty", 8, document.AlignRight),
+	}
+
+	items, err := s.transferItemInfo(st.Items)
+	if err != nil {
+		return nil, err
+	}
+	var total float64
+	for i, item := range st.Items {
+		info := items[item.ItemType+":"+fmt.Sprint(item.ItemID)]
+		var from, to string
+		if item.FromLocation != nil {
+			from = item.FromLocation.Code
+		}
+		if item.ToLocation != nil {
+			to = item.ToLocation.Code
+		}
+		doc.Rows = append(doc.Rows, []string{
+			fmt.Sprint(i + 1), info.Code, info.Name, info.Unit, item.BatchNumber, from, to,
+			docQty(lang, item.Quantity),
+		})
+		total += item.Quantity
+	}
+	doc.Totals = []document.Field{{Label: document.T(lang, "total_qty"), Value: docQty(lang, total)}}
+	doc.Notes = st.Notes
+	doc.Signatures = docSignatures(lang, "sign_preparer", "sign_from_store", "sign_deliverer", "sign_to_store")
+	return s.render(doc, st.TransferNumber)
+}
+
+type docItemInfo struct {
+	ID   uint
+	Code string
+	Name string
+	Unit string
+}
+
+// transferItemInfo loads code/name/unit for transfer lines, keyed by "item_type:item_id"
+func (s *documentService) transferItemInfo(items []models.StockTransferItem) (map[string]docItemInfo, error) {
+	var materialIDs, productIDs []uint
+	for _, item := range items {
+		if item.ItemType == "finished_product" {
+			productIDs = append(productIDs, item.ItemID)
+		} else {
+			materialIDs = append(materialIDs, item.ItemID)
+		}
+	}
+	out := make(map[string]docItemInfo)
+	if len(materialIDs) > 0 {
+		var rows []docItemInfo
+		if err := s.db.Model(&models.Material{}).
+			Select("id, code, trading_name AS name, unit").
+			Where("id IN ?", uniqueUints(materialIDs)).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			out["material:"+fmt.Sprint(r.ID)] = r
+		}
+	}
+	if len(productIDs) > 0 {
+		var rows []docItemInfo
+		if err := s.db.Model(&models.FinishedProduct{}).
+			Select("id, code, name, unit").
+			Where("id IN ?", uniqueUints(productIDs)).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			out["finished_product:"+fmt.Sprint(r.ID)] = r
+		}
+	}
+	return out, nil
+}
+
+func (s *documentService) AttachPurchaseOrderPDF(poID, userID uint) error {
+	rendered, err := s.PurchaseOrderPDF(poID, "")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(PODocumentDir, fmt.Sprint(poID))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	storedName := fmt.Sprintf("%d_%s", time.Now().UnixNano(), rendered.FileName)
+	path := filepath.Join(dir, storedName)
+	if err := os.WriteFile(path, rendered.Content, 0644); err != nil {
+		return err
+	}
+
+	doc := models.PODocument{
+		POID:         poID,
+		FileName:     fmt.Sprintf("%d/%s", poID, storedName),
+		OriginalName: rendered.FileName,
+		FileSize:     int64(len(rendered.Content)),
+		MimeType:     "application/pdf",
+		UploadedBy:   &userID,
+	}
+	if err := s.db.Create(&doc).Error; err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ExchangeRateService maintains dated exchange rates and converts document amounts to VND
+type ExchangeRateService interface {
+	List(filters map[string]interface{}, offset, limit int) ([]*models.ExchangeRate, int64, error)
+	Upsert(req *dto.UpsertExchangeRateRequest, userID uint, username string) (*models.ExchangeRate, error)
+	Delete(id uint, userID uint, username string) error
+	// Import reads a CSV file with columns currency,rate_date,rate (header optional)
+	Import(r io.Reader, userID uint, username string) (*dto.ExchangeRateImportResult, error)
+	// GetRate returns VND per unit of currency effective on date (1 for VND)
+	GetRate(currency string, date string) (float64, error)
+}
+
+type exchangeRateService struct {
+	repo     repository.ExchangeRateRepository
+	auditSvc AuditLogService
+}
+
+func NewExchangeRateService(repo repository.ExchangeRateRepository, auditSvc AuditLogService) ExchangeRateService {
+	return &exchangeRateService{repo: repo, auditSvc: auditSvc}
+}
+
+func (s *exchangeRateService) List(filters map[string]interface{}, offset, limit int) ([]*models.ExchangeRate, int64, error) {
+	return s.repo.List(filters, offset, limit)
+}
+
+func (s *exchangeRateService) Upsert(req *dto.UpsertExchangeRateRequest, userID uint, username string) (*models.ExchangeRate, error) {
+	rate, err := buildExchangeRate(req.Currency, req.RateDate, req.Rate)
+	if err != nil {
+		return nil, err
+	}
+	rate.Source = "manual"
+	rate.Notes = req.Notes
+	rate.CreatedBy = &userID
+	rate.UpdatedBy = &userID
+	if err := s.repo.Upsert(rate); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("exchange_rates", "UPSERT", int64(rate.ID), int64(userID), username, nil, rate)
+	return rate, nil
+}
+
+func (s *exchangeRateService) Delete(id uint, userID uint, username string) error {
+	old, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("exchange rate not found")
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	_ = s.auditSvc.Log("exchange_rates", "DELETE", int64(id), int64(userID), username, old, nil)
+	return nil
+}
+
+func (s *exchangeRateService) Import(r io.Reader, userID uint, username string) (*dto.ExchangeRateImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV file: %w", err)
+	}
+
+	result := &dto.ExchangeRateImportResult{}
+	for i, rec := range records {
+		line := i + 1
+		if len(rec) < 3 {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: expected currency,rate_date,rate", line))
+			continue
+		}
+		if i == 0 && strings.EqualFold(strings.TrimSpace(rec[0]), "currency") {
+			continue // header
+		}
+		value, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(rec[2]), ",", ""), 64)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: invalid rate %q", line, rec[2]))
+			continue
+		}
+		rate, err := buildExchangeRate(rec[0], rec[1], value)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: %s", line, err.Error()))
+			continue
+		}
+		rate.Source = "import"
+		rate.CreatedBy = &userID
+		rate.UpdatedBy = &userID
+		if err := s.repo.Upsert(rate); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: %s", line, err.Error()))
+			continue
+		}
+		result.Imported++
+	}
+
+	_ = s.auditSvc.Log("exchange_rates", "IMPORT", 0, int64(userID), username, nil, result)
+	return result, nil
+}
+
+func (s *exchangeRateService) GetRate(currency string, date string) (float64, error) {
+	currency = NormalizeCurrency(currency)
+	if currency == models.BaseCurrency {
+		return 1, nil
+	}
+	date = firstN(date, 10)
+	rate, err := s.repo.GetEffective(currency, date)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, fmt.Errorf("no %s exchange rate on or before %s", currency, date)
+		}
+		return 0, err
+	}
+	return rate.Rate, nil
+}
+
+func buildExchangeRate(currency, rateDate string, value float64) (*models.ExchangeRate, error) {
+	currency = NormalizeCurrency(currency)
+	if len(currency) != 3 {
+		return nil, errors.New("currency must be a 3-letter ISO code")
+	}
+	if currency == models.BaseCurrency {
+		return nil, errors.New("VND is the base currency and has no exchange rate")
+	}
+	rateDate = strings.TrimSpace(rateDate)
+	if _, err := time.Parse("2006-01-02", rateDate); err != nil {
+		return nil, errors.New("invalid rate_date format, use YYYY-MM-DD")
+	}
+	if value <= 0 {
+		return nil, errors.New("rate must be greater than 0")
+	}
+	return &models.ExchangeRate{Currency: currency, RateDate: rateDate, Rate: value}, nil
+}
+
+// NormalizeCurrency upper-cases an ISO code and defaults empty values to VND
+func NormalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return models.BaseCurrency
+	}
+	return currency
+}
+
+// toBase converts a document-currency amount to VND
+func toBase(amount, rate float64) float64 {
+	return roundMoney(amount * rate)
+}
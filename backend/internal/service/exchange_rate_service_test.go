@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildExchangeRate(t *testing.T) {
+	rate, err := buildExchangeRate(" usd ", "2026-03-01", 25450)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", rate.Currency)
+	assert.Equal(t, "2026-03-01", rate.RateDate)
+
+	_, err = buildExchangeRate("VND", "2026-03-01", 1)
+	assert.Error(t, err, "base currency has no rate")
+	_, err = buildExchangeRate("EURO", "2026-03-01", 27000)
+	assert.Error(t, err)
+	_, err = buildExchangeRate("EUR", "01/03/2026", 27000)
+	assert.Error(t, err)
+	_, err = buildExchangeRate("EUR", "2026-03-01", 0)
+	assert.Error(t, err)
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	assert.Equal(t, "VND", NormalizeCurrency(""))
+	assert.Equal(t, "EUR", NormalizeCurrency(" eur"))
+}
+
+func TestRealizedFX(t *testing.T) {
+	// Booked at 25,000, paid at 25,400: 400 VND loss per USD
+	base, booked, diff := realizedFX(1000, 25400, 25000)
+	assert.Equal(t, 25400000.0, base)
+	assert.Equal(t, 25000000.0, booked)
+	assert.Equal(t, 400000.0, diff)
+
+	// Rate fell: gain
+	_, _, diff = realizedFX(1000, 24800, 25000)
+	assert.Equal(t, -200000.0, diff)
+
+	// VND payment has no difference
+	_, _, diff = realizedFX(5000000, 1, 1)
+	assert.Zero(t, diff)
+}
@@ -25,6 +25,7 @@ type fprnService struct {
 	stockBalanceRepo repository.StockBalanceRepository
 	ppRepo           repository.ProductionPlanRepository
 	db               *gorm.DB
+	putawaySvc       PutawayService // optional: putaway tasks for products received without a storage location
 }
 
 func NewFinishedProductReceiptService(
@@ -33,6 +34,7 @@ func NewFinishedProductReceiptService(
 	stockBalanceRepo repository.StockBalanceRepository,
 	ppRepo repository.ProductionPlanRepository,
 	db *gorm.DB,
+	putawaySvc PutawayService,
 ) FinishedProductReceiptService {
 	return &fprnService{
 		repo:             repo,
@@ -40,6 +42,7 @@ func NewFinishedProductReceiptService(
 		stockBalanceRepo: stockBalanceRepo,
 		ppRepo:           ppRepo,
 		db:               db,
+		putawaySvc:       putawaySvc,
 	}
 }
 
@@ -69,6 +72,9 @@ func (s *fprnService) Create(fprn *models.FinishedProductReceipt) (*models.Finis
 	fprn.FPRNNumber = number
 	fprn.Status = "draft"
 	fprn.Posted = false
+	for _, item := range fprn.Items {
+		item.SerialNumbers = cleanSerials(item.SerialNumbers)
+	}
 
 	if err := s.repo.Create(fprn); err != nil {
 		return nil, err
@@ -103,10 +109,35 @@ func (s *fprnService) Post(id uint, userID uint) error {
 		txLedger := repository.NewStockLedgerRepository(tx)
 		txBalance := repository.NewStockBalanceRepository(tx)
 
+		if err := checkWarehouseNotFrozen(tx, fprn.WarehouseID); err != nil {
+			return err
+		}
+
+		var placements []StoragePlacement
+		for _, item := range fprn.Items {
+			placements = addPlacement(placements, item.WarehouseLocationID, "finished_product", item.FinishedProductID, item.Quantity)
+		}
+		if err := checkStoragePlacements(tx, placements); err != nil {
+			return err
+		}
+
+		// Serial-tracked products need one serial per unit received
+		productIDs := make([]uint, 0, len(fprn.Items))
+		for _, item := range fprn.Items {
+			productIDs = append(productIDs, item.FinishedProductID)
+		}
+		tracked, err := serialTrackedItems(tx, "finished_product", productIDs)
+		if err != nil {
+			return err
+		}
+
 		for _, item := range fprn.Items {
 			if item.Quantity <= 0 {
 				continue
 			}
+			if err := checkSerialCount(tracked[item.FinishedProductID], item.Quantity, item.SerialNumbers, fmt.Sprintf("FPRN item %d", item.ID)); err != nil {
+				return err
+			}
 
 			// 1. Get latest balance for ledger continuity
 			prevBalance, err := txLedger.GetLatestBalance(
@@ -146,6 +177,9 @@ func (s *fprnService) Post(id uint, userID uint) error {
 			if err := txLedger.Create(ledgerEntry); err != nil {
 				return fmt.Errorf("error creating ledger entry for product %d: %w", item.FinishedProductID, err)
 			}
+			if err := postSerials(tx, ledgerEntry, item.SerialNumbers, serialReceive); err != nil {
+				return fmt.Errorf("FPRN item %d: %w", item.ID, err)
+			}
 
 			// 3. Upsert Stock Balance (weighted average cost)
 			existingBalance, _ := txBalance.Get(
@@ -194,6 +228,12 @@ func (s *fprnService) Post(id uint, userID uint) error {
 			}
 		}
 
+		if s.putawaySvc != nil {
+			if err := s.putawaySvc.CreateTasks(tx, putawayLinesForFPRN(fprn), userID); err != nil {
+				return err
+			}
+		}
+
 		// 4. Mark FPRN as posted
 		if err := tx.Model(&models.FinishedProductReceipt{}).Where("id = ?", id).Updates(map[string]interface{}{
 			"posted":    true,
@@ -59,12 +59,15 @@ func (s *finishedProductService) CreateFinishedProduct(req *dto.CreateFinishedPr
 		NetWeight:         req.NetWeight,
 		GrossWeight:       req.GrossWeight,
 		Volume:            req.Volume,
+		UnitVolumeM3:      req.UnitVolumeM3,
+		UnitsPerPallet:    req.UnitsPerPallet,
 		MinStockLevel:     req.MinStockLevel,
 		MaxStockLevel:     req.MaxStockLevel,
 		ReorderPoint:      req.ReorderPoint,
 		ShelfLifeDays:     req.ShelfLifeDays,
 		StorageConditions: req.StorageConditions,
 		Barcode:           req.Barcode,
+		SerialTracked:     req.SerialTracked,
 		IsActive:          req.IsActive,
 		Notes:             req.Notes,
 		CreatedBy:         &userID,
@@ -184,6 +187,12 @@ func (s *finishedProductService) UpdateFinishedProduct(id uint, req *dto.UpdateF
 	if req.Volume != nil {
 		product.Volume = req.Volume
 	}
+	if req.UnitVolumeM3 != nil {
+		product.UnitVolumeM3 = req.UnitVolumeM3
+	}
+	if req.UnitsPerPallet != nil {
+		product.UnitsPerPallet = req.UnitsPerPallet
+	}
 
 	if req.MinStockLevel != nil {
 		product.MinStockLevel = req.MinStockLevel
@@ -209,6 +218,10 @@ func (s *finishedProductService) UpdateFinishedProduct(id uint, req *dto.UpdateF
 		product.Barcode = req.Barcode
 	}
 
+	if req.SerialTracked != nil {
+		product.SerialTracked = *req.SerialTracked
+	}
+
 	if req.IsActive != nil {
 		product.IsActive = *req.IsActive
 	}
@@ -31,6 +31,9 @@ type grnService struct {
 	stockBalanceRepo  repository.StockBalanceRepository
 	ppRepo            repository.ProductionPlanRepository // for KHSX status hooks
 	auditSvc          AuditLogService
+	complianceSvc     SupplierComplianceService // mandatory supplier documents gate
+	fxSvc             ExchangeRateService       // converts foreign currency costs to VND at posting
+	putawaySvc        PutawayService            // optional: putaway tasks for stock received without a storage location
 }
 
 // NewGRNService creates a new GRNService
@@ -45,6 +48,9 @@ func NewGRNService(
 	stockBalanceRepo repository.StockBalanceRepository,
 	ppRepo repository.ProductionPlanRepository,
 	auditSvc AuditLogService,
+	complianceSvc SupplierComplianceService,
+	fxSvc ExchangeRateService,
+	putawaySvc PutawayService,
 ) GRNService {
 	return &grnService{
 		db:               db,
@@ -57,6 +63,9 @@ func NewGRNService(
 		stockBalanceRepo: stockBalanceRepo,
 		ppRepo:           ppRepo,
 		auditSvc:         auditSvc,
+		complianceSvc:    complianceSvc,
+		fxSvc:            fxSvc,
+		putawaySvc:       putawaySvc,
 	}
 }
 
@@ -68,6 +77,7 @@ func (s *grnService) CreateGRN(req *dto.CreateGRNRequest, userID uint, username
 	}
 
 	// Validate PO exists and is approved (if PO is specified)
+	currency := models.BaseCurrency
 	if req.PurchaseOrderID > 0 {
 		po, err := s.poRepo.GetByID(req.PurchaseOrderID)
 		if err != nil {
@@ -76,6 +86,24 @@ func (s *grnService) CreateGRN(req *dto.CreateGRNRequest, userID uint, username
 		if po.Status != "approved" {
 			return nil, errors.New("can only create GRN for approved purchase orders")
 		}
+		// Unit costs are entered in the PO currency
+		currency = NormalizeCurrency(po.Currency)
+
+		// Quantities must stay within the open PO quantity plus tolerance, counting unposted GRNs
+		if err := s.validateReceiptQuantities(po, req.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	// A replacement delivery must match a posted purchase return still awaiting goods
+	if req.PurchaseReturnID != nil {
+		ret, err := repository.NewPurchaseReturnRepository(s.db).GetByID(*req.PurchaseReturnID)
+		if err != nil {
+			return nil, errors.New("purchase return not found")
+		}
+		if err := validateReplacementReturn(ret, req.PurchaseOrderID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Validate warehouse exists
@@ -85,13 +113,16 @@ func (s *grnService) CreateGRN(req *dto.CreateGRNRequest, userID uint, username
 	}
 
 	grn := &models.GoodsReceiptNote{
-		GRNNumber:       req.GRNNumber,
-		WarehouseID:     req.WarehouseID,
-		ReceiptDate:     req.ReceiptDate,
-		Status:          "pending_qc",
-		Notes:           req.Notes,
-		CreatedBy:       &userID,
-		UpdatedBy:       &userID,
+		GRNNumber:        req.GRNNumber,
+		WarehouseID:      req.WarehouseID,
+		ReceiptDate:      req.ReceiptDate,
+		Status:           "pending_qc",
+		Currency:         currency,
+		ExchangeRate:     1,
+		PurchaseReturnID: req.PurchaseReturnID,
+		Notes:            req.Notes,
+		CreatedBy:        &userID,
+		UpdatedBy:        &userID,
 	}
 	if req.PurchaseOrderID > 0 {
 		grn.PurchaseOrderID = &req.PurchaseOrderID
@@ -117,6 +148,7 @@ func (s *grnService) CreateGRN(req *dto.CreateGRNRequest, userID uint, username
 				UnitCost:            itemReq.UnitCost,
 				BatchNumber:         itemReq.BatchNumber,
 				LotNumber:           itemReq.LotNumber,
+				SerialNumbers:       cleanSerials(itemReq.SerialNumbers),
 				Notes:               itemReq.Notes,
 				CreatedBy:           &userID,
 				UpdatedBy:           &userID,
@@ -190,6 +222,18 @@ func (s *grnService) UpdateQC(id uint, req *dto.UpdateGRNQCRequest, userID uint,
 		return nil, errors.New("cannot update QC for posted GRN")
 	}
 
+	// A line whose batch failed its spec inspection cannot be released as pass
+	qcRepo := repository.NewQCInspectionRepository(s.db)
+	for itemID, qcReq := range req.Items {
+		if qcReq.QCStatus != "pass" {
+			continue
+		}
+		inspection, err := qcRepo.LatestCompletedByGRNItem(itemID)
+		if err == nil && inspection.Result == QCResultFail {
+			return nil, fmt.Errorf("GRN item %d failed inspection %s; it cannot be marked pass", itemID, inspection.InspectionNumber)
+		}
+	}
+
 	now := time.Now()
 	// Overall status for GRN based on items
 	overallQCStatus := "pass"
@@ -205,6 +249,12 @@ func (s *grnService) UpdateQC(id uint, req *dto.UpdateGRNQCRequest, userID uint,
 			if err := txGRNItemRepo.UpdateQC(itemID, qcReq.ReceivedQuantity, qcReq.AcceptedQuantity, qcReq.RejectedQuantity, qcReq.QCStatus, qcReq.QCNotes); err != nil {
 				return err
 			}
+			if qcReq.SerialNumbers != nil {
+				if err := tx.Model(&models.GoodsReceiptNoteItem{}).Where("id = ? AND grn_id = ?", itemID, id).
+					Update("serial_numbers", models.StringSlice(cleanSerials(qcReq.SerialNumbers))).Error; err != nil {
+					return err
+				}
+			}
 		}
 
 		if err := txGRNRepo.UpdateQC(id, overallQCStatus, userID, now, req.Notes); err != nil {
@@ -258,6 +308,33 @@ func (s *grnService) UpdateQC(id uint, req *dto.UpdateGRNQCRequest, userID uint,
 	return grn.ToSafe(), nil
 }
 
+// validateReceiptQuantities checks new GRN lines against their PO lines and receiving tolerances
+func (s *grnService) validateReceiptQuantities(po *models.PurchaseOrder, items []dto.CreateGRNItemRequest) error {
+	poItems := make(map[uint]*models.PurchaseOrderItem, len(po.Items))
+	for _, item := range po.Items {
+		poItems[item.ID] = item
+	}
+	requested := make(map[uint]float64, len(items))
+	for _, itemReq := range items {
+		poItem, ok := poItems[itemReq.POItemID]
+		if !ok {
+			return fmt.Errorf("PO item %d does not belong to purchase order %s", itemReq.POItemID, po.PONumber)
+		}
+		if poItem.MaterialID != itemReq.MaterialID {
+			return fmt.Errorf("%s: material does not match the PO line", poLineLabel(poItem))
+		}
+		unposted, err := s.grnItemRepo.SumUnpostedByPOItem(poItem.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkReceivable(po, poItem, poItem.ReceivedQuantity+unposted+requested[poItem.ID], itemReq.Quantity); err != nil {
+			return err
+		}
+		requested[poItem.ID] += itemReq.Quantity
+	}
+	return nil
+}
+
 func (s *grnService) PostGRN(id uint, userID uint, username string) (*models.SafeGoodsReceiptNote, error) {
 	grn, err := s.grnRepo.GetByID(id)
 	if err != nil {
@@ -272,6 +349,57 @@ func (s *grnService) PostGRN(id uint, userID uint, username string) (*models.Saf
 		return nil, errors.New("GRN is already posted")
 	}
 
+	// Block posting when mandatory supplier documents (e.g. COA per material) are missing or expired
+	if s.complianceSvc != nil && grn.PurchaseOrder != nil {
+		materialIDs := make([]uint, 0, len(grn.Items))
+		for _, item := range grn.Items {
+			if item.AcceptedQuantity > 0 {
+				materialIDs = append(materialIDs, item.MaterialID)
+			}
+		}
+		if err := s.complianceSvc.EnsureCompliant(grn.PurchaseOrder.SupplierID, materialIDs, ComplianceStageGRNPost); err != nil {
+			return nil, err
+		}
+	}
+
+	// Accepted quantities must still fit the PO lines (other GRNs may have been posted meanwhile)
+	if grn.PurchaseOrderID != nil {
+		po, err := s.poRepo.GetByID(*grn.PurchaseOrderID)
+		if err != nil {
+			return nil, errors.New("purchase order not found")
+		}
+		poItems := make(map[uint]*models.PurchaseOrderItem, len(po.Items))
+		for _, item := range po.Items {
+			poItems[item.ID] = item
+		}
+		accepted := make(map[uint]float64, len(grn.Items))
+		for _, item := range grn.Items {
+			if item.AcceptedQuantity <= 0 {
+				continue
+			}
+			poItem, ok := poItems[item.POItemID]
+			if !ok {
+				return nil, fmt.Errorf("PO item %d does not belong to purchase order %s", item.POItemID, po.PONumber)
+			}
+			if err := checkReceivable(po, poItem, poItem.ReceivedQuantity+accepted[poItem.ID], item.AcceptedQuantity); err != nil {
+				return nil, err
+			}
+			accepted[poItem.ID] += item.AcceptedQuantity
+		}
+	}
+
+	// Inventory is costed in VND: fix the exchange rate on the receipt date
+	exchangeRate := 1.0
+	if currency := NormalizeCurrency(grn.Currency); currency != models.BaseCurrency {
+		if s.fxSvc == nil {
+			return nil, errors.New("exchange rates are not available")
+		}
+		exchangeRate, err = s.fxSvc.GetRate(currency, grn.ReceiptDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	now := time.Now()
 
 	err = s.db.Transaction(func(tx *gorm.DB) error {
@@ -279,11 +407,49 @@ func (s *grnService) PostGRN(id uint, userID uint, username string) (*models.Saf
 		txStockLedgerRepo := repository.NewStockLedgerRepository(tx)
 		txStockBalanceRepo := repository.NewStockBalanceRepository(tx)
 
+		if err := tx.Model(&models.GoodsReceiptNote{}).Where("id = ?", id).
+			Update("exchange_rate", exchangeRate).Error; err != nil {
+			return err
+		}
+
+		if err := checkWarehouseNotFrozen(tx, grn.WarehouseID); err != nil {
+			return err
+		}
+
+		// Zoning, storage conditions and capacity of the receiving locations
+		var placements []StoragePlacement
+		for _, item := range grn.Items {
+			placements = addPlacement(placements, item.WarehouseLocationID, "material", item.MaterialID, item.AcceptedQuantity)
+		}
+		if err := checkStoragePlacements(tx, placements); err != nil {
+			return err
+		}
+
+		// Serial-tracked materials need one serial per accepted unit
+		materialIDs := make([]uint, 0, len(grn.Items))
+		for _, item := range grn.Items {
+			materialIDs = append(materialIDs, item.MaterialID)
+		}
+		tracked, err := serialTrackedItems(tx, "material", materialIDs)
+		if err != nil {
+			return err
+		}
+
 		for _, item := range grn.Items {
 			// Only post accepted quantity
 			if item.AcceptedQuantity <= 0 {
 				continue
 			}
+			if err := checkSerialCount(tracked[item.MaterialID], item.AcceptedQuantity, item.SerialNumbers, fmt.Sprintf("GRN item %d", item.ID)); err != nil {
+				return err
+			}
+
+			// Unit cost in VND for ledger and balance
+			baseUnitCost := toBase(item.UnitCost, exchangeRate)
+			if err := tx.Model(&models.GoodsReceiptNoteItem{}).Where("id = ?", item.ID).
+				Update("base_unit_cost", baseUnitCost).Error; err != nil {
+				return err
+			}
 
 			// 1. Get latest balance for ledger entry
 			prevBalance, err := txStockLedgerRepo.GetLatestBalance("material", item.MaterialID, grn.WarehouseID, item.WarehouseLocationID, item.BatchNumber, item.LotNumber)
@@ -306,8 +472,8 @@ func (s *grnService) PostGRN(id uint, userID uint, username string) (*models.Saf
 				LotNumber:           item.LotNumber,
 				ExpiryDate:          item.ExpiryDate,
 				Quantity:            item.AcceptedQuantity,
-				UnitCost:            item.UnitCost,
-				TotalCost:           item.AcceptedQuantity * item.UnitCost,
+				UnitCost:            baseUnitCost,
+				TotalCost:           item.AcceptedQuantity * baseUnitCost,
 				BalanceQuantity:     newBalance,
 				ReferenceType:       "GRN",
 				ReferenceID:         grn.ID,
@@ -316,6 +482,9 @@ func (s *grnService) PostGRN(id uint, userID uint, username string) (*models.Saf
 			if err := txStockLedgerRepo.Create(ledgerEntry); err != nil {
 				return err
 			}
+			if err := postSerials(tx, ledgerEntry, item.SerialNumbers, serialReceive); err != nil {
+				return fmt.Errorf("GRN item %d: %w", item.ID, err)
+			}
 
 			// 3. Update Stock Balance (Upsert)
 			balance, err := txStockBalanceRepo.Get("material", item.MaterialID, grn.WarehouseID, item.WarehouseLocationID, item.BatchNumber, item.LotNumber)
@@ -335,14 +504,14 @@ func (s *grnService) PostGRN(id uint, userID uint, username string) (*models.Saf
 					ManufactureDate:     item.ManufactureDate,
 					ExpiryDate:          item.ExpiryDate,
 					Quantity:            item.AcceptedQuantity,
-					UnitCost:            item.UnitCost,
-					TotalCost:           item.AcceptedQuantity * item.UnitCost,
+					UnitCost:            baseUnitCost,
+					TotalCost:           item.AcceptedQuantity * baseUnitCost,
 					LastTransactionDate: &now,
 				}
 			} else {
 				// Update existing balance (using weighted average cost if possible, simple update for now)
 				// total_cost = (prev_qty * prev_cost) + (new_qty * new_cost)
-				newTotalCost := balance.TotalCost + (item.AcceptedQuantity * item.UnitCost)
+				newTotalCost := balance.TotalCost + (item.AcceptedQuantity * baseUnitCost)
 				newTotalQty := balance.Quantity + item.AcceptedQuantity
 				
 				balance.Quantity = newTotalQty
@@ -365,6 +534,20 @@ func (s *grnService) PostGRN(id uint, userID uint, username string) (*models.Saf
 			}
 		}
 
+		// Replacement delivery: count accepted goods against the purchase return
+		if grn.PurchaseReturnID != nil {
+			if err := applyReplacementReceipt(tx, *grn.PurchaseReturnID, grn.Items, userID); err != nil {
+				return err
+			}
+		}
+
+		// Stock left at "no location" or in a receiving area gets a putaway task
+		if s.putawaySvc != nil {
+			if err := s.putawaySvc.CreateTasks(tx, putawayLinesForGRN(grn), userID); err != nil {
+				return err
+			}
+		}
+
 		// 5. Update GRN status to posted
 		if err := txGRNRepo.UpdatePosting(id, userID, now); err != nil {
 			return err
@@ -382,9 +565,9 @@ func (s *grnService) PostGRN(id uint, userID uint, username string) (*models.Saf
 		return nil, err
 	}
 
-	// B7: Auto-complete PO if all items fully received
+	// B7: Maintain PO receipt status; auto-complete when every line is received or closed short
 	if grn.PurchaseOrderID != nil {
-		_ = s.poRepo.CompleteIfFullyReceived(*grn.PurchaseOrderID, userID)
+		_ = refreshReceiptStatus(s.poRepo, *grn.PurchaseOrderID, userID)
 		// Hook: tìm KHSX liên kết với PO này, cập nhật procurement_status='receiving'
 		if s.ppRepo != nil {
 			po, err2 := s.poRepo.GetByID(*grn.PurchaseOrderID)
@@ -0,0 +1,724 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Kit order types and statuses
+const (
+	KitOrderAssembly    = "assembly"
+	KitOrderDisassembly = "disassembly"
+
+	KitOrderDraft     = "draft"
+	KitOrderPosted    = "posted"
+	KitOrderCancelled = "cancelled"
+)
+
+// KitService manages kit definitions and the orders that assemble kits from their components or
+// take them apart again
+type KitService interface {
+	CreateKit(req *dto.CreateKitRequest, userID uint, username string) (*models.Kit, error)
+	UpdateKit(id uint, req *dto.UpdateKitRequest, userID uint, username string) (*models.Kit, error)
+	GetKit(id uint) (*models.Kit, error)
+	ListKits(filters map[string]interface{}, offset, limit int) ([]*models.Kit, int64, error)
+
+	CreateOrder(req *dto.CreateKitOrderRequest, userID uint, username string) (*models.KitOrder, error)
+	GetOrder(id uint) (*models.KitOrder, error)
+	ListOrders(filters map[string]interface{}, offset, limit int) ([]*models.KitOrder, int64, error)
+	PostOrder(id uint, userID uint, username string) (*models.KitOrder, error)
+	CancelOrder(id uint, userID uint, username string) (*models.KitOrder, error)
+}
+
+type kitService struct {
+	db       *gorm.DB
+	repo     repository.KitRepository
+	auditSvc AuditLogService
+}
+
+func NewKitService(db *gorm.DB, repo repository.KitRepository, auditSvc AuditLogService) KitService {
+	return &kitService{db: db, repo: repo, auditSvc: auditSvc}
+}
+
+// generateKitOrderNumber creates a number like KO-2026-000001
+func generateKitOrderNumber(repo repository.KitRepository) (string, error) {
+	prefix := fmt.Sprintf("KO-%s-", time.Now().Format("2006"))
+	count, err := repo.CountByOrderNumber(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%06d", prefix, count+1), nil
+}
+
+// validateKitComponents checks that a kit is not a component of itself and lists each component once
+func validateKitComponents(kitProductID uint, components []dto.KitComponentRequest) error {
+	if len(components) == 0 {
+		return errors.New("a kit needs at least one component")
+	}
+	seen := make(map[uint]bool, len(components))
+	for i, c := range components {
+		if c.ComponentProductID == kitProductID {
+			return fmt.Errorf("component %d: a kit cannot contain itself", i+1)
+		}
+		if seen[c.ComponentProductID] {
+			return fmt.Errorf("component %d: product %d is listed twice", i+1, c.ComponentProductID)
+		}
+		seen[c.ComponentProductID] = true
+	}
+	return nil
+}
+
+// checkKitProducts checks the products of a kit against the database: they exist, the components
+// are not kits themselves and the kit is not a component of another kit (kits are one level deep)
+func checkKitProducts(repo repository.KitRepository, kitID, kitProductID uint, components []dto.KitComponentRequest) error {
+	ids := []uint{kitProductID}
+	for _, c := range components {
+		ids = append(ids, c.ComponentProductID)
+	}
+	products, err := repo.GetProducts(ids)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if products[id] == nil {
+			return fmt.Errorf("finished product %d not found", id)
+		}
+	}
+	kitIDs, err := repo.KitProductIDs(ids[1:])
+	if err != nil {
+		return err
+	}
+	if len(kitIDs) > 0 {
+		return fmt.Errorf("component %s is a kit itself", products[kitIDs[0]].Code)
+	}
+	usedBy, err := repo.KitsUsingComponent(kitProductID, kitID)
+	if err != nil {
+		return err
+	}
+	if len(usedBy) > 0 {
+		return fmt.Errorf("%s is a component of another kit and cannot be a kit itself", products[kitProductID].Code)
+	}
+	return nil
+}
+
+func kitComponents(reqs []dto.KitComponentRequest) []*models.KitComponent {
+	components := make([]*models.KitComponent, 0, len(reqs))
+	for i, r := range reqs {
+		components = append(components, &models.KitComponent{
+			ComponentProductID: r.ComponentProductID,
+			Quantity:           roundQty(r.Quantity),
+			Sequence:           i + 1,
+			Notes:              r.Notes,
+		})
+	}
+	return components
+}
+
+func (s *kitService) CreateKit(req *dto.CreateKitRequest, userID uint, username string) (*models.Kit, error) {
+	if err := validateKitComponents(req.KitProductID, req.Components); err != nil {
+		return nil, err
+	}
+	kit := &models.Kit{
+		KitProductID:  req.KitProductID,
+		ExplodeOnPick: req.ExplodeOnPick,
+		IsActive:      req.IsActive == nil || *req.IsActive,
+		Notes:         req.Notes,
+		CreatedBy:     &userID,
+		UpdatedBy:     &userID,
+		Components:    kitComponents(req.Components),
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewKitRepository(tx)
+		if ids, err := txRepo.KitProductIDs([]uint{req.KitProductID}); err != nil {
+			return err
+		} else if len(ids) > 0 {
+			return errors.New("the product already has a kit definition")
+		}
+		if err := checkKitProducts(txRepo, 0, req.KitProductID, req.Components); err != nil {
+			return err
+		}
+		return txRepo.Create(kit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("kits", "CREATE", int64(kit.ID), int64(userID), username, nil, map[string]interface{}{
+		"kit_product_id":  kit.KitProductID,
+		"components":      len(kit.Components),
+		"explode_on_pick": kit.ExplodeOnPick,
+	})
+	return s.repo.GetByID(kit.ID)
+}
+
+func (s *kitService) UpdateKit(id uint, req *dto.UpdateKitRequest, userID uint, username string) (*models.Kit, error) {
+	kit, err := s.GetKit(id)
+	if err != nil {
+		return nil, err
+	}
+	old := map[string]interface{}{
+		"explode_on_pick": kit.ExplodeOnPick,
+		"is_active":       kit.IsActive,
+		"components":      len(kit.Components),
+	}
+
+	if req.ExplodeOnPick != nil {
+		kit.ExplodeOnPick = *req.ExplodeOnPick
+	}
+	if req.IsActive != nil {
+		kit.IsActive = *req.IsActive
+	}
+	if req.Notes != nil {
+		kit.Notes = *req.Notes
+	}
+	kit.UpdatedBy = &userID
+	components := len(kit.Components)
+	if req.Components != nil {
+		components = len(req.Components)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewKitRepository(tx)
+		if req.Components != nil {
+			if err := validateKitComponents(kit.KitProductID, req.Components); err != nil {
+				return err
+			}
+			if err := checkKitProducts(txRepo, kit.ID, kit.KitProductID, req.Components); err != nil {
+				return err
+			}
+			if err := txRepo.ReplaceComponents(kit.ID, kitComponents(req.Components)); err != nil {
+				return err
+			}
+		}
+		return txRepo.Update(kit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("kits", "UPDATE", int64(kit.ID), int64(userID), username, old, map[string]interface{}{
+		"explode_on_pick": kit.ExplodeOnPick,
+		"is_active":       kit.IsActive,
+		"components":      components,
+	})
+	return s.repo.GetByID(kit.ID)
+}
+
+func (s *kitService) GetKit(id uint) (*models.Kit, error) {
+	kit, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("kit not found")
+	}
+	return kit, nil
+}
+
+func (s *kitService) ListKits(filters map[string]interface{}, offset, limit int) ([]*models.Kit, int64, error) {
+	return s.repo.List(filters, offset, limit)
+}
+
+func (s *kitService) CreateOrder(req *dto.CreateKitOrderRequest, userID uint, username string) (*models.KitOrder, error) {
+	kit, err := s.GetKit(req.KitID)
+	if err != nil {
+		return nil, err
+	}
+	if !kit.IsActive && req.OrderType == KitOrderAssembly {
+		return nil, errors.New("the kit is inactive and cannot be assembled")
+	}
+
+	order := &models.KitOrder{
+		OrderType:           req.OrderType,
+		KitID:               kit.ID,
+		KitProductID:        kit.KitProductID,
+		WarehouseID:         req.WarehouseID,
+		Quantity:            roundQty(req.Quantity),
+		KitLocationID:       req.KitLocationID,
+		KitBatchNumber:      req.KitBatchNumber,
+		KitLotNumber:        req.KitLotNumber,
+		ComponentLocationID: req.ComponentLocationID,
+		Status:              KitOrderDraft,
+		Notes:               req.Notes,
+		CreatedBy:           &userID,
+	}
+	for _, c := range kit.Components {
+		order.Lines = append(order.Lines, &models.KitOrderLine{
+			ComponentProductID: c.ComponentProductID,
+			QuantityPerKit:     c.Quantity,
+			Quantity:           roundQty(c.Quantity * order.Quantity),
+		})
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewKitRepository(tx)
+		if err := checkKitOrderLocations(txRepo, order); err != nil {
+			return err
+		}
+		if err := checkKitSerials(tx, order); err != nil {
+			return err
+		}
+		number, err := generateKitOrderNumber(txRepo)
+		if err != nil {
+			return err
+		}
+		order.OrderNumber = number
+		if order.OrderType == KitOrderAssembly && order.KitBatchNumber == "" {
+			order.KitBatchNumber = number
+		}
+		return txRepo.CreateOrder(order)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("kit_orders", "CREATE", int64(order.ID), int64(userID), username, nil, map[string]interface{}{
+		"order_number": order.OrderNumber,
+		"order_type":   order.OrderType,
+		"quantity":     order.Quantity,
+		"status":       order.Status,
+	})
+	return s.repo.GetOrder(order.ID)
+}
+
+func (s *kitService) GetOrder(id uint) (*models.KitOrder, error) {
+	order, err := s.repo.GetOrder(id)
+	if err != nil {
+		return nil, errors.New("kit order not found")
+	}
+	return order, nil
+}
+
+func (s *kitService) ListOrders(filters map[string]interface{}, offset, limit int) ([]*models.KitOrder, int64, error) {
+	return s.repo.ListOrders(filters, offset, limit)
+}
+
+func (s *kitService) PostOrder(id uint, userID uint, username string) (*models.KitOrder, error) {
+	order, err := s.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != KitOrderDraft {
+		return nil, fmt.Errorf("kit order is %s", order.Status)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkKitOrderLocations(repository.NewKitRepository(tx), order); err != nil {
+			return err
+		}
+		return postKitOrder(tx, order, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("kit_orders", "POST", int64(order.ID), int64(userID), username, nil, map[string]interface{}{
+		"order_number": order.OrderNumber,
+		"total_cost":   order.TotalCost,
+		"unit_cost":    order.UnitCost,
+	})
+	return s.repo.GetOrder(order.ID)
+}
+
+func (s *kitService) CancelOrder(id uint, userID uint, username string) (*models.KitOrder, error) {
+	order, err := s.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != KitOrderDraft {
+		return nil, fmt.Errorf("kit order is %s and cannot be cancelled", order.Status)
+	}
+	order.Status = KitOrderCancelled
+	if err := s.repo.UpdateOrder(order); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("kit_orders", "CANCEL", int64(order.ID), int64(userID), username, nil, map[string]interface{}{
+		"order_number": order.OrderNumber,
+	})
+	return s.repo.GetOrder(order.ID)
+}
+
+// checkKitOrderLocations checks that the kit and component locations are usable storage of the warehouse
+func checkKitOrderLocations(repo repository.KitRepository, order *models.KitOrder) error {
+	var ids []uint
+	for _, id := range []*uint{order.KitLocationID, order.ComponentLocationID} {
+		if id != nil {
+			ids = append(ids, *id)
+		}
+	}
+	locations, err := repo.GetLocations(uniqueUints(ids))
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		loc := locations[id]
+		if loc == nil {
+			return fmt.Errorf("location %d not found", id)
+		}
+		if loc.WarehouseID != order.WarehouseID {
+			return fmt.Errorf("location %s belongs to another warehouse", loc.Code)
+		}
+		if loc.LocationType == LocationTypeInTransit {
+			return fmt.Errorf("location %s is an in-transit location", loc.Code)
+		}
+		if loc.IsActive != nil && !*loc.IsActive {
+			return fmt.Errorf("location %s is inactive", loc.Code)
+		}
+	}
+	return nil
+}
+
+// checkKitSerials refuses kit orders on serial-tracked kits or components: an order consumes and
+// produces stock without recording which serials go into or come out of the kits
+func checkKitSerials(tx *gorm.DB, order *models.KitOrder) error {
+	productIDs := []uint{order.KitProductID}
+	for _, line := range order.Lines {
+		productIDs = append(productIDs, line.ComponentProductID)
+	}
+	tracked, err := serialTrackedItems(tx, "finished_product", productIDs)
+	if err != nil {
+		return err
+	}
+	for _, id := range uniqueUints(productIDs) {
+		if tracked[id] {
+			return fmt.Errorf("product %d is serial-tracked; kit orders cannot assemble or take apart serial-tracked products", id)
+		}
+	}
+	return nil
+}
+
+// kitPick is a product to pick for a delivery order item: the item itself or, when a kit is
+// exploded, one of its components
+type kitPick struct {
+	ProductID    uint
+	Quantity     float64
+	KitProductID *uint
+}
+
+// kitPicks returns what to pick for a delivery order item. A kit is exploded into its components
+// when the item asks for it, or by default when the kit is set to explode on picking.
+func kitPicks(item *models.DeliveryOrderItem, kit *models.Kit) []kitPick {
+	explode := kit != nil && len(kit.Components) > 0 && kit.ExplodeOnPick
+	if kit != nil && len(kit.Components) > 0 && item.ExplodeKit != nil {
+		explode = *item.ExplodeKit
+	}
+	if !explode {
+		return []kitPick{{ProductID: item.FinishedProductID, Quantity: item.Quantity}}
+	}
+	kitProductID := item.FinishedProductID
+	picks := make([]kitPick, 0, len(kit.Components))
+	for _, c := range kit.Components {
+		picks = append(picks, kitPick{
+			ProductID:    c.ComponentProductID,
+			Quantity:     roundQty(c.Quantity * item.Quantity),
+			KitProductID: &kitProductID,
+		})
+	}
+	return picks
+}
+
+// splitKitCost divides the cost of disassembled kits over the components in proportion to what
+// they are worth (quantity × reference cost), or to their quantities when no cost is known. The
+// rounding difference goes to the last component so the parts add up to the total.
+func splitKitCost(total float64, lines []*models.KitOrderLine, refCosts map[uint]float64) []float64 {
+	weights := make([]float64, len(lines))
+	var sum float64
+	for i, l := range lines {
+		weights[i] = l.Quantity * refCosts[l.ComponentProductID]
+		sum += weights[i]
+	}
+	if sum <= 0 {
+		sum = 0
+		for i, l := range lines {
+			weights[i] = l.Quantity
+			sum += weights[i]
+		}
+	}
+
+	costs := make([]float64, len(lines))
+	if sum <= 0 {
+		return costs
+	}
+	var allocated float64
+	for i := range lines {
+		if i == len(lines)-1 {
+			costs[i] = roundMoney(total - allocated)
+			break
+		}
+		costs[i] = roundMoney(total * weights[i] / sum)
+		allocated += costs[i]
+	}
+	return costs
+}
+
+// earliestExpiry returns the earlier of two expiry dates; stock without expiry never wins
+func earliestExpiry(a, b *string) *string {
+	if a == nil {
+		return b
+	}
+	if b == nil || dateOnly(*a) <= dateOnly(*b) {
+		return a
+	}
+	return b
+}
+
+// postKitOrder moves the stock of an assembly or disassembly in one go and marks the order posted.
+// Stock is taken FEFO from pickable balances, leaving what pick lists have allocated alone.
+func postKitOrder(tx *gorm.DB, order *models.KitOrder, userID uint) error {
+	if err := checkWarehouseNotFrozen(tx, order.WarehouseID); err != nil {
+		return err
+	}
+	if err := checkKitSerials(tx, order); err != nil {
+		return err
+	}
+	pickRepo := repository.NewPickListRepository(tx)
+	rows, err := pickRepo.AllocatedQuantities(order.WarehouseID)
+	if err != nil {
+		return err
+	}
+	allocated := allocatedByKey(rows)
+	now := time.Now()
+
+	// take consumes stock of a product FEFO, returning its cost and earliest expiry
+	take := func(productID uint, quantity float64, locationID *uint, batch, lot string) (float64, *string, []pickAllocation, error) {
+		candidates, err := pickCandidatesFor(pickRepo, order.WarehouseID, productID, allocated)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		candidates = matchingCandidates(candidates, &models.DeliveryOrderItem{WarehouseLocationID: locationID, BatchNumber: batch, LotNumber: lot})
+		allocations, short := allocateFEFO(candidates, quantity)
+		if short > 0 {
+			return 0, nil, nil, fmt.Errorf("insufficient stock of product %d: %s short", productID, formatQty(short))
+		}
+		var cost float64
+		var expiry *string
+		for _, a := range allocations {
+			balance, err := kitStockOut(tx, order, productID, a, userID, now)
+			if err != nil {
+				return 0, nil, nil, err
+			}
+			cost += a.Quantity * balance.UnitCost
+			expiry = earliestExpiry(expiry, balance.ExpiryDate)
+		}
+		return roundMoney(cost), expiry, allocations, nil
+	}
+
+	txRepo := repository.NewKitRepository(tx)
+	switch order.OrderType {
+	case KitOrderAssembly:
+		if err := checkStoragePlacements(tx, addPlacement(nil, order.KitLocationID, "finished_product", order.KitProductID, order.Quantity)); err != nil {
+			return err
+		}
+		var total float64
+		var expiry *string
+		for _, line := range order.Lines {
+			cost, lineExpiry, _, err := take(line.ComponentProductID, line.Quantity, order.ComponentLocationID, "", "")
+			if err != nil {
+				return err
+			}
+			line.TotalCost = cost
+			line.UnitCost = roundMoney(cost / line.Quantity)
+			if err := txRepo.UpdateOrderLine(line); err != nil {
+				return err
+			}
+			total += cost
+			expiry = earliestExpiry(expiry, lineExpiry)
+		}
+		order.TotalCost = roundMoney(total)
+		order.UnitCost = roundMoney(total / order.Quantity)
+		order.ExpiryDate = expiry
+		if err := kitStockIn(tx, order, order.KitProductID, order.KitLocationID, order.Quantity, order.TotalCost, userID, now); err != nil {
+			return err
+		}
+
+	case KitOrderDisassembly:
+		var placements []StoragePlacement
+		for _, line := range order.Lines {
+			placements = addPlacement(placements, order.ComponentLocationID, "finished_product", line.ComponentProductID, line.Quantity)
+		}
+		if err := checkStoragePlacements(tx, placements); err != nil {
+			return err
+		}
+		cost, expiry, allocations, err := take(order.KitProductID, order.Quantity, order.KitLocationID, order.KitBatchNumber, order.KitLotNumber)
+		if err != nil {
+			return err
+		}
+		// components go back under the batch of the kits taken apart
+		if order.KitBatchNumber == "" && len(allocations) > 0 {
+			order.KitBatchNumber = allocations[0].BatchNumber
+			order.KitLotNumber = allocations[0].LotNumber
+		}
+		order.TotalCost = cost
+		order.UnitCost = roundMoney(cost / order.Quantity)
+		order.ExpiryDate = expiry
+
+		productIDs := make([]uint, 0, len(order.Lines))
+		for _, line := range order.Lines {
+			productIDs = append(productIDs, line.ComponentProductID)
+		}
+		refCosts, err := kitReferenceCosts(txRepo, order.WarehouseID, productIDs)
+		if err != nil {
+			return err
+		}
+		for i, lineCost := range splitKitCost(cost, order.Lines, refCosts) {
+			line := order.Lines[i]
+			line.TotalCost = lineCost
+			line.UnitCost = roundMoney(lineCost / line.Quantity)
+			if err := txRepo.UpdateOrderLine(line); err != nil {
+				return err
+			}
+			if err := kitStockIn(tx, order, line.ComponentProductID, order.ComponentLocationID, line.Quantity, lineCost, userID, now); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown kit order type %s", order.OrderType)
+	}
+
+	order.Status = KitOrderPosted
+	order.PostedBy = &userID
+	order.PostedAt = &now
+	return txRepo.UpdateOrder(order)
+}
+
+// kitReferenceCosts is what each component is worth: its average cost in the warehouse, else its
+// standard cost
+func kitReferenceCosts(repo repository.KitRepository, warehouseID uint, productIDs []uint) (map[uint]float64, error) {
+	costs, err := repo.AverageCosts(warehouseID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	products, err := repo.GetProducts(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range productIDs {
+		if costs[id] > 0 {
+			continue
+		}
+		if p := products[id]; p != nil && p.StandardCost != nil {
+			costs[id] = *p.StandardCost
+		}
+	}
+	return costs, nil
+}
+
+func kitLedgerType(order *models.KitOrder) string {
+	if order.OrderType == KitOrderDisassembly {
+		return "KIT_DISASSEMBLY"
+	}
+	return "KIT_ASSEMBLY"
+}
+
+// kitBalance returns the balance of a finished product, batch and lot at a location (nil location:
+// the warehouse itself), nil when there is none
+func kitBalance(tx *gorm.DB, productID, warehouseID uint, locationID *uint, batch, lot string) (*models.StockBalance, error) {
+	query := tx.Where("item_type = ? AND item_id = ? AND warehouse_id = ?", "finished_product", productID, warehouseID).
+		Where("COALESCE(batch_number, '') = ? AND COALESCE(lot_number, '') = ?", batch, lot)
+	if locationID != nil {
+		query = query.Where("warehouse_location_id = ?", *locationID)
+	} else {
+		query = query.Where("warehouse_location_id IS NULL")
+	}
+	var balances []*models.StockBalance
+	if err := query.Limit(1).Find(&balances).Error; err != nil || len(balances) == 0 {
+		return nil, err
+	}
+	return balances[0], nil
+}
+
+// kitLedger writes the ledger entry of a kit order for one balance
+func kitLedger(tx *gorm.DB, order *models.KitOrder, b *models.StockBalance, quantity float64, userID uint, now time.Time) error {
+	txLedger := repository.NewStockLedgerRepository(tx)
+	prev, err := txLedger.GetLatestBalance("finished_product", b.ItemID, order.WarehouseID, b.WarehouseLocationID, b.BatchNumber, b.LotNumber)
+	if err != nil {
+		return err
+	}
+	return txLedger.Create(&models.StockLedger{
+		TransactionType:     kitLedgerType(order),
+		TransactionNumber:   order.OrderNumber,
+		TransactionDate:     now,
+		ItemType:            "finished_product",
+		ItemID:              b.ItemID,
+		WarehouseID:         order.WarehouseID,
+		WarehouseLocationID: b.WarehouseLocationID,
+		BatchNumber:         b.BatchNumber,
+		LotNumber:           b.LotNumber,
+		ExpiryDate:          b.ExpiryDate,
+		Quantity:            quantity,
+		UnitCost:            b.UnitCost,
+		TotalCost:           roundMoney(quantity * b.UnitCost),
+		BalanceQuantity:     prev + quantity,
+		ReferenceType:       "KitOrder",
+		ReferenceID:         order.ID,
+		CreatedBy:           &userID,
+	})
+}
+
+// kitStockOut takes an allocated quantity out of its balance and returns the balance as it was costed
+func kitStockOut(tx *gorm.DB, order *models.KitOrder, productID uint, a pickAllocation, userID uint, now time.Time) (*models.StockBalance, error) {
+	balance, err := kitBalance(tx, productID, order.WarehouseID, a.LocationID, a.BatchNumber, a.LotNumber)
+	if err != nil {
+		return nil, err
+	}
+	if balance == nil || balance.Quantity < a.Quantity-qtyEpsilon {
+		return nil, fmt.Errorf("insufficient physical stock for product %d", productID)
+	}
+	balance.Quantity = roundQty(balance.Quantity - a.Quantity)
+	balance.TotalCost = roundMoney(balance.Quantity * balance.UnitCost)
+	balance.LastTransactionDate = &now
+	if err := repository.NewStockBalanceRepository(tx).Update(balance); err != nil {
+		return nil, err
+	}
+	return balance, kitLedger(tx, order, balance, -a.Quantity, userID, now)
+}
+
+// kitStockIn receives the output of a kit order under the order's batch and expiry, averaging the
+// cost with stock already in the same balance
+func kitStockIn(tx *gorm.DB, order *models.KitOrder, productID uint, locationID *uint, quantity, cost float64, userID uint, now time.Time) error {
+	txBalance := repository.NewStockBalanceRepository(tx)
+	unitCost := roundMoney(cost / quantity)
+	balance, err := kitBalance(tx, productID, order.WarehouseID, locationID, order.KitBatchNumber, order.KitLotNumber)
+	if err != nil {
+		return err
+	}
+	if balance == nil {
+		balance = &models.StockBalance{
+			ItemType:            "finished_product",
+			ItemID:              productID,
+			WarehouseID:         order.WarehouseID,
+			WarehouseLocationID: locationID,
+			BatchNumber:         order.KitBatchNumber,
+			LotNumber:           order.KitLotNumber,
+			ExpiryDate:          order.ExpiryDate,
+			Quantity:            quantity,
+			UnitCost:            unitCost,
+			TotalCost:           roundMoney(cost),
+			LastTransactionDate: &now,
+		}
+		if err := txBalance.Upsert(balance); err != nil {
+			return err
+		}
+	} else {
+		balance.TotalCost = roundMoney(balance.TotalCost + cost)
+		balance.Quantity = roundQty(balance.Quantity + quantity)
+		if balance.Quantity > 0 {
+			balance.UnitCost = balance.TotalCost / balance.Quantity
+		}
+		balance.ExpiryDate = earliestExpiry(balance.ExpiryDate, order.ExpiryDate)
+		balance.LastTransactionDate = &now
+		if err := txBalance.Update(balance); err != nil {
+			return err
+		}
+	}
+	// the entry is costed at what this order brought in, not the averaged balance
+	entry := *balance
+	entry.UnitCost = unitCost
+	return kitLedger(tx, order, &entry, quantity, userID, now)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateKitComponents(t *testing.T) {
+	ok := []dto.KitComponentRequest{{ComponentProductID: 2, Quantity: 1}, {ComponentProductID: 3, Quantity: 2}}
+	assert.NoError(t, validateKitComponents(1, ok))
+
+	assert.Error(t, validateKitComponents(1, nil))
+	assert.ErrorContains(t, validateKitComponents(2, ok), "cannot contain itself")
+	dup := append(ok, dto.KitComponentRequest{ComponentProductID: 3, Quantity: 1})
+	assert.ErrorContains(t, validateKitComponents(1, dup), "listed twice")
+}
+
+func TestKitPicks(t *testing.T) {
+	kit := &models.Kit{KitProductID: 10, Components: []*models.KitComponent{
+		{ComponentProductID: 11, Quantity: 1},
+		{ComponentProductID: 12, Quantity: 2},
+	}}
+	item := &models.DeliveryOrderItem{FinishedProductID: 10, Quantity: 3}
+
+	picks := kitPicks(item, kit)
+	require.Len(t, picks, 1, "kits are picked assembled unless set to explode")
+	assert.Equal(t, uint(10), picks[0].ProductID)
+	assert.Nil(t, picks[0].KitProductID)
+
+	kit.ExplodeOnPick = true
+	picks = kitPicks(item, kit)
+	require.Len(t, picks, 2)
+	assert.Equal(t, uint(11), picks[0].ProductID)
+	assert.Equal(t, 3.0, picks[0].Quantity)
+	assert.Equal(t, 6.0, picks[1].Quantity)
+	assert.Equal(t, uint(10), *picks[1].KitProductID)
+
+	no := false
+	item.ExplodeKit = &no
+	assert.Len(t, kitPicks(item, kit), 1, "the item overrides the kit setting")
+
+	yes := true
+	plain := &models.DeliveryOrderItem{FinishedProductID: 20, Quantity: 1, ExplodeKit: &yes}
+	assert.Equal(t, []kitPick{{ProductID: 20, Quantity: 1}}, kitPicks(plain, nil), "only kits explode")
+}
+
+func TestSplitKitCost(t *testing.T) {
+	lines := []*models.KitOrderLine{
+		{ComponentProductID: 1, Quantity: 10},
+		{ComponentProductID: 2, Quantity: 10},
+		{ComponentProductID: 3, Quantity: 20},
+	}
+
+	costs := splitKitCost(1000, lines, map[uint]float64{1: 30, 2: 10, 3: 5})
+	assert.Equal(t, []float64{600, 200, 200}, costs)
+
+	costs = splitKitCost(100, lines, map[uint]float64{})
+	assert.Equal(t, []float64{25, 25, 50}, costs, "without costs the split follows the quantities")
+
+	costs = splitKitCost(100, lines[:2], map[uint]float64{1: 1, 2: 2})
+	assert.InDelta(t, 100, costs[0]+costs[1], 0.001)
+	assert.Equal(t, 33.33, costs[0])
+}
+
+func TestEarliestExpiry(t *testing.T) {
+	assert.Nil(t, earliestExpiry(nil, nil))
+	assert.Equal(t, "2027-01-31", *earliestExpiry(nil, strPtr("2027-01-31")))
+	assert.Equal(t, "2026-12-01T00:00:00Z", *earliestExpiry(strPtr("2027-01-31"), strPtr("2026-12-01T00:00:00Z")))
+}
@@ -0,0 +1,788 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/config"
+	"github.com/VyVy-ERP/warehouse-backend/internal/document"
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// RenderedLabels is a print job ready to be sent to the printer; LogID is its print log entry
+type RenderedLabels struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+	LogID       uint
+}
+
+// LabelService renders batch, location and pallet labels as ZPL or PDF sheets and keeps a print log
+type LabelService interface {
+	ListTemplates(filters map[string]interface{}) ([]*models.LabelTemplate, error)
+	CreateTemplate(req *dto.SaveLabelTemplateRequest, userID uint, username string) (*models.LabelTemplate, error)
+	UpdateTemplate(id uint, req *dto.SaveLabelTemplateRequest, userID uint, username string) (*models.LabelTemplate, error)
+
+	Print(req *dto.PrintLabelsRequest, userID uint, username string) (*RenderedLabels, error)
+	// Reprint renders a logged job again with the same content and logs it as a reprint
+	Reprint(logID uint, req *dto.ReprintLabelsRequest, userID uint, username string) (*RenderedLabels, error)
+	ListLogs(filters map[string]interface{}, offset, limit int) ([]*models.LabelPrintLog, int64, error)
+	GetLog(id uint) (*models.LabelPrintLog, error)
+}
+
+type labelService struct {
+	db       *gorm.DB
+	repo     repository.LabelRepository
+	auditSvc AuditLogService
+	cfg      config.LabelConfig
+	lang     string
+	fonts    *document.FontSet
+}
+
+func NewLabelService(
+	db *gorm.DB,
+	repo repository.LabelRepository,
+	auditSvc AuditLogService,
+	cfg config.LabelConfig,
+	defaultLang string,
+	fonts *document.FontSet,
+) LabelService {
+	return &labelService{
+		db:       db,
+		repo:     repo,
+		auditSvc: auditSvc,
+		cfg:      cfg,
+		lang:     document.NormalizeLang(defaultLang),
+		fonts:    fonts,
+	}
+}
+
+// labelSource identifies what a print job was for in the log
+type labelSource struct {
+	Type   string
+	ID     *uint
+	Number string
+}
+
+// builtinLabelTemplate is used when no default template is configured: 100x50 mm thermal labels
+// (100x150 mm for pallets) or 70x37 mm labels 3x8 on A4 (two pallet labels per sheet)
+func builtinLabelTemplate(labelType, format string) *models.LabelTemplate {
+	t := &models.LabelTemplate{
+		LabelType:    labelType,
+		Format:       format,
+		Dpmm:         8,
+		BarcodeType:  document.BarcodeCode128,
+		SheetColumns: 1,
+		SheetRows:    1,
+		IsActive:     true,
+	}
+	switch {
+	case format == models.LabelFormatZPL && labelType == models.LabelTypePallet:
+		t.WidthMM, t.HeightMM = 100, 150
+	case format == models.LabelFormatZPL:
+		t.WidthMM, t.HeightMM = 100, 50
+	case labelType == models.LabelTypePallet:
+		t.WidthMM, t.HeightMM = 190, 138
+		t.MarginLeftMM, t.MarginTopMM, t.GapYMM = 10, 10, 0
+		t.SheetRows = 2
+	default:
+		t.WidthMM, t.HeightMM = 70, 37
+		t.SheetColumns, t.SheetRows = 3, 8
+		t.MarginTopMM = 0.5
+	}
+	return t
+}
+
+func labelLayout(t *models.LabelTemplate) document.LabelLayout {
+	return document.LabelLayout{
+		WidthMM:      t.WidthMM,
+		HeightMM:     t.HeightMM,
+		Columns:      t.SheetColumns,
+		Rows:         t.SheetRows,
+		MarginLeftMM: t.MarginLeftMM,
+		MarginTopMM:  t.MarginTopMM,
+		GapXMM:       t.GapXMM,
+		GapYMM:       t.GapYMM,
+	}
+}
+
+func (s *labelService) ListTemplates(filters map[string]interface{}) ([]*models.LabelTemplate, error) {
+	return s.repo.ListTemplates(filters)
+}
+
+func (s *labelService) CreateTemplate(req *dto.SaveLabelTemplateRequest, userID uint, username string) (*models.LabelTemplate, error) {
+	t := &models.LabelTemplate{CreatedBy: &userID}
+	if err := applyLabelTemplate(t, req); err != nil {
+		return nil, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		repo := repository.NewLabelRepository(tx)
+		if err := repo.CreateTemplate(t); err != nil {
+			return err
+		}
+		if t.IsDefault {
+			return repo.ClearDefault(t.LabelType, t.Format, t.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("label_templates", "CREATE", int64(t.ID), int64(userID), username, nil, t)
+	return t, nil
+}
+
+func (s *labelService) UpdateTemplate(id uint, req *dto.SaveLabelTemplateRequest, userID uint, username string) (*models.LabelTemplate, error) {
+	t, err := s.repo.GetTemplate(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("label template not found")
+		}
+		return nil, err
+	}
+	old := *t
+	if err := applyLabelTemplate(t, req); err != nil {
+		return nil, err
+	}
+	t.UpdatedBy = &userID
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		repo := repository.NewLabelRepository(tx)
+		if err := repo.UpdateTemplate(t); err != nil {
+			return err
+		}
+		if t.IsDefault {
+			return repo.ClearDefault(t.LabelType, t.Format, t.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("label_templates", "UPDATE", int64(t.ID), int64(userID), username, old, t)
+	return t, nil
+}
+
+// applyLabelTemplate copies and validates the request: ZPL bodies must parse, PDF grids must
+// fit on A4 and PDF sheets only print Code 128
+func applyLabelTemplate(t *models.LabelTemplate, req *dto.SaveLabelTemplateRequest) error {
+	t.Code = strings.TrimSpace(req.Code)
+	t.Name = req.Name
+	t.LabelType = req.LabelType
+	t.Format = req.Format
+	t.WidthMM = req.WidthMM
+	t.HeightMM = req.HeightMM
+	t.Dpmm = req.Dpmm
+	if t.Dpmm == 0 {
+		t.Dpmm = 8
+	}
+	t.BarcodeType = req.BarcodeType
+	if t.BarcodeType == "" {
+		t.BarcodeType = document.BarcodeCode128
+	}
+	t.Body = req.Body
+	t.SheetColumns = max(req.SheetColumns, 1)
+	t.SheetRows = max(req.SheetRows, 1)
+	t.MarginLeftMM = req.MarginLeftMM
+	t.MarginTopMM = req.MarginTopMM
+	t.GapXMM = req.GapXMM
+	t.GapYMM = req.GapYMM
+	t.IsDefault = req.IsDefault
+	t.IsActive = req.IsActive == nil || *req.IsActive
+	if t.IsDefault && !t.IsActive {
+		return errors.New("an inactive template cannot be the default")
+	}
+
+	if t.Format == models.LabelFormatZPL {
+		if _, err := document.ParseZPLTemplate(t.Body); err != nil {
+			return fmt.Errorf("invalid ZPL template: %w", err)
+		}
+		return nil
+	}
+	if t.BarcodeType != document.BarcodeCode128 {
+		return errors.New("PDF labels are printed with Code 128 barcodes only")
+	}
+	if strings.TrimSpace(t.Body) != "" {
+		return errors.New("a template body is only used for ZPL labels")
+	}
+	return labelLayout(t).Validate()
+}
+
+// resolveTemplate picks the requested template, the configured default or the built-in layout
+func (s *labelService) resolveTemplate(labelType, format string, templateID *uint) (*models.LabelTemplate, error) {
+	if templateID != nil {
+		t, err := s.repo.GetTemplate(*templateID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, errors.New("label template not found")
+			}
+			return nil, err
+		}
+		if !t.IsActive {
+			return nil, fmt.Errorf("label template %s is inactive", t.Code)
+		}
+		if t.LabelType != labelType {
+			return nil, fmt.Errorf("label template %s is for %s labels", t.Code, t.LabelType)
+		}
+		if format != "" && format != t.Format {
+			return nil, fmt.Errorf("label template %s prints %s, not %s", t.Code, t.Format, format)
+		}
+		return t, nil
+	}
+	if format == "" {
+		format = models.LabelFormatZPL
+	}
+	t, err := s.repo.DefaultTemplate(labelType, format)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		t = builtinLabelTemplate(labelType, format)
+	}
+	return t, nil
+}
+
+func (s *labelService) Print(req *dto.PrintLabelsRequest, userID uint, username string) (*RenderedLabels, error) {
+	tmpl, err := s.resolveTemplate(req.LabelType, req.Format, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	lang := s.lang
+	if req.Lang != "" {
+		lang = document.NormalizeLang(req.Lang)
+	}
+
+	var labels []document.Label
+	var source labelSource
+	switch req.LabelType {
+	case models.LabelTypeGRNItem:
+		labels, source, err = s.grnLabels(req.GRNID, req.ItemIDs, lang)
+	case models.LabelTypeFPRNBatch:
+		labels, source, err = s.fprnLabels(req.FPRNID, req.ItemIDs, lang)
+	case models.LabelTypeLocation:
+		labels, source, err = s.locationLabels(req.LocationIDs, req.WarehouseID)
+	case models.LabelTypePallet:
+		labels, source, err = s.palletLabels(req.Pallet, lang)
+	default:
+		err = fmt.Errorf("unknown label type %s", req.LabelType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(labels) == 0 {
+		return nil, errors.New("nothing to print")
+	}
+
+	copies := max(req.Copies, 1)
+	rendered, err := s.render(tmpl, labels, copies, source)
+	if err != nil {
+		return nil, err
+	}
+	content, err := json.Marshal(labels)
+	if err != nil {
+		return nil, err
+	}
+	log := &models.LabelPrintLog{
+		LabelType:     req.LabelType,
+		Format:        tmpl.Format,
+		SourceType:    source.Type,
+		SourceID:      source.ID,
+		SourceNumber:  source.Number,
+		LabelCount:    len(labels),
+		Copies:        copies,
+		Content:       content,
+		PrintedBy:     &userID,
+		PrintedByName: username,
+		PrintedAt:     time.Now(),
+	}
+	if tmpl.ID != 0 {
+		log.TemplateID = &tmpl.ID
+	}
+	if err := s.repo.CreateLog(log); err != nil {
+		return nil, err
+	}
+	rendered.LogID = log.ID
+	return rendered, nil
+}
+
+func (s *labelService) Reprint(logID uint, req *dto.ReprintLabelsRequest, userID uint, username string) (*RenderedLabels, error) {
+	original, err := s.repo.GetLog(logID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("label print log not found")
+		}
+		return nil, err
+	}
+	var labels []document.Label
+	if err := json.Unmarshal(original.Content, &labels); err != nil {
+		return nil, fmt.Errorf("stored labels are unreadable: %w", err)
+	}
+
+	var tmpl *models.LabelTemplate
+	switch {
+	case req.TemplateID != nil:
+		tmpl, err = s.resolveTemplate(original.LabelType, "", req.TemplateID)
+	case original.Template != nil && original.Template.IsActive:
+		tmpl = original.Template
+	default:
+		tmpl, err = s.resolveTemplate(original.LabelType, original.Format, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	copies := req.Copies
+	if copies == 0 {
+		copies = original.Copies
+	}
+	source := labelSource{Type: original.SourceType, ID: original.SourceID, Number: original.SourceNumber}
+	rendered, err := s.render(tmpl, labels, copies, source)
+	if err != nil {
+		return nil, err
+	}
+
+	// reprints always point at the first print of the job
+	reprintOf := original.ID
+	if original.ReprintOfID != nil {
+		reprintOf = *original.ReprintOfID
+	}
+	log := &models.LabelPrintLog{
+		LabelType:     original.LabelType,
+		Format:        tmpl.Format,
+		SourceType:    original.SourceType,
+		SourceID:      original.SourceID,
+		SourceNumber:  original.SourceNumber,
+		LabelCount:    len(labels),
+		Copies:        copies,
+		Content:       original.Content,
+		ReprintOfID:   &reprintOf,
+		Reason:        req.Reason,
+		PrintedBy:     &userID,
+		PrintedByName: username,
+		PrintedAt:     time.Now(),
+	}
+	if tmpl.ID != 0 {
+		log.TemplateID = &tmpl.ID
+	}
+	if err := s.repo.CreateLog(log); err != nil {
+		return nil, err
+	}
+	rendered.LogID = log.ID
+	return rendered, nil
+}
+
+func (s *labelService) ListLogs(filters map[string]interface{}, offset, limit int) ([]*models.LabelPrintLog, int64, error) {
+	return s.repo.ListLogs(filters, offset, limit)
+}
+
+func (s *labelService) GetLog(id uint) (*models.LabelPrintLog, error) {
+	log, err := s.repo.GetLog(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("label print log not found")
+		}
+		return nil, err
+	}
+	return log, nil
+}
+
+// render prints every label copies times in a row
+func (s *labelService) render(tmpl *models.LabelTemplate, labels []document.Label, copies int, source labelSource) (*RenderedLabels, error) {
+	printed := make([]document.Label, 0, len(labels)*copies)
+	for _, l := range labels {
+		for i := 0; i < copies; i++ {
+			printed = append(printed, l)
+		}
+	}
+
+	base := source.Number
+	if base == "" {
+		base = tmpl.LabelType
+	}
+	out := &RenderedLabels{FileName: base + "-labels." + tmpl.Format}
+	var err error
+	if tmpl.Format == models.LabelFormatPDF {
+		out.ContentType = "application/pdf"
+		out.Content, err = document.RenderLabelSheets(printed, labelLayout(tmpl), s.fonts)
+	} else {
+		out.ContentType = "text/plain; charset=utf-8"
+		out.Content, err = document.RenderZPL(printed, tmpl.Body, tmpl.WidthMM, tmpl.HeightMM, tmpl.Dpmm, tmpl.BarcodeType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *labelService) grnLabels(grnID uint, itemIDs []uint, lang string) ([]document.Label, labelSource, error) {
+	if grnID == 0 {
+		return nil, labelSource{}, errors.New("grn_id is required for GRN item labels")
+	}
+	grn, err := s.repo.GetGRN(grnID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, labelSource{}, errors.New("GRN not found")
+		}
+		return nil, labelSource{}, err
+	}
+	source := labelSource{Type: "grn", ID: &grn.ID, Number: grn.GRNNumber}
+	wanted := idSet(itemIDs)
+
+	var labels []document.Label
+	for _, item := range grn.Items {
+		if len(wanted) > 0 && !wanted[item.ID] {
+			continue
+		}
+		var code, name, unit, location string
+		if item.Material != nil {
+			code, name, unit = item.Material.Code, item.Material.TradingName, item.Material.Unit
+		}
+		if item.WarehouseLocation != nil {
+			location = item.WarehouseLocation.Code
+		}
+		qcStatus := item.QCStatus
+		if qcStatus == "" {
+			qcStatus = grn.QCStatus
+		}
+		qty := item.Quantity
+		if item.AcceptedQuantity > 0 {
+			qty = item.AcceptedQuantity
+		}
+		labels = append(labels, document.Label{
+			Title: code,
+			Lines: labelLines(lang,
+				"", name,
+				"col_batch", joinNonEmpty(" / ", item.BatchNumber, item.LotNumber),
+				"col_expiry", docDate(lang, item.ExpiryDate),
+				"qc_status", qcStatus,
+				"col_qty", labelQty(lang, qty, unit),
+				"title_grn", grn.GRNNumber,
+			),
+			Barcode: itemBarcode("", code, item.BatchNumber, item.ExpiryDate),
+			Fields: map[string]string{
+				"item_type":   "material",
+				"code":        code,
+				"name":        name,
+				"batch":       item.BatchNumber,
+				"lot":         item.LotNumber,
+				"expiry":      strValue(item.ExpiryDate),
+				"qc_status":   qcStatus,
+				"quantity":    formatQty(qty),
+				"unit":        unit,
+				"location":    location,
+				"document":    grn.GRNNumber,
+				"received_on": grn.ReceiptDate,
+			},
+		})
+	}
+	return labels, source, nil
+}
+
+func (s *labelService) fprnLabels(fprnID uint, itemIDs []uint, lang string) ([]document.Label, labelSource, error) {
+	if fprnID == 0 {
+		return nil, labelSource{}, errors.New("fprn_id is required for finished batch labels")
+	}
+	fprn, err := s.repo.GetFPRN(fprnID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, labelSource{}, errors.New("finished product receipt not found")
+		}
+		return nil, labelSource{}, err
+	}
+	source := labelSource{Type: "fprn", ID: &fprn.ID, Number: fprn.FPRNNumber}
+	wanted := idSet(itemIDs)
+
+	var labels []document.Label
+	for _, item := range fprn.Items {
+		if len(wanted) > 0 && !wanted[item.ID] {
+			continue
+		}
+		var code, name, unit, gtin, location string
+		if fp := item.FinishedProduct; fp != nil {
+			code, name, unit = fp.Code, fp.Name, fp.Unit
+			gtin = productGTIN(fp.Barcode)
+		}
+		if item.WarehouseLocation != nil {
+			location = item.WarehouseLocation.Code
+		}
+		labels = append(labels, document.Label{
+			Title: code,
+			Lines: labelLines(lang,
+				"", name,
+				"col_batch", item.BatchNumber,
+				"col_mfg_date", docDate(lang, item.ManufactureDate),
+				"col_expiry", docDate(lang, item.ExpiryDate),
+				"col_qty", labelQty(lang, item.Quantity, unit),
+				"title_fprn", fprn.FPRNNumber,
+			),
+			Barcode: itemBarcode(gtin, code, item.BatchNumber, item.ExpiryDate),
+			Fields: map[string]string{
+				"item_type":        "finished_product",
+				"code":             code,
+				"name":             name,
+				"gtin":             gtin,
+				"batch":            item.BatchNumber,
+				"manufacture_date": strValue(item.ManufactureDate),
+				"expiry":           strValue(item.ExpiryDate),
+				"quantity":         formatQty(item.Quantity),
+				"unit":             unit,
+				"location":         location,
+				"document":         fprn.FPRNNumber,
+			},
+		})
+	}
+	return labels, source, nil
+}
+
+func (s *labelService) locationLabels(ids []uint, warehouseID uint) ([]document.Label, labelSource, error) {
+	if len(ids) == 0 && warehouseID == 0 {
+		return nil, labelSource{}, errors.New("location_ids or warehouse_id is required for location labels")
+	}
+	locations, err := s.repo.Locations(uniqueUints(ids), warehouseID)
+	if err != nil {
+		return nil, labelSource{}, err
+	}
+	source := labelSource{Type: "location"}
+	if len(ids) == 0 {
+		source = labelSource{Type: "warehouse", ID: &warehouseID}
+	} else if len(locations) == 1 {
+		source.ID = &locations[0].ID
+		source.Number = locations[0].Code
+	}
+
+	labels := make([]document.Label, 0, len(locations))
+	for _, loc := range locations {
+		path := locationPath(loc)
+		var lines []string
+		if loc.Name != "" && loc.Name != loc.Code {
+			lines = append(lines, loc.Name)
+		}
+		if path != "" && path != loc.Code {
+			lines = append(lines, path)
+		}
+		labels = append(labels, document.Label{
+			Title:   loc.Code,
+			Lines:   lines,
+			Barcode: locationScanPrefix + loc.Code,
+			Fields: map[string]string{
+				"code":         loc.Code,
+				"name":         loc.Name,
+				"path":         path,
+				"warehouse_id": fmt.Sprint(loc.WarehouseID),
+			},
+		})
+	}
+	return labels, source, nil
+}
+
+func (s *labelService) palletLabels(req *dto.PalletLabelRequest, lang string) ([]document.Label, labelSource, error) {
+	if req == nil {
+		return nil, labelSource{}, errors.New("pallet details are required for pallet labels")
+	}
+	if err := validGS1CompanyPrefix(s.cfg.GS1CompanyPrefix); err != nil {
+		return nil, labelSource{}, err
+	}
+	var code, name, unit, gtin string
+	if req.ItemType == "material" {
+		m, err := s.repo.GetMaterial(req.ItemID)
+		if err != nil {
+			return nil, labelSource{}, errors.New("material not found")
+		}
+		code, name, unit = m.Code, m.TradingName, m.Unit
+	} else {
+		fp, err := s.repo.GetFinishedProduct(req.ItemID)
+		if err != nil {
+			return nil, labelSource{}, errors.New("finished product not found")
+		}
+		code, name, unit = fp.Code, fp.Name, fp.Unit
+		gtin = productGTIN(fp.Barcode)
+	}
+	expiry := pickExpiry(req.ExpiryDate)
+	var expiryStr *string
+	if expiry != nil {
+		d := expiry.Format("2006-01-02")
+		expiryStr = &d
+	}
+
+	pallets := max(req.Pallets, 1)
+	source := labelSource{Type: "item", ID: &req.ItemID, Number: code}
+	labels := make([]document.Label, 0, pallets)
+	for i := 1; i <= pallets; i++ {
+		serial, err := s.repo.NextSSCCSerial()
+		if err != nil {
+			return nil, labelSource{}, err
+		}
+		sscc, err := buildSSCC(s.cfg.SSCCExtensionDigit, s.cfg.GS1CompanyPrefix, serial)
+		if err != nil {
+			return nil, labelSource{}, err
+		}
+		labels = append(labels, document.Label{
+			Title: "SSCC " + sscc,
+			Lines: labelLines(lang,
+				"", code+" - "+name,
+				"col_batch", req.BatchNumber,
+				"col_expiry", docDate(lang, expiryStr),
+				"col_qty", labelQty(lang, req.Quantity, unit),
+				"pallet", fmt.Sprintf("%d/%d", i, pallets),
+			),
+			Barcode: palletBarcode(sscc, gtin, code, req.BatchNumber, expiryStr, req.Quantity),
+			Fields: map[string]string{
+				"sscc":      sscc,
+				"item_type": req.ItemType,
+				"code":      code,
+				"name":      name,
+				"gtin":      gtin,
+				"batch":     req.BatchNumber,
+				"expiry":    strValue(expiryStr),
+				"quantity":  formatQty(req.Quantity),
+				"unit":      unit,
+				"pallet":    fmt.Sprintf("%d/%d", i, pallets),
+			},
+		})
+	}
+	return labels, source, nil
+}
+
+// labelLines turns key/value pairs into "Label: value" lines, skipping empty values;
+// an empty key prints the value alone
+func labelLines(lang string, pairs ...string) []string {
+	lines := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		switch {
+		case pairs[i+1] == "":
+		case pairs[i] == "":
+			lines = append(lines, pairs[i+1])
+		default:
+			lines = append(lines, document.T(lang, pairs[i])+": "+pairs[i+1])
+		}
+	}
+	return lines
+}
+
+func labelQty(lang string, q float64, unit string) string {
+	return strings.TrimSpace(docQty(lang, q) + " " + unit)
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var out []string
+	for _, p := range parts {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+func idSet(ids []uint) map[uint]bool {
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// productGTIN returns a finished product barcode as GTIN-14 when it is a valid GTIN
+func productGTIN(barcode string) string {
+	barcode = strings.TrimSpace(barcode)
+	if isDigits(barcode) && validGTIN(barcode) {
+		return padGTIN(barcode)
+	}
+	return ""
+}
+
+// gs1Value reports whether s can be carried in a GS1 field of at most maxLen characters
+// (GS1 character set 82, without the brackets our bracketed form uses as delimiters)
+func gs1Value(s string, maxLen int) bool {
+	if s == "" || len(s) > maxLen {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '!' || c > 'z' || strings.IndexByte("#$@[\\]^`()", c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// gs1Date formats a YYYY-MM-DD date as YYMMDD
+func gs1Date(date *string) string {
+	if date == nil || len(*date) < 10 {
+		return ""
+	}
+	d := *date
+	return d[2:4] + d[5:7] + d[8:10]
+}
+
+// itemBarcode is the scan payload of a batch label: GTIN (01) or item code (240), batch (10)
+// and expiry (17) in bracketed GS1 form. A code GS1 cannot carry falls back to the plain code.
+func itemBarcode(gtin, code, batch string, expiry *string) string {
+	var b strings.Builder
+	switch {
+	case gtin != "":
+		b.WriteString("(01)" + gtin)
+	case gs1Value(code, 30):
+		b.WriteString("(240)" + code)
+	default:
+		return code
+	}
+	if gs1Value(batch, 20) {
+		b.WriteString("(10)" + batch)
+	}
+	if d := gs1Date(expiry); d != "" {
+		b.WriteString("(17)" + d)
+	}
+	return b.String()
+}
+
+// palletBarcode is the GS1 logistic label payload: SSCC (00), contained GTIN (02) or item code (240),
+// batch, expiry and the count of contained units (37) when it is a whole number
+func palletBarcode(sscc, gtin, code, batch string, expiry *string, quantity float64) string {
+	var b strings.Builder
+	b.WriteString("(00)" + sscc)
+	switch {
+	case gtin != "":
+		b.WriteString("(02)" + gtin)
+	case gs1Value(code, 30):
+		b.WriteString("(240)" + code)
+	}
+	if gs1Value(batch, 20) {
+		b.WriteString("(10)" + batch)
+	}
+	if d := gs1Date(expiry); d != "" {
+		b.WriteString("(17)" + d)
+	}
+	if quantity == math.Trunc(quantity) && quantity > 0 && quantity < 1e8 {
+		b.WriteString(fmt.Sprintf("(37)%d", int64(quantity)))
+	}
+	return b.String()
+}
+
+func validGS1CompanyPrefix(prefix string) error {
+	if prefix == "" {
+		return errors.New("pallet labels need a GS1 company prefix (set LABEL_GS1_COMPANY_PREFIX)")
+	}
+	if !isDigits(prefix) || len(prefix) < 6 || len(prefix) > 12 {
+		return errors.New("the GS1 company prefix must be 6 to 12 digits")
+	}
+	return nil
+}
+
+// buildSSCC numbers an 18-digit SSCC: extension digit, company prefix, serial reference, check digit
+func buildSSCC(extension int, prefix string, serial int64) (string, error) {
+	if extension < 0 || extension > 9 {
+		return "", errors.New("the SSCC extension digit must be 0-9")
+	}
+	refLen := 16 - len(prefix)
+	body := fmt.Sprintf("%d%s%0*d", extension, prefix, refLen, serial)
+	if len(body) != 17 {
+		return "", fmt.Errorf("SSCC serial references for prefix %s are exhausted", prefix)
+	}
+	return body + string(gs1CheckDigit(body)), nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSSCC(t *testing.T) {
+	sscc, err := buildSSCC(1, "0614141", 123456789)
+	require.NoError(t, err)
+	assert.Equal(t, "106141411234567897", sscc)
+
+	_, err = buildSSCC(0, "893123456789", 100000)
+	assert.Error(t, err, "a 12-digit prefix leaves four digits for the serial reference")
+
+	assert.Error(t, validGS1CompanyPrefix(""))
+	assert.Error(t, validGS1CompanyPrefix("89A1234"))
+	assert.NoError(t, validGS1CompanyPrefix("8931234"))
+}
+
+func TestItemBarcode(t *testing.T) {
+	assert.Equal(t, "(01)04006381333931(10)L-01(17)261231",
+		itemBarcode(productGTIN("4006381333931"), "FP-01", "L-01", strPtr("2026-12-31")))
+	assert.Equal(t, "(240)MAT-001(10)B7", itemBarcode("", "MAT-001", "B7", nil))
+	// a batch GS1 cannot carry is left out, a code it cannot carry falls back to plain text
+	assert.Equal(t, "(240)MAT-001", itemBarcode("", "MAT-001", "LOT 7", nil))
+	assert.Equal(t, "MAT 001", itemBarcode("", "MAT 001", "B7", nil))
+	assert.Empty(t, productGTIN("ABC123"))
+}
+
+func TestPalletBarcodeScansBack(t *testing.T) {
+	payload := palletBarcode("106141411234567897", "04006381333931", "FP-01", "L1", strPtr("2027-02-00"), 480)
+	assert.Equal(t, "(00)106141411234567897(02)04006381333931(10)L1(17)270200(37)480", payload)
+
+	code, err := parseScan(payload)
+	require.NoError(t, err)
+	assert.Equal(t, "04006381333931", code.GTIN)
+	assert.Equal(t, "106141411234567897", code.AIs["00"])
+	assert.Equal(t, "L1", code.Batch)
+	assert.Equal(t, "2027-02-28", code.Expiry)
+
+	// fractional quantities are printed but not encoded
+	assert.Equal(t, "(00)106141411234567897(240)MAT-001", palletBarcode("106141411234567897", "", "MAT-001", "", nil, 12.5))
+}
+
+func TestApplyLabelTemplate(t *testing.T) {
+	req := &dto.SaveLabelTemplateRequest{
+		Code: "LOC-A4", Name: "Location sheet", LabelType: models.LabelTypeLocation, Format: models.LabelFormatPDF,
+		WidthMM: 70, HeightMM: 37, SheetColumns: 3, SheetRows: 8,
+	}
+	tmpl := &models.LabelTemplate{}
+	require.NoError(t, applyLabelTemplate(tmpl, req))
+	assert.True(t, tmpl.IsActive)
+	assert.Equal(t, 8, tmpl.Dpmm)
+
+	req.SheetRows = 9
+	assert.Error(t, applyLabelTemplate(tmpl, req), "nine rows of 37 mm do not fit on A4")
+
+	req.SheetRows = 8
+	req.BarcodeType = "qr"
+	assert.Error(t, applyLabelTemplate(tmpl, req))
+
+	req.Format = models.LabelFormatZPL
+	req.Body = "^XA^FD{{.Title}^FS^XZ"
+	assert.Error(t, applyLabelTemplate(tmpl, req))
+	req.Body = "^XA^FD{{zpl .Title}}^FS{{.BarcodeField}}^XZ"
+	assert.NoError(t, applyLabelTemplate(tmpl, req))
+}
+
+func TestLabelLines(t *testing.T) {
+	lines := labelLines("en", "", "Glycerin", "col_batch", "B1", "col_expiry", "", "col_qty", labelQty("en", 1250.5, "KG"))
+	assert.Equal(t, []string{"Glycerin", "Batch: B1", "Quantity: 1,250.5 KG"}, lines)
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// LandedCostService handles landed cost vouchers (chi phí mua hàng phân bổ vào giá vốn)
+type LandedCostService interface {
+	Create(req *dto.CreateLandedCostVoucherRequest, userID uint, username string) (*models.LandedCostVoucher, error)
+	GetByID(id uint) (*models.LandedCostVoucher, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.LandedCostVoucher, int64, error)
+	Post(id uint, userID uint, username string) (*models.LandedCostVoucher, error)
+	Cancel(id uint, userID uint, username string) error
+}
+
+type landedCostService struct {
+	db       *gorm.DB
+	repo     repository.LandedCostRepository
+	grnRepo  repository.GoodsReceiptNoteRepository
+	auditSvc AuditLogService
+}
+
+func NewLandedCostService(
+	db *gorm.DB,
+	repo repository.LandedCostRepository,
+	grnRepo repository.GoodsReceiptNoteRepository,
+	auditSvc AuditLogService,
+) LandedCostService {
+	return &landedCostService{
+		db:       db,
+		repo:     repo,
+		grnRepo:  grnRepo,
+		auditSvc: auditSvc,
+	}
+}
+
+// generateVoucherNumber creates a number like LCV-2026-000001
+func (s *landedCostService) generateVoucherNumber() (string, error) {
+	prefix := fmt.Sprintf("LCV-%s-", time.Now().Format("2006"))
+	count, err := s.repo.CountByVoucherNumber(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%06d", prefix, count+1), nil
+}
+
+func (s *landedCostService) Create(req *dto.CreateLandedCostVoucherRequest, userID uint, username string) (*models.LandedCostVoucher, error) {
+	if _, err := time.Parse("2006-01-02", req.VoucherDate); err != nil {
+		return nil, errors.New("invalid date format, use YYYY-MM-DD")
+	}
+
+	voucher := &models.LandedCostVoucher{
+		VoucherDate:      req.VoucherDate,
+		AllocationMethod: req.AllocationMethod,
+		Status:           "draft",
+		Notes:            req.Notes,
+		CreatedBy:        &userID,
+		UpdatedBy:        &userID,
+	}
+
+	for _, c := range req.Charges {
+		voucher.TotalAmount += c.Amount
+		voucher.Charges = append(voucher.Charges, &models.LandedCostCharge{
+			ChargeType:  c.ChargeType,
+			Description: c.Description,
+			Amount:      c.Amount,
+		})
+	}
+	voucher.TotalAmount = roundMoney(voucher.TotalAmount)
+
+	// Build one allocation line per accepted GRN item
+	seen := make(map[uint]bool)
+	var bases []float64
+	for _, grnID := range req.GRNIDs {
+		if seen[grnID] {
+			continue
+		}
+		seen[grnID] = true
+
+		grn, err := s.grnRepo.GetByID(grnID)
+		if err != nil {
+			return nil, fmt.Errorf("GRN %d not found", grnID)
+		}
+		if !grn.Posted {
+			return nil, fmt.Errorf("GRN %s is not posted", grn.GRNNumber)
+		}
+		voucher.GRNs = append(voucher.GRNs, &models.LandedCostVoucherGRN{GRNID: grn.ID})
+
+		for _, item := range grn.Items {
+			if item.AcceptedQuantity <= 0 {
+				continue
+			}
+			line := &models.LandedCostAllocation{
+				GRNID:               grn.ID,
+				GRNItemID:           item.ID,
+				MaterialID:          item.MaterialID,
+				WarehouseID:         grn.WarehouseID,
+				WarehouseLocationID: item.WarehouseLocationID,
+				BatchNumber:         item.BatchNumber,
+				LotNumber:           item.LotNumber,
+				BasisQuantity:       item.AcceptedQuantity,
+				BasisValue:          roundMoney(item.AcceptedQuantity * item.CostInBase()),
+				BasisWeight:         req.Weights[item.ID],
+			}
+
+			switch req.AllocationMethod {
+			case "quantity":
+				bases = append(bases, line.BasisQuantity)
+			case "value":
+				bases = append(bases, line.BasisValue)
+			case "weight":
+				if line.BasisWeight <= 0 {
+					return nil, fmt.Errorf("weight is required for GRN item %d (%s)", item.ID, grn.GRNNumber)
+				}
+				bases = append(bases, line.BasisWeight)
+			default:
+				return nil, errors.New("allocation_method must be quantity, value or weight")
+			}
+			voucher.Allocations = append(voucher.Allocations, line)
+		}
+	}
+
+	if len(voucher.Allocations) == 0 {
+		return nil, errors.New("selected GRNs have no accepted quantity to allocate onto")
+	}
+
+	amounts, err := allocateLandedCost(voucher.TotalAmount, bases)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range voucher.Allocations {
+		line.AllocatedAmount = amounts[i]
+	}
+
+	number, err := s.generateVoucherNumber()
+	if err != nil {
+		return nil, err
+	}
+	voucher.VoucherNumber = number
+
+	if err := s.repo.Create(voucher); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("landed_cost_vouchers", "CREATE", int64(voucher.ID), int64(userID), username, nil, map[string]interface{}{
+		"voucher_number":    voucher.VoucherNumber,
+		"allocation_method": voucher.AllocationMethod,
+		"total_amount":      voucher.TotalAmount,
+		"grn_ids":           req.GRNIDs,
+	})
+
+	return s.repo.GetByID(voucher.ID)
+}
+
+func (s *landedCostService) GetByID(id uint) (*models.LandedCostVoucher, error) {
+	voucher, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("landed cost voucher not found")
+	}
+	return voucher, nil
+}
+
+func (s *landedCostService) List(filters map[string]interface{}, offset, limit int) ([]*models.LandedCostVoucher, int64, error) {
+	return s.repo.List(filters, offset, limit)
+}
+
+// Post revalues the receiving batches. stock_balance rows are kept per batch/lot, so the rows of a
+// batch act as the cost layer of its receipt: the allocated charge is added to their total_cost,
+// split by quantity over the locations the batch is at now, and the weighted average unit cost is
+// recomputed. When part of the batch was already issued, only the on-hand share is capitalized and
+// the remainder is reported as expensed.
+func (s *landedCostService) Post(id uint, userID uint, username string) (*models.LandedCostVoucher, error) {
+	voucher, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("landed cost voucher not found")
+	}
+	if voucher.Status != "draft" {
+		return nil, errors.New("only draft vouchers can be posted")
+	}
+	if voucher.Posted {
+		return nil, errors.New("voucher is already posted")
+	}
+
+	now := time.Now()
+	var capitalized, expensed float64
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txLedger := repository.NewStockLedgerRepository(tx)
+		txBalance := repository.NewStockBalanceRepository(tx)
+
+		for _, line := range voucher.Allocations {
+			line.CapitalizedAmount = 0
+			line.ExpensedAmount = line.AllocatedAmount
+			line.OnHandQuantity = 0
+
+			// The batch may have been put away or moved since it was received: revalue it
+			// wherever it is in the warehouse now
+			balances, err := txBalance.ListBatch("material", line.MaterialID, line.WarehouseID, line.BatchNumber, line.LotNumber)
+			if err != nil {
+				return err
+			}
+			var onHand, prevValue float64
+			quantities := make([]float64, len(balances))
+			for i, balance := range balances {
+				quantities[i] = balance.Quantity
+				onHand += balance.Quantity
+				prevValue += balance.TotalCost
+			}
+
+			if onHand > 0 && line.BasisQuantity > 0 {
+				capitalizedQty := math.Min(onHand, line.BasisQuantity)
+				share := roundMoney(line.AllocatedAmount * capitalizedQty / line.BasisQuantity)
+				shares, err := allocateLandedCost(share, quantities)
+				if err != nil {
+					return err
+				}
+
+				prevUnitCost := prevValue / onHand
+				newUnitCost := (prevValue + share) / onHand
+				line.OnHandQuantity = capitalizedQty
+				line.CapitalizedAmount = share
+				line.ExpensedAmount = roundMoney(line.AllocatedAmount - share)
+				line.PreviousUnitCost = &prevUnitCost
+				line.NewUnitCost = &newUnitCost
+
+				for i, balance := range balances {
+					balancePrevUnitCost := balance.UnitCost
+					balance.TotalCost += shares[i]
+					balance.UnitCost = balance.TotalCost / balance.Quantity
+					balance.LastTransactionDate = &now
+					if err := txBalance.Update(balance); err != nil {
+						return err
+					}
+
+					// Revaluation entry: no quantity movement, only value
+					entry := &models.StockLedger{
+						TransactionType:     "revaluation",
+						TransactionNumber:   voucher.VoucherNumber,
+						TransactionDate:     now,
+						ItemType:            "material",
+						ItemID:              line.MaterialID,
+						WarehouseID:         line.WarehouseID,
+						WarehouseLocationID: balance.WarehouseLocationID,
+						BatchNumber:         line.BatchNumber,
+						LotNumber:           line.LotNumber,
+						ExpiryDate:          balance.ExpiryDate,
+						Quantity:            0,
+						UnitCost:            balance.UnitCost,
+						TotalCost:           shares[i],
+						BalanceQuantity:     balance.Quantity,
+						ReferenceType:       "LandedCost",
+						ReferenceID:         voucher.ID,
+						Notes:               fmt.Sprintf("Landed cost GRN #%d, unit cost %.2f -> %.2f", line.GRNID, balancePrevUnitCost, balance.UnitCost),
+						CreatedBy:           &userID,
+					}
+					if err := txLedger.Create(entry); err != nil {
+						return err
+					}
+				}
+			}
+
+			capitalized += line.CapitalizedAmount
+			expensed += line.ExpensedAmount
+			if err := tx.Omit("Material").Save(line).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.LandedCostVoucher{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"posted":     true,
+			"posted_by":  userID,
+			"posted_at":  now,
+			"status":     "posted",
+			"updated_by": userID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("landed_cost_vouchers", "POST", int64(id), int64(userID), username, nil, map[string]interface{}{
+		"voucher_number":     voucher.VoucherNumber,
+		"capitalized_amount": roundMoney(capitalized),
+		"expensed_amount":    roundMoney(expensed),
+	})
+
+	return s.repo.GetByID(id)
+}
+
+func (s *landedCostService) Cancel(id uint, userID uint, username string) error {
+	voucher, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("landed cost voucher not found")
+	}
+	if voucher.Status != "draft" {
+		return errors.New("only draft vouchers can be cancelled")
+	}
+	if err := s.repo.UpdateStatus(id, "cancelled"); err != nil {
+		return err
+	}
+	_ = s.auditSvc.Log("landed_cost_vouchers", "CANCEL", int64(id), int64(userID), username, nil, map[string]interface{}{
+		"voucher_number": voucher.VoucherNumber,
+	})
+	return nil
+}
+
+// allocateLandedCost splits total proportionally to bases, rounded to 2 decimals.
+// The rounding remainder goes to the line with the largest basis so the shares add up to total.
+func allocateLandedCost(total float64, bases []float64) ([]float64, error) {
+	var sum float64
+	largest := -1
+	for i, b := range bases {
+		if b < 0 {
+			return nil, errors.New("allocation basis cannot be negative")
+		}
+		sum += b
+		if largest < 0 || b > bases[largest] {
+			largest = i
+		}
+	}
+	if sum <= 0 {
+		return nil, errors.New("allocation basis is zero, cannot allocate charges")
+	}
+
+	amounts := make([]float64, len(bases))
+	var allocated float64
+	for i, b := range bases {
+		amounts[i] = roundMoney(total * b / sum)
+		allocated += amounts[i]
+	}
+	amounts[largest] = roundMoney(amounts[largest] + total - allocated)
+	return amounts, nil
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocateLandedCost(t *testing.T) {
+	t.Run("proportional by value", func(t *testing.T) {
+		amounts, err := allocateLandedCost(1000, []float64{3000, 1000})
+		require.NoError(t, err)
+		assert.Equal(t, []float64{750, 250}, amounts)
+	})
+
+	t.Run("rounding remainder goes to largest basis", func(t *testing.T) {
+		amounts, err := allocateLandedCost(100, []float64{1, 1, 1})
+		require.NoError(t, err)
+		assert.InDelta(t, 100, amounts[0]+amounts[1]+amounts[2], 0.0001)
+		assert.Equal(t, 33.34, amounts[0])
+		assert.Equal(t, 33.33, amounts[1])
+	})
+
+	t.Run("zero basis is rejected", func(t *testing.T) {
+		_, err := allocateLandedCost(100, []float64{0, 0})
+		assert.Error(t, err)
+	})
+
+	t.Run("negative basis is rejected", func(t *testing.T) {
+		_, err := allocateLandedCost(100, []float64{5, -1})
+		assert.Error(t, err)
+	})
+}
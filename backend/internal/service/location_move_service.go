@@ -0,0 +1,497 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Location move statuses and sources
+const (
+	LocationMoveDraft     = "draft"
+	LocationMovePosted    = "posted"
+	LocationMoveCancelled = "cancelled"
+
+	LocationMoveManual        = "manual"
+	LocationMoveScan          = "scan"
+	LocationMoveConsolidation = "consolidation"
+)
+
+// LocationMoveService moves stock between locations of one warehouse, from a document, from
+// handheld scans or from a consolidation suggestion
+type LocationMoveService interface {
+	CreateMove(req *dto.CreateLocationMoveRequest, userID uint, username string) (*models.LocationMove, error)
+	GetMove(id uint) (*models.LocationMove, error)
+	ListMoves(filters map[string]interface{}, offset, limit int) ([]*models.LocationMove, int64, error)
+	PostMove(id uint, userID uint, username string) (*models.LocationMove, error)
+	CancelMove(id uint, userID uint, username string) (*models.LocationMove, error)
+	// ScanMove creates and posts a single-item move from scanned location and item labels
+	ScanMove(req *dto.ScanLocationMoveRequest, userID uint, username string) (*models.LocationMove, error)
+	// Consolidation finds items spread over several partially-filled locations
+	Consolidation(filter *dto.ConsolidationFilterRequest) ([]dto.ConsolidationSuggestion, error)
+}
+
+type locationMoveService struct {
+	db       *gorm.DB
+	repo     repository.LocationMoveRepository
+	scanSvc  ScanService
+	auditSvc AuditLogService
+}
+
+func NewLocationMoveService(db *gorm.DB, repo repository.LocationMoveRepository, scanSvc ScanService, auditSvc AuditLogService) LocationMoveService {
+	return &locationMoveService{db: db, repo: repo, scanSvc: scanSvc, auditSvc: auditSvc}
+}
+
+// generateLocationMoveNumber creates a number like MV-2026-000001
+func generateLocationMoveNumber(repo repository.LocationMoveRepository) (string, error) {
+	prefix := fmt.Sprintf("MV-%s-", time.Now().Format("2006"))
+	count, err := repo.CountByMoveNumber(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%06d", prefix, count+1), nil
+}
+
+func (s *locationMoveService) CreateMove(req *dto.CreateLocationMoveRequest, userID uint, username string) (*models.LocationMove, error) {
+	source := req.Source
+	if source == "" {
+		source = LocationMoveManual
+	}
+	return s.create(req, source, userID, username)
+}
+
+func (s *locationMoveService) create(req *dto.CreateLocationMoveRequest, source string, userID uint, username string) (*models.LocationMove, error) {
+	move := &models.LocationMove{
+		WarehouseID: req.WarehouseID,
+		Source:      source,
+		Status:      LocationMoveDraft,
+		Notes:       req.Notes,
+		CreatedBy:   &userID,
+	}
+	for _, r := range req.Items {
+		move.Items = append(move.Items, &models.LocationMoveItem{
+			ItemType:       r.ItemType,
+			ItemID:         r.ItemID,
+			BatchNumber:    r.BatchNumber,
+			LotNumber:      r.LotNumber,
+			FromLocationID: r.FromLocationID,
+			ToLocationID:   r.ToLocationID,
+			Quantity:       roundQty(r.Quantity),
+			Notes:          r.Notes,
+			SerialNumbers:  cleanSerials(r.SerialNumbers),
+		})
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewLocationMoveRepository(tx)
+		if err := checkMoveLocations(txRepo, move); err != nil {
+			return err
+		}
+		number, err := generateLocationMoveNumber(txRepo)
+		if err != nil {
+			return err
+		}
+		move.MoveNumber = number
+		if err := txRepo.Create(move); err != nil {
+			return err
+		}
+		if req.Post {
+			return postLocationMove(tx, move, userID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("location_moves", "CREATE", int64(move.ID), int64(userID), username, nil, map[string]interface{}{
+		"move_number": move.MoveNumber,
+		"source":      move.Source,
+		"items":       len(move.Items),
+		"status":      move.Status,
+	})
+	return s.repo.GetByID(move.ID)
+}
+
+func (s *locationMoveService) GetMove(id uint) (*models.LocationMove, error) {
+	move, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("location move not found")
+	}
+	return move, nil
+}
+
+func (s *locationMoveService) ListMoves(filters map[string]interface{}, offset, limit int) ([]*models.LocationMove, int64, error) {
+	return s.repo.List(filters, offset, limit)
+}
+
+func (s *locationMoveService) PostMove(id uint, userID uint, username string) (*models.LocationMove, error) {
+	move, err := s.GetMove(id)
+	if err != nil {
+		return nil, err
+	}
+	if move.Status != LocationMoveDraft {
+		return nil, fmt.Errorf("location move is %s", move.Status)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// locations may have been deactivated since the draft was made
+		if err := checkMoveLocations(repository.NewLocationMoveRepository(tx), move); err != nil {
+			return err
+		}
+		return postLocationMove(tx, move, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("location_moves", "POST", int64(move.ID), int64(userID), username, nil, map[string]interface{}{
+		"move_number": move.MoveNumber,
+		"status":      move.Status,
+	})
+	return s.repo.GetByID(move.ID)
+}
+
+func (s *locationMoveService) CancelMove(id uint, userID uint, username string) (*models.LocationMove, error) {
+	move, err := s.GetMove(id)
+	if err != nil {
+		return nil, err
+	}
+	if move.Status != LocationMoveDraft {
+		return nil, fmt.Errorf("location move is %s and cannot be cancelled", move.Status)
+	}
+	move.Status = LocationMoveCancelled
+	if err := s.repo.Update(move); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("location_moves", "CANCEL", int64(move.ID), int64(userID), username, nil, map[string]interface{}{
+		"move_number": move.MoveNumber,
+	})
+	return s.repo.GetByID(move.ID)
+}
+
+func (s *locationMoveService) ScanMove(req *dto.ScanLocationMoveRequest, userID uint, username string) (*models.LocationMove, error) {
+	from, err := resolveLocationScan(s.scanSvc, req.FromLocation, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := resolveLocationScan(s.scanSvc, req.ToLocation, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.scanSvc.Resolve(&dto.ScanRequest{Code: req.ItemScan})
+	if err != nil && !errors.Is(err, ErrScanNotRecognised) {
+		return nil, err
+	}
+	if result == nil || result.Item == nil {
+		return nil, fmt.Errorf("%w: %s is not an item", ErrScanMismatch, req.ItemScan)
+	}
+	item := result.Item
+
+	// the scanned item must be at the source, in a single batch and lot unless the label says which
+	var balances []*models.StockBalance
+	query := s.db.Where("item_type = ? AND item_id = ? AND warehouse_id = ? AND warehouse_location_id = ? AND quantity > 0",
+		item.ItemType, item.ItemID, req.WarehouseID, from.ID)
+	if result.Batch != "" {
+		query = query.Where("batch_number = ?", result.Batch)
+	}
+	if err := query.Order("id").Find(&balances).Error; err != nil {
+		return nil, err
+	}
+	switch {
+	case len(balances) == 0:
+		return nil, fmt.Errorf("no stock of %s at %s", item.Code, from.Code)
+	case len(balances) > 1:
+		return nil, fmt.Errorf("%s holds %d batches of %s; scan the lot label", from.Code, len(balances), item.Code)
+	}
+	balance := balances[0]
+
+	quantity := 0.0
+	if req.Quantity != nil {
+		quantity = roundQty(*req.Quantity)
+	} else {
+		allocated, err := moveAllocations(s.db, req.WarehouseID, item.ItemType)
+		if err != nil {
+			return nil, err
+		}
+		quantity = movableQuantity(balance, allocated)
+		if quantity <= qtyEpsilon {
+			return nil, fmt.Errorf("the %s of %s at %s is reserved or allocated to pick lists", item.Code, formatQty(balance.Quantity), from.Code)
+		}
+	}
+
+	return s.create(&dto.CreateLocationMoveRequest{
+		WarehouseID: req.WarehouseID,
+		Items: []dto.LocationMoveItemRequest{{
+			ItemType:       item.ItemType,
+			ItemID:         item.ItemID,
+			BatchNumber:    balance.BatchNumber,
+			LotNumber:      balance.LotNumber,
+			FromLocationID: from.ID,
+			ToLocationID:   to.ID,
+			Quantity:       quantity,
+			SerialNumbers:  req.SerialNumbers,
+		}},
+		Notes: req.Notes,
+		Post:  true,
+	}, LocationMoveScan, userID, username)
+}
+
+// checkMoveLocations checks that every line moves between two different locations of the warehouse;
+// in-transit stock can only be received and inactive locations take no stock
+func checkMoveLocations(repo repository.LocationMoveRepository, move *models.LocationMove) error {
+	var ids []uint
+	for _, item := range move.Items {
+		ids = append(ids, item.FromLocationID, item.ToLocationID)
+	}
+	locations, err := repo.GetLocations(uniqueUints(ids))
+	if err != nil {
+		return err
+	}
+	for i, item := range move.Items {
+		if item.FromLocationID == item.ToLocationID {
+			return fmt.Errorf("line %d: source and target are the same location", i+1)
+		}
+		from, to := locations[item.FromLocationID], locations[item.ToLocationID]
+		if from == nil || to == nil {
+			return fmt.Errorf("line %d: location not found", i+1)
+		}
+		for _, loc := range []*models.WarehouseLocation{from, to} {
+			if loc.WarehouseID != move.WarehouseID {
+				return fmt.Errorf("line %d: location %s belongs to another warehouse", i+1, loc.Code)
+			}
+			if loc.LocationType == LocationTypeInTransit {
+				return fmt.Errorf("line %d: location %s is an in-transit location", i+1, loc.Code)
+			}
+		}
+		if to.IsActive != nil && !*to.IsActive {
+			return fmt.Errorf("line %d: location %s is inactive", i+1, to.Code)
+		}
+	}
+	return nil
+}
+
+// moveAllocations returns the pick list allocations of the warehouse when finished products are moved;
+// allocated stock stays where the pick list expects it
+func moveAllocations(tx *gorm.DB, warehouseID uint, itemTypes ...string) (map[pickKey]float64, error) {
+	for _, itemType := range itemTypes {
+		if itemType == "finished_product" {
+			rows, err := repository.NewPickListRepository(tx).AllocatedQuantities(warehouseID)
+			if err != nil {
+				return nil, err
+			}
+			return allocatedByKey(rows), nil
+		}
+	}
+	return map[pickKey]float64{}, nil
+}
+
+// movableQuantity is the part of a balance that is neither reserved nor allocated to pick lines
+func movableQuantity(b *models.StockBalance, allocated map[pickKey]float64) float64 {
+	movable := b.Quantity - b.ReservedQuantity
+	if b.ItemType == "finished_product" {
+		movable -= allocated[newPickKey(b.ItemID, b.WarehouseLocationID, b.BatchNumber, b.LotNumber)]
+	}
+	if movable < 0 {
+		return 0
+	}
+	return roundQty(movable)
+}
+
+// postLocationMove moves the stock of every line at the unit cost of the source and marks the move posted
+func postLocationMove(tx *gorm.DB, move *models.LocationMove, userID uint) error {
+	txRepo := repository.NewLocationMoveRepository(tx)
+
+	if err := checkWarehouseNotFrozen(tx, move.WarehouseID); err != nil {
+		return err
+	}
+
+	var placements []StoragePlacement
+	itemTypes := make([]string, 0, len(move.Items))
+	for _, item := range move.Items {
+		placements = addPlacement(placements, &item.ToLocationID, item.ItemType, item.ItemID, item.Quantity)
+		itemTypes = append(itemTypes, item.ItemType)
+	}
+	if err := checkStoragePlacements(tx, placements); err != nil {
+		return err
+	}
+	allocated, err := moveAllocations(tx, move.WarehouseID, itemTypes...)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i, item := range move.Items {
+		fromLocationID, toLocationID := item.FromLocationID, item.ToLocationID
+		serials, err := movingSerials(tx, item.ItemType, item.ItemID, move.WarehouseID, &fromLocationID,
+			item.BatchNumber, item.LotNumber, item.Quantity, item.SerialNumbers, fmt.Sprintf("line %d", i+1))
+		if err != nil {
+			return err
+		}
+		source, err := moveStock(tx, stockPosting{
+			ItemType:    item.ItemType,
+			ItemID:      item.ItemID,
+			BatchNumber: item.BatchNumber,
+			LotNumber:   item.LotNumber,
+			WarehouseID: move.WarehouseID,
+			LocationID:  &fromLocationID,
+			Quantity:    item.Quantity,
+			TxType:      "LOCATION_MOVE",
+			TxNumber:    move.MoveNumber,
+			RefType:     "LocationMove",
+			RefID:       move.ID,
+			Notes:       item.Notes,
+			Allocated:   allocated,
+			Serials:     serials,
+		}, move.WarehouseID, &toLocationID, "", userID, now)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		item.UnitCost = source.UnitCost
+		item.SerialNumbers = serials
+		if err := txRepo.UpdateItem(item); err != nil {
+			return err
+		}
+	}
+
+	move.Status = LocationMovePosted
+	move.PostedBy = &userID
+	move.PostedAt = &now
+	return txRepo.Update(move)
+}
+
+func (s *locationMoveService) Consolidation(filter *dto.ConsolidationFilterRequest) ([]dto.ConsolidationSuggestion, error) {
+	rows, err := s.repo.ConsolidationRows(filter.WarehouseID, filter.ItemType, filter.ItemID)
+	if err != nil {
+		return nil, err
+	}
+	itemTypes := make([]string, 0, len(rows))
+	for _, row := range rows {
+		itemTypes = append(itemTypes, row.ItemType)
+	}
+	allocated, err := moveAllocations(s.db, filter.WarehouseID, itemTypes...)
+	if err != nil {
+		return nil, err
+	}
+	minLocations := filter.MinLocations
+	if minLocations < 2 {
+		minLocations = 2
+	}
+	return consolidationSuggestions(rows, allocated, minLocations), nil
+}
+
+// consolidationSuggestions groups the rows by item, batch and lot. Full locations are left out; the
+// rest is moved, smallest first, into the location holding the most as long as it has room.
+// Suggestions that free the most locations come first.
+func consolidationSuggestions(rows []repository.ConsolidationRow, allocated map[pickKey]float64, minLocations int) []dto.ConsolidationSuggestion {
+	type groupKey struct {
+		itemType   string
+		itemID     uint
+		batch, lot string
+	}
+	var keys []groupKey
+	groups := make(map[groupKey][]repository.ConsolidationRow)
+	for _, row := range rows {
+		// a location filled to capacity is not a candidate, either way
+		if row.MaxQuantity != nil && row.LocationQuantity >= *row.MaxQuantity-qtyEpsilon {
+			continue
+		}
+		key := groupKey{row.ItemType, row.ItemID, row.BatchNumber, row.LotNumber}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], row)
+	}
+
+	suggestions := make([]dto.ConsolidationSuggestion, 0)
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < minLocations {
+			continue
+		}
+		// largest quantity first, as ordered by the repository
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Quantity > group[j].Quantity })
+
+		target := group[0]
+		suggestion := dto.ConsolidationSuggestion{
+			ItemType:           target.ItemType,
+			ItemID:             target.ItemID,
+			ItemCode:           target.ItemCode,
+			ItemName:           target.ItemName,
+			BatchNumber:        target.BatchNumber,
+			LotNumber:          target.LotNumber,
+			TargetLocationID:   target.LocationID,
+			TargetLocationCode: target.LocationCode,
+		}
+		movable := make([]float64, len(group))
+		for i, row := range group {
+			locationID := row.LocationID
+			movable[i] = movableQuantity(&models.StockBalance{
+				ItemType: row.ItemType, ItemID: row.ItemID, WarehouseLocationID: &locationID,
+				BatchNumber: row.BatchNumber, LotNumber: row.LotNumber,
+				Quantity: row.Quantity, ReservedQuantity: row.ReservedQuantity,
+			}, allocated)
+
+			location := dto.ConsolidationLocation{
+				LocationID:   row.LocationID,
+				LocationCode: row.LocationCode,
+				Quantity:     row.Quantity,
+				Movable:      movable[i],
+			}
+			if row.MaxQuantity != nil && *row.MaxQuantity > 0 {
+				fill := roundMoney(row.LocationQuantity / *row.MaxQuantity * 100)
+				location.FillPercent = &fill
+			}
+			suggestion.Locations = append(suggestion.Locations, location)
+			suggestion.TotalQuantity = roundQty(suggestion.TotalQuantity + row.Quantity)
+		}
+
+		var free *float64
+		if target.MaxQuantity != nil {
+			room := *target.MaxQuantity - target.LocationQuantity
+			free = &room
+		}
+		for i := len(group) - 1; i > 0; i-- {
+			row := group[i]
+			quantity := movable[i]
+			if quantity <= qtyEpsilon || (free != nil && quantity > *free+qtyEpsilon) {
+				continue
+			}
+			if free != nil {
+				*free -= quantity
+			}
+			suggestion.Moves = append(suggestion.Moves, dto.LocationMoveItemRequest{
+				ItemType:       row.ItemType,
+				ItemID:         row.ItemID,
+				BatchNumber:    row.BatchNumber,
+				LotNumber:      row.LotNumber,
+				FromLocationID: row.LocationID,
+				ToLocationID:   target.LocationID,
+				Quantity:       quantity,
+			})
+			if quantity >= row.Quantity-qtyEpsilon && row.LocationQuantity-row.Quantity <= qtyEpsilon {
+				suggestion.LocationsFreed++
+			}
+		}
+		if len(suggestion.Moves) == 0 {
+			continue
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].LocationsFreed != suggestions[j].LocationsFreed {
+			return suggestions[i].LocationsFreed > suggestions[j].LocationsFreed
+		}
+		return len(suggestions[i].Locations) > len(suggestions[j].Locations)
+	})
+	return suggestions
+}
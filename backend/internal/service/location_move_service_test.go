@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMovableQuantity(t *testing.T) {
+	loc := uint(3)
+	allocated := map[pickKey]float64{newPickKey(7, &loc, "B1", ""): 4}
+
+	product := &models.StockBalance{ItemType: "finished_product", ItemID: 7, WarehouseLocationID: &loc, BatchNumber: "B1", Quantity: 10, ReservedQuantity: 1}
+	assert.Equal(t, 5.0, movableQuantity(product, allocated))
+
+	material := &models.StockBalance{ItemType: "material", ItemID: 7, WarehouseLocationID: &loc, BatchNumber: "B1", Quantity: 10, ReservedQuantity: 1}
+	assert.Equal(t, 9.0, movableQuantity(material, allocated), "pick allocations only hold finished products")
+
+	product.ReservedQuantity = 8
+	assert.Equal(t, 0.0, movableQuantity(product, allocated))
+}
+
+func TestConsolidationSuggestions(t *testing.T) {
+	rows := []repository.ConsolidationRow{
+		{ItemType: "material", ItemID: 1, ItemCode: "MAT-001", BatchNumber: "B1", LocationID: 10, LocationCode: "A-10", MaxQuantity: f64(100), Quantity: 50, LocationQuantity: 50},
+		{ItemType: "material", ItemID: 1, ItemCode: "MAT-001", BatchNumber: "B1", LocationID: 13, LocationCode: "A-13", MaxQuantity: f64(100), Quantity: 20, LocationQuantity: 100},
+		{ItemType: "material", ItemID: 1, ItemCode: "MAT-001", BatchNumber: "B1", LocationID: 11, LocationCode: "A-11", MaxQuantity: f64(100), Quantity: 10, LocationQuantity: 10},
+		{ItemType: "material", ItemID: 1, ItemCode: "MAT-001", BatchNumber: "B1", LocationID: 12, LocationCode: "A-12", Quantity: 5, LocationQuantity: 8},
+		{ItemType: "material", ItemID: 1, ItemCode: "MAT-001", BatchNumber: "B2", LocationID: 14, LocationCode: "A-14", Quantity: 7, LocationQuantity: 7},
+		{ItemType: "finished_product", ItemID: 2, ItemCode: "FP-002", LocationID: 20, LocationCode: "B-20", Quantity: 30, LocationQuantity: 30},
+		{ItemType: "finished_product", ItemID: 2, ItemCode: "FP-002", LocationID: 21, LocationCode: "B-21", Quantity: 10, LocationQuantity: 10},
+	}
+	loc21 := uint(21)
+	allocated := map[pickKey]float64{newPickKey(2, &loc21, "", ""): 4}
+
+	suggestions := consolidationSuggestions(rows, allocated, 2)
+	require.Len(t, suggestions, 2)
+
+	first := suggestions[0]
+	assert.Equal(t, "MAT-001", first.ItemCode)
+	assert.Equal(t, "A-10", first.TargetLocationCode)
+	assert.Len(t, first.Locations, 3, "a full location is left out")
+	assert.Equal(t, 65.0, first.TotalQuantity)
+	require.Len(t, first.Moves, 2)
+	assert.Equal(t, uint(12), first.Moves[0].FromLocationID, "smallest first")
+	assert.Equal(t, uint(11), first.Moves[1].FromLocationID)
+	assert.Equal(t, uint(10), first.Moves[1].ToLocationID)
+	assert.Equal(t, 1, first.LocationsFreed, "A-12 still holds other stock")
+	require.NotNil(t, first.Locations[0].FillPercent)
+	assert.Equal(t, 50.0, *first.Locations[0].FillPercent)
+	assert.Nil(t, first.Locations[2].FillPercent)
+
+	second := suggestions[1]
+	assert.Equal(t, "FP-002", second.ItemCode)
+	require.Len(t, second.Moves, 1)
+	assert.Equal(t, 6.0, second.Moves[0].Quantity, "allocated stock stays for the pick list")
+	assert.Equal(t, 0, second.LocationsFreed)
+
+	assert.Len(t, consolidationSuggestions(rows, allocated, 3), 1)
+}
+
+func TestConsolidationSuggestionsTargetFull(t *testing.T) {
+	rows := []repository.ConsolidationRow{
+		{ItemType: "material", ItemID: 1, LocationID: 10, MaxQuantity: f64(100), Quantity: 60, LocationQuantity: 95},
+		{ItemType: "material", ItemID: 1, LocationID: 11, Quantity: 10, LocationQuantity: 10},
+	}
+	assert.Empty(t, consolidationSuggestions(rows, nil, 2), "the target has no room for the rest")
+}
@@ -66,6 +66,9 @@ func (s *materialIssueNoteService) Create(min *models.MaterialIssueNote) (*model
 	min.MINNumber = fmt.Sprintf("MIN-%s-%06d", year, count+1)
 	min.Status = "draft"
 	min.IsPosted = false
+	for _, item := range min.Items {
+		item.SerialNumbers = cleanSerials(item.SerialNumbers)
+	}
 
 	// 3. Save MIN
 	if err := s.minRepo.Create(min); err != nil {
@@ -105,10 +108,27 @@ func (s *materialIssueNoteService) Post(id uint, userID uint) error {
 		if min.IsPosted {
 			return errors.New("material issue note is already posted")
 		}
+		if err := checkWarehouseNotFrozen(tx, min.WarehouseID); err != nil {
+			return err
+		}
+
+		// Serial-tracked materials are issued by serial, one per unit
+		materialIDs := make([]uint, 0, len(min.Items))
+		for _, item := range min.Items {
+			materialIDs = append(materialIDs, item.MaterialID)
+		}
+		tracked, err := serialTrackedItems(tx, "material", materialIDs)
+		if err != nil {
+			return err
+		}
 
 		// 2. Process each item
 		now := time.Now()
 		for _, item := range min.Items {
+			if err := checkSerialCount(tracked[item.MaterialID], item.Quantity, item.SerialNumbers, fmt.Sprintf("MIN item %d", item.ID)); err != nil {
+				return err
+			}
+
 			// a. Get stock balance
 			var balance models.StockBalance
 			query := tx.Where("item_type = ? AND item_id = ? AND warehouse_id = ?", "material", item.MaterialID, min.WarehouseID)
@@ -189,6 +209,9 @@ func (s *materialIssueNoteService) Post(id uint, userID uint) error {
 			if err := tx.Create(&ledger).Error; err != nil {
 				return err
 			}
+			if err := postSerials(tx, &ledger, item.SerialNumbers, serialIssue); err != nil {
+				return fmt.Errorf("MIN item %d: %w", item.ID, err)
+			}
 
 			// e. Update stock balance and reservation
 			balance.Quantity -= item.Quantity
@@ -35,12 +35,14 @@ func buildMaterialSuppliers(inputs []dto.MaterialSupplierInput, materialID int64
 	result := make([]models.MaterialSupplier, len(inputs))
 	for i, in := range inputs {
 		result[i] = models.MaterialSupplier{
-			MaterialID:   materialID,
-			SupplierID:   in.SupplierID,
-			Priority:     in.Priority,
-			UnitPrice:    in.UnitPrice,
-			LeadTimeDays: in.LeadTimeDays,
-			Notes:        in.Notes,
+			MaterialID:       materialID,
+			SupplierID:       in.SupplierID,
+			Priority:         in.Priority,
+			UnitPrice:        in.UnitPrice,
+			LeadTimeDays:     in.LeadTimeDays,
+			MinOrderQuantity: in.MinOrderQuantity,
+			PackSize:         in.PackSize,
+			Notes:            in.Notes,
 		}
 	}
 	return result
@@ -77,10 +79,17 @@ func (s *materialService) CreateMaterial(req dto.CreateMaterialRequest, userID i
 		ShelfLifeDays:     req.ShelfLifeDays,
 		StorageConditions: req.StorageConditions,
 		Hazardous:         req.Hazardous,
+		SerialTracked:     req.SerialTracked,
 		IsActive:          &req.IsActive,
 		Notes:             req.Notes,
 		CreatedBy:         &userID,
 		UpdatedBy:         &userID,
+
+		OverReceiptTolerance:  req.OverReceiptTolerance,
+		UnderReceiptTolerance: req.UnderReceiptTolerance,
+		UnitWeightKg:          req.UnitWeightKg,
+		UnitVolumeM3:          req.UnitVolumeM3,
+		UnitsPerPallet:        req.UnitsPerPallet,
 	}
 
 	if err := s.repo.Create(material); err != nil {
@@ -188,6 +197,21 @@ func (s *materialService) UpdateMaterial(id int64, req dto.UpdateMaterialRequest
 	if req.ReorderQuantity != nil {
 		material.ReorderQuantity = req.ReorderQuantity
 	}
+	if req.OverReceiptTolerance != nil {
+		material.OverReceiptTolerance = req.OverReceiptTolerance
+	}
+	if req.UnderReceiptTolerance != nil {
+		material.UnderReceiptTolerance = req.UnderReceiptTolerance
+	}
+	if req.UnitWeightKg != nil {
+		material.UnitWeightKg = req.UnitWeightKg
+	}
+	if req.UnitVolumeM3 != nil {
+		material.UnitVolumeM3 = req.UnitVolumeM3
+	}
+	if req.UnitsPerPallet != nil {
+		material.UnitsPerPallet = req.UnitsPerPallet
+	}
 	if req.RequiresQC != nil {
 		material.RequiresQC = *req.RequiresQC
 	}
@@ -200,6 +224,9 @@ func (s *materialService) UpdateMaterial(id int64, req dto.UpdateMaterialRequest
 	if req.Hazardous != nil {
 		material.Hazardous = *req.Hazardous
 	}
+	if req.SerialTracked != nil {
+		material.SerialTracked = *req.SerialTracked
+	}
 	if req.IsActive != nil {
 		material.IsActive = req.IsActive
 	}
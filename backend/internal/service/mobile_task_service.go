@@ -0,0 +1,1071 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrMobileTaskNotFound is returned for an unknown task type or ID
+	ErrMobileTaskNotFound = errors.New("task not found")
+	// ErrMobileTaskClaimed is returned when another operator is working the task
+	ErrMobileTaskClaimed = errors.New("task is claimed by another operator")
+	// ErrMobileRequestReused is returned when a request ID comes back with a different step
+	ErrMobileRequestReused = errors.New("request_id was already used for a different step")
+	// ErrScanMismatch is returned when a scanned code is not what the step expects
+	ErrScanMismatch = errors.New("scanned code does not match the task")
+)
+
+// errMobileReplay rolls back a step confirmation whose request ID was stored in the meantime
+var errMobileReplay = errors.New("step already confirmed")
+
+// mobileTaskTypes is the order in which task types are listed
+var mobileTaskTypes = []string{models.MobileTaskPutaway, models.MobileTaskPick, models.MobileTaskCount, models.MobileTaskTransfer, models.MobileTaskReceive}
+
+// MobileTaskService is the handheld view of putaway tasks, pick lists, cycle count tasks and stock
+// transfers to ship or receive: task lists with compact payloads, per-operator assignment, scan-verified step
+// confirmations that are safe to retry, and exceptions reported from the floor
+type MobileTaskService interface {
+	ListTasks(taskType string, q repository.MobileTaskQuery) ([]dto.MobileTask, error)
+	GetTask(taskType string, id uint) (*dto.MobileTaskDetail, error)
+	Claim(taskType string, id uint, userID uint, username string) (*dto.MobileTask, error)
+	Release(taskType string, id uint, userID uint, username string) (*dto.MobileTask, error)
+	Assign(taskType string, id uint, req *dto.AssignMobileTaskRequest, userID uint, username string) (*dto.MobileTask, error)
+	ConfirmStep(taskType string, id uint, req *dto.ConfirmMobileStepRequest, userID uint, username string) (*dto.MobileStepResult, error)
+	ReportException(taskType string, id uint, req *dto.ReportMobileExceptionRequest, userID uint, username string) (*models.MobileTaskException, error)
+	ListExceptions(filters map[string]interface{}, offset, limit int) ([]*models.MobileTaskException, int64, error)
+	ResolveException(id uint, req *dto.ResolveMobileExceptionRequest, userID uint, username string) (*models.MobileTaskException, error)
+}
+
+type mobileTaskService struct {
+	db       *gorm.DB
+	repo     repository.MobileTaskRepository
+	scanSvc  ScanService
+	auditSvc AuditLogService
+}
+
+func NewMobileTaskService(db *gorm.DB, repo repository.MobileTaskRepository, scanSvc ScanService, auditSvc AuditLogService) MobileTaskService {
+	return &mobileTaskService{db: db, repo: repo, scanSvc: scanSvc, auditSvc: auditSvc}
+}
+
+// mobileTask is a loaded task of any type
+type mobileTask struct {
+	kind        string
+	id          uint
+	number      string
+	status      string
+	warehouseID uint
+	open        bool
+	createdAt   time.Time
+
+	putaway  *models.PutawayTask
+	pick     *models.PickList
+	count    *models.CycleCountTask
+	transfer *models.StockTransfer
+	// confirmed holds the transfer items already scanned (shipped or received, depending on kind)
+	confirmed map[uint]bool
+}
+
+func putawayMobileTask(t *models.PutawayTask) *mobileTask {
+	return &mobileTask{
+		kind: models.MobileTaskPutaway, id: t.ID, number: t.TaskNumber, status: t.Status, warehouseID: t.WarehouseID,
+		open: t.Status == PutawayPending || t.Status == PutawayInProgress, createdAt: t.CreatedAt, putaway: t,
+	}
+}
+
+func pickMobileTask(l *models.PickList) *mobileTask {
+	return &mobileTask{
+		kind: models.MobileTaskPick, id: l.ID, number: l.PickNumber, status: l.Status, warehouseID: l.WarehouseID,
+		open: l.Status == PickListOpen || l.Status == PickListInProgress, createdAt: l.CreatedAt, pick: l,
+	}
+}
+
+func countMobileTask(t *models.CycleCountTask) *mobileTask {
+	return &mobileTask{
+		kind: models.MobileTaskCount, id: t.ID, number: t.TaskNumber, status: t.Status, warehouseID: t.WarehouseID,
+		open: t.Status == CycleCountPending || t.Status == CycleCountRecount, createdAt: t.CreatedAt, count: t,
+	}
+}
+
+// transferMobileTask is the shipping of a transfer from its source warehouse or, for a receive
+// task, its receipt at the destination
+func transferMobileTask(kind string, st *models.StockTransfer, confirmed []uint) *mobileTask {
+	t := &mobileTask{
+		kind: kind, id: st.ID, number: st.TransferNumber, status: st.Status, warehouseID: st.FromWarehouseID,
+		open: !st.IsPosted && (st.Status == "draft" || st.Status == "approved"), createdAt: st.CreatedAt, transfer: st,
+		confirmed: make(map[uint]bool, len(confirmed)),
+	}
+	if kind == models.MobileTaskReceive {
+		t.warehouseID = st.ToWarehouseID
+		t.open = st.Status == "shipped"
+	}
+	for _, id := range confirmed {
+		t.confirmed[id] = true
+	}
+	return t
+}
+
+func (s *mobileTaskService) load(repo repository.MobileTaskRepository, taskType string, id uint) (*mobileTask, error) {
+	var (
+		t   *mobileTask
+		err error
+	)
+	switch taskType {
+	case models.MobileTaskPutaway:
+		var task *models.PutawayTask
+		if task, err = repo.GetPutawayTask(id); err == nil {
+			t = putawayMobileTask(task)
+		}
+	case models.MobileTaskPick:
+		var list *models.PickList
+		if list, err = repo.GetPickList(id); err == nil {
+			t = pickMobileTask(list)
+		}
+	case models.MobileTaskCount:
+		var task *models.CycleCountTask
+		if task, err = repo.GetCountTask(id); err == nil {
+			t = countMobileTask(task)
+		}
+	case models.MobileTaskTransfer, models.MobileTaskReceive:
+		var st *models.StockTransfer
+		if st, err = repo.GetTransfer(id); err == nil {
+			var confirmed []uint
+			if confirmed, err = repo.ConfirmedSteps(taskType, id); err == nil {
+				t = transferMobileTask(taskType, st, confirmed)
+			}
+		}
+	default:
+		return nil, ErrMobileTaskNotFound
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMobileTaskNotFound
+	}
+	return t, err
+}
+
+func (s *mobileTaskService) ListTasks(taskType string, q repository.MobileTaskQuery) ([]dto.MobileTask, error) {
+	types := mobileTaskTypes
+	if taskType != "" {
+		types = []string{taskType}
+	}
+
+	var tasks []*mobileTask
+	for _, kind := range types {
+		switch kind {
+		case models.MobileTaskPutaway:
+			rows, err := s.repo.OpenPutawayTasks(q)
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				tasks = append(tasks, putawayMobileTask(row))
+			}
+		case models.MobileTaskPick:
+			rows, err := s.repo.OpenPickLists(q)
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				tasks = append(tasks, pickMobileTask(row))
+			}
+		case models.MobileTaskCount:
+			rows, err := s.repo.OpenCountTasks(q)
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				tasks = append(tasks, countMobileTask(row))
+			}
+		case models.MobileTaskTransfer, models.MobileTaskReceive:
+			var rows []*models.StockTransfer
+			var err error
+			if kind == models.MobileTaskReceive {
+				rows, err = s.repo.OpenReceipts(q)
+			} else {
+				rows, err = s.repo.OpenTransfers(q)
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				confirmed, err := s.repo.ConfirmedSteps(kind, row.ID)
+				if err != nil {
+					return nil, err
+				}
+				tasks = append(tasks, transferMobileTask(kind, row, confirmed))
+			}
+		default:
+			return nil, fmt.Errorf("unknown task type %q", kind)
+		}
+	}
+
+	// oldest work first across all types
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].createdAt.Before(tasks[j].createdAt)
+	})
+	if q.Limit > 0 && len(tasks) > q.Limit {
+		tasks = tasks[:q.Limit]
+	}
+	return s.summaries(s.repo, tasks)
+}
+
+func (s *mobileTaskService) GetTask(taskType string, id uint) (*dto.MobileTaskDetail, error) {
+	t, err := s.load(s.repo, taskType, id)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.summaries(s.repo, []*mobileTask{t})
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.itemNames(s.repo, []*mobileTask{t})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MobileTaskDetail{MobileTask: summaries[0], Steps: mobileSteps(t, names)}, nil
+}
+
+func (s *mobileTaskService) Claim(taskType string, id uint, userID uint, username string) (*dto.MobileTask, error) {
+	t, err := s.load(s.repo, taskType, id)
+	if err != nil {
+		return nil, err
+	}
+	if !t.open {
+		return nil, fmt.Errorf("%s is %s", t.number, t.status)
+	}
+	assignment, err := s.claim(s.repo, t, userID, username, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("mobile_task_assignments", "CLAIM", int64(assignment.ID), int64(userID), username, nil, map[string]interface{}{
+		"task_type": t.kind,
+		"task_id":   t.id,
+		"number":    t.number,
+	})
+	return s.summary(t)
+}
+
+// claim makes the user the active assignee of the task, unless another operator already is
+func (s *mobileTaskService) claim(repo repository.MobileTaskRepository, t *mobileTask, userID uint, username string, assignedBy *uint) (*models.MobileTaskAssignment, error) {
+	assignment := &models.MobileTaskAssignment{
+		TaskType:       t.kind,
+		TaskID:         t.id,
+		WarehouseID:    &t.warehouseID,
+		AssignedTo:     userID,
+		AssignedToName: username,
+		AssignedBy:     assignedBy,
+		Status:         models.MobileAssignmentActive,
+		ClaimedAt:      time.Now(),
+	}
+	created, err := repo.CreateAssignment(assignment)
+	if err != nil || created {
+		return assignment, err
+	}
+
+	active, err := repo.ActiveAssignments(t.kind, []uint{t.id})
+	if err != nil {
+		return nil, err
+	}
+	if len(active) == 0 {
+		return nil, errors.New("the task assignment changed, please retry")
+	}
+	if active[0].AssignedTo != userID {
+		return nil, fmt.Errorf("%w: %s", ErrMobileTaskClaimed, active[0].AssignedToName)
+	}
+	return active[0], nil
+}
+
+func (s *mobileTaskService) Release(taskType string, id uint, userID uint, username string) (*dto.MobileTask, error) {
+	t, err := s.load(s.repo, taskType, id)
+	if err != nil {
+		return nil, err
+	}
+	assignment, err := s.release(s.repo, t, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("mobile_task_assignments", "RELEASE", int64(assignment.ID), int64(userID), username, nil, map[string]interface{}{
+		"task_type": t.kind,
+		"task_id":   t.id,
+		"number":    t.number,
+	})
+	return s.summary(t)
+}
+
+// release ends the user's active assignment of the task
+func (s *mobileTaskService) release(repo repository.MobileTaskRepository, t *mobileTask, userID uint) (*models.MobileTaskAssignment, error) {
+	active, err := repo.ActiveAssignments(t.kind, []uint{t.id})
+	if err != nil {
+		return nil, err
+	}
+	if len(active) == 0 {
+		return nil, fmt.Errorf("%s is not claimed", t.number)
+	}
+	assignment := active[0]
+	if assignment.AssignedTo != userID {
+		return nil, fmt.Errorf("%w: %s", ErrMobileTaskClaimed, assignment.AssignedToName)
+	}
+	now := time.Now()
+	assignment.Status = models.MobileAssignmentReleased
+	assignment.ClosedAt = &now
+	return assignment, repo.UpdateAssignment(assignment)
+}
+
+// Assign hands a task to an operator, taking it away from whoever had it
+func (s *mobileTaskService) Assign(taskType string, id uint, req *dto.AssignMobileTaskRequest, userID uint, username string) (*dto.MobileTask, error) {
+	t, err := s.load(s.repo, taskType, id)
+	if err != nil {
+		return nil, err
+	}
+	if !t.open {
+		return nil, fmt.Errorf("%s is %s", t.number, t.status)
+	}
+	assignee, err := s.repo.UserName(req.UserID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	var assignment *models.MobileTaskAssignment
+	var previous *uint
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewMobileTaskRepository(tx)
+		active, err := txRepo.ActiveAssignments(t.kind, []uint{t.id})
+		if err != nil {
+			return err
+		}
+		for _, a := range active {
+			if a.AssignedTo == req.UserID {
+				assignment = a
+				return nil
+			}
+			now := time.Now()
+			a.Status = models.MobileAssignmentReleased
+			a.ClosedAt = &now
+			if err := txRepo.UpdateAssignment(a); err != nil {
+				return err
+			}
+			previous = &a.AssignedTo
+		}
+		assignment, err = s.claim(txRepo, t, req.UserID, assignee, &userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("mobile_task_assignments", "ASSIGN", int64(assignment.ID), int64(userID), username, nil, map[string]interface{}{
+		"task_type":         t.kind,
+		"task_id":           t.id,
+		"number":            t.number,
+		"assigned_to":       req.UserID,
+		"previous_assignee": previous,
+	})
+	return s.summary(t)
+}
+
+// ConfirmStep applies one scanned step through the task's own service. The confirmation is stored
+// under the device's request ID in the same transaction, so a retry of a step that went through
+// gets the stored answer instead of moving stock twice.
+func (s *mobileTaskService) ConfirmStep(taskType string, id uint, req *dto.ConfirmMobileStepRequest, userID uint, username string) (*dto.MobileStepResult, error) {
+	req.RequestID = strings.TrimSpace(req.RequestID)
+	if req.RequestID == "" {
+		return nil, errors.New("request_id is required to confirm a step")
+	}
+	hash := mobileRequestHash(taskType, id, req)
+	if result, err := s.replay(req.RequestID, userID, taskType, id, hash); result != nil || err != nil {
+		return result, err
+	}
+
+	var result *dto.MobileStepResult
+	var confirmation *models.MobileStepConfirmation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewMobileTaskRepository(tx)
+		if err := txRepo.LockTask(taskType, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMobileTaskNotFound
+			}
+			return err
+		}
+		t, err := s.load(txRepo, taskType, id)
+		if err != nil {
+			return err
+		}
+
+		confirmation = &models.MobileStepConfirmation{
+			RequestID:   req.RequestID,
+			UserID:      userID,
+			TaskType:    taskType,
+			TaskID:      id,
+			StepID:      req.StepID,
+			RequestHash: hash,
+			ConfirmedAt: time.Now(),
+		}
+		created, err := txRepo.CreateConfirmation(confirmation)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return errMobileReplay
+		}
+
+		if !t.open {
+			return fmt.Errorf("%s is %s", t.number, t.status)
+		}
+		assignment, err := s.claim(txRepo, t, userID, username, nil)
+		if err != nil {
+			return err
+		}
+		if err := s.confirm(tx, t, req, userID, username); err != nil {
+			return err
+		}
+
+		if t, err = s.load(txRepo, taskType, id); err != nil {
+			return err
+		}
+		if !t.open {
+			now := time.Now()
+			assignment.Status = models.MobileAssignmentCompleted
+			assignment.ClosedAt = &now
+			if err := txRepo.UpdateAssignment(assignment); err != nil {
+				return err
+			}
+		}
+		summaries, err := s.summaries(txRepo, []*mobileTask{t})
+		if err != nil {
+			return err
+		}
+		result = &dto.MobileStepResult{Task: summaries[0], StepID: req.StepID, Completed: !t.open}
+		response, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		return txRepo.SaveConfirmationResponse(confirmation.ID, response)
+	})
+	if errors.Is(err, errMobileReplay) {
+		result, err = s.replay(req.RequestID, userID, taskType, id, hash)
+		if result == nil && err == nil {
+			err = errors.New("the step is being confirmed, please retry")
+		}
+		return result, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("mobile_step_confirmations", "CONFIRM", int64(confirmation.ID), int64(userID), username, nil, map[string]interface{}{
+		"task_type":  taskType,
+		"task_id":    id,
+		"number":     result.Task.Number,
+		"step_id":    req.StepID,
+		"request_id": req.RequestID,
+		"completed":  result.Completed,
+	})
+	return result, nil
+}
+
+// replay returns the stored answer of an earlier confirmation with the same request ID, or nil
+func (s *mobileTaskService) replay(requestID string, userID uint, taskType string, taskID uint, hash string) (*dto.MobileStepResult, error) {
+	confirmation, err := s.repo.GetConfirmation(requestID)
+	if err != nil || confirmation == nil {
+		return nil, err
+	}
+	if confirmation.UserID != userID || confirmation.TaskType != taskType || confirmation.TaskID != taskID || confirmation.RequestHash != hash {
+		return nil, ErrMobileRequestReused
+	}
+	var result dto.MobileStepResult
+	if err := json.Unmarshal(confirmation.Response, &result); err != nil {
+		return nil, err
+	}
+	result.Replayed = true
+	return &result, nil
+}
+
+// mobileRequestHash fingerprints a step confirmation, so that a reused request ID with other data is refused
+func mobileRequestHash(taskType string, taskID uint, req *dto.ConfirmMobileStepRequest) string {
+	body := *req
+	body.RequestID = ""
+	data, _ := json.Marshal(struct {
+		TaskType string                        `json:"task_type"`
+		TaskID   uint                          `json:"task_id"`
+		Step     *dto.ConfirmMobileStepRequest `json:"step"`
+	}{taskType, taskID, &body})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// confirm checks the scans of one step and applies it with the task's service bound to the transaction
+func (s *mobileTaskService) confirm(tx *gorm.DB, t *mobileTask, req *dto.ConfirmMobileStepRequest, userID uint, username string) error {
+	switch t.kind {
+	case models.MobileTaskPutaway:
+		task := t.putaway
+		if req.LocationScan == "" {
+			return errors.New("scan the storage location")
+		}
+		location, err := s.scanLocation(req.LocationScan, t.warehouseID)
+		if err != nil {
+			return err
+		}
+		if req.ItemScan != "" {
+			if _, err := s.scanItem(req.ItemScan, task.ItemType, task.ItemID, task.BatchNumber); err != nil {
+				return err
+			}
+		}
+		if req.Quantity != nil && *req.Quantity <= 0 {
+			return errors.New("quantity must be greater than zero")
+		}
+		putaway := NewPutawayService(tx, repository.NewPutawayTaskRepository(tx), s.auditSvc)
+		_, err = putaway.Confirm(task.ID, &dto.ConfirmPutawayRequest{LocationID: location.ID, Quantity: req.Quantity, Notes: req.Notes}, userID, username)
+		return err
+
+	case models.MobileTaskPick:
+		line := pickListLine(t.pick, req.StepID)
+		if line == nil {
+			return fmt.Errorf("line %d is not on pick list %s", req.StepID, t.number)
+		}
+		if req.ItemScan == "" {
+			return errors.New("scan the item")
+		}
+		scanned, err := s.scanItem(req.ItemScan, "finished_product", line.FinishedProductID, "")
+		if err != nil {
+			return err
+		}
+		pick := dto.ConfirmPickLineRequest{LineID: line.ID, PickedQuantity: req.Quantity, ShortReason: req.ShortReason, Notes: req.Notes}
+		if req.LocationScan != "" {
+			location, err := s.scanLocation(req.LocationScan, t.warehouseID)
+			if err != nil {
+				return err
+			}
+			if line.LocationID == nil || *line.LocationID != location.ID {
+				pick.LocationID = &location.ID
+			}
+		}
+		// a scanned lot label of another batch than allocated is a substitution
+		if scanned.Batch != "" && scanned.Batch != line.BatchNumber {
+			pick.BatchNumber = &scanned.Batch
+		}
+		picks := NewPickListService(tx, repository.NewPickListRepository(tx), s.auditSvc)
+		_, err = picks.Confirm(t.id, &dto.ConfirmPicksRequest{Lines: []dto.ConfirmPickLineRequest{pick}}, userID, username)
+		return err
+
+	case models.MobileTaskCount:
+		task := t.count
+		if req.Quantity == nil {
+			return errors.New("enter the counted quantity")
+		}
+		if req.ItemScan == "" {
+			return errors.New("scan the item")
+		}
+		if _, err := s.scanItem(req.ItemScan, task.ItemType, task.ItemID, task.BatchNumber); err != nil {
+			return err
+		}
+		if task.WarehouseLocationID != nil {
+			if req.LocationScan == "" {
+				return errors.New("scan the location")
+			}
+			location, err := s.scanLocation(req.LocationScan, t.warehouseID)
+			if err != nil {
+				return err
+			}
+			if location.ID != *task.WarehouseLocationID {
+				return fmt.Errorf("%w: count %s is at %s", ErrScanMismatch, t.number, locationPath(task.Location))
+			}
+		}
+		counts := NewCycleCountService(tx, repository.NewCycleCountRepository(tx), s.auditSvc)
+		_, err := counts.Count(task.ID, &dto.EnterCycleCountRequest{CountedQuantity: req.Quantity, Notes: req.Notes}, userID, username)
+		return err
+
+	case models.MobileTaskTransfer:
+		st := t.transfer
+		item := transferItem(st, req.StepID)
+		if item == nil {
+			return fmt.Errorf("item %d is not on transfer %s", req.StepID, t.number)
+		}
+		if t.confirmed[item.ID] {
+			return fmt.Errorf("item %d of transfer %s is already confirmed", item.ID, t.number)
+		}
+		if req.ItemScan == "" {
+			return errors.New("scan the item")
+		}
+		if _, err := s.scanItem(req.ItemScan, item.ItemType, item.ItemID, item.BatchNumber); err != nil {
+			return err
+		}
+		if req.LocationScan != "" && item.FromLocationID != nil {
+			location, err := s.scanLocation(req.LocationScan, st.FromWarehouseID)
+			if err != nil {
+				return err
+			}
+			if location.ID != *item.FromLocationID {
+				return fmt.Errorf("%w: the item is taken from %s", ErrScanMismatch, locationPath(item.FromLocation))
+			}
+		}
+		if req.Quantity != nil && math.Abs(*req.Quantity-item.Quantity) > qtyEpsilon {
+			return fmt.Errorf("the transfer moves %s; report an exception for a different quantity", formatQty(item.Quantity))
+		}
+
+		// the transfer is shipped with the last item
+		if len(t.confirmed)+1 < len(st.Items) {
+			return nil
+		}
+		transfers := NewStockTransferService(tx, repository.NewStockTransferRepository(tx), repository.NewWarehouseRepository(tx), repository.NewStockBalanceRepository(tx))
+		_, err := transfers.ShipTransfer(st.ID, &dto.ShipStockTransferRequest{}, userID)
+		return err
+
+	case models.MobileTaskReceive:
+		st := t.transfer
+		item := transferItem(st, req.StepID)
+		if item == nil {
+			return fmt.Errorf("item %d is not on transfer %s", req.StepID, t.number)
+		}
+		if t.confirmed[item.ID] {
+			return fmt.Errorf("item %d of transfer %s is already received", item.ID, t.number)
+		}
+		if req.ItemScan == "" {
+			return errors.New("scan the item")
+		}
+		if _, err := s.scanItem(req.ItemScan, item.ItemType, item.ItemID, item.BatchNumber); err != nil {
+			return err
+		}
+		// a scanned location is where the item is put on arrival, instead of the planned one
+		if req.LocationScan != "" {
+			location, err := s.scanLocation(req.LocationScan, st.ToWarehouseID)
+			if err != nil {
+				return err
+			}
+			item.ToLocationID = &location.ID
+		}
+		received := item.ShippedQuantity
+		if req.Quantity != nil {
+			received = roundQty(*req.Quantity)
+		}
+		if received > item.ShippedQuantity+qtyEpsilon {
+			return fmt.Errorf("only %s was shipped", formatQty(item.ShippedQuantity))
+		}
+		item.DiscrepancyReason = ""
+		if received < item.ShippedQuantity-qtyEpsilon {
+			if req.ShortReason == "" {
+				return fmt.Errorf("%s short of the %s shipped, give a short reason", formatQty(item.ShippedQuantity-received), formatQty(item.ShippedQuantity))
+			}
+			item.DiscrepancyReason = req.ShortReason
+		}
+		item.ReceivedQuantity = received
+		item.UpdatedBy = &userID
+		if err := repository.NewStockTransferRepository(tx).UpdateItem(item); err != nil {
+			return err
+		}
+
+		// the transfer is received with the last item, with what was scanned for each
+		if len(t.confirmed)+1 < len(st.Items) {
+			return nil
+		}
+		receipt := &dto.ReceiveStockTransferRequest{}
+		for _, it := range st.Items {
+			quantity := it.ReceivedQuantity
+			receipt.Items = append(receipt.Items, dto.ReceiveStockTransferItemRequest{
+				ItemID:            it.ID,
+				ReceivedQuantity:  &quantity,
+				ToLocationID:      it.ToLocationID,
+				DiscrepancyReason: it.DiscrepancyReason,
+			})
+		}
+		transfers := NewStockTransferService(tx, repository.NewStockTransferRepository(tx), repository.NewWarehouseRepository(tx), repository.NewStockBalanceRepository(tx))
+		_, err := transfers.ReceiveTransfer(st.ID, receipt, userID)
+		return err
+	}
+	return ErrMobileTaskNotFound
+}
+
+// scanLocation resolves a scanned location label, which must belong to the warehouse
+func (s *mobileTaskService) scanLocation(code string, warehouseID uint) (*dto.ScanLocation, error) {
+	return resolveLocationScan(s.scanSvc, code, warehouseID)
+}
+
+// resolveLocationScan resolves a scanned location label, which must belong to the warehouse
+func resolveLocationScan(scanSvc ScanService, code string, warehouseID uint) (*dto.ScanLocation, error) {
+	result, err := scanSvc.Resolve(&dto.ScanRequest{Code: code})
+	if err != nil && !errors.Is(err, ErrScanNotRecognised) {
+		return nil, err
+	}
+	if result == nil || result.Location == nil {
+		return nil, fmt.Errorf("%w: %s is not a location", ErrScanMismatch, code)
+	}
+	if result.Location.WarehouseID != warehouseID {
+		return nil, fmt.Errorf("%w: location %s is in another warehouse", ErrScanMismatch, result.Location.Code)
+	}
+	return result.Location, nil
+}
+
+// scanItem resolves a scanned item or lot label, which must be the expected item and, when
+// batch is given and the label carries one, the expected batch
+func (s *mobileTaskService) scanItem(code, itemType string, itemID uint, batch string) (*dto.ScanResult, error) {
+	result, err := s.scanSvc.Resolve(&dto.ScanRequest{Code: code})
+	if err != nil && !errors.Is(err, ErrScanNotRecognised) {
+		return nil, err
+	}
+	if result == nil || result.Item == nil || result.Item.ItemType != itemType || result.Item.ItemID != itemID {
+		return nil, fmt.Errorf("%w: %s is not the item of this step", ErrScanMismatch, code)
+	}
+	if batch != "" && result.Batch != "" && result.Batch != batch {
+		return nil, fmt.Errorf("%w: scanned batch %s, expected %s", ErrScanMismatch, result.Batch, batch)
+	}
+	return result, nil
+}
+
+func pickListLine(list *models.PickList, id uint) *models.PickListLine {
+	for _, line := range list.Lines {
+		if line.ID == id {
+			return line
+		}
+	}
+	return nil
+}
+
+func transferItem(st *models.StockTransfer, id uint) *models.StockTransferItem {
+	for i := range st.Items {
+		if st.Items[i].ID == id {
+			return &st.Items[i]
+		}
+	}
+	return nil
+}
+
+func (s *mobileTaskService) ReportException(taskType string, id uint, req *dto.ReportMobileExceptionRequest, userID uint, username string) (*models.MobileTaskException, error) {
+	t, err := s.load(s.repo, taskType, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.StepID > 0 {
+		switch {
+		case t.pick != nil && pickListLine(t.pick, req.StepID) == nil:
+			return nil, fmt.Errorf("line %d is not on pick list %s", req.StepID, t.number)
+		case t.transfer != nil && transferItem(t.transfer, req.StepID) == nil:
+			return nil, fmt.Errorf("item %d is not on transfer %s", req.StepID, t.number)
+		}
+	}
+
+	exception := &models.MobileTaskException{
+		TaskType:       t.kind,
+		TaskID:         t.id,
+		TaskNumber:     t.number,
+		StepID:         req.StepID,
+		WarehouseID:    &t.warehouseID,
+		ExceptionType:  req.ExceptionType,
+		Quantity:       req.Quantity,
+		ScannedCode:    req.ScannedCode,
+		Notes:          req.Notes,
+		Status:         models.MobileExceptionOpen,
+		ReportedBy:     &userID,
+		ReportedByName: username,
+		ReportedAt:     time.Now(),
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewMobileTaskRepository(tx)
+		if err := txRepo.CreateException(exception); err != nil {
+			return err
+		}
+		if req.Release {
+			_, err := s.release(txRepo, t, userID)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("mobile_task_exceptions", "REPORT", int64(exception.ID), int64(userID), username, nil, exception)
+	return exception, nil
+}
+
+func (s *mobileTaskService) ListExceptions(filters map[string]interface{}, offset, limit int) ([]*models.MobileTaskException, int64, error) {
+	return s.repo.ListExceptions(filters, offset, limit)
+}
+
+func (s *mobileTaskService) ResolveException(id uint, req *dto.ResolveMobileExceptionRequest, userID uint, username string) (*models.MobileTaskException, error) {
+	exception, err := s.repo.GetException(id)
+	if err != nil {
+		return nil, err
+	}
+	if exception.Status != models.MobileExceptionOpen {
+		return nil, fmt.Errorf("exception is %s", exception.Status)
+	}
+
+	now := time.Now()
+	exception.Status = models.MobileExceptionResolved
+	exception.ResolvedBy = &userID
+	exception.ResolvedAt = &now
+	exception.Resolution = req.Resolution
+	if err := s.repo.UpdateException(exception); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("mobile_task_exceptions", "RESOLVE", int64(exception.ID), int64(userID), username, nil, map[string]interface{}{
+		"resolution": req.Resolution,
+	})
+	return exception, nil
+}
+
+// mobileItemNames are item codes and names by item type and ID
+type mobileItemNames map[string]map[uint]repository.MobileItemName
+
+func (n mobileItemNames) item(itemType string, id uint) *dto.MobileItem {
+	name := n[itemType][id]
+	return &dto.MobileItem{Type: itemType, ID: id, Code: name.Code, Name: name.Name}
+}
+
+// itemNames loads the codes and names of every item on the tasks
+func (s *mobileTaskService) itemNames(repo repository.MobileTaskRepository, tasks []*mobileTask) (mobileItemNames, error) {
+	ids := make(map[string][]uint)
+	for _, t := range tasks {
+		switch {
+		case t.putaway != nil:
+			ids[t.putaway.ItemType] = append(ids[t.putaway.ItemType], t.putaway.ItemID)
+		case t.count != nil:
+			ids[t.count.ItemType] = append(ids[t.count.ItemType], t.count.ItemID)
+		case t.pick != nil:
+			for _, line := range t.pick.Lines {
+				ids["finished_product"] = append(ids["finished_product"], line.FinishedProductID)
+			}
+		case t.transfer != nil:
+			for _, item := range t.transfer.Items {
+				ids[item.ItemType] = append(ids[item.ItemType], item.ItemID)
+			}
+		}
+	}
+
+	names := make(mobileItemNames, len(ids))
+	for itemType, list := range ids {
+		byID, err := repo.ItemNames(itemType, uniqueUints(list))
+		if err != nil {
+			return nil, err
+		}
+		names[itemType] = byID
+	}
+	return names, nil
+}
+
+func (s *mobileTaskService) summary(t *mobileTask) (*dto.MobileTask, error) {
+	summaries, err := s.summaries(s.repo, []*mobileTask{t})
+	if err != nil {
+		return nil, err
+	}
+	return &summaries[0], nil
+}
+
+// summaries builds the compact list entries with item names and current assignees
+func (s *mobileTaskService) summaries(repo repository.MobileTaskRepository, tasks []*mobileTask) ([]dto.MobileTask, error) {
+	var single []*mobileTask
+	ids := make(map[string][]uint)
+	for _, t := range tasks {
+		if t.putaway != nil || t.count != nil {
+			single = append(single, t)
+		}
+		ids[t.kind] = append(ids[t.kind], t.id)
+	}
+	names, err := s.itemNames(repo, single)
+	if err != nil {
+		return nil, err
+	}
+	assigned := make(map[string]*models.MobileTaskAssignment)
+	for kind, list := range ids {
+		active, err := repo.ActiveAssignments(kind, list)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range active {
+			assigned[fmt.Sprintf("%s:%d", a.TaskType, a.TaskID)] = a
+		}
+	}
+
+	summaries := make([]dto.MobileTask, 0, len(tasks))
+	for _, t := range tasks {
+		summary := mobileSummary(t, names)
+		if a := assigned[fmt.Sprintf("%s:%d", t.kind, t.id)]; a != nil {
+			summary.AssignedTo = &a.AssignedTo
+			summary.AssignedToName = a.AssignedToName
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// mobileSummary is the list entry of a task; the item and quantity are shown for single-step tasks,
+// except the quantity of a count, which is counted blind
+func mobileSummary(t *mobileTask, names mobileItemNames) dto.MobileTask {
+	summary := dto.MobileTask{
+		Type:        t.kind,
+		ID:          t.id,
+		Number:      t.number,
+		Status:      t.status,
+		WarehouseID: t.warehouseID,
+		StepCount:   1,
+		CreatedAt:   t.createdAt,
+	}
+	switch {
+	case t.putaway != nil:
+		task := t.putaway
+		remaining := roundQty(task.RemainingQuantity())
+		summary.Item = names.item(task.ItemType, task.ItemID)
+		summary.From = locationPath(task.FromLocation)
+		summary.To = locationPath(task.SuggestedLocation)
+		summary.Batch = task.BatchNumber
+		summary.Quantity = &remaining
+		if !t.open {
+			summary.StepsDone = 1
+		}
+	case t.count != nil:
+		task := t.count
+		summary.Item = names.item(task.ItemType, task.ItemID)
+		summary.From = locationPath(task.Location)
+		summary.Batch = task.BatchNumber
+		if !t.open {
+			summary.StepsDone = 1
+		}
+	case t.pick != nil:
+		summary.StepCount = len(t.pick.Lines)
+		for _, line := range t.pick.Lines {
+			if line.Status != PickLinePending {
+				summary.StepsDone++
+			}
+		}
+	case t.transfer != nil:
+		summary.StepCount = len(t.transfer.Items)
+		summary.StepsDone = len(t.confirmed)
+		if t.transferDone() {
+			summary.StepsDone = summary.StepCount
+		}
+	}
+	return summary
+}
+
+// transferDone tells whether every item of a transfer task is through: shipped for a
+// transfer task, received for a receive task
+func (t *mobileTask) transferDone() bool {
+	if t.kind == models.MobileTaskReceive {
+		return t.transfer.Status == "received"
+	}
+	return t.transfer.IsPosted || t.transfer.ShippedAt != nil
+}
+
+// mobileSteps lists what there is to scan for a task
+func mobileSteps(t *mobileTask, names mobileItemNames) []dto.MobileStep {
+	var steps []dto.MobileStep
+	switch {
+	case t.putaway != nil:
+		task := t.putaway
+		remaining := roundQty(task.RemainingQuantity())
+		done := roundQty(task.PutawayQuantity)
+		step := dto.MobileStep{
+			Sequence:     1,
+			Item:         names.item(task.ItemType, task.ItemID),
+			From:         locationPath(task.FromLocation),
+			To:           locationPath(task.SuggestedLocation),
+			Batch:        task.BatchNumber,
+			Lot:          task.LotNumber,
+			Expiry:       task.ExpiryDate,
+			Quantity:     &remaining,
+			DoneQuantity: &done,
+			Status:       "pending",
+		}
+		if !t.open {
+			step.Status = "done"
+		}
+		steps = append(steps, step)
+
+	case t.count != nil:
+		task := t.count
+		step := dto.MobileStep{
+			Sequence: 1,
+			Item:     names.item(task.ItemType, task.ItemID),
+			From:     locationPath(task.Location),
+			Batch:    task.BatchNumber,
+			Lot:      task.LotNumber,
+			Status:   "pending",
+		}
+		if !t.open {
+			step.Status = "done"
+		}
+		steps = append(steps, step)
+
+	case t.pick != nil:
+		for i, line := range t.pick.Lines {
+			quantity := line.Quantity
+			step := dto.MobileStep{
+				ID:        line.ID,
+				Sequence:  i + 1,
+				Item:      names.item("finished_product", line.FinishedProductID),
+				From:      line.LocationPath,
+				Batch:     line.BatchNumber,
+				Lot:       line.LotNumber,
+				Expiry:    line.ExpiryDate,
+				Quantity:  &quantity,
+				Status:    "pending",
+				Reference: line.DONumber,
+			}
+			if line.Location != nil {
+				step.From = locationPath(line.Location)
+			}
+			if line.Status != PickLinePending {
+				picked := line.PickedQuantity
+				step.DoneQuantity = &picked
+				step.Status = "done"
+				if line.Status == PickLineShort {
+					step.Status = "short"
+				}
+			}
+			steps = append(steps, step)
+		}
+
+	case t.transfer != nil:
+		receive := t.kind == models.MobileTaskReceive
+		for i, item := range t.transfer.Items {
+			quantity := item.Quantity
+			if receive {
+				quantity = item.ShippedQuantity
+			}
+			step := dto.MobileStep{
+				ID:       item.ID,
+				Sequence: i + 1,
+				Item:     names.item(item.ItemType, item.ItemID),
+				From:     locationPath(item.FromLocation),
+				To:       locationPath(item.ToLocation),
+				Batch:    item.BatchNumber,
+				Lot:      item.LotNumber,
+				Quantity: &quantity,
+				Status:   "pending",
+			}
+			if item.ExpiryDate != nil {
+				expiry := item.ExpiryDate.Format("2006-01-02")
+				step.Expiry = &expiry
+			}
+			if receive {
+				step.From = locationPath(t.transfer.TransitLocation)
+			}
+			if t.confirmed[item.ID] || t.transferDone() {
+				step.Status = "done"
+				if receive {
+					received := item.ReceivedQuantity
+					step.DoneQuantity = &received
+					if received < item.ShippedQuantity-qtyEpsilon {
+						step.Status = "short"
+					}
+				}
+			}
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
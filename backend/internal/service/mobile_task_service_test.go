@@ -0,0 +1,130 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMobileRequestHash(t *testing.T) {
+	req := &dto.ConfirmMobileStepRequest{RequestID: "a1", StepID: 7, ItemScan: "4006381333931", Quantity: f64(5)}
+	hash := mobileRequestHash(models.MobileTaskPick, 3, req)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, "a1", req.RequestID, "the request is not modified")
+
+	retry := *req
+	retry.RequestID = "a2"
+	assert.Equal(t, hash, mobileRequestHash(models.MobileTaskPick, 3, &retry), "the request ID is not part of the fingerprint")
+
+	other := *req
+	other.Quantity = f64(4)
+	assert.NotEqual(t, hash, mobileRequestHash(models.MobileTaskPick, 3, &other))
+	assert.NotEqual(t, hash, mobileRequestHash(models.MobileTaskPick, 4, req))
+}
+
+func TestMobileSummaryCountIsBlind(t *testing.T) {
+	task := countMobileTask(&models.CycleCountTask{
+		ID: 9, TaskNumber: "CC-9", WarehouseID: 1, ItemType: "material", ItemID: 4, BatchNumber: "B1",
+		Status: CycleCountRecount, SystemQuantity: f64(120),
+	})
+	names := mobileItemNames{"material": {4: repository.MobileItemName{ID: 4, Code: "MAT-004", Name: "Glycerin"}}}
+
+	summary := mobileSummary(task, names)
+	assert.True(t, task.open)
+	assert.Nil(t, summary.Quantity)
+	assert.Equal(t, "MAT-004", summary.Item.Code)
+	assert.Equal(t, 1, summary.StepCount)
+	assert.Equal(t, 0, summary.StepsDone)
+
+	steps := mobileSteps(task, names)
+	require.Len(t, steps, 1)
+	assert.Nil(t, steps[0].Quantity)
+	assert.Equal(t, "pending", steps[0].Status)
+}
+
+func TestMobileStepsPickList(t *testing.T) {
+	task := pickMobileTask(&models.PickList{
+		ID: 2, PickNumber: "PK-2", WarehouseID: 1, Status: PickListInProgress, CreatedAt: time.Now(),
+		Lines: []*models.PickListLine{
+			{ID: 11, FinishedProductID: 5, LocationPath: "A-01", Quantity: 10, PickedQuantity: 10, Status: PickLinePicked, DONumber: "DO-1"},
+			{ID: 12, FinishedProductID: 5, LocationPath: "A-02", Quantity: 6, PickedQuantity: 4, Status: PickLineShort, DONumber: "DO-1"},
+			{ID: 13, FinishedProductID: 6, LocationPath: "B-01", Quantity: 3, Status: PickLinePending, DONumber: "DO-2"},
+		},
+	})
+
+	summary := mobileSummary(task, nil)
+	assert.Equal(t, 3, summary.StepCount)
+	assert.Equal(t, 2, summary.StepsDone)
+	assert.Nil(t, summary.Item)
+
+	steps := mobileSteps(task, mobileItemNames{})
+	require.Len(t, steps, 3)
+	assert.Equal(t, []string{"done", "short", "pending"}, []string{steps[0].Status, steps[1].Status, steps[2].Status})
+	assert.Equal(t, 4.0, *steps[1].DoneQuantity)
+	assert.Nil(t, steps[2].DoneQuantity)
+	assert.Equal(t, "B-01", steps[2].From)
+	assert.Equal(t, "DO-2", steps[2].Reference)
+	assert.Equal(t, uint(6), steps[2].Item.ID)
+}
+
+func TestMobileStepsTransfer(t *testing.T) {
+	st := &models.StockTransfer{
+		ID: 4, TransferNumber: "ST-4", FromWarehouseID: 1, ToWarehouseID: 2, Status: "approved",
+		Items: []models.StockTransferItem{
+			{ID: 21, ItemType: "material", ItemID: 1, Quantity: 5},
+			{ID: 22, ItemType: "material", ItemID: 2, Quantity: 8},
+		},
+	}
+	task := transferMobileTask(models.MobileTaskTransfer, st, []uint{22})
+	assert.True(t, task.open)
+	assert.Equal(t, uint(1), task.warehouseID, "transfers are worked in the source warehouse")
+
+	summary := mobileSummary(task, nil)
+	assert.Equal(t, 2, summary.StepCount)
+	assert.Equal(t, 1, summary.StepsDone)
+
+	steps := mobileSteps(task, mobileItemNames{})
+	assert.Equal(t, "pending", steps[0].Status)
+	assert.Equal(t, "done", steps[1].Status)
+	assert.Same(t, &st.Items[1], transferItem(st, 22))
+	assert.Nil(t, transferItem(st, 23))
+
+	st.IsPosted = true
+	assert.False(t, transferMobileTask(models.MobileTaskTransfer, st, nil).open)
+}
+
+func TestMobileStepsReceive(t *testing.T) {
+	shippedAt := time.Now()
+	st := &models.StockTransfer{
+		ID: 4, TransferNumber: "ST-4", FromWarehouseID: 1, ToWarehouseID: 2, Status: "shipped", ShippedAt: &shippedAt,
+		TransitLocation: &models.WarehouseLocation{Code: "TRANSIT-US"},
+		Items: []models.StockTransferItem{
+			{ID: 21, ItemType: "material", ItemID: 1, Quantity: 5, ShippedQuantity: 5},
+			{ID: 22, ItemType: "material", ItemID: 2, Quantity: 8, ShippedQuantity: 8, ReceivedQuantity: 6},
+		},
+	}
+	assert.False(t, transferMobileTask(models.MobileTaskTransfer, st, nil).open, "a shipped transfer is no longer a transfer task")
+
+	task := transferMobileTask(models.MobileTaskReceive, st, []uint{22})
+	assert.True(t, task.open)
+	assert.Equal(t, uint(2), task.warehouseID, "receipts are worked in the destination warehouse")
+
+	steps := mobileSteps(task, mobileItemNames{})
+	require.Len(t, steps, 2)
+	assert.Equal(t, "TRANSIT-US", steps[0].From)
+	assert.Equal(t, "pending", steps[0].Status)
+	assert.Nil(t, steps[0].DoneQuantity)
+	assert.Equal(t, "short", steps[1].Status)
+	assert.Equal(t, 6.0, *steps[1].DoneQuantity)
+
+	st.Status = "received"
+	st.IsPosted = true
+	task = transferMobileTask(models.MobileTaskReceive, st, nil)
+	assert.False(t, task.open)
+	assert.Equal(t, 2, mobileSummary(task, nil).StepsDone)
+}
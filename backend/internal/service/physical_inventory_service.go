@@ -0,0 +1,509 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Physical inventory statuses; a warehouse is frozen while it has an inventory counting
+const (
+	PhysicalInventoryCounting  = "counting"
+	PhysicalInventoryFinalized = "finalized"
+	PhysicalInventoryCancelled = "cancelled"
+)
+
+// PhysicalInventoryService runs full stocktakes: snapshot and freeze, count sheets per location,
+// counting rounds and a single physical_count adjustment on finalization
+type PhysicalInventoryService interface {
+	Create(req *dto.CreatePhysicalInventoryRequest, userID uint, username string) (*models.PhysicalInventory, error)
+	Get(id uint) (*models.PhysicalInventory, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.PhysicalInventory, int64, error)
+	Sheets(id uint) ([]dto.CountSheet, error)
+	RecordCounts(id uint, req *dto.RecordPhysicalCountsRequest, userID uint, username string) (*models.PhysicalInventory, error)
+	Finalize(id uint, req *dto.FinalizePhysicalInventoryRequest, userID uint, username string) (*models.PhysicalInventory, error)
+	Cancel(id uint, userID uint, username string) (*models.PhysicalInventory, error)
+}
+
+type physicalInventoryService struct {
+	db       *gorm.DB
+	repo     repository.PhysicalInventoryRepository
+	auditSvc AuditLogService
+}
+
+func NewPhysicalInventoryService(db *gorm.DB, repo repository.PhysicalInventoryRepository, auditSvc AuditLogService) PhysicalInventoryService {
+	return &physicalInventoryService{db: db, repo: repo, auditSvc: auditSvc}
+}
+
+// checkWarehouseNotFrozen rejects stock postings to warehouses that are being counted
+func checkWarehouseNotFrozen(tx *gorm.DB, warehouseIDs ...uint) error {
+	counting, err := repository.NewPhysicalInventoryRepository(tx).CountingInventories(uniqueUints(warehouseIDs))
+	if err != nil {
+		return err
+	}
+	if len(counting) > 0 {
+		return fmt.Errorf("warehouse %d is frozen for physical inventory %s", counting[0].WarehouseID, counting[0].InventoryNumber)
+	}
+	return nil
+}
+
+// assignSheetNumbers orders the lines by location (unlocated stock last) and gives every
+// location its own count sheet
+func assignSheetNumbers(lines []*models.PhysicalInventoryLine) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, b := lines[i], lines[j]
+		if (a.WarehouseLocationID == nil) != (b.WarehouseLocationID == nil) {
+			return b.WarehouseLocationID == nil
+		}
+		if a.LocationPath != b.LocationPath {
+			return a.LocationPath < b.LocationPath
+		}
+		if a.ItemType != b.ItemType {
+			return a.ItemType < b.ItemType
+		}
+		return a.ItemID < b.ItemID
+	})
+	sheet := 0
+	var last *uint
+	for i, line := range lines {
+		if i == 0 || !sameLocation(last, line.WarehouseLocationID) {
+			sheet++
+		}
+		line.SheetNumber = sheet
+		last = line.WarehouseLocationID
+	}
+}
+
+func sameLocation(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// applyCount makes the quantity the latest count of the line and recomputes its variance
+func applyCount(line *models.PhysicalInventoryLine, counted float64) {
+	counted = roundQty(counted)
+	variance := roundQty(counted - line.SnapshotQuantity)
+	value := roundMoney(variance * line.UnitCost)
+	line.CountedQuantity = &counted
+	line.VarianceQuantity = &variance
+	line.VarianceValue = &value
+}
+
+// inventoryValueImpact sums the value of the counted variances into gains and losses
+func inventoryValueImpact(lines []*models.PhysicalInventoryLine) (gain, loss float64) {
+	for _, line := range lines {
+		if line.VarianceValue == nil {
+			continue
+		}
+		if v := *line.VarianceValue; v > 0 {
+			gain += v
+		} else {
+			loss -= v
+		}
+	}
+	return roundMoney(gain), roundMoney(loss)
+}
+
+func locationPath(location *models.WarehouseLocation) string {
+	if location == nil {
+		return ""
+	}
+	return location.GetFullLocation()
+}
+
+func (s *physicalInventoryService) Create(req *dto.CreatePhysicalInventoryRequest, userID uint, username string) (*models.PhysicalInventory, error) {
+	var warehouse models.Warehouse
+	if err := s.db.First(&warehouse, req.WarehouseID).Error; err != nil {
+		return nil, errors.New("warehouse not found")
+	}
+
+	var pi *models.PhysicalInventory
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewPhysicalInventoryRepository(tx)
+		if err := checkWarehouseNotFrozen(tx, req.WarehouseID); err != nil {
+			return err
+		}
+
+		balances, err := txRepo.Snapshot(req.WarehouseID)
+		if err != nil {
+			return err
+		}
+		lines := make([]*models.PhysicalInventoryLine, 0, len(balances))
+		for _, b := range balances {
+			lines = append(lines, &models.PhysicalInventoryLine{
+				WarehouseLocationID: b.WarehouseLocationID,
+				LocationPath:        locationPath(b.WarehouseLocation),
+				ItemType:            b.ItemType,
+				ItemID:              b.ItemID,
+				BatchNumber:         b.BatchNumber,
+				LotNumber:           b.LotNumber,
+				ExpiryDate:          b.ExpiryDate,
+				SnapshotQuantity:    roundQty(b.Quantity),
+				UnitCost:            b.UnitCost,
+			})
+		}
+		assignSheetNumbers(lines)
+
+		prefix := fmt.Sprintf("PI-%s-", time.Now().Format("2006"))
+		count, err := txRepo.CountByInventoryNumber(prefix)
+		if err != nil {
+			return err
+		}
+		pi = &models.PhysicalInventory{
+			InventoryNumber: fmt.Sprintf("%s%06d", prefix, count+1),
+			WarehouseID:     req.WarehouseID,
+			Status:          PhysicalInventoryCounting,
+			SnapshotAt:      time.Now(),
+			Notes:           req.Notes,
+			CreatedBy:       &userID,
+			Lines:           lines,
+		}
+		return txRepo.Create(pi)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("physical_inventories", "CREATE", int64(pi.ID), int64(userID), username, nil, map[string]interface{}{
+		"inventory_number": pi.InventoryNumber,
+		"warehouse_id":     pi.WarehouseID,
+		"lines":            len(pi.Lines),
+	})
+	return s.repo.GetByID(pi.ID)
+}
+
+func (s *physicalInventoryService) Get(id uint) (*models.PhysicalInventory, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *physicalInventoryService) List(filters map[string]interface{}, offset, limit int) ([]*models.PhysicalInventory, int64, error) {
+	return s.repo.List(filters, offset, limit)
+}
+
+// Sheets groups the lines into one count sheet per location, without system quantities
+func (s *physicalInventoryService) Sheets(id uint) ([]dto.CountSheet, error) {
+	pi, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[string][]uint{}
+	for _, line := range pi.Lines {
+		ids[line.ItemType] = append(ids[line.ItemType], line.ItemID)
+	}
+	labels := map[string]map[uint]repository.ItemLabel{}
+	for itemType, itemIDs := range ids {
+		if labels[itemType], err = s.repo.ItemLabels(itemType, uniqueUints(itemIDs)); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheets []dto.CountSheet
+	for _, line := range pi.Lines {
+		if len(sheets) == 0 || sheets[len(sheets)-1].SheetNumber != line.SheetNumber {
+			sheets = append(sheets, dto.CountSheet{
+				SheetNumber:  line.SheetNumber,
+				LocationID:   line.WarehouseLocationID,
+				LocationPath: line.LocationPath,
+			})
+		}
+		label := labels[line.ItemType][line.ItemID]
+		sheet := &sheets[len(sheets)-1]
+		sheet.Lines = append(sheet.Lines, dto.CountSheetLine{
+			LineID:      line.ID,
+			ItemType:    line.ItemType,
+			ItemID:      line.ItemID,
+			ItemCode:    label.Code,
+			ItemName:    label.Name,
+			BatchNumber: line.BatchNumber,
+			LotNumber:   line.LotNumber,
+			ExpiryDate:  line.ExpiryDate,
+			CountRounds: line.CountRounds,
+		})
+	}
+	return sheets, nil
+}
+
+// RecordCounts adds a counting round to each submitted line. Stock found that was not in the
+// snapshot is added as a new line with a snapshot quantity of zero.
+func (s *physicalInventoryService) RecordCounts(id uint, req *dto.RecordPhysicalCountsRequest, userID uint, username string) (*models.PhysicalInventory, error) {
+	var pi *models.PhysicalInventory
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewPhysicalInventoryRepository(tx)
+		var err error
+		if pi, err = lockCountingInventory(txRepo, id); err != nil {
+			return err
+		}
+
+		byID := make(map[uint]*models.PhysicalInventoryLine, len(pi.Lines))
+		maxSheet := 0
+		for _, line := range pi.Lines {
+			byID[line.ID] = line
+			if line.SheetNumber > maxSheet {
+				maxSheet = line.SheetNumber
+			}
+		}
+
+		for i, r := range req.Lines {
+			var line *models.PhysicalInventoryLine
+			if r.LineID > 0 {
+				line = byID[r.LineID]
+				if line == nil {
+					return fmt.Errorf("line %d does not belong to this physical inventory", r.LineID)
+				}
+			} else {
+				if r.ItemType == "" || r.ItemID == 0 {
+					return fmt.Errorf("lines[%d]: line_id or item_type and item_id are required", i)
+				}
+				line = findInventoryLine(pi.Lines, r)
+				if line == nil {
+					line, err = s.addFoundLine(txRepo, pi, r, &maxSheet)
+					if err != nil {
+						return fmt.Errorf("lines[%d]: %w", i, err)
+					}
+					pi.Lines = append(pi.Lines, line)
+					byID[line.ID] = line
+				}
+			}
+
+			serials := cleanSerials(r.SerialNumbers)
+			if err := checkCountedSerials(tx, line.ItemType, line.ItemID, *r.CountedQuantity, serials, fmt.Sprintf("lines[%d]", i)); err != nil {
+				return err
+			}
+			line.SerialNumbers = serials
+			line.CountRounds++
+			applyCount(line, *r.CountedQuantity)
+			if err := txRepo.CreateCount(&models.PhysicalInventoryCount{
+				LineID:          line.ID,
+				Round:           line.CountRounds,
+				CountedQuantity: *line.CountedQuantity,
+				CountedBy:       &userID,
+				CountedAt:       now,
+				Notes:           r.Notes,
+			}); err != nil {
+				return err
+			}
+			if err := txRepo.UpdateLine(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("physical_inventories", "COUNT", int64(pi.ID), int64(userID), username, nil, map[string]interface{}{
+		"inventory_number": pi.InventoryNumber,
+		"lines":            len(req.Lines),
+	})
+	return s.repo.GetByID(pi.ID)
+}
+
+// lockCountingInventory locks the stocktake and reads it again inside the transaction, so a
+// concurrent request that finalized or cancelled it meanwhile is seen
+func lockCountingInventory(txRepo repository.PhysicalInventoryRepository, id uint) (*models.PhysicalInventory, error) {
+	if err := txRepo.Lock(id); err != nil {
+		return nil, err
+	}
+	pi, err := txRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if pi.Status != PhysicalInventoryCounting {
+		return nil, fmt.Errorf("physical inventory is %s", pi.Status)
+	}
+	return pi, nil
+}
+
+// findInventoryLine looks up the line of an item/location/batch submitted without a line ID
+func findInventoryLine(lines []*models.PhysicalInventoryLine, r dto.PhysicalCountLineRequest) *models.PhysicalInventoryLine {
+	for _, line := range lines {
+		if line.ItemType == r.ItemType && line.ItemID == r.ItemID && sameLocation(line.WarehouseLocationID, r.LocationID) &&
+			line.BatchNumber == r.BatchNumber && line.LotNumber == r.LotNumber {
+			return line
+		}
+	}
+	return nil
+}
+
+func (s *physicalInventoryService) addFoundLine(txRepo repository.PhysicalInventoryRepository, pi *models.PhysicalInventory,
+	r dto.PhysicalCountLineRequest, maxSheet *int) (*models.PhysicalInventoryLine, error) {
+	line := &models.PhysicalInventoryLine{
+		InventoryID:         pi.ID,
+		WarehouseLocationID: r.LocationID,
+		ItemType:            r.ItemType,
+		ItemID:              r.ItemID,
+		BatchNumber:         r.BatchNumber,
+		LotNumber:           r.LotNumber,
+		AddedDuringCount:    true,
+	}
+	if r.LocationID != nil {
+		location, err := txRepo.GetLocation(*r.LocationID)
+		if err != nil || location.WarehouseID != pi.WarehouseID {
+			return nil, errors.New("location not found in this warehouse")
+		}
+		line.LocationPath = locationPath(location)
+	}
+
+	labels, err := txRepo.ItemLabels(r.ItemType, []uint{r.ItemID})
+	if err != nil {
+		return nil, err
+	}
+	label, ok := labels[r.ItemID]
+	if !ok {
+		return nil, fmt.Errorf("%s %d not found", r.ItemType, r.ItemID)
+	}
+	if label.StandardCost != nil {
+		line.UnitCost = *label.StandardCost
+	}
+
+	// Found stock goes on the sheet of its location, or a new sheet
+	for _, existing := range pi.Lines {
+		if sameLocation(existing.WarehouseLocationID, r.LocationID) {
+			line.SheetNumber = existing.SheetNumber
+			break
+		}
+	}
+	if line.SheetNumber == 0 {
+		*maxSheet++
+		line.SheetNumber = *maxSheet
+	}
+
+	if err := txRepo.CreateLine(line); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// Finalize posts one physical_count adjustment for all counted variances and unfreezes the warehouse
+func (s *physicalInventoryService) Finalize(id uint, req *dto.FinalizePhysicalInventoryRequest, userID uint, username string) (*models.PhysicalInventory, error) {
+	var pi *models.PhysicalInventory
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewPhysicalInventoryRepository(tx)
+		var err error
+		if pi, err = lockCountingInventory(txRepo, id); err != nil {
+			return err
+		}
+
+		uncounted := 0
+		for _, line := range pi.Lines {
+			if line.CountRounds == 0 {
+				uncounted++
+			}
+		}
+		if uncounted > 0 && !req.ZeroUncounted {
+			return fmt.Errorf("%d lines have not been counted", uncounted)
+		}
+
+		reason := req.Reason
+		if reason == "" {
+			reason = "Physical inventory " + pi.InventoryNumber
+		}
+
+		sa := &models.StockAdjustment{
+			WarehouseID:    pi.WarehouseID,
+			AdjustmentDate: now,
+			AdjustmentType: "physical_count",
+			Reason:         reason,
+			Notes:          req.Notes,
+			Status:         "approved",
+			ApprovedBy:     &userID,
+			ApprovedAt:     &now,
+			CreatedBy:      &userID,
+			UpdatedBy:      &userID,
+		}
+		for _, line := range pi.Lines {
+			if line.CountRounds == 0 {
+				applyCount(line, 0)
+				if err := txRepo.UpdateLine(line); err != nil {
+					return err
+				}
+			}
+			if math.Abs(*line.VarianceQuantity) <= qtyEpsilon {
+				continue
+			}
+			serials, err := countedSerials(tx, line.ItemType, line.ItemID, pi.WarehouseID, line.WarehouseLocationID,
+				line.BatchNumber, line.LotNumber, line.SerialNumbers, *line.VarianceQuantity)
+			if err != nil {
+				return err
+			}
+			sa.Items = append(sa.Items, models.StockAdjustmentItem{
+				ItemType:            line.ItemType,
+				ItemID:              line.ItemID,
+				WarehouseLocationID: line.WarehouseLocationID,
+				BatchNumber:         line.BatchNumber,
+				LotNumber:           line.LotNumber,
+				ExpiryDate:          pickExpiry(line.ExpiryDate),
+				AdjustmentQuantity:  *line.VarianceQuantity,
+				PreviousQuantity:    line.SnapshotQuantity,
+				NewQuantity:         *line.CountedQuantity,
+				UnitCost:            line.UnitCost,
+				SerialNumbers:       serials,
+				Notes:               fmt.Sprintf("%s sheet %d", pi.InventoryNumber, line.SheetNumber),
+				CreatedBy:           &userID,
+				UpdatedBy:           &userID,
+			})
+		}
+
+		if len(sa.Items) > 0 {
+			sa.AdjustmentNumber = generateAdjustmentNumber(tx)
+			if err := repository.NewStockAdjustmentRepository(tx).Create(sa); err != nil {
+				return err
+			}
+			if err := postStockAdjustment(tx, sa, userID, now); err != nil {
+				return err
+			}
+			pi.StockAdjustmentID = &sa.ID
+		}
+
+		pi.GainValue, pi.LossValue = inventoryValueImpact(pi.Lines)
+		pi.NetValue = roundMoney(pi.GainValue - pi.LossValue)
+		pi.Status = PhysicalInventoryFinalized
+		pi.FinalizedBy = &userID
+		pi.FinalizedAt = &now
+		return txRepo.Update(pi)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("physical_inventories", "FINALIZE", int64(pi.ID), int64(userID), username, nil, map[string]interface{}{
+		"inventory_number":    pi.InventoryNumber,
+		"stock_adjustment_id": pi.StockAdjustmentID,
+		"net_value":           pi.NetValue,
+	})
+	return s.repo.GetByID(pi.ID)
+}
+
+// Cancel abandons the stocktake without posting and unfreezes the warehouse
+func (s *physicalInventoryService) Cancel(id uint, userID uint, username string) (*models.PhysicalInventory, error) {
+	var pi *models.PhysicalInventory
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewPhysicalInventoryRepository(tx)
+		var err error
+		if pi, err = lockCountingInventory(txRepo, id); err != nil {
+			return err
+		}
+		pi.Status = PhysicalInventoryCancelled
+		return txRepo.Update(pi)
+	})
+	if err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("physical_inventories", "CANCEL", int64(pi.ID), int64(userID), username, nil, map[string]interface{}{
+		"inventory_number": pi.InventoryNumber,
+	})
+	return s.repo.GetByID(pi.ID)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignSheetNumbers(t *testing.T) {
+	lines := []*models.PhysicalInventoryLine{
+		{ItemType: "material", ItemID: 1},
+		{ItemType: "material", ItemID: 2, WarehouseLocationID: uintPtr(7), LocationPath: "B-01"},
+		{ItemType: "material", ItemID: 3, WarehouseLocationID: uintPtr(5), LocationPath: "A-01"},
+		{ItemType: "finished_product", ItemID: 4, WarehouseLocationID: uintPtr(7), LocationPath: "B-01"},
+	}
+	assignSheetNumbers(lines)
+
+	assert.Equal(t, uint(3), lines[0].ItemID)
+	assert.Equal(t, 1, lines[0].SheetNumber)
+	assert.Equal(t, "finished_product", lines[1].ItemType)
+	assert.Equal(t, 2, lines[1].SheetNumber)
+	assert.Equal(t, 2, lines[2].SheetNumber)
+	assert.Nil(t, lines[3].WarehouseLocationID)
+	assert.Equal(t, 3, lines[3].SheetNumber)
+}
+
+func TestApplyCountAndValueImpact(t *testing.T) {
+	short := &models.PhysicalInventoryLine{SnapshotQuantity: 10, UnitCost: 2.5}
+	over := &models.PhysicalInventoryLine{SnapshotQuantity: 0, UnitCost: 4}
+	uncounted := &models.PhysicalInventoryLine{SnapshotQuantity: 3, UnitCost: 1}
+
+	applyCount(short, 8)
+	applyCount(short, 7) // a later round replaces the earlier one
+	applyCount(over, 1.5)
+
+	assert.Equal(t, -3.0, *short.VarianceQuantity)
+	assert.Equal(t, -7.5, *short.VarianceValue)
+	assert.Equal(t, 6.0, *over.VarianceValue)
+
+	gain, loss := inventoryValueImpact([]*models.PhysicalInventoryLine{short, over, uncounted})
+	assert.Equal(t, 6.0, gain)
+	assert.Equal(t, 7.5, loss)
+}
+
+func TestFindInventoryLine(t *testing.T) {
+	lines := []*models.PhysicalInventoryLine{
+		{ItemType: "material", ItemID: 1, WarehouseLocationID: uintPtr(5), BatchNumber: "B1"},
+		{ItemType: "material", ItemID: 1, BatchNumber: "B1"},
+	}
+	found := findInventoryLine(lines, dto.PhysicalCountLineRequest{ItemType: "material", ItemID: 1, BatchNumber: "B1"})
+	assert.Same(t, lines[1], found)
+
+	found = findInventoryLine(lines, dto.PhysicalCountLineRequest{ItemType: "material", ItemID: 1, LocationID: uintPtr(5), BatchNumber: "B1"})
+	assert.Same(t, lines[0], found)
+
+	assert.Nil(t, findInventoryLine(lines, dto.PhysicalCountLineRequest{ItemType: "material", ItemID: 1, LocationID: uintPtr(5)}))
+}
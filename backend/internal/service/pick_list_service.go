@@ -0,0 +1,662 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Pick list and pick line statuses
+const (
+	PickListOpen       = "open"
+	PickListInProgress = "in_progress"
+	PickListCompleted  = "completed"
+	PickListCancelled  = "cancelled"
+
+	PickLinePending = "pending"
+	PickLinePicked  = "picked"
+	PickLineShort   = "short"
+)
+
+// PickListService generates FEFO pick lists for delivery orders and records what was actually picked;
+// the confirmed picks are what ShipDeliveryOrder posts
+type PickListService interface {
+	Generate(req *dto.GeneratePickListRequest, userID uint, username string) (*models.PickList, error)
+	Get(id uint) (*models.PickList, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.PickList, int64, error)
+	Confirm(id uint, req *dto.ConfirmPicksRequest, userID uint, username string) (*models.PickList, error)
+	Cancel(id uint, userID uint, username string) (*models.PickList, error)
+}
+
+type pickListService struct {
+	db       *gorm.DB
+	repo     repository.PickListRepository
+	auditSvc AuditLogService
+}
+
+func NewPickListService(db *gorm.DB, repo repository.PickListRepository, auditSvc AuditLogService) PickListService {
+	return &pickListService{db: db, repo: repo, auditSvc: auditSvc}
+}
+
+// pickKey identifies a finished product balance within a warehouse (LocationID 0 = no location)
+type pickKey struct {
+	ProductID  uint
+	LocationID uint
+	Batch      string
+	Lot        string
+}
+
+func newPickKey(productID uint, locationID *uint, batch, lot string) pickKey {
+	key := pickKey{ProductID: productID, Batch: batch, Lot: lot}
+	if locationID != nil {
+		key.LocationID = *locationID
+	}
+	return key
+}
+
+// pickCandidate is a balance with the quantity that can still be allocated to pick lines
+type pickCandidate struct {
+	LocationID   *uint
+	LocationPath string
+	BatchNumber  string
+	LotNumber    string
+	ExpiryDate   *string
+	Available    float64
+}
+
+type pickAllocation struct {
+	*pickCandidate
+	Quantity float64
+}
+
+// dateOnly trims a date or timestamp string to YYYY-MM-DD
+func dateOnly(s string) string {
+	if len(s) > 10 {
+		return s[:10]
+	}
+	return s
+}
+
+// pickExpiry converts a balance expiry date to the form stored on delivery order items
+func pickExpiry(s *string) *time.Time {
+	if s == nil {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", dateOnly(*s))
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// allocateFEFO takes the quantity from the candidates expiring first (stock without expiry last,
+// otherwise in the given order) and reduces what they have available. It returns the allocations
+// and the quantity that could not be covered.
+func allocateFEFO(candidates []*pickCandidate, quantity float64) ([]pickAllocation, float64) {
+	ordered := append([]*pickCandidate(nil), candidates...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i].ExpiryDate, ordered[j].ExpiryDate
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return dateOnly(*a) < dateOnly(*b)
+	})
+
+	remaining := roundQty(quantity)
+	var allocations []pickAllocation
+	for _, c := range ordered {
+		if remaining <= qtyEpsilon {
+			break
+		}
+		if c.Available <= qtyEpsilon {
+			continue
+		}
+		take := roundQty(math.Min(c.Available, remaining))
+		c.Available = roundQty(c.Available - take)
+		remaining = roundQty(remaining - take)
+		allocations = append(allocations, pickAllocation{pickCandidate: c, Quantity: take})
+	}
+	if remaining <= qtyEpsilon {
+		remaining = 0
+	}
+	return allocations, remaining
+}
+
+// matchingCandidates keeps the candidates allowed by the location, batch and lot fixed on the item
+func matchingCandidates(candidates []*pickCandidate, item *models.DeliveryOrderItem) []*pickCandidate {
+	matching := make([]*pickCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if item.WarehouseLocationID != nil && (c.LocationID == nil || *c.LocationID != *item.WarehouseLocationID) {
+			continue
+		}
+		if item.BatchNumber != "" && c.BatchNumber != item.BatchNumber {
+			continue
+		}
+		if item.LotNumber != "" && c.LotNumber != item.LotNumber {
+			continue
+		}
+		matching = append(matching, c)
+	}
+	return matching
+}
+
+// pickCandidatesFor returns the pickable balances of a product, FEFO first, with what is still
+// available after reservations and the given pick allocations
+func pickCandidatesFor(repo repository.PickListRepository, warehouseID, productID uint, allocated map[pickKey]float64) ([]*pickCandidate, error) {
+	balances, err := repo.PickableBalances(warehouseID, productID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]*pickCandidate, 0, len(balances))
+	for _, b := range balances {
+		available := roundQty(b.Quantity - b.ReservedQuantity - allocated[newPickKey(productID, b.WarehouseLocationID, b.BatchNumber, b.LotNumber)])
+		if available <= qtyEpsilon {
+			continue
+		}
+		c := &pickCandidate{
+			LocationID:  b.WarehouseLocationID,
+			BatchNumber: b.BatchNumber,
+			LotNumber:   b.LotNumber,
+			ExpiryDate:  b.ExpiryDate,
+			Available:   available,
+		}
+		if b.WarehouseLocation != nil {
+			c.LocationPath = b.WarehouseLocation.GetFullLocation()
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// sortPickLines orders the lines along the location path (unlocated lines last) and numbers them
+func sortPickLines(lines []*models.PickListLine) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		a, b := lines[i], lines[j]
+		if (a.LocationPath == "") != (b.LocationPath == "") {
+			return b.LocationPath == ""
+		}
+		if a.LocationPath != b.LocationPath {
+			return a.LocationPath < b.LocationPath
+		}
+		if a.DONumber != b.DONumber {
+			return a.DONumber < b.DONumber
+		}
+		return a.FinishedProductID < b.FinishedProductID
+	})
+	for i, line := range lines {
+		line.Sequence = i + 1
+	}
+}
+
+// refreshPickListStatus derives the list status from its lines
+func refreshPickListStatus(list *models.PickList, userID uint, now time.Time) {
+	pending, confirmed := 0, 0
+	for _, line := range list.Lines {
+		if line.Status == PickLinePending {
+			pending++
+		} else if line.PickedAt != nil {
+			confirmed++
+		}
+	}
+	switch {
+	case pending == 0:
+		list.Status = PickListCompleted
+		list.CompletedBy = &userID
+		list.CompletedAt = &now
+	case confirmed > 0:
+		list.Status = PickListInProgress
+	default:
+		list.Status = PickListOpen
+	}
+}
+
+// allocatedByKey indexes the stock already promised to other pick lines
+func allocatedByKey(rows []repository.PickAllocation) map[pickKey]float64 {
+	allocated := make(map[pickKey]float64, len(rows))
+	for _, row := range rows {
+		allocated[newPickKey(row.FinishedProductID, row.LocationID, row.BatchNumber, row.LotNumber)] += row.Quantity
+	}
+	return allocated
+}
+
+// generatePickNumber creates a number like PK-2026-000001
+func generatePickNumber(repo repository.PickListRepository) (string, error) {
+	prefix := fmt.Sprintf("PK-%s-", time.Now().Format("2006"))
+	count, err := repo.CountByPickNumber(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%06d", prefix, count+1), nil
+}
+
+// Generate allocates every item of the delivery orders to balances by FEFO, skipping expired and
+// quarantined stock and what other pick lists already hold. Quantity that cannot be allocated is
+// recorded as a short line. The delivery orders move to "picking".
+func (s *pickListService) Generate(req *dto.GeneratePickListRequest, userID uint, username string) (*models.PickList, error) {
+	ids := uniqueUints(req.DeliveryOrderIDs)
+	var list *models.PickList
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		list, err = generatePickList(tx, ids, req.Notes, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("pick_lists", "CREATE", int64(list.ID), int64(userID), username, nil, map[string]interface{}{
+		"pick_number":     list.PickNumber,
+		"delivery_orders": ids,
+		"lines":           len(list.Lines),
+	})
+	return s.repo.GetByID(list.ID)
+}
+
+// generatePickList builds and saves the pick list for the delivery orders inside the caller's transaction
+func generatePickList(tx *gorm.DB, ids []uint, notes string, userID uint) (*models.PickList, error) {
+	txRepo := repository.NewPickListRepository(tx)
+	doRepo := repository.NewDeliveryOrderRepository(tx)
+
+	orders := make([]*models.DeliveryOrder, 0, len(ids))
+	for _, id := range ids {
+		do, err := doRepo.GetByID(id)
+		if err != nil {
+			return nil, fmt.Errorf("delivery order %d not found", id)
+		}
+		if do.IsPosted || do.Status != "draft" {
+			return nil, fmt.Errorf("delivery order %s is %s, only draft orders can be picked", do.DONumber, do.Status)
+		}
+		if len(orders) > 0 && do.WarehouseID != orders[0].WarehouseID {
+			return nil, errors.New("delivery orders of different warehouses cannot share a pick list")
+		}
+		if len(do.Items) == 0 {
+			return nil, fmt.Errorf("delivery order %s has no items", do.DONumber)
+		}
+		orders = append(orders, do)
+	}
+	if len(orders) == 0 {
+		return nil, errors.New("no delivery orders to pick")
+	}
+	warehouseID := orders[0].WarehouseID
+
+	rows, err := txRepo.AllocatedQuantities(warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	allocated := allocatedByKey(rows)
+
+	// Stock reserved for these orders is theirs to pick
+	orderIDs := make([]uint, 0, len(orders))
+	for _, do := range orders {
+		orderIDs = append(orderIDs, do.ID)
+	}
+	reservations, err := repository.NewStockReservationRepository(tx).ActiveForReferences(ReservationForDeliveryOrder, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	for key, held := range deliveryReservationsByKey(reservations) {
+		allocated[key] -= held
+	}
+
+	var productIDs []uint
+	for _, do := range orders {
+		for _, item := range do.Items {
+			productIDs = append(productIDs, item.FinishedProductID)
+		}
+	}
+	kits, err := repository.NewKitRepository(tx).ActiveByProductIDs(uniqueUints(productIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	stock := map[uint][]*pickCandidate{}
+	candidatesFor := func(productID uint) ([]*pickCandidate, error) {
+		if candidates, ok := stock[productID]; ok {
+			return candidates, nil
+		}
+		candidates, err := pickCandidatesFor(txRepo, warehouseID, productID, allocated)
+		if err != nil {
+			return nil, err
+		}
+		stock[productID] = candidates
+		return candidates, nil
+	}
+
+	var lines []*models.PickListLine
+	for _, do := range orders {
+		for i := range do.Items {
+			item := &do.Items[i]
+			for _, pick := range kitPicks(item, kits[item.FinishedProductID]) {
+				candidates, err := candidatesFor(pick.ProductID)
+				if err != nil {
+					return nil, err
+				}
+				// the location, batch and lot of the item are those of the kit, not of its components
+				if pick.KitProductID == nil {
+					candidates = matchingCandidates(candidates, item)
+				}
+				allocations, short := allocateFEFO(candidates, pick.Quantity)
+				for _, a := range allocations {
+					lines = append(lines, &models.PickListLine{
+						DeliveryOrderID:     do.ID,
+						DeliveryOrderItemID: item.ID,
+						DONumber:            do.DONumber,
+						FinishedProductID:   pick.ProductID,
+						KitProductID:        pick.KitProductID,
+						LocationID:          a.LocationID,
+						LocationPath:        a.LocationPath,
+						BatchNumber:         a.BatchNumber,
+						LotNumber:           a.LotNumber,
+						ExpiryDate:          a.ExpiryDate,
+						Quantity:            a.Quantity,
+						Status:              PickLinePending,
+					})
+				}
+				if short > 0 {
+					lines = append(lines, &models.PickListLine{
+						DeliveryOrderID:     do.ID,
+						DeliveryOrderItemID: item.ID,
+						DONumber:            do.DONumber,
+						FinishedProductID:   pick.ProductID,
+						KitProductID:        pick.KitProductID,
+						Quantity:            short,
+						Status:              PickLineShort,
+						ShortReason:         "insufficient stock at allocation",
+					})
+				}
+			}
+		}
+	}
+	sortPickLines(lines)
+
+	number, err := generatePickNumber(txRepo)
+	if err != nil {
+		return nil, err
+	}
+	list := &models.PickList{
+		PickNumber:  number,
+		WarehouseID: warehouseID,
+		Notes:       notes,
+		CreatedBy:   &userID,
+		Lines:       lines,
+	}
+	refreshPickListStatus(list, userID, time.Now())
+	if err := txRepo.Create(list); err != nil {
+		return nil, err
+	}
+	if err := txRepo.SetDeliveryOrderStatus(ids, "draft", "picking"); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *pickListService) Get(id uint) (*models.PickList, error) {
+	list, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("pick list not found")
+	}
+	return list, nil
+}
+
+func (s *pickListService) List(filters map[string]interface{}, offset, limit int) ([]*models.PickList, int64, error) {
+	return s.repo.List(filters, offset, limit)
+}
+
+// pickSubstituted reports whether the confirmation names another location, batch or lot than allocated
+func pickSubstituted(line *models.PickListLine, req *dto.ConfirmPickLineRequest) bool {
+	if req.LocationID != nil && (line.LocationID == nil || *req.LocationID != *line.LocationID) {
+		return true
+	}
+	if req.BatchNumber != nil && *req.BatchNumber != line.BatchNumber {
+		return true
+	}
+	return req.LotNumber != nil && *req.LotNumber != line.LotNumber
+}
+
+// Confirm records the picked quantity of each line. Less than allocated is a short pick;
+// naming another location/batch/lot substitutes the allocation after checking that stock.
+func (s *pickListService) Confirm(id uint, req *dto.ConfirmPicksRequest, userID uint, username string) (*models.PickList, error) {
+	list, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if list.Status == PickListCompleted || list.Status == PickListCancelled {
+		return nil, fmt.Errorf("pick list is %s", list.Status)
+	}
+
+	shorts, substitutions := 0, 0
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		shorts, substitutions, err = applyPickConfirmations(tx, list, req.Lines, userID, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("pick_lists", "PICK", int64(list.ID), int64(userID), username, nil, map[string]interface{}{
+		"pick_number":   list.PickNumber,
+		"lines":         len(req.Lines),
+		"short_picks":   shorts,
+		"substitutions": substitutions,
+		"status":        list.Status,
+	})
+	return s.repo.GetByID(list.ID)
+}
+
+// applyPickConfirmations updates the confirmed lines and the list status inside the caller's transaction
+func applyPickConfirmations(tx *gorm.DB, list *models.PickList, reqs []dto.ConfirmPickLineRequest, userID uint, now time.Time) (shorts, substitutions int, err error) {
+	txRepo := repository.NewPickListRepository(tx)
+	byID := make(map[uint]*models.PickListLine, len(list.Lines))
+	for _, line := range list.Lines {
+		byID[line.ID] = line
+	}
+	var allocated map[pickKey]float64
+
+	for i := range reqs {
+		r := &reqs[i]
+		line, ok := byID[r.LineID]
+		if !ok {
+			return 0, 0, fmt.Errorf("line %d is not on pick list %s", r.LineID, list.PickNumber)
+		}
+		if line.Status != PickLinePending {
+			return 0, 0, fmt.Errorf("line %d is already %s", line.ID, line.Status)
+		}
+
+		picked := line.Quantity
+		if r.PickedQuantity != nil {
+			picked = roundQty(*r.PickedQuantity)
+		}
+		if picked > line.Quantity+qtyEpsilon {
+			return 0, 0, fmt.Errorf("line %d: picked %s exceeds the %s to pick", line.ID, formatQty(picked), formatQty(line.Quantity))
+		}
+
+		if pickSubstituted(line, r) {
+			if picked <= qtyEpsilon {
+				return 0, 0, fmt.Errorf("line %d: a substitution needs a picked quantity", line.ID)
+			}
+			if allocated == nil {
+				rows, err := txRepo.AllocatedQuantities(list.WarehouseID)
+				if err != nil {
+					return 0, 0, err
+				}
+				allocated = allocatedByKey(rows)
+			}
+			if err := substitutePick(tx, list, line, r, picked, allocated); err != nil {
+				return 0, 0, err
+			}
+			substitutions++
+		}
+
+		line.PickedQuantity = picked
+		line.Status = PickLinePicked
+		if picked < line.Quantity-qtyEpsilon {
+			line.Status = PickLineShort
+			line.ShortReason = r.ShortReason
+			if line.ShortReason == "" {
+				line.ShortReason = "short pick"
+			}
+			shorts++
+		}
+		if r.Notes != "" {
+			line.Notes = r.Notes
+		}
+		line.PickedBy = &userID
+		line.PickedAt = &now
+		if err := txRepo.UpdateLine(line); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	refreshPickListStatus(list, userID, now)
+	return shorts, substitutions, txRepo.Update(list)
+}
+
+// substitutePick points the line at the stock the picker actually took, keeping the original allocation
+func substitutePick(tx *gorm.DB, list *models.PickList, line *models.PickListLine, r *dto.ConfirmPickLineRequest, picked float64, allocated map[pickKey]float64) error {
+	locationID, batch, lot := line.LocationID, line.BatchNumber, line.LotNumber
+	if r.LocationID != nil {
+		locationID = r.LocationID
+	}
+	if r.BatchNumber != nil {
+		batch = *r.BatchNumber
+	}
+	if r.LotNumber != nil {
+		lot = *r.LotNumber
+	}
+
+	path := ""
+	if locationID != nil {
+		var location models.WarehouseLocation
+		if err := tx.First(&location, *locationID).Error; err != nil {
+			return fmt.Errorf("location %d not found", *locationID)
+		}
+		if location.WarehouseID != list.WarehouseID {
+			return fmt.Errorf("location %s belongs to another warehouse", location.Code)
+		}
+		if location.LocationType == "quarantine" {
+			return fmt.Errorf("location %s is a quarantine area", location.Code)
+		}
+		if location.LocationType == LocationTypeInTransit {
+			return fmt.Errorf("location %s is an in-transit location", location.Code)
+		}
+		path = location.GetFullLocation()
+	}
+
+	balance, err := repository.NewStockBalanceRepository(tx).Get("finished_product", line.FinishedProductID, list.WarehouseID, locationID, batch, lot)
+	if err != nil {
+		return fmt.Errorf("line %d: no stock of the product at the substitute location/batch", line.ID)
+	}
+	if balance.ExpiryDate != nil && dateOnly(*balance.ExpiryDate) < time.Now().Format("2006-01-02") {
+		return fmt.Errorf("line %d: batch %s is expired", line.ID, balance.BatchNumber)
+	}
+	key := newPickKey(line.FinishedProductID, locationID, balance.BatchNumber, balance.LotNumber)
+	available := roundQty(balance.Quantity - balance.ReservedQuantity - allocated[key])
+	if available < picked-qtyEpsilon {
+		return fmt.Errorf("line %d: only %s available at the substitute location/batch", line.ID, formatQty(math.Max(available, 0)))
+	}
+	allocated[key] += picked
+
+	line.Substituted = true
+	line.AllocatedLocationID = line.LocationID
+	line.AllocatedBatchNumber = line.BatchNumber
+	line.AllocatedLotNumber = line.LotNumber
+	line.LocationID = locationID
+	line.LocationPath = path
+	line.BatchNumber = balance.BatchNumber
+	line.LotNumber = balance.LotNumber
+	line.ExpiryDate = balance.ExpiryDate
+	line.Location = nil
+	return nil
+}
+
+// Cancel drops the pick list; its delivery orders go back to draft unless another list still covers them.
+// A list is kept once one of its delivery orders has shipped.
+func (s *pickListService) Cancel(id uint, userID uint, username string) (*models.PickList, error) {
+	list, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if list.Status == PickListCancelled {
+		return nil, errors.New("pick list is already cancelled")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return cancelPickList(tx, list)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("pick_lists", "CANCEL", int64(list.ID), int64(userID), username, nil, map[string]interface{}{
+		"pick_number": list.PickNumber,
+	})
+	return s.repo.GetByID(list.ID)
+}
+
+// cancelPickList cancels the list inside the caller's transaction
+func cancelPickList(tx *gorm.DB, list *models.PickList) error {
+	txRepo := repository.NewPickListRepository(tx)
+	doIDs := make([]uint, 0, len(list.Lines))
+	for _, line := range list.Lines {
+		doIDs = append(doIDs, line.DeliveryOrderID)
+	}
+	doIDs = uniqueUints(doIDs)
+
+	shipped, err := txRepo.ShippedDeliveryOrderNumbers(doIDs)
+	if err != nil {
+		return err
+	}
+	if len(shipped) > 0 {
+		return fmt.Errorf("delivery order %s has already shipped from this pick list", shipped[0])
+	}
+
+	list.Status = PickListCancelled
+	if err := txRepo.Update(list); err != nil {
+		return err
+	}
+	return releaseToDraft(txRepo, doIDs)
+}
+
+// releaseDeliveryOrder takes an unshipped delivery order off the pick list inside the caller's transaction
+func releaseDeliveryOrder(tx *gorm.DB, list *models.PickList, deliveryOrderID uint) error {
+	txRepo := repository.NewPickListRepository(tx)
+	if err := txRepo.DeleteLinesForDeliveryOrder(list.ID, deliveryOrderID); err != nil {
+		return err
+	}
+	kept := list.Lines[:0]
+	for _, line := range list.Lines {
+		if line.DeliveryOrderID != deliveryOrderID {
+			kept = append(kept, line)
+		}
+	}
+	list.Lines = kept
+	return releaseToDraft(txRepo, []uint{deliveryOrderID})
+}
+
+// releaseToDraft returns the delivery orders no active pick list covers any more to draft
+func releaseToDraft(txRepo repository.PickListRepository, doIDs []uint) error {
+	active, err := txRepo.ActiveDeliveryOrderIDs(doIDs)
+	if err != nil {
+		return err
+	}
+	stillPicking := make(map[uint]bool, len(active))
+	for _, id := range active {
+		stillPicking[id] = true
+	}
+	release := make([]uint, 0, len(doIDs))
+	for _, id := range doIDs {
+		if !stillPicking[id] {
+			release = append(release, id)
+		}
+	}
+	return txRepo.SetDeliveryOrderStatus(release, "picking", "draft")
+}
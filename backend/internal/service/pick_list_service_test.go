@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func strPtr(s string) *string { return &s }
+
+func uintPtr(v uint) *uint { return &v }
+
+func TestAllocateFEFO(t *testing.T) {
+	noExpiry := &pickCandidate{LocationID: uintPtr(1), BatchNumber: "B0", Available: 100}
+	late := &pickCandidate{LocationID: uintPtr(2), BatchNumber: "B2", ExpiryDate: strPtr("2027-06-30T00:00:00Z"), Available: 40}
+	early := &pickCandidate{LocationID: uintPtr(3), BatchNumber: "B1", ExpiryDate: strPtr("2027-01-31"), Available: 25}
+	candidates := []*pickCandidate{noExpiry, late, early}
+
+	allocations, short := allocateFEFO(candidates, 50)
+	assert.Equal(t, 0.0, short)
+	if assert.Len(t, allocations, 2) {
+		assert.Equal(t, "B1", allocations[0].BatchNumber)
+		assert.Equal(t, 25.0, allocations[0].Quantity)
+		assert.Equal(t, "B2", allocations[1].BatchNumber)
+		assert.Equal(t, 25.0, allocations[1].Quantity)
+	}
+	assert.Equal(t, 0.0, early.Available)
+	assert.Equal(t, 15.0, late.Available)
+
+	// A second order continues with what is left
+	allocations, short = allocateFEFO(candidates, 200)
+	assert.Equal(t, 85.0, short)
+	if assert.Len(t, allocations, 2) {
+		assert.Equal(t, "B2", allocations[0].BatchNumber)
+		assert.Equal(t, 15.0, allocations[0].Quantity)
+		assert.Equal(t, "B0", allocations[1].BatchNumber)
+		assert.Equal(t, 100.0, allocations[1].Quantity)
+	}
+}
+
+func TestMatchingCandidates(t *testing.T) {
+	candidates := []*pickCandidate{
+		{LocationID: uintPtr(1), BatchNumber: "B1", LotNumber: "L1"},
+		{LocationID: uintPtr(2), BatchNumber: "B1", LotNumber: "L2"},
+		{BatchNumber: "B2"},
+	}
+	assert.Len(t, matchingCandidates(candidates, &models.DeliveryOrderItem{}), 3)
+	assert.Len(t, matchingCandidates(candidates, &models.DeliveryOrderItem{BatchNumber: "B1"}), 2)
+	assert.Len(t, matchingCandidates(candidates, &models.DeliveryOrderItem{BatchNumber: "B1", LotNumber: "L2"}), 1)
+	assert.Len(t, matchingCandidates(candidates, &models.DeliveryOrderItem{WarehouseLocationID: uintPtr(1)}), 1)
+	assert.Empty(t, matchingCandidates(candidates, &models.DeliveryOrderItem{WarehouseLocationID: uintPtr(2), BatchNumber: "B2"}))
+}
+
+func TestSortPickLines(t *testing.T) {
+	lines := []*models.PickListLine{
+		{ID: 1, DONumber: "DO-2", LocationPath: "B-01-02"},
+		{ID: 2, DONumber: "DO-1", Status: PickLineShort},
+		{ID: 3, DONumber: "DO-2", LocationPath: "A-03-01"},
+		{ID: 4, DONumber: "DO-1", LocationPath: "B-01-02"},
+	}
+	sortPickLines(lines)
+
+	order := make([]uint, len(lines))
+	for i, l := range lines {
+		order[i] = l.ID
+		assert.Equal(t, i+1, l.Sequence)
+	}
+	assert.Equal(t, []uint{3, 4, 1, 2}, order)
+}
+
+func TestRefreshPickListStatus(t *testing.T) {
+	now := time.Now()
+	list := &models.PickList{Lines: []*models.PickListLine{
+		{Status: PickLinePending},
+		{Status: PickLineShort, ShortReason: "insufficient stock at allocation"},
+	}}
+	refreshPickListStatus(list, 7, now)
+	assert.Equal(t, PickListOpen, list.Status)
+
+	list.Lines = append(list.Lines, &models.PickListLine{Status: PickLinePicked, PickedAt: &now})
+	refreshPickListStatus(list, 7, now)
+	assert.Equal(t, PickListInProgress, list.Status)
+
+	list.Lines[0].Status = PickLineShort
+	list.Lines[0].PickedAt = &now
+	refreshPickListStatus(list, 7, now)
+	assert.Equal(t, PickListCompleted, list.Status)
+	assert.Equal(t, uint(7), *list.CompletedBy)
+}
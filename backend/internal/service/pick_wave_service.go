@@ -0,0 +1,518 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Pick wave and wave order statuses
+const (
+	WavePicking          = "picking"
+	WavePicked           = "picked"
+	WaveSorted           = "sorted"
+	WavePartiallyShipped = "partially_shipped"
+	WaveShipped          = "shipped"
+	WaveCancelled        = "cancelled"
+
+	WaveOrderPending  = "pending"
+	WaveOrderPacked   = "packed"
+	WaveOrderShort    = "short"
+	WaveOrderReleased = "released"
+	WaveOrderShipped  = "shipped"
+	WaveOrderFailed   = "failed"
+)
+
+// PickWaveService batches open delivery orders into waves: one consolidated pick per wave,
+// a sort/pack step that splits the picked stock back per order, then a bulk ship
+type PickWaveService interface {
+	Create(req *dto.CreatePickWaveRequest, userID uint, username string) (*models.PickWave, error)
+	Get(id uint) (*models.PickWave, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.PickWave, int64, error)
+	ConfirmPicks(id uint, req *dto.ConfirmWavePicksRequest, userID uint, username string) (*models.PickWave, error)
+	Sort(id uint, userID uint, username string) (*models.PickWave, error)
+	Ship(id uint, req *dto.ShipWaveRequest, userID uint, username string) (*dto.WaveShipResponse, error)
+	Cancel(id uint, userID uint, username string) (*models.PickWave, error)
+}
+
+type pickWaveService struct {
+	db       *gorm.DB
+	repo     repository.PickWaveRepository
+	doSvc    DeliveryOrderService
+	auditSvc AuditLogService
+}
+
+func NewPickWaveService(db *gorm.DB, repo repository.PickWaveRepository, doSvc DeliveryOrderService, auditSvc AuditLogService) PickWaveService {
+	return &pickWaveService{db: db, repo: repo, doSvc: doSvc, auditSvc: auditSvc}
+}
+
+// parseCutoff accepts RFC3339, "YYYY-MM-DD HH:MM" (local time) or a plain date (end of that day)
+func parseCutoff(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		end := t.Add(24*time.Hour - time.Second)
+		return &end, nil
+	}
+	return nil, fmt.Errorf("invalid cutoff %q, use RFC3339 or YYYY-MM-DD HH:MM", s)
+}
+
+// consolidateWaveLines sums the pending pick lines of all orders per product/location/batch,
+// keeping the walk order of the pick list
+func consolidateWaveLines(lines []*models.PickListLine) []*models.PickWaveLine {
+	index := make(map[pickKey]*models.PickWaveLine)
+	var consolidated []*models.PickWaveLine
+	for _, line := range lines {
+		if line.Status != PickLinePending {
+			continue
+		}
+		key := newPickKey(line.FinishedProductID, line.LocationID, line.BatchNumber, line.LotNumber)
+		wl, ok := index[key]
+		if !ok {
+			wl = &models.PickWaveLine{
+				FinishedProductID: line.FinishedProductID,
+				LocationID:        line.LocationID,
+				LocationPath:      line.LocationPath,
+				BatchNumber:       line.BatchNumber,
+				LotNumber:         line.LotNumber,
+				ExpiryDate:        line.ExpiryDate,
+				Status:            PickLinePending,
+			}
+			index[key] = wl
+			consolidated = append(consolidated, wl)
+		}
+		wl.Quantity = roundQty(wl.Quantity + line.Quantity)
+	}
+	for i, wl := range consolidated {
+		wl.Sequence = i + 1
+	}
+	return consolidated
+}
+
+// distributeWavePick shares a picked total over the order demands in sequence, filling earlier orders first
+func distributeWavePick(picked float64, demands []float64) []float64 {
+	shares := make([]float64, len(demands))
+	remaining := roundQty(picked)
+	for i, demand := range demands {
+		if remaining <= qtyEpsilon {
+			break
+		}
+		shares[i] = roundQty(math.Min(demand, remaining))
+		remaining = roundQty(remaining - shares[i])
+	}
+	return shares
+}
+
+// generateWaveNumber creates a number like WV-2026-000001
+func generateWaveNumber(repo repository.PickWaveRepository) (string, error) {
+	prefix := fmt.Sprintf("WV-%s-", time.Now().Format("2006"))
+	count, err := repo.CountByWaveNumber(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%06d", prefix, count+1), nil
+}
+
+// Create collects the matching draft delivery orders (earliest due first) and generates the wave's pick list
+func (s *pickWaveService) Create(req *dto.CreatePickWaveRequest, userID uint, username string) (*models.PickWave, error) {
+	cutoff, err := parseCutoff(req.Cutoff)
+	if err != nil {
+		return nil, err
+	}
+	if req.DeliveryDate != "" {
+		if _, err := time.Parse("2006-01-02", req.DeliveryDate); err != nil {
+			return nil, errors.New("invalid delivery date format, use YYYY-MM-DD")
+		}
+	}
+
+	var wave *models.PickWave
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewPickWaveRepository(tx)
+		orders, err := txRepo.FindOpenDeliveryOrders(repository.WaveCriteria{
+			WarehouseID:    req.WarehouseID,
+			CarrierID:      req.CarrierID,
+			SalesChannelID: req.SalesChannelID,
+			CutoffAt:       cutoff,
+			DeliveryDate:   req.DeliveryDate,
+			IDs:            uniqueUints(req.DeliveryOrderIDs),
+			MaxOrders:      req.MaxOrders,
+		})
+		if err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			return errors.New("no open delivery orders match the wave criteria")
+		}
+
+		number, err := generateWaveNumber(txRepo)
+		if err != nil {
+			return err
+		}
+		ids := make([]uint, len(orders))
+		for i, do := range orders {
+			ids[i] = do.ID
+		}
+		list, err := generatePickList(tx, ids, "Wave "+number, userID)
+		if err != nil {
+			return err
+		}
+
+		wave = &models.PickWave{
+			WaveNumber:     number,
+			WarehouseID:    req.WarehouseID,
+			PickListID:     &list.ID,
+			CarrierID:      req.CarrierID,
+			SalesChannelID: req.SalesChannelID,
+			CutoffAt:       cutoff,
+			Status:         WavePicking,
+			Notes:          req.Notes,
+			CreatedBy:      &userID,
+		}
+		if req.DeliveryDate != "" {
+			date := req.DeliveryDate
+			wave.DeliveryDate = &date
+		}
+		for i, do := range orders {
+			var ordered float64
+			for _, item := range do.Items {
+				ordered += item.Quantity
+			}
+			wave.Orders = append(wave.Orders, &models.PickWaveOrder{
+				DeliveryOrderID: do.ID,
+				DONumber:        do.DONumber,
+				Sequence:        i + 1,
+				OrderedQuantity: roundQty(ordered),
+				Status:          WaveOrderPending,
+			})
+		}
+		wave.Lines = consolidateWaveLines(list.Lines)
+		if len(wave.Lines) == 0 {
+			// Nothing could be allocated; the sort step releases the orders
+			now := time.Now()
+			wave.Status = WavePicked
+			wave.PickedAt = &now
+		}
+		return txRepo.Create(wave)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("pick_waves", "CREATE", int64(wave.ID), int64(userID), username, nil, map[string]interface{}{
+		"wave_number":     wave.WaveNumber,
+		"delivery_orders": len(wave.Orders),
+		"lines":           len(wave.Lines),
+	})
+	return s.repo.GetByID(wave.ID)
+}
+
+func (s *pickWaveService) Get(id uint) (*models.PickWave, error) {
+	wave, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("pick wave not found")
+	}
+	return wave, nil
+}
+
+func (s *pickWaveService) List(filters map[string]interface{}, offset, limit int) ([]*models.PickWave, int64, error) {
+	return s.repo.List(filters, offset, limit)
+}
+
+// ConfirmPicks records the picked totals of consolidated lines; the wave is picked once no line is pending
+func (s *pickWaveService) ConfirmPicks(id uint, req *dto.ConfirmWavePicksRequest, userID uint, username string) (*models.PickWave, error) {
+	wave, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if wave.Status != WavePicking {
+		return nil, fmt.Errorf("pick wave is %s", wave.Status)
+	}
+	byID := make(map[uint]*models.PickWaveLine, len(wave.Lines))
+	for _, line := range wave.Lines {
+		byID[line.ID] = line
+	}
+
+	now := time.Now()
+	shorts := 0
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewPickWaveRepository(tx)
+		for _, r := range req.Lines {
+			line, ok := byID[r.LineID]
+			if !ok {
+				return fmt.Errorf("line %d is not on pick wave %s", r.LineID, wave.WaveNumber)
+			}
+			if line.Status != PickLinePending {
+				return fmt.Errorf("line %d is already %s", line.ID, line.Status)
+			}
+			picked := line.Quantity
+			if r.PickedQuantity != nil {
+				picked = roundQty(*r.PickedQuantity)
+			}
+			if picked > line.Quantity+qtyEpsilon {
+				return fmt.Errorf("line %d: picked %s exceeds the %s to pick", line.ID, formatQty(picked), formatQty(line.Quantity))
+			}
+			line.PickedQuantity = picked
+			line.Status = PickLinePicked
+			if picked < line.Quantity-qtyEpsilon {
+				line.Status = PickLineShort
+				line.ShortReason = r.ShortReason
+				if line.ShortReason == "" {
+					line.ShortReason = "short pick"
+				}
+				shorts++
+			}
+			line.PickedBy = &userID
+			line.PickedAt = &now
+			if err := txRepo.UpdateLine(line); err != nil {
+				return err
+			}
+		}
+
+		for _, line := range wave.Lines {
+			if line.Status == PickLinePending {
+				return nil
+			}
+		}
+		wave.Status = WavePicked
+		wave.PickedAt = &now
+		return txRepo.Update(wave)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("pick_waves", "PICK", int64(wave.ID), int64(userID), username, nil, map[string]interface{}{
+		"wave_number": wave.WaveNumber,
+		"lines":       len(req.Lines),
+		"short_picks": shorts,
+		"status":      wave.Status,
+	})
+	return s.repo.GetByID(wave.ID)
+}
+
+// Sort splits every consolidated pick back over the orders of the wave in sequence and confirms their
+// pick lines. Orders that got everything are packed, partly filled ones are short and orders nothing
+// was picked for are released back to draft for a later wave.
+func (s *pickWaveService) Sort(id uint, userID uint, username string) (*models.PickWave, error) {
+	wave, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if wave.Status == WavePicking {
+		return nil, errors.New("pick wave still has lines to pick")
+	}
+	if wave.Status != WavePicked {
+		return nil, fmt.Errorf("pick wave is %s", wave.Status)
+	}
+	if wave.PickListID == nil {
+		return nil, errors.New("pick wave has no pick list")
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewPickWaveRepository(tx)
+		list, err := repository.NewPickListRepository(tx).GetByID(*wave.PickListID)
+		if err != nil {
+			return errors.New("pick list of the wave not found")
+		}
+
+		sequence := make(map[uint]int, len(wave.Orders))
+		for _, o := range wave.Orders {
+			sequence[o.DeliveryOrderID] = o.Sequence
+		}
+		groups := make(map[pickKey][]*models.PickListLine)
+		for _, line := range list.Lines {
+			if line.Status != PickLinePending {
+				continue
+			}
+			key := newPickKey(line.FinishedProductID, line.LocationID, line.BatchNumber, line.LotNumber)
+			groups[key] = append(groups[key], line)
+		}
+
+		var reqs []dto.ConfirmPickLineRequest
+		for _, wl := range wave.Lines {
+			group := groups[newPickKey(wl.FinishedProductID, wl.LocationID, wl.BatchNumber, wl.LotNumber)]
+			sort.SliceStable(group, func(i, j int) bool {
+				return sequence[group[i].DeliveryOrderID] < sequence[group[j].DeliveryOrderID]
+			})
+			demands := make([]float64, len(group))
+			for i, line := range group {
+				demands[i] = line.Quantity
+			}
+			shares := distributeWavePick(wl.PickedQuantity, demands)
+			for i, line := range group {
+				share := shares[i]
+				r := dto.ConfirmPickLineRequest{LineID: line.ID, PickedQuantity: &share}
+				if share < line.Quantity-qtyEpsilon {
+					r.ShortReason = wl.ShortReason
+				}
+				reqs = append(reqs, r)
+			}
+		}
+		if _, _, err := applyPickConfirmations(tx, list, reqs, userID, now); err != nil {
+			return err
+		}
+
+		packed := make(map[uint]float64, len(wave.Orders))
+		for _, line := range list.Lines {
+			packed[line.DeliveryOrderID] += line.PickedQuantity
+		}
+		shippable := 0
+		for _, o := range wave.Orders {
+			o.PackedQuantity = roundQty(packed[o.DeliveryOrderID])
+			switch {
+			case o.PackedQuantity >= o.OrderedQuantity-qtyEpsilon:
+				o.Status = WaveOrderPacked
+				shippable++
+			case o.PackedQuantity > qtyEpsilon:
+				o.Status = WaveOrderShort
+				shippable++
+			default:
+				o.Status = WaveOrderReleased
+				o.ErrorMessage = "nothing picked, returned to draft"
+				if err := releaseDeliveryOrder(tx, list, o.DeliveryOrderID); err != nil {
+					return err
+				}
+			}
+			if err := txRepo.UpdateOrder(o); err != nil {
+				return err
+			}
+		}
+
+		wave.Status = WaveSorted
+		wave.SortedAt = &now
+		if shippable == 0 {
+			if err := cancelPickList(tx, list); err != nil {
+				return err
+			}
+			wave.Status = WaveCancelled
+		}
+		return txRepo.Update(wave)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("pick_waves", "SORT", int64(wave.ID), int64(userID), username, nil, map[string]interface{}{
+		"wave_number": wave.WaveNumber,
+		"status":      wave.Status,
+	})
+	return s.repo.GetByID(wave.ID)
+}
+
+// Ship posts every packed or short order of the wave on its own, so one failing order does not block
+// the others; failed orders can be shipped again with another call
+func (s *pickWaveService) Ship(id uint, req *dto.ShipWaveRequest, userID uint, username string) (*dto.WaveShipResponse, error) {
+	wave, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if wave.Status != WaveSorted && wave.Status != WavePartiallyShipped {
+		return nil, fmt.Errorf("pick wave is %s", wave.Status)
+	}
+
+	resp := &dto.WaveShipResponse{WaveID: wave.ID, WaveNumber: wave.WaveNumber, Results: []dto.WaveShipResult{}}
+	for _, o := range wave.Orders {
+		if o.Status != WaveOrderPacked && o.Status != WaveOrderShort && o.Status != WaveOrderFailed {
+			continue
+		}
+		result := dto.WaveShipResult{DeliveryOrderID: o.DeliveryOrderID, DONumber: o.DONumber}
+		_, err := s.doSvc.ShipDeliveryOrder(o.DeliveryOrderID, &dto.ShipDeliveryOrderRequest{
+			TrackingNumber: req.TrackingNumbers[o.DeliveryOrderID],
+			Notes:          req.Notes,
+		}, userID)
+		if err != nil {
+			o.Status = WaveOrderFailed
+			o.ErrorMessage = err.Error()
+			result.Error = err.Error()
+			resp.Failed++
+		} else {
+			now := time.Now()
+			o.Status = WaveOrderShipped
+			o.ErrorMessage = ""
+			o.ShippedAt = &now
+			result.Success = true
+			resp.Shipped++
+		}
+		if err := s.repo.UpdateOrder(o); err != nil {
+			return nil, err
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	wave.Status = WaveShipped
+	for _, o := range wave.Orders {
+		if o.Status == WaveOrderFailed {
+			wave.Status = WavePartiallyShipped
+			break
+		}
+	}
+	if wave.Status == WaveShipped {
+		now := time.Now()
+		wave.ShippedAt = &now
+	}
+	if err := s.repo.Update(wave); err != nil {
+		return nil, err
+	}
+	resp.Status = wave.Status
+
+	_ = s.auditSvc.Log("pick_waves", "SHIP", int64(wave.ID), int64(userID), username, nil, map[string]interface{}{
+		"wave_number": wave.WaveNumber,
+		"shipped":     resp.Shipped,
+		"failed":      resp.Failed,
+		"status":      wave.Status,
+	})
+	return resp, nil
+}
+
+// Cancel drops the wave and its pick list before anything was shipped; the orders go back to draft
+func (s *pickWaveService) Cancel(id uint, userID uint, username string) (*models.PickWave, error) {
+	wave, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	switch wave.Status {
+	case WaveCancelled, WaveShipped, WavePartiallyShipped:
+		return nil, fmt.Errorf("pick wave is %s", wave.Status)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if wave.PickListID != nil {
+			list, err := repository.NewPickListRepository(tx).GetByID(*wave.PickListID)
+			if err != nil {
+				return errors.New("pick list of the wave not found")
+			}
+			if list.Status != PickListCancelled {
+				if err := cancelPickList(tx, list); err != nil {
+					return err
+				}
+			}
+		}
+		wave.Status = WaveCancelled
+		return repository.NewPickWaveRepository(tx).Update(wave)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("pick_waves", "CANCEL", int64(wave.ID), int64(userID), username, nil, map[string]interface{}{
+		"wave_number": wave.WaveNumber,
+	})
+	return s.repo.GetByID(wave.ID)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsolidateWaveLines(t *testing.T) {
+	lines := []*models.PickListLine{
+		{DeliveryOrderID: 1, FinishedProductID: 10, LocationID: uintPtr(5), LocationPath: "A-01", BatchNumber: "B1", Quantity: 2, Status: PickLinePending},
+		{DeliveryOrderID: 2, FinishedProductID: 10, LocationID: uintPtr(5), LocationPath: "A-01", BatchNumber: "B1", Quantity: 3, Status: PickLinePending},
+		{DeliveryOrderID: 2, FinishedProductID: 10, LocationID: uintPtr(6), LocationPath: "A-02", BatchNumber: "B2", Quantity: 1, Status: PickLinePending},
+		{DeliveryOrderID: 3, FinishedProductID: 11, LocationID: uintPtr(5), LocationPath: "A-01", Quantity: 4, Status: PickLinePending},
+		{DeliveryOrderID: 3, FinishedProductID: 12, Quantity: 7, Status: PickLineShort},
+	}
+	consolidated := consolidateWaveLines(lines)
+
+	if assert.Len(t, consolidated, 3) {
+		assert.Equal(t, 5.0, consolidated[0].Quantity)
+		assert.Equal(t, "B1", consolidated[0].BatchNumber)
+		assert.Equal(t, 1, consolidated[0].Sequence)
+		assert.Equal(t, "A-02", consolidated[1].LocationPath)
+		assert.Equal(t, uint(11), consolidated[2].FinishedProductID)
+		assert.Equal(t, 3, consolidated[2].Sequence)
+	}
+}
+
+func TestDistributeWavePick(t *testing.T) {
+	assert.Equal(t, []float64{2, 3, 1}, distributeWavePick(6, []float64{2, 3, 1}))
+	assert.Equal(t, []float64{2, 2, 0}, distributeWavePick(4, []float64{2, 3, 1}))
+	assert.Equal(t, []float64{0, 0}, distributeWavePick(0, []float64{2, 3}))
+	assert.Equal(t, []float64{1.5, 0.25}, distributeWavePick(1.75, []float64{1.5, 2}))
+}
+
+func TestParseCutoff(t *testing.T) {
+	none, err := parseCutoff("")
+	assert.NoError(t, err)
+	assert.Nil(t, none)
+
+	rfc, err := parseCutoff("2026-10-19T14:00:00+07:00")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, rfc.UTC().Hour())
+
+	local, err := parseCutoff("2026-10-19 14:00")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 19, 14, 0, 0, 0, time.Local), *local)
+
+	day, err := parseCutoff("2026-10-19")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 19, 23, 59, 59, 0, time.Local), *day)
+
+	_, err = parseCutoff("19/10/2026")
+	assert.Error(t, err)
+}
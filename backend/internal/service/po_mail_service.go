@@ -0,0 +1,368 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/config"
+	"github.com/VyVy-ERP/warehouse-backend/internal/document"
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/mail"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Supplier e-mail types and statuses
+const (
+	POEmailPurchaseOrder    = "purchase_order"
+	POEmailDeliveryReminder = "delivery_reminder"
+
+	POEmailSent   = "sent"
+	POEmailFailed = "failed"
+)
+
+// ErrMailNotConfigured is returned when no SMTP server is set up
+var ErrMailNotConfigured = errors.New("e-mail is not configured (set SMTP_HOST and SMTP_FROM)")
+
+// POMailService e-mails purchase orders and delivery reminders to suppliers and logs every message
+type POMailService interface {
+	SendPurchaseOrder(poID uint, req *dto.SendPOEmailRequest, userID uint, username string) (*models.POEmailLog, error)
+	SendDeliveryReminder(poID uint, req *dto.SendPOEmailRequest, userID uint, username string) (*models.POEmailLog, error)
+	RunDeliveryReminders(req *dto.RunDeliveryRemindersRequest, userID uint, username string) (*dto.DeliveryReminderRunResult, error)
+	ListEmailLogs(filters map[string]interface{}, offset, limit int) ([]*models.POEmailLog, int64, error)
+}
+
+type poMailService struct {
+	poRepo       repository.PurchaseOrderRepository
+	logRepo      repository.POEmailLogRepository
+	docSvc       DocumentService
+	auditSvc     AuditLogService
+	sender       mail.Sender // nil when SMTP is not configured
+	company      config.CompanyConfig
+	lang         string
+	reminderDays int
+}
+
+// NewPOMailService creates a new POMailService; sender may be nil when mail is disabled
+func NewPOMailService(
+	poRepo repository.PurchaseOrderRepository,
+	logRepo repository.POEmailLogRepository,
+	docSvc DocumentService,
+	auditSvc AuditLogService,
+	sender mail.Sender,
+	company config.CompanyConfig,
+	defaultLang string,
+	reminderDays int,
+) POMailService {
+	return &poMailService{
+		poRepo:       poRepo,
+		logRepo:      logRepo,
+		docSvc:       docSvc,
+		auditSvc:     auditSvc,
+		sender:       sender,
+		company:      company,
+		lang:         document.NormalizeLang(defaultLang),
+		reminderDays: reminderDays,
+	}
+}
+
+func (s *poMailService) getPO(id uint) (*models.PurchaseOrder, error) {
+	po, err := s.poRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("purchase order not found")
+		}
+		return nil, err
+	}
+	return po, nil
+}
+
+// mailData fills the template fields shared by all PO messages
+func (s *poMailService) mailData(po *models.PurchaseOrder, lang, message, username string) mail.PurchaseOrderData {
+	data := mail.PurchaseOrderData{
+		CompanyName: s.company.Name,
+		PONumber:    po.PONumber,
+		OrderDate:   document.FormatDate(lang, po.OrderDate),
+		Currency:    po.Currency,
+		Message:     strings.TrimSpace(message),
+		SenderName:  username,
+	}
+	if data.Currency == "" {
+		data.Currency = models.BaseCurrency
+	}
+	if po.TotalAmount > 0 {
+		data.TotalAmount = docMoney(lang, po.TotalAmount, data.Currency)
+	}
+	if po.ExpectedDeliveryDate != nil {
+		data.ExpectedDeliveryDate = document.FormatDate(lang, *po.ExpectedDeliveryDate)
+	}
+	if po.Supplier != nil {
+		data.SupplierName = po.Supplier.Name
+		data.ContactPerson = strValue(po.Supplier.ContactPerson)
+	}
+	return data
+}
+
+// recipients uses the requested addresses, else the supplier's e-mail
+func recipients(po *models.PurchaseOrder, req *dto.SendPOEmailRequest) ([]string, error) {
+	if len(req.To) > 0 {
+		return req.To, nil
+	}
+	if po.Supplier != nil {
+		if email := strings.TrimSpace(strValue(po.Supplier.Email)); email != "" {
+			return []string{email}, nil
+		}
+	}
+	return nil, errors.New("supplier has no e-mail address; specify recipients")
+}
+
+func (s *poMailService) SendPurchaseOrder(poID uint, req *dto.SendPOEmailRequest, userID uint, username string) (*models.POEmailLog, error) {
+	if s.sender == nil {
+		return nil, ErrMailNotConfigured
+	}
+	po, err := s.getPO(poID)
+	if err != nil {
+		return nil, err
+	}
+	if po.Status == "draft" || po.Status == "cancelled" {
+		return nil, errors.New("only approved purchase orders can be sent to the supplier")
+	}
+	to, err := recipients(po, req)
+	if err != nil {
+		return nil, err
+	}
+	lang := s.lang
+	if req.Lang != "" {
+		lang = document.NormalizeLang(req.Lang)
+	}
+
+	pdf, err := s.docSvc.PurchaseOrderPDF(po.ID, lang)
+	if err != nil {
+		return nil, err
+	}
+	subject, body, err := mail.Render(mail.TemplatePurchaseOrder, lang, s.mailData(po, lang, req.Message, username))
+	if err != nil {
+		return nil, err
+	}
+	msg := &mail.Message{
+		To:          to,
+		Cc:          req.Cc,
+		Subject:     subject,
+		Text:        body,
+		Attachments: []mail.Attachment{{FileName: pdf.FileName, ContentType: "application/pdf", Data: pdf.Content}},
+	}
+	return s.send(po, POEmailPurchaseOrder, msg, nil, userID, username)
+}
+
+func (s *poMailService) SendDeliveryReminder(poID uint, req *dto.SendPOEmailRequest, userID uint, username string) (*models.POEmailLog, error) {
+	if s.sender == nil {
+		return nil, ErrMailNotConfigured
+	}
+	po, err := s.getPO(poID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRemindable(po); err != nil {
+		return nil, err
+	}
+	lang := s.lang
+	if req.Lang != "" {
+		lang = document.NormalizeLang(req.Lang)
+	}
+	return s.sendReminder(po, req, lang, time.Now(), userID, username)
+}
+
+// checkRemindable allows reminders for approved POs with goods still to come and a delivery date
+func checkRemindable(po *models.PurchaseOrder) error {
+	if po.Status != "approved" {
+		return errors.New("delivery reminders are only sent for approved purchase orders")
+	}
+	if po.ReceiptStatus == ReceiptStatusReceived || po.ReceiptStatus == ReceiptStatusClosedShort {
+		return errors.New("purchase order is already fully received")
+	}
+	if po.ExpectedDeliveryDate == nil || *po.ExpectedDeliveryDate == "" {
+		return errors.New("purchase order has no expected delivery date")
+	}
+	return nil
+}
+
+func (s *poMailService) sendReminder(po *models.PurchaseOrder, req *dto.SendPOEmailRequest, lang string, now time.Time, userID uint, username string) (*models.POEmailLog, error) {
+	to, err := recipients(po, req)
+	if err != nil {
+		return nil, err
+	}
+	expected := firstN(*po.ExpectedDeliveryDate, 10)
+	data := s.mailData(po, lang, req.Message, username)
+	data.DaysUntilDelivery = daysUntil(now, expected)
+	data.OpenLines = openLines(po, lang)
+
+	subject, body, err := mail.Render(mail.TemplateDeliveryReminder, lang, data)
+	if err != nil {
+		return nil, err
+	}
+	msg := &mail.Message{To: to, Cc: req.Cc, Subject: subject, Text: body}
+	return s.send(po, POEmailDeliveryReminder, msg, &expected, userID, username)
+}
+
+// daysUntil counts calendar days from now to a YYYY-MM-DD date (negative when overdue)
+func daysUntil(now time.Time, date string) int {
+	d, err := time.ParseInLocation("2006-01-02", date, now.Location())
+	if err != nil {
+		return 0
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return int(d.Sub(today).Hours() / 24)
+}
+
+// openLines lists PO lines with quantity still outstanding (closed-short lines excluded)
+func openLines(po *models.PurchaseOrder, lang string) []mail.OpenLine {
+	var lines []mail.OpenLine
+	for _, item := range po.Items {
+		outstanding := item.Quantity - item.ReceivedQuantity
+		if item.ClosedShort || outstanding <= qtyEpsilon {
+			continue
+		}
+		line := mail.OpenLine{
+			Ordered:     docQty(lang, item.Quantity),
+			Received:    docQty(lang, item.ReceivedQuantity),
+			Outstanding: docQty(lang, outstanding),
+		}
+		if item.Material != nil {
+			line.Code, line.Name, line.Unit = item.Material.Code, item.Material.TradingName, item.Material.Unit
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// send delivers msg and logs the outcome; a failed delivery is logged and returned as an error
+func (s *poMailService) send(po *models.PurchaseOrder, messageType string, msg *mail.Message, reminderFor *string, userID uint, username string) (*models.POEmailLog, error) {
+	entry := &models.POEmailLog{
+		PurchaseOrderID: po.ID,
+		MessageType:     messageType,
+		Recipients:      strings.Join(msg.To, ", "),
+		Cc:              strings.Join(msg.Cc, ", "),
+		Subject:         msg.Subject,
+		Body:            msg.Text,
+		ReminderFor:     reminderFor,
+	}
+	if po.SupplierID > 0 {
+		supplierID := po.SupplierID
+		entry.SupplierID = &supplierID
+	}
+	if len(msg.Attachments) > 0 {
+		entry.AttachmentName = msg.Attachments[0].FileName
+	}
+	if userID > 0 {
+		entry.SentBy = &userID
+	}
+
+	sendErr := s.sender.Send(msg)
+	if sendErr != nil {
+		entry.Status = POEmailFailed
+		entry.ErrorMessage = sendErr.Error()
+	} else {
+		now := time.Now()
+		entry.Status = POEmailSent
+		entry.SentAt = &now
+	}
+	if err := s.logRepo.Create(entry); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("purchase_orders", "EMAIL", int64(po.ID), int64(userID), username, nil,
+		map[string]interface{}{"type": messageType, "to": entry.Recipients, "status": entry.Status, "error": entry.ErrorMessage})
+
+	if sendErr != nil {
+		return entry, sendErr
+	}
+	return entry, nil
+}
+
+// RunDeliveryReminders e-mails suppliers of approved POs due within the window. Each PO gets one
+// reminder per expected delivery date; failed sends are retried on the next run.
+func (s *poMailService) RunDeliveryReminders(req *dto.RunDeliveryRemindersRequest, userID uint, username string) (*dto.DeliveryReminderRunResult, error) {
+	if s.sender == nil {
+		return nil, ErrMailNotConfigured
+	}
+	days := s.reminderDays
+	if req != nil && req.DaysAhead != nil {
+		days = *req.DaysAhead
+	}
+	now := time.Now()
+	result := &dto.DeliveryReminderRunResult{
+		FromDate: now.Format("2006-01-02"),
+		ToDate:   now.AddDate(0, 0, days).Format("2006-01-02"),
+		Results:  []dto.DeliveryReminderOutcome{},
+	}
+
+	ids, err := s.logRepo.ListReminderCandidates(result.FromDate, result.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		po, err := s.getPO(id)
+		if err != nil {
+			return nil, err
+		}
+		outcome := dto.DeliveryReminderOutcome{PurchaseOrderID: po.ID, PONumber: po.PONumber}
+		if po.ExpectedDeliveryDate != nil {
+			outcome.ExpectedDate = firstN(*po.ExpectedDeliveryDate, 10)
+		}
+
+		sent, err := s.logRepo.ReminderSent(po.ID, outcome.ExpectedDate)
+		switch {
+		case err != nil:
+			return nil, err
+		case sent:
+			outcome.Status, outcome.Reason = "skipped", "reminder already sent for this date"
+		case len(openLines(po, s.lang)) == 0:
+			outcome.Status, outcome.Reason = "skipped", "no outstanding quantity"
+		default:
+			if _, err := s.sendReminder(po, &dto.SendPOEmailRequest{}, s.lang, now, userID, username); err != nil {
+				outcome.Status, outcome.Reason = POEmailFailed, err.Error()
+			} else {
+				outcome.Status = POEmailSent
+			}
+		}
+
+		switch outcome.Status {
+		case POEmailSent:
+			result.Sent++
+		case POEmailFailed:
+			result.Failed++
+		default:
+			result.Skipped++
+		}
+		result.Results = append(result.Results, outcome)
+	}
+	return result, nil
+}
+
+func (s *poMailService) ListEmailLogs(filters map[string]interface{}, offset, limit int) ([]*models.POEmailLog, int64, error) {
+	return s.logRepo.List(filters, offset, limit)
+}
+
+// StartPOReminderScheduler sends delivery reminders in the background every interval
+func StartPOReminderScheduler(svc POMailService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			result, err := svc.RunDeliveryReminders(nil, 0, "po-reminder")
+			if err != nil {
+				log.Printf("po reminders: %v", err)
+				continue
+			}
+			log.Printf("po reminders: %d sent, %d failed, %d skipped", result.Sent, result.Failed, result.Skipped)
+			for _, r := range result.Results {
+				if r.Status == POEmailFailed {
+					log.Printf("po reminders: %s: %s", r.PONumber, r.Reason)
+				}
+			}
+		}
+	}()
+}
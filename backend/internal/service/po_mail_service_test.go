@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaysUntil(t *testing.T) {
+	now := time.Date(2026, 3, 8, 17, 30, 0, 0, time.UTC)
+	assert.Equal(t, 2, daysUntil(now, "2026-03-10"))
+	assert.Equal(t, 0, daysUntil(now, "2026-03-08"))
+	assert.Equal(t, -1, daysUntil(now, "2026-03-07"))
+}
+
+func TestOpenLinesSkipsDeliveredAndClosedShort(t *testing.T) {
+	po := &models.PurchaseOrder{Items: []*models.PurchaseOrderItem{
+		{Quantity: 100, ReceivedQuantity: 40, Material: &models.Material{Code: "GLY", TradingName: "Glycerin", Unit: "KG"}},
+		{Quantity: 50, ReceivedQuantity: 50},
+		{Quantity: 20, ReceivedQuantity: 5, ClosedShort: true},
+	}}
+	lines := openLines(po, "en")
+	if assert.Len(t, lines, 1) {
+		assert.Equal(t, "GLY", lines[0].Code)
+		assert.Equal(t, "60", lines[0].Outstanding)
+		assert.Equal(t, "40", lines[0].Received)
+	}
+}
+
+func TestCheckRemindable(t *testing.T) {
+	date := "2026-03-10"
+	po := &models.PurchaseOrder{Status: "approved", ReceiptStatus: ReceiptStatusPartial, ExpectedDeliveryDate: &date}
+	assert.NoError(t, checkRemindable(po))
+
+	po.ReceiptStatus = ReceiptStatusReceived
+	assert.Error(t, checkRemindable(po))
+
+	po.ReceiptStatus = ReceiptStatusPending
+	po.ExpectedDeliveryDate = nil
+	assert.Error(t, checkRemindable(po))
+
+	po.ExpectedDeliveryDate = &date
+	po.Status = "draft"
+	assert.Error(t, checkRemindable(po))
+}
+
+func TestRecipientsDefaultToSupplierEmail(t *testing.T) {
+	email := "sales@supplier.vn"
+	po := &models.PurchaseOrder{Supplier: &models.Supplier{Email: &email}}
+
+	to, err := recipients(po, &dto.SendPOEmailRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{email}, to)
+
+	to, err = recipients(po, &dto.SendPOEmailRequest{To: []string{"buyer@supplier.vn"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"buyer@supplier.vn"}, to)
+
+	po.Supplier.Email = nil
+	_, err = recipients(po, &dto.SendPOEmailRequest{})
+	assert.Error(t, err)
+}
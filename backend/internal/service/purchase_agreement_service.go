@@ -0,0 +1,408 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PurchaseAgreementService handles blanket purchase agreements (hợp đồng nguyên tắc) and their call-offs
+type PurchaseAgreementService interface {
+	Create(req *dto.CreatePurchaseAgreementRequest, userID uint, username string) (*models.PurchaseAgreement, error)
+	GetByID(id uint) (*models.PurchaseAgreement, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.PurchaseAgreement, int64, error)
+	Update(id uint, req *dto.UpdatePurchaseAgreementRequest, userID uint, username string) (*models.PurchaseAgreement, error)
+	Activate(id uint, userID uint, username string) (*models.PurchaseAgreement, error)
+	Close(id uint, userID uint, username string) (*models.PurchaseAgreement, error)
+	Cancel(id uint, userID uint, username string) error
+
+	// ResolveForCallOff returns an active agreement valid for the supplier on orderDate
+	ResolveForCallOff(id uint, supplierID uint, orderDate string) (*models.PurchaseAgreement, error)
+	// CheckCallOff returns the over-commitment warnings the agreement would have with the call-off
+	// lines added to the other call-offs; excludePOID leaves out the PO being edited
+	CheckCallOff(agreement *models.PurchaseAgreement, excludePOID uint, lines []repository.AgreementConsumptionRow) ([]string, error)
+}
+
+type purchaseAgreementService struct {
+	repo         repository.PurchaseAgreementRepository
+	supplierRepo repository.SupplierRepository
+	materialRepo repository.MaterialRepository
+	auditSvc     AuditLogService
+}
+
+func NewPurchaseAgreementService(
+	repo repository.PurchaseAgreementRepository,
+	supplierRepo repository.SupplierRepository,
+	materialRepo repository.MaterialRepository,
+	auditSvc AuditLogService,
+) PurchaseAgreementService {
+	return &purchaseAgreementService{
+		repo:         repo,
+		supplierRepo: supplierRepo,
+		materialRepo: materialRepo,
+		auditSvc:     auditSvc,
+	}
+}
+
+// generateAgreementNumber creates a number like BPA-2026-000001
+func (s *purchaseAgreementService) generateAgreementNumber() (string, error) {
+	prefix := fmt.Sprintf("BPA-%s-", time.Now().Format("2006"))
+	count, err := s.repo.CountByAgreementNumber(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%06d", prefix, count+1), nil
+}
+
+func (s *purchaseAgreementService) Create(req *dto.CreatePurchaseAgreementRequest, userID uint, username string) (*models.PurchaseAgreement, error) {
+	supplier, err := s.supplierRepo.GetByID(req.SupplierID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("supplier not found")
+		}
+		return nil, err
+	}
+	// Agreed prices are in the supplier's currency unless another one is given
+	currency := NormalizeCurrency(req.Currency)
+	if req.Currency == "" {
+		currency = NormalizeCurrency(supplier.Currency)
+	}
+	if err := validateAgreementPeriod(req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+	items, err := s.buildItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	number, err := s.generateAgreementNumber()
+	if err != nil {
+		return nil, err
+	}
+	agreement := &models.PurchaseAgreement{
+		AgreementNumber: number,
+		SupplierID:      req.SupplierID,
+		StartDate:       req.StartDate,
+		EndDate:         req.EndDate,
+		Currency:        currency,
+		Status:          "draft",
+		CommittedValue:  committedValue(items),
+		PaymentTerms:    req.PaymentTerms,
+		Notes:           req.Notes,
+		CreatedBy:       &userID,
+		UpdatedBy:       &userID,
+		Items:           items,
+	}
+	if err := s.repo.Create(agreement); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("purchase_agreements", "CREATE", int64(agreement.ID), int64(userID), username, nil, agreement)
+	return s.GetByID(agreement.ID)
+}
+
+// buildItems validates materials and rejects duplicate lines
+func (s *purchaseAgreementService) buildItems(reqs []dto.PurchaseAgreementItemRequest) ([]*models.PurchaseAgreementItem, error) {
+	seen := make(map[uint]bool, len(reqs))
+	items := make([]*models.PurchaseAgreementItem, 0, len(reqs))
+	for _, r := range reqs {
+		if seen[r.MaterialID] {
+			return nil, fmt.Errorf("material %d appears more than once", r.MaterialID)
+		}
+		seen[r.MaterialID] = true
+		if _, err := s.materialRepo.GetByID(int64(r.MaterialID)); err != nil {
+			return nil, fmt.Errorf("material %d not found", r.MaterialID)
+		}
+		items = append(items, &models.PurchaseAgreementItem{
+			MaterialID:        r.MaterialID,
+			AgreedPrice:       r.AgreedPrice,
+			TaxRate:           r.TaxRate,
+			DiscountRate:      r.DiscountRate,
+			CommittedQuantity: r.CommittedQuantity,
+			Notes:             r.Notes,
+		})
+	}
+	return items, nil
+}
+
+func (s *purchaseAgreementService) GetByID(id uint) (*models.PurchaseAgreement, error) {
+	agreement, err := s.repo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("purchase agreement not found")
+		}
+		return nil, err
+	}
+	rows, err := s.repo.GetConsumption(id, 0)
+	if err != nil {
+		return nil, err
+	}
+	applyAgreementConsumption(agreement, rows)
+	return agreement, nil
+}
+
+func (s *purchaseAgreementService) List(filters map[string]interface{}, offset, limit int) ([]*models.PurchaseAgreement, int64, error) {
+	agreements, total, err := s.repo.List(filters, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, a := range agreements {
+		rows, err := s.repo.GetConsumption(a.ID, 0)
+		if err != nil {
+			return nil, 0, err
+		}
+		applyAgreementConsumption(a, rows)
+	}
+	return agreements, total, nil
+}
+
+// Update edits a draft agreement; active agreements are frozen so call-off prices stay stable
+func (s *purchaseAgreementService) Update(id uint, req *dto.UpdatePurchaseAgreementRequest, userID uint, username string) (*models.PurchaseAgreement, error) {
+	agreement, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if agreement.Status != "draft" {
+		return nil, errors.New("can only update agreements in draft status")
+	}
+	old := *agreement
+
+	if req.StartDate != "" {
+		agreement.StartDate = req.StartDate
+	}
+	if req.EndDate != "" {
+		agreement.EndDate = req.EndDate
+	}
+	if err := validateAgreementPeriod(agreement.StartDate, agreement.EndDate); err != nil {
+		return nil, err
+	}
+	if req.Currency != "" {
+		agreement.Currency = NormalizeCurrency(req.Currency)
+	}
+	agreement.PaymentTerms = req.PaymentTerms
+	agreement.Notes = req.Notes
+	agreement.UpdatedBy = &userID
+
+	if len(req.Items) > 0 {
+		items, err := s.buildItems(req.Items)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.ReplaceItems(id, items); err != nil {
+			return nil, err
+		}
+		agreement.CommittedValue = committedValue(items)
+	}
+	if err := s.repo.Update(agreement); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("purchase_agreements", "UPDATE", int64(id), int64(userID), username, old, updated)
+	return updated, nil
+}
+
+// Activate approves a draft agreement so call-off POs can be raised against it
+func (s *purchaseAgreementService) Activate(id uint, userID uint, username string) (*models.PurchaseAgreement, error) {
+	agreement, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if agreement.Status != "draft" {
+		return nil, errors.New("can only activate agreements in draft status")
+	}
+	if len(agreement.Items) == 0 {
+		return nil, errors.New("agreement has no items")
+	}
+
+	now := time.Now()
+	agreement.Status = "active"
+	agreement.ApprovedBy = &userID
+	agreement.ApprovedAt = &now
+	agreement.UpdatedBy = &userID
+	if err := s.repo.Update(agreement); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("purchase_agreements", "APPROVE", int64(id), int64(userID), username,
+		map[string]interface{}{"status": "draft"},
+		map[string]interface{}{"status": "active"})
+	return s.GetByID(id)
+}
+
+// Close ends an active agreement early; existing call-offs are unaffected
+func (s *purchaseAgreementService) Close(id uint, userID uint, username string) (*models.PurchaseAgreement, error) {
+	agreement, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("purchase agreement not found")
+	}
+	if agreement.Status != "active" {
+		return nil, errors.New("can only close active agreements")
+	}
+	if err := s.repo.UpdateStatus(id, "closed", userID); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("purchase_agreements", "CLOSE", int64(id), int64(userID), username,
+		map[string]interface{}{"status": agreement.Status},
+		map[string]interface{}{"status": "closed"})
+	return s.GetByID(id)
+}
+
+// Cancel voids an agreement that has no open call-off POs
+func (s *purchaseAgreementService) Cancel(id uint, userID uint, username string) error {
+	agreement, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("purchase agreement not found")
+	}
+	if agreement.Status != "draft" && agreement.Status != "active" {
+		return fmt.Errorf("cannot cancel agreement in %s status", agreement.Status)
+	}
+	open, err := s.repo.CountOpenCallOffs(id)
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return fmt.Errorf("agreement has %d open call-off orders, close it instead", open)
+	}
+	if err := s.repo.UpdateStatus(id, "cancelled", userID); err != nil {
+		return err
+	}
+
+	_ = s.auditSvc.Log("purchase_agreements", "CANCEL", int64(id), int64(userID), username,
+		map[string]interface{}{"status": agreement.Status},
+		map[string]interface{}{"status": "cancelled"})
+	return nil
+}
+
+func (s *purchaseAgreementService) ResolveForCallOff(id uint, supplierID uint, orderDate string) (*models.PurchaseAgreement, error) {
+	agreement, err := s.repo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("purchase agreement not found")
+		}
+		return nil, err
+	}
+	if agreement.Status != "active" {
+		return nil, fmt.Errorf("agreement %s is not active", agreement.AgreementNumber)
+	}
+	if agreement.SupplierID != supplierID {
+		return nil, fmt.Errorf("agreement %s belongs to another supplier", agreement.AgreementNumber)
+	}
+	orderDate = firstN(orderDate, 10)
+	start, end := firstN(agreement.StartDate, 10), firstN(agreement.EndDate, 10)
+	if orderDate < start || orderDate > end {
+		return nil, fmt.Errorf("order date %s is outside agreement validity %s to %s", orderDate, start, end)
+	}
+	return agreement, nil
+}
+
+func (s *purchaseAgreementService) CheckCallOff(agreement *models.PurchaseAgreement, excludePOID uint, lines []repository.AgreementConsumptionRow) ([]string, error) {
+	rows, err := s.repo.GetConsumption(agreement.ID, excludePOID)
+	if err != nil {
+		return nil, err
+	}
+	applyAgreementConsumption(agreement, addConsumption(rows, lines))
+	return agreement.Warnings, nil
+}
+
+// addConsumption adds call-off lines to the consumption rows of their materials
+func addConsumption(rows, lines []repository.AgreementConsumptionRow) []repository.AgreementConsumptionRow {
+	total := make([]repository.AgreementConsumptionRow, len(rows))
+	copy(total, rows)
+	for _, line := range lines {
+		found := false
+		for i := range total {
+			if total[i].MaterialID == line.MaterialID {
+				total[i].Quantity += line.Quantity
+				total[i].ReceivedQuantity += line.ReceivedQuantity
+				total[i].Value += line.Value
+				found = true
+				break
+			}
+		}
+		if !found {
+			total = append(total, line)
+		}
+	}
+	return total
+}
+
+func validateAgreementPeriod(start, end string) error {
+	startDate, err := time.Parse("2006-01-02", firstN(start, 10))
+	if err != nil {
+		return errors.New("invalid start_date format, use YYYY-MM-DD")
+	}
+	endDate, err := time.Parse("2006-01-02", firstN(end, 10))
+	if err != nil {
+		return errors.New("invalid end_date format, use YYYY-MM-DD")
+	}
+	if endDate.Before(startDate) {
+		return errors.New("end_date must not be before start_date")
+	}
+	return nil
+}
+
+func firstN(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+func committedValue(items []*models.PurchaseAgreementItem) float64 {
+	total := 0.0
+	for _, item := range items {
+		total += item.CommittedQuantity * item.AgreedPrice
+	}
+	return roundMoney(total)
+}
+
+// applyAgreementConsumption fills consumed/remaining figures and warns when call-offs
+// exceed the committed quantity or value
+func applyAgreementConsumption(agreement *models.PurchaseAgreement, rows []repository.AgreementConsumptionRow) {
+	byMaterial := make(map[uint]repository.AgreementConsumptionRow, len(rows))
+	for _, r := range rows {
+		byMaterial[r.MaterialID] = r
+	}
+
+	agreement.ConsumedValue = 0
+	agreement.Warnings = nil
+	for _, item := range agreement.Items {
+		r := byMaterial[item.MaterialID]
+		item.ConsumedQuantity = r.Quantity
+		item.ReceivedQuantity = r.ReceivedQuantity
+		item.ConsumedValue = roundMoney(r.Value)
+		item.RemainingQuantity = item.CommittedQuantity - r.Quantity
+		agreement.ConsumedValue += r.Value
+
+		if item.RemainingQuantity < 0 {
+			label := fmt.Sprintf("material %d", item.MaterialID)
+			if item.Material != nil {
+				label = item.Material.Code
+			}
+			agreement.Warnings = append(agreement.Warnings, fmt.Sprintf(
+				"%s: called off %s exceeds committed quantity %s",
+				label, formatQty(item.ConsumedQuantity), formatQty(item.CommittedQuantity)))
+		}
+	}
+	agreement.ConsumedValue = roundMoney(agreement.ConsumedValue)
+	agreement.RemainingValue = roundMoney(agreement.CommittedValue - agreement.ConsumedValue)
+	if agreement.RemainingValue < 0 {
+		agreement.Warnings = append(agreement.Warnings, fmt.Sprintf(
+			"called-off value %.2f exceeds committed value %.2f",
+			agreement.ConsumedValue, agreement.CommittedValue))
+	}
+}
+
+func formatQty(q float64) string {
+	return fmt.Sprintf("%g", q)
+}
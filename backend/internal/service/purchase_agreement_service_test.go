@@ -0,0 +1,64 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyAgreementConsumption(t *testing.T) {
+	items := []*models.PurchaseAgreementItem{
+		{MaterialID: 1, AgreedPrice: 10, CommittedQuantity: 100, Material: &models.Material{Code: "BOX-01"}},
+		{MaterialID: 2, AgreedPrice: 5, CommittedQuantity: 200},
+	}
+	agreement := &models.PurchaseAgreement{Items: items, CommittedValue: committedValue(items)}
+	require.Equal(t, 2000.0, agreement.CommittedValue)
+
+	t.Run("within commitment", func(t *testing.T) {
+		applyAgreementConsumption(agreement, []repository.AgreementConsumptionRow{
+			{MaterialID: 1, Quantity: 40, ReceivedQuantity: 30, Value: 400},
+		})
+		assert.Equal(t, 60.0, items[0].RemainingQuantity)
+		assert.Equal(t, 30.0, items[0].ReceivedQuantity)
+		assert.Equal(t, 200.0, items[1].RemainingQuantity)
+		assert.Equal(t, 400.0, agreement.ConsumedValue)
+		assert.Equal(t, 1600.0, agreement.RemainingValue)
+		assert.Empty(t, agreement.Warnings)
+	})
+
+	t.Run("quantity and value exceeded", func(t *testing.T) {
+		applyAgreementConsumption(agreement, []repository.AgreementConsumptionRow{
+			{MaterialID: 1, Quantity: 120, Value: 1200},
+			{MaterialID: 2, Quantity: 190, Value: 950},
+		})
+		assert.Equal(t, -20.0, items[0].RemainingQuantity)
+		assert.Equal(t, -150.0, agreement.RemainingValue)
+		require.Len(t, agreement.Warnings, 2)
+		assert.Contains(t, agreement.Warnings[0], "BOX-01")
+		assert.Contains(t, agreement.Warnings[1], "committed value")
+	})
+}
+
+func TestValidateAgreementPeriod(t *testing.T) {
+	assert.NoError(t, validateAgreementPeriod("2026-01-01", "2026-12-31"))
+	assert.NoError(t, validateAgreementPeriod("2026-01-01T00:00:00Z", "2026-01-01"))
+	assert.Error(t, validateAgreementPeriod("2026-12-31", "2026-01-01"))
+	assert.Error(t, validateAgreementPeriod("01/01/2026", "2026-12-31"))
+}
+
+func TestAddConsumption(t *testing.T) {
+	rows := []repository.AgreementConsumptionRow{{MaterialID: 1, Quantity: 40, ReceivedQuantity: 30, Value: 400}}
+	total := addConsumption(rows, []repository.AgreementConsumptionRow{
+		{MaterialID: 1, Quantity: 70, Value: 700},
+		{MaterialID: 2, Quantity: 10, Value: 50},
+	})
+	require.Len(t, total, 2)
+	assert.Equal(t, 110.0, total[0].Quantity)
+	assert.Equal(t, 30.0, total[0].ReceivedQuantity)
+	assert.Equal(t, 1100.0, total[0].Value)
+	assert.Equal(t, 10.0, total[1].Quantity)
+	assert.Equal(t, 40.0, rows[0].Quantity, "the consumption rows are not changed")
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PurchaseOrderPaymentService records supplier payments and the realized FX difference
+// between the VND booked at GRN posting and the VND actually paid
+type PurchaseOrderPaymentService interface {
+	RecordPayment(poID uint, req *dto.RecordPOPaymentRequest, userID uint, username string) (*models.PurchaseOrderPayment, error)
+	ListPayments(poID uint) ([]*models.PurchaseOrderPayment, error)
+	GetCurrencyReport(from, to, currency string) ([]dto.PurchaseCurrencyReportRow, error)
+}
+
+type purchaseOrderPaymentService struct {
+	db          *gorm.DB
+	poRepo      repository.PurchaseOrderRepository
+	paymentRepo repository.PurchaseOrderPaymentRepository
+	fxSvc       ExchangeRateService
+	auditSvc    AuditLogService
+}
+
+func NewPurchaseOrderPaymentService(
+	db *gorm.DB,
+	poRepo repository.PurchaseOrderRepository,
+	paymentRepo repository.PurchaseOrderPaymentRepository,
+	fxSvc ExchangeRateService,
+	auditSvc AuditLogService,
+) PurchaseOrderPaymentService {
+	return &purchaseOrderPaymentService{
+		db:          db,
+		poRepo:      poRepo,
+		paymentRepo: paymentRepo,
+		fxSvc:       fxSvc,
+		auditSvc:    auditSvc,
+	}
+}
+
+func (s *purchaseOrderPaymentService) RecordPayment(poID uint, req *dto.RecordPOPaymentRequest, userID uint, username string) (*models.PurchaseOrderPayment, error) {
+	if _, err := time.Parse("2006-01-02", req.PaymentDate); err != nil {
+		return nil, errors.New("invalid payment_date format, use YYYY-MM-DD")
+	}
+	po, err := s.poRepo.GetByID(poID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("purchase order not found")
+		}
+		return nil, err
+	}
+	if po.Status == "draft" || po.Status == "cancelled" {
+		return nil, errors.New("cannot record payments for draft or cancelled purchase orders")
+	}
+
+	currency := NormalizeCurrency(po.Currency)
+	rate := 1.0
+	bookedRate := 1.0
+	if currency != models.BaseCurrency {
+		rate = req.ExchangeRate
+		if rate <= 0 {
+			if rate, err = s.fxSvc.GetRate(currency, req.PaymentDate); err != nil {
+				return nil, err
+			}
+		}
+		if bookedRate, err = s.bookedRate(po); err != nil {
+			return nil, err
+		}
+	}
+
+	payment := &models.PurchaseOrderPayment{
+		PurchaseOrderID: poID,
+		PaymentDate:     req.PaymentDate,
+		Amount:          req.Amount,
+		Currency:        currency,
+		ExchangeRate:    rate,
+		BookedRate:      bookedRate,
+		Reference:       req.Reference,
+		Notes:           req.Notes,
+		CreatedBy:       &userID,
+	}
+	payment.BaseAmount, payment.BookedBaseAmount, payment.FXDifference = realizedFX(req.Amount, rate, bookedRate)
+
+	oldStatus := po.PaymentStatus
+	newStatus := oldStatus
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txPaymentRepo := repository.NewPurchaseOrderPaymentRepository(tx)
+		if err := txPaymentRepo.Create(payment); err != nil {
+			return err
+		}
+		paid, _, err := txPaymentRepo.SumByPO(poID)
+		if err != nil {
+			return err
+		}
+		newStatus = "partial"
+		if paid >= po.TotalAmount-0.005 {
+			newStatus = "completed"
+		}
+		return repository.NewPurchaseOrderRepository(tx).UpdateWorkflowStatus(poID, "payment_status", newStatus, req.Notes, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("purchase_orders", "RECORD_PAYMENT", int64(poID), int64(userID), username,
+		map[string]interface{}{"payment_status": oldStatus},
+		map[string]interface{}{
+			"payment_status": newStatus,
+			"amount":         payment.Amount,
+			"currency":       payment.Currency,
+			"exchange_rate":  payment.ExchangeRate,
+			"fx_difference":  payment.FXDifference,
+		})
+	return payment, nil
+}
+
+// bookedRate is the rate the payable was carried at: the value-weighted rate of posted GRNs,
+// falling back to the order-date rate when nothing has been received yet
+func (s *purchaseOrderPaymentService) bookedRate(po *models.PurchaseOrder) (float64, error) {
+	var row struct {
+		DocValue  float64
+		BaseValue float64
+	}
+	err := s.db.Table("goods_receipt_note_items gi").
+		Select(`COALESCE(SUM(gi.accepted_quantity * gi.unit_cost), 0) AS doc_value,
+			COALESCE(SUM(gi.accepted_quantity * gi.unit_cost * g.exchange_rate), 0) AS base_value`).
+		Joins("JOIN goods_receipt_notes g ON g.id = gi.grn_id").
+		Where("g.purchase_order_id = ? AND g.posted = true", po.ID).
+		Scan(&row).Error
+	if err != nil {
+		return 0, err
+	}
+	if row.DocValue > 0 {
+		return row.BaseValue / row.DocValue, nil
+	}
+	if po.ExchangeRate > 0 {
+		return po.ExchangeRate, nil
+	}
+	return 1, nil
+}
+
+func (s *purchaseOrderPaymentService) ListPayments(poID uint) ([]*models.PurchaseOrderPayment, error) {
+	return s.paymentRepo.ListByPO(poID)
+}
+
+// GetCurrencyReport lists POs in the period with amounts in document currency and VND
+func (s *purchaseOrderPaymentService) GetCurrencyReport(from, to, currency string) ([]dto.PurchaseCurrencyReportRow, error) {
+	query := s.db.Table("purchase_orders po").
+		Select(`po.id AS purchase_order_id, po.po_number, s.code AS supplier_code, s.name AS supplier_name,
+			po.order_date, po.currency, po.exchange_rate AS order_rate, po.total_amount,
+			COALESCE(rcv.doc_value, 0) AS received_amount, COALESCE(rcv.base_value, 0) AS received_base,
+			COALESCE(pay.amount, 0) AS paid_amount, COALESCE(pay.base_amount, 0) AS paid_base,
+			COALESCE(pay.fx_difference, 0) AS realized_fx_amount`).
+		Joins("JOIN suppliers s ON s.id = po.supplier_id").
+		Joins(`LEFT JOIN (
+			SELECT g.purchase_order_id,
+			       SUM(gi.accepted_quantity * gi.unit_cost) AS doc_value,
+			       SUM(gi.accepted_quantity * COALESCE(gi.base_unit_cost, gi.unit_cost)) AS base_value
+			FROM goods_receipt_note_items gi
+			JOIN goods_receipt_notes g ON g.id = gi.grn_id
+			WHERE g.posted = true
+			GROUP BY g.purchase_order_id) rcv ON rcv.purchase_order_id = po.id`).
+		Joins(`LEFT JOIN (
+			SELECT purchase_order_id, SUM(amount) AS amount, SUM(base_amount) AS base_amount,
+			       SUM(fx_difference) AS fx_difference
+			FROM purchase_order_payments
+			GROUP BY purchase_order_id) pay ON pay.purchase_order_id = po.id`).
+		Where("po.status <> 'cancelled'")
+	if from != "" {
+		query = query.Where("po.order_date >= ?", from)
+	}
+	if to != "" {
+		query = query.Where("po.order_date <= ?", to)
+	}
+	if currency != "" {
+		query = query.Where("po.currency = ?", NormalizeCurrency(currency))
+	}
+
+	var rows []dto.PurchaseCurrencyReportRow
+	if err := query.Order("po.order_date DESC, po.id DESC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].OrderDate = firstN(rows[i].OrderDate, 10)
+		rows[i].TotalBaseAmount = toBase(rows[i].TotalAmount, rows[i].OrderRate)
+		rows[i].ReceivedAmount = roundMoney(rows[i].ReceivedAmount)
+		rows[i].ReceivedBase = roundMoney(rows[i].ReceivedBase)
+	}
+	return rows, nil
+}
+
+// realizedFX returns the VND paid, the VND booked for the same amount and their difference
+// (positive = loss: more VND paid than booked)
+func realizedFX(amount, rate, bookedRate float64) (base, booked, diff float64) {
+	base = toBase(amount, rate)
+	booked = toBase(amount, bookedRate)
+	diff = roundMoney(base - booked)
+	if math.Abs(diff) < 0.005 {
+		diff = 0
+	}
+	return base, booked, diff
+}
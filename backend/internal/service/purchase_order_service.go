@@ -5,6 +5,9 @@ import (
 	"github.com/VyVy-ERP/warehouse-backend/internal/models"
 	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -22,6 +25,7 @@ type PurchaseOrderService interface {
 	UpdateOrderStatus(id uint, req *dto.UpdateOrderStatusRequest, userID uint, username string) (*models.SafePurchaseOrder, error)
 	UpdatePaymentStatus(id uint, req *dto.UpdatePaymentStatusRequest, userID uint, username string) (*models.SafePurchaseOrder, error)
 	UpdateInvoiceStatus(id uint, req *dto.UpdateInvoiceStatusRequest, userID uint, username string) (*models.SafePurchaseOrder, error)
+	CloseShort(id uint, req *dto.ClosePOShortRequest, userID uint, username string) (*models.SafePurchaseOrder, error)
 }
 
 type purchaseOrderService struct {
@@ -32,6 +36,11 @@ type purchaseOrderService struct {
 	ppRepo        repository.ProductionPlanRepository // for KHSX status hooks
 	auditSvc      AuditLogService
 	db            *gorm.DB
+	complianceSvc SupplierComplianceService // mandatory supplier documents gate
+	agreementSvc  PurchaseAgreementService  // blanket agreement pricing for call-off POs
+	fxSvc         ExchangeRateService       // order-date rate for foreign currency POs
+	docSvc        DocumentService           // files the PO PDF on approval when configured
+	mailSvc       POMailService             // e-mails the PO to the supplier on approval when configured
 }
 
 // NewPurchaseOrderService creates a new PurchaseOrderService
@@ -43,6 +52,11 @@ func NewPurchaseOrderService(
 	ppRepo repository.ProductionPlanRepository,
 	db *gorm.DB,
 	auditSvc AuditLogService,
+	complianceSvc SupplierComplianceService,
+	agreementSvc PurchaseAgreementService,
+	fxSvc ExchangeRateService,
+	docSvc DocumentService,
+	mailSvc POMailService,
 ) PurchaseOrderService {
 	return &purchaseOrderService{
 		poRepo:        poRepo,
@@ -52,9 +66,69 @@ func NewPurchaseOrderService(
 		ppRepo:        ppRepo,
 		db:            db,
 		auditSvc:      auditSvc,
+		complianceSvc: complianceSvc,
+		agreementSvc:  agreementSvc,
+		fxSvc:         fxSvc,
+		docSvc:        docSvc,
+		mailSvc:       mailSvc,
 	}
 }
 
+// orderExchangeRate returns the VND rate for a PO currency on its order date
+func (s *purchaseOrderService) orderExchangeRate(currency string, orderDate string) (float64, error) {
+	if currency == models.BaseCurrency {
+		return 1, nil
+	}
+	if s.fxSvc == nil {
+		return 0, errors.New("exchange rates are not available")
+	}
+	return s.fxSvc.GetRate(currency, orderDate)
+}
+
+// applyAgreementPricing overrides item prices with the agreed ones for a call-off PO, which
+// must be in the currency of the agreement. A call-off taking the agreement past its committed
+// quantity or value is refused unless allowOver is set; poID is the PO being edited, if any.
+func (s *purchaseOrderService) applyAgreementPricing(agreementID uint, supplierID uint, orderDate, currency string, poID uint, allowOver bool, items []*models.PurchaseOrderItem) error {
+	if s.agreementSvc == nil {
+		return errors.New("purchase agreements are not available")
+	}
+	agreement, err := s.agreementSvc.ResolveForCallOff(agreementID, supplierID, orderDate)
+	if err != nil {
+		return err
+	}
+	if agreed := NormalizeCurrency(agreement.Currency); agreed != NormalizeCurrency(currency) {
+		return fmt.Errorf("agreement %s is priced in %s, the PO is in %s", agreement.AgreementNumber, agreed, NormalizeCurrency(currency))
+	}
+	for _, item := range items {
+		line := agreement.ItemFor(item.MaterialID)
+		if line == nil {
+			return fmt.Errorf("material %d is not covered by agreement %s", item.MaterialID, agreement.AgreementNumber)
+		}
+		item.UnitPrice = line.AgreedPrice
+		item.TaxRate = line.TaxRate
+		item.DiscountRate = line.DiscountRate
+		item.CalculateLineTotal()
+	}
+
+	lines := make([]repository.AgreementConsumptionRow, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, repository.AgreementConsumptionRow{
+			MaterialID: item.MaterialID,
+			Quantity:   item.Quantity,
+			Value:      item.Quantity * item.UnitPrice,
+		})
+	}
+	warnings, err := s.agreementSvc.CheckCallOff(agreement, poID, lines)
+	if err != nil {
+		return err
+	}
+	if len(warnings) > 0 && !allowOver {
+		return fmt.Errorf("call-off exceeds agreement %s: %s; set allow_over_commitment to place it anyway",
+			agreement.AgreementNumber, strings.Join(warnings, "; "))
+	}
+	return nil
+}
+
 // findLinkedKHSXIDs finds all production plan IDs linked to the given PO via notes.
 func (s *purchaseOrderService) findLinkedKHSXIDs(po *models.PurchaseOrder) []uint {
 	if s.ppRepo == nil {
@@ -87,7 +161,7 @@ func (s *purchaseOrderService) CreatePurchaseOrder(req *dto.CreatePurchaseOrderR
 	}
 
 	// Validate supplier exists
-	_, err = s.supplierRepo.GetByID(req.SupplierID)
+	supplier, err := s.supplierRepo.GetByID(req.SupplierID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, errors.New("supplier not found")
@@ -95,6 +169,16 @@ func (s *purchaseOrderService) CreatePurchaseOrder(req *dto.CreatePurchaseOrderR
 		return nil, err
 	}
 
+	// Document currency defaults to the supplier's; the rate is fixed on the order date
+	currency := NormalizeCurrency(req.Currency)
+	if req.Currency == "" {
+		currency = NormalizeCurrency(supplier.Currency)
+	}
+	exchangeRate, err := s.orderExchangeRate(currency, req.OrderDate)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate warehouse exists
 	_, err = s.warehouseRepo.GetByID(req.WarehouseID)
 	if err != nil {
@@ -104,11 +188,49 @@ func (s *purchaseOrderService) CreatePurchaseOrder(req *dto.CreatePurchaseOrderR
 		return nil, err
 	}
 
+	// Build items
+	items := make([]*models.PurchaseOrderItem, len(req.Items))
+	for i, itemReq := range req.Items {
+		item := &models.PurchaseOrderItem{
+			MaterialID:      itemReq.MaterialID,
+			Quantity:        itemReq.Quantity,
+			UnitPrice:       itemReq.UnitPrice,
+			TaxRate:         itemReq.TaxRate,
+			DiscountRate:    itemReq.DiscountRate,
+			Notes:           itemReq.Notes,
+			Attachments:     itemReq.Attachments,
+			CreatedBy:       &userID,
+			UpdatedBy:       &userID,
+		}
+		if itemReq.ExpectedDeliveryDate != "" {
+			item.ExpectedDeliveryDate = &itemReq.ExpectedDeliveryDate
+		}
+		// Calculate line total
+		item.CalculateLineTotal()
+		items[i] = item
+	}
+
+	// Call-off PO: prices come from the blanket agreement
+	if req.AgreementID != nil {
+		if err := s.applyAgreementPricing(*req.AgreementID, req.SupplierID, req.OrderDate, currency, 0, req.AllowOverCommitment, items); err != nil {
+			return nil, err
+		}
+	}
+
+	source := req.Source
+	if source == "" {
+		source = "manual"
+	}
+
 	// Create purchase order
 	po := &models.PurchaseOrder{
 		PONumber:             req.PONumber,
 		SupplierID:           req.SupplierID,
 		WarehouseID:          req.WarehouseID,
+		AgreementID:          req.AgreementID,
+		Source:               source,
+		Currency:             currency,
+		ExchangeRate:         exchangeRate,
 		OrderDate:            req.OrderDate,
 		PaymentTerms:         req.PaymentTerms,
 		ShippingMethod:       req.ShippingMethod,
@@ -128,28 +250,8 @@ func (s *purchaseOrderService) CreatePurchaseOrder(req *dto.CreatePurchaseOrderR
 	if err := s.poRepo.Create(po); err != nil {
 		return nil, err
 	}
-
-	// Create items
-	items := make([]*models.PurchaseOrderItem, len(req.Items))
-	for i, itemReq := range req.Items {
-		item := &models.PurchaseOrderItem{
-			PurchaseOrderID: po.ID,
-			MaterialID:      itemReq.MaterialID,
-			Quantity:        itemReq.Quantity,
-			UnitPrice:       itemReq.UnitPrice,
-			TaxRate:         itemReq.TaxRate,
-			DiscountRate:    itemReq.DiscountRate,
-			Notes:           itemReq.Notes,
-			Attachments:     itemReq.Attachments,
-			CreatedBy:       &userID,
-			UpdatedBy:       &userID,
-		}
-		if itemReq.ExpectedDeliveryDate != "" {
-			item.ExpectedDeliveryDate = &itemReq.ExpectedDeliveryDate
-		}
-		// Calculate line total
-		item.CalculateLineTotal()
-		items[i] = item
+	for _, item := range items {
+		item.PurchaseOrderID = po.ID
 	}
 
 	// Bulk create items
@@ -294,6 +396,9 @@ func (s *purchaseOrderService) UpdatePurchaseOrder(id uint, req *dto.UpdatePurch
 	}
 
 	// Update fields if provided
+	if req.SupplierID > 0 && req.SupplierID != po.SupplierID && po.AgreementID != nil {
+		return nil, errors.New("cannot change supplier of a call-off order")
+	}
 	if req.SupplierID > 0 {
 		// Validate supplier exists
 		_, err = s.supplierRepo.GetByID(req.SupplierID)
@@ -318,8 +423,14 @@ func (s *purchaseOrderService) UpdatePurchaseOrder(id uint, req *dto.UpdatePurch
 		po.WarehouseID = req.WarehouseID
 	}
 
-	if req.OrderDate != "" {
+	if req.OrderDate != "" && req.OrderDate != po.OrderDate {
 		po.OrderDate = req.OrderDate
+		// Re-fix the order rate for the new date
+		rate, err := s.orderExchangeRate(NormalizeCurrency(po.Currency), po.OrderDate)
+		if err != nil {
+			return nil, err
+		}
+		po.ExchangeRate = rate
 	}
 	if req.ExpectedDeliveryDate != "" {
 		po.ExpectedDeliveryDate = &req.ExpectedDeliveryDate
@@ -329,6 +440,13 @@ func (s *purchaseOrderService) UpdatePurchaseOrder(id uint, req *dto.UpdatePurch
 	po.Notes = req.Notes
 	po.UpdatedBy = &userID
 
+	// Call-off PO: the order date must stay within the agreement validity
+	if po.AgreementID != nil && s.agreementSvc != nil {
+		if _, err := s.agreementSvc.ResolveForCallOff(*po.AgreementID, po.SupplierID, po.OrderDate); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update PO
 	if err := s.poRepo.Update(po); err != nil {
 		return nil, err
@@ -336,11 +454,6 @@ func (s *purchaseOrderService) UpdatePurchaseOrder(id uint, req *dto.UpdatePurch
 
 	// Update items if provided
 	if len(req.Items) > 0 {
-		// Delete old items
-		if err := s.poItemRepo.DeleteByPOID(id); err != nil {
-			return nil, err
-		}
-
 		// Create new items
 		items := make([]*models.PurchaseOrderItem, len(req.Items))
 		for i, itemReq := range req.Items {
@@ -364,6 +477,18 @@ func (s *purchaseOrderService) UpdatePurchaseOrder(id uint, req *dto.UpdatePurch
 			items[i] = item
 		}
 
+		// Call-off PO: keep agreed prices regardless of what was sent
+		if po.AgreementID != nil {
+			if err := s.applyAgreementPricing(*po.AgreementID, po.SupplierID, po.OrderDate, po.Currency, po.ID, req.AllowOverCommitment, items); err != nil {
+				return nil, err
+			}
+		}
+
+		// Delete old items
+		if err := s.poItemRepo.DeleteByPOID(id); err != nil {
+			return nil, err
+		}
+
 		// Bulk create items
 		if err := s.poItemRepo.CreateBulk(items); err != nil {
 			return nil, err
@@ -456,6 +581,17 @@ func (s *purchaseOrderService) ApprovePurchaseOrder(id uint, userID uint, userna
 		return nil, errors.New("can only approve purchase orders in draft status")
 	}
 
+	// Block approval when mandatory supplier documents are missing or expired
+	if s.complianceSvc != nil {
+		materialIDs := make([]uint, 0, len(po.Items))
+		for _, item := range po.Items {
+			materialIDs = append(materialIDs, item.MaterialID)
+		}
+		if err := s.complianceSvc.EnsureCompliant(po.SupplierID, materialIDs, ComplianceStagePOApproval); err != nil {
+			return nil, err
+		}
+	}
+
 	oldStatus := po.Status
 	// Update status to approved
 	now := time.Now()
@@ -473,6 +609,21 @@ func (s *purchaseOrderService) ApprovePurchaseOrder(id uint, userID uint, userna
 		map[string]interface{}{"status": oldStatus},
 		map[string]interface{}{"status": "approved"})
 
+	// The printed PO is a convenience copy; approval stands even if rendering fails
+	if s.docSvc != nil {
+		if err := s.docSvc.AttachPurchaseOrderPDF(id, userID); err != nil {
+			log.Printf("purchase order %s: attach PDF: %v", po.PONumber, err)
+		}
+	}
+	// SMTP can be slow, so the supplier e-mail goes out in the background; the outcome is in po_email_logs
+	if s.mailSvc != nil {
+		go func(poNumber string) {
+			if _, err := s.mailSvc.SendPurchaseOrder(id, &dto.SendPOEmailRequest{}, userID, username); err != nil {
+				log.Printf("purchase order %s: e-mail supplier: %v", poNumber, err)
+			}
+		}(po.PONumber)
+	}
+
 	return po.ToSafe(), nil
 }
 
@@ -511,6 +662,84 @@ func (s *purchaseOrderService) CancelPurchaseOrder(id uint, userID uint, usernam
 	return po.ToSafe(), nil
 }
 
+// CloseShort closes PO lines the supplier will not deliver in full, so the PO can complete
+func (s *purchaseOrderService) CloseShort(id uint, req *dto.ClosePOShortRequest, userID uint, username string) (*models.SafePurchaseOrder, error) {
+	po, err := s.poRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("purchase order not found")
+		}
+		return nil, err
+	}
+	if po.Status != "approved" {
+		return nil, errors.New("only approved purchase orders can be closed short")
+	}
+
+	grnItemRepo := repository.NewGoodsReceiptNoteItemRepository(s.db)
+	var itemIDs []uint
+	for _, item := range po.Items {
+		selected := len(req.ItemIDs) == 0 || containsUint(req.ItemIDs, item.ID)
+		if !selected {
+			continue
+		}
+		fulfilled := resolveReceiptTolerance(item.Material, po.Supplier).fulfilled(item.Quantity, item.ReceivedQuantity)
+		if item.ClosedShort || fulfilled {
+			if len(req.ItemIDs) == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("%s: PO line is already received or closed short", poLineLabel(item))
+		}
+		unposted, err := grnItemRepo.SumUnpostedByPOItem(item.ID)
+		if err != nil {
+			return nil, err
+		}
+		if unposted > qtyEpsilon {
+			return nil, fmt.Errorf("%s: %s is still on unposted GRNs; post or reject them first", poLineLabel(item), formatQty(unposted))
+		}
+		itemIDs = append(itemIDs, item.ID)
+	}
+	for _, itemID := range req.ItemIDs {
+		if !poHasItem(po, itemID) {
+			return nil, fmt.Errorf("PO item %d does not belong to purchase order %s", itemID, po.PONumber)
+		}
+	}
+	if len(itemIDs) == 0 {
+		return nil, errors.New("no open PO lines to close short")
+	}
+
+	oldReceiptStatus := po.ReceiptStatus
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txPORepo := repository.NewPurchaseOrderRepository(tx)
+		if err := txPORepo.CloseShortItems(id, itemIDs, req.Reason, userID, time.Now()); err != nil {
+			return err
+		}
+		return refreshReceiptStatus(txPORepo, id, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	po, err = s.poRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("purchase_orders", "CLOSE_SHORT", int64(id), int64(userID), username,
+		map[string]interface{}{"receipt_status": oldReceiptStatus},
+		map[string]interface{}{"receipt_status": po.ReceiptStatus, "status": po.Status, "item_ids": itemIDs, "reason": req.Reason})
+
+	return po.ToSafe(), nil
+}
+
+func poHasItem(po *models.PurchaseOrder, itemID uint) bool {
+	for _, item := range po.Items {
+		if item.ID == itemID {
+			return true
+		}
+	}
+	return false
+}
+
 // UpdateOrderStatus updates the ordering status of a PO (B4: procurement confirms order placed)
 func (s *purchaseOrderService) UpdateOrderStatus(id uint, req *dto.UpdateOrderStatusRequest, userID uint, username string) (*models.SafePurchaseOrder, error) {
 	po, err := s.poRepo.GetByID(id)
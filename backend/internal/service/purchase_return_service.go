@@ -0,0 +1,621 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Purchase return sources and line resolutions
+const (
+	PurchaseReturnSourceGRNRejected = "grn_rejected"
+	PurchaseReturnSourceStock       = "stock"
+
+	PurchaseReturnResolutionCredit      = "credit"
+	PurchaseReturnResolutionReplacement = "replacement"
+)
+
+// PurchaseReturnService handles returns to supplier (trả hàng NCC) for QC-rejected and damaged material
+type PurchaseReturnService interface {
+	Create(req *dto.CreatePurchaseReturnRequest, userID uint, username string) (*models.PurchaseReturn, error)
+	GetByID(id uint) (*models.PurchaseReturn, error)
+	List(filters map[string]interface{}, offset, limit int) ([]*models.PurchaseReturn, int64, error)
+	Post(id uint, userID uint, username string) (*models.PurchaseReturn, error)
+	Cancel(id uint, userID uint, username string) (*models.PurchaseReturn, error)
+}
+
+type purchaseReturnService struct {
+	db               *gorm.DB
+	repo             repository.PurchaseReturnRepository
+	grnRepo          repository.GoodsReceiptNoteRepository
+	grnItemRepo      repository.GoodsReceiptNoteItemRepository
+	poRepo           repository.PurchaseOrderRepository
+	supplierRepo     repository.SupplierRepository
+	warehouseRepo    repository.WarehouseRepository
+	stockBalanceRepo repository.StockBalanceRepository
+	auditSvc         AuditLogService
+	fxSvc            ExchangeRateService // converts the debit note to VND on the return date
+}
+
+func NewPurchaseReturnService(
+	db *gorm.DB,
+	repo repository.PurchaseReturnRepository,
+	grnRepo repository.GoodsReceiptNoteRepository,
+	grnItemRepo repository.GoodsReceiptNoteItemRepository,
+	poRepo repository.PurchaseOrderRepository,
+	supplierRepo repository.SupplierRepository,
+	warehouseRepo repository.WarehouseRepository,
+	stockBalanceRepo repository.StockBalanceRepository,
+	auditSvc AuditLogService,
+	fxSvc ExchangeRateService,
+) PurchaseReturnService {
+	return &purchaseReturnService{
+		db:               db,
+		repo:             repo,
+		grnRepo:          grnRepo,
+		grnItemRepo:      grnItemRepo,
+		poRepo:           poRepo,
+		supplierRepo:     supplierRepo,
+		warehouseRepo:    warehouseRepo,
+		stockBalanceRepo: stockBalanceRepo,
+		auditSvc:         auditSvc,
+		fxSvc:            fxSvc,
+	}
+}
+
+// generateReturnNumber creates a number like PRT-2026-000001
+func (s *purchaseReturnService) generateReturnNumber() (string, error) {
+	prefix := fmt.Sprintf("PRT-%s-", time.Now().Format("2006"))
+	count, err := s.repo.CountByReturnNumber(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%06d", prefix, count+1), nil
+}
+
+// generateDebitNoteNumber creates a number like DN-2026-000001
+func (s *purchaseReturnService) generateDebitNoteNumber() (string, error) {
+	prefix := fmt.Sprintf("DN-%s-", time.Now().Format("2006"))
+	count, err := s.repo.CountByDebitNoteNumber(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%06d", prefix, count+1), nil
+}
+
+func (s *purchaseReturnService) Create(req *dto.CreatePurchaseReturnRequest, userID uint, username string) (*models.PurchaseReturn, error) {
+	if _, err := time.Parse("2006-01-02", req.ReturnDate); err != nil {
+		return nil, errors.New("return_date must be YYYY-MM-DD")
+	}
+
+	ret := &models.PurchaseReturn{
+		ReturnDate:        req.ReturnDate,
+		Source:            req.Source,
+		Status:            "draft",
+		Reason:            req.Reason,
+		ExchangeRate:      1,
+		ReplacementStatus: "none",
+		Notes:             req.Notes,
+		CreatedBy:         &userID,
+		UpdatedBy:         &userID,
+	}
+
+	var err error
+	switch req.Source {
+	case PurchaseReturnSourceGRNRejected:
+		err = s.buildFromRejected(ret, req)
+	case PurchaseReturnSourceStock:
+		err = s.buildFromStock(ret, req)
+	default:
+		err = fmt.Errorf("unsupported return source %q", req.Source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	number, err := s.generateReturnNumber()
+	if err != nil {
+		return nil, err
+	}
+	ret.ReturnNumber = number
+	if err := s.repo.Create(ret); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("purchase_returns", "CREATE", int64(ret.ID), int64(userID), username, nil, map[string]interface{}{
+		"return_number": ret.ReturnNumber,
+		"source":        ret.Source,
+		"supplier_id":   ret.SupplierID,
+		"grn_id":        ret.GRNID,
+		"items_count":   len(ret.Items),
+	})
+	return s.GetByID(ret.ID)
+}
+
+// buildFromRejected returns quantities QC rejected on a GRN; that stock was never posted
+func (s *purchaseReturnService) buildFromRejected(ret *models.PurchaseReturn, req *dto.CreatePurchaseReturnRequest) error {
+	if req.GRNID == nil {
+		return errors.New("grn_id is required for grn_rejected returns")
+	}
+	grn, err := s.grnRepo.GetByID(*req.GRNID)
+	if err != nil {
+		return errors.New("GRN not found")
+	}
+	if grn.Status != "qc_completed" && grn.Status != "posted" {
+		return errors.New("GRN must be QC completed before rejected goods can be returned")
+	}
+	if grn.PurchaseOrder == nil {
+		return errors.New("GRN is not linked to a purchase order")
+	}
+
+	ret.SupplierID = grn.PurchaseOrder.SupplierID
+	ret.PurchaseOrderID = grn.PurchaseOrderID
+	ret.GRNID = &grn.ID
+	ret.WarehouseID = grn.WarehouseID
+	ret.Currency = NormalizeCurrency(grn.Currency)
+
+	grnItems := make(map[uint]*models.GoodsReceiptNoteItem, len(grn.Items))
+	for _, item := range grn.Items {
+		grnItems[item.ID] = item
+	}
+
+	requested := make(map[uint]float64, len(req.Items))
+	for _, r := range req.Items {
+		if r.GRNItemID == nil {
+			return errors.New("grn_item_id is required for every line of a grn_rejected return")
+		}
+		source, ok := grnItems[*r.GRNItemID]
+		if !ok {
+			return fmt.Errorf("GRN item %d does not belong to GRN %s", *r.GRNItemID, grn.GRNNumber)
+		}
+		returned, err := s.repo.SumReturnedByGRNItem(source.ID)
+		if err != nil {
+			return err
+		}
+		requested[source.ID] += r.Quantity
+		if remaining := source.RejectedQuantity - returned; requested[source.ID] > remaining {
+			return fmt.Errorf("GRN item %d: only %s rejected quantity left to return", source.ID, formatQty(remaining))
+		}
+
+		poItemID := source.POItemID
+		ret.Items = append(ret.Items, &models.PurchaseReturnItem{
+			GRNItemID:           &source.ID,
+			POItemID:            &poItemID,
+			MaterialID:          source.MaterialID,
+			WarehouseLocationID: source.WarehouseLocationID,
+			BatchNumber:         source.BatchNumber,
+			LotNumber:           source.LotNumber,
+			Quantity:            r.Quantity,
+			UnitCost:            source.UnitCost,
+			Resolution:          resolutionOrDefault(r.Resolution),
+			Reason:              r.Reason,
+		})
+	}
+	return nil
+}
+
+// buildFromStock returns posted stock by batch; the originating GRN line supplies the purchase price
+func (s *purchaseReturnService) buildFromStock(ret *models.PurchaseReturn, req *dto.CreatePurchaseReturnRequest) error {
+	if req.WarehouseID == 0 {
+		return errors.New("warehouse_id is required for stock returns")
+	}
+	if _, err := s.warehouseRepo.GetByID(req.WarehouseID); err != nil {
+		return errors.New("warehouse not found")
+	}
+	ret.WarehouseID = req.WarehouseID
+
+	supplierID := req.SupplierID
+	currency := ""
+	if req.PurchaseOrderID != nil {
+		po, err := s.poRepo.GetByID(*req.PurchaseOrderID)
+		if err != nil {
+			return errors.New("purchase order not found")
+		}
+		if supplierID != 0 && supplierID != po.SupplierID {
+			return errors.New("purchase order belongs to a different supplier")
+		}
+		supplierID = po.SupplierID
+		currency = po.Currency
+		ret.PurchaseOrderID = &po.ID
+	}
+	if supplierID == 0 {
+		return errors.New("supplier_id is required for stock returns without a purchase order")
+	}
+	supplier, err := s.supplierRepo.GetByID(supplierID)
+	if err != nil {
+		return errors.New("supplier not found")
+	}
+	if currency == "" {
+		currency = supplier.Currency
+	}
+	ret.SupplierID = supplierID
+	ret.Currency = NormalizeCurrency(currency)
+
+	for _, r := range req.Items {
+		item := &models.PurchaseReturnItem{
+			MaterialID:          r.MaterialID,
+			WarehouseLocationID: r.WarehouseLocationID,
+			BatchNumber:         r.BatchNumber,
+			LotNumber:           r.LotNumber,
+			Quantity:            r.Quantity,
+			Resolution:          resolutionOrDefault(r.Resolution),
+			SerialNumbers:       cleanSerials(r.SerialNumbers),
+			Reason:              r.Reason,
+		}
+
+		source, err := s.resolveSourceGRNItem(ret, r)
+		if err != nil {
+			return err
+		}
+		if source != nil {
+			item.GRNItemID = &source.ID
+			poItemID := source.POItemID
+			item.POItemID = &poItemID
+			item.MaterialID = source.MaterialID
+			item.BatchNumber = source.BatchNumber
+			item.LotNumber = source.LotNumber
+			if item.WarehouseLocationID == nil {
+				item.WarehouseLocationID = source.WarehouseLocationID
+			}
+		}
+		if item.MaterialID == 0 {
+			return errors.New("material_id or grn_item_id is required for every line of a stock return")
+		}
+
+		balance, err := s.stockBalanceRepo.Get("material", item.MaterialID, ret.WarehouseID, item.WarehouseLocationID, item.BatchNumber, item.LotNumber)
+		if err != nil {
+			return fmt.Errorf("no stock of material %d batch %q at the selected location", item.MaterialID, item.BatchNumber)
+		}
+		if balance.AvailableQuantity < item.Quantity {
+			return fmt.Errorf("material %d batch %q: only %s available", item.MaterialID, item.BatchNumber, formatQty(balance.AvailableQuantity))
+		}
+
+		// Credit at the purchase price; without a source receipt only a VND stock cost is known
+		switch {
+		case source != nil:
+			item.UnitCost = source.UnitCost
+		case ret.Currency == models.BaseCurrency:
+			item.UnitCost = balance.UnitCost
+		default:
+			return fmt.Errorf("material %d batch %q: no posted receipt from this supplier to price the return in %s", item.MaterialID, item.BatchNumber, ret.Currency)
+		}
+		if item.Resolution == PurchaseReturnResolutionReplacement && item.POItemID == nil {
+			return fmt.Errorf("material %d batch %q: replacement requires a purchase order receipt", item.MaterialID, item.BatchNumber)
+		}
+		ret.Items = append(ret.Items, item)
+	}
+	return nil
+}
+
+// resolveSourceGRNItem finds the posted GRN line the returned batch came from (nil when unknown)
+func (s *purchaseReturnService) resolveSourceGRNItem(ret *models.PurchaseReturn, r dto.PurchaseReturnItemRequest) (*models.GoodsReceiptNoteItem, error) {
+	if r.GRNItemID != nil {
+		source, err := s.grnItemRepo.GetByID(*r.GRNItemID)
+		if err != nil {
+			return nil, fmt.Errorf("GRN item %d not found", *r.GRNItemID)
+		}
+		grn, err := s.grnRepo.GetByID(source.GRNID)
+		if err != nil {
+			return nil, err
+		}
+		if !grn.Posted {
+			return nil, fmt.Errorf("GRN %s is not posted", grn.GRNNumber)
+		}
+		if grn.PurchaseOrder == nil || grn.PurchaseOrder.SupplierID != ret.SupplierID {
+			return nil, fmt.Errorf("GRN %s was not received from this supplier", grn.GRNNumber)
+		}
+		if ret.PurchaseOrderID != nil && (grn.PurchaseOrderID == nil || *grn.PurchaseOrderID != *ret.PurchaseOrderID) {
+			return nil, fmt.Errorf("GRN %s does not belong to the selected purchase order", grn.GRNNumber)
+		}
+		if NormalizeCurrency(grn.Currency) != ret.Currency {
+			return nil, fmt.Errorf("GRN %s is in %s, return is in %s", grn.GRNNumber, NormalizeCurrency(grn.Currency), ret.Currency)
+		}
+		return source, nil
+	}
+	if r.MaterialID == 0 {
+		return nil, nil
+	}
+
+	source, err := s.repo.FindSourceGRNItem(ret.SupplierID, ret.PurchaseOrderID, r.MaterialID, r.BatchNumber, r.LotNumber)
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	grn, err := s.grnRepo.GetByID(source.GRNID)
+	if err != nil {
+		return nil, err
+	}
+	if NormalizeCurrency(grn.Currency) != ret.Currency {
+		return nil, nil
+	}
+	return source, nil
+}
+
+func resolutionOrDefault(resolution string) string {
+	if resolution == "" {
+		return PurchaseReturnResolutionCredit
+	}
+	return resolution
+}
+
+func (s *purchaseReturnService) GetByID(id uint) (*models.PurchaseReturn, error) {
+	ret, err := s.repo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("purchase return not found")
+		}
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (s *purchaseReturnService) List(filters map[string]interface{}, offset, limit int) ([]*models.PurchaseReturn, int64, error) {
+	return s.repo.List(filters, offset, limit)
+}
+
+// Post issues returned stock, reopens PO lines awaiting replacement and raises the debit note
+func (s *purchaseReturnService) Post(id uint, userID uint, username string) (*models.PurchaseReturn, error) {
+	ret, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if ret.Status != "draft" {
+		return nil, fmt.Errorf("cannot post a purchase return in status %s", ret.Status)
+	}
+
+	exchangeRate := 1.0
+	if currency := NormalizeCurrency(ret.Currency); currency != models.BaseCurrency {
+		if s.fxSvc == nil {
+			return nil, errors.New("exchange rates are not available")
+		}
+		exchangeRate, err = s.fxSvc.GetRate(currency, ret.ReturnDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	debitNoteAmount := purchaseReturnDebitNote(ret.Items)
+	debitNoteNumber := ""
+	if debitNoteAmount > 0 {
+		if debitNoteNumber, err = s.generateDebitNoteNumber(); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	reopenPO := false
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewPurchaseReturnRepository(tx)
+		txStockLedgerRepo := repository.NewStockLedgerRepository(tx)
+		txStockBalanceRepo := repository.NewStockBalanceRepository(tx)
+
+		if err := checkWarehouseNotFrozen(tx, ret.WarehouseID); err != nil {
+			return err
+		}
+
+		for _, item := range ret.Items {
+			// Rejected goods never entered stock; only posted stock is issued
+			if ret.Source != PurchaseReturnSourceStock {
+				continue
+			}
+
+			balance, err := txStockBalanceRepo.Get("material", item.MaterialID, ret.WarehouseID, item.WarehouseLocationID, item.BatchNumber, item.LotNumber)
+			if err != nil {
+				return fmt.Errorf("insufficient stock for material %d in specified batch/location", item.MaterialID)
+			}
+			if balance.AvailableQuantity < item.Quantity {
+				return fmt.Errorf("insufficient available stock for material %d batch %q", item.MaterialID, item.BatchNumber)
+			}
+
+			serials, err := movingSerials(tx, "material", item.MaterialID, ret.WarehouseID, item.WarehouseLocationID,
+				item.BatchNumber, item.LotNumber, item.Quantity, item.SerialNumbers, fmt.Sprintf("material %d", item.MaterialID))
+			if err != nil {
+				return err
+			}
+
+			prevBalance, err := txStockLedgerRepo.GetLatestBalance("material", item.MaterialID, ret.WarehouseID, item.WarehouseLocationID, item.BatchNumber, item.LotNumber)
+			if err != nil {
+				return err
+			}
+			ledgerEntry := &models.StockLedger{
+				TransactionType:     "purchase_return",
+				TransactionNumber:   ret.ReturnNumber,
+				TransactionDate:     now,
+				ItemType:            "material",
+				ItemID:              item.MaterialID,
+				WarehouseID:         ret.WarehouseID,
+				WarehouseLocationID: item.WarehouseLocationID,
+				BatchNumber:         item.BatchNumber,
+				LotNumber:           item.LotNumber,
+				ExpiryDate:          balance.ExpiryDate,
+				Quantity:            -item.Quantity,
+				UnitCost:            balance.UnitCost,
+				TotalCost:           -item.Quantity * balance.UnitCost,
+				BalanceQuantity:     prevBalance - item.Quantity,
+				ReferenceType:       "PurchaseReturn",
+				ReferenceID:         ret.ID,
+				CreatedBy:           &userID,
+			}
+			if err := txStockLedgerRepo.Create(ledgerEntry); err != nil {
+				return err
+			}
+			if err := postSerials(tx, ledgerEntry, serials, serialIssue); err != nil {
+				return err
+			}
+
+			balance.Quantity -= item.Quantity
+			balance.TotalCost = balance.Quantity * balance.UnitCost
+			balance.LastTransactionDate = &now
+			if err := tx.Save(balance).Error; err != nil {
+				return err
+			}
+
+			unitCost := balance.UnitCost
+			item.BaseUnitCost = &unitCost
+			item.SerialNumbers = serials
+			if err := txRepo.UpdateItem(item); err != nil {
+				return err
+			}
+
+			// Goods to be replaced are owed again on the PO
+			if item.Resolution == PurchaseReturnResolutionReplacement && item.POItemID != nil {
+				if err := tx.Model(&models.PurchaseOrderItem{}).Where("id = ?", *item.POItemID).
+					Update("received_quantity", gorm.Expr("GREATEST(received_quantity - ?, 0)", item.Quantity)).Error; err != nil {
+					return err
+				}
+				reopenPO = true
+			}
+		}
+
+		if reopenPO && ret.PurchaseOrderID != nil {
+			if err := refreshReceiptStatus(repository.NewPurchaseOrderRepository(tx), *ret.PurchaseOrderID, userID); err != nil {
+				return err
+			}
+		}
+
+		ret.Status = "posted"
+		ret.Posted = true
+		ret.PostedBy = &userID
+		ret.PostedAt = &now
+		ret.ExchangeRate = exchangeRate
+		ret.DebitNoteNumber = debitNoteNumber
+		ret.DebitNoteAmount = debitNoteAmount
+		ret.DebitNoteBaseAmount = toBase(debitNoteAmount, exchangeRate)
+		ret.ReplacementStatus = replacementStatus(ret.Items)
+		ret.UpdatedBy = &userID
+		return txRepo.Update(ret)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("purchase_returns", "POST", int64(id), int64(userID), username, nil, map[string]interface{}{
+		"return_number":      ret.ReturnNumber,
+		"debit_note_number":  ret.DebitNoteNumber,
+		"debit_note_amount":  ret.DebitNoteAmount,
+		"replacement_status": ret.ReplacementStatus,
+	})
+	return s.GetByID(id)
+}
+
+func (s *purchaseReturnService) Cancel(id uint, userID uint, username string) (*models.PurchaseReturn, error) {
+	ret, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if ret.Status != "draft" {
+		return nil, errors.New("only draft purchase returns can be cancelled")
+	}
+	ret.Status = "cancelled"
+	ret.UpdatedBy = &userID
+	if err := s.repo.Update(ret); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("purchase_returns", "CANCEL", int64(id), int64(userID), username,
+		map[string]interface{}{"status": "draft"}, map[string]interface{}{"status": "cancelled"})
+	return s.GetByID(id)
+}
+
+// purchaseReturnDebitNote totals the lines the supplier settles by credit, in the document currency
+func purchaseReturnDebitNote(items []*models.PurchaseReturnItem) float64 {
+	total := 0.0
+	for _, item := range items {
+		if item.Resolution == PurchaseReturnResolutionCredit {
+			total += item.Quantity * item.UnitCost
+		}
+	}
+	return roundMoney(total)
+}
+
+// replacementStatus summarises replacement lines: none, pending, partial or received
+func replacementStatus(items []*models.PurchaseReturnItem) string {
+	var owed, replaced float64
+	for _, item := range items {
+		if item.Resolution != PurchaseReturnResolutionReplacement {
+			continue
+		}
+		owed += item.Quantity
+		replaced += item.ReplacedQuantity
+	}
+	switch {
+	case owed == 0:
+		return "none"
+	case replaced <= 0:
+		return "pending"
+	case replaced < owed:
+		return "partial"
+	default:
+		return "received"
+	}
+}
+
+// allocateReplacement spreads a received quantity of a material over open replacement lines
+// (oldest first) and returns the changed lines
+func allocateReplacement(items []*models.PurchaseReturnItem, materialID uint, quantity float64) []*models.PurchaseReturnItem {
+	var changed []*models.PurchaseReturnItem
+	for _, item := range items {
+		if quantity <= 0 {
+			break
+		}
+		if item.Resolution != PurchaseReturnResolutionReplacement || item.MaterialID != materialID {
+			continue
+		}
+		open := item.Quantity - item.ReplacedQuantity
+		if open <= 0 {
+			continue
+		}
+		take := quantity
+		if take > open {
+			take = open
+		}
+		item.ReplacedQuantity += take
+		quantity -= take
+		changed = append(changed, item)
+	}
+	return changed
+}
+
+// validateReplacementReturn checks that a GRN may be recorded as a replacement delivery
+func validateReplacementReturn(ret *models.PurchaseReturn, purchaseOrderID uint) error {
+	if ret.Status != "posted" {
+		return fmt.Errorf("purchase return %s is not posted", ret.ReturnNumber)
+	}
+	if ret.ReplacementStatus != "pending" && ret.ReplacementStatus != "partial" {
+		return fmt.Errorf("purchase return %s has no outstanding replacement", ret.ReturnNumber)
+	}
+	if ret.PurchaseOrderID == nil || *ret.PurchaseOrderID != purchaseOrderID {
+		return fmt.Errorf("purchase return %s belongs to a different purchase order", ret.ReturnNumber)
+	}
+	return nil
+}
+
+// applyReplacementReceipt records accepted quantities of a posted replacement GRN against its purchase return
+func applyReplacementReceipt(tx *gorm.DB, returnID uint, grnItems []*models.GoodsReceiptNoteItem, userID uint) error {
+	txRepo := repository.NewPurchaseReturnRepository(tx)
+	ret, err := txRepo.GetByID(returnID)
+	if err != nil {
+		return err
+	}
+	for _, grnItem := range grnItems {
+		if grnItem.AcceptedQuantity <= 0 {
+			continue
+		}
+		for _, item := range allocateReplacement(ret.Items, grnItem.MaterialID, grnItem.AcceptedQuantity) {
+			if err := txRepo.UpdateItem(item); err != nil {
+				return err
+			}
+		}
+	}
+	ret.ReplacementStatus = replacementStatus(ret.Items)
+	ret.UpdatedBy = &userID
+	return txRepo.Update(ret)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurchaseReturnDebitNote(t *testing.T) {
+	items := []*models.PurchaseReturnItem{
+		{MaterialID: 1, Quantity: 10, UnitCost: 12.5, Resolution: PurchaseReturnResolutionCredit},
+		{MaterialID: 2, Quantity: 4, UnitCost: 100, Resolution: PurchaseReturnResolutionReplacement},
+		{MaterialID: 3, Quantity: 3, UnitCost: 0.333, Resolution: PurchaseReturnResolutionCredit},
+	}
+	// Replacement lines are not credited
+	assert.Equal(t, 126.0, purchaseReturnDebitNote(items))
+	assert.Equal(t, 0.0, purchaseReturnDebitNote(items[1:2]))
+}
+
+func TestReplacementTracking(t *testing.T) {
+	items := []*models.PurchaseReturnItem{
+		{MaterialID: 1, Quantity: 5, Resolution: PurchaseReturnResolutionCredit},
+		{MaterialID: 2, Quantity: 10, Resolution: PurchaseReturnResolutionReplacement},
+		{MaterialID: 2, Quantity: 5, Resolution: PurchaseReturnResolutionReplacement},
+	}
+	assert.Equal(t, "none", replacementStatus(items[:1]))
+	assert.Equal(t, "pending", replacementStatus(items))
+
+	// Credit lines and other materials are not touched
+	assert.Empty(t, allocateReplacement(items, 1, 5))
+	assert.Empty(t, allocateReplacement(items, 3, 5))
+
+	changed := allocateReplacement(items, 2, 12)
+	require.Len(t, changed, 2)
+	assert.Equal(t, 10.0, items[1].ReplacedQuantity)
+	assert.Equal(t, 2.0, items[2].ReplacedQuantity)
+	assert.Equal(t, "partial", replacementStatus(items))
+
+	// Over-delivery only fills what is still open
+	changed = allocateReplacement(items, 2, 10)
+	require.Len(t, changed, 1)
+	assert.Equal(t, 5.0, items[2].ReplacedQuantity)
+	assert.Equal(t, "received", replacementStatus(items))
+}
+
+func TestValidateReplacementReturn(t *testing.T) {
+	poID := uint(7)
+	ret := &models.PurchaseReturn{ReturnNumber: "PRT-2026-000001", Status: "posted", ReplacementStatus: "pending", PurchaseOrderID: &poID}
+	assert.NoError(t, validateReplacementReturn(ret, 7))
+	assert.Error(t, validateReplacementReturn(ret, 8))
+
+	ret.ReplacementStatus = "received"
+	assert.Error(t, validateReplacementReturn(ret, 7))
+
+	ret.ReplacementStatus = "partial"
+	ret.Status = "draft"
+	assert.Error(t, validateReplacementReturn(ret, 7))
+}
@@ -0,0 +1,458 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Putaway task statuses and sources
+const (
+	PutawayPending    = "pending"
+	PutawayInProgress = "in_progress"
+	PutawayCompleted  = "completed"
+	PutawayCancelled  = "cancelled"
+
+	PutawaySourceGRN  = "GRN"
+	PutawaySourceFPRN = "FPRN"
+
+	// LocationTypeReceiving marks the dock/staging area goods are received into before putaway
+	LocationTypeReceiving = "receiving"
+)
+
+// PutawayLine is one posted receipt line that may need to be put away
+type PutawayLine struct {
+	WarehouseID  uint
+	SourceType   string
+	SourceID     uint
+	SourceNumber string
+	SourceItemID uint
+	ItemType     string
+	ItemID       uint
+	BatchNumber  string
+	LotNumber    string
+	ExpiryDate   *string
+	Quantity     float64
+	LocationID   *uint // where the receipt put the stock
+}
+
+// PutawayService generates putaway tasks for posted receipts, suggests storage locations and
+// moves the stock once the putaway is confirmed
+type PutawayService interface {
+	// CreateTasks runs inside the posting transaction of the receipt
+	CreateTasks(tx *gorm.DB, lines []PutawayLine, userID uint) error
+	GetTask(id uint) (*models.PutawayTask, error)
+	ListTasks(filters map[string]interface{}, offset, limit int) ([]*models.PutawayTask, int64, error)
+	Suggest(id uint, limit int) ([]dto.PutawaySuggestion, error)
+	Confirm(id uint, req *dto.ConfirmPutawayRequest, userID uint, username string) (*models.PutawayTask, error)
+	Cancel(id uint, userID uint, username string) (*models.PutawayTask, error)
+}
+
+type putawayService struct {
+	db       *gorm.DB
+	repo     repository.PutawayTaskRepository
+	auditSvc AuditLogService
+}
+
+func NewPutawayService(db *gorm.DB, repo repository.PutawayTaskRepository, auditSvc AuditLogService) PutawayService {
+	return &putawayService{db: db, repo: repo, auditSvc: auditSvc}
+}
+
+// putawayLinesForGRN lists the accepted lines of a posted GRN
+func putawayLinesForGRN(grn *models.GoodsReceiptNote) []PutawayLine {
+	lines := make([]PutawayLine, 0, len(grn.Items))
+	for _, item := range grn.Items {
+		if item.AcceptedQuantity <= 0 {
+			continue
+		}
+		lines = append(lines, PutawayLine{
+			WarehouseID:  grn.WarehouseID,
+			SourceType:   PutawaySourceGRN,
+			SourceID:     grn.ID,
+			SourceNumber: grn.GRNNumber,
+			SourceItemID: item.ID,
+			ItemType:     "material",
+			ItemID:       item.MaterialID,
+			BatchNumber:  item.BatchNumber,
+			LotNumber:    item.LotNumber,
+			ExpiryDate:   item.ExpiryDate,
+			Quantity:     item.AcceptedQuantity,
+			LocationID:   item.WarehouseLocationID,
+		})
+	}
+	return lines
+}
+
+// putawayLinesForFPRN lists the received lines of a posted FPRN
+func putawayLinesForFPRN(fprn *models.FinishedProductReceipt) []PutawayLine {
+	lines := make([]PutawayLine, 0, len(fprn.Items))
+	for _, item := range fprn.Items {
+		if item.Quantity <= 0 {
+			continue
+		}
+		lines = append(lines, PutawayLine{
+			WarehouseID:  fprn.WarehouseID,
+			SourceType:   PutawaySourceFPRN,
+			SourceID:     fprn.ID,
+			SourceNumber: fprn.FPRNNumber,
+			SourceItemID: item.ID,
+			ItemType:     "finished_product",
+			ItemID:       item.FinishedProductID,
+			BatchNumber:  item.BatchNumber,
+			ExpiryDate:   item.ExpiryDate,
+			Quantity:     item.Quantity,
+			LocationID:   item.WarehouseLocationID,
+		})
+	}
+	return lines
+}
+
+// CreateTasks opens a task for every line received without a location or into a receiving
+// location; lines already posted to a storage location need no putaway
+func (s *putawayService) CreateTasks(tx *gorm.DB, lines []PutawayLine, userID uint) error {
+	txRepo := repository.NewPutawayTaskRepository(tx)
+	for _, line := range lines {
+		if line.LocationID != nil {
+			location, err := txRepo.GetLocation(*line.LocationID)
+			if err != nil {
+				return fmt.Errorf("location %d not found", *line.LocationID)
+			}
+			if location.LocationType != LocationTypeReceiving {
+				continue
+			}
+		}
+
+		number, err := generatePutawayNumber(txRepo)
+		if err != nil {
+			return err
+		}
+		sourceItemID := line.SourceItemID
+		task := &models.PutawayTask{
+			TaskNumber:     number,
+			WarehouseID:    line.WarehouseID,
+			SourceType:     line.SourceType,
+			SourceID:       line.SourceID,
+			SourceNumber:   line.SourceNumber,
+			SourceItemID:   &sourceItemID,
+			ItemType:       line.ItemType,
+			ItemID:         line.ItemID,
+			BatchNumber:    line.BatchNumber,
+			LotNumber:      line.LotNumber,
+			ExpiryDate:     line.ExpiryDate,
+			Quantity:       line.Quantity,
+			FromLocationID: line.LocationID,
+			Status:         PutawayPending,
+			CreatedBy:      &userID,
+		}
+		suggestions, err := suggestLocations(tx, task, 1)
+		if err != nil {
+			return err
+		}
+		if len(suggestions) > 0 {
+			task.SuggestedLocationID = &suggestions[0].LocationID
+		}
+		if err := txRepo.Create(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generatePutawayNumber creates a number like PA-2026-000001
+func generatePutawayNumber(repo repository.PutawayTaskRepository) (string, error) {
+	prefix := fmt.Sprintf("PA-%s-", time.Now().Format("2006"))
+	count, err := repo.CountByTaskNumber(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%06d", prefix, count+1), nil
+}
+
+func (s *putawayService) GetTask(id uint) (*models.PutawayTask, error) {
+	task, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("putaway task not found")
+	}
+	return task, nil
+}
+
+func (s *putawayService) ListTasks(filters map[string]interface{}, offset, limit int) ([]*models.PutawayTask, int64, error) {
+	return s.repo.List(filters, offset, limit)
+}
+
+// Suggest ranks the storage locations of the task's warehouse for the quantity still waiting
+func (s *putawayService) Suggest(id uint, limit int) ([]dto.PutawaySuggestion, error) {
+	task, err := s.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status == PutawayCompleted || task.Status == PutawayCancelled {
+		return nil, fmt.Errorf("putaway task is %s", task.Status)
+	}
+	return suggestLocations(s.db, task, limit)
+}
+
+func suggestLocations(db *gorm.DB, task *models.PutawayTask, limit int) ([]dto.PutawaySuggestion, error) {
+	repo := repository.NewPutawayTaskRepository(db)
+	locations, err := repo.ListStorageLocations(task.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	locations, err = filterStorableLocations(db, locations, task.ItemType, task.ItemID, task.RemainingQuantity())
+	if err != nil {
+		return nil, err
+	}
+	occupancy, err := repo.LocationOccupancy(task.WarehouseID, task.ItemType, task.ItemID, task.BatchNumber)
+	if err != nil {
+		return nil, err
+	}
+	hazardous, err := repo.IsHazardous(task.ItemType, task.ItemID)
+	if err != nil {
+		return nil, err
+	}
+	byLocation := make(map[uint]repository.LocationOccupancy, len(occupancy))
+	for _, o := range occupancy {
+		byLocation[o.LocationID] = o
+	}
+	ranked := rankPutawayLocations(locations, byLocation, hazardous, task.RemainingQuantity(), task.FromLocationID)
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
+
+// rankPutawayLocations applies the putaway rules. Hazardous goods only go to locations approved for
+// them and full locations are skipped; the rest is scored:
+//   - same item and batch already stored +50, same item (other batch) +30
+//   - plain storage location +10, empty location +5
+//   - hazardous goods in an approved location +15; other goods there -20 (keep it free)
+//   - not enough free capacity for the whole quantity -30 (the task can be split)
+//
+// Ties go to the location with the most free capacity, then by code.
+func rankPutawayLocations(locations []*models.WarehouseLocation, occupancy map[uint]repository.LocationOccupancy, hazardous bool, quantity float64, fromLocationID *uint) []dto.PutawaySuggestion {
+	suggestions := make([]dto.PutawaySuggestion, 0, len(locations))
+	for _, loc := range locations {
+		if fromLocationID != nil && loc.ID == *fromLocationID {
+			continue
+		}
+		if loc.LocationType == LocationTypeReceiving {
+			continue
+		}
+		if hazardous && !locationHazardousAllowed(loc) {
+			continue
+		}
+		occ := occupancy[loc.ID]
+
+		var free *float64
+		if loc.MaxQuantity != nil {
+			f := roundQty(*loc.MaxQuantity - occ.Quantity)
+			if f <= qtyEpsilon {
+				continue
+			}
+			free = &f
+		}
+
+		sg := dto.PutawaySuggestion{
+			LocationID:   loc.ID,
+			Code:         loc.Code,
+			Name:         loc.Name,
+			LocationType: loc.LocationType,
+			FreeCapacity: free,
+			Reasons:      []string{},
+		}
+		switch {
+		case occ.SameBatch:
+			sg.Score += 50
+			sg.Reasons = append(sg.Reasons, "same batch already stored")
+		case occ.ItemQuantity > qtyEpsilon:
+			sg.Score += 30
+			sg.Reasons = append(sg.Reasons, "same item already stored")
+		}
+		if loc.LocationType == "" || loc.LocationType == "storage" {
+			sg.Score += 10
+			sg.Reasons = append(sg.Reasons, "storage location")
+		}
+		if occ.Quantity <= qtyEpsilon {
+			sg.Score += 5
+			sg.Reasons = append(sg.Reasons, "empty location")
+		}
+		if locationHazardousAllowed(loc) {
+			if hazardous {
+				sg.Score += 15
+				sg.Reasons = append(sg.Reasons, "approved for hazardous goods")
+			} else {
+				sg.Score -= 20
+				sg.Reasons = append(sg.Reasons, "reserved for hazardous goods")
+			}
+		}
+		if free != nil && *free < quantity-qtyEpsilon {
+			sg.Score -= 30
+			sg.Reasons = append(sg.Reasons, fmt.Sprintf("only %s free, split required", formatQty(*free)))
+		}
+		suggestions = append(suggestions, sg)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if (a.FreeCapacity == nil) != (b.FreeCapacity == nil) {
+			return a.FreeCapacity == nil
+		}
+		if a.FreeCapacity != nil && *a.FreeCapacity != *b.FreeCapacity {
+			return *a.FreeCapacity > *b.FreeCapacity
+		}
+		return a.Code < b.Code
+	})
+	return suggestions
+}
+
+// Confirm moves the confirmed quantity from the task's source location to the chosen location:
+// one PUTAWAY ledger entry out of the source and one into the target, with the balance cost carried over
+func (s *putawayService) Confirm(id uint, req *dto.ConfirmPutawayRequest, userID uint, username string) (*models.PutawayTask, error) {
+	task, err := s.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != PutawayPending && task.Status != PutawayInProgress {
+		return nil, fmt.Errorf("putaway task is %s", task.Status)
+	}
+
+	remaining := roundQty(task.RemainingQuantity())
+	quantity := remaining
+	if req.Quantity != nil {
+		quantity = roundQty(*req.Quantity)
+	}
+	if quantity > remaining+qtyEpsilon {
+		return nil, fmt.Errorf("quantity %s exceeds the %s still to put away", formatQty(quantity), formatQty(remaining))
+	}
+
+	location, err := s.repo.GetLocation(req.LocationID)
+	if err != nil {
+		return nil, errors.New("location not found")
+	}
+	if err := s.checkTarget(task, location); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewPutawayTaskRepository(tx)
+
+		if err := checkWarehouseNotFrozen(tx, task.WarehouseID); err != nil {
+			return err
+		}
+
+		if err := checkStoragePlacements(tx, []StoragePlacement{{LocationID: location.ID, ItemType: task.ItemType, ItemID: task.ItemID, Quantity: quantity}}); err != nil {
+			return err
+		}
+
+		allocated, err := moveAllocations(tx, task.WarehouseID, task.ItemType)
+		if err != nil {
+			return err
+		}
+		serials, err := movingSerials(tx, task.ItemType, task.ItemID, task.WarehouseID, task.FromLocationID,
+			task.BatchNumber, task.LotNumber, quantity, req.SerialNumbers, task.TaskNumber)
+		if err != nil {
+			return err
+		}
+		if _, err := moveStock(tx, stockPosting{
+			ItemType:    task.ItemType,
+			ItemID:      task.ItemID,
+			BatchNumber: task.BatchNumber,
+			LotNumber:   task.LotNumber,
+			WarehouseID: task.WarehouseID,
+			LocationID:  task.FromLocationID,
+			Quantity:    quantity,
+			TxType:      "PUTAWAY",
+			TxNumber:    task.TaskNumber,
+			RefType:     "PutawayTask",
+			RefID:       task.ID,
+			Notes:       req.Notes,
+			Allocated:   allocated,
+			Serials:     serials,
+		}, task.WarehouseID, &location.ID, "", userID, now); err != nil {
+			return err
+		}
+
+		if err := txRepo.CreateConfirmation(&models.PutawayConfirmation{
+			TaskID:        task.ID,
+			ToLocationID:  location.ID,
+			Quantity:      quantity,
+			SerialNumbers: serials,
+			ConfirmedBy:   &userID,
+		}); err != nil {
+			return err
+		}
+
+		task.PutawayQuantity = roundQty(task.PutawayQuantity + quantity)
+		task.Status = PutawayInProgress
+		if task.RemainingQuantity() <= qtyEpsilon {
+			task.Status = PutawayCompleted
+			task.CompletedBy = &userID
+			task.CompletedAt = &now
+		}
+		return txRepo.Update(task)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("putaway_tasks", "PUTAWAY", int64(task.ID), int64(userID), username, nil, map[string]interface{}{
+		"task_number": task.TaskNumber,
+		"location":    location.Code,
+		"quantity":    quantity,
+		"status":      task.Status,
+	})
+	return s.repo.GetByID(task.ID)
+}
+
+// checkTarget validates the chosen location; storage constraints are checked when the stock moves
+func (s *putawayService) checkTarget(task *models.PutawayTask, location *models.WarehouseLocation) error {
+	if location.WarehouseID != task.WarehouseID {
+		return errors.New("location belongs to another warehouse")
+	}
+	if location.IsActive != nil && !*location.IsActive {
+		return fmt.Errorf("location %s is inactive", location.Code)
+	}
+	if task.FromLocationID != nil && *task.FromLocationID == location.ID {
+		return errors.New("stock is already in this location")
+	}
+	if location.LocationType == LocationTypeReceiving {
+		return fmt.Errorf("location %s is a receiving area", location.Code)
+	}
+	if location.LocationType == LocationTypeInTransit {
+		return fmt.Errorf("location %s is an in-transit location", location.Code)
+	}
+	return nil
+}
+
+// Cancel closes a task without moving the remaining stock; it stays at the source location
+func (s *putawayService) Cancel(id uint, userID uint, username string) (*models.PutawayTask, error) {
+	task, err := s.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != PutawayPending && task.Status != PutawayInProgress {
+		return nil, fmt.Errorf("putaway task is %s", task.Status)
+	}
+	now := time.Now()
+	task.Status = PutawayCancelled
+	task.CompletedBy = &userID
+	task.CompletedAt = &now
+	if err := s.repo.Update(task); err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("putaway_tasks", "CANCEL", int64(task.ID), int64(userID), username, nil, map[string]interface{}{
+		"task_number":      task.TaskNumber,
+		"putaway_quantity": task.PutawayQuantity,
+	})
+	return task, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func putawayCodes(locations []*models.WarehouseLocation, occupancy map[uint]repository.LocationOccupancy, hazardous bool, qty float64, from *uint) []string {
+	var codes []string
+	for _, s := range rankPutawayLocations(locations, occupancy, hazardous, qty, from) {
+		codes = append(codes, s.Code)
+	}
+	return codes
+}
+
+func TestRankPutawayLocations(t *testing.T) {
+	small := 40.0
+	locations := []*models.WarehouseLocation{
+		{ID: 1, Code: "RCV-01", LocationType: LocationTypeReceiving},
+		{ID: 2, Code: "A-01", LocationType: "storage"},
+		{ID: 3, Code: "A-02", LocationType: "storage"},
+		{ID: 4, Code: "B-01", LocationType: "storage", MaxQuantity: &small},
+		{ID: 5, Code: "HZ-01", LocationType: "storage", HazardousAllowed: true},
+	}
+	from := uint(1)
+
+	// Same batch beats same item beats empty; the receiving area and hazardous store come last or not at all
+	occupancy := map[uint]repository.LocationOccupancy{
+		2: {LocationID: 2, Quantity: 30, ItemQuantity: 30},
+		3: {LocationID: 3, Quantity: 10, ItemQuantity: 10, SameBatch: true},
+	}
+	assert.Equal(t, []string{"A-02", "A-01", "HZ-01", "B-01"}, putawayCodes(locations, occupancy, false, 50, &from))
+
+	// Hazardous goods only go to approved locations
+	assert.Equal(t, []string{"HZ-01"}, putawayCodes(locations, occupancy, true, 50, &from))
+
+	// A full location is skipped; a location that fits the whole quantity is not penalised
+	occupancy[4] = repository.LocationOccupancy{LocationID: 4, Quantity: 40}
+	assert.NotContains(t, putawayCodes(locations, occupancy, false, 10, &from), "B-01")
+	delete(occupancy, 4)
+	ranked := rankPutawayLocations(locations, occupancy, false, 10, &from)
+	for _, s := range ranked {
+		if s.Code == "B-01" {
+			assert.Equal(t, 15, s.Score)
+			assert.Equal(t, 40.0, *s.FreeCapacity)
+		}
+	}
+}
+
+func TestPutawayLinesForGRN(t *testing.T) {
+	loc := uint(7)
+	grn := &models.GoodsReceiptNote{ID: 3, GRNNumber: "GRN-001", WarehouseID: 2, Items: []*models.GoodsReceiptNoteItem{
+		{ID: 10, MaterialID: 5, AcceptedQuantity: 80, BatchNumber: "B1", WarehouseLocationID: &loc},
+		{ID: 11, MaterialID: 6, AcceptedQuantity: 0},
+	}}
+	lines := putawayLinesForGRN(grn)
+	if assert.Len(t, lines, 1) {
+		assert.Equal(t, PutawayLine{
+			WarehouseID: 2, SourceType: PutawaySourceGRN, SourceID: 3, SourceNumber: "GRN-001", SourceItemID: 10,
+			ItemType: "material", ItemID: 5, BatchNumber: "B1", Quantity: 80, LocationID: &loc,
+		}, lines[0])
+	}
+}
@@ -0,0 +1,741 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// QC sampling rules, result values and inspection statuses
+const (
+	QCSamplingFixed   = "fixed"
+	QCSamplingPercent = "percent"
+	QCSamplingSqrt    = "sqrt"
+
+	QCResultTypeNumeric = "numeric"
+	QCResultTypeText    = "text"
+
+	QCResultPending = "pending"
+	QCResultPass    = "pass"
+	QCResultFail    = "fail"
+
+	QCInspectionPending   = "pending"
+	QCInspectionCompleted = "completed"
+)
+
+// QCInspectionService manages incoming inspection templates, batch inspections, retained samples and COAs
+type QCInspectionService interface {
+	CreateTemplate(req *dto.CreateQCTemplateRequest, userID uint, username string) (*models.QCTemplate, error)
+	UpdateTemplate(id uint, req *dto.UpdateQCTemplateRequest, userID uint, username string) (*models.QCTemplate, error)
+	GetTemplate(id uint) (*models.QCTemplate, error)
+	ListTemplates(filters map[string]interface{}, offset, limit int) ([]*models.QCTemplate, int64, error)
+
+	CreateInspection(req *dto.CreateQCInspectionRequest, userID uint, username string) (*models.QCInspection, error)
+	GetInspection(id uint) (*models.QCInspection, error)
+	ListInspections(filters map[string]interface{}, offset, limit int) ([]*models.QCInspection, int64, error)
+	RecordResults(id uint, req *dto.RecordQCResultsRequest, userID uint, username string) (*models.QCInspection, error)
+	GetCOA(id uint) (*dto.CertificateOfAnalysis, error)
+
+	CreateRetainedSample(inspectionID uint, req *dto.CreateRetainedSampleRequest, userID uint, username string) (*models.QCRetainedSample, error)
+	ListRetainedSamples(filters map[string]interface{}, offset, limit int) ([]*models.QCRetainedSample, int64, error)
+	DisposeRetainedSample(id uint, req *dto.DisposeRetainedSampleRequest, userID uint, username string) (*models.QCRetainedSample, error)
+}
+
+type qcInspectionService struct {
+	db           *gorm.DB
+	repo         repository.QCInspectionRepository
+	grnRepo      repository.GoodsReceiptNoteRepository
+	grnItemRepo  repository.GoodsReceiptNoteItemRepository
+	materialRepo repository.MaterialRepository
+	auditSvc     AuditLogService
+}
+
+func NewQCInspectionService(
+	db *gorm.DB,
+	repo repository.QCInspectionRepository,
+	grnRepo repository.GoodsReceiptNoteRepository,
+	grnItemRepo repository.GoodsReceiptNoteItemRepository,
+	materialRepo repository.MaterialRepository,
+	auditSvc AuditLogService,
+) QCInspectionService {
+	return &qcInspectionService{
+		db:           db,
+		repo:         repo,
+		grnRepo:      grnRepo,
+		grnItemRepo:  grnItemRepo,
+		materialRepo: materialRepo,
+		auditSvc:     auditSvc,
+	}
+}
+
+func (s *qcInspectionService) CreateTemplate(req *dto.CreateQCTemplateRequest, userID uint, username string) (*models.QCTemplate, error) {
+	if _, err := s.materialRepo.GetByID(int64(req.MaterialID)); err != nil {
+		return nil, errors.New("material not found")
+	}
+	tests, err := buildTemplateTests(req.Tests)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &models.QCTemplate{
+		Code:             req.Code,
+		Name:             req.Name,
+		MaterialID:       req.MaterialID,
+		SamplingRule:     req.SamplingRule,
+		SampleValue:      req.SampleValue,
+		MinSample:        req.MinSample,
+		MaxSample:        req.MaxSample,
+		RetainedQuantity: req.RetainedQuantity,
+		RetentionDays:    req.RetentionDays,
+		IsActive:         req.IsActive == nil || *req.IsActive,
+		Notes:            req.Notes,
+		Tests:            tests,
+		CreatedBy:        &userID,
+		UpdatedBy:        &userID,
+	}
+	if err := validateSamplingRule(t); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewQCInspectionRepository(tx)
+		version, err := txRepo.MaxTemplateVersion(t.MaterialID)
+		if err != nil {
+			return err
+		}
+		t.Version = version + 1
+		if t.IsActive {
+			// The new version replaces the material's current plan
+			if err := txRepo.DeactivateTemplates(t.MaterialID, 0); err != nil {
+				return err
+			}
+		}
+		return txRepo.CreateTemplate(t)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("qc_templates", "CREATE", int64(t.ID), int64(userID), username, nil, map[string]interface{}{
+		"code":          t.Code,
+		"material_id":   t.MaterialID,
+		"version":       t.Version,
+		"sampling_rule": t.SamplingRule,
+		"tests_count":   len(t.Tests),
+	})
+	return s.repo.GetTemplateByID(t.ID)
+}
+
+func (s *qcInspectionService) UpdateTemplate(id uint, req *dto.UpdateQCTemplateRequest, userID uint, username string) (*models.QCTemplate, error) {
+	t, err := s.repo.GetTemplateByID(id)
+	if err != nil {
+		return nil, errors.New("QC template not found")
+	}
+	old := map[string]interface{}{
+		"name":          t.Name,
+		"sampling_rule": t.SamplingRule,
+		"sample_value":  t.SampleValue,
+		"is_active":     t.IsActive,
+		"tests_count":   len(t.Tests),
+	}
+
+	if req.Name != nil {
+		t.Name = *req.Name
+	}
+	if req.SamplingRule != nil {
+		t.SamplingRule = *req.SamplingRule
+	}
+	if req.SampleValue != nil {
+		t.SampleValue = *req.SampleValue
+	}
+	if req.MinSample != nil {
+		t.MinSample = req.MinSample
+	}
+	if req.MaxSample != nil {
+		t.MaxSample = req.MaxSample
+	}
+	if req.RetainedQuantity != nil {
+		t.RetainedQuantity = *req.RetainedQuantity
+	}
+	if req.RetentionDays != nil {
+		t.RetentionDays = *req.RetentionDays
+	}
+	if req.IsActive != nil {
+		t.IsActive = *req.IsActive
+	}
+	if req.Notes != nil {
+		t.Notes = *req.Notes
+	}
+	if err := validateSamplingRule(t); err != nil {
+		return nil, err
+	}
+	var tests []*models.QCTemplateTest
+	if req.Tests != nil {
+		if tests, err = buildTemplateTests(*req.Tests); err != nil {
+			return nil, err
+		}
+	}
+	t.UpdatedBy = &userID
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewQCInspectionRepository(tx)
+		if t.IsActive {
+			if err := txRepo.DeactivateTemplates(t.MaterialID, t.ID); err != nil {
+				return err
+			}
+		}
+		if err := txRepo.UpdateTemplate(t); err != nil {
+			return err
+		}
+		if tests != nil {
+			return txRepo.ReplaceTemplateTests(t.ID, tests)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t, err = s.repo.GetTemplateByID(id)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("qc_templates", "UPDATE", int64(id), int64(userID), username, old, map[string]interface{}{
+		"name":          t.Name,
+		"sampling_rule": t.SamplingRule,
+		"sample_value":  t.SampleValue,
+		"is_active":     t.IsActive,
+		"tests_count":   len(t.Tests),
+	})
+	return t, nil
+}
+
+func (s *qcInspectionService) GetTemplate(id uint) (*models.QCTemplate, error) {
+	t, err := s.repo.GetTemplateByID(id)
+	if err != nil {
+		return nil, errors.New("QC template not found")
+	}
+	return t, nil
+}
+
+func (s *qcInspectionService) ListTemplates(filters map[string]interface{}, offset, limit int) ([]*models.QCTemplate, int64, error) {
+	return s.repo.ListTemplates(filters, offset, limit)
+}
+
+// generateInspectionNumber creates a number like QCI-2026-000001
+func (s *qcInspectionService) generateInspectionNumber() (string, error) {
+	prefix := fmt.Sprintf("QCI-%s-", time.Now().Format("2006"))
+	count, err := s.repo.CountByInspectionNumber(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%06d", prefix, count+1), nil
+}
+
+// CreateInspection opens an inspection for a GRN line with the material's active template
+// and computes the sample size from its sampling rule
+func (s *qcInspectionService) CreateInspection(req *dto.CreateQCInspectionRequest, userID uint, username string) (*models.QCInspection, error) {
+	item, err := s.grnItemRepo.GetByID(req.GRNItemID)
+	if err != nil {
+		return nil, errors.New("GRN item not found")
+	}
+	grn, err := s.grnRepo.GetByID(item.GRNID)
+	if err != nil {
+		return nil, errors.New("GRN not found")
+	}
+	if grn.Posted {
+		return nil, errors.New("cannot inspect a posted GRN")
+	}
+	template, err := s.repo.GetActiveTemplate(item.MaterialID)
+	if err != nil {
+		return nil, errors.New("no active QC template for this material")
+	}
+
+	sampleSize, sampleUnit, err := qcSampleSize(template, item.Quantity, req.ContainerCount)
+	if err != nil {
+		return nil, err
+	}
+	if sampleUnit == "" && item.Material != nil {
+		sampleUnit = item.Material.Unit
+	}
+
+	inspection := &models.QCInspection{
+		GRNID:            grn.ID,
+		GRNItemID:        item.ID,
+		MaterialID:       item.MaterialID,
+		TemplateID:       template.ID,
+		BatchNumber:      item.BatchNumber,
+		LotNumber:        item.LotNumber,
+		ReceivedQuantity: item.Quantity,
+		ContainerCount:   req.ContainerCount,
+		SampleSize:       sampleSize,
+		SampleUnit:       sampleUnit,
+		Status:           QCInspectionPending,
+		Notes:            req.Notes,
+		CreatedBy:        &userID,
+		UpdatedBy:        &userID,
+	}
+	for _, test := range template.Tests {
+		testID := test.ID
+		inspection.Results = append(inspection.Results, &models.QCInspectionResult{
+			TemplateTestID: &testID,
+			TestCode:       test.TestCode,
+			TestName:       test.TestName,
+			Category:       test.Category,
+			ResultType:     test.ResultType,
+			Unit:           test.Unit,
+			MinValue:       test.MinValue,
+			MaxValue:       test.MaxValue,
+			ExpectedText:   test.ExpectedText,
+			Method:         test.Method,
+			IsRequired:     test.IsRequired,
+			SortOrder:      test.SortOrder,
+			Result:         QCResultPending,
+		})
+	}
+
+	number, err := s.generateInspectionNumber()
+	if err != nil {
+		return nil, err
+	}
+	inspection.InspectionNumber = number
+	if err := s.repo.CreateInspection(inspection); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("qc_inspections", "CREATE", int64(inspection.ID), int64(userID), username, nil, map[string]interface{}{
+		"inspection_number": inspection.InspectionNumber,
+		"grn_id":            inspection.GRNID,
+		"grn_item_id":       inspection.GRNItemID,
+		"batch_number":      inspection.BatchNumber,
+		"template_id":       inspection.TemplateID,
+		"sample_size":       inspection.SampleSize,
+	})
+	return s.repo.GetInspectionByID(inspection.ID)
+}
+
+func (s *qcInspectionService) GetInspection(id uint) (*models.QCInspection, error) {
+	inspection, err := s.repo.GetInspectionByID(id)
+	if err != nil {
+		return nil, errors.New("QC inspection not found")
+	}
+	return inspection, nil
+}
+
+func (s *qcInspectionService) ListInspections(filters map[string]interface{}, offset, limit int) ([]*models.QCInspection, int64, error) {
+	return s.repo.ListInspections(filters, offset, limit)
+}
+
+// RecordResults stores measured values and evaluates each against its spec. Results can be
+// corrected until the GRN is posted; the verdict is recomputed every time.
+func (s *qcInspectionService) RecordResults(id uint, req *dto.RecordQCResultsRequest, userID uint, username string) (*models.QCInspection, error) {
+	inspection, err := s.repo.GetInspectionByID(id)
+	if err != nil {
+		return nil, errors.New("QC inspection not found")
+	}
+	if inspection.GRN != nil && inspection.GRN.Posted {
+		return nil, errors.New("cannot change results after the GRN is posted")
+	}
+
+	results := make(map[uint]*models.QCInspectionResult, len(inspection.Results))
+	for _, r := range inspection.Results {
+		results[r.ID] = r
+	}
+	changed := make([]*models.QCInspectionResult, 0, len(req.Results))
+	for _, entry := range req.Results {
+		r, ok := results[entry.ResultID]
+		if !ok {
+			return nil, fmt.Errorf("result %d does not belong to inspection %s", entry.ResultID, inspection.InspectionNumber)
+		}
+		switch r.ResultType {
+		case QCResultTypeNumeric:
+			if entry.NumericValue == nil {
+				return nil, fmt.Errorf("%s: numeric_value is required", r.TestCode)
+			}
+			r.NumericValue = entry.NumericValue
+		default:
+			if entry.TextValue == nil || strings.TrimSpace(*entry.TextValue) == "" {
+				return nil, fmt.Errorf("%s: text_value is required", r.TestCode)
+			}
+			r.TextValue = entry.TextValue
+		}
+		if entry.Notes != "" {
+			r.Notes = entry.Notes
+		}
+		r.Result = evaluateQCResult(r)
+		changed = append(changed, r)
+	}
+
+	oldStatus, oldResult := inspection.Status, inspection.Result
+	status, result := qcInspectionOutcome(inspection.Results)
+	inspection.Status = status
+	inspection.Result = result
+	if status == QCInspectionCompleted {
+		now := time.Now()
+		inspection.InspectedBy = &userID
+		inspection.InspectedAt = &now
+	} else {
+		inspection.InspectedBy = nil
+		inspection.InspectedAt = nil
+	}
+	if req.Notes != nil {
+		inspection.Notes = *req.Notes
+	}
+	inspection.UpdatedBy = &userID
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewQCInspectionRepository(tx)
+		for _, r := range changed {
+			if err := txRepo.UpdateResult(r); err != nil {
+				return err
+			}
+		}
+		return txRepo.UpdateInspection(inspection)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("qc_inspections", "RECORD_RESULTS", int64(id), int64(userID), username,
+		map[string]interface{}{"status": oldStatus, "result": oldResult},
+		map[string]interface{}{"status": status, "result": result, "results_count": len(changed)})
+	return s.repo.GetInspectionByID(id)
+}
+
+// GetCOA builds the certificate of analysis for a completed inspection
+func (s *qcInspectionService) GetCOA(id uint) (*dto.CertificateOfAnalysis, error) {
+	inspection, err := s.repo.GetInspectionByID(id)
+	if err != nil {
+		return nil, errors.New("QC inspection not found")
+	}
+	if inspection.Status != QCInspectionCompleted {
+		return nil, errors.New("COA is only available for completed inspections")
+	}
+	return buildCOA(inspection), nil
+}
+
+func (s *qcInspectionService) CreateRetainedSample(inspectionID uint, req *dto.CreateRetainedSampleRequest, userID uint, username string) (*models.QCRetainedSample, error) {
+	inspection, err := s.repo.GetInspectionByID(inspectionID)
+	if err != nil {
+		return nil, errors.New("QC inspection not found")
+	}
+
+	retainedAt := time.Now()
+	if req.RetainedAt != "" {
+		if retainedAt, err = time.Parse("2006-01-02", req.RetainedAt); err != nil {
+			return nil, errors.New("retained_at must be YYYY-MM-DD")
+		}
+	}
+	quantity := req.Quantity
+	if quantity <= 0 && inspection.Template != nil {
+		quantity = inspection.Template.RetainedQuantity
+	}
+	if quantity <= 0 {
+		return nil, errors.New("quantity is required when the template has no retained quantity")
+	}
+
+	sample := &models.QCRetainedSample{
+		InspectionID:    inspection.ID,
+		MaterialID:      inspection.MaterialID,
+		BatchNumber:     inspection.BatchNumber,
+		LotNumber:       inspection.LotNumber,
+		Quantity:        quantity,
+		StorageLocation: req.StorageLocation,
+		RetainedAt:      retainedAt.Format("2006-01-02"),
+		Status:          "retained",
+		Notes:           req.Notes,
+		CreatedBy:       &userID,
+	}
+	if req.RetainUntil != "" {
+		if _, err := time.Parse("2006-01-02", req.RetainUntil); err != nil {
+			return nil, errors.New("retain_until must be YYYY-MM-DD")
+		}
+		sample.RetainUntil = &req.RetainUntil
+	} else if inspection.Template != nil {
+		var expiry *string
+		if inspection.GRNItem != nil {
+			expiry = inspection.GRNItem.ExpiryDate
+		}
+		sample.RetainUntil = retainUntil(retainedAt, expiry, inspection.Template.RetentionDays)
+	}
+
+	if err := s.repo.CreateRetainedSample(sample); err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("qc_retained_samples", "CREATE", int64(sample.ID), int64(userID), username, nil, map[string]interface{}{
+		"inspection_id": sample.InspectionID,
+		"batch_number":  sample.BatchNumber,
+		"quantity":      sample.Quantity,
+		"retain_until":  sample.RetainUntil,
+	})
+	return s.repo.GetRetainedSampleByID(sample.ID)
+}
+
+func (s *qcInspectionService) ListRetainedSamples(filters map[string]interface{}, offset, limit int) ([]*models.QCRetainedSample, int64, error) {
+	return s.repo.ListRetainedSamples(filters, offset, limit)
+}
+
+func (s *qcInspectionService) DisposeRetainedSample(id uint, req *dto.DisposeRetainedSampleRequest, userID uint, username string) (*models.QCRetainedSample, error) {
+	sample, err := s.repo.GetRetainedSampleByID(id)
+	if err != nil {
+		return nil, errors.New("retained sample not found")
+	}
+	if sample.Status != "retained" {
+		return nil, errors.New("retained sample is already disposed")
+	}
+	now := time.Now()
+	sample.Status = "disposed"
+	sample.DisposedAt = &now
+	sample.DisposedBy = &userID
+	sample.DisposalNotes = req.Notes
+	if err := s.repo.UpdateRetainedSample(sample); err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("qc_retained_samples", "DISPOSE", int64(id), int64(userID), username,
+		map[string]interface{}{"status": "retained"},
+		map[string]interface{}{"status": "disposed", "notes": req.Notes})
+	return sample, nil
+}
+
+func buildTemplateTests(reqs []dto.QCTemplateTestRequest) ([]*models.QCTemplateTest, error) {
+	seen := make(map[string]bool, len(reqs))
+	tests := make([]*models.QCTemplateTest, 0, len(reqs))
+	for i, r := range reqs {
+		code := strings.TrimSpace(r.TestCode)
+		if seen[code] {
+			return nil, fmt.Errorf("duplicate test code %s", code)
+		}
+		seen[code] = true
+		if r.ResultType == QCResultTypeNumeric {
+			if r.MinValue == nil && r.MaxValue == nil {
+				return nil, fmt.Errorf("%s: numeric tests need min_value and/or max_value", code)
+			}
+			if r.MinValue != nil && r.MaxValue != nil && *r.MinValue > *r.MaxValue {
+				return nil, fmt.Errorf("%s: min_value is greater than max_value", code)
+			}
+		}
+		sortOrder := r.SortOrder
+		if sortOrder == 0 {
+			sortOrder = i + 1
+		}
+		tests = append(tests, &models.QCTemplateTest{
+			TestCode:     code,
+			TestName:     r.TestName,
+			Category:     r.Category,
+			ResultType:   r.ResultType,
+			Unit:         r.Unit,
+			MinValue:     r.MinValue,
+			MaxValue:     r.MaxValue,
+			ExpectedText: r.ExpectedText,
+			Method:       r.Method,
+			IsRequired:   r.IsRequired == nil || *r.IsRequired,
+			SortOrder:    sortOrder,
+		})
+	}
+	return tests, nil
+}
+
+func validateSamplingRule(t *models.QCTemplate) error {
+	switch t.SamplingRule {
+	case QCSamplingFixed:
+		if t.SampleValue <= 0 {
+			return errors.New("sample_value must be positive for fixed sampling")
+		}
+	case QCSamplingPercent:
+		if t.SampleValue <= 0 || t.SampleValue > 100 {
+			return errors.New("sample_value must be a percentage between 0 and 100")
+		}
+	case QCSamplingSqrt:
+	default:
+		return fmt.Errorf("unsupported sampling rule %q", t.SamplingRule)
+	}
+	if t.MinSample != nil && t.MaxSample != nil && *t.MinSample > *t.MaxSample {
+		return errors.New("min_sample is greater than max_sample")
+	}
+	return nil
+}
+
+// qcSampleSize applies the template sampling rule. The sqrt rule samples sqrt(N)+1 of N
+// containers (rounded up, capped at N) and is expressed in containers; the others in the material unit.
+func qcSampleSize(t *models.QCTemplate, receivedQty float64, containerCount *int) (float64, string, error) {
+	var size float64
+	unit := ""
+	switch t.SamplingRule {
+	case QCSamplingFixed:
+		size = t.SampleValue
+	case QCSamplingPercent:
+		size = receivedQty * t.SampleValue / 100
+	case QCSamplingSqrt:
+		if containerCount == nil || *containerCount <= 0 {
+			return 0, "", errors.New("container_count is required by the sqrt(N)+1 sampling rule")
+		}
+		n := float64(*containerCount)
+		size = math.Min(math.Ceil(math.Sqrt(n))+1, n)
+		unit = "containers"
+	default:
+		return 0, "", fmt.Errorf("unsupported sampling rule %q", t.SamplingRule)
+	}
+	if t.MinSample != nil && size < *t.MinSample {
+		size = *t.MinSample
+	}
+	if t.MaxSample != nil && size > *t.MaxSample {
+		size = *t.MaxSample
+	}
+	if unit == "" && size > receivedQty {
+		size = receivedQty
+	}
+	return roundQty(size), unit, nil
+}
+
+// evaluateQCResult checks a recorded value against the spec copied from the template.
+// Bounds are inclusive; text results match case-insensitively, and a text test without
+// an expected value passes once observed.
+func evaluateQCResult(r *models.QCInspectionResult) string {
+	if r.ResultType == QCResultTypeNumeric {
+		if r.NumericValue == nil {
+			return QCResultPending
+		}
+		v := *r.NumericValue
+		if (r.MinValue != nil && v < *r.MinValue) || (r.MaxValue != nil && v > *r.MaxValue) {
+			return QCResultFail
+		}
+		return QCResultPass
+	}
+	if r.TextValue == nil || strings.TrimSpace(*r.TextValue) == "" {
+		return QCResultPending
+	}
+	if r.ExpectedText != "" && !strings.EqualFold(strings.TrimSpace(*r.TextValue), strings.TrimSpace(r.ExpectedText)) {
+		return QCResultFail
+	}
+	return QCResultPass
+}
+
+// qcInspectionOutcome completes the inspection once every required test has a result.
+// Any failed required test fails the batch; optional tests are informational.
+func qcInspectionOutcome(results []*models.QCInspectionResult) (status string, result string) {
+	failed := false
+	for _, r := range results {
+		if !r.IsRequired {
+			continue
+		}
+		switch r.Result {
+		case QCResultPending:
+			return QCInspectionPending, ""
+		case QCResultFail:
+			failed = true
+		}
+	}
+	if failed {
+		return QCInspectionCompleted, QCResultFail
+	}
+	return QCInspectionCompleted, QCResultPass
+}
+
+// retainUntil keeps a sample retentionDays past the batch expiry, or past retainedAt when the
+// batch has no expiry date (nil when the template sets no retention)
+func retainUntil(retainedAt time.Time, expiryDate *string, retentionDays int) *string {
+	if retentionDays == 0 {
+		return nil
+	}
+	base := retainedAt
+	if expiryDate != nil && *expiryDate != "" {
+		if expiry, err := time.Parse("2006-01-02", firstN(*expiryDate, 10)); err == nil {
+			base = expiry
+		}
+	}
+	until := base.AddDate(0, 0, retentionDays).Format("2006-01-02")
+	return &until
+}
+
+// qcSpecification renders a test spec for the COA, e.g. "5.5 – 7", "≤ 100", "Conforms"
+func qcSpecification(r *models.QCInspectionResult) string {
+	if r.ResultType != QCResultTypeNumeric {
+		if r.ExpectedText == "" {
+			return "Report"
+		}
+		return r.ExpectedText
+	}
+	switch {
+	case r.MinValue != nil && r.MaxValue != nil:
+		return fmt.Sprintf("%g – %g", *r.MinValue, *r.MaxValue)
+	case r.MinValue != nil:
+		return fmt.Sprintf("≥ %g", *r.MinValue)
+	case r.MaxValue != nil:
+		return fmt.Sprintf("≤ %g", *r.MaxValue)
+	}
+	return "Report"
+}
+
+func buildCOA(inspection *models.QCInspection) *dto.CertificateOfAnalysis {
+	coa := &dto.CertificateOfAnalysis{
+		InspectionID:     inspection.ID,
+		InspectionNumber: inspection.InspectionNumber,
+		BatchNumber:      inspection.BatchNumber,
+		LotNumber:        inspection.LotNumber,
+		ReceivedQuantity: inspection.ReceivedQuantity,
+		SampleSize:       inspection.SampleSize,
+		SampleUnit:       inspection.SampleUnit,
+		Result:           inspection.Result,
+		InspectedAt:      inspection.InspectedAt,
+		Notes:            inspection.Notes,
+		Tests:            make([]dto.COATestLine, 0, len(inspection.Results)),
+	}
+	if m := inspection.Material; m != nil {
+		coa.MaterialCode = m.Code
+		coa.MaterialName = m.TradingName
+		coa.Unit = m.Unit
+		if m.InciName != nil {
+			coa.InciName = *m.InciName
+		}
+	}
+	if grn := inspection.GRN; grn != nil {
+		coa.GRNNumber = grn.GRNNumber
+		coa.ReceiptDate = firstN(grn.ReceiptDate, 10)
+		if grn.PurchaseOrder != nil && grn.PurchaseOrder.Supplier != nil {
+			coa.SupplierName = grn.PurchaseOrder.Supplier.Name
+		}
+	}
+	if item := inspection.GRNItem; item != nil {
+		if item.ManufactureDate != nil {
+			coa.ManufactureDate = firstN(*item.ManufactureDate, 10)
+		}
+		if item.ExpiryDate != nil {
+			coa.ExpiryDate = firstN(*item.ExpiryDate, 10)
+		}
+	}
+	if t := inspection.Template; t != nil {
+		coa.TemplateCode = t.Code
+		coa.TemplateVersion = t.Version
+	}
+	if u := inspection.InspectedByUser; u != nil {
+		coa.InspectedBy = u.FullName
+		if coa.InspectedBy == "" {
+			coa.InspectedBy = u.Username
+		}
+	}
+	for _, r := range inspection.Results {
+		line := dto.COATestLine{
+			TestCode:      r.TestCode,
+			TestName:      r.TestName,
+			Category:      r.Category,
+			Method:        r.Method,
+			Specification: qcSpecification(r),
+			Unit:          r.Unit,
+			Conclusion:    r.Result,
+		}
+		if r.NumericValue != nil {
+			line.Result = fmt.Sprintf("%g", *r.NumericValue)
+		} else if r.TextValue != nil {
+			line.Result = *r.TextValue
+		}
+		coa.Tests = append(coa.Tests, line)
+	}
+	return coa
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func fptr(v float64) *float64 { return &v }
+
+func sptr(v string) *string { return &v }
+
+func TestQCSampleSize(t *testing.T) {
+	fixed := &models.QCTemplate{SamplingRule: QCSamplingFixed, SampleValue: 0.5}
+	size, unit, err := qcSampleSize(fixed, 200, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, size)
+	assert.Equal(t, "", unit)
+
+	// Percent of received quantity, clamped to min/max
+	percent := &models.QCTemplate{SamplingRule: QCSamplingPercent, SampleValue: 1, MinSample: fptr(0.5), MaxSample: fptr(2)}
+	size, _, _ = qcSampleSize(percent, 120, nil)
+	assert.Equal(t, 1.2, size)
+	size, _, _ = qcSampleSize(percent, 10, nil)
+	assert.Equal(t, 0.5, size)
+	size, _, _ = qcSampleSize(percent, 1000, nil)
+	assert.Equal(t, 2.0, size)
+
+	// sqrt(N)+1 containers
+	sqrtRule := &models.QCTemplate{SamplingRule: QCSamplingSqrt}
+	_, _, err = qcSampleSize(sqrtRule, 500, nil)
+	assert.Error(t, err)
+	containers := 20
+	size, unit, err = qcSampleSize(sqrtRule, 500, &containers)
+	assert.NoError(t, err)
+	assert.Equal(t, 6.0, size) // ceil(4.47) + 1
+	assert.Equal(t, "containers", unit)
+	containers = 1
+	size, _, _ = qcSampleSize(sqrtRule, 25, &containers)
+	assert.Equal(t, 1.0, size)
+}
+
+func TestEvaluateQCResult(t *testing.T) {
+	ph := &models.QCInspectionResult{ResultType: QCResultTypeNumeric, MinValue: fptr(5.5), MaxValue: fptr(7)}
+	assert.Equal(t, QCResultPending, evaluateQCResult(ph))
+	ph.NumericValue = fptr(7)
+	assert.Equal(t, QCResultPass, evaluateQCResult(ph))
+	ph.NumericValue = fptr(7.1)
+	assert.Equal(t, QCResultFail, evaluateQCResult(ph))
+
+	micro := &models.QCInspectionResult{ResultType: QCResultTypeNumeric, MaxValue: fptr(100)}
+	micro.NumericValue = fptr(20)
+	assert.Equal(t, QCResultPass, evaluateQCResult(micro))
+
+	appearance := &models.QCInspectionResult{ResultType: QCResultTypeText, ExpectedText: "Conforms"}
+	appearance.TextValue = sptr(" conforms ")
+	assert.Equal(t, QCResultPass, evaluateQCResult(appearance))
+	appearance.TextValue = sptr("Yellowish")
+	assert.Equal(t, QCResultFail, evaluateQCResult(appearance))
+}
+
+func TestQCInspectionOutcome(t *testing.T) {
+	results := []*models.QCInspectionResult{
+		{IsRequired: true, Result: QCResultPass},
+		{IsRequired: true, Result: QCResultPending},
+		{IsRequired: false, Result: QCResultFail},
+	}
+	status, result := qcInspectionOutcome(results)
+	assert.Equal(t, QCInspectionPending, status)
+	assert.Equal(t, "", result)
+
+	// Optional tests don't fail the batch
+	results[1].Result = QCResultPass
+	status, result = qcInspectionOutcome(results)
+	assert.Equal(t, QCInspectionCompleted, status)
+	assert.Equal(t, QCResultPass, result)
+
+	results[1].Result = QCResultFail
+	_, result = qcInspectionOutcome(results)
+	assert.Equal(t, QCResultFail, result)
+}
+
+func TestRetainUntil(t *testing.T) {
+	retainedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2028-01-31", *retainUntil(retainedAt, sptr("2027-01-31T00:00:00Z"), 365))
+	assert.Equal(t, "2026-03-31", *retainUntil(retainedAt, nil, 30))
+	assert.Nil(t, retainUntil(retainedAt, nil, 0))
+	assert.Nil(t, retainUntil(retainedAt, sptr("2027-01-31"), 0))
+}
+
+func TestQCSpecification(t *testing.T) {
+	assert.Equal(t, "5.5 – 7", qcSpecification(&models.QCInspectionResult{ResultType: QCResultTypeNumeric, MinValue: fptr(5.5), MaxValue: fptr(7)}))
+	assert.Equal(t, "≤ 100", qcSpecification(&models.QCInspectionResult{ResultType: QCResultTypeNumeric, MaxValue: fptr(100)}))
+	assert.Equal(t, "Conforms", qcSpecification(&models.QCInspectionResult{ResultType: QCResultTypeText, ExpectedText: "Conforms"}))
+}
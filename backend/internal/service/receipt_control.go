@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+)
+
+// Purchase order receipt statuses
+const (
+	ReceiptStatusPending     = "pending"
+	ReceiptStatusPartial     = "partial"
+	ReceiptStatusReceived    = "received"
+	ReceiptStatusClosedShort = "closed_short"
+)
+
+// qtyEpsilon absorbs decimal(15,3) rounding when comparing quantities
+const qtyEpsilon = 0.0005
+
+// receiptTolerance holds over/under receiving tolerances in percent of the ordered quantity
+type receiptTolerance struct {
+	Over  float64
+	Under float64
+}
+
+// resolveReceiptTolerance takes the material tolerance when set, otherwise the supplier's
+func resolveReceiptTolerance(material *models.Material, supplier *models.Supplier) receiptTolerance {
+	var t receiptTolerance
+	if supplier != nil {
+		t.Over = supplier.OverReceiptTolerance
+		t.Under = supplier.UnderReceiptTolerance
+	}
+	if material != nil {
+		if material.OverReceiptTolerance != nil {
+			t.Over = *material.OverReceiptTolerance
+		}
+		if material.UnderReceiptTolerance != nil {
+			t.Under = *material.UnderReceiptTolerance
+		}
+	}
+	return t
+}
+
+// maxReceivable is the most that may be received against an ordered quantity
+func (t receiptTolerance) maxReceivable(ordered float64) float64 {
+	return ordered * (1 + t.Over/100)
+}
+
+// fulfilled reports whether received is close enough to ordered to consider the line delivered
+func (t receiptTolerance) fulfilled(ordered, received float64) bool {
+	return received >= ordered*(1-t.Under/100)-qtyEpsilon
+}
+
+// poLineLabel names a PO line in error messages
+func poLineLabel(item *models.PurchaseOrderItem) string {
+	if item.Material != nil && item.Material.Code != "" {
+		return item.Material.Code
+	}
+	return fmt.Sprintf("PO line %d", item.ID)
+}
+
+// checkReceivable validates receiving qty on a PO line on top of alreadyCounted
+// (posted receipts, plus unposted GRNs when creating a GRN)
+func checkReceivable(po *models.PurchaseOrder, item *models.PurchaseOrderItem, alreadyCounted, qty float64) error {
+	if item.ClosedShort {
+		return fmt.Errorf("%s: PO line is closed short", poLineLabel(item))
+	}
+	tolerance := resolveReceiptTolerance(item.Material, po.Supplier)
+	limit := tolerance.maxReceivable(item.Quantity)
+	if alreadyCounted+qty > limit+qtyEpsilon {
+		remaining := limit - alreadyCounted
+		if remaining < 0 {
+			remaining = 0
+		}
+		return fmt.Errorf("%s: receiving %s exceeds the open PO quantity (ordered %s, already received or pending %s, tolerance %s%%, at most %s more)",
+			poLineLabel(item), formatQty(qty), formatQty(item.Quantity), formatQty(alreadyCounted), formatQty(tolerance.Over), formatQty(roundQty(remaining)))
+	}
+	return nil
+}
+
+// computeReceiptStatus derives the PO receipt status from its lines.
+// A line is done when received within the under-receipt tolerance or closed short.
+func computeReceiptStatus(po *models.PurchaseOrder) string {
+	if len(po.Items) == 0 {
+		return ReceiptStatusPending
+	}
+	allDone, anyReceived, anyClosedShort := true, false, false
+	for _, item := range po.Items {
+		if item.ReceivedQuantity > qtyEpsilon {
+			anyReceived = true
+		}
+		tolerance := resolveReceiptTolerance(item.Material, po.Supplier)
+		switch {
+		case tolerance.fulfilled(item.Quantity, item.ReceivedQuantity):
+		case item.ClosedShort:
+			anyClosedShort = true
+		default:
+			allDone = false
+		}
+	}
+	switch {
+	case allDone && anyClosedShort:
+		return ReceiptStatusClosedShort
+	case allDone:
+		return ReceiptStatusReceived
+	case anyReceived || anyClosedShort:
+		return ReceiptStatusPartial
+	default:
+		return ReceiptStatusPending
+	}
+}
+
+// refreshReceiptStatus recomputes receipt_status and completes (or reopens) the PO accordingly
+func refreshReceiptStatus(poRepo repository.PurchaseOrderRepository, poID uint, userID uint) error {
+	po, err := poRepo.GetByID(poID)
+	if err != nil {
+		return err
+	}
+	status := computeReceiptStatus(po)
+	fulfilled := status == ReceiptStatusReceived || status == ReceiptStatusClosedShort
+	return poRepo.UpdateReceiptStatus(poID, status, fulfilled, userID)
+}
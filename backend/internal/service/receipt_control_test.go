@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveReceiptTolerance(t *testing.T) {
+	supplier := &models.Supplier{OverReceiptTolerance: 10, UnderReceiptTolerance: 5}
+	assert.Equal(t, receiptTolerance{Over: 10, Under: 5}, resolveReceiptTolerance(&models.Material{}, supplier))
+
+	// Material settings override the supplier, field by field
+	over := 2.0
+	assert.Equal(t, receiptTolerance{Over: 2, Under: 5}, resolveReceiptTolerance(&models.Material{OverReceiptTolerance: &over}, supplier))
+
+	assert.Equal(t, receiptTolerance{}, resolveReceiptTolerance(nil, nil))
+}
+
+func TestCheckReceivable(t *testing.T) {
+	po := &models.PurchaseOrder{Supplier: &models.Supplier{OverReceiptTolerance: 10}}
+	item := &models.PurchaseOrderItem{ID: 1, Quantity: 100, Material: &models.Material{Code: "MAT-001"}}
+
+	assert.NoError(t, checkReceivable(po, item, 0, 100))
+	assert.NoError(t, checkReceivable(po, item, 60, 50)) // 110 is within 10%
+	assert.Error(t, checkReceivable(po, item, 60, 51))
+
+	// No tolerance: the ordered quantity is the ceiling
+	po.Supplier.OverReceiptTolerance = 0
+	assert.Error(t, checkReceivable(po, item, 100, 1))
+
+	item.ClosedShort = true
+	assert.Error(t, checkReceivable(po, item, 0, 1))
+}
+
+func TestComputeReceiptStatus(t *testing.T) {
+	po := &models.PurchaseOrder{
+		Supplier: &models.Supplier{UnderReceiptTolerance: 5},
+		Items: []*models.PurchaseOrderItem{
+			{ID: 1, Quantity: 100},
+			{ID: 2, Quantity: 50},
+		},
+	}
+	assert.Equal(t, ReceiptStatusPending, computeReceiptStatus(po))
+
+	po.Items[0].ReceivedQuantity = 96 // within 5% under-receipt
+	assert.Equal(t, ReceiptStatusPartial, computeReceiptStatus(po))
+
+	po.Items[1].ReceivedQuantity = 50
+	assert.Equal(t, ReceiptStatusReceived, computeReceiptStatus(po))
+
+	po.Items[1].ReceivedQuantity = 20
+	po.Items[1].ClosedShort = true
+	assert.Equal(t, ReceiptStatusClosedShort, computeReceiptStatus(po))
+
+	po.Items[0].ReceivedQuantity = 90
+	assert.Equal(t, ReceiptStatusPartial, computeReceiptStatus(po))
+}
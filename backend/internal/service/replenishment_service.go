@@ -0,0 +1,285 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+)
+
+// ReplenishmentRunResult is a replenishment plan together with the draft POs raised from it
+type ReplenishmentRunResult struct {
+	Plan           *dto.ReplenishmentPlan      `json:"plan"`
+	PurchaseOrders []*models.SafePurchaseOrder `json:"purchase_orders"`
+	Errors         []string                    `json:"errors,omitempty"` // suppliers whose draft PO could not be created
+}
+
+// ReplenishmentService proposes purchase orders for materials below their reorder point
+type ReplenishmentService interface {
+	Preview(req *dto.ReplenishmentRequest) (*dto.ReplenishmentPlan, error)
+	Run(req *dto.ReplenishmentRequest, userID uint, username string) (*ReplenishmentRunResult, error)
+}
+
+type replenishmentService struct {
+	repo          repository.ReplenishmentRepository
+	warehouseRepo repository.WarehouseRepository
+	poService     PurchaseOrderService
+	auditSvc      AuditLogService
+}
+
+func NewReplenishmentService(
+	repo repository.ReplenishmentRepository,
+	warehouseRepo repository.WarehouseRepository,
+	poService PurchaseOrderService,
+	auditSvc AuditLogService,
+) ReplenishmentService {
+	return &replenishmentService{
+		repo:          repo,
+		warehouseRepo: warehouseRepo,
+		poService:     poService,
+		auditSvc:      auditSvc,
+	}
+}
+
+// Preview computes the proposals without creating anything
+func (s *replenishmentService) Preview(req *dto.ReplenishmentRequest) (*dto.ReplenishmentPlan, error) {
+	if _, err := s.warehouseRepo.GetByID(req.WarehouseID); err != nil {
+		return nil, errors.New("warehouse not found")
+	}
+	orderDate := req.OrderDate
+	if orderDate == "" {
+		orderDate = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", orderDate); err != nil {
+		return nil, errors.New("order_date must be YYYY-MM-DD")
+	}
+
+	rows, err := s.repo.ListPositions(req.WarehouseID, req.MaterialIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &dto.ReplenishmentPlan{
+		WarehouseID: req.WarehouseID,
+		OrderDate:   orderDate,
+		Proposals:   []dto.ReplenishmentProposal{},
+		Unassigned:  []dto.ReplenishmentLine{},
+	}
+
+	var lines []dto.ReplenishmentLine
+	var materialIDs []uint
+	for _, row := range rows {
+		suggested := replenishmentQuantity(row)
+		if suggested <= 0 {
+			continue
+		}
+		lines = append(lines, dto.ReplenishmentLine{
+			MaterialID:        row.MaterialID,
+			MaterialCode:      row.MaterialCode,
+			MaterialName:      row.MaterialName,
+			Unit:              row.Unit,
+			ReorderPoint:      row.ReorderPoint,
+			ReorderQuantity:   row.ReorderQuantity,
+			MaxStockLevel:     row.MaxStockLevel,
+			OnHand:            row.OnHand,
+			Reserved:          row.Reserved,
+			OnOrder:           row.OnOrder,
+			Position:          stockPosition(row),
+			SuggestedQuantity: suggested,
+			OrderQuantity:     suggested,
+		})
+		materialIDs = append(materialIDs, row.MaterialID)
+	}
+	if len(lines) == 0 {
+		return plan, nil
+	}
+
+	links, err := s.repo.ListMaterialSuppliers(materialIDs)
+	if err != nil {
+		return nil, err
+	}
+	preferred := preferredSuppliers(links)
+
+	proposalIndex := make(map[uint]int)
+	for _, line := range lines {
+		link, ok := preferred[line.MaterialID]
+		if !ok {
+			plan.Unassigned = append(plan.Unassigned, line)
+			continue
+		}
+		if link.MinOrderQuantity != nil {
+			line.MinOrderQuantity = *link.MinOrderQuantity
+		}
+		if link.PackSize != nil {
+			line.PackSize = *link.PackSize
+		}
+		if link.UnitPrice != nil {
+			line.UnitPrice = *link.UnitPrice
+		}
+		if link.LeadTimeDays != nil {
+			line.LeadTimeDays = *link.LeadTimeDays
+		}
+		line.OrderQuantity = roundOrderQuantity(line.SuggestedQuantity, line.MinOrderQuantity, line.PackSize)
+
+		supplierID := uint(link.SupplierID)
+		idx, ok := proposalIndex[supplierID]
+		if !ok {
+			plan.Proposals = append(plan.Proposals, dto.ReplenishmentProposal{
+				SupplierID:   supplierID,
+				SupplierCode: link.Supplier.Code,
+				SupplierName: link.Supplier.Name,
+			})
+			idx = len(plan.Proposals) - 1
+			proposalIndex[supplierID] = idx
+		}
+		plan.Proposals[idx].Lines = append(plan.Proposals[idx].Lines, line)
+		plan.Proposals[idx].EstimatedValue = roundMoney(plan.Proposals[idx].EstimatedValue + line.OrderQuantity*line.UnitPrice)
+	}
+	return plan, nil
+}
+
+// Run raises one draft PO per preferred supplier for the buyer to review.
+// Open POs count as on order, so running again does not duplicate proposals.
+func (s *replenishmentService) Run(req *dto.ReplenishmentRequest, userID uint, username string) (*ReplenishmentRunResult, error) {
+	plan, err := s.Preview(req)
+	if err != nil {
+		return nil, err
+	}
+	result := &ReplenishmentRunResult{Plan: plan, PurchaseOrders: []*models.SafePurchaseOrder{}}
+
+	for _, proposal := range plan.Proposals {
+		poNumber, err := s.generatePONumber()
+		if err != nil {
+			return nil, err
+		}
+		poReq := dto.CreatePurchaseOrderRequest{
+			PONumber:    poNumber,
+			SupplierID:  proposal.SupplierID,
+			WarehouseID: plan.WarehouseID,
+			OrderDate:   plan.OrderDate,
+			Notes:       "Auto replenishment: materials below reorder point",
+			Source:      "replenishment",
+		}
+		leadTime := 0
+		for _, line := range proposal.Lines {
+			poReq.Items = append(poReq.Items, dto.CreatePurchaseOrderItemRequest{
+				MaterialID: line.MaterialID,
+				Quantity:   line.OrderQuantity,
+				UnitPrice:  line.UnitPrice,
+				Notes:      fmt.Sprintf("Position %s below reorder point %s", formatQty(line.Position), formatQty(line.ReorderPoint)),
+			})
+			if line.LeadTimeDays > leadTime {
+				leadTime = line.LeadTimeDays
+			}
+		}
+		if leadTime > 0 {
+			orderDate, _ := time.Parse("2006-01-02", plan.OrderDate)
+			poReq.ExpectedDeliveryDate = orderDate.AddDate(0, 0, leadTime).Format("2006-01-02")
+		}
+
+		po, err := s.poService.CreatePurchaseOrder(&poReq, userID, username)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", proposal.SupplierCode, err))
+			continue
+		}
+		result.PurchaseOrders = append(result.PurchaseOrders, po)
+	}
+
+	_ = s.auditSvc.Log("purchase_orders", "REPLENISHMENT_RUN", int64(plan.WarehouseID), int64(userID), username, nil, map[string]interface{}{
+		"warehouse_id":    plan.WarehouseID,
+		"proposals":       len(plan.Proposals),
+		"unassigned":      len(plan.Unassigned),
+		"purchase_orders": len(result.PurchaseOrders),
+		"errors":          result.Errors,
+	})
+	return result, nil
+}
+
+// generatePONumber creates a number like RPL-2026-000001
+func (s *replenishmentService) generatePONumber() (string, error) {
+	prefix := fmt.Sprintf("RPL-%s-", time.Now().Format("2006"))
+	count, err := s.repo.CountByPONumber(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%06d", prefix, count+1), nil
+}
+
+// stockPosition is what the warehouse can count on: on hand - reserved + on order
+func stockPosition(row repository.ReplenishmentPositionRow) float64 {
+	return row.OnHand - row.Reserved + row.OnOrder
+}
+
+// replenishmentQuantity sizes the order for a material below its reorder point (0 = not needed).
+// Reorder quantity is used when set, otherwise the order tops the position up to max stock;
+// either way the order at least brings the position back to the reorder point.
+func replenishmentQuantity(row repository.ReplenishmentPositionRow) float64 {
+	position := stockPosition(row)
+	if row.ReorderPoint <= 0 || position >= row.ReorderPoint {
+		return 0
+	}
+	qty := row.ReorderQuantity
+	if qty <= 0 && row.MaxStockLevel > 0 {
+		qty = row.MaxStockLevel - position
+	}
+	if shortfall := row.ReorderPoint - position; qty < shortfall {
+		qty = shortfall
+	}
+	return roundQty(qty)
+}
+
+// roundOrderQuantity raises qty to the supplier MOQ and then up to a whole number of packs
+func roundOrderQuantity(qty, minOrderQty, packSize float64) float64 {
+	if qty < minOrderQty {
+		qty = minOrderQty
+	}
+	if packSize > 0 {
+		qty = math.Ceil(roundQty(qty/packSize)) * packSize
+	}
+	return roundQty(qty)
+}
+
+func roundQty(q float64) float64 {
+	return math.Round(q*1000) / 1000
+}
+
+// preferredSuppliers picks the highest-priority active supplier per material.
+// links must be ordered by material and priority.
+func preferredSuppliers(links []models.MaterialSupplier) map[uint]models.MaterialSupplier {
+	preferred := make(map[uint]models.MaterialSupplier)
+	for _, link := range links {
+		materialID := uint(link.MaterialID)
+		if _, ok := preferred[materialID]; ok {
+			continue
+		}
+		if link.Supplier == nil || (link.Supplier.IsActive != nil && !*link.Supplier.IsActive) {
+			continue
+		}
+		preferred[materialID] = link
+	}
+	return preferred
+}
+
+// StartReplenishmentScheduler runs replenishment for a warehouse in the background every interval
+func StartReplenishmentScheduler(svc ReplenishmentService, interval time.Duration, warehouseID, userID uint) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			result, err := svc.Run(&dto.ReplenishmentRequest{WarehouseID: warehouseID}, userID, "replenishment")
+			if err != nil {
+				log.Printf("replenishment: warehouse %d: %v", warehouseID, err)
+				continue
+			}
+			log.Printf("replenishment: warehouse %d: %d draft POs, %d materials without supplier",
+				warehouseID, len(result.PurchaseOrders), len(result.Plan.Unassigned))
+			for _, msg := range result.Errors {
+				log.Printf("replenishment: warehouse %d: %s", warehouseID, msg)
+			}
+		}
+	}()
+}
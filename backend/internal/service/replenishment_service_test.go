@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplenishmentQuantity(t *testing.T) {
+	row := repository.ReplenishmentPositionRow{ReorderPoint: 100, OnHand: 80, Reserved: 30, OnOrder: 10}
+	// Position 60 is below the reorder point; no reorder quantity or max: back to the reorder point
+	assert.Equal(t, 40.0, replenishmentQuantity(row))
+
+	row.ReorderQuantity = 250
+	assert.Equal(t, 250.0, replenishmentQuantity(row))
+
+	// Up to max when no reorder quantity is set
+	row.ReorderQuantity = 0
+	row.MaxStockLevel = 300
+	assert.Equal(t, 240.0, replenishmentQuantity(row))
+
+	// A reorder quantity smaller than the shortfall is raised to it
+	row.ReorderQuantity = 10
+	assert.Equal(t, 40.0, replenishmentQuantity(row))
+
+	// Open POs cover the gap
+	row.OnOrder = 50
+	assert.Equal(t, 0.0, replenishmentQuantity(row))
+}
+
+func TestRoundOrderQuantity(t *testing.T) {
+	assert.Equal(t, 40.0, roundOrderQuantity(40, 0, 0))
+	assert.Equal(t, 100.0, roundOrderQuantity(40, 100, 0))
+	assert.Equal(t, 50.0, roundOrderQuantity(40, 0, 25))
+	assert.Equal(t, 125.0, roundOrderQuantity(40, 110, 25))
+	assert.Equal(t, 75.0, roundOrderQuantity(75, 0, 25))
+	assert.Equal(t, 0.6, roundOrderQuantity(0.55, 0, 0.2))
+}
+
+func TestPreferredSuppliers(t *testing.T) {
+	inactive := false
+	links := []models.MaterialSupplier{
+		{MaterialID: 1, SupplierID: 10, Priority: 1, Supplier: &models.Supplier{IsActive: &inactive}},
+		{MaterialID: 1, SupplierID: 11, Priority: 2, Supplier: &models.Supplier{}},
+		{MaterialID: 1, SupplierID: 12, Priority: 3, Supplier: &models.Supplier{}},
+		{MaterialID: 2, SupplierID: 12, Priority: 1, Supplier: &models.Supplier{}},
+	}
+	preferred := preferredSuppliers(links)
+	assert.Equal(t, int64(11), preferred[1].SupplierID)
+	assert.Equal(t, int64(12), preferred[2].SupplierID)
+	_, ok := preferred[3]
+	assert.False(t, ok)
+}
@@ -76,13 +76,43 @@ func (s *ReturnOrderService) Create(req dto.CreateReturnOrderRequest, userID uin
 		UpdatedBy:       &userID,
 	}
 
+	// Serial-tracked products come back with the serials they were shipped with
+	productIDs := make([]uint, 0, len(req.Items))
+	for _, item := range req.Items {
+		productIDs = append(productIDs, item.FinishedProductID)
+	}
+	tracked, err := serialTrackedItems(s.db, "finished_product", productIDs)
+	if err != nil {
+		return nil, err
+	}
+	shipped := make(map[uint]map[string]bool)
+	for _, item := range do.Items {
+		for _, serial := range item.SerialNumbers {
+			if shipped[item.FinishedProductID] == nil {
+				shipped[item.FinishedProductID] = make(map[string]bool)
+			}
+			shipped[item.FinishedProductID][serial] = true
+		}
+	}
+
 	// Create items
 	totalItems := 0
-	for _, item := range req.Items {
+	for i, item := range req.Items {
+		serials := cleanSerials(item.SerialNumbers)
+		label := fmt.Sprintf("item %d", i+1)
+		if err := checkSerialCount(tracked[item.FinishedProductID], float64(item.QuantityReturned), serials, label); err != nil {
+			return nil, err
+		}
+		for _, serial := range serials {
+			if !shipped[item.FinishedProductID][serial] {
+				return nil, fmt.Errorf("%s: serial %s was not shipped on delivery order %s", label, serial, do.DONumber)
+			}
+		}
 		roItem := models.ReturnOrderItem{
 			DeliveryOrderItemID: item.DeliveryOrderItemID,
 			FinishedProductID:   item.FinishedProductID,
 			QuantityReturned:    item.QuantityReturned,
+			SerialNumbers:       serials,
 			Condition:           "pending_inspection",
 			Reason:              item.Reason,
 			Notes:               item.Notes,
@@ -304,7 +334,15 @@ func (s *ReturnOrderService) Complete(id uint, userID uint) (*models.ReturnOrder
 	ro.TotalScrapped = totalScrapped
 	ro.UpdatedBy = &userID
 
-	if err := s.roRepo.Update(ro); err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range ro.Items {
+			if err := returnSerials(tx, item.FinishedProductID, item.SerialNumbers, ro.ID); err != nil {
+				return err
+			}
+		}
+		return repository.NewReturnOrderRepository(tx).Update(ro)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Scan symbologies recognised by parseScan
+const (
+	ScanSymbologyGS1  = "gs1"
+	ScanSymbologyGTIN = "gtin" // EAN-8, UPC-A, EAN-13, GTIN-14
+	ScanSymbologyText = "text" // location, document or item codes
+)
+
+// gs1Separator is the FNC1 group separator scanners emit after variable-length fields
+const gs1Separator = "\x1d"
+
+// scanCode is a raw scan decoded into its parts, before anything is looked up
+type scanCode struct {
+	Symbology string
+	Data      string            // payload without the symbology identifier
+	AIs       map[string]string // GS1 application identifiers and their values
+	GTIN      string
+	Batch     string
+	Serial    string
+	Expiry    string // YYYY-MM-DD
+}
+
+type gs1AI struct {
+	Length   int  // fixed length, or maximum length when Variable
+	Variable bool // terminated by FNC1 or the end of the data
+}
+
+// gs1AIs are the application identifiers we decode; only 01/02, 10, 17, 21 and 240 are used for lookups,
+// the others are parsed so that codes carrying them can still be read
+var gs1AIs = map[string]gs1AI{
+	"00":  {18, false}, // SSCC
+	"01":  {14, false}, // GTIN
+	"02":  {14, false}, // GTIN of contained items
+	"10":  {20, true},  // batch/lot
+	"11":  {6, false},  // production date
+	"13":  {6, false},  // packaging date
+	"15":  {6, false},  // best before
+	"16":  {6, false},  // sell by
+	"17":  {6, false},  // expiry
+	"20":  {2, false},  // variant
+	"21":  {20, true},  // serial
+	"22":  {20, true},  // consumer product variant
+	"30":  {8, true},   // variable count
+	"37":  {8, true},   // count of contained items
+	"240": {30, true},  // additional product id
+	"241": {30, true},  // customer part number
+	"400": {30, true},  // customer PO number
+}
+
+var gs1BracketedAI = regexp.MustCompile(`\((\d{2,4})\)([^(]*)`)
+
+// parseScan decodes a raw scanned string. GS1 element strings are recognised by their symbology
+// identifier (]C1 GS1-128, ]d2 DataMatrix, ]Q3 QR, ]e0 DataBar), an FNC1 separator, the printed
+// "(01)..." form or a leading AI 01 with a valid GTIN; bare 8/12/13/14 digit codes with a valid
+// check digit are GTINs and everything else is text.
+func parseScan(raw string) (*scanCode, error) {
+	data := strings.TrimSpace(raw)
+	gs1 := false
+	if len(data) >= 3 && data[0] == ']' {
+		switch data[1:3] {
+		case "C1", "d2", "Q3", "e0":
+			gs1 = true
+		}
+		data = data[3:]
+	}
+	data = strings.TrimPrefix(data, gs1Separator)
+	if data == "" {
+		return nil, fmt.Errorf("empty scan")
+	}
+
+	code := &scanCode{Data: data, Symbology: ScanSymbologyText}
+	switch {
+	case strings.HasPrefix(data, "(") && gs1BracketedAI.MatchString(data):
+		ais, err := parseGS1Bracketed(data)
+		if err != nil {
+			return nil, err
+		}
+		code.AIs = ais
+	case gs1 || strings.Contains(data, gs1Separator) || looksLikeGS1(data):
+		ais, err := parseGS1Elements(data)
+		if err != nil {
+			return nil, err
+		}
+		code.AIs = ais
+	case isDigits(data) && validGTIN(data):
+		code.Symbology = ScanSymbologyGTIN
+		code.GTIN = padGTIN(data)
+		return code, nil
+	default:
+		return code, nil
+	}
+
+	code.Symbology = ScanSymbologyGS1
+	if err := code.applyAIs(); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// looksLikeGS1 accepts unprefixed element strings that start with AI 01 and a valid GTIN-14
+// and carry at least one more element
+func looksLikeGS1(data string) bool {
+	return len(data) > 16 && strings.HasPrefix(data, "01") && isDigits(data[2:16]) && validGTIN(data[2:16])
+}
+
+func parseGS1Elements(data string) (map[string]string, error) {
+	ais := make(map[string]string)
+	for i := 0; i < len(data); {
+		if strings.HasPrefix(data[i:], gs1Separator) {
+			i += len(gs1Separator)
+			continue
+		}
+		ai, spec, ok := matchAI(data[i:])
+		if !ok {
+			return nil, fmt.Errorf("unsupported GS1 application identifier at %q", data[i:])
+		}
+		i += len(ai)
+		end := i + spec.Length
+		if spec.Variable {
+			if sep := strings.Index(data[i:], gs1Separator); sep >= 0 && i+sep < end {
+				end = i + sep
+			}
+		}
+		if end > len(data) {
+			if !spec.Variable {
+				return nil, fmt.Errorf("GS1 AI (%s) needs %d characters", ai, spec.Length)
+			}
+			end = len(data)
+		}
+		ais[ai] = data[i:end]
+		i = end
+	}
+	return ais, nil
+}
+
+func parseGS1Bracketed(data string) (map[string]string, error) {
+	ais := make(map[string]string)
+	for _, m := range gs1BracketedAI.FindAllStringSubmatch(data, -1) {
+		spec, ok := gs1AIs[m[1]]
+		if !ok {
+			return nil, fmt.Errorf("unsupported GS1 application identifier (%s)", m[1])
+		}
+		value := strings.TrimSpace(m[2])
+		if (!spec.Variable && len(value) != spec.Length) || len(value) > spec.Length {
+			return nil, fmt.Errorf("GS1 AI (%s) has an invalid length", m[1])
+		}
+		ais[m[1]] = value
+	}
+	return ais, nil
+}
+
+// matchAI finds the known AI at the start of s, trying 2, 3 and 4 digit identifiers
+func matchAI(s string) (string, gs1AI, bool) {
+	for n := 2; n <= 4 && n <= len(s); n++ {
+		if spec, ok := gs1AIs[s[:n]]; ok {
+			return s[:n], spec, true
+		}
+	}
+	return "", gs1AI{}, false
+}
+
+func (c *scanCode) applyAIs() error {
+	gtin, ok := c.AIs["01"]
+	if !ok {
+		// pallet labels carry the GTIN of the contained items in AI 02
+		gtin, ok = c.AIs["02"]
+	}
+	if ok {
+		if !isDigits(gtin) || !validGTIN(gtin) {
+			return fmt.Errorf("invalid GTIN %s", gtin)
+		}
+		c.GTIN = gtin
+	}
+	c.Batch = c.AIs["10"]
+	c.Serial = c.AIs["21"]
+	if exp, ok := c.AIs["17"]; ok {
+		date, err := parseGS1Date(exp)
+		if err != nil {
+			return err
+		}
+		c.Expiry = date
+	}
+	return nil
+}
+
+// parseGS1Date converts YYMMDD to YYYY-MM-DD; day 00 means the last day of the month
+func parseGS1Date(s string) (string, error) {
+	if len(s) != 6 || !isDigits(s) {
+		return "", fmt.Errorf("invalid GS1 date %q", s)
+	}
+	year := 2000 + int(s[0]-'0')*10 + int(s[1]-'0')
+	month := int(s[2]-'0')*10 + int(s[3]-'0')
+	day := int(s[4]-'0')*10 + int(s[5]-'0')
+	if month < 1 || month > 12 {
+		return "", fmt.Errorf("invalid GS1 date %q", s)
+	}
+	last := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day == 0 {
+		day = last
+	}
+	if day > last {
+		return "", fmt.Errorf("invalid GS1 date %q", s)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", year, month, day), nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// validGTIN checks the length and mod-10 check digit of an EAN-8, UPC-A, EAN-13 or GTIN-14
+func validGTIN(s string) bool {
+	switch len(s) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+	return gs1CheckDigit(s[:len(s)-1]) == s[len(s)-1]
+}
+
+// gs1CheckDigit computes the mod-10 check digit for a GTIN or SSCC without its check digit
+func gs1CheckDigit(body string) byte {
+	sum := 0
+	for i := len(body) - 1; i >= 0; i-- {
+		d := int(body[i] - '0')
+		if (len(body)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// padGTIN left-pads a GTIN to 14 digits
+func padGTIN(s string) string {
+	return strings.Repeat("0", 14-len(s)) + s
+}
+
+// gtinVariants lists the forms a GTIN may be stored as in a barcode field:
+// GTIN-14 and, where the leading zeros allow it, EAN-13, UPC-A and EAN-8
+func gtinVariants(gtin string) []string {
+	g := padGTIN(gtin)
+	variants := []string{g}
+	for _, n := range []int{13, 12, 8} {
+		if strings.Count(g[:14-n], "0") == 14-n {
+			variants = append(variants, g[14-n:])
+		}
+	}
+	return variants
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScanGTIN(t *testing.T) {
+	code, err := parseScan("4006381333931")
+	require.NoError(t, err)
+	assert.Equal(t, ScanSymbologyGTIN, code.Symbology)
+	assert.Equal(t, "04006381333931", code.GTIN)
+
+	// a wrong check digit is not a GTIN, just text
+	code, err = parseScan("4006381333932")
+	require.NoError(t, err)
+	assert.Equal(t, ScanSymbologyText, code.Symbology)
+	assert.Empty(t, code.GTIN)
+}
+
+func TestParseScanGS1ElementString(t *testing.T) {
+	code, err := parseScan("]C1010400638133393117261200" + "10LOT-42" + gs1Separator + "21SN0001")
+	require.NoError(t, err)
+	assert.Equal(t, ScanSymbologyGS1, code.Symbology)
+	assert.Equal(t, "04006381333931", code.GTIN)
+	assert.Equal(t, "2026-12-31", code.Expiry)
+	assert.Equal(t, "LOT-42", code.Batch)
+	assert.Equal(t, "SN0001", code.Serial)
+
+	// no symbology identifier, recognised by the leading AI 01
+	code, err = parseScan("010400638133393110ABC")
+	require.NoError(t, err)
+	assert.Equal(t, ScanSymbologyGS1, code.Symbology)
+	assert.Equal(t, "ABC", code.Batch)
+
+	_, err = parseScan("]d20104006381333931179913XX")
+	assert.Error(t, err)
+}
+
+func TestParseScanGS1Bracketed(t *testing.T) {
+	code, err := parseScan("(01)04006381333931(17)280229(10)B7")
+	require.NoError(t, err)
+	assert.Equal(t, ScanSymbologyGS1, code.Symbology)
+	assert.Equal(t, "2028-02-29", code.Expiry)
+	assert.Equal(t, "B7", code.Batch)
+
+	code, err = parseScan("(01)04006381333931(17)270200")
+	require.NoError(t, err)
+	assert.Equal(t, "2027-02-28", code.Expiry)
+
+	_, err = parseScan("(01)04006381333931(17)270229")
+	assert.Error(t, err)
+
+	_, err = parseScan("(01)123")
+	assert.Error(t, err)
+}
+
+func TestParseScanText(t *testing.T) {
+	code, err := parseScan("  LOC:A-01-02 ")
+	require.NoError(t, err)
+	assert.Equal(t, ScanSymbologyText, code.Symbology)
+	assert.Equal(t, "LOC:A-01-02", code.Data)
+
+	_, err = parseScan("   ")
+	assert.Error(t, err)
+}
+
+func TestGTINVariants(t *testing.T) {
+	assert.Equal(t, []string{"04006381333931", "4006381333931"}, gtinVariants("4006381333931"))
+	assert.Equal(t, []string{"00000096385074", "0000096385074", "000096385074", "96385074"}, gtinVariants("96385074"))
+	assert.Equal(t, []string{"14006381333938"}, gtinVariants("14006381333938"))
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+)
+
+// ErrScanNotRecognised is returned when a scanned code matches no item, location or document
+var ErrScanNotRecognised = errors.New("scanned code is not recognised")
+
+// locationScanPrefix marks our own location labels, e.g. "LOC:A-01-02"
+const locationScanPrefix = "LOC:"
+
+// ScanService resolves raw scanner input to items, batches, locations and documents
+type ScanService interface {
+	Resolve(req *dto.ScanRequest) (*dto.ScanResult, error)
+}
+
+type scanService struct {
+	repo repository.ScanRepository
+}
+
+func NewScanService(repo repository.ScanRepository) ScanService {
+	return &scanService{repo: repo}
+}
+
+func (s *scanService) Resolve(req *dto.ScanRequest) (*dto.ScanResult, error) {
+	code, err := parseScan(req.Code)
+	if err != nil {
+		return nil, err
+	}
+	result := &dto.ScanResult{
+		Code:      code.Data,
+		Symbology: code.Symbology,
+		GTIN:      code.GTIN,
+		Batch:     code.Batch,
+		Serial:    code.Serial,
+		Expiry:    code.Expiry,
+		AIs:       code.AIs,
+	}
+
+	if code.GTIN != "" {
+		products, err := s.repo.FinishedProductsByBarcode(gtinVariants(code.GTIN))
+		if err != nil {
+			return nil, err
+		}
+		if len(products) == 0 {
+			return nil, ErrScanNotRecognised
+		}
+		result.Type = "finished_product"
+		result.Item = scanProductItem(products[0])
+	} else if itemCode := code.AIs["240"]; itemCode != "" {
+		// our own GS1 labels identify materials (and products without a GTIN) by code in AI 240
+		if err := s.resolveItemCode(itemCode, result); err != nil {
+			return nil, err
+		}
+	} else if err := s.resolveText(code.Data, result); err != nil {
+		return nil, err
+	}
+
+	if result.Item != nil && req.WarehouseID != 0 {
+		if err := s.fillBalances(result, req.WarehouseID); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// resolveText looks a plain code up as a location, a document number, then a product or material code
+func (s *scanService) resolveText(data string, result *dto.ScanResult) error {
+	locationCode, labelled := strings.CutPrefix(data, locationScanPrefix)
+	location, err := s.repo.LocationByCode(locationCode)
+	if err != nil {
+		return err
+	}
+	if location != nil {
+		result.Type = "location"
+		result.Location = &dto.ScanLocation{
+			ID:          location.ID,
+			WarehouseID: location.WarehouseID,
+			Code:        location.Code,
+			Name:        location.Name,
+			Path:        locationPath(location),
+		}
+		return nil
+	}
+	if labelled {
+		return ErrScanNotRecognised
+	}
+
+	doc, err := s.repo.FindDocument(data)
+	if err != nil {
+		return err
+	}
+	if doc != nil {
+		result.Type = "document"
+		result.Document = &dto.ScanDocument{
+			DocumentType: doc.DocumentType,
+			ID:           doc.ID,
+			Number:       doc.Number,
+			Status:       doc.Status,
+		}
+		return nil
+	}
+	return s.resolveItemCode(data, result)
+}
+
+// resolveItemCode looks a code up as a finished product (code or barcode), then as a material code
+func (s *scanService) resolveItemCode(code string, result *dto.ScanResult) error {
+	product, err := s.repo.FinishedProductByCode(code)
+	if err != nil {
+		return err
+	}
+	if product != nil {
+		result.Type = "finished_product"
+		result.Item = scanProductItem(product)
+		return nil
+	}
+
+	material, err := s.repo.MaterialByCode(code)
+	if err != nil {
+		return err
+	}
+	if material != nil {
+		result.Type = "material"
+		result.Item = &dto.ScanItem{
+			ItemType: "material",
+			ItemID:   uint(material.ID),
+			Code:     material.Code,
+			Name:     material.TradingName,
+		}
+		return nil
+	}
+	return ErrScanNotRecognised
+}
+
+// fillBalances adds the item's stock in the warehouse and, when the scan carried a batch without
+// an expiry, takes the expiry from that batch's balance
+func (s *scanService) fillBalances(result *dto.ScanResult, warehouseID uint) error {
+	balances, err := s.repo.Balances(result.Item.ItemType, result.Item.ItemID, warehouseID, result.Batch)
+	if err != nil {
+		return err
+	}
+	result.Balances = make([]dto.ScanBalance, 0, len(balances))
+	for _, b := range balances {
+		result.Balances = append(result.Balances, dto.ScanBalance{
+			LocationID:        b.WarehouseLocationID,
+			LocationPath:      locationPath(b.WarehouseLocation),
+			BatchNumber:       b.BatchNumber,
+			LotNumber:         b.LotNumber,
+			ExpiryDate:        b.ExpiryDate,
+			Quantity:          b.Quantity,
+			AvailableQuantity: b.AvailableQuantity,
+		})
+		if result.Expiry == "" && result.Batch != "" && b.ExpiryDate != nil {
+			result.Expiry = dateOnlyString(*b.ExpiryDate)
+		}
+	}
+	return nil
+}
+
+func scanProductItem(p *models.FinishedProduct) *dto.ScanItem {
+	return &dto.ScanItem{
+		ItemType: "finished_product",
+		ItemID:   p.ID,
+		Code:     p.Code,
+		Name:     p.Name,
+		Barcode:  p.Barcode,
+	}
+}
+
+// dateOnlyString trims a timestamp returned for a date column to YYYY-MM-DD
+func dateOnlyString(s string) string {
+	if len(s) > 10 {
+		return s[:10]
+	}
+	return s
+}
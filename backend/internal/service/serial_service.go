@@ -0,0 +1,359 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Serial number statuses
+const (
+	SerialInStock  = "in_stock"
+	SerialIssued   = "issued"
+	SerialShipped  = "shipped"
+	SerialReturned = "returned"
+)
+
+// What a stock posting does to the serials it carries
+const (
+	serialReceive = "receive"  // GRN/FPRN: new or previously issued serials come into stock
+	serialIssue   = "issue"    // MIN, transit loss: serials leave stock for good
+	serialShip    = "ship"     // DO: serials leave stock to a customer
+	serialMoveOut = "move_out" // transfer out leg: serials must be in stock at the source
+	serialMoveIn  = "move_in"  // transfer in leg: serials are relocated to the destination
+)
+
+// SerialService gives read access to the serial number register and the history of each serial
+type SerialService interface {
+	List(filters map[string]interface{}, offset, limit int) ([]*models.SerialNumber, int64, error)
+	Get(id uint) (*models.SerialNumber, error)
+	History(id uint) ([]*models.StockLedger, error)
+}
+
+type serialService struct {
+	repo repository.SerialRepository
+}
+
+func NewSerialService(repo repository.SerialRepository) SerialService {
+	return &serialService{repo: repo}
+}
+
+func (s *serialService) List(filters map[string]interface{}, offset, limit int) ([]*models.SerialNumber, int64, error) {
+	return s.repo.List(filters, offset, limit)
+}
+
+func (s *serialService) Get(id uint) (*models.SerialNumber, error) {
+	serial, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("serial number not found")
+	}
+	return serial, nil
+}
+
+func (s *serialService) History(id uint) ([]*models.StockLedger, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, errors.New("serial number not found")
+	}
+	return s.repo.History(id)
+}
+
+// cleanSerials trims the serial numbers and drops the blank ones
+func cleanSerials(serials []string) []string {
+	if serials == nil {
+		return nil
+	}
+	out := make([]string, 0, len(serials))
+	for _, s := range serials {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// checkSerialCount checks the serials given for a posted quantity: a serial-tracked item needs a
+// whole quantity and exactly one distinct serial per unit, any other item takes no serials
+func checkSerialCount(tracked bool, qty float64, serials []string, label string) error {
+	if !tracked {
+		if len(serials) > 0 {
+			return fmt.Errorf("%s: serial numbers are only recorded for serial-tracked items", label)
+		}
+		return nil
+	}
+	if math.Abs(qty-math.Round(qty)) > qtyEpsilon {
+		return fmt.Errorf("%s: %s is not a whole number of serial-tracked units", label, formatQty(qty))
+	}
+	seen := make(map[string]bool, len(serials))
+	for _, s := range serials {
+		if seen[s] {
+			return fmt.Errorf("%s: serial %s is listed twice", label, s)
+		}
+		seen[s] = true
+	}
+	if want := int(math.Round(qty)); len(serials) != want {
+		return fmt.Errorf("%s: %d serial numbers are required, %d given", label, want, len(serials))
+	}
+	return nil
+}
+
+// serialTransition returns the status a serial takes when a posting in warehouseID applies the
+// action to it; serial is nil for a number that is not registered yet
+func serialTransition(serial *models.SerialNumber, warehouseID uint, action string) (string, error) {
+	if action == serialReceive {
+		if serial != nil && serial.Status == SerialInStock {
+			return "", errors.New("is already in stock")
+		}
+		return SerialInStock, nil
+	}
+	if serial == nil {
+		return "", errors.New("is not registered")
+	}
+	if serial.Status != SerialInStock {
+		return "", fmt.Errorf("is %s, not in stock", serial.Status)
+	}
+	if action == serialMoveIn {
+		return SerialInStock, nil
+	}
+	if serial.WarehouseID == nil || *serial.WarehouseID != warehouseID {
+		return "", errors.New("is not in stock in this warehouse")
+	}
+	switch action {
+	case serialIssue:
+		return SerialIssued, nil
+	case serialShip:
+		return SerialShipped, nil
+	case serialMoveOut:
+		return SerialInStock, nil
+	}
+	return "", fmt.Errorf("unknown serial action %q", action)
+}
+
+// postSerials applies a stock ledger entry to the serials it moved and links them to the entry,
+// which is what the serial history is built from. Receipts and the in leg of a transfer record
+// the entry's warehouse, location and batch on the serial.
+func postSerials(tx *gorm.DB, entry *models.StockLedger, serials []string, action string) error {
+	if len(serials) == 0 {
+		return nil
+	}
+	repo := repository.NewSerialRepository(tx)
+	existing, err := repo.FindByNumbers(entry.ItemType, entry.ItemID, serials)
+	if err != nil {
+		return err
+	}
+	for _, number := range serials {
+		serial := existing[number]
+		status, err := serialTransition(serial, entry.WarehouseID, action)
+		if err != nil {
+			return fmt.Errorf("serial %s %v", number, err)
+		}
+		if serial == nil {
+			serial = &models.SerialNumber{ItemType: entry.ItemType, ItemID: entry.ItemID, SerialNumber: number}
+		}
+		serial.Status = status
+		if action == serialReceive || action == serialMoveIn {
+			warehouseID := entry.WarehouseID
+			serial.WarehouseID = &warehouseID
+			serial.WarehouseLocationID = entry.WarehouseLocationID
+			serial.BatchNumber = entry.BatchNumber
+			serial.LotNumber = entry.LotNumber
+		}
+		referenceID := entry.ReferenceID
+		serial.LastReferenceType = entry.ReferenceType
+		serial.LastReferenceID = &referenceID
+		if err := repo.Save(serial); err != nil {
+			return err
+		}
+		if err := repo.Link(entry.ID, serial.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returnSerials marks shipped serials of a product as returned by a customer return order.
+// Returns post no stock, so there is no ledger entry to link.
+func returnSerials(tx *gorm.DB, productID uint, serials []string, returnOrderID uint) error {
+	if len(serials) == 0 {
+		return nil
+	}
+	repo := repository.NewSerialRepository(tx)
+	existing, err := repo.FindByNumbers("finished_product", productID, serials)
+	if err != nil {
+		return err
+	}
+	for _, number := range serials {
+		serial := existing[number]
+		if serial == nil || serial.Status != SerialShipped {
+			return fmt.Errorf("serial %s of product %d was not shipped", number, productID)
+		}
+		serial.Status = SerialReturned
+		serial.LastReferenceType = "ReturnOrder"
+		serial.LastReferenceID = &returnOrderID
+		if err := repo.Save(serial); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serialSlot is one ledger entry of a document that serials are spread over
+type serialSlot struct {
+	Quantity    float64
+	BatchNumber string
+	LotNumber   string
+}
+
+// assignSerials spreads the serials of one item over the entries that post it, giving each entry
+// serials of its own batch/lot first. The serials must add up to the entries' quantities.
+func assignSerials(slots []serialSlot, serials []*models.SerialNumber) ([][]string, error) {
+	out := make([][]string, len(slots))
+	used := make([]bool, len(serials))
+	need := make([]int, len(slots))
+	total := 0
+	for i, slot := range slots {
+		need[i] = int(math.Round(slot.Quantity))
+		total += need[i]
+	}
+	if total != len(serials) {
+		return nil, fmt.Errorf("%d serial numbers are required, %d given", total, len(serials))
+	}
+
+	take := func(i int, match bool) {
+		for j, serial := range serials {
+			if len(out[i]) == need[i] {
+				return
+			}
+			if used[j] {
+				continue
+			}
+			if match && (serial.BatchNumber != slots[i].BatchNumber || serial.LotNumber != slots[i].LotNumber) {
+				continue
+			}
+			used[j] = true
+			out[i] = append(out[i], serial.SerialNumber)
+		}
+	}
+	for i := range slots {
+		take(i, true)
+	}
+	for i := range slots {
+		take(i, false)
+	}
+	return out, nil
+}
+
+// serialTrackedItems returns which of the items are serial-tracked
+func serialTrackedItems(tx *gorm.DB, itemType string, itemIDs []uint) (map[uint]bool, error) {
+	return repository.NewSerialRepository(tx).TrackedItems(itemType, uniqueUints(itemIDs))
+}
+
+// movingSerials returns the serials that move with a quantity of an item taken from a location,
+// see chooseMovingSerials. Items that are not serial-tracked take no serials.
+func movingSerials(tx *gorm.DB, itemType string, itemID, warehouseID uint, locationID *uint, batch, lot string, qty float64, given []string, label string) ([]string, error) {
+	given = cleanSerials(given)
+	tracked, err := serialTrackedItems(tx, itemType, []uint{itemID})
+	if err != nil {
+		return nil, err
+	}
+	if !tracked[itemID] {
+		return nil, checkSerialCount(false, qty, given, label)
+	}
+	held, err := repository.NewSerialRepository(tx).InStockAt(itemType, itemID, warehouseID, locationID, batch, lot)
+	if err != nil {
+		return nil, err
+	}
+	return chooseMovingSerials(held, qty, given, label)
+}
+
+// chooseMovingSerials picks the serials moved with a quantity taken from a location that holds the
+// given in-stock serials. Serials given must be held there; without them the move has to take
+// every serial at the location, as which units move cannot be told otherwise. Stock received
+// before the item was serial-tracked has no serials and moves without any.
+func chooseMovingSerials(held []*models.SerialNumber, qty float64, given []string, label string) ([]string, error) {
+	if len(given) == 0 {
+		if len(held) == 0 {
+			return nil, nil
+		}
+		if math.Abs(qty-float64(len(held))) > qtyEpsilon {
+			return nil, fmt.Errorf("%s: give the serial numbers of the %s units moved, the location holds %d serials", label, formatQty(qty), len(held))
+		}
+		serials := make([]string, 0, len(held))
+		for _, serial := range held {
+			serials = append(serials, serial.SerialNumber)
+		}
+		return serials, nil
+	}
+
+	if err := checkSerialCount(true, qty, given, label); err != nil {
+		return nil, err
+	}
+	at := make(map[string]bool, len(held))
+	for _, serial := range held {
+		at[serial.SerialNumber] = true
+	}
+	for _, number := range given {
+		if !at[number] {
+			return nil, fmt.Errorf("%s: serial %s is not in stock at the source location", label, number)
+		}
+	}
+	return given, nil
+}
+
+// countedSerials returns the serials a counted variance adjusts: the ones held at the location
+// that were not counted when stock is missing, the ones counted that are not held there when
+// stock was found. Without counted serials it returns nil and the posting works them out.
+func countedSerials(tx *gorm.DB, itemType string, itemID, warehouseID uint, locationID *uint, batch, lot string, counted []string, variance float64) ([]string, error) {
+	if counted == nil {
+		return nil, nil
+	}
+	held, err := repository.NewSerialRepository(tx).InStockAt(itemType, itemID, warehouseID, locationID, batch, lot)
+	if err != nil {
+		return nil, err
+	}
+	return serialVariance(held, counted, variance), nil
+}
+
+// serialVariance compares the serials held at a location with the ones counted there
+func serialVariance(held []*models.SerialNumber, counted []string, variance float64) []string {
+	isHeld := make(map[string]bool, len(held))
+	for _, serial := range held {
+		isHeld[serial.SerialNumber] = true
+	}
+	out := []string{}
+	if variance < 0 {
+		isCounted := make(map[string]bool, len(counted))
+		for _, number := range counted {
+			isCounted[number] = true
+		}
+		for _, serial := range held {
+			if !isCounted[serial.SerialNumber] {
+				out = append(out, serial.SerialNumber)
+			}
+		}
+		return out
+	}
+	for _, number := range counted {
+		if !isHeld[number] {
+			out = append(out, number)
+		}
+	}
+	return out
+}
+
+// checkCountedSerials checks the serials counted for a quantity of an item; counting without
+// serials is allowed, the posting then works out which ones are missing
+func checkCountedSerials(tx *gorm.DB, itemType string, itemID uint, qty float64, serials []string, label string) error {
+	if serials == nil {
+		return nil
+	}
+	tracked, err := serialTrackedItems(tx, itemType, []uint{itemID})
+	if err != nil {
+		return err
+	}
+	return checkSerialCount(tracked[itemID], qty, serials, label)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanSerials(t *testing.T) {
+	assert.Nil(t, cleanSerials(nil))
+	assert.Equal(t, []string{}, cleanSerials([]string{"", "  "}), "an empty list is kept to clear the serials")
+	assert.Equal(t, []string{"SN1", "SN2"}, cleanSerials([]string{" SN1", "", "SN2 "}))
+}
+
+func TestCheckSerialCount(t *testing.T) {
+	assert.NoError(t, checkSerialCount(false, 2.5, nil, "line 1"))
+	assert.ErrorContains(t, checkSerialCount(false, 1, []string{"SN1"}, "line 1"), "only recorded for serial-tracked items")
+
+	assert.NoError(t, checkSerialCount(true, 2, []string{"SN1", "SN2"}, "line 1"))
+	assert.NoError(t, checkSerialCount(true, 0, nil, "line 1"))
+	assert.ErrorContains(t, checkSerialCount(true, 1.5, []string{"SN1"}, "line 1"), "not a whole number")
+	assert.ErrorContains(t, checkSerialCount(true, 2, []string{"SN1", "SN1"}, "line 1"), "serial SN1 is listed twice")
+	assert.EqualError(t, checkSerialCount(true, 3, []string{"SN1", "SN2"}, "line 1"), "line 1: 3 serial numbers are required, 2 given")
+}
+
+func TestSerialTransition(t *testing.T) {
+	wh := uint(1)
+	inStock := &models.SerialNumber{Status: SerialInStock, WarehouseID: &wh}
+
+	status, err := serialTransition(nil, 1, serialReceive)
+	require.NoError(t, err)
+	assert.Equal(t, SerialInStock, status)
+	_, err = serialTransition(inStock, 1, serialReceive)
+	assert.ErrorContains(t, err, "already in stock")
+	status, err = serialTransition(&models.SerialNumber{Status: SerialReturned}, 2, serialReceive)
+	require.NoError(t, err)
+	assert.Equal(t, SerialInStock, status, "a returned serial can be received again")
+
+	status, err = serialTransition(inStock, 1, serialIssue)
+	require.NoError(t, err)
+	assert.Equal(t, SerialIssued, status)
+	status, err = serialTransition(inStock, 1, serialShip)
+	require.NoError(t, err)
+	assert.Equal(t, SerialShipped, status)
+	status, err = serialTransition(inStock, 1, serialMoveOut)
+	require.NoError(t, err)
+	assert.Equal(t, SerialInStock, status)
+
+	_, err = serialTransition(inStock, 2, serialShip)
+	assert.ErrorContains(t, err, "not in stock in this warehouse")
+	status, err = serialTransition(inStock, 2, serialMoveIn)
+	require.NoError(t, err, "the in leg of a transfer lands in another warehouse")
+	assert.Equal(t, SerialInStock, status)
+
+	_, err = serialTransition(nil, 1, serialIssue)
+	assert.ErrorContains(t, err, "not registered")
+	_, err = serialTransition(&models.SerialNumber{Status: SerialShipped, WarehouseID: &wh}, 1, serialShip)
+	assert.ErrorContains(t, err, "is shipped, not in stock")
+}
+
+func TestAssignSerials(t *testing.T) {
+	serials := []*models.SerialNumber{
+		{SerialNumber: "A1", BatchNumber: "A"},
+		{SerialNumber: "B1", BatchNumber: "B"},
+		{SerialNumber: "A2", BatchNumber: "A"},
+		{SerialNumber: "C1", BatchNumber: "C"},
+	}
+	slots := []serialSlot{{Quantity: 1, BatchNumber: "B"}, {Quantity: 3, BatchNumber: "A"}}
+
+	assigned, err := assignSerials(slots, serials)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"B1"}, assigned[0])
+	assert.Equal(t, []string{"A1", "A2", "C1"}, assigned[1], "own batch first, then whatever is left")
+
+	_, err = assignSerials(slots, serials[:3])
+	assert.ErrorContains(t, err, "4 serial numbers are required, 3 given")
+}
+
+func TestChooseMovingSerials(t *testing.T) {
+	held := []*models.SerialNumber{{SerialNumber: "SN1"}, {SerialNumber: "SN2"}, {SerialNumber: "SN3"}}
+
+	serials, err := chooseMovingSerials(held, 3, nil, "line 1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SN1", "SN2", "SN3"}, serials, "moving everything takes every serial")
+
+	_, err = chooseMovingSerials(held, 2, nil, "line 1")
+	assert.EqualError(t, err, "line 1: give the serial numbers of the 2 units moved, the location holds 3 serials")
+
+	serials, err = chooseMovingSerials(held, 2, []string{"SN3", "SN1"}, "line 1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SN3", "SN1"}, serials)
+
+	_, err = chooseMovingSerials(held, 1, []string{"SN9"}, "line 1")
+	assert.EqualError(t, err, "line 1: serial SN9 is not in stock at the source location")
+	_, err = chooseMovingSerials(held, 2, []string{"SN1"}, "line 1")
+	assert.ErrorContains(t, err, "2 serial numbers are required, 1 given")
+
+	serials, err = chooseMovingSerials(nil, 5, nil, "line 1")
+	require.NoError(t, err)
+	assert.Nil(t, serials, "stock from before serial tracking moves without serials")
+}
+
+func TestSerialVariance(t *testing.T) {
+	held := []*models.SerialNumber{{SerialNumber: "SN1"}, {SerialNumber: "SN2"}, {SerialNumber: "SN3"}}
+
+	assert.Equal(t, []string{"SN2"}, serialVariance(held, []string{"SN3", "SN1"}, -1), "missing units are the serials not counted")
+	assert.Equal(t, []string{"SN1", "SN2", "SN3"}, serialVariance(held, []string{}, -3))
+	assert.Equal(t, []string{"SN9"}, serialVariance(held, []string{"SN1", "SN2", "SN3", "SN9"}, 1), "found units are the serials not held")
+}
@@ -46,6 +46,14 @@ func NewStockAdjustmentService(
 	}
 }
 
+// generateAdjustmentNumber returns the next ADJ-YYYY-XXXXXX number
+func generateAdjustmentNumber(db *gorm.DB) string {
+	year := time.Now().Format("2006")
+	var count int64
+	db.Model(&models.StockAdjustment{}).Where("adjustment_number LIKE ?", "ADJ-"+year+"-%").Count(&count)
+	return fmt.Sprintf("ADJ-%s-%06d", year, count+1)
+}
+
 func (s *stockAdjustmentService) CreateAdjustment(req *dto.CreateStockAdjustmentRequest, userID uint) (*models.SafeStockAdjustment, error) {
 	// 1. Validate warehouse
 	_, err := s.warehouseRepo.GetByID(req.WarehouseID)
@@ -60,10 +68,7 @@ func (s *stockAdjustmentService) CreateAdjustment(req *dto.CreateStockAdjustment
 	}
 
 	// 3. Generate number: ADJ-YYYY-XXXXXX
-	year := time.Now().Format("2006")
-	var count int64
-	s.db.Model(&models.StockAdjustment{}).Where("adjustment_number LIKE ?", "ADJ-"+year+"-%").Count(&count)
-	adjNumber := fmt.Sprintf("ADJ-%s-%06d", year, count+1)
+	adjNumber := generateAdjustmentNumber(s.db)
 
 	// 4. Create Header
 	sa := &models.StockAdjustment{
@@ -114,6 +119,7 @@ func (s *stockAdjustmentService) CreateAdjustment(req *dto.CreateStockAdjustment
 			PreviousQuantity:   currentQty,
 			NewQuantity:        currentQty + itemReq.AdjustmentQuantity,
 			UnitCost:           unitCost,
+			SerialNumbers:      cleanSerials(itemReq.SerialNumbers),
 			Notes:             itemReq.Notes,
 			CreatedBy:         &userID,
 			UpdatedBy:         &userID,
@@ -176,90 +182,133 @@ func (s *stockAdjustmentService) PostAdjustment(id uint, userID uint) (*models.S
 
 	now := time.Now()
 	err = s.db.Transaction(func(tx *gorm.DB) error {
-		for _, item := range sa.Items {
-			// 1. Update/Create Stock Balance
-			var balance models.StockBalance
-			err := tx.Where("item_type = ? AND item_id = ? AND warehouse_id = ?", item.ItemType, item.ItemID, sa.WarehouseID).
-				Where("COALESCE(warehouse_location_id, 0) = COALESCE(?, 0)", item.WarehouseLocationID).
-				Where("COALESCE(batch_number, '') = COALESCE(?, '')", item.BatchNumber).
-				First(&balance).Error
+		if err := checkWarehouseNotFrozen(tx, sa.WarehouseID); err != nil {
+			return err
+		}
+		return postStockAdjustment(tx, sa, userID, now)
+	})
+
+	if err != nil {
+		return nil, err
+	}
 
+	return s.GetAdjustmentByID(id)
+}
+
+// postStockAdjustment books the adjustment items into stock_balance and stock_ledger
+// and marks the adjustment posted, inside the caller's transaction
+func postStockAdjustment(tx *gorm.DB, sa *models.StockAdjustment, userID uint, now time.Time) error {
+	// Only increases put stock into a location
+	var placements []StoragePlacement
+	for _, item := range sa.Items {
+		placements = addPlacement(placements, item.WarehouseLocationID, item.ItemType, item.ItemID, item.AdjustmentQuantity)
+	}
+	if err := checkStoragePlacements(tx, placements); err != nil {
+		return err
+	}
+
+	for _, item := range sa.Items {
+		// Serials leave stock with a removal and come in with an addition
+		label := fmt.Sprintf("%s %d", item.ItemType, item.ItemID)
+		serials, serialAction := []string(item.SerialNumbers), serialReceive
+		if item.AdjustmentQuantity < 0 {
+			var err error
+			serials, err = movingSerials(tx, item.ItemType, item.ItemID, sa.WarehouseID, item.WarehouseLocationID,
+				item.BatchNumber, item.LotNumber, -item.AdjustmentQuantity, item.SerialNumbers, label)
 			if err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					// Create new balance
-					balance = models.StockBalance{
-						ItemType:            item.ItemType,
-						ItemID:              item.ItemID,
-						WarehouseID:         sa.WarehouseID,
-						WarehouseLocationID: item.WarehouseLocationID,
-						BatchNumber:         item.BatchNumber,
-						LotNumber:           item.LotNumber,
-						Quantity:            item.AdjustmentQuantity,
-						UnitCost:            item.UnitCost,
-						TotalCost:           item.AdjustmentQuantity * item.UnitCost,
-						LastTransactionDate: &now,
-					}
-					if item.ExpiryDate != nil {
-						exp := item.ExpiryDate.Format("2006-01-02")
-						balance.ExpiryDate = &exp
-					}
-					if err := tx.Create(&balance).Error; err != nil {
-						return err
-					}
-				} else {
-					return err
+				return err
+			}
+			serialAction = serialIssue
+		} else {
+			tracked, err := serialTrackedItems(tx, item.ItemType, []uint{item.ItemID})
+			if err != nil {
+				return err
+			}
+			if err := checkSerialCount(tracked[item.ItemID], item.AdjustmentQuantity, serials, label); err != nil {
+				return err
+			}
+		}
+
+		// 1. Update/Create Stock Balance
+		var balance models.StockBalance
+		err := tx.Where("item_type = ? AND item_id = ? AND warehouse_id = ?", item.ItemType, item.ItemID, sa.WarehouseID).
+			Where("COALESCE(warehouse_location_id, 0) = COALESCE(?, 0)", item.WarehouseLocationID).
+			Where("COALESCE(batch_number, '') = COALESCE(?, '')", item.BatchNumber).
+			Where("COALESCE(lot_number, '') = ?", item.LotNumber).
+			First(&balance).Error
+
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// Create new balance
+				balance = models.StockBalance{
+					ItemType:            item.ItemType,
+					ItemID:              item.ItemID,
+					WarehouseID:         sa.WarehouseID,
+					WarehouseLocationID: item.WarehouseLocationID,
+					BatchNumber:         item.BatchNumber,
+					LotNumber:           item.LotNumber,
+					Quantity:            item.AdjustmentQuantity,
+					UnitCost:            item.UnitCost,
+					TotalCost:           item.AdjustmentQuantity * item.UnitCost,
+					LastTransactionDate: &now,
 				}
-			} else {
-				// Update existing balance
-				balance.Quantity += item.AdjustmentQuantity
-				balance.TotalCost = balance.Quantity * balance.UnitCost // Simple adjustment doesn't recalculate unit cost usually
-				balance.LastTransactionDate = &now
-				if err := tx.Save(&balance).Error; err != nil {
+				if item.ExpiryDate != nil {
+					exp := item.ExpiryDate.Format("2006-01-02")
+					balance.ExpiryDate = &exp
+				}
+				if err := tx.Create(&balance).Error; err != nil {
 					return err
 				}
+			} else {
+				return err
 			}
-
-			// 2. Create Ledger Entry
-			ledger := models.StockLedger{
-				TransactionType:   "adjustment",
-				TransactionNumber: sa.AdjustmentNumber,
-				TransactionDate:   now,
-				ItemType:          item.ItemType,
-				ItemID:            item.ItemID,
-				WarehouseID:       sa.WarehouseID,
-				WarehouseLocationID: item.WarehouseLocationID,
-				BatchNumber:       item.BatchNumber,
-				LotNumber:         item.LotNumber,
-				Quantity:          item.AdjustmentQuantity,
-				UnitCost:          item.UnitCost,
-				TotalCost:         item.AdjustmentQuantity * item.UnitCost,
-				BalanceQuantity:   balance.Quantity,
-				ReferenceType:     "Adjustment",
-				ReferenceID:       sa.ID,
-				CreatedBy:         &userID,
-			}
-			if err := tx.Create(&ledger).Error; err != nil {
+		} else {
+			// Update existing balance
+			balance.Quantity += item.AdjustmentQuantity
+			balance.TotalCost = balance.Quantity * balance.UnitCost // Simple adjustment doesn't recalculate unit cost usually
+			balance.LastTransactionDate = &now
+			if err := tx.Save(&balance).Error; err != nil {
 				return err
 			}
 		}
 
-		// Update Header
-		sa.Status = "posted"
-		sa.IsPosted = true
-		sa.PostedBy = &userID
-		sa.PostedAt = &now
-		if err := tx.Save(sa).Error; err != nil {
+		// 2. Create Ledger Entry
+		ledger := models.StockLedger{
+			TransactionType:   "adjustment",
+			TransactionNumber: sa.AdjustmentNumber,
+			TransactionDate:   now,
+			ItemType:          item.ItemType,
+			ItemID:            item.ItemID,
+			WarehouseID:       sa.WarehouseID,
+			WarehouseLocationID: item.WarehouseLocationID,
+			BatchNumber:       item.BatchNumber,
+			LotNumber:         item.LotNumber,
+			Quantity:          item.AdjustmentQuantity,
+			UnitCost:          item.UnitCost,
+			TotalCost:         item.AdjustmentQuantity * item.UnitCost,
+			BalanceQuantity:   balance.Quantity,
+			ReferenceType:     "Adjustment",
+			ReferenceID:       sa.ID,
+			CreatedBy:         &userID,
+		}
+		if err := tx.Create(&ledger).Error; err != nil {
 			return err
 		}
+		if err := postSerials(tx, &ledger, serials, serialAction); err != nil {
+			return err
+		}
+	}
 
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
+	// Update Header
+	sa.Status = "posted"
+	sa.IsPosted = true
+	sa.PostedBy = &userID
+	sa.PostedAt = &now
+	if err := tx.Save(sa).Error; err != nil {
+		return err
 	}
 
-	return s.GetAdjustmentByID(id)
+	return nil
 }
 
 func (s *stockAdjustmentService) CancelAdjustment(id uint, userID uint) (*models.SafeStockAdjustment, error) {
@@ -321,6 +370,7 @@ func (s *stockAdjustmentService) MapToSafe(sa *models.StockAdjustment) *models.S
 				PreviousQuantity:   item.PreviousQuantity,
 				NewQuantity:        item.NewQuantity,
 				UnitCost:           item.UnitCost,
+				SerialNumbers:      item.SerialNumbers,
 				Notes:             item.Notes,
 			}
 			if item.Location != nil {
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// stockPosting is one ledger entry moving an item, batch and lot in or out of a location, with the
+// document it is booked under. A negative quantity takes stock out of the balance, a positive one
+// puts it in at UnitCost, averaged with whatever is already there.
+type stockPosting struct {
+	ItemType    string
+	ItemID      uint
+	BatchNumber string
+	LotNumber   string
+	WarehouseID uint
+	LocationID  *uint
+	Quantity    float64
+	// UnitCost is the cost of the stock put in; stock taken out moves at the cost of the balance
+	// unless one is given
+	UnitCost float64
+	// Origin gives its manufacture and expiry dates to a balance the posting creates
+	Origin *models.StockBalance
+
+	TxType   string
+	TxNumber string
+	RefType  string
+	RefID    uint
+	Notes    string
+
+	// Allocated holds the pick list allocations of the warehouse: only stock that is neither
+	// reserved nor allocated can be taken out
+	Allocated    map[pickKey]float64
+	Serials      []string
+	SerialAction string
+}
+
+// findStockBalance returns the balance of an item, batch and lot at a location, nil when there is none
+func findStockBalance(tx *gorm.DB, itemType string, itemID, warehouseID uint, locationID *uint, batch, lot string) (*models.StockBalance, error) {
+	var balances []*models.StockBalance
+	err := tx.Where("item_type = ? AND item_id = ? AND warehouse_id = ?", itemType, itemID, warehouseID).
+		Where("COALESCE(warehouse_location_id, 0) = COALESCE(?, 0)", locationID).
+		Where("COALESCE(batch_number, '') = ? AND COALESCE(lot_number, '') = ?", batch, lot).
+		Limit(1).Find(&balances).Error
+	if err != nil || len(balances) == 0 {
+		return nil, err
+	}
+	return balances[0], nil
+}
+
+// postStock applies a posting to its balance, writes the ledger entry and moves the serials with
+// it. It returns the balance after the posting; its unit cost is the cost the stock moved at.
+func postStock(tx *gorm.DB, p stockPosting, userID uint, now time.Time) (*models.StockBalance, error) {
+	balance, err := findStockBalance(tx, p.ItemType, p.ItemID, p.WarehouseID, p.LocationID, p.BatchNumber, p.LotNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Quantity < 0 {
+		if balance == nil {
+			return nil, errors.New("no stock at the source location")
+		}
+		if movable := movableQuantity(balance, p.Allocated); movable < -p.Quantity-qtyEpsilon {
+			return nil, fmt.Errorf("only %s at the source location is not reserved or allocated to pick lists", formatQty(movable))
+		}
+		if p.UnitCost == 0 {
+			p.UnitCost = balance.UnitCost
+		}
+		balance.Quantity = roundQty(balance.Quantity + p.Quantity)
+		balance.TotalCost = roundMoney(balance.Quantity * balance.UnitCost)
+	} else if balance == nil {
+		balance = &models.StockBalance{
+			ItemType:            p.ItemType,
+			ItemID:              p.ItemID,
+			WarehouseID:         p.WarehouseID,
+			WarehouseLocationID: p.LocationID,
+			BatchNumber:         p.BatchNumber,
+			LotNumber:           p.LotNumber,
+			Quantity:            p.Quantity,
+			UnitCost:            p.UnitCost,
+			TotalCost:           roundMoney(p.Quantity * p.UnitCost),
+		}
+		if p.Origin != nil {
+			balance.ManufactureDate = p.Origin.ManufactureDate
+			balance.ExpiryDate = p.Origin.ExpiryDate
+		}
+	} else {
+		balance.TotalCost = roundMoney(balance.TotalCost + p.Quantity*p.UnitCost)
+		balance.Quantity = roundQty(balance.Quantity + p.Quantity)
+		if balance.Quantity > 0 {
+			balance.UnitCost = balance.TotalCost / balance.Quantity
+		}
+	}
+	balance.LastTransactionDate = &now
+	if err := tx.Omit("Warehouse", "WarehouseLocation").Save(balance).Error; err != nil {
+		return nil, err
+	}
+
+	entry := models.StockLedger{
+		TransactionType:     p.TxType,
+		TransactionNumber:   p.TxNumber,
+		TransactionDate:     now,
+		ItemType:            p.ItemType,
+		ItemID:              p.ItemID,
+		WarehouseID:         p.WarehouseID,
+		WarehouseLocationID: p.LocationID,
+		BatchNumber:         p.BatchNumber,
+		LotNumber:           p.LotNumber,
+		ExpiryDate:          balance.ExpiryDate,
+		Quantity:            p.Quantity,
+		UnitCost:            p.UnitCost,
+		TotalCost:           roundMoney(p.Quantity * p.UnitCost),
+		BalanceQuantity:     balance.Quantity,
+		ReferenceType:       p.RefType,
+		ReferenceID:         p.RefID,
+		Notes:               p.Notes,
+		CreatedBy:           &userID,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return nil, err
+	}
+	if err := postSerials(tx, &entry, p.Serials, p.SerialAction); err != nil {
+		return nil, err
+	}
+	if p.Quantity < 0 {
+		// report the cost the stock left at, which a given cost may differ from
+		moved := *balance
+		moved.UnitCost = p.UnitCost
+		return &moved, nil
+	}
+	return balance, nil
+}
+
+// moveStock takes p.Quantity out of p's location and puts it in at the target location, possibly
+// in another warehouse, at the cost and with the dates of the source: two ledger entries under one
+// document, the in entry booked as inTxType (default p.TxType). Serials move along. It returns
+// the source balance after the move.
+func moveStock(tx *gorm.DB, p stockPosting, toWarehouseID uint, toLocationID *uint, inTxType string, userID uint, now time.Time) (*models.StockBalance, error) {
+	quantity := p.Quantity
+	out := p
+	out.Quantity = -quantity
+	out.SerialAction = serialMoveOut
+	source, err := postStock(tx, out, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	in := p
+	in.WarehouseID = toWarehouseID
+	in.LocationID = toLocationID
+	in.Quantity = quantity
+	in.UnitCost = source.UnitCost
+	in.Origin = source
+	in.Allocated = nil
+	in.SerialAction = serialMoveIn
+	if inTxType != "" {
+		in.TxType = inTxType
+	}
+	if _, err := postStock(tx, in, userID, now); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
@@ -0,0 +1,512 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Reservation statuses and the references that can hold stock
+const (
+	ReservationActive    = "active"
+	ReservationFulfilled = "fulfilled"
+	ReservationCancelled = "cancelled"
+
+	ReservationForProductionPlan = "production_plan"
+	ReservationForDeliveryOrder  = "delivery_order"
+	ReservationForCustomerOrder  = "customer_order"
+)
+
+// StockReservationService manages holds on stock: manual reservations for customer and delivery
+// orders, moving them to other stock, giving them back and comparing reserved with available stock
+type StockReservationService interface {
+	List(filters map[string]interface{}, offset, limit int) ([]*models.StockReservation, int64, error)
+	Get(id uint) (*models.StockReservation, error)
+	Create(req *dto.CreateReservationRequest, userID uint, username string) ([]*models.StockReservation, error)
+	Reallocate(id uint, req *dto.ReallocateReservationRequest, userID uint, username string) (*models.StockReservation, error)
+	Release(id uint, req *dto.ReleaseReservationRequest, userID uint, username string) (*models.StockReservation, error)
+	Report(filter *dto.ReservationReportFilterRequest) ([]dto.ReservationReportRow, error)
+}
+
+type stockReservationService struct {
+	db       *gorm.DB
+	repo     repository.StockReservationRepository
+	auditSvc AuditLogService
+}
+
+func NewStockReservationService(db *gorm.DB, repo repository.StockReservationRepository, auditSvc AuditLogService) StockReservationService {
+	return &stockReservationService{db: db, repo: repo, auditSvc: auditSvc}
+}
+
+func (s *stockReservationService) List(filters map[string]interface{}, offset, limit int) ([]*models.StockReservation, int64, error) {
+	return s.repo.List(filters, offset, limit)
+}
+
+func (s *stockReservationService) Get(id uint) (*models.StockReservation, error) {
+	res, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("reservation not found")
+	}
+	return res, nil
+}
+
+func (s *stockReservationService) Create(req *dto.CreateReservationRequest, userID uint, username string) ([]*models.StockReservation, error) {
+	refNumber := strings.TrimSpace(req.ReferenceNumber)
+	var created []*models.StockReservation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		switch req.ReferenceType {
+		case ReservationForCustomerOrder:
+			if refNumber == "" {
+				return errors.New("a customer order reservation needs the order number")
+			}
+		case ReservationForDeliveryOrder:
+			do, err := repository.NewDeliveryOrderRepository(tx).GetByID(req.ReferenceID)
+			if err != nil {
+				return fmt.Errorf("delivery order %d not found", req.ReferenceID)
+			}
+			if do.IsPosted || do.Status == "cancelled" {
+				return fmt.Errorf("delivery order %s is %s", do.DONumber, do.Status)
+			}
+			if req.ItemType != "finished_product" || !deliveryOrderHasProduct(do, req.ItemID) {
+				return fmt.Errorf("delivery order %s has no item for this product", do.DONumber)
+			}
+			if do.WarehouseID != req.WarehouseID {
+				return fmt.Errorf("delivery order %s ships from another warehouse", do.DONumber)
+			}
+			if refNumber == "" {
+				refNumber = do.DONumber
+			}
+		}
+
+		balances, err := repository.NewStockReservationRepository(tx).ReservableBalances(req.ItemType, req.ItemID, req.WarehouseID)
+		if err != nil {
+			return err
+		}
+		balances = matchingBalances(balances, req.LocationID, req.BatchNumber, req.LotNumber)
+		available := make([]float64, len(balances))
+		for i, b := range balances {
+			available[i] = b.Quantity - b.ReservedQuantity
+		}
+		amounts, err := allocateReservation(available, req.Quantity)
+		if err != nil {
+			return err
+		}
+
+		for i, qty := range amounts {
+			if qty <= qtyEpsilon {
+				continue
+			}
+			b := balances[i]
+			res := &models.StockReservation{
+				ItemType:            req.ItemType,
+				ItemID:              req.ItemID,
+				WarehouseID:         req.WarehouseID,
+				WarehouseLocationID: b.WarehouseLocationID,
+				BatchNumber:         b.BatchNumber,
+				LotNumber:           b.LotNumber,
+				ReservedQuantity:    qty,
+				ReferenceType:       req.ReferenceType,
+				ReferenceID:         req.ReferenceID,
+				ReferenceNumber:     refNumber,
+				Status:              ReservationActive,
+				ExpiresAt:           req.ExpiresAt,
+				Notes:               req.Notes,
+				CreatedBy:           &userID,
+				UpdatedBy:           &userID,
+			}
+			if err := reserveBalance(tx, b.ID, qty); err != nil {
+				return err
+			}
+			if err := repository.NewStockReservationRepository(tx).Create(res); err != nil {
+				return err
+			}
+			created = append(created, res)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, res := range created {
+		_ = s.auditSvc.Log("stock_reservations", "CREATE", int64(res.ID), int64(userID), username, nil, res)
+	}
+	return created, nil
+}
+
+func (s *stockReservationService) Reallocate(id uint, req *dto.ReallocateReservationRequest, userID uint, username string) (*models.StockReservation, error) {
+	res, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("reservation not found")
+	}
+	if res.Status != ReservationActive {
+		return nil, fmt.Errorf("reservation is %s, only active reservations can be re-allocated", res.Status)
+	}
+	if req.LocationID == nil && req.BatchNumber == "" && req.LotNumber == "" {
+		return nil, errors.New("give the location and/or batch to move the reservation to")
+	}
+	open := roundQty(res.ReservedQuantity - res.FulfilledQuantity)
+	qty := open
+	if req.Quantity != nil {
+		qty = roundQty(*req.Quantity)
+	}
+	if qty > open+qtyEpsilon {
+		return nil, fmt.Errorf("the reservation only holds %s", formatQty(open))
+	}
+
+	old := *res
+	var moved *models.StockReservation
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		repo := repository.NewStockReservationRepository(tx)
+		balances, err := repo.ReservableBalances(res.ItemType, res.ItemID, res.WarehouseID)
+		if err != nil {
+			return err
+		}
+		var target *models.StockBalance
+		for _, b := range matchingBalances(balances, req.LocationID, req.BatchNumber, req.LotNumber) {
+			if sameReservationStock(res, b) {
+				continue
+			}
+			if b.Quantity-b.ReservedQuantity >= qty-qtyEpsilon {
+				target = b
+				break
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("no other stock with %s available at the given location/batch", formatQty(qty))
+		}
+
+		if err := adjustReservedStock(tx, res, -qty); err != nil {
+			return err
+		}
+		if err := reserveBalance(tx, target.ID, qty); err != nil {
+			return err
+		}
+
+		// Move the whole reservation when nothing of it stays behind, otherwise split the moved part off
+		if qty >= open-qtyEpsilon && res.FulfilledQuantity <= qtyEpsilon {
+			moved = res
+		} else {
+			res.ReservedQuantity = roundQty(res.ReservedQuantity - qty)
+			if res.ReservedQuantity-res.FulfilledQuantity <= qtyEpsilon {
+				res.Status = ReservationFulfilled
+			}
+			res.UpdatedBy = &userID
+			if err := repo.Update(res); err != nil {
+				return err
+			}
+			copied := *res
+			moved = &copied
+			moved.ID = 0
+			moved.CreatedAt = time.Time{}
+			moved.FulfilledQuantity = 0
+			moved.ReleasedQuantity = 0
+			moved.Status = ReservationActive
+			moved.CreatedBy = &userID
+		}
+		moved.ReservedQuantity = qty
+		moved.WarehouseLocationID = target.WarehouseLocationID
+		moved.BatchNumber = target.BatchNumber
+		moved.LotNumber = target.LotNumber
+		moved.Warehouse, moved.WarehouseLocation = nil, nil
+		if req.Notes != "" {
+			moved.Notes = req.Notes
+		}
+		moved.UpdatedBy = &userID
+		if moved.ID == 0 {
+			return repo.Create(moved)
+		}
+		return repo.Update(moved)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("stock_reservations", "REALLOCATE", int64(id), int64(userID), username, old, moved)
+	return s.repo.GetByID(moved.ID)
+}
+
+func (s *stockReservationService) Release(id uint, req *dto.ReleaseReservationRequest, userID uint, username string) (*models.StockReservation, error) {
+	res, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("reservation not found")
+	}
+	if res.Status != ReservationActive {
+		return nil, fmt.Errorf("reservation is %s, only active reservations can be released", res.Status)
+	}
+	qty := roundQty(res.ReservedQuantity - res.FulfilledQuantity)
+	if req.Quantity != nil {
+		qty = roundQty(*req.Quantity)
+	}
+
+	old := *res
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := releaseReservation(res, qty); err != nil {
+			return err
+		}
+		if err := adjustReservedStock(tx, res, -qty); err != nil {
+			return err
+		}
+		if req.Reason != "" {
+			res.Notes = strings.TrimSpace(res.Notes + "\n" + "Released " + formatQty(qty) + ": " + req.Reason)
+		}
+		res.UpdatedBy = &userID
+		return repository.NewStockReservationRepository(tx).Update(res)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log("stock_reservations", "RELEASE", int64(id), int64(userID), username, old, res)
+	return s.repo.GetByID(id)
+}
+
+func (s *stockReservationService) Report(filter *dto.ReservationReportFilterRequest) ([]dto.ReservationReportRow, error) {
+	filters := map[string]interface{}{
+		"item_type":    filter.ItemType,
+		"item_id":      filter.ItemID,
+		"warehouse_id": filter.WarehouseID,
+	}
+	stock, err := s.repo.StockRows(filters)
+	if err != nil {
+		return nil, err
+	}
+	open, err := s.repo.OpenRows(filters)
+	if err != nil {
+		return nil, err
+	}
+	return reservationReport(stock, open, filter.OnlyReserved), nil
+}
+
+// matchingBalances keeps the balances at the given location, batch and lot; empty values match any
+func matchingBalances(balances []*models.StockBalance, locationID *uint, batch, lot string) []*models.StockBalance {
+	out := make([]*models.StockBalance, 0, len(balances))
+	for _, b := range balances {
+		if locationID != nil && (b.WarehouseLocationID == nil || *b.WarehouseLocationID != *locationID) {
+			continue
+		}
+		if batch != "" && b.BatchNumber != batch {
+			continue
+		}
+		if lot != "" && b.LotNumber != lot {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// sameReservationStock tells whether the balance is the stock the reservation already holds
+func sameReservationStock(res *models.StockReservation, b *models.StockBalance) bool {
+	sameLocation := (res.WarehouseLocationID == nil && b.WarehouseLocationID == nil) ||
+		(res.WarehouseLocationID != nil && b.WarehouseLocationID != nil && *res.WarehouseLocationID == *b.WarehouseLocationID)
+	return sameLocation && res.BatchNumber == b.BatchNumber && res.LotNumber == b.LotNumber
+}
+
+// allocateReservation takes qty from the available quantities in order and returns how much is
+// taken from each; it fails when they do not add up to qty
+func allocateReservation(available []float64, qty float64) ([]float64, error) {
+	amounts := make([]float64, len(available))
+	remaining := roundQty(qty)
+	for i, a := range available {
+		if remaining <= qtyEpsilon {
+			break
+		}
+		if a <= qtyEpsilon {
+			continue
+		}
+		take := a
+		if take > remaining {
+			take = remaining
+		}
+		amounts[i] = roundQty(take)
+		remaining = roundQty(remaining - take)
+	}
+	if remaining > qtyEpsilon {
+		return nil, fmt.Errorf("only %s available to reserve, %s requested", formatQty(roundQty(qty-remaining)), formatQty(qty))
+	}
+	return amounts, nil
+}
+
+// releaseReservation gives back qty of what a reservation still holds. A reservation with
+// nothing left to hold is fulfilled when some of it was used, cancelled otherwise.
+func releaseReservation(res *models.StockReservation, qty float64) error {
+	open := roundQty(res.ReservedQuantity - res.FulfilledQuantity)
+	if qty > open+qtyEpsilon {
+		return fmt.Errorf("the reservation only holds %s", formatQty(open))
+	}
+	res.ReservedQuantity = roundQty(res.ReservedQuantity - qty)
+	res.ReleasedQuantity = roundQty(res.ReleasedQuantity + qty)
+	if res.ReservedQuantity-res.FulfilledQuantity <= qtyEpsilon {
+		if res.FulfilledQuantity > qtyEpsilon {
+			res.Status = ReservationFulfilled
+		} else {
+			res.Status = ReservationCancelled
+		}
+	}
+	return nil
+}
+
+// reserveBalance adds qty to the reserved quantity of a balance, provided that much is still free
+func reserveBalance(tx *gorm.DB, balanceID uint, qty float64) error {
+	result := tx.Model(&models.StockBalance{}).
+		Where("id = ? AND quantity - COALESCE(reserved_quantity, 0) >= ?", balanceID, qty-qtyEpsilon).
+		Update("reserved_quantity", gorm.Expr("COALESCE(reserved_quantity, 0) + ?", qty))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("the stock was taken in the meantime, try again")
+	}
+	return nil
+}
+
+// adjustReservedStock changes the reserved quantity of the balance a reservation holds, never
+// below zero: the balance may have been counted or moved since
+func adjustReservedStock(tx *gorm.DB, res *models.StockReservation, delta float64) error {
+	query := tx.Model(&models.StockBalance{}).
+		Where("item_type = ? AND item_id = ? AND warehouse_id = ? AND COALESCE(batch_number, '') = ? AND COALESCE(lot_number, '') = ?",
+			res.ItemType, res.ItemID, res.WarehouseID, res.BatchNumber, res.LotNumber)
+	if res.WarehouseLocationID != nil {
+		query = query.Where("warehouse_location_id = ?", *res.WarehouseLocationID)
+	} else {
+		query = query.Where("warehouse_location_id IS NULL")
+	}
+	return query.Update("reserved_quantity", gorm.Expr("GREATEST(COALESCE(reserved_quantity, 0) + ?, 0)", delta)).Error
+}
+
+// closeReservations ends the active reservations of a reference and gives their stock back.
+// fulfilled is how much of each item was used (e.g. shipped), which is booked on the
+// reservations in order; a nil map cancels them.
+func closeReservations(tx *gorm.DB, refType string, refID uint, fulfilled map[uint]float64) error {
+	repo := repository.NewStockReservationRepository(tx)
+	reservations, err := repo.ActiveForReferences(refType, []uint{refID})
+	if err != nil {
+		return err
+	}
+	for _, res := range reservations {
+		open := roundQty(res.ReservedQuantity - res.FulfilledQuantity)
+		if err := adjustReservedStock(tx, res, -open); err != nil {
+			return err
+		}
+		used := fulfilled[res.ItemID]
+		if used > open {
+			used = open
+		}
+		if used > qtyEpsilon {
+			res.FulfilledQuantity = roundQty(res.FulfilledQuantity + used)
+			fulfilled[res.ItemID] = roundQty(fulfilled[res.ItemID] - used)
+		}
+		if err := releaseReservation(res, roundQty(open-used)); err != nil {
+			return err
+		}
+		if res.Status == ReservationActive {
+			res.Status = ReservationFulfilled
+		}
+		if err := repo.Update(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliveryReservationsByKey sums what the active reservations of the delivery orders hold per
+// product, location, batch and lot
+func deliveryReservationsByKey(reservations []*models.StockReservation) map[pickKey]float64 {
+	held := make(map[pickKey]float64, len(reservations))
+	for _, res := range reservations {
+		if res.ItemType != "finished_product" {
+			continue
+		}
+		held[newPickKey(res.ItemID, res.WarehouseLocationID, res.BatchNumber, res.LotNumber)] += roundQty(res.ReservedQuantity - res.FulfilledQuantity)
+	}
+	return held
+}
+
+// deliveryOrderHasProduct tells whether a delivery order ships the product
+func deliveryOrderHasProduct(do *models.DeliveryOrder, productID uint) bool {
+	for _, item := range do.Items {
+		if item.FinishedProductID == productID {
+			return true
+		}
+	}
+	return false
+}
+
+// reservationReport puts the stock of each item and warehouse next to its open reservations
+func reservationReport(stock []repository.ReservationStockRow, open []repository.OpenReservationRow, onlyReserved bool) []dto.ReservationReportRow {
+	type key struct {
+		itemType    string
+		itemID      uint
+		warehouseID uint
+	}
+	openByKey := make(map[key]repository.OpenReservationRow, len(open))
+	for _, o := range open {
+		openByKey[key{o.ItemType, o.ItemID, o.WarehouseID}] = o
+	}
+
+	report := make([]dto.ReservationReportRow, 0, len(stock))
+	seen := make(map[key]bool, len(stock))
+	for _, row := range stock {
+		k := key{row.ItemType, row.ItemID, row.WarehouseID}
+		seen[k] = true
+		o := openByKey[k]
+		if onlyReserved && o.Count == 0 && row.Reserved <= qtyEpsilon {
+			continue
+		}
+		available := roundQty(row.OnHand - row.Quarantined - row.Reserved)
+		if available < 0 {
+			available = 0
+		}
+		r := dto.ReservationReportRow{
+			ItemType:         row.ItemType,
+			ItemID:           row.ItemID,
+			ItemCode:         row.ItemCode,
+			ItemName:         row.ItemName,
+			WarehouseID:      row.WarehouseID,
+			WarehouseName:    row.WarehouseName,
+			OnHand:           roundQty(row.OnHand),
+			InTransit:        roundQty(row.InTransit),
+			Quarantined:      roundQty(row.Quarantined),
+			Reserved:         roundQty(row.Reserved),
+			Available:        available,
+			OpenReservations: o.Count,
+		}
+		if unbacked := roundQty(o.Open - row.Reserved); unbacked > qtyEpsilon {
+			r.Unbacked = unbacked
+		}
+		report = append(report, r)
+	}
+
+	// Reservations on stock that is gone altogether
+	var orphans []dto.ReservationReportRow
+	for k, o := range openByKey {
+		if seen[k] || o.Open <= qtyEpsilon {
+			continue
+		}
+		orphans = append(orphans, dto.ReservationReportRow{
+			ItemType:         k.itemType,
+			ItemID:           k.itemID,
+			WarehouseID:      k.warehouseID,
+			OpenReservations: o.Count,
+			Unbacked:         roundQty(o.Open),
+		})
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].ItemType != orphans[j].ItemType {
+			return orphans[i].ItemType < orphans[j].ItemType
+		}
+		if orphans[i].ItemID != orphans[j].ItemID {
+			return orphans[i].ItemID < orphans[j].ItemID
+		}
+		return orphans[i].WarehouseID < orphans[j].WarehouseID
+	})
+	return append(report, orphans...)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocateReservation(t *testing.T) {
+	amounts, err := allocateReservation([]float64{4, 0, 10, 5}, 9)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{4, 0, 5, 0}, amounts, "FEFO order is kept and empty balances are skipped")
+
+	amounts, err = allocateReservation([]float64{4}, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{4}, amounts)
+
+	_, err = allocateReservation([]float64{2, 1.5}, 5)
+	assert.EqualError(t, err, "only 3.5 available to reserve, 5 requested")
+}
+
+func TestMatchingBalances(t *testing.T) {
+	loc1, loc2 := uint(1), uint(2)
+	balances := []*models.StockBalance{
+		{ID: 1, WarehouseLocationID: &loc1, BatchNumber: "B1"},
+		{ID: 2, WarehouseLocationID: &loc2, BatchNumber: "B1"},
+		{ID: 3, WarehouseLocationID: &loc2, BatchNumber: "B2", LotNumber: "L1"},
+		{ID: 4, BatchNumber: "B2"},
+	}
+	ids := func(bs []*models.StockBalance) []uint {
+		var out []uint
+		for _, b := range bs {
+			out = append(out, b.ID)
+		}
+		return out
+	}
+	assert.Equal(t, []uint{1, 2, 3, 4}, ids(matchingBalances(balances, nil, "", "")))
+	assert.Equal(t, []uint{2, 3}, ids(matchingBalances(balances, &loc2, "", "")))
+	assert.Equal(t, []uint{3, 4}, ids(matchingBalances(balances, nil, "B2", "")))
+	assert.Equal(t, []uint{3}, ids(matchingBalances(balances, &loc2, "B2", "L1")))
+
+	res := &models.StockReservation{WarehouseLocationID: &loc2, BatchNumber: "B1"}
+	assert.True(t, sameReservationStock(res, balances[1]))
+	assert.False(t, sameReservationStock(res, balances[0]))
+	assert.False(t, sameReservationStock(&models.StockReservation{BatchNumber: "B2"}, balances[2]))
+	assert.True(t, sameReservationStock(&models.StockReservation{BatchNumber: "B2"}, balances[3]))
+}
+
+func TestReleaseReservation(t *testing.T) {
+	res := &models.StockReservation{ReservedQuantity: 10, Status: ReservationActive}
+	require.NoError(t, releaseReservation(res, 4))
+	assert.Equal(t, 6.0, res.ReservedQuantity)
+	assert.Equal(t, 4.0, res.ReleasedQuantity)
+	assert.Equal(t, ReservationActive, res.Status)
+
+	assert.EqualError(t, releaseReservation(res, 7), "the reservation only holds 6")
+
+	require.NoError(t, releaseReservation(res, 6))
+	assert.Equal(t, ReservationCancelled, res.Status, "nothing of it was used")
+
+	res = &models.StockReservation{ReservedQuantity: 10, FulfilledQuantity: 3, Status: ReservationActive}
+	require.NoError(t, releaseReservation(res, 7))
+	assert.Equal(t, 3.0, res.ReservedQuantity)
+	assert.Equal(t, 7.0, res.ReleasedQuantity)
+	assert.Equal(t, ReservationFulfilled, res.Status, "the used part stays fulfilled")
+}
+
+func TestDeliveryReservationsByKey(t *testing.T) {
+	loc := uint(5)
+	held := deliveryReservationsByKey([]*models.StockReservation{
+		{ItemType: "finished_product", ItemID: 1, WarehouseLocationID: &loc, BatchNumber: "B1", ReservedQuantity: 4},
+		{ItemType: "finished_product", ItemID: 1, WarehouseLocationID: &loc, BatchNumber: "B1", ReservedQuantity: 3, FulfilledQuantity: 1},
+		{ItemType: "material", ItemID: 1, ReservedQuantity: 9},
+	})
+	assert.Equal(t, map[pickKey]float64{newPickKey(1, &loc, "B1", ""): 6}, held)
+}
+
+func TestReservationReport(t *testing.T) {
+	stock := []repository.ReservationStockRow{
+		{ItemType: "finished_product", ItemID: 1, ItemCode: "FP1", WarehouseID: 1, OnHand: 20, InTransit: 5, Quarantined: 2, Reserved: 8},
+		{ItemType: "finished_product", ItemID: 2, ItemCode: "FP2", WarehouseID: 1, OnHand: 3},
+		{ItemType: "material", ItemID: 7, ItemCode: "M7", WarehouseID: 1, OnHand: 4, Reserved: 5},
+	}
+	open := []repository.OpenReservationRow{
+		{ItemType: "finished_product", ItemID: 1, WarehouseID: 1, Open: 8, Count: 2},
+		{ItemType: "material", ItemID: 7, WarehouseID: 1, Open: 6, Count: 1},
+		{ItemType: "finished_product", ItemID: 9, WarehouseID: 2, Open: 3, Count: 1},
+	}
+
+	report := reservationReport(stock, open, false)
+	require.Len(t, report, 4)
+	assert.Equal(t, 10.0, report[0].Available, "on hand less quarantine and reserved")
+	assert.Equal(t, 2, report[0].OpenReservations)
+	assert.Zero(t, report[0].Unbacked)
+	assert.Equal(t, 3.0, report[1].Available)
+	assert.Zero(t, report[2].Available, "over-reserved stock shows nothing available")
+	assert.Equal(t, 1.0, report[2].Unbacked)
+	assert.Equal(t, uint(9), report[3].ItemID, "reservations on stock that is gone are listed")
+	assert.Equal(t, 3.0, report[3].Unbacked)
+
+	report = reservationReport(stock, open, true)
+	require.Len(t, report, 3)
+	assert.Equal(t, "FP1", report[0].ItemCode)
+	assert.Equal(t, "M7", report[1].ItemCode)
+}
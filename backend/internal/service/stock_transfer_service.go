@@ -9,13 +9,23 @@ import (
 	"github.com/VyVy-ERP/warehouse-backend/internal/models"
 	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// LocationTypeInTransit marks the virtual location of a warehouse that holds stock shipped to it
+// and not yet received; it is never picked, put away into or counted
+const LocationTypeInTransit = "in_transit"
+
+// StockTransferService moves stock between warehouses, either in one step (PostTransfer) or in
+// two: ShipTransfer into the destination's in-transit location and ReceiveTransfer out of it
 type StockTransferService interface {
 	CreateTransfer(req *dto.CreateStockTransferRequest, userID uint) (*models.SafeStockTransfer, error)
 	GetTransferByID(id uint) (*models.SafeStockTransfer, error)
 	ListTransfers(filter *dto.StockTransferFilterRequest) ([]*models.SafeStockTransfer, int64, error)
 	PostTransfer(id uint, userID uint) (*models.SafeStockTransfer, error)
+	ShipTransfer(id uint, req *dto.ShipStockTransferRequest, userID uint) (*models.SafeStockTransfer, error)
+	ReceiveTransfer(id uint, req *dto.ReceiveStockTransferRequest, userID uint) (*models.SafeStockTransfer, error)
+	InTransitReport(filter *dto.InTransitFilterRequest) ([]dto.InTransitTransfer, error)
 	CancelTransfer(id uint, userID uint) (*models.SafeStockTransfer, error)
 }
 
@@ -42,7 +52,7 @@ func NewStockTransferService(
 
 func (s *stockTransferService) CreateTransfer(req *dto.CreateStockTransferRequest, userID uint) (*models.SafeStockTransfer, error) {
 	if req.FromWarehouseID == req.ToWarehouseID {
-		return nil, errors.New("source and destination warehouse cannot be the same")
+		return nil, errors.New("source and destination warehouse cannot be the same; use a location move within a warehouse")
 	}
 
 	// 1. Validate warehouses
@@ -97,6 +107,7 @@ func (s *stockTransferService) CreateTransfer(req *dto.CreateStockTransferReques
 			LotNumber:      itemReq.LotNumber,
 			Quantity:       itemReq.Quantity,
 			UnitCost:       balance.UnitCost,
+			SerialNumbers:  cleanSerials(itemReq.SerialNumbers),
 			Notes:          itemReq.Notes,
 			CreatedBy:      &userID,
 			UpdatedBy:      &userID,
@@ -156,111 +167,55 @@ func (s *stockTransferService) PostTransfer(id uint, userID uint) (*models.SafeS
 	if st.IsPosted {
 		return nil, errors.New("transfer is already posted")
 	}
+	if st.Status == "shipped" || st.Status == "cancelled" {
+		return nil, fmt.Errorf("transfer is %s and cannot be posted", st.Status)
+	}
 
 	now := time.Now()
 	err = s.db.Transaction(func(tx *gorm.DB) error {
-		for _, item := range st.Items {
-			// --- SOURCE WAREHOUSE: TRANSIT OUT ---
-			var sourceBalance models.StockBalance
-			err := tx.Where("item_type = ? AND item_id = ? AND warehouse_id = ?", item.ItemType, item.ItemID, st.FromWarehouseID).
-				Where("COALESCE(warehouse_location_id, 0) = COALESCE(?, 0)", item.FromLocationID).
-				Where("COALESCE(batch_number, '') = COALESCE(?, '')", item.BatchNumber).
-				First(&sourceBalance).Error
-			
-			if err != nil || sourceBalance.Quantity < item.Quantity {
-				return fmt.Errorf("insufficient stock for item %d at source", item.ItemID)
-			}
-
-			sourceBalance.Quantity -= item.Quantity
-			sourceBalance.TotalCost = sourceBalance.Quantity * sourceBalance.UnitCost
-			sourceBalance.LastTransactionDate = &now
-			if err := tx.Save(&sourceBalance).Error; err != nil {
-				return err
-			}
+		if err := checkWarehouseNotFrozen(tx, st.FromWarehouseID, st.ToWarehouseID); err != nil {
+			return err
+		}
 
-			// Source Ledger (Issue)
-			sourceLedger := models.StockLedger{
-				TransactionType:   "transfer_out",
-				TransactionNumber: st.TransferNumber,
-				TransactionDate:   now,
-				ItemType:          item.ItemType,
-				ItemID:            item.ItemID,
-				WarehouseID:       st.FromWarehouseID,
-				WarehouseLocationID: item.FromLocationID,
-				BatchNumber:       item.BatchNumber,
-				LotNumber:         item.LotNumber,
-				Quantity:          -item.Quantity,
-				UnitCost:          item.UnitCost,
-				TotalCost:         -item.Quantity * item.UnitCost,
-				BalanceQuantity:   sourceBalance.Quantity,
-				ReferenceType:     "Transfer",
-				ReferenceID:       st.ID,
-				CreatedBy:         &userID,
-			}
-			if err := tx.Create(&sourceLedger).Error; err != nil {
-				return err
-			}
+		var placements []StoragePlacement
+		for _, item := range st.Items {
+			placements = addPlacement(placements, item.ToLocationID, item.ItemType, item.ItemID, item.Quantity)
+		}
+		if err := checkStoragePlacements(tx, placements); err != nil {
+			return err
+		}
+		if err := checkTransferSerials(tx, st); err != nil {
+			return err
+		}
 
-			// --- DESTINATION WAREHOUSE: TRANSIT IN ---
-			var destBalance models.StockBalance
-			err = tx.Where("item_type = ? AND item_id = ? AND warehouse_id = ?", item.ItemType, item.ItemID, st.ToWarehouseID).
-				Where("COALESCE(warehouse_location_id, 0) = COALESCE(?, 0)", item.ToLocationID).
-				Where("COALESCE(batch_number, '') = COALESCE(?, '')", item.BatchNumber).
-				First(&destBalance).Error
+		itemTypes := make([]string, 0, len(st.Items))
+		for _, item := range st.Items {
+			itemTypes = append(itemTypes, item.ItemType)
+		}
+		allocated, err := moveAllocations(tx, st.FromWarehouseID, itemTypes...)
+		if err != nil {
+			return err
+		}
 
+		txRepo := repository.NewStockTransferRepository(tx)
+		for i := range st.Items {
+			item := &st.Items[i]
+			out := transferStock(st, item, st.FromWarehouseID, item.FromLocationID, item.Quantity, "transfer_out")
+			out.Allocated = allocated
+			out.Serials = item.SerialNumbers
+			source, err := moveStock(tx, out, st.ToWarehouseID, item.ToLocationID, "transfer_in", userID, now)
 			if err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					destBalance = models.StockBalance{
-						ItemType:            item.ItemType,
-						ItemID:              item.ItemID,
-						WarehouseID:         st.ToWarehouseID,
-						WarehouseLocationID: item.ToLocationID,
-						BatchNumber:         item.BatchNumber,
-						LotNumber:           item.LotNumber,
-						Quantity:            item.Quantity,
-						UnitCost:            item.UnitCost,
-						TotalCost:           item.Quantity * item.UnitCost,
-						LastTransactionDate: &now,
-					}
-					if item.ExpiryDate != nil {
-						exp := item.ExpiryDate.Format("2006-01-02")
-						destBalance.ExpiryDate = &exp
-					}
-					if err := tx.Create(&destBalance).Error; err != nil {
-						return err
-					}
-				} else {
-					return err
-				}
-			} else {
-				destBalance.Quantity += item.Quantity
-				destBalance.TotalCost = destBalance.Quantity * destBalance.UnitCost
-				destBalance.LastTransactionDate = &now
-				if err := tx.Save(&destBalance).Error; err != nil {
-					return err
-				}
+				return fmt.Errorf("item %d (transfer_out): %w", item.ItemID, err)
 			}
 
-			// Destination Ledger (Receipt)
-			destLedger := models.StockLedger{
-				TransactionType:   "transfer_in",
-				TransactionNumber: st.TransferNumber,
-				TransactionDate:   now,
-				ItemType:          item.ItemType,
-				ItemID:            item.ItemID,
-				WarehouseID:       st.ToWarehouseID,
-				WarehouseLocationID: item.ToLocationID,
-				BatchNumber:       item.BatchNumber,
-				LotNumber:         item.LotNumber,
-				Quantity:          item.Quantity,
-				UnitCost:          item.UnitCost,
-				TotalCost:         item.Quantity * item.UnitCost,
-				BalanceQuantity:   destBalance.Quantity,
-				ReferenceType:     "Transfer",
-				ReferenceID:       st.ID,
-				CreatedBy:         &userID,
+			item.UnitCost = source.UnitCost
+			if item.ExpiryDate == nil && source.ExpiryDate != nil {
+				if expiry, err := time.Parse("2006-01-02", dateOnly(*source.ExpiryDate)); err == nil {
+					item.ExpiryDate = &expiry
+				}
 			}
-			if err := tx.Create(&destLedger).Error; err != nil {
+			item.UpdatedBy = &userID
+			if err := txRepo.UpdateItem(item); err != nil {
 				return err
 			}
 		}
@@ -270,11 +225,8 @@ func (s *stockTransferService) PostTransfer(id uint, userID uint) (*models.SafeS
 		st.IsPosted = true
 		st.PostedBy = &userID
 		st.PostedAt = &now
-		if err := tx.Save(st).Error; err != nil {
-			return err
-		}
-
-		return nil
+		st.UpdatedBy = &userID
+		return tx.Omit(clause.Associations).Save(st).Error
 	})
 
 	if err != nil {
@@ -293,6 +245,9 @@ func (s *stockTransferService) CancelTransfer(id uint, userID uint) (*models.Saf
 	if st.IsPosted {
 		return nil, errors.New("cannot cancel a posted transfer")
 	}
+	if st.Status == "shipped" {
+		return nil, errors.New("cannot cancel a transfer in transit; receive it at the destination")
+	}
 
 	st.Status = "cancelled"
 	st.UpdatedBy = &userID
@@ -305,18 +260,26 @@ func (s *stockTransferService) CancelTransfer(id uint, userID uint) (*models.Saf
 
 func (s *stockTransferService) MapToSafe(st *models.StockTransfer) *models.SafeStockTransfer {
 	safe := &models.SafeStockTransfer{
-		ID:                st.ID,
-		TransferNumber:    st.TransferNumber,
-		FromWarehouseID:   st.FromWarehouseID,
-		FromWarehouseName: st.FromWarehouse.Name,
-		ToWarehouseID:     st.ToWarehouseID,
-		ToWarehouseName:   st.ToWarehouse.Name,
-		TransferDate:      st.TransferDate,
-		Status:            st.Status,
-		IsPosted:          st.IsPosted,
-		PostedAt:          st.PostedAt,
-		Notes:             st.Notes,
-		CreatedAt:         st.CreatedAt,
+		ID:                  st.ID,
+		TransferNumber:      st.TransferNumber,
+		FromWarehouseID:     st.FromWarehouseID,
+		FromWarehouseName:   st.FromWarehouse.Name,
+		ToWarehouseID:       st.ToWarehouseID,
+		ToWarehouseName:     st.ToWarehouse.Name,
+		TransferDate:        st.TransferDate,
+		Status:              st.Status,
+		IsPosted:            st.IsPosted,
+		PostedAt:            st.PostedAt,
+		ShippedAt:           st.ShippedAt,
+		ReceivedAt:          st.ReceivedAt,
+		Carrier:             st.Carrier,
+		TrackingNumber:      st.TrackingNumber,
+		ExpectedArrivalDate: st.ExpectedArrivalDate,
+		Notes:               st.Notes,
+		CreatedAt:           st.CreatedAt,
+	}
+	if st.TransitLocation != nil {
+		safe.TransitLocationCode = st.TransitLocation.Code
 	}
 	if st.PostedByUser != nil {
 		safe.PostedByName = st.PostedByUser.FullName
@@ -338,16 +301,21 @@ func (s *stockTransferService) MapToSafe(st *models.StockTransfer) *models.SafeS
 		safe.Items = make([]models.SafeStockTransferItem, len(st.Items))
 		for i, item := range st.Items {
 			safeItem := models.SafeStockTransferItem{
-				ID:               item.ID,
-				ItemType:         item.ItemType,
-				ItemID:           item.ItemID,
-				FromLocationID:   item.FromLocationID,
-				ToLocationID:     item.ToLocationID,
-				BatchNumber:      item.BatchNumber,
-				LotNumber:        item.LotNumber,
-				Quantity:         item.Quantity,
-				ReceivedQuantity: item.ReceivedQuantity,
-				UnitCost:         item.UnitCost,
+				ID:                    item.ID,
+				ItemType:              item.ItemType,
+				ItemID:                item.ItemID,
+				FromLocationID:        item.FromLocationID,
+				ToLocationID:          item.ToLocationID,
+				BatchNumber:           item.BatchNumber,
+				LotNumber:             item.LotNumber,
+				Quantity:              item.Quantity,
+				ShippedQuantity:       item.ShippedQuantity,
+				ReceivedQuantity:      item.ReceivedQuantity,
+				DiscrepancyQuantity:   item.DiscrepancyQuantity,
+				DiscrepancyReason:     item.DiscrepancyReason,
+				SerialNumbers:         item.SerialNumbers,
+				ReceivedSerialNumbers: item.ReceivedSerialNumbers,
+				UnitCost:              item.UnitCost,
 			}
 			if item.FromLocation != nil {
 				safeItem.FromLocationCode = item.FromLocation.Code
@@ -360,3 +328,361 @@ func (s *stockTransferService) MapToSafe(st *models.StockTransfer) *models.SafeS
 	}
 	return safe
 }
+
+// ShipTransfer takes the stock out of the source warehouse and into the in-transit location of the
+// destination, where it stays until ReceiveTransfer. The destination is not checked for a freeze:
+// in-transit stock is left out of counts.
+func (s *stockTransferService) ShipTransfer(id uint, req *dto.ShipStockTransferRequest, userID uint) (*models.SafeStockTransfer, error) {
+	st, err := s.stRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if st.IsPosted {
+		return nil, errors.New("transfer is already posted")
+	}
+	if st.Status != "draft" && st.Status != "approved" {
+		return nil, fmt.Errorf("transfer is %s and cannot be shipped", st.Status)
+	}
+
+	var expected *time.Time
+	if req.ExpectedArrivalDate != "" {
+		date, err := time.Parse("2006-01-02", req.ExpectedArrivalDate)
+		if err != nil {
+			return nil, errors.New("invalid expected_arrival_date, use YYYY-MM-DD")
+		}
+		expected = &date
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewStockTransferRepository(tx)
+		if err := checkWarehouseNotFrozen(tx, st.FromWarehouseID); err != nil {
+			return err
+		}
+		transit, err := txRepo.TransitLocation(&st.ToWarehouse)
+		if err != nil {
+			return err
+		}
+		if err := checkTransferSerials(tx, st); err != nil {
+			return err
+		}
+		itemTypes := make([]string, 0, len(st.Items))
+		for _, item := range st.Items {
+			itemTypes = append(itemTypes, item.ItemType)
+		}
+		allocated, err := moveAllocations(tx, st.FromWarehouseID, itemTypes...)
+		if err != nil {
+			return err
+		}
+
+		for i := range st.Items {
+			item := &st.Items[i]
+			out := transferStock(st, item, st.FromWarehouseID, item.FromLocationID, item.Quantity, "transfer_out")
+			out.Allocated = allocated
+			out.Serials = item.SerialNumbers
+			source, err := moveStock(tx, out, st.ToWarehouseID, &transit.ID, "transit_in", userID, now)
+			if err != nil {
+				return fmt.Errorf("item %d (transfer_out): %w", item.ItemID, err)
+			}
+
+			item.UnitCost = source.UnitCost
+			item.ShippedQuantity = item.Quantity
+			if item.ExpiryDate == nil && source.ExpiryDate != nil {
+				if expiry, err := time.Parse("2006-01-02", dateOnly(*source.ExpiryDate)); err == nil {
+					item.ExpiryDate = &expiry
+				}
+			}
+			item.UpdatedBy = &userID
+			if err := txRepo.UpdateItem(item); err != nil {
+				return err
+			}
+		}
+
+		st.Status = "shipped"
+		st.ShippedBy = &userID
+		st.ShippedAt = &now
+		st.TransitLocationID = &transit.ID
+		st.Carrier = req.Carrier
+		st.TrackingNumber = req.TrackingNumber
+		st.ExpectedArrivalDate = expected
+		if req.Notes != "" {
+			st.Notes = req.Notes
+		}
+		st.UpdatedBy = &userID
+		return tx.Omit(clause.Associations).Save(st).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTransferByID(id)
+}
+
+// ReceiveTransfer moves what arrived from the in-transit location into the destination locations.
+// A shortfall is recorded on the item with its reason and written off from the in-transit location;
+// receiving more than was shipped is refused.
+func (s *stockTransferService) ReceiveTransfer(id uint, req *dto.ReceiveStockTransferRequest, userID uint) (*models.SafeStockTransfer, error) {
+	st, err := s.stRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if st.Status != "shipped" || st.TransitLocationID == nil {
+		return nil, fmt.Errorf("transfer is %s; only shipped transfers can be received", st.Status)
+	}
+
+	receipts := make(map[uint]*dto.ReceiveStockTransferItemRequest, len(req.Items))
+	for i := range req.Items {
+		r := &req.Items[i]
+		if transferItem(st, r.ItemID) == nil {
+			return nil, fmt.Errorf("item %d is not on transfer %s", r.ItemID, st.TransferNumber)
+		}
+		if receipts[r.ItemID] != nil {
+			return nil, fmt.Errorf("item %d is listed twice", r.ItemID)
+		}
+		receipts[r.ItemID] = r
+	}
+
+	tracked, err := transferTracked(s.db, st)
+	if err != nil {
+		return nil, err
+	}
+
+	// settle every item first so that nothing is posted when one of them is refused
+	lost := make([][]string, len(st.Items))
+	for i := range st.Items {
+		item := &st.Items[i]
+		received := item.ShippedQuantity
+		receivedSerials := []string(item.SerialNumbers)
+		if r := receipts[item.ID]; r != nil {
+			received = roundQty(*r.ReceivedQuantity)
+			if r.ToLocationID != nil {
+				item.ToLocationID = r.ToLocationID
+			}
+			item.DiscrepancyReason = r.DiscrepancyReason
+			if r.SerialNumbers != nil {
+				receivedSerials = cleanSerials(r.SerialNumbers)
+			} else if received < item.ShippedQuantity-qtyEpsilon {
+				receivedSerials = nil
+			}
+		}
+		if received > item.ShippedQuantity+qtyEpsilon {
+			return nil, fmt.Errorf("item %d: received %s exceeds the %s shipped", item.ID, formatQty(received), formatQty(item.ShippedQuantity))
+		}
+		if err := checkSerialCount(tracked[item.ItemType][item.ItemID], received, receivedSerials, fmt.Sprintf("item %d", item.ID)); err != nil {
+			return nil, err
+		}
+		if lost[i], err = missingSerials(item.SerialNumbers, receivedSerials); err != nil {
+			return nil, fmt.Errorf("item %d: %w", item.ID, err)
+		}
+		item.ReceivedSerialNumbers = receivedSerials
+		item.ReceivedQuantity = received
+		item.DiscrepancyQuantity = roundQty(item.ShippedQuantity - received)
+		if item.DiscrepancyQuantity <= qtyEpsilon {
+			item.DiscrepancyQuantity = 0
+			item.DiscrepancyReason = ""
+		} else if item.DiscrepancyReason == "" {
+			return nil, fmt.Errorf("item %d: %s short, a discrepancy reason is required", item.ID, formatQty(item.DiscrepancyQuantity))
+		}
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewStockTransferRepository(tx)
+		if err := checkWarehouseNotFrozen(tx, st.ToWarehouseID); err != nil {
+			return err
+		}
+
+		var placements []StoragePlacement
+		for _, item := range st.Items {
+			if item.ToLocationID != nil {
+				var location models.WarehouseLocation
+				if err := tx.First(&location, *item.ToLocationID).Error; err != nil {
+					return fmt.Errorf("location %d not found", *item.ToLocationID)
+				}
+				if location.WarehouseID != st.ToWarehouseID {
+					return fmt.Errorf("location %s is not in the destination warehouse", location.Code)
+				}
+				if location.LocationType == LocationTypeInTransit {
+					return fmt.Errorf("location %s is an in-transit location", location.Code)
+				}
+			}
+			placements = addPlacement(placements, item.ToLocationID, item.ItemType, item.ItemID, item.ReceivedQuantity)
+		}
+		if err := checkStoragePlacements(tx, placements); err != nil {
+			return err
+		}
+
+		for i := range st.Items {
+			item := &st.Items[i]
+			if item.ReceivedQuantity > 0 {
+				received := transferStock(st, item, st.ToWarehouseID, st.TransitLocationID, item.ReceivedQuantity, "transit_out")
+				received.UnitCost = item.UnitCost
+				received.Serials = item.ReceivedSerialNumbers
+				if _, err := moveStock(tx, received, st.ToWarehouseID, item.ToLocationID, "transfer_in", userID, now); err != nil {
+					return fmt.Errorf("item %d (transit_out): %w", item.ItemID, err)
+				}
+			}
+			if item.DiscrepancyQuantity > 0 {
+				loss := transferStock(st, item, st.ToWarehouseID, st.TransitLocationID, -item.DiscrepancyQuantity, "transit_loss")
+				loss.UnitCost = item.UnitCost
+				loss.Notes = item.DiscrepancyReason
+				loss.Serials = lost[i]
+				loss.SerialAction = serialIssue
+				if _, err := postStock(tx, loss, userID, now); err != nil {
+					return fmt.Errorf("item %d (transit_loss): %w", item.ItemID, err)
+				}
+			}
+			item.UpdatedBy = &userID
+			if err := txRepo.UpdateItem(item); err != nil {
+				return err
+			}
+		}
+
+		st.Status = "received"
+		st.ReceivedBy = &userID
+		st.ReceivedAt = &now
+		st.IsPosted = true
+		st.PostedBy = &userID
+		st.PostedAt = &now
+		if req.Notes != "" {
+			st.Notes = req.Notes
+		}
+		st.UpdatedBy = &userID
+		return tx.Omit(clause.Associations).Save(st).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTransferByID(id)
+}
+
+// InTransitReport lists the shipped transfers that have not been received, with their value;
+// a transfer past its expected arrival date is overdue
+func (s *stockTransferService) InTransitReport(filter *dto.InTransitFilterRequest) ([]dto.InTransitTransfer, error) {
+	rows, err := s.stRepo.InTransit(filter.FromWarehouseID, filter.ToWarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	return inTransitTransfers(rows, time.Now()), nil
+}
+
+// inTransitTransfers groups the in-transit rows by transfer
+func inTransitTransfers(rows []repository.InTransitRow, now time.Time) []dto.InTransitTransfer {
+	today := dateOnlyTime(now)
+	transfers := make([]dto.InTransitTransfer, 0)
+	index := make(map[uint]int)
+	for _, row := range rows {
+		i, ok := index[row.TransferID]
+		if !ok {
+			t := dto.InTransitTransfer{
+				TransferID:          row.TransferID,
+				TransferNumber:      row.TransferNumber,
+				FromWarehouseID:     row.FromWarehouseID,
+				FromWarehouseName:   row.FromWarehouseName,
+				ToWarehouseID:       row.ToWarehouseID,
+				ToWarehouseName:     row.ToWarehouseName,
+				ShippedAt:           row.ShippedAt,
+				ExpectedArrivalDate: row.ExpectedArrivalDate,
+				Carrier:             row.Carrier,
+				TrackingNumber:      row.TrackingNumber,
+				Items:               []dto.InTransitItem{},
+			}
+			if row.ShippedAt != nil {
+				t.DaysInTransit = int(today.Sub(dateOnlyTime(*row.ShippedAt)).Hours() / 24)
+			}
+			if row.ExpectedArrivalDate != nil {
+				t.Overdue = today.After(dateOnlyTime(*row.ExpectedArrivalDate))
+			}
+			transfers = append(transfers, t)
+			i = len(transfers) - 1
+			index[row.TransferID] = i
+		}
+
+		value := roundMoney(row.Quantity * row.UnitCost)
+		t := &transfers[i]
+		t.Items = append(t.Items, dto.InTransitItem{
+			ItemID:      row.ItemID,
+			ItemType:    row.ItemType,
+			ProductID:   row.ProductID,
+			ItemCode:    row.ItemCode,
+			ItemName:    row.ItemName,
+			BatchNumber: row.BatchNumber,
+			LotNumber:   row.LotNumber,
+			Quantity:    row.Quantity,
+			UnitCost:    row.UnitCost,
+			Value:       value,
+		})
+		t.TotalQuantity = roundQty(t.TotalQuantity + row.Quantity)
+		t.TotalValue = roundMoney(t.TotalValue + value)
+	}
+	return transfers
+}
+
+// transferStock is the posting of a transfer item at a location of one of the warehouses
+func transferStock(st *models.StockTransfer, item *models.StockTransferItem, warehouseID uint, locationID *uint, quantity float64, txType string) stockPosting {
+	return stockPosting{
+		ItemType:    item.ItemType,
+		ItemID:      item.ItemID,
+		BatchNumber: item.BatchNumber,
+		LotNumber:   item.LotNumber,
+		WarehouseID: warehouseID,
+		LocationID:  locationID,
+		Quantity:    quantity,
+		TxType:      txType,
+		TxNumber:    st.TransferNumber,
+		RefType:     "Transfer",
+		RefID:       st.ID,
+	}
+}
+
+// transferTracked returns, per item type, which items of the transfer are serial-tracked
+func transferTracked(tx *gorm.DB, st *models.StockTransfer) (map[string]map[uint]bool, error) {
+	ids := make(map[string][]uint)
+	for _, item := range st.Items {
+		ids[item.ItemType] = append(ids[item.ItemType], item.ItemID)
+	}
+	tracked := make(map[string]map[uint]bool, len(ids))
+	for itemType, list := range ids {
+		t, err := serialTrackedItems(tx, itemType, list)
+		if err != nil {
+			return nil, err
+		}
+		tracked[itemType] = t
+	}
+	return tracked, nil
+}
+
+// checkTransferSerials checks that every serial-tracked item of the transfer carries one serial per unit
+func checkTransferSerials(tx *gorm.DB, st *models.StockTransfer) error {
+	tracked, err := transferTracked(tx, st)
+	if err != nil {
+		return err
+	}
+	for _, item := range st.Items {
+		if err := checkSerialCount(tracked[item.ItemType][item.ItemID], item.Quantity, item.SerialNumbers, fmt.Sprintf("item %d", item.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// missingSerials returns the shipped serials that were not received; a received serial must have been shipped
+func missingSerials(shipped, received []string) ([]string, error) {
+	arrived := make(map[string]bool, len(received))
+	for _, s := range received {
+		arrived[s] = true
+	}
+	var missing []string
+	for _, s := range shipped {
+		if arrived[s] {
+			delete(arrived, s)
+		} else {
+			missing = append(missing, s)
+		}
+	}
+	for _, s := range received {
+		if arrived[s] {
+			return nil, fmt.Errorf("serial %s was not shipped on this transfer", s)
+		}
+	}
+	return missing, nil
+}
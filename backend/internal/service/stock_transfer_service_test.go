@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStockTransferService_Post_LogicStructure(t *testing.T) {
@@ -99,3 +101,47 @@ func TestStockTransferService_Cancel_LogicStructure(t *testing.T) {
 
 	assert.Equal(t, "cancelled", st.Status)
 }
+
+func TestInTransitTransfers(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.Local)
+	shipped := time.Date(2026, 3, 6, 9, 30, 0, 0, time.Local)
+	due := time.Date(2026, 3, 9, 0, 0, 0, 0, time.Local)
+	later := time.Date(2026, 3, 20, 0, 0, 0, 0, time.Local)
+
+	rows := []repository.InTransitRow{
+		{TransferID: 1, TransferNumber: "TR-1", ShippedAt: &shipped, ExpectedArrivalDate: &due, ItemID: 11, Quantity: 10, UnitCost: 2.5},
+		{TransferID: 2, TransferNumber: "TR-2", ShippedAt: &now, ExpectedArrivalDate: &later, ItemID: 21, Quantity: 1, UnitCost: 100},
+		{TransferID: 1, TransferNumber: "TR-1", ShippedAt: &shipped, ExpectedArrivalDate: &due, ItemID: 12, Quantity: 3, UnitCost: 1.333},
+	}
+
+	transfers := inTransitTransfers(rows, now)
+	require.Len(t, transfers, 2)
+
+	first := transfers[0]
+	assert.Equal(t, "TR-1", first.TransferNumber)
+	assert.Len(t, first.Items, 2)
+	assert.Equal(t, 13.0, first.TotalQuantity)
+	assert.Equal(t, 29.0, first.TotalValue)
+	assert.Equal(t, 4, first.DaysInTransit)
+	assert.True(t, first.Overdue)
+
+	second := transfers[1]
+	assert.Equal(t, 0, second.DaysInTransit)
+	assert.False(t, second.Overdue)
+	assert.Equal(t, 100.0, second.TotalValue)
+
+	assert.Empty(t, inTransitTransfers(nil, now))
+}
+
+func TestMissingSerials(t *testing.T) {
+	lost, err := missingSerials([]string{"A", "B", "C"}, []string{"C", "A"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"B"}, lost)
+
+	lost, err = missingSerials([]string{"A"}, []string{"A"})
+	require.NoError(t, err)
+	assert.Empty(t, lost)
+
+	_, err = missingSerials([]string{"A", "B"}, []string{"A", "X"})
+	assert.ErrorContains(t, err, "serial X was not shipped")
+}
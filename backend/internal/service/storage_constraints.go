@@ -0,0 +1,302 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// coldChainMaxTemperature: items that must stay at or below this (°C) may only go to a
+// temperature-controlled location; other requirements are met by an uncontrolled location
+const coldChainMaxTemperature = 8.0
+
+// StoragePlacement is stock a posting is about to put into a location
+type StoragePlacement struct {
+	LocationID uint
+	ItemType   string
+	ItemID     uint
+	Quantity   float64
+}
+
+// temperatureRange is an inclusive range in °C; nil bounds are open
+type temperatureRange struct {
+	Min *float64
+	Max *float64
+}
+
+func (r temperatureRange) known() bool { return r.Min != nil || r.Max != nil }
+
+func (r temperatureRange) String() string {
+	switch {
+	case r.Min != nil && r.Max != nil:
+		return fmt.Sprintf("%g..%g°C", *r.Min, *r.Max)
+	case r.Max != nil:
+		return fmt.Sprintf("≤%g°C", *r.Max)
+	case r.Min != nil:
+		return fmt.Sprintf("≥%g°C", *r.Min)
+	}
+	return "uncontrolled"
+}
+
+const tempNumber = `(-?\d+(?:[.,]\d+)?)`
+const tempUnit = `(?:\s*℃|\s*(?:°|º|độ)?\s*c\b)`
+
+var (
+	tempRangeRe = regexp.MustCompile(tempNumber + tempUnit + `?\s*(?:-|–|~|đến|to)\s*` + tempNumber + tempUnit)
+	tempMaxRe   = regexp.MustCompile(`(?:dưới|tối đa|below|under|max(?:imum)?|<=?|≤)\s*` + tempNumber + tempUnit)
+	tempMinRe   = regexp.MustCompile(`(?:trên|tối thiểu|above|over|min(?:imum)?|>=?|≥)\s*` + tempNumber + tempUnit)
+
+	// Negated bounds are rewritten first so "không dưới 5°C" is not read as "dưới 5°C"
+	tempNegations = strings.NewReplacer("không vượt quá", "≤", "không quá", "≤", "not above", "≤", "không dưới", "≥", "not below", "≥")
+)
+
+// parseTemperatureRange reads the storage temperature out of free text such as "2-8°C",
+// "Bảo quản dưới 30 độ C" or "Store at 15 – 25 ℃". Text without a temperature has no constraint.
+func parseTemperatureRange(text string) temperatureRange {
+	text = tempNegations.Replace(strings.ToLower(text))
+	parse := func(s string) *float64 {
+		v, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+		if err != nil {
+			return nil
+		}
+		return &v
+	}
+	if m := tempRangeRe.FindStringSubmatch(text); m != nil {
+		lo, hi := parse(m[1]), parse(m[2])
+		if lo != nil && hi != nil && *lo > *hi {
+			lo, hi = hi, lo
+		}
+		return temperatureRange{Min: lo, Max: hi}
+	}
+	var r temperatureRange
+	if m := tempMaxRe.FindStringSubmatch(text); m != nil {
+		r.Max = parse(m[1])
+	}
+	if m := tempMinRe.FindStringSubmatch(text); m != nil {
+		r.Min = parse(m[1])
+	}
+	return r
+}
+
+// locationTemperature is the location's own range, or its zone's when the location has none
+func locationTemperature(loc *models.WarehouseLocation) temperatureRange {
+	r := temperatureRange{Min: loc.MinTemperature, Max: loc.MaxTemperature}
+	if !r.known() && loc.Zone != nil {
+		r = temperatureRange{Min: loc.Zone.MinTemperature, Max: loc.Zone.MaxTemperature}
+	}
+	return r
+}
+
+func locationHazardousAllowed(loc *models.WarehouseLocation) bool {
+	return loc.HazardousAllowed || (loc.Zone != nil && loc.Zone.HazardousAllowed)
+}
+
+// temperatureFits reports whether a location held at loc keeps an item within item
+func temperatureFits(item, loc temperatureRange) bool {
+	if !item.known() {
+		return true
+	}
+	if !loc.known() {
+		return item.Max == nil || *item.Max > coldChainMaxTemperature
+	}
+	if item.Min != nil && (loc.Min == nil || *loc.Min < *item.Min) {
+		return false
+	}
+	if item.Max != nil && (loc.Max == nil || *loc.Max > *item.Max) {
+		return false
+	}
+	return true
+}
+
+// itemViolations checks the item-level rules: active location, hazardous approval, temperature
+func itemViolations(loc *models.WarehouseLocation, item *repository.StorageItemProfile) []string {
+	var out []string
+	if loc.IsActive != nil && !*loc.IsActive {
+		out = append(out, fmt.Sprintf("location %s is inactive", loc.Code))
+	}
+	if loc.Zone != nil && !loc.Zone.IsActive {
+		out = append(out, fmt.Sprintf("zone %s of location %s is inactive", loc.Zone.Code, loc.Code))
+	}
+	if item.Hazardous && !locationHazardousAllowed(loc) {
+		out = append(out, fmt.Sprintf("%s is hazardous and location %s is not approved for hazardous goods", item.Code, loc.Code))
+	}
+	need := parseTemperatureRange(item.StorageConditions)
+	if have := locationTemperature(loc); !temperatureFits(need, have) {
+		out = append(out, fmt.Sprintf("%s must be stored at %s but location %s is %s", item.Code, need, loc.Code, have))
+	}
+	return out
+}
+
+// placementLoad converts a quantity of an item into location load; unknown specs add nothing
+func placementLoad(item *repository.StorageItemProfile, quantity float64) repository.LocationLoad {
+	load := repository.LocationLoad{Quantity: quantity}
+	if item.UnitWeightKg != nil {
+		load.WeightKg = quantity * *item.UnitWeightKg
+	}
+	if item.UnitVolumeM3 != nil {
+		load.VolumeM3 = quantity * *item.UnitVolumeM3
+	}
+	if item.UnitsPerPallet != nil && *item.UnitsPerPallet > 0 {
+		load.Pallets = math.Ceil(quantity / *item.UnitsPerPallet)
+	}
+	return load
+}
+
+// capacityViolations compares the load a location would hold against each configured limit
+func capacityViolations(loc *models.WarehouseLocation, load repository.LocationLoad) []string {
+	var out []string
+	check := func(limit *float64, used float64, unit string) {
+		if limit != nil && used > *limit+qtyEpsilon {
+			out = append(out, fmt.Sprintf("location %s would hold %s of %s%s", loc.Code, formatQty(roundQty(used)), formatQty(*limit), unit))
+		}
+	}
+	check(loc.MaxQuantity, load.Quantity, " units")
+	check(loc.MaxWeightKg, load.WeightKg, " kg")
+	check(loc.MaxVolumeM3, load.VolumeM3, " m³")
+	if loc.MaxPallets != nil {
+		pallets := float64(*loc.MaxPallets)
+		check(&pallets, load.Pallets, " pallets")
+	}
+	return out
+}
+
+// checkStoragePlacements validates stock about to be posted into locations against zoning,
+// storage conditions and capacity. Call it inside the posting transaction before balances change.
+func checkStoragePlacements(tx *gorm.DB, placements []StoragePlacement) error {
+	locationIDs := []uint{}
+	itemIDs := map[string][]uint{}
+	for _, p := range placements {
+		if p.LocationID == 0 || p.Quantity <= 0 {
+			continue
+		}
+		locationIDs = append(locationIDs, p.LocationID)
+		itemIDs[p.ItemType] = append(itemIDs[p.ItemType], p.ItemID)
+	}
+	if len(locationIDs) == 0 {
+		return nil
+	}
+	locationIDs = uniqueUints(locationIDs)
+
+	repo := repository.NewStorageCapacityRepository(tx)
+	locations, err := repo.GetLocations(locationIDs)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint]*models.WarehouseLocation, len(locations))
+	for _, loc := range locations {
+		byID[loc.ID] = loc
+	}
+	items := map[string]*repository.StorageItemProfile{}
+	for itemType, ids := range itemIDs {
+		profiles, err := repo.ItemProfiles(itemType, uniqueUints(ids))
+		if err != nil {
+			return err
+		}
+		for i := range profiles {
+			items[fmt.Sprintf("%s:%d", itemType, profiles[i].ItemID)] = &profiles[i]
+		}
+	}
+	current, err := repo.LocationLoads(locationIDs)
+	if err != nil {
+		return err
+	}
+	loads := make(map[uint]repository.LocationLoad, len(current))
+	for _, l := range current {
+		loads[l.LocationID] = l
+	}
+
+	var violations []string
+	for _, p := range placements {
+		if p.LocationID == 0 || p.Quantity <= 0 {
+			continue
+		}
+		loc, ok := byID[p.LocationID]
+		if !ok {
+			return fmt.Errorf("location %d not found", p.LocationID)
+		}
+		item, ok := items[fmt.Sprintf("%s:%d", p.ItemType, p.ItemID)]
+		if !ok {
+			return fmt.Errorf("%s %d not found", p.ItemType, p.ItemID)
+		}
+		violations = append(violations, itemViolations(loc, item)...)
+
+		add := placementLoad(item, p.Quantity)
+		l := loads[p.LocationID]
+		l.Quantity += add.Quantity
+		l.WeightKg += add.WeightKg
+		l.VolumeM3 += add.VolumeM3
+		l.Pallets += add.Pallets
+		loads[p.LocationID] = l
+	}
+	for _, id := range locationIDs {
+		violations = append(violations, capacityViolations(byID[id], loads[id])...)
+	}
+	if len(violations) > 0 {
+		sort.Strings(violations)
+		return fmt.Errorf("storage constraints violated: %s", strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// addPlacement appends stock going into locationID; stock posted without a location is not constrained
+func addPlacement(list []StoragePlacement, locationID *uint, itemType string, itemID uint, quantity float64) []StoragePlacement {
+	if locationID == nil || quantity <= 0 {
+		return list
+	}
+	return append(list, StoragePlacement{LocationID: *locationID, ItemType: itemType, ItemID: itemID, Quantity: quantity})
+}
+
+// filterStorableLocations keeps the locations that accept the item and still have room for at least
+// one unit of it; whether the full quantity fits is checked when the stock is posted
+func filterStorableLocations(db *gorm.DB, locations []*models.WarehouseLocation, itemType string, itemID uint, quantity float64) ([]*models.WarehouseLocation, error) {
+	if len(locations) == 0 {
+		return locations, nil
+	}
+	repo := repository.NewStorageCapacityRepository(db)
+	profiles, err := repo.ItemProfiles(itemType, []uint{itemID})
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("%s %d not found", itemType, itemID)
+	}
+	item := &profiles[0]
+
+	ids := make([]uint, len(locations))
+	for i, loc := range locations {
+		ids[i] = loc.ID
+	}
+	current, err := repo.LocationLoads(ids)
+	if err != nil {
+		return nil, err
+	}
+	loads := make(map[uint]repository.LocationLoad, len(current))
+	for _, l := range current {
+		loads[l.LocationID] = l
+	}
+
+	add := placementLoad(item, math.Min(quantity, 1))
+	kept := make([]*models.WarehouseLocation, 0, len(locations))
+	for _, loc := range locations {
+		if len(itemViolations(loc, item)) > 0 {
+			continue
+		}
+		l := loads[loc.ID]
+		l.Quantity += add.Quantity
+		l.WeightKg += add.WeightKg
+		l.VolumeM3 += add.VolumeM3
+		l.Pallets += add.Pallets
+		if len(capacityViolations(loc, l)) > 0 {
+			continue
+		}
+		kept = append(kept, loc)
+	}
+	return kept, nil
+}
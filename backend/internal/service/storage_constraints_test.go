@@ -0,0 +1,116 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func f64(v float64) *float64 { return &v }
+
+func TestParseTemperatureRange(t *testing.T) {
+	cases := []struct {
+		text     string
+		min, max *float64
+	}{
+		{"Bảo quản lạnh 2-8°C", f64(2), f64(8)},
+		{"Store at 15 – 25 ℃, away from light", f64(15), f64(25)},
+		{"từ 2 đến 8 độ C", f64(2), f64(8)},
+		{"Kho đông -20 - -10°C", f64(-20), f64(-10)},
+		{"Nơi khô ráo, dưới 30°C", nil, f64(30)},
+		{"Không dưới 5 độ C", f64(5), nil},
+		{"Store below 25,5°C", nil, f64(25.5)},
+		{"Nơi khô ráo, tránh ánh nắng", nil, nil},
+		{"Lô 2024-08, đóng gói 25 kg", nil, nil},
+	}
+	for _, tc := range cases {
+		r := parseTemperatureRange(tc.text)
+		assert.Equal(t, tc.min, r.Min, tc.text)
+		assert.Equal(t, tc.max, r.Max, tc.text)
+	}
+}
+
+func TestTemperatureFits(t *testing.T) {
+	cold := temperatureRange{Min: f64(2), Max: f64(8)}
+	ambient := temperatureRange{Min: f64(15), Max: f64(25)}
+	uncontrolled := temperatureRange{}
+
+	assert.True(t, temperatureFits(cold, cold))
+	assert.True(t, temperatureFits(cold, temperatureRange{Min: f64(3), Max: f64(6)}))
+	assert.False(t, temperatureFits(cold, ambient))
+	assert.False(t, temperatureFits(cold, uncontrolled)) // cold chain needs a controlled location
+	assert.True(t, temperatureFits(temperatureRange{Max: f64(30)}, uncontrolled))
+	assert.True(t, temperatureFits(temperatureRange{Max: f64(30)}, ambient))
+	assert.False(t, temperatureFits(temperatureRange{Max: f64(30)}, temperatureRange{Min: f64(20)}))
+	assert.True(t, temperatureFits(uncontrolled, cold))
+}
+
+func TestItemViolations(t *testing.T) {
+	zone := &models.WarehouseZone{Code: "COLD", IsActive: true, MinTemperature: f64(2), MaxTemperature: f64(8)}
+	loc := &models.WarehouseLocation{Code: "C-01", Zone: zone}
+	active := &repository.StorageItemProfile{Code: "VIT-C", StorageConditions: "2-8°C"}
+	acid := &repository.StorageItemProfile{Code: "HCL", Hazardous: true, StorageConditions: "dưới 30°C"}
+
+	// Location inherits the zone temperature
+	assert.Empty(t, itemViolations(loc, active))
+	assert.Len(t, itemViolations(&models.WarehouseLocation{Code: "A-01"}, active), 1)
+
+	// Hazardous approval may come from the location or its zone
+	assert.Len(t, itemViolations(loc, acid), 1)
+	zone.HazardousAllowed = true
+	assert.Empty(t, itemViolations(loc, acid))
+
+	inactive := false
+	zone.IsActive = false
+	assert.Len(t, itemViolations(&models.WarehouseLocation{Code: "C-02", Zone: zone, IsActive: &inactive}, active), 2)
+}
+
+func TestCapacityViolations(t *testing.T) {
+	pallets := 2
+	loc := &models.WarehouseLocation{Code: "B-01", MaxWeightKg: f64(1000), MaxPallets: &pallets}
+	item := &repository.StorageItemProfile{UnitWeightKg: f64(1), UnitsPerPallet: f64(500)}
+
+	load := placementLoad(item, 900)
+	assert.Equal(t, repository.LocationLoad{Quantity: 900, WeightKg: 900, Pallets: 2}, load)
+	assert.Empty(t, capacityViolations(loc, load))
+
+	load.WeightKg += 200
+	load.Pallets++
+	v := capacityViolations(loc, load)
+	assert.Equal(t, []string{"location B-01 would hold 1100 of 1000 kg", "location B-01 would hold 3 of 2 pallets"}, v)
+
+	// Unknown specs add nothing but the quantity
+	assert.Equal(t, repository.LocationLoad{Quantity: 5}, placementLoad(&repository.StorageItemProfile{}, 5))
+}
+
+func TestBuildUtilizationReport(t *testing.T) {
+	zone := &models.WarehouseZone{ID: 1, Code: "COLD", Name: "Kho lạnh"}
+	zoneID := uint(1)
+	locations := []*models.WarehouseLocation{
+		{ID: 1, Code: "C-01", ZoneID: &zoneID, Zone: zone, MaxWeightKg: f64(1000)},
+		{ID: 2, Code: "C-02", ZoneID: &zoneID, Zone: zone, MaxWeightKg: f64(500)},
+		{ID: 3, Code: "A-01"},
+	}
+	loads := []repository.LocationLoad{
+		{LocationID: 1, Quantity: 950, WeightKg: 950},
+		{LocationID: 2, Quantity: 100, WeightKg: 100},
+		{LocationID: 3, Quantity: 10, WeightKg: 10},
+	}
+	report := buildUtilizationReport(9, locations, loads)
+
+	assert.Equal(t, "near_full", report.Locations[0].Status)
+	assert.Equal(t, 95.0, *report.Locations[0].UtilizationPct)
+	assert.Equal(t, "available", report.Locations[1].Status)
+	assert.Equal(t, "unlimited", report.Locations[2].Status)
+
+	if assert.Len(t, report.Zones, 2) {
+		assert.Equal(t, "", report.Zones[0].ZoneCode)
+		assert.Equal(t, "COLD", report.Zones[1].ZoneCode)
+		assert.Equal(t, 2, report.Zones[1].Locations)
+		assert.Equal(t, 57.5, *report.Zones[1].AvgUtilizationPct)
+	}
+	assert.Equal(t, 3, report.Summary.Locations)
+	assert.Equal(t, 1060.0, report.Summary.WeightKg)
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Compliance check stages
+const (
+	ComplianceStagePOApproval = "po_approval"
+	ComplianceStageGRNPost    = "grn_post"
+)
+
+// ComplianceIssue describes a mandatory supplier document that is missing or expired
+type ComplianceIssue struct {
+	RequirementID uint    `json:"requirement_id"`
+	DocumentType  string  `json:"document_type"`
+	Scope         string  `json:"scope"`
+	MaterialID    *uint   `json:"material_id,omitempty"`
+	MaterialCode  string  `json:"material_code,omitempty"`
+	Problem       string  `json:"problem"` // missing, expired
+	ExpiryDate    *string `json:"expiry_date,omitempty"`
+}
+
+// SupplierComplianceService evaluates supplier_document_requirements against uploaded documents
+type SupplierComplianceService interface {
+	Check(supplierID uint, materialIDs []uint, stage string) ([]ComplianceIssue, error)
+	EnsureCompliant(supplierID uint, materialIDs []uint, stage string) error
+
+	ListRequirements() ([]models.SupplierDocumentRequirement, error)
+	CreateRequirement(req *models.SupplierDocumentRequirement) error
+	UpdateRequirement(id uint, req *models.SupplierDocumentRequirement) (*models.SupplierDocumentRequirement, error)
+	DeleteRequirement(id uint) error
+}
+
+type supplierComplianceService struct {
+	db *gorm.DB
+}
+
+func NewSupplierComplianceService(db *gorm.DB) SupplierComplianceService {
+	return &supplierComplianceService{db: db}
+}
+
+// Check returns the compliance issues for a supplier and the materials on a document.
+// stage filters the rules (po_approval / grn_post); empty stage evaluates all active rules.
+func (s *supplierComplianceService) Check(supplierID uint, materialIDs []uint, stage string) ([]ComplianceIssue, error) {
+	var supplier models.Supplier
+	if err := s.db.First(&supplier, supplierID).Error; err != nil {
+		return nil, errors.New("supplier not found")
+	}
+
+	query := s.db.Where("is_active = ?", true)
+	switch stage {
+	case ComplianceStagePOApproval:
+		query = query.Where("block_po_approval = ?", true)
+	case ComplianceStageGRNPost:
+		query = query.Where("block_grn_post = ?", true)
+	}
+	var rules []models.SupplierDocumentRequirement
+	if err := query.Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	issues := []ComplianceIssue{}
+	today := time.Now().Format("2006-01-02")
+
+	for _, rule := range rules {
+		if rule.SupplierGroup != nil && (supplier.SupplierGroup == nil || *supplier.SupplierGroup != *rule.SupplierGroup) {
+			continue
+		}
+
+		if rule.Scope == "material" {
+			for _, materialID := range uniqueUints(materialIDs) {
+				if rule.MaterialID != nil && *rule.MaterialID != materialID {
+					continue
+				}
+				mID := materialID
+				issue, err := s.checkDocument(rule, supplierID, &mID, today)
+				if err != nil {
+					return nil, err
+				}
+				if issue != nil {
+					issues = append(issues, *issue)
+				}
+			}
+			continue
+		}
+
+		// Supplier-level document, optionally only required when a given material is purchased
+		if rule.MaterialID != nil && !containsUint(materialIDs, *rule.MaterialID) {
+			continue
+		}
+		issue, err := s.checkDocument(rule, supplierID, nil, today)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+
+	return issues, nil
+}
+
+// checkDocument looks for the most recent document of the required type; nil means compliant.
+// Without a material only supplier-wide documents count, not certificates for one material.
+func (s *supplierComplianceService) checkDocument(rule models.SupplierDocumentRequirement, supplierID uint, materialID *uint, today string) (*ComplianceIssue, error) {
+	query := s.db.Model(&models.SupplierDocument{}).
+		Where("supplier_id = ? AND document_type = ?", supplierID, rule.DocumentType)
+	if materialID != nil {
+		query = query.Where("material_id = ?", *materialID)
+	} else {
+		query = query.Where("material_id IS NULL")
+	}
+
+	var docs []models.SupplierDocument
+	if err := query.Order("expiry_date DESC NULLS FIRST").Limit(1).Find(&docs).Error; err != nil {
+		return nil, err
+	}
+
+	issue := &ComplianceIssue{
+		RequirementID: rule.ID,
+		DocumentType:  rule.DocumentType,
+		Scope:         rule.Scope,
+		MaterialID:    materialID,
+	}
+	if materialID != nil {
+		var m models.Material
+		if err := s.db.Select("code").First(&m, *materialID).Error; err == nil {
+			issue.MaterialCode = m.Code
+		}
+	}
+
+	if len(docs) == 0 {
+		issue.Problem = "missing"
+		return issue, nil
+	}
+	doc := docs[0]
+	// expiry_date is scanned as a full timestamp string by some drivers; compare the date part
+	if doc.ExpiryDate != nil && len(*doc.ExpiryDate) >= 10 && (*doc.ExpiryDate)[:10] < today {
+		issue.Problem = "expired"
+		issue.ExpiryDate = doc.ExpiryDate
+		return issue, nil
+	}
+	return nil, nil
+}
+
+// EnsureCompliant returns an error describing all blocking issues, or nil
+func (s *supplierComplianceService) EnsureCompliant(supplierID uint, materialIDs []uint, stage string) error {
+	issues, err := s.Check(supplierID, materialIDs, stage)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+
+	parts := make([]string, len(issues))
+	for i, issue := range issues {
+		label := issue.DocumentType
+		if issue.MaterialCode != "" {
+			label += " (" + issue.MaterialCode + ")"
+		}
+		parts[i] = label + " " + issue.Problem
+	}
+	return fmt.Errorf("supplier documents not compliant: %s", strings.Join(parts, ", "))
+}
+
+func (s *supplierComplianceService) ListRequirements() ([]models.SupplierDocumentRequirement, error) {
+	var rules []models.SupplierDocumentRequirement
+	err := s.db.Order("document_type, id").Find(&rules).Error
+	return rules, err
+}
+
+func (s *supplierComplianceService) CreateRequirement(req *models.SupplierDocumentRequirement) error {
+	if err := validateRequirement(req); err != nil {
+		return err
+	}
+	return s.db.Create(req).Error
+}
+
+func (s *supplierComplianceService) UpdateRequirement(id uint, req *models.SupplierDocumentRequirement) (*models.SupplierDocumentRequirement, error) {
+	var existing models.SupplierDocumentRequirement
+	if err := s.db.First(&existing, id).Error; err != nil {
+		return nil, errors.New("requirement not found")
+	}
+	if err := validateRequirement(req); err != nil {
+		return nil, err
+	}
+
+	req.ID = existing.ID
+	req.CreatedAt = existing.CreatedAt
+	req.CreatedBy = existing.CreatedBy
+	if err := s.db.Save(req).Error; err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (s *supplierComplianceService) DeleteRequirement(id uint) error {
+	result := s.db.Delete(&models.SupplierDocumentRequirement{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("requirement not found")
+	}
+	return nil
+}
+
+func validateRequirement(req *models.SupplierDocumentRequirement) error {
+	if !IsValidSupplierDocumentType(req.DocumentType) || req.DocumentType == models.SupplierDocOther {
+		return errors.New("document_type must be one of coa, msds, business_licence, gmp, halal")
+	}
+	if req.Scope == "" {
+		req.Scope = "supplier"
+	}
+	if req.Scope != "supplier" && req.Scope != "material" {
+		return errors.New("scope must be supplier or material")
+	}
+	return nil
+}
+
+// IsValidSupplierDocumentType reports whether t is a known supplier document type
+func IsValidSupplierDocumentType(t string) bool {
+	switch t {
+	case models.SupplierDocCOA, models.SupplierDocMSDS, models.SupplierDocBusinessLicence,
+		models.SupplierDocGMP, models.SupplierDocHalal, models.SupplierDocOther:
+		return true
+	}
+	return false
+}
+
+func containsUint(list []uint, v uint) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueUints(list []uint) []uint {
+	seen := make(map[uint]bool, len(list))
+	out := make([]uint, 0, len(list))
+	for _, x := range list {
+		if !seen[x] {
+			seen[x] = true
+			out = append(out, x)
+		}
+	}
+	return out
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRequirement(t *testing.T) {
+	rule := &models.SupplierDocumentRequirement{DocumentType: models.SupplierDocCOA}
+	assert.NoError(t, validateRequirement(rule))
+	assert.Equal(t, "supplier", rule.Scope, "empty scope defaults to supplier")
+
+	assert.Error(t, validateRequirement(&models.SupplierDocumentRequirement{DocumentType: models.SupplierDocOther}))
+	assert.Error(t, validateRequirement(&models.SupplierDocumentRequirement{DocumentType: "passport"}))
+	assert.Error(t, validateRequirement(&models.SupplierDocumentRequirement{DocumentType: models.SupplierDocMSDS, Scope: "warehouse"}))
+}
+
+func TestUniqueUints(t *testing.T) {
+	assert.Equal(t, []uint{3, 1, 2}, uniqueUints([]uint{3, 1, 3, 2, 1}))
+	assert.Empty(t, uniqueUints(nil))
+	assert.True(t, containsUint([]uint{1, 2}, 2))
+	assert.False(t, containsUint(nil, 2))
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Score weights (sum = 100). KPIs without data are dropped and the remaining weights rescaled.
+const (
+	scoreWeightOnTime  = 40.0
+	scoreWeightFill    = 30.0
+	scoreWeightQuality = 20.0
+	scoreWeightPrice   = 10.0
+
+	// Paying this much above the reference price (or more) gives a price score of 0
+	priceVarianceFloor = 0.20
+)
+
+// SupplierScorecardService evaluates supplier performance from PO/GRN history
+type SupplierScorecardService interface {
+	GetScorecard(supplierID uint, from, to string) (*dto.SupplierScorecard, error)
+	GetRanking(from, to string) ([]*dto.SupplierScorecard, error)
+	SuggestPriorities(materialID int64, from, to string) ([]*dto.SupplierPrioritySuggestion, error)
+}
+
+type supplierScorecardService struct {
+	db *gorm.DB
+}
+
+func NewSupplierScorecardService(db *gorm.DB) SupplierScorecardService {
+	return &supplierScorecardService{db: db}
+}
+
+func (s *supplierScorecardService) GetScorecard(supplierID uint, from, to string) (*dto.SupplierScorecard, error) {
+	var supplier models.Supplier
+	if err := s.db.First(&supplier, supplierID).Error; err != nil {
+		return nil, errors.New("supplier not found")
+	}
+
+	from, to, err := normalizeScorecardPeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := s.compute([]uint{supplierID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	card := cards[supplierID]
+	card.SupplierCode = supplier.Code
+	card.SupplierName = supplier.Name
+	return card, nil
+}
+
+// GetRanking returns scorecards of all suppliers with purchasing activity in the period, best first
+func (s *supplierScorecardService) GetRanking(from, to string) ([]*dto.SupplierScorecard, error) {
+	from, to, err := normalizeScorecardPeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var suppliers []models.Supplier
+	err = s.db.Where("id IN (SELECT DISTINCT supplier_id FROM purchase_orders WHERE order_date BETWEEN ? AND ? AND status NOT IN ('draft', 'cancelled'))", from, to).
+		Find(&suppliers).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(suppliers) == 0 {
+		return []*dto.SupplierScorecard{}, nil
+	}
+
+	ids := make([]uint, len(suppliers))
+	for i, sp := range suppliers {
+		ids[i] = sp.ID
+	}
+	cards, err := s.compute(ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*dto.SupplierScorecard, 0, len(suppliers))
+	for _, sp := range suppliers {
+		card := cards[sp.ID]
+		card.SupplierCode = sp.Code
+		card.SupplierName = sp.Name
+		result = append(result, card)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].SupplierCode < result[j].SupplierCode
+	})
+	for i, card := range result {
+		card.Rank = i + 1
+	}
+	return result, nil
+}
+
+// SuggestPriorities ranks the approved suppliers of a material by score; rank 1 = suggested priority 1
+func (s *supplierScorecardService) SuggestPriorities(materialID int64, from, to string) ([]*dto.SupplierPrioritySuggestion, error) {
+	from, to, err := normalizeScorecardPeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []models.MaterialSupplier
+	if err := s.db.Preload("Supplier").Where("material_id = ?", materialID).Order("priority ASC").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return []*dto.SupplierPrioritySuggestion{}, nil
+	}
+
+	ids := make([]uint, len(links))
+	for i, l := range links {
+		ids[i] = uint(l.SupplierID)
+	}
+	cards, err := s.compute(ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*dto.SupplierPrioritySuggestion, len(links))
+	for i, l := range links {
+		card := cards[uint(l.SupplierID)]
+		sug := &dto.SupplierPrioritySuggestion{
+			MaterialSupplierID: l.ID,
+			SupplierID:         uint(l.SupplierID),
+			CurrentPriority:    l.Priority,
+			Score:              card.Score,
+			Grade:              card.Grade,
+		}
+		if l.Supplier != nil {
+			sug.SupplierCode = l.Supplier.Code
+			sug.SupplierName = l.Supplier.Name
+		}
+		result[i] = sug
+	}
+	// Suppliers without any history (N/A) keep their current order after the scored ones
+	sort.SliceStable(result, func(i, j int) bool {
+		ni, nj := result[i].Grade == "N/A", result[j].Grade == "N/A"
+		if ni != nj {
+			return !ni
+		}
+		if ni {
+			return result[i].CurrentPriority < result[j].CurrentPriority
+		}
+		return result[i].Score > result[j].Score
+	})
+	for i, sug := range result {
+		sug.SuggestedPriority = i + 1
+	}
+	return result, nil
+}
+
+// compute aggregates the raw KPIs for the given suppliers and scores them
+func (s *supplierScorecardService) compute(supplierIDs []uint, from, to string) (map[uint]*dto.SupplierScorecard, error) {
+	cards := make(map[uint]*dto.SupplierScorecard, len(supplierIDs))
+	for _, id := range supplierIDs {
+		cards[id] = &dto.SupplierScorecard{SupplierID: id, PeriodFrom: from, PeriodTo: to}
+	}
+
+	// 1. On-time delivery
+	var onTime []struct {
+		SupplierID uint
+		Receipts   int64
+		OnTime     int64
+	}
+	err := s.db.Table("goods_receipt_notes AS grn").
+		Select(`po.supplier_id,
+			COUNT(*) AS receipts,
+			SUM(CASE WHEN grn.receipt_date <= po.expected_delivery_date THEN 1 ELSE 0 END) AS on_time`).
+		Joins("JOIN purchase_orders po ON po.id = grn.purchase_order_id").
+		Where("grn.posted = true AND po.expected_delivery_date IS NOT NULL").
+		Where("grn.receipt_date BETWEEN ? AND ?", from, to).
+		Where("po.supplier_id IN ?", supplierIDs).
+		Group("po.supplier_id").
+		Scan(&onTime).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range onTime {
+		if c, ok := cards[r.SupplierID]; ok {
+			c.Receipts = r.Receipts
+			c.OnTimeReceipts = r.OnTime
+		}
+	}
+
+	// 2. Fill rate (only POs already due by the end of the period)
+	var fill []struct {
+		SupplierID uint
+		Ordered    float64
+		Received   float64
+	}
+	err = s.db.Table("purchase_order_items AS poi").
+		Select(`po.supplier_id,
+			SUM(poi.quantity) AS ordered,
+			SUM(LEAST(poi.received_quantity, poi.quantity)) AS received`).
+		Joins("JOIN purchase_orders po ON po.id = poi.purchase_order_id").
+		Where("po.status NOT IN ('draft', 'cancelled')").
+		Where("po.order_date BETWEEN ? AND ?", from, to).
+		Where("(po.expected_delivery_date IS NULL OR po.expected_delivery_date <= ?)", to).
+		Where("po.supplier_id IN ?", supplierIDs).
+		Group("po.supplier_id").
+		Scan(&fill).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range fill {
+		if c, ok := cards[r.SupplierID]; ok {
+			c.OrderedQuantity = r.Ordered
+			c.ReceivedQuantity = r.Received
+		}
+	}
+
+	// 3. QC rejection
+	var qc []struct {
+		SupplierID uint
+		Accepted   float64
+		Rejected   float64
+	}
+	err = s.db.Table("goods_receipt_note_items AS gi").
+		Select(`po.supplier_id,
+			SUM(gi.accepted_quantity) AS accepted,
+			SUM(gi.rejected_quantity) AS rejected`).
+		Joins("JOIN goods_receipt_notes grn ON grn.id = gi.grn_id").
+		Joins("JOIN purchase_orders po ON po.id = grn.purchase_order_id").
+		Where("grn.status IN ('qc_completed', 'posted')").
+		Where("grn.receipt_date BETWEEN ? AND ?", from, to).
+		Where("po.supplier_id IN ?", supplierIDs).
+		Group("po.supplier_id").
+		Scan(&qc).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range qc {
+		if c, ok := cards[r.SupplierID]; ok {
+			c.AcceptedQuantity = r.Accepted
+			c.RejectedQuantity = r.Rejected
+		}
+	}
+
+	// 4. Price variance vs agreed price
+	var price []struct {
+		SupplierID uint
+		Actual     float64
+		Reference  float64
+	}
+	err = s.db.Table("purchase_order_items AS poi").
+		Select(`po.supplier_id,
+			SUM(poi.quantity * poi.unit_price) AS actual,
+			SUM(poi.quantity * COALESCE(ms.unit_price, m.standard_cost)) AS reference`).
+		Joins("JOIN purchase_orders po ON po.id = poi.purchase_order_id").
+		Joins("JOIN materials m ON m.id = poi.material_id").
+		Joins("LEFT JOIN material_suppliers ms ON ms.material_id = poi.material_id AND ms.supplier_id = po.supplier_id").
+		Where("po.status NOT IN ('draft', 'cancelled')").
+		Where("po.order_date BETWEEN ? AND ?", from, to).
+		Where("COALESCE(ms.unit_price, m.standard_cost) > 0").
+		Where("po.supplier_id IN ?", supplierIDs).
+		Group("po.supplier_id").
+		Scan(&price).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range price {
+		if c, ok := cards[r.SupplierID]; ok {
+			c.ActualAmount = r.Actual
+			c.ReferenceAmount = r.Reference
+		}
+	}
+
+	for _, c := range cards {
+		scoreSupplier(c)
+	}
+	return cards, nil
+}
+
+// scoreSupplier derives the KPI rates from the raw totals and computes the weighted score
+func scoreSupplier(c *dto.SupplierScorecard) {
+	var weighted, weights float64
+
+	if c.Receipts > 0 {
+		rate := float64(c.OnTimeReceipts) / float64(c.Receipts)
+		c.OnTimeRate = &rate
+		weighted += rate * scoreWeightOnTime
+		weights += scoreWeightOnTime
+	}
+	if c.OrderedQuantity > 0 {
+		rate := math.Min(c.ReceivedQuantity/c.OrderedQuantity, 1)
+		c.FillRate = &rate
+		weighted += rate * scoreWeightFill
+		weights += scoreWeightFill
+	}
+	if inspected := c.AcceptedQuantity + c.RejectedQuantity; inspected > 0 {
+		rate := c.RejectedQuantity / inspected
+		c.RejectionRate = &rate
+		weighted += (1 - rate) * scoreWeightQuality
+		weights += scoreWeightQuality
+	}
+	if c.ReferenceAmount > 0 {
+		variance := (c.ActualAmount - c.ReferenceAmount) / c.ReferenceAmount
+		c.PriceVariance = &variance
+		priceScore := 1 - math.Max(variance, 0)/priceVarianceFloor
+		weighted += math.Max(priceScore, 0) * scoreWeightPrice
+		weights += scoreWeightPrice
+	}
+
+	if weights == 0 {
+		c.Score = 0
+		c.Grade = "N/A"
+		return
+	}
+	c.Score = math.Round(weighted/weights*100*10) / 10
+	switch {
+	case c.Score >= 90:
+		c.Grade = "A"
+	case c.Score >= 75:
+		c.Grade = "B"
+	case c.Score >= 60:
+		c.Grade = "C"
+	default:
+		c.Grade = "D"
+	}
+}
+
+// normalizeScorecardPeriod validates YYYY-MM-DD bounds, defaulting to the last 12 months
+func normalizeScorecardPeriod(from, to string) (string, string, error) {
+	now := time.Now()
+	if to == "" {
+		to = now.Format("2006-01-02")
+	}
+	if from == "" {
+		from = now.AddDate(-1, 0, 0).Format("2006-01-02")
+	}
+	fromDate, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return "", "", errors.New("invalid from date, use YYYY-MM-DD")
+	}
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return "", "", errors.New("invalid to date, use YYYY-MM-DD")
+	}
+	if fromDate.After(toDate) {
+		return "", "", errors.New("from date must be before to date")
+	}
+	return from, to, nil
+}
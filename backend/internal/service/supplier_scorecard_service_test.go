@@ -0,0 +1,48 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreSupplier(t *testing.T) {
+	t.Run("all KPIs present", func(t *testing.T) {
+		card := &dto.SupplierScorecard{
+			Receipts:         10,
+			OnTimeReceipts:   8, // 0.8 * 40 = 32
+			OrderedQuantity:  100,
+			ReceivedQuantity: 100, // 1.0 * 30 = 30
+			AcceptedQuantity: 90,
+			RejectedQuantity: 10, // 0.9 * 20 = 18
+			ActualAmount:     1100,
+			ReferenceAmount:  1000, // +10% => 0.5 * 10 = 5
+		}
+		scoreSupplier(card)
+
+		require.NotNil(t, card.OnTimeRate)
+		require.NotNil(t, card.PriceVariance)
+		assert.InDelta(t, 0.8, *card.OnTimeRate, 0.0001)
+		assert.InDelta(t, 0.1, *card.PriceVariance, 0.0001)
+		assert.Equal(t, 85.0, card.Score)
+		assert.Equal(t, "B", card.Grade)
+	})
+
+	t.Run("missing KPIs are excluded from the weighting", func(t *testing.T) {
+		card := &dto.SupplierScorecard{Receipts: 4, OnTimeReceipts: 4}
+		scoreSupplier(card)
+
+		assert.Nil(t, card.FillRate)
+		assert.Nil(t, card.RejectionRate)
+		assert.Equal(t, 100.0, card.Score)
+		assert.Equal(t, "A", card.Grade)
+	})
+
+	t.Run("no data", func(t *testing.T) {
+		card := &dto.SupplierScorecard{}
+		scoreSupplier(card)
+		assert.Equal(t, "N/A", card.Grade)
+	})
+}
@@ -61,11 +61,18 @@ func (s *supplierService) CreateSupplier(req *dto.CreateSupplierRequest, userID
 		Country:       country,
 		PaymentTerms:  req.PaymentTerms,
 		CreditLimit:   req.CreditLimit,
+		Currency:      NormalizeCurrency(req.Currency),
 		IsActive:      &req.IsActive,
 		Notes:         req.Notes,
 		CreatedBy:     &userID,
 		UpdatedBy:     &userID,
 	}
+	if req.OverReceiptTolerance != nil {
+		supplier.OverReceiptTolerance = *req.OverReceiptTolerance
+	}
+	if req.UnderReceiptTolerance != nil {
+		supplier.UnderReceiptTolerance = *req.UnderReceiptTolerance
+	}
 
 	if err := s.repo.Create(supplier); err != nil {
 		return nil, err
@@ -164,6 +171,15 @@ func (s *supplierService) UpdateSupplier(id uint, req *dto.UpdateSupplierRequest
 	if req.CreditLimit != nil {
 		supplier.CreditLimit = req.CreditLimit
 	}
+	if req.Currency != "" {
+		supplier.Currency = NormalizeCurrency(req.Currency)
+	}
+	if req.OverReceiptTolerance != nil {
+		supplier.OverReceiptTolerance = *req.OverReceiptTolerance
+	}
+	if req.UnderReceiptTolerance != nil {
+		supplier.UnderReceiptTolerance = *req.UnderReceiptTolerance
+	}
 	if req.IsActive != nil {
 		supplier.IsActive = req.IsActive
 	}
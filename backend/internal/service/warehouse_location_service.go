@@ -1,10 +1,10 @@
 package service
 
 import (
+	"errors"
 	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
 	"github.com/VyVy-ERP/warehouse-backend/internal/models"
 	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
-	"errors"
 	"time"
 
 	"gorm.io/gorm"
@@ -23,14 +23,30 @@ type WarehouseLocationService interface {
 type warehouseLocationService struct {
 	repo          repository.WarehouseLocationRepository
 	warehouseRepo repository.WarehouseRepository
+	zoneRepo      repository.WarehouseZoneRepository
 }
 
 // NewWarehouseLocationService creates a new warehouse location service
-func NewWarehouseLocationService(repo repository.WarehouseLocationRepository, warehouseRepo repository.WarehouseRepository) WarehouseLocationService {
+func NewWarehouseLocationService(repo repository.WarehouseLocationRepository, warehouseRepo repository.WarehouseRepository, zoneRepo repository.WarehouseZoneRepository) WarehouseLocationService {
 	return &warehouseLocationService{
 		repo:          repo,
 		warehouseRepo: warehouseRepo,
+		zoneRepo:      zoneRepo,
+	}
+}
+
+// validateStorageSettings checks the zone belongs to the location's warehouse and the temperature range
+func (s *warehouseLocationService) validateStorageSettings(location *models.WarehouseLocation) error {
+	if location.ZoneID != nil {
+		zone, err := s.zoneRepo.GetByID(*location.ZoneID)
+		if err != nil {
+			return errors.New("zone not found")
+		}
+		if zone.WarehouseID != location.WarehouseID {
+			return errors.New("zone belongs to another warehouse")
+		}
 	}
+	return validateTemperatureRange(location.MinTemperature, location.MaxTemperature)
 }
 
 // CreateLocation creates a new warehouse location
@@ -61,18 +77,30 @@ func (s *warehouseLocationService) CreateLocation(req *dto.CreateWarehouseLocati
 
 	// Create location
 	location := &models.WarehouseLocation{
-		WarehouseID:  req.WarehouseID,
-		Code:         req.Code,
-		Name:         req.Name,
-		Aisle:        req.Aisle,
-		Rack:         req.Rack,
-		Shelf:        req.Shelf,
-		Bin:          req.Bin,
-		LocationType: locationType,
-		IsActive:     &req.IsActive,
-		Notes:        req.Notes,
-		CreatedBy:    &userID,
-		UpdatedBy:    &userID,
+		WarehouseID:      req.WarehouseID,
+		Code:             req.Code,
+		Name:             req.Name,
+		Aisle:            req.Aisle,
+		Rack:             req.Rack,
+		Shelf:            req.Shelf,
+		Bin:              req.Bin,
+		LocationType:     locationType,
+		MaxQuantity:      req.MaxQuantity,
+		HazardousAllowed: req.HazardousAllowed,
+		ZoneID:           req.ZoneID,
+		MaxWeightKg:      req.MaxWeightKg,
+		MaxVolumeM3:      req.MaxVolumeM3,
+		MaxPallets:       req.MaxPallets,
+		MinTemperature:   req.MinTemperature,
+		MaxTemperature:   req.MaxTemperature,
+		IsActive:         &req.IsActive,
+		Notes:            req.Notes,
+		CreatedBy:        &userID,
+		UpdatedBy:        &userID,
+	}
+
+	if err := s.validateStorageSettings(location); err != nil {
+		return nil, err
 	}
 
 	if err := s.repo.Create(location); err != nil {
@@ -163,6 +191,33 @@ func (s *warehouseLocationService) UpdateLocation(id uint, req *dto.UpdateWareho
 	if req.LocationType != "" {
 		location.LocationType = req.LocationType
 	}
+	if req.MaxQuantity != nil {
+		location.MaxQuantity = req.MaxQuantity
+	}
+	if req.HazardousAllowed != nil {
+		location.HazardousAllowed = *req.HazardousAllowed
+	}
+	if req.ZoneID != nil {
+		location.ZoneID = req.ZoneID
+	}
+	if req.MaxWeightKg != nil {
+		location.MaxWeightKg = req.MaxWeightKg
+	}
+	if req.MaxVolumeM3 != nil {
+		location.MaxVolumeM3 = req.MaxVolumeM3
+	}
+	if req.MaxPallets != nil {
+		location.MaxPallets = req.MaxPallets
+	}
+	if req.MinTemperature != nil {
+		location.MinTemperature = req.MinTemperature
+	}
+	if req.MaxTemperature != nil {
+		location.MaxTemperature = req.MaxTemperature
+	}
+	if err := s.validateStorageSettings(location); err != nil {
+		return nil, err
+	}
 	if req.IsActive != nil {
 		location.IsActive = req.IsActive
 	}
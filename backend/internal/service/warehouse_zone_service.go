@@ -0,0 +1,298 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/VyVy-ERP/warehouse-backend/internal/dto"
+	"github.com/VyVy-ERP/warehouse-backend/internal/models"
+	"github.com/VyVy-ERP/warehouse-backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// nearFullPct is the utilization from which a location is reported as near full
+const nearFullPct = 90.0
+
+// WarehouseZoneService manages warehouse zones and reports location capacity utilization
+type WarehouseZoneService interface {
+	CreateZone(req *dto.CreateWarehouseZoneRequest, userID uint, username string) (*models.WarehouseZone, error)
+	UpdateZone(id uint, req *dto.UpdateWarehouseZoneRequest, userID uint, username string) (*models.WarehouseZone, error)
+	GetZone(id uint) (*models.WarehouseZone, error)
+	ListZones(filters map[string]interface{}, offset, limit int) ([]*models.WarehouseZone, int64, error)
+	DeleteZone(id uint, userID uint, username string) error
+	Utilization(warehouseID uint) (*dto.WarehouseUtilizationReport, error)
+}
+
+type warehouseZoneService struct {
+	db            *gorm.DB
+	repo          repository.WarehouseZoneRepository
+	warehouseRepo repository.WarehouseRepository
+	auditSvc      AuditLogService
+}
+
+func NewWarehouseZoneService(db *gorm.DB, repo repository.WarehouseZoneRepository, warehouseRepo repository.WarehouseRepository, auditSvc AuditLogService) WarehouseZoneService {
+	return &warehouseZoneService{db: db, repo: repo, warehouseRepo: warehouseRepo, auditSvc: auditSvc}
+}
+
+// validateTemperatureRange rejects an inverted min/max pair
+func validateTemperatureRange(min, max *float64) error {
+	if min != nil && max != nil && *min > *max {
+		return fmt.Errorf("min_temperature %g is above max_temperature %g", *min, *max)
+	}
+	return nil
+}
+
+func (s *warehouseZoneService) CreateZone(req *dto.CreateWarehouseZoneRequest, userID uint, username string) (*models.WarehouseZone, error) {
+	if _, err := s.warehouseRepo.GetByID(req.WarehouseID); err != nil {
+		return nil, errors.New("warehouse not found")
+	}
+	if _, err := s.repo.GetByCode(req.WarehouseID, req.Code); err == nil {
+		return nil, fmt.Errorf("zone code %s already exists in this warehouse", req.Code)
+	}
+	if err := validateTemperatureRange(req.MinTemperature, req.MaxTemperature); err != nil {
+		return nil, err
+	}
+
+	zoneType := req.ZoneType
+	if zoneType == "" {
+		zoneType = "ambient"
+	}
+	zone := &models.WarehouseZone{
+		WarehouseID:      req.WarehouseID,
+		Code:             req.Code,
+		Name:             req.Name,
+		ZoneType:         zoneType,
+		MinTemperature:   req.MinTemperature,
+		MaxTemperature:   req.MaxTemperature,
+		HazardousAllowed: req.HazardousAllowed,
+		IsActive:         req.IsActive == nil || *req.IsActive,
+		Notes:            req.Notes,
+		CreatedBy:        &userID,
+		UpdatedBy:        &userID,
+	}
+	if err := s.repo.Create(zone); err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("warehouse_zones", "CREATE", int64(zone.ID), int64(userID), username, nil, zone)
+	return s.repo.GetByID(zone.ID)
+}
+
+func (s *warehouseZoneService) UpdateZone(id uint, req *dto.UpdateWarehouseZoneRequest, userID uint, username string) (*models.WarehouseZone, error) {
+	zone, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("zone not found")
+	}
+	old := *zone
+
+	if req.Code != "" && req.Code != zone.Code {
+		if _, err := s.repo.GetByCode(zone.WarehouseID, req.Code); err == nil {
+			return nil, fmt.Errorf("zone code %s already exists in this warehouse", req.Code)
+		}
+		zone.Code = req.Code
+	}
+	if req.Name != "" {
+		zone.Name = req.Name
+	}
+	if req.ZoneType != "" {
+		zone.ZoneType = req.ZoneType
+	}
+	if req.ClearTemperature {
+		zone.MinTemperature, zone.MaxTemperature = nil, nil
+	}
+	if req.MinTemperature != nil {
+		zone.MinTemperature = req.MinTemperature
+	}
+	if req.MaxTemperature != nil {
+		zone.MaxTemperature = req.MaxTemperature
+	}
+	if err := validateTemperatureRange(zone.MinTemperature, zone.MaxTemperature); err != nil {
+		return nil, err
+	}
+	if req.HazardousAllowed != nil {
+		zone.HazardousAllowed = *req.HazardousAllowed
+	}
+	if req.IsActive != nil {
+		zone.IsActive = *req.IsActive
+	}
+	if req.Notes != nil {
+		zone.Notes = *req.Notes
+	}
+	zone.UpdatedBy = &userID
+	zone.Warehouse = nil
+
+	if err := s.repo.Update(zone); err != nil {
+		return nil, err
+	}
+	_ = s.auditSvc.Log("warehouse_zones", "UPDATE", int64(zone.ID), int64(userID), username, old, zone)
+	return s.repo.GetByID(zone.ID)
+}
+
+func (s *warehouseZoneService) GetZone(id uint) (*models.WarehouseZone, error) {
+	zone, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("zone not found")
+	}
+	return zone, nil
+}
+
+func (s *warehouseZoneService) ListZones(filters map[string]interface{}, offset, limit int) ([]*models.WarehouseZone, int64, error) {
+	return s.repo.List(filters, offset, limit)
+}
+
+// DeleteZone removes a zone that no location refers to any more
+func (s *warehouseZoneService) DeleteZone(id uint, userID uint, username string) error {
+	zone, err := s.repo.GetByID(id)
+	if err != nil {
+		return errors.New("zone not found")
+	}
+	count, err := s.repo.CountLocations(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("zone %s still has %d locations", zone.Code, count)
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	_ = s.auditSvc.Log("warehouse_zones", "DELETE", int64(id), int64(userID), username, zone, nil)
+	return nil
+}
+
+// Utilization reports every location of the warehouse with its load against capacity, grouped by zone
+func (s *warehouseZoneService) Utilization(warehouseID uint) (*dto.WarehouseUtilizationReport, error) {
+	if _, err := s.warehouseRepo.GetByID(warehouseID); err != nil {
+		return nil, errors.New("warehouse not found")
+	}
+	repo := repository.NewStorageCapacityRepository(s.db)
+	locations, err := repo.ListWarehouseLocations(warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	loads, err := repo.WarehouseLoads(warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	return buildUtilizationReport(warehouseID, locations, loads), nil
+}
+
+// locationUtilization computes the load percentages of one location
+func locationUtilization(loc *models.WarehouseLocation, load repository.LocationLoad) dto.LocationUtilization {
+	u := dto.LocationUtilization{
+		LocationID:   loc.ID,
+		Code:         loc.Code,
+		Name:         loc.Name,
+		LocationType: loc.LocationType,
+		ZoneID:       loc.ZoneID,
+		Quantity:     roundQty(load.Quantity),
+		MaxQuantity:  loc.MaxQuantity,
+		WeightKg:     roundQty(load.WeightKg),
+		MaxWeightKg:  loc.MaxWeightKg,
+		VolumeM3:     roundQty(load.VolumeM3),
+		MaxVolumeM3:  loc.MaxVolumeM3,
+		Pallets:      load.Pallets,
+		MaxPallets:   loc.MaxPallets,
+	}
+	if loc.Zone != nil {
+		u.ZoneCode = loc.Zone.Code
+	}
+
+	var pct *float64
+	consider := func(limit *float64, used float64) {
+		if limit == nil || *limit <= 0 {
+			return
+		}
+		p := roundMoney(used / *limit * 100)
+		if pct == nil || p > *pct {
+			pct = &p
+		}
+	}
+	consider(loc.MaxQuantity, load.Quantity)
+	consider(loc.MaxWeightKg, load.WeightKg)
+	consider(loc.MaxVolumeM3, load.VolumeM3)
+	if loc.MaxPallets != nil {
+		pallets := float64(*loc.MaxPallets)
+		consider(&pallets, load.Pallets)
+	}
+	u.UtilizationPct = pct
+
+	switch {
+	case load.Quantity <= qtyEpsilon:
+		u.Status = "empty"
+	case pct == nil:
+		u.Status = "unlimited"
+	case *pct > 100:
+		u.Status = "over_capacity"
+	case *pct >= 100:
+		u.Status = "full"
+	case *pct >= nearFullPct:
+		u.Status = "near_full"
+	default:
+		u.Status = "available"
+	}
+	return u
+}
+
+func buildUtilizationReport(warehouseID uint, locations []*models.WarehouseLocation, loads []repository.LocationLoad) *dto.WarehouseUtilizationReport {
+	byLocation := make(map[uint]repository.LocationLoad, len(loads))
+	for _, l := range loads {
+		byLocation[l.LocationID] = l
+	}
+
+	report := &dto.WarehouseUtilizationReport{
+		WarehouseID: warehouseID,
+		Summary:     dto.ZoneUtilization{ZoneCode: "ALL", ZoneName: "All locations"},
+		Locations:   make([]dto.LocationUtilization, 0, len(locations)),
+	}
+	zones := map[string]*dto.ZoneUtilization{}
+	pctSums := map[*dto.ZoneUtilization][2]float64{} // sum of pct, number of limited locations
+
+	add := func(z *dto.ZoneUtilization, u dto.LocationUtilization) {
+		z.Locations++
+		if u.Status != "empty" {
+			z.OccupiedLocations++
+		}
+		if u.Status == "full" || u.Status == "over_capacity" {
+			z.FullLocations++
+		}
+		z.WeightKg = roundQty(z.WeightKg + u.WeightKg)
+		z.VolumeM3 = roundQty(z.VolumeM3 + u.VolumeM3)
+		z.Pallets += u.Pallets
+		if u.UtilizationPct != nil {
+			sum := pctSums[z]
+			pctSums[z] = [2]float64{sum[0] + *u.UtilizationPct, sum[1] + 1}
+		}
+	}
+
+	for _, loc := range locations {
+		u := locationUtilization(loc, byLocation[loc.ID])
+		report.Locations = append(report.Locations, u)
+
+		key := u.ZoneCode
+		z, ok := zones[key]
+		if !ok {
+			z = &dto.ZoneUtilization{ZoneID: loc.ZoneID, ZoneCode: key}
+			if loc.Zone != nil {
+				z.ZoneName = loc.Zone.Name
+			}
+			zones[key] = z
+		}
+		add(z, u)
+		add(&report.Summary, u)
+	}
+
+	for z, sum := range pctSums {
+		if sum[1] > 0 {
+			avg := roundMoney(sum[0] / sum[1])
+			z.AvgUtilizationPct = &avg
+		}
+	}
+
+	report.Zones = make([]dto.ZoneUtilization, 0, len(zones))
+	for _, z := range zones {
+		report.Zones = append(report.Zones, *z)
+	}
+	sort.Slice(report.Zones, func(i, j int) bool { return report.Zones[i].ZoneCode < report.Zones[j].ZoneCode })
+	return report
+}
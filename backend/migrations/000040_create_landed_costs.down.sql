@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS landed_cost_allocations;
+DROP TABLE IF EXISTS landed_cost_charges;
+DROP TABLE IF EXISTS landed_cost_voucher_grns;
+DROP TABLE IF EXISTS landed_cost_vouchers;
@@ -0,0 +1,70 @@
+-- Migration 000040: Landed cost vouchers
+-- Phân bổ chi phí vận chuyển, thuế nhập khẩu, phí hải quan vào giá vốn của GRN đã nhập kho
+
+CREATE TABLE IF NOT EXISTS landed_cost_vouchers (
+    id                 BIGSERIAL PRIMARY KEY,
+    voucher_number     VARCHAR(50)   UNIQUE NOT NULL,
+    voucher_date       DATE          NOT NULL DEFAULT CURRENT_DATE,
+    allocation_method  VARCHAR(20)   NOT NULL DEFAULT 'value', -- quantity, value, weight
+    status             VARCHAR(50)   NOT NULL DEFAULT 'draft', -- draft, posted, cancelled
+    total_amount       NUMERIC(15,2) NOT NULL DEFAULT 0,
+    posted             BOOLEAN       DEFAULT false,
+    posted_by          BIGINT        REFERENCES users(id),
+    posted_at          TIMESTAMP,
+    notes              TEXT,
+    created_at         TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    created_by         BIGINT,
+    updated_at         TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    updated_by         BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS landed_cost_voucher_grns (
+    id          BIGSERIAL PRIMARY KEY,
+    voucher_id  BIGINT NOT NULL REFERENCES landed_cost_vouchers(id) ON DELETE CASCADE,
+    grn_id      BIGINT NOT NULL REFERENCES goods_receipt_notes(id),
+    UNIQUE(voucher_id, grn_id)
+);
+
+CREATE TABLE IF NOT EXISTS landed_cost_charges (
+    id           BIGSERIAL PRIMARY KEY,
+    voucher_id   BIGINT        NOT NULL REFERENCES landed_cost_vouchers(id) ON DELETE CASCADE,
+    charge_type  VARCHAR(50)   NOT NULL, -- freight, import_duty, customs_fee, insurance, other
+    description  TEXT,
+    amount       NUMERIC(15,2) NOT NULL DEFAULT 0,
+    created_at   TIMESTAMP     DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS landed_cost_allocations (
+    id                    BIGSERIAL PRIMARY KEY,
+    voucher_id            BIGINT        NOT NULL REFERENCES landed_cost_vouchers(id) ON DELETE CASCADE,
+    grn_id                BIGINT        NOT NULL REFERENCES goods_receipt_notes(id),
+    grn_item_id           BIGINT        NOT NULL REFERENCES goods_receipt_note_items(id),
+    material_id           BIGINT        NOT NULL REFERENCES materials(id),
+    warehouse_id          BIGINT        NOT NULL REFERENCES warehouses(id),
+    warehouse_location_id BIGINT        REFERENCES warehouse_locations(id),
+    batch_number          VARCHAR(100),
+    lot_number            VARCHAR(100),
+    basis_quantity        NUMERIC(15,3) NOT NULL DEFAULT 0,
+    basis_value           NUMERIC(15,2) NOT NULL DEFAULT 0,
+    basis_weight          NUMERIC(15,3) NOT NULL DEFAULT 0,
+    allocated_amount      NUMERIC(15,2) NOT NULL DEFAULT 0,
+    -- Filled on posting: part of the charge still on hand vs. already consumed
+    capitalized_amount    NUMERIC(15,2) NOT NULL DEFAULT 0,
+    expensed_amount       NUMERIC(15,2) NOT NULL DEFAULT 0,
+    on_hand_quantity      NUMERIC(15,3) NOT NULL DEFAULT 0,
+    previous_unit_cost    NUMERIC(15,2),
+    new_unit_cost         NUMERIC(15,2),
+    created_at            TIMESTAMP     DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_lcv_status        ON landed_cost_vouchers(status);
+CREATE INDEX IF NOT EXISTS idx_lcv_voucher_date  ON landed_cost_vouchers(voucher_date);
+CREATE INDEX IF NOT EXISTS idx_lcvg_grn          ON landed_cost_voucher_grns(grn_id);
+CREATE INDEX IF NOT EXISTS idx_lcc_voucher       ON landed_cost_charges(voucher_id);
+CREATE INDEX IF NOT EXISTS idx_lca_voucher       ON landed_cost_allocations(voucher_id);
+CREATE INDEX IF NOT EXISTS idx_lca_grn_item      ON landed_cost_allocations(grn_item_id);
+
+CREATE TRIGGER update_landed_cost_vouchers_updated_at
+    BEFORE UPDATE ON landed_cost_vouchers
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
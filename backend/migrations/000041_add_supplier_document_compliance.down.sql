@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS supplier_document_requirements;
+
+DROP INDEX IF EXISTS idx_supplier_documents_expiry;
+DROP INDEX IF EXISTS idx_supplier_documents_material;
+DROP INDEX IF EXISTS idx_supplier_documents_type;
+
+ALTER TABLE supplier_documents
+    DROP COLUMN IF EXISTS notes,
+    DROP COLUMN IF EXISTS expiry_date,
+    DROP COLUMN IF EXISTS issue_date,
+    DROP COLUMN IF EXISTS material_id,
+    DROP COLUMN IF EXISTS document_number,
+    DROP COLUMN IF EXISTS document_type;
@@ -0,0 +1,40 @@
+-- Migration 000041: Typed supplier documents with validity + compliance rules
+-- Hồ sơ NCC (COA, MSDS, giấy phép kinh doanh, GMP, halal) có ngày cấp / ngày hết hạn
+
+ALTER TABLE supplier_documents
+    ADD COLUMN IF NOT EXISTS document_type   VARCHAR(30)  NOT NULL DEFAULT 'other', -- coa, msds, business_licence, gmp, halal, other
+    ADD COLUMN IF NOT EXISTS document_number VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS material_id     BIGINT       REFERENCES materials(id),  -- COA/MSDS are per material
+    ADD COLUMN IF NOT EXISTS issue_date      DATE,
+    ADD COLUMN IF NOT EXISTS expiry_date     DATE,
+    ADD COLUMN IF NOT EXISTS notes           TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_supplier_documents_type     ON supplier_documents(document_type);
+CREATE INDEX IF NOT EXISTS idx_supplier_documents_material ON supplier_documents(material_id);
+CREATE INDEX IF NOT EXISTS idx_supplier_documents_expiry   ON supplier_documents(expiry_date);
+
+-- Which document types are mandatory, and which workflow steps they block.
+-- scope = 'supplier': one valid document per supplier (e.g. business_licence, gmp)
+-- scope = 'material': one valid document per supplier + material on the PO/GRN (e.g. coa, msds)
+-- material_id / supplier_group narrow the rule; NULL = applies to all.
+CREATE TABLE IF NOT EXISTS supplier_document_requirements (
+    id                 BIGSERIAL PRIMARY KEY,
+    document_type      VARCHAR(30)  NOT NULL,
+    scope              VARCHAR(20)  NOT NULL DEFAULT 'supplier',
+    supplier_group     VARCHAR(50),
+    material_id        BIGINT       REFERENCES materials(id),
+    block_po_approval  BOOLEAN      NOT NULL DEFAULT true,
+    block_grn_post     BOOLEAN      NOT NULL DEFAULT true,
+    is_active          BOOLEAN      NOT NULL DEFAULT true,
+    notes              TEXT,
+    created_at         TIMESTAMP    DEFAULT CURRENT_TIMESTAMP,
+    created_by         BIGINT,
+    updated_at         TIMESTAMP    DEFAULT CURRENT_TIMESTAMP,
+    updated_by         BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_sdr_active ON supplier_document_requirements(is_active);
+
+CREATE TRIGGER update_supplier_document_requirements_updated_at
+    BEFORE UPDATE ON supplier_document_requirements
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_po_agreement;
+ALTER TABLE purchase_orders DROP COLUMN IF EXISTS agreement_id;
+DROP TABLE IF EXISTS purchase_agreement_items;
+DROP TABLE IF EXISTS purchase_agreements;
@@ -0,0 +1,47 @@
+-- Migration 000042: Blanket purchase agreements
+-- Hợp đồng nguyên tắc theo năm với NCC: giá cố định, số lượng cam kết, đơn gọi hàng (call-off PO)
+
+CREATE TABLE IF NOT EXISTS purchase_agreements (
+    id                BIGSERIAL PRIMARY KEY,
+    agreement_number  VARCHAR(50)   UNIQUE NOT NULL,
+    supplier_id       BIGINT        NOT NULL REFERENCES suppliers(id),
+    start_date        DATE          NOT NULL,
+    end_date          DATE          NOT NULL,
+    status            VARCHAR(50)   NOT NULL DEFAULT 'draft', -- draft, active, closed, cancelled
+    committed_value   NUMERIC(15,2) NOT NULL DEFAULT 0,
+    payment_terms     VARCHAR(100),
+    notes             TEXT,
+    approved_by       BIGINT        REFERENCES users(id),
+    approved_at       TIMESTAMP,
+    created_at        TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    created_by        BIGINT,
+    updated_at        TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    updated_by        BIGINT,
+    CHECK (end_date >= start_date)
+);
+
+CREATE TABLE IF NOT EXISTS purchase_agreement_items (
+    id                  BIGSERIAL PRIMARY KEY,
+    agreement_id        BIGINT        NOT NULL REFERENCES purchase_agreements(id) ON DELETE CASCADE,
+    material_id         BIGINT        NOT NULL REFERENCES materials(id),
+    agreed_price        NUMERIC(15,2) NOT NULL DEFAULT 0,
+    tax_rate            NUMERIC(5,2)  DEFAULT 0,
+    discount_rate       NUMERIC(5,2)  DEFAULT 0,
+    committed_quantity  NUMERIC(15,3) NOT NULL DEFAULT 0,
+    notes               TEXT,
+    created_at          TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(agreement_id, material_id)
+);
+
+-- Call-off POs reference the agreement they were drawn against
+ALTER TABLE purchase_orders ADD COLUMN IF NOT EXISTS agreement_id BIGINT REFERENCES purchase_agreements(id);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_pa_supplier      ON purchase_agreements(supplier_id);
+CREATE INDEX IF NOT EXISTS idx_pa_status        ON purchase_agreements(status);
+CREATE INDEX IF NOT EXISTS idx_pai_agreement    ON purchase_agreement_items(agreement_id);
+CREATE INDEX IF NOT EXISTS idx_po_agreement     ON purchase_orders(agreement_id);
+
+CREATE TRIGGER update_purchase_agreements_updated_at
+    BEFORE UPDATE ON purchase_agreements
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS purchase_order_payments;
+ALTER TABLE goods_receipt_note_items DROP COLUMN IF EXISTS base_unit_cost;
+ALTER TABLE goods_receipt_notes DROP COLUMN IF EXISTS exchange_rate;
+ALTER TABLE goods_receipt_notes DROP COLUMN IF EXISTS currency;
+ALTER TABLE purchase_orders DROP COLUMN IF EXISTS exchange_rate;
+ALTER TABLE purchase_orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE suppliers DROP COLUMN IF EXISTS currency;
+DROP TABLE IF EXISTS exchange_rates;
@@ -0,0 +1,53 @@
+-- Migration 000043: Multi-currency purchasing
+-- Nhập khẩu hương liệu bằng USD/EUR: tỷ giá theo ngày, quy đổi VND khi nhập kho, chênh lệch tỷ giá khi thanh toán
+
+-- Exchange rates: VND per 1 unit of foreign currency, effective from rate_date
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id          BIGSERIAL PRIMARY KEY,
+    currency    VARCHAR(3)    NOT NULL,
+    rate_date   DATE          NOT NULL,
+    rate        NUMERIC(18,6) NOT NULL CHECK (rate > 0),
+    source      VARCHAR(20)   NOT NULL DEFAULT 'manual', -- manual, import
+    notes       TEXT,
+    created_at  TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    created_by  BIGINT,
+    updated_at  TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    updated_by  BIGINT,
+    UNIQUE(currency, rate_date)
+);
+
+-- Document currency
+ALTER TABLE suppliers        ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'VND';
+ALTER TABLE purchase_orders  ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'VND';
+ALTER TABLE purchase_orders  ADD COLUMN IF NOT EXISTS exchange_rate NUMERIC(18,6) NOT NULL DEFAULT 1;
+ALTER TABLE goods_receipt_notes ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'VND';
+ALTER TABLE goods_receipt_notes ADD COLUMN IF NOT EXISTS exchange_rate NUMERIC(18,6) NOT NULL DEFAULT 1;
+-- Unit cost converted to VND at posting (inventory cost)
+ALTER TABLE goods_receipt_note_items ADD COLUMN IF NOT EXISTS base_unit_cost NUMERIC(15,2);
+
+-- PO payments with realized FX difference against the booked (GRN) rate
+CREATE TABLE IF NOT EXISTS purchase_order_payments (
+    id                 BIGSERIAL PRIMARY KEY,
+    purchase_order_id  BIGINT        NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    payment_date       DATE          NOT NULL,
+    amount             NUMERIC(15,2) NOT NULL CHECK (amount > 0), -- document currency
+    currency           VARCHAR(3)    NOT NULL DEFAULT 'VND',
+    exchange_rate      NUMERIC(18,6) NOT NULL DEFAULT 1,          -- rate on payment date
+    base_amount        NUMERIC(15,2) NOT NULL DEFAULT 0,          -- VND actually paid
+    booked_rate        NUMERIC(18,6) NOT NULL DEFAULT 1,          -- rate the payable was booked at
+    booked_base_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    fx_difference      NUMERIC(15,2) NOT NULL DEFAULT 0,          -- > 0: loss, < 0: gain
+    reference          VARCHAR(100),
+    notes              TEXT,
+    created_at         TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    created_by         BIGINT
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_fx_currency_date ON exchange_rates(currency, rate_date DESC);
+CREATE INDEX IF NOT EXISTS idx_pop_po           ON purchase_order_payments(purchase_order_id);
+CREATE INDEX IF NOT EXISTS idx_pop_date         ON purchase_order_payments(payment_date);
+
+CREATE TRIGGER update_exchange_rates_updated_at
+    BEFORE UPDATE ON exchange_rates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
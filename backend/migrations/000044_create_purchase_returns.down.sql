@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_grn_purchase_return;
+ALTER TABLE goods_receipt_notes DROP COLUMN IF EXISTS purchase_return_id;
+DROP TABLE IF EXISTS purchase_return_items;
+DROP TABLE IF EXISTS purchase_returns;
@@ -0,0 +1,64 @@
+-- Migration 000044: Purchase returns to supplier
+-- Trả hàng NCC: hàng bị QC loại trên GRN hoặc hàng đã nhập kho (theo lô), ghi nợ NCC (debit note), theo dõi hàng thay thế
+
+CREATE TABLE IF NOT EXISTS purchase_returns (
+    id                    BIGSERIAL PRIMARY KEY,
+    return_number         VARCHAR(50)   UNIQUE NOT NULL,
+    supplier_id           BIGINT        NOT NULL REFERENCES suppliers(id),
+    purchase_order_id     BIGINT        REFERENCES purchase_orders(id),
+    grn_id                BIGINT        REFERENCES goods_receipt_notes(id),
+    warehouse_id          BIGINT        NOT NULL REFERENCES warehouses(id),
+    return_date           DATE          NOT NULL DEFAULT CURRENT_DATE,
+    source                VARCHAR(20)   NOT NULL DEFAULT 'stock',   -- grn_rejected, stock
+    status                VARCHAR(50)   NOT NULL DEFAULT 'draft',   -- draft, posted, cancelled
+    reason                TEXT,
+    -- Debit note for lines settled by credit (document currency + VND)
+    currency              VARCHAR(3)    NOT NULL DEFAULT 'VND',
+    exchange_rate         NUMERIC(18,6) NOT NULL DEFAULT 1,
+    debit_note_number     VARCHAR(50),
+    debit_note_amount     NUMERIC(15,2) NOT NULL DEFAULT 0,
+    debit_note_base_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    -- Replacement deliveries: none, pending, partial, received
+    replacement_status    VARCHAR(20)   NOT NULL DEFAULT 'none',
+    posted                BOOLEAN       DEFAULT false,
+    posted_by             BIGINT        REFERENCES users(id),
+    posted_at             TIMESTAMP,
+    notes                 TEXT,
+    created_at            TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    created_by            BIGINT,
+    updated_at            TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    updated_by            BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS purchase_return_items (
+    id                    BIGSERIAL PRIMARY KEY,
+    purchase_return_id    BIGINT        NOT NULL REFERENCES purchase_returns(id) ON DELETE CASCADE,
+    grn_item_id           BIGINT        REFERENCES goods_receipt_note_items(id),
+    po_item_id            BIGINT        REFERENCES purchase_order_items(id),
+    material_id           BIGINT        NOT NULL REFERENCES materials(id),
+    warehouse_location_id BIGINT        REFERENCES warehouse_locations(id),
+    batch_number          VARCHAR(100),
+    lot_number            VARCHAR(100),
+    quantity              NUMERIC(15,3) NOT NULL CHECK (quantity > 0),
+    unit_cost             NUMERIC(15,2) NOT NULL DEFAULT 0, -- document currency
+    base_unit_cost        NUMERIC(15,2),                    -- VND issued from stock
+    resolution            VARCHAR(20)   NOT NULL DEFAULT 'credit', -- credit, replacement
+    replaced_quantity     NUMERIC(15,3) NOT NULL DEFAULT 0,
+    reason                TEXT,
+    created_at            TIMESTAMP     DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Replacement GRNs point at the return they make good
+ALTER TABLE goods_receipt_notes ADD COLUMN IF NOT EXISTS purchase_return_id BIGINT REFERENCES purchase_returns(id);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_prt_supplier  ON purchase_returns(supplier_id);
+CREATE INDEX IF NOT EXISTS idx_prt_po        ON purchase_returns(purchase_order_id);
+CREATE INDEX IF NOT EXISTS idx_prt_status    ON purchase_returns(status);
+CREATE INDEX IF NOT EXISTS idx_prti_return   ON purchase_return_items(purchase_return_id);
+CREATE INDEX IF NOT EXISTS idx_prti_grn_item ON purchase_return_items(grn_item_id);
+CREATE INDEX IF NOT EXISTS idx_grn_purchase_return ON goods_receipt_notes(purchase_return_id);
+
+CREATE TRIGGER update_purchase_returns_updated_at
+    BEFORE UPDATE ON purchase_returns
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_po_source;
+ALTER TABLE purchase_orders DROP COLUMN IF EXISTS source;
+ALTER TABLE material_suppliers DROP COLUMN IF EXISTS pack_size;
+ALTER TABLE material_suppliers DROP COLUMN IF EXISTS min_order_quantity;
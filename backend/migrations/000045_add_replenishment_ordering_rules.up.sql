@@ -0,0 +1,10 @@
+-- Migration 000045: Reorder-point replenishment
+-- Quy cách đặt hàng theo NCC: số lượng đặt tối thiểu (MOQ) và bội số đóng gói, dùng để làm tròn đề xuất bổ sung
+
+ALTER TABLE material_suppliers ADD COLUMN IF NOT EXISTS min_order_quantity NUMERIC(15,3);
+ALTER TABLE material_suppliers ADD COLUMN IF NOT EXISTS pack_size          NUMERIC(15,3);
+
+-- Draft POs raised by the replenishment engine (manual, replenishment)
+ALTER TABLE purchase_orders ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'manual';
+
+CREATE INDEX IF NOT EXISTS idx_po_source ON purchase_orders(source);
@@ -0,0 +1,13 @@
+UPDATE purchase_orders SET receipt_status = 'completed' WHERE receipt_status IN ('received', 'closed_short');
+UPDATE purchase_orders SET receipt_status = 'pending' WHERE receipt_status = 'partial';
+
+ALTER TABLE purchase_order_items DROP COLUMN IF EXISTS close_short_reason;
+ALTER TABLE purchase_order_items DROP COLUMN IF EXISTS closed_short_by;
+ALTER TABLE purchase_order_items DROP COLUMN IF EXISTS closed_short_at;
+ALTER TABLE purchase_order_items DROP COLUMN IF EXISTS closed_short;
+
+ALTER TABLE materials DROP COLUMN IF EXISTS under_receipt_tolerance;
+ALTER TABLE materials DROP COLUMN IF EXISTS over_receipt_tolerance;
+
+ALTER TABLE suppliers DROP COLUMN IF EXISTS under_receipt_tolerance;
+ALTER TABLE suppliers DROP COLUMN IF EXISTS over_receipt_tolerance;
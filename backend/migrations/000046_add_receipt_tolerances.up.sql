@@ -0,0 +1,20 @@
+-- Migration 000046: Receiving tolerances and close-short on PO lines
+-- Dung sai nhận hàng (% vượt/thiếu) theo NCC hoặc nguyên liệu; đóng dòng PO thiếu (close short)
+
+ALTER TABLE suppliers ADD COLUMN IF NOT EXISTS over_receipt_tolerance  NUMERIC(5,2) NOT NULL DEFAULT 0;
+ALTER TABLE suppliers ADD COLUMN IF NOT EXISTS under_receipt_tolerance NUMERIC(5,2) NOT NULL DEFAULT 0;
+
+-- NULL = use the supplier tolerance
+ALTER TABLE materials ADD COLUMN IF NOT EXISTS over_receipt_tolerance  NUMERIC(5,2);
+ALTER TABLE materials ADD COLUMN IF NOT EXISTS under_receipt_tolerance NUMERIC(5,2);
+
+ALTER TABLE purchase_order_items ADD COLUMN IF NOT EXISTS closed_short       BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE purchase_order_items ADD COLUMN IF NOT EXISTS closed_short_at    TIMESTAMP;
+ALTER TABLE purchase_order_items ADD COLUMN IF NOT EXISTS closed_short_by    BIGINT REFERENCES users(id);
+ALTER TABLE purchase_order_items ADD COLUMN IF NOT EXISTS close_short_reason TEXT;
+
+-- receipt_status is now pending / partial / received / closed_short
+UPDATE purchase_orders SET receipt_status = 'received' WHERE receipt_status = 'completed';
+UPDATE purchase_orders po SET receipt_status = 'partial'
+WHERE receipt_status = 'pending'
+  AND EXISTS (SELECT 1 FROM purchase_order_items poi WHERE poi.purchase_order_id = po.id AND poi.received_quantity > 0);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS qc_retained_samples;
+DROP TABLE IF EXISTS qc_inspection_results;
+DROP TABLE IF EXISTS qc_inspections;
+DROP TABLE IF EXISTS qc_template_tests;
+DROP TABLE IF EXISTS qc_templates;
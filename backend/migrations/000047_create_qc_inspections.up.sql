@@ -0,0 +1,126 @@
+-- Migration 000047: QC inspection templates, results, retained samples
+-- Kiểm nghiệm đầu vào: mẫu chỉ tiêu theo nguyên liệu (pH, độ nhớt, cảm quan, vi sinh...), quy tắc lấy mẫu,
+-- kết quả theo lô GRN (tự đánh giá đạt/không đạt theo tiêu chuẩn), sổ lưu mẫu và phiếu COA
+
+CREATE TABLE IF NOT EXISTS qc_templates (
+    id                 BIGSERIAL PRIMARY KEY,
+    code               VARCHAR(50)   UNIQUE NOT NULL,
+    name               VARCHAR(255)  NOT NULL,
+    material_id        BIGINT        NOT NULL REFERENCES materials(id),
+    version            INTEGER       NOT NULL DEFAULT 1,
+    -- Sampling: fixed (sample_value units), percent (sample_value % of received qty),
+    -- sqrt (sqrt(N)+1 containers of N received)
+    sampling_rule      VARCHAR(20)   NOT NULL DEFAULT 'fixed',
+    sample_value       NUMERIC(15,3) NOT NULL DEFAULT 0,
+    min_sample         NUMERIC(15,3),
+    max_sample         NUMERIC(15,3),
+    -- Retained sample kept per batch; retention counted from batch expiry (or receipt when no expiry)
+    retained_quantity  NUMERIC(15,3) NOT NULL DEFAULT 0,
+    retention_days     INTEGER       NOT NULL DEFAULT 0,
+    is_active          BOOLEAN       NOT NULL DEFAULT true,
+    notes              TEXT,
+    created_at         TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    created_by         BIGINT,
+    updated_at         TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    updated_by         BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS qc_template_tests (
+    id             BIGSERIAL PRIMARY KEY,
+    template_id    BIGINT        NOT NULL REFERENCES qc_templates(id) ON DELETE CASCADE,
+    test_code      VARCHAR(50)   NOT NULL,
+    test_name      VARCHAR(255)  NOT NULL,
+    category       VARCHAR(50),                              -- physical, chemical, appearance, microbiology
+    result_type    VARCHAR(20)   NOT NULL DEFAULT 'numeric', -- numeric, text
+    unit           VARCHAR(20),
+    min_value      NUMERIC(18,6),
+    max_value      NUMERIC(18,6),
+    expected_text  VARCHAR(255),                             -- text tests: expected result, e.g. "Conforms"
+    method         VARCHAR(255),
+    is_required    BOOLEAN       NOT NULL DEFAULT true,
+    sort_order     INTEGER       NOT NULL DEFAULT 0,
+    UNIQUE (template_id, test_code)
+);
+
+CREATE TABLE IF NOT EXISTS qc_inspections (
+    id                 BIGSERIAL PRIMARY KEY,
+    inspection_number  VARCHAR(50)   UNIQUE NOT NULL,
+    grn_id             BIGINT        NOT NULL REFERENCES goods_receipt_notes(id),
+    grn_item_id        BIGINT        NOT NULL REFERENCES goods_receipt_note_items(id),
+    material_id        BIGINT        NOT NULL REFERENCES materials(id),
+    template_id        BIGINT        NOT NULL REFERENCES qc_templates(id),
+    batch_number       VARCHAR(100),
+    lot_number         VARCHAR(100),
+    received_quantity  NUMERIC(15,3) NOT NULL DEFAULT 0,
+    container_count    INTEGER,
+    sample_size        NUMERIC(15,3) NOT NULL DEFAULT 0,
+    sample_unit        VARCHAR(20),
+    status             VARCHAR(20)   NOT NULL DEFAULT 'pending', -- pending, completed
+    result             VARCHAR(20),                              -- pass, fail
+    inspected_by       BIGINT        REFERENCES users(id),
+    inspected_at       TIMESTAMP,
+    notes              TEXT,
+    created_at         TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    created_by         BIGINT,
+    updated_at         TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    updated_by         BIGINT
+);
+
+-- Results snapshot the template spec so later template changes don't alter past inspections
+CREATE TABLE IF NOT EXISTS qc_inspection_results (
+    id                BIGSERIAL PRIMARY KEY,
+    inspection_id     BIGINT        NOT NULL REFERENCES qc_inspections(id) ON DELETE CASCADE,
+    template_test_id  BIGINT        REFERENCES qc_template_tests(id) ON DELETE SET NULL,
+    test_code         VARCHAR(50)   NOT NULL,
+    test_name         VARCHAR(255)  NOT NULL,
+    category          VARCHAR(50),
+    result_type       VARCHAR(20)   NOT NULL DEFAULT 'numeric',
+    unit              VARCHAR(20),
+    min_value         NUMERIC(18,6),
+    max_value         NUMERIC(18,6),
+    expected_text     VARCHAR(255),
+    method            VARCHAR(255),
+    is_required       BOOLEAN       NOT NULL DEFAULT true,
+    sort_order        INTEGER       NOT NULL DEFAULT 0,
+    numeric_value     NUMERIC(18,6),
+    text_value        VARCHAR(255),
+    result            VARCHAR(20)   NOT NULL DEFAULT 'pending', -- pending, pass, fail
+    notes             TEXT
+);
+
+CREATE TABLE IF NOT EXISTS qc_retained_samples (
+    id                BIGSERIAL PRIMARY KEY,
+    inspection_id     BIGINT        NOT NULL REFERENCES qc_inspections(id),
+    material_id       BIGINT        NOT NULL REFERENCES materials(id),
+    batch_number      VARCHAR(100),
+    lot_number        VARCHAR(100),
+    quantity          NUMERIC(15,3) NOT NULL CHECK (quantity > 0),
+    storage_location  VARCHAR(255),
+    retained_at       DATE          NOT NULL DEFAULT CURRENT_DATE,
+    retain_until      DATE,
+    status            VARCHAR(20)   NOT NULL DEFAULT 'retained', -- retained, disposed
+    disposed_at       TIMESTAMP,
+    disposed_by       BIGINT        REFERENCES users(id),
+    disposal_notes    TEXT,
+    notes             TEXT,
+    created_at        TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    created_by        BIGINT
+);
+
+-- Indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_qct_material_active ON qc_templates(material_id) WHERE is_active;
+CREATE INDEX IF NOT EXISTS idx_qctt_template   ON qc_template_tests(template_id);
+CREATE INDEX IF NOT EXISTS idx_qci_grn         ON qc_inspections(grn_id);
+CREATE INDEX IF NOT EXISTS idx_qci_grn_item    ON qc_inspections(grn_item_id);
+CREATE INDEX IF NOT EXISTS idx_qci_material    ON qc_inspections(material_id, batch_number);
+CREATE INDEX IF NOT EXISTS idx_qcir_inspection ON qc_inspection_results(inspection_id);
+CREATE INDEX IF NOT EXISTS idx_qcrs_material   ON qc_retained_samples(material_id, batch_number);
+CREATE INDEX IF NOT EXISTS idx_qcrs_status     ON qc_retained_samples(status, retain_until);
+
+CREATE TRIGGER update_qc_templates_updated_at
+    BEFORE UPDATE ON qc_templates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_qc_inspections_updated_at
+    BEFORE UPDATE ON qc_inspections
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
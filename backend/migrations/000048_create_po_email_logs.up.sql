@@ -0,0 +1,25 @@
+-- Migration 000048: Supplier e-mail log for purchase orders
+-- Gửi đơn đặt hàng (kèm PDF) và nhắc lịch giao hàng cho NCC qua SMTP; mọi email đều được ghi nhận theo PO
+
+CREATE TABLE IF NOT EXISTS po_email_logs (
+    id                 BIGSERIAL PRIMARY KEY,
+    purchase_order_id  BIGINT        NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    supplier_id        BIGINT        REFERENCES suppliers(id),
+    message_type       VARCHAR(30)   NOT NULL,            -- purchase_order, delivery_reminder
+    recipients         TEXT          NOT NULL,            -- comma separated To addresses
+    cc                 TEXT,
+    subject            VARCHAR(500)  NOT NULL,
+    body               TEXT,
+    attachment_name    VARCHAR(255),
+    -- Expected delivery date the reminder was sent for; one automatic reminder per date
+    reminder_for       DATE,
+    status             VARCHAR(20)   NOT NULL,            -- sent, failed
+    error_message      TEXT,
+    sent_by            BIGINT,                            -- NULL for scheduled reminders
+    sent_at            TIMESTAMP,
+    created_at         TIMESTAMP     DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_po_email_logs_po ON po_email_logs(purchase_order_id);
+CREATE INDEX IF NOT EXISTS idx_po_email_logs_status ON po_email_logs(status);
+CREATE INDEX IF NOT EXISTS idx_po_email_logs_reminder ON po_email_logs(purchase_order_id, message_type, reminder_for);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS putaway_confirmations;
+DROP TABLE IF EXISTS putaway_tasks;
+ALTER TABLE warehouse_locations DROP COLUMN IF EXISTS hazardous_allowed;
+ALTER TABLE warehouse_locations DROP COLUMN IF EXISTS max_quantity;
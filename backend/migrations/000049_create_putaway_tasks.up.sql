@@ -0,0 +1,49 @@
+-- Migration 000049: Guided putaway after GRN/FPRN posting
+-- Hàng nhập kho chưa có vị trí (hoặc đang ở khu nhận hàng) sinh nhiệm vụ cất hàng; hệ thống gợi ý vị trí theo quy tắc
+
+-- Thuộc tính vị trí dùng cho gợi ý: sức chứa (theo đơn vị tồn kho) và cho phép hàng nguy hiểm
+ALTER TABLE warehouse_locations ADD COLUMN IF NOT EXISTS max_quantity DECIMAL(15,3);          -- NULL = không giới hạn
+ALTER TABLE warehouse_locations ADD COLUMN IF NOT EXISTS hazardous_allowed BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS putaway_tasks (
+    id                     BIGSERIAL PRIMARY KEY,
+    task_number            VARCHAR(50)    NOT NULL UNIQUE,
+    warehouse_id           BIGINT         NOT NULL REFERENCES warehouses(id),
+    source_type            VARCHAR(20)    NOT NULL,            -- GRN, FPRN
+    source_id              BIGINT         NOT NULL,
+    source_number          VARCHAR(50),
+    source_item_id         BIGINT,
+    item_type              VARCHAR(20)    NOT NULL,            -- material, finished_product
+    item_id                BIGINT         NOT NULL,
+    batch_number           VARCHAR(100),
+    lot_number             VARCHAR(100),
+    expiry_date            DATE,
+    quantity               DECIMAL(15,3)  NOT NULL,
+    putaway_quantity       DECIMAL(15,3)  NOT NULL DEFAULT 0,
+    -- Vị trí đang chứa hàng sau khi nhập (NULL = chưa có vị trí)
+    from_location_id       BIGINT         REFERENCES warehouse_locations(id),
+    suggested_location_id  BIGINT         REFERENCES warehouse_locations(id),
+    status                 VARCHAR(20)    NOT NULL DEFAULT 'pending',  -- pending, in_progress, completed, cancelled
+    completed_by           BIGINT,
+    completed_at           TIMESTAMP,
+    notes                  TEXT,
+    created_at             TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    created_by             BIGINT,
+    updated_at             TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_putaway_tasks_warehouse_status ON putaway_tasks(warehouse_id, status);
+CREATE INDEX IF NOT EXISTS idx_putaway_tasks_source ON putaway_tasks(source_type, source_id);
+CREATE INDEX IF NOT EXISTS idx_putaway_tasks_item ON putaway_tasks(item_type, item_id);
+
+-- Từng lần xác nhận cất hàng (một nhiệm vụ có thể chia ra nhiều vị trí)
+CREATE TABLE IF NOT EXISTS putaway_confirmations (
+    id               BIGSERIAL PRIMARY KEY,
+    task_id          BIGINT         NOT NULL REFERENCES putaway_tasks(id) ON DELETE CASCADE,
+    to_location_id   BIGINT         NOT NULL REFERENCES warehouse_locations(id),
+    quantity         DECIMAL(15,3)  NOT NULL,
+    confirmed_by     BIGINT,
+    confirmed_at     TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_putaway_confirmations_task ON putaway_confirmations(task_id);
@@ -0,0 +1,15 @@
+ALTER TABLE finished_products DROP COLUMN IF EXISTS units_per_pallet;
+ALTER TABLE finished_products DROP COLUMN IF EXISTS unit_volume_m3;
+ALTER TABLE materials DROP COLUMN IF EXISTS units_per_pallet;
+ALTER TABLE materials DROP COLUMN IF EXISTS unit_volume_m3;
+ALTER TABLE materials DROP COLUMN IF EXISTS unit_weight_kg;
+
+DROP INDEX IF EXISTS idx_warehouse_locations_zone;
+ALTER TABLE warehouse_locations DROP COLUMN IF EXISTS max_temperature;
+ALTER TABLE warehouse_locations DROP COLUMN IF EXISTS min_temperature;
+ALTER TABLE warehouse_locations DROP COLUMN IF EXISTS max_pallets;
+ALTER TABLE warehouse_locations DROP COLUMN IF EXISTS max_volume_m3;
+ALTER TABLE warehouse_locations DROP COLUMN IF EXISTS max_weight_kg;
+ALTER TABLE warehouse_locations DROP COLUMN IF EXISTS zone_id;
+
+DROP TABLE IF EXISTS warehouse_zones;
@@ -0,0 +1,40 @@
+-- Migration 000050: Location capacity, zoning and storage-condition constraints
+-- Khu vực kho (zone) với điều kiện bảo quản; sức chứa vị trí theo khối lượng/thể tích/pallet; kiểm tra khi nhập hàng vào vị trí
+
+CREATE TABLE IF NOT EXISTS warehouse_zones (
+    id                 BIGSERIAL PRIMARY KEY,
+    warehouse_id       BIGINT        NOT NULL REFERENCES warehouses(id),
+    code               VARCHAR(50)   NOT NULL,
+    name               VARCHAR(255)  NOT NULL,
+    zone_type          VARCHAR(30)   NOT NULL DEFAULT 'ambient',  -- ambient, cold, flammable, corrosive, hazardous, general
+    -- Nhiệt độ bảo quản của khu (°C); NULL = không kiểm soát nhiệt độ
+    min_temperature    DECIMAL(6,2),
+    max_temperature    DECIMAL(6,2),
+    hazardous_allowed  BOOLEAN       NOT NULL DEFAULT FALSE,
+    is_active          BOOLEAN       NOT NULL DEFAULT TRUE,
+    notes              TEXT,
+    created_at         TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    created_by         BIGINT,
+    updated_at         TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    updated_by         BIGINT,
+    CONSTRAINT uq_warehouse_zones_code UNIQUE (warehouse_id, code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_zones_warehouse ON warehouse_zones(warehouse_id);
+
+-- Vị trí: thuộc khu, sức chứa và nhiệt độ riêng (ghi đè nhiệt độ của khu)
+ALTER TABLE warehouse_locations ADD COLUMN IF NOT EXISTS zone_id BIGINT REFERENCES warehouse_zones(id);
+ALTER TABLE warehouse_locations ADD COLUMN IF NOT EXISTS max_weight_kg DECIMAL(15,3);
+ALTER TABLE warehouse_locations ADD COLUMN IF NOT EXISTS max_volume_m3 DECIMAL(15,3);
+ALTER TABLE warehouse_locations ADD COLUMN IF NOT EXISTS max_pallets INTEGER;
+ALTER TABLE warehouse_locations ADD COLUMN IF NOT EXISTS min_temperature DECIMAL(6,2);
+ALTER TABLE warehouse_locations ADD COLUMN IF NOT EXISTS max_temperature DECIMAL(6,2);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_locations_zone ON warehouse_locations(zone_id);
+
+-- Quy cách lưu kho của vật tư/thành phẩm để tính tải của vị trí
+ALTER TABLE materials ADD COLUMN IF NOT EXISTS unit_weight_kg DECIMAL(15,6);   -- NULL: đơn vị KG/G tự quy đổi
+ALTER TABLE materials ADD COLUMN IF NOT EXISTS unit_volume_m3 DECIMAL(15,6);
+ALTER TABLE materials ADD COLUMN IF NOT EXISTS units_per_pallet DECIMAL(15,3);
+ALTER TABLE finished_products ADD COLUMN IF NOT EXISTS unit_volume_m3 DECIMAL(15,6); -- khối lượng dùng gross_weight (kg)
+ALTER TABLE finished_products ADD COLUMN IF NOT EXISTS units_per_pallet DECIMAL(15,3);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pick_list_lines;
+DROP TABLE IF EXISTS pick_lists;
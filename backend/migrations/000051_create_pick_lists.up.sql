@@ -0,0 +1,52 @@
+-- Migration 000051: Pick lists for delivery orders
+-- Phiếu soạn hàng gom một hoặc nhiều DO, phân bổ lô/vị trí theo FEFO và sắp xếp theo lộ trình vị trí;
+-- kết quả soạn thực tế (thiếu hàng, thay lô/vị trí) được dùng khi xuất kho DO
+
+CREATE TABLE IF NOT EXISTS pick_lists (
+    id              BIGSERIAL PRIMARY KEY,
+    pick_number     VARCHAR(50)    NOT NULL UNIQUE,
+    warehouse_id    BIGINT         NOT NULL REFERENCES warehouses(id),
+    status          VARCHAR(20)    NOT NULL DEFAULT 'open',   -- open, in_progress, completed, cancelled
+    notes           TEXT,
+    completed_by    BIGINT,
+    completed_at    TIMESTAMP,
+    created_at      TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    created_by      BIGINT,
+    updated_at      TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pick_lists_warehouse_status ON pick_lists(warehouse_id, status);
+
+CREATE TABLE IF NOT EXISTS pick_list_lines (
+    id                      BIGSERIAL PRIMARY KEY,
+    pick_list_id            BIGINT         NOT NULL REFERENCES pick_lists(id) ON DELETE CASCADE,
+    sequence                INT            NOT NULL DEFAULT 0,   -- thứ tự đi lấy hàng theo vị trí
+    delivery_order_id       BIGINT         NOT NULL REFERENCES delivery_orders(id),
+    delivery_order_item_id  BIGINT         NOT NULL REFERENCES delivery_order_items(id) ON DELETE CASCADE,
+    do_number               VARCHAR(50),
+    finished_product_id     BIGINT         NOT NULL REFERENCES finished_products(id),
+    -- Vị trí/lô cần lấy (sau khi thay thế: vị trí/lô thực tế đã lấy)
+    location_id             BIGINT         REFERENCES warehouse_locations(id),
+    location_path           VARCHAR(255),
+    batch_number            VARCHAR(100),
+    lot_number              VARCHAR(100),
+    expiry_date             DATE,
+    quantity                DECIMAL(15,3)  NOT NULL,
+    picked_quantity         DECIMAL(15,3)  NOT NULL DEFAULT 0,
+    status                  VARCHAR(20)    NOT NULL DEFAULT 'pending',  -- pending, picked, short
+    short_reason            VARCHAR(255),
+    -- Phân bổ ban đầu khi người soạn lấy từ vị trí/lô khác
+    substituted             BOOLEAN        NOT NULL DEFAULT FALSE,
+    allocated_location_id   BIGINT         REFERENCES warehouse_locations(id),
+    allocated_batch_number  VARCHAR(100),
+    allocated_lot_number    VARCHAR(100),
+    picked_by               BIGINT,
+    picked_at               TIMESTAMP,
+    notes                   TEXT,
+    created_at              TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    updated_at              TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pick_list_lines_list ON pick_list_lines(pick_list_id, sequence);
+CREATE INDEX IF NOT EXISTS idx_pick_list_lines_do ON pick_list_lines(delivery_order_id);
+CREATE INDEX IF NOT EXISTS idx_pick_list_lines_stock ON pick_list_lines(finished_product_id, location_id, batch_number);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS pick_wave_lines;
+DROP TABLE IF EXISTS pick_wave_orders;
+DROP TABLE IF EXISTS pick_waves;
@@ -0,0 +1,68 @@
+-- Migration 000052: Wave picking for batches of delivery orders
+-- Gom các DO đang mở theo đơn vị vận chuyển / kênh bán / giờ chốt đơn thành một đợt (wave);
+-- mỗi đợt có một phiếu soạn hàng gộp, bước phân loại (sort/pack) chia lại số lượng đã soạn cho từng DO,
+-- sau đó xuất kho hàng loạt cả đợt
+
+CREATE TABLE IF NOT EXISTS pick_waves (
+    id                BIGSERIAL PRIMARY KEY,
+    wave_number       VARCHAR(50)    NOT NULL UNIQUE,
+    warehouse_id      BIGINT         NOT NULL REFERENCES warehouses(id),
+    pick_list_id      BIGINT         REFERENCES pick_lists(id),
+    -- Tiêu chí gom đơn
+    carrier_id        BIGINT         REFERENCES carriers(id),
+    sales_channel_id  BIGINT         REFERENCES sales_channels(id),
+    cutoff_at         TIMESTAMP,
+    delivery_date     DATE,
+    status            VARCHAR(20)    NOT NULL DEFAULT 'picking',  -- picking, picked, sorted, partially_shipped, shipped, cancelled
+    notes             TEXT,
+    picked_at         TIMESTAMP,
+    sorted_at         TIMESTAMP,
+    shipped_at        TIMESTAMP,
+    created_at        TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    created_by        BIGINT,
+    updated_at        TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pick_waves_warehouse_status ON pick_waves(warehouse_id, status);
+
+-- Các DO trong đợt và kết quả phân loại / xuất kho của từng DO
+CREATE TABLE IF NOT EXISTS pick_wave_orders (
+    id                 BIGSERIAL PRIMARY KEY,
+    wave_id            BIGINT         NOT NULL REFERENCES pick_waves(id) ON DELETE CASCADE,
+    delivery_order_id  BIGINT         NOT NULL REFERENCES delivery_orders(id),
+    do_number          VARCHAR(50),
+    sequence           INT            NOT NULL DEFAULT 0,
+    ordered_quantity   DECIMAL(15,3)  NOT NULL DEFAULT 0,
+    packed_quantity    DECIMAL(15,3)  NOT NULL DEFAULT 0,
+    status             VARCHAR(20)    NOT NULL DEFAULT 'pending',  -- pending, packed, short, released, shipped, failed
+    error_message      TEXT,
+    shipped_at         TIMESTAMP,
+    created_at         TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    updated_at         TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pick_wave_orders_wave ON pick_wave_orders(wave_id, sequence);
+CREATE INDEX IF NOT EXISTS idx_pick_wave_orders_do ON pick_wave_orders(delivery_order_id);
+
+-- Dòng soạn gộp: một dòng cho mỗi sản phẩm / vị trí / lô của cả đợt
+CREATE TABLE IF NOT EXISTS pick_wave_lines (
+    id                   BIGSERIAL PRIMARY KEY,
+    wave_id              BIGINT         NOT NULL REFERENCES pick_waves(id) ON DELETE CASCADE,
+    sequence             INT            NOT NULL DEFAULT 0,
+    finished_product_id  BIGINT         NOT NULL REFERENCES finished_products(id),
+    location_id          BIGINT         REFERENCES warehouse_locations(id),
+    location_path        VARCHAR(255),
+    batch_number         VARCHAR(100),
+    lot_number           VARCHAR(100),
+    expiry_date          DATE,
+    quantity             DECIMAL(15,3)  NOT NULL,
+    picked_quantity      DECIMAL(15,3)  NOT NULL DEFAULT 0,
+    status               VARCHAR(20)    NOT NULL DEFAULT 'pending',  -- pending, picked, short
+    short_reason         VARCHAR(255),
+    picked_by            BIGINT,
+    picked_at            TIMESTAMP,
+    created_at           TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    updated_at           TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pick_wave_lines_wave ON pick_wave_lines(wave_id, sequence);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS cycle_count_tasks;
+DROP TABLE IF EXISTS item_abc_classes;
+DROP TABLE IF EXISTS cycle_count_policies;
@@ -0,0 +1,79 @@
+-- Migration 000053: Cycle counting programme with ABC classification
+-- Phân loại ABC mặt hàng theo giá trị tiêu hao (từ stock_ledger), tần suất kiểm đếm theo nhóm,
+-- lịch tự động sinh phiếu kiểm đếm theo vị trí / mặt hàng, đếm mù (blind count),
+-- tính chênh lệch so với tồn kho tại thời điểm đếm, ngưỡng đếm lại và tạo phiếu điều chỉnh nháp
+
+-- Chính sách kiểm đếm của từng kho
+CREATE TABLE IF NOT EXISTS cycle_count_policies (
+    id                    BIGSERIAL PRIMARY KEY,
+    warehouse_id          BIGINT         NOT NULL UNIQUE REFERENCES warehouses(id),
+    -- Ngưỡng tỷ trọng giá trị tích lũy (%) cho nhóm A và B; phần còn lại là nhóm C
+    a_value_share         DECIMAL(5,2)   NOT NULL DEFAULT 80,
+    b_value_share         DECIMAL(5,2)   NOT NULL DEFAULT 95,
+    -- Tần suất kiểm đếm (ngày) theo nhóm
+    a_frequency_days      INT            NOT NULL DEFAULT 30,
+    b_frequency_days      INT            NOT NULL DEFAULT 90,
+    c_frequency_days      INT            NOT NULL DEFAULT 180,
+    lookback_days         INT            NOT NULL DEFAULT 365,
+    -- Chênh lệch vượt cả hai ngưỡng thì phải đếm lại
+    recount_threshold_pct DECIMAL(7,2)   NOT NULL DEFAULT 2,
+    recount_threshold_qty DECIMAL(15,3)  NOT NULL DEFAULT 0,
+    auto_schedule         BOOLEAN        NOT NULL DEFAULT FALSE,
+    created_at            TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    updated_at            TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Kết quả phân loại ABC theo kho
+CREATE TABLE IF NOT EXISTS item_abc_classes (
+    id                 BIGSERIAL PRIMARY KEY,
+    warehouse_id       BIGINT         NOT NULL REFERENCES warehouses(id),
+    item_type          VARCHAR(20)    NOT NULL,  -- material, finished_product
+    item_id            BIGINT         NOT NULL,
+    abc_class          VARCHAR(1)     NOT NULL DEFAULT 'C',
+    consumption_qty    DECIMAL(15,3)  NOT NULL DEFAULT 0,
+    consumption_value  DECIMAL(18,2)  NOT NULL DEFAULT 0,
+    cumulative_share   DECIMAL(7,2)   NOT NULL DEFAULT 0,
+    classified_at      TIMESTAMP,
+    last_counted_at    TIMESTAMP,
+    created_at         TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    updated_at         TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (warehouse_id, item_type, item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_abc_classes_class ON item_abc_classes(warehouse_id, abc_class);
+
+-- Phiếu kiểm đếm: một phiếu cho mỗi dòng tồn (vị trí / mặt hàng / lô)
+CREATE TABLE IF NOT EXISTS cycle_count_tasks (
+    id                      BIGSERIAL PRIMARY KEY,
+    task_number             VARCHAR(50)    NOT NULL UNIQUE,
+    warehouse_id            BIGINT         NOT NULL REFERENCES warehouses(id),
+    item_type               VARCHAR(20)    NOT NULL,
+    item_id                 BIGINT         NOT NULL,
+    warehouse_location_id   BIGINT         REFERENCES warehouse_locations(id),
+    batch_number            VARCHAR(100),
+    lot_number              VARCHAR(100),
+    abc_class               VARCHAR(1),
+    scheduled_date          DATE           NOT NULL,
+    status                  VARCHAR(20)    NOT NULL DEFAULT 'pending',  -- pending, recount, counted, adjusted, closed, cancelled
+    -- Tồn hệ thống chụp lại tại thời điểm đếm (ẩn với người đếm)
+    system_quantity         DECIMAL(15,3),
+    counted_quantity        DECIMAL(15,3),
+    first_counted_quantity  DECIMAL(15,3),
+    variance_quantity       DECIMAL(15,3),
+    variance_pct            DECIMAL(9,2),
+    unit_cost               DECIMAL(15,2)  NOT NULL DEFAULT 0,
+    variance_value          DECIMAL(18,2),
+    recount_count           INT            NOT NULL DEFAULT 0,
+    counted_by              BIGINT,
+    counted_at              TIMESTAMP,
+    approved_by             BIGINT,
+    approved_at             TIMESTAMP,
+    stock_adjustment_id     BIGINT         REFERENCES stock_adjustments(id),
+    notes                   TEXT,
+    created_at              TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    created_by              BIGINT,
+    updated_at              TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_cycle_count_tasks_warehouse_status ON cycle_count_tasks(warehouse_id, status);
+CREATE INDEX IF NOT EXISTS idx_cycle_count_tasks_item ON cycle_count_tasks(item_type, item_id);
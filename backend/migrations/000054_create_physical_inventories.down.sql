@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS physical_inventory_counts;
+DROP TABLE IF EXISTS physical_inventory_lines;
+DROP TABLE IF EXISTS physical_inventories;
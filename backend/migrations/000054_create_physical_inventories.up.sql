@@ -0,0 +1,64 @@
+-- Migration 000054: Full physical inventory (stocktake) with count freeze
+-- Chụp tồn kho (stock_balance) của một kho, khóa mọi nghiệp vụ nhập/xuất/chuyển kho trong lúc kiểm kê,
+-- sinh phiếu đếm theo vị trí, nhận nhiều vòng đếm cho mỗi dòng và khi chốt thì ghi sổ
+-- một phiếu điều chỉnh physical_count duy nhất rồi mở khóa kho
+
+CREATE TABLE IF NOT EXISTS physical_inventories (
+    id                   BIGSERIAL PRIMARY KEY,
+    inventory_number     VARCHAR(50)    NOT NULL UNIQUE,
+    warehouse_id         BIGINT         NOT NULL REFERENCES warehouses(id),
+    status               VARCHAR(20)    NOT NULL DEFAULT 'counting',  -- counting, finalized, cancelled
+    snapshot_at          TIMESTAMP      NOT NULL,
+    notes                TEXT,
+    -- Kết quả khi chốt
+    stock_adjustment_id  BIGINT         REFERENCES stock_adjustments(id),
+    gain_value           DECIMAL(18,2)  NOT NULL DEFAULT 0,
+    loss_value           DECIMAL(18,2)  NOT NULL DEFAULT 0,
+    net_value            DECIMAL(18,2)  NOT NULL DEFAULT 0,
+    finalized_by         BIGINT,
+    finalized_at         TIMESTAMP,
+    created_at           TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    created_by           BIGINT,
+    updated_at           TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Mỗi kho chỉ có một đợt kiểm kê đang đếm (đang khóa)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_physical_inventories_counting
+    ON physical_inventories(warehouse_id) WHERE status = 'counting';
+
+-- Dòng kiểm kê: tồn chụp lại theo vị trí / mặt hàng / lô; sheet_number gom các dòng cùng vị trí thành phiếu đếm
+CREATE TABLE IF NOT EXISTS physical_inventory_lines (
+    id                     BIGSERIAL PRIMARY KEY,
+    inventory_id           BIGINT         NOT NULL REFERENCES physical_inventories(id) ON DELETE CASCADE,
+    sheet_number           INT            NOT NULL DEFAULT 1,
+    warehouse_location_id  BIGINT         REFERENCES warehouse_locations(id),
+    location_path          VARCHAR(255),
+    item_type              VARCHAR(20)    NOT NULL,
+    item_id                BIGINT         NOT NULL,
+    batch_number           VARCHAR(100),
+    lot_number             VARCHAR(100),
+    expiry_date            DATE,
+    snapshot_quantity      DECIMAL(15,3)  NOT NULL DEFAULT 0,
+    unit_cost              DECIMAL(15,2)  NOT NULL DEFAULT 0,
+    counted_quantity       DECIMAL(15,3),              -- kết quả của vòng đếm cuối
+    count_rounds           INT            NOT NULL DEFAULT 0,
+    variance_quantity      DECIMAL(15,3),
+    variance_value         DECIMAL(18,2),
+    added_during_count     BOOLEAN        NOT NULL DEFAULT FALSE,  -- hàng tìm thấy ngoài ảnh chụp tồn
+    created_at             TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    updated_at             TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_physical_inventory_lines_sheet ON physical_inventory_lines(inventory_id, sheet_number);
+
+-- Các vòng đếm của từng dòng
+CREATE TABLE IF NOT EXISTS physical_inventory_counts (
+    id                BIGSERIAL PRIMARY KEY,
+    line_id           BIGINT         NOT NULL REFERENCES physical_inventory_lines(id) ON DELETE CASCADE,
+    round             INT            NOT NULL,
+    counted_quantity  DECIMAL(15,3)  NOT NULL,
+    counted_by        BIGINT,
+    counted_at        TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    notes             TEXT,
+    UNIQUE (line_id, round)
+);
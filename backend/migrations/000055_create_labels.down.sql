@@ -0,0 +1,3 @@
+DROP SEQUENCE IF EXISTS label_sscc_seq;
+DROP TABLE IF EXISTS label_print_logs;
+DROP TABLE IF EXISTS label_templates;
@@ -0,0 +1,59 @@
+-- Migration 000055: Label templates and print log
+-- Mẫu nhãn (ZPL cho máy in nhiệt, PDF khổ A4 cho máy in laser) cho lô nhập (GRN), lô thành phẩm (FPRN),
+-- vị trí kho và pallet; nhật ký in/in lại lưu lại nội dung nhãn để in lại đúng như bản gốc
+
+CREATE TABLE IF NOT EXISTS label_templates (
+    id              BIGSERIAL PRIMARY KEY,
+    code            VARCHAR(50)    NOT NULL UNIQUE,
+    name            VARCHAR(255)   NOT NULL,
+    label_type      VARCHAR(20)    NOT NULL,                     -- grn_item, fprn_batch, location, pallet
+    format          VARCHAR(10)    NOT NULL,                     -- zpl, pdf
+    width_mm        DECIMAL(7,2)   NOT NULL,
+    height_mm       DECIMAL(7,2)   NOT NULL,
+    -- ZPL: độ phân giải (8 = 203 dpi, 12 = 300 dpi), loại mã vạch và thân mẫu (Go text/template; rỗng = mẫu mặc định)
+    dpmm            INT            NOT NULL DEFAULT 8,
+    barcode_type    VARCHAR(20)    NOT NULL DEFAULT 'code128',   -- code128, qr, datamatrix
+    body            TEXT,
+    -- PDF: lưới nhãn trên tờ A4
+    sheet_columns   INT            NOT NULL DEFAULT 1,
+    sheet_rows      INT            NOT NULL DEFAULT 1,
+    margin_left_mm  DECIMAL(7,2)   NOT NULL DEFAULT 0,
+    margin_top_mm   DECIMAL(7,2)   NOT NULL DEFAULT 0,
+    gap_x_mm        DECIMAL(7,2)   NOT NULL DEFAULT 0,
+    gap_y_mm        DECIMAL(7,2)   NOT NULL DEFAULT 0,
+    is_default      BOOLEAN        NOT NULL DEFAULT FALSE,
+    is_active       BOOLEAN        NOT NULL DEFAULT TRUE,
+    created_at      TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    created_by      BIGINT,
+    updated_at      TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    updated_by      BIGINT
+);
+
+-- Mỗi loại nhãn / định dạng chỉ có một mẫu mặc định
+CREATE UNIQUE INDEX IF NOT EXISTS idx_label_templates_default
+    ON label_templates(label_type, format) WHERE is_default;
+
+-- Nhật ký in nhãn; content là danh sách nhãn đã in (JSON) để in lại y hệt, kể cả SSCC của pallet
+CREATE TABLE IF NOT EXISTS label_print_logs (
+    id              BIGSERIAL PRIMARY KEY,
+    label_type      VARCHAR(20)    NOT NULL,
+    format          VARCHAR(10)    NOT NULL,
+    template_id     BIGINT         REFERENCES label_templates(id) ON DELETE SET NULL,
+    source_type     VARCHAR(30),                                 -- grn, fprn, warehouse, location, item
+    source_id       BIGINT,
+    source_number   VARCHAR(50),
+    label_count     INT            NOT NULL DEFAULT 0,
+    copies          INT            NOT NULL DEFAULT 1,
+    content         JSONB          NOT NULL DEFAULT '[]',
+    reprint_of_id   BIGINT         REFERENCES label_print_logs(id),
+    reason          TEXT,
+    printed_by      BIGINT,
+    printed_by_name VARCHAR(100),
+    printed_at      TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_label_print_logs_source ON label_print_logs(source_type, source_id);
+CREATE INDEX IF NOT EXISTS idx_label_print_logs_printed_at ON label_print_logs(printed_at);
+
+-- Số tham chiếu nối tiếp cho SSCC của pallet
+CREATE SEQUENCE IF NOT EXISTS label_sscc_seq;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS mobile_task_exceptions;
+DROP TABLE IF EXISTS mobile_step_confirmations;
+DROP TABLE IF EXISTS mobile_task_assignments;
@@ -0,0 +1,60 @@
+-- Migration 000056: Mobile handheld tasks
+-- Giao việc cho nhân viên kho trên thiết bị cầm tay (cất hàng, soạn hàng, kiểm đếm, chuyển kho),
+-- nhật ký xác nhận từng bước theo request_id để gửi lại an toàn khi mất kết nối, và báo cáo sự cố
+
+-- Mỗi nhiệm vụ chỉ có một người nhận đang hiệu lực (status = active)
+CREATE TABLE IF NOT EXISTS mobile_task_assignments (
+    id                BIGSERIAL PRIMARY KEY,
+    task_type         VARCHAR(20)    NOT NULL,                   -- putaway, pick, count, transfer
+    task_id           BIGINT         NOT NULL,
+    warehouse_id      BIGINT,
+    assigned_to       BIGINT         NOT NULL,
+    assigned_to_name  VARCHAR(100),
+    assigned_by       BIGINT,
+    status            VARCHAR(20)    NOT NULL DEFAULT 'active',  -- active, released, completed
+    claimed_at        TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at         TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mobile_task_assignments_active
+    ON mobile_task_assignments(task_type, task_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_mobile_task_assignments_user ON mobile_task_assignments(assigned_to, status);
+
+-- Xác nhận bước: request_id do thiết bị sinh ra; gửi lại cùng request_id trả về đúng kết quả đã lưu
+CREATE TABLE IF NOT EXISTS mobile_step_confirmations (
+    id            BIGSERIAL PRIMARY KEY,
+    request_id    VARCHAR(64)    NOT NULL UNIQUE,
+    user_id       BIGINT         NOT NULL,
+    task_type     VARCHAR(20)    NOT NULL,
+    task_id       BIGINT         NOT NULL,
+    step_id       BIGINT         NOT NULL DEFAULT 0,             -- dòng soạn hàng / dòng chuyển kho; 0 = cả nhiệm vụ
+    request_hash  VARCHAR(64)    NOT NULL,
+    response      JSONB          NOT NULL DEFAULT '{}',
+    confirmed_at  TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_mobile_step_confirmations_task ON mobile_step_confirmations(task_type, task_id);
+
+-- Sự cố do nhân viên báo: hàng hỏng, thiếu, sai hàng, vị trí bị chặn...
+CREATE TABLE IF NOT EXISTS mobile_task_exceptions (
+    id               BIGSERIAL PRIMARY KEY,
+    task_type        VARCHAR(20)    NOT NULL,
+    task_id          BIGINT         NOT NULL,
+    task_number      VARCHAR(50),
+    step_id          BIGINT         NOT NULL DEFAULT 0,
+    warehouse_id     BIGINT,
+    exception_type   VARCHAR(30)    NOT NULL,                    -- damaged, missing, wrong_item, location_blocked, short, other
+    quantity         DECIMAL(15,3),
+    scanned_code     VARCHAR(255),
+    notes            TEXT,
+    status           VARCHAR(20)    NOT NULL DEFAULT 'open',     -- open, resolved
+    reported_by      BIGINT,
+    reported_by_name VARCHAR(100),
+    reported_at      TIMESTAMP      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_by      BIGINT,
+    resolved_at      TIMESTAMP,
+    resolution       TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_mobile_task_exceptions_task ON mobile_task_exceptions(task_type, task_id);
+CREATE INDEX IF NOT EXISTS idx_mobile_task_exceptions_status ON mobile_task_exceptions(status, warehouse_id);
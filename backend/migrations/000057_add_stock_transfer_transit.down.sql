@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_warehouse_locations_in_transit;
+
+ALTER TABLE stock_transfer_items
+    DROP COLUMN IF EXISTS discrepancy_reason,
+    DROP COLUMN IF EXISTS discrepancy_quantity,
+    DROP COLUMN IF EXISTS shipped_quantity;
+
+ALTER TABLE stock_transfers
+    DROP COLUMN IF EXISTS expected_arrival_date,
+    DROP COLUMN IF EXISTS tracking_number,
+    DROP COLUMN IF EXISTS carrier,
+    DROP COLUMN IF EXISTS transit_location_id;
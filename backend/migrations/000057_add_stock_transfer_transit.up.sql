@@ -0,0 +1,19 @@
+-- Migration 000057: Two-step stock transfers
+-- Xuất kho chuyển hàng vào vị trí "đang vận chuyển" (in_transit) của kho nhận, nhận hàng tại kho đích
+-- và ghi nhận chênh lệch số lượng giữa hàng xuất và hàng nhận
+
+ALTER TABLE stock_transfers
+    ADD COLUMN IF NOT EXISTS transit_location_id   BIGINT REFERENCES warehouse_locations(id),
+    ADD COLUMN IF NOT EXISTS carrier               VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS tracking_number       VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS expected_arrival_date DATE;
+
+-- shipped_quantity: số lượng đã xuất đi; discrepancy_quantity: phần thiếu khi nhận (ghi giảm khỏi hàng đang vận chuyển)
+ALTER TABLE stock_transfer_items
+    ADD COLUMN IF NOT EXISTS shipped_quantity     DECIMAL(15,3) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS discrepancy_quantity DECIMAL(15,3) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS discrepancy_reason   TEXT;
+
+-- Mỗi kho có tối đa một vị trí đang vận chuyển, tạo tự động khi xuất phiếu chuyển kho đầu tiên
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouse_locations_in_transit
+    ON warehouse_locations(warehouse_id) WHERE location_type = 'in_transit';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS location_move_items;
+DROP TABLE IF EXISTS location_moves;
@@ -0,0 +1,36 @@
+-- Migration 000058: Internal bin-to-bin moves
+-- Phiếu chuyển vị trí trong cùng một kho (không qua phiếu chuyển kho): nhập tay, quét mã trên thiết bị cầm tay
+-- hoặc tạo từ gợi ý gom hàng (cùng vật tư/lô nằm rải rác ở nhiều vị trí chưa đầy)
+
+CREATE TABLE IF NOT EXISTS location_moves (
+    id            BIGSERIAL PRIMARY KEY,
+    move_number   VARCHAR(50)    NOT NULL UNIQUE,
+    warehouse_id  BIGINT         NOT NULL REFERENCES warehouses(id),
+    source        VARCHAR(20)    NOT NULL DEFAULT 'manual',   -- manual, scan, consolidation
+    status        VARCHAR(20)    NOT NULL DEFAULT 'draft',    -- draft, posted, cancelled
+    notes         TEXT,
+    posted_by     BIGINT,
+    posted_at     TIMESTAMP,
+    created_at    TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    created_by    BIGINT,
+    updated_at    TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_location_moves_warehouse_status ON location_moves(warehouse_id, status);
+
+CREATE TABLE IF NOT EXISTS location_move_items (
+    id                BIGSERIAL PRIMARY KEY,
+    move_id           BIGINT         NOT NULL REFERENCES location_moves(id) ON DELETE CASCADE,
+    item_type         VARCHAR(20)    NOT NULL,            -- material, finished_product
+    item_id           BIGINT         NOT NULL,
+    batch_number      VARCHAR(100),
+    lot_number        VARCHAR(100),
+    from_location_id  BIGINT         NOT NULL REFERENCES warehouse_locations(id),
+    to_location_id    BIGINT         NOT NULL REFERENCES warehouse_locations(id),
+    quantity          DECIMAL(15,3)  NOT NULL,
+    unit_cost         DECIMAL(15,2)  NOT NULL DEFAULT 0,  -- giá vốn tại vị trí nguồn khi ghi sổ
+    notes             TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_location_move_items_move ON location_move_items(move_id);
+CREATE INDEX IF NOT EXISTS idx_location_move_items_item ON location_move_items(item_type, item_id);
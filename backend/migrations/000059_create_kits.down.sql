@@ -0,0 +1,7 @@
+ALTER TABLE pick_list_lines DROP COLUMN IF EXISTS kit_product_id;
+ALTER TABLE delivery_order_items DROP COLUMN IF EXISTS kit_product_id;
+ALTER TABLE delivery_order_items DROP COLUMN IF EXISTS explode_kit;
+DROP TABLE IF EXISTS kit_order_lines;
+DROP TABLE IF EXISTS kit_orders;
+DROP TABLE IF EXISTS kit_components;
+DROP TABLE IF EXISTS kits;
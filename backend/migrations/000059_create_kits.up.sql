@@ -0,0 +1,71 @@
+-- Migration 000059: Kits, assembly and disassembly orders
+-- Bộ combo (ví dụ sữa rửa mặt + toner + serum) lắp ráp trong kho từ các thành phẩm thành phần.
+-- Phiếu lắp ráp xuất thành phần và nhập bộ kit với giá vốn cộng dồn trong một lần ghi sổ;
+-- phiếu tháo dỡ làm ngược lại. Dòng phiếu xuất của bộ kit có thể được tách thành thành phần khi lấy hàng.
+
+CREATE TABLE IF NOT EXISTS kits (
+    id               BIGSERIAL PRIMARY KEY,
+    kit_product_id   BIGINT         NOT NULL UNIQUE REFERENCES finished_products(id),
+    explode_on_pick  BOOLEAN        NOT NULL DEFAULT FALSE,   -- lấy hàng theo thành phần thay vì bộ kit đã lắp
+    is_active        BOOLEAN        NOT NULL DEFAULT TRUE,
+    notes            TEXT,
+    created_at       TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    created_by       BIGINT,
+    updated_at       TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    updated_by       BIGINT
+);
+
+CREATE TABLE IF NOT EXISTS kit_components (
+    id                    BIGSERIAL PRIMARY KEY,
+    kit_id                BIGINT         NOT NULL REFERENCES kits(id) ON DELETE CASCADE,
+    component_product_id  BIGINT         NOT NULL REFERENCES finished_products(id),
+    quantity              DECIMAL(15,3)  NOT NULL CHECK (quantity > 0),   -- số lượng cho một bộ kit
+    sequence              INT            NOT NULL DEFAULT 0,
+    notes                 TEXT,
+    UNIQUE (kit_id, component_product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kit_components_product ON kit_components(component_product_id);
+
+CREATE TABLE IF NOT EXISTS kit_orders (
+    id                     BIGSERIAL PRIMARY KEY,
+    order_number           VARCHAR(50)    NOT NULL UNIQUE,
+    order_type             VARCHAR(20)    NOT NULL,                    -- assembly, disassembly
+    kit_id                 BIGINT         NOT NULL REFERENCES kits(id),
+    kit_product_id         BIGINT         NOT NULL REFERENCES finished_products(id),
+    warehouse_id           BIGINT         NOT NULL REFERENCES warehouses(id),
+    quantity               DECIMAL(15,3)  NOT NULL CHECK (quantity > 0),
+    kit_location_id        BIGINT         REFERENCES warehouse_locations(id),
+    kit_batch_number       VARCHAR(100),
+    kit_lot_number         VARCHAR(100),
+    component_location_id  BIGINT         REFERENCES warehouse_locations(id),
+    status                 VARCHAR(20)    NOT NULL DEFAULT 'draft',    -- draft, posted, cancelled
+    unit_cost              DECIMAL(15,2)  NOT NULL DEFAULT 0,
+    total_cost             DECIMAL(15,2)  NOT NULL DEFAULT 0,
+    expiry_date            DATE,
+    notes                  TEXT,
+    posted_by              BIGINT,
+    posted_at              TIMESTAMP,
+    created_at             TIMESTAMP      DEFAULT CURRENT_TIMESTAMP,
+    created_by             BIGINT,
+    updated_at             TIMESTAMP      DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_kit_orders_warehouse_status ON kit_orders(warehouse_id, status);
+CREATE INDEX IF NOT EXISTS idx_kit_orders_kit ON kit_orders(kit_id);
+
+CREATE TABLE IF NOT EXISTS kit_order_lines (
+    id                    BIGSERIAL PRIMARY KEY,
+    order_id              BIGINT         NOT NULL REFERENCES kit_orders(id) ON DELETE CASCADE,
+    component_product_id  BIGINT         NOT NULL REFERENCES finished_products(id),
+    quantity_per_kit      DECIMAL(15,3)  NOT NULL,
+    quantity              DECIMAL(15,3)  NOT NULL,
+    unit_cost             DECIMAL(15,2)  NOT NULL DEFAULT 0,
+    total_cost            DECIMAL(15,2)  NOT NULL DEFAULT 0
+);
+
+-- Dòng phiếu xuất: explode_kit NULL = theo thiết lập của bộ kit; kit_product_id ghi lại bộ kit
+-- khi dòng đã được tách thành thành phần lúc lấy hàng
+ALTER TABLE delivery_order_items ADD COLUMN IF NOT EXISTS explode_kit BOOLEAN;
+ALTER TABLE delivery_order_items ADD COLUMN IF NOT EXISTS kit_product_id BIGINT REFERENCES finished_products(id);
+ALTER TABLE pick_list_lines ADD COLUMN IF NOT EXISTS kit_product_id BIGINT REFERENCES finished_products(id);
@@ -0,0 +1,11 @@
+ALTER TABLE return_order_items DROP COLUMN IF EXISTS serial_numbers;
+ALTER TABLE stock_transfer_items DROP COLUMN IF EXISTS received_serial_numbers;
+ALTER TABLE stock_transfer_items DROP COLUMN IF EXISTS serial_numbers;
+ALTER TABLE delivery_order_items DROP COLUMN IF EXISTS serial_numbers;
+ALTER TABLE material_issue_note_items DROP COLUMN IF EXISTS serial_numbers;
+ALTER TABLE finished_product_receipt_items DROP COLUMN IF EXISTS serial_numbers;
+ALTER TABLE goods_receipt_note_items DROP COLUMN IF EXISTS serial_numbers;
+DROP TABLE IF EXISTS stock_ledger_serials;
+DROP TABLE IF EXISTS serial_numbers;
+ALTER TABLE finished_products DROP COLUMN IF EXISTS serial_tracked;
+ALTER TABLE materials DROP COLUMN IF EXISTS serial_tracked;
@@ -0,0 +1,46 @@
+-- Migration 000060: Serial-number tracking
+-- Theo dõi số serial cho hàng giá trị cao: bật theo từng nguyên liệu/thành phẩm.
+-- Serial được ghi nhận khi nhập (GRN/FPRN), chọn khi xuất (MIN/DO/chuyển kho) và
+-- liên kết với từng dòng stock_ledger để tra cứu lịch sử.
+
+ALTER TABLE materials ADD COLUMN IF NOT EXISTS serial_tracked BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE finished_products ADD COLUMN IF NOT EXISTS serial_tracked BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS serial_numbers (
+    id                     BIGSERIAL PRIMARY KEY,
+    item_type              VARCHAR(20)   NOT NULL,   -- material, finished_product
+    item_id                BIGINT        NOT NULL,
+    serial_number          VARCHAR(100)  NOT NULL,
+    status                 VARCHAR(20)   NOT NULL DEFAULT 'in_stock',   -- in_stock, issued, shipped, returned
+    warehouse_id           BIGINT        REFERENCES warehouses(id),
+    warehouse_location_id  BIGINT        REFERENCES warehouse_locations(id),   -- vị trí ghi nhận ở lần ghi sổ gần nhất
+    batch_number           VARCHAR(100),
+    lot_number             VARCHAR(100),
+    last_reference_type    VARCHAR(50),
+    last_reference_id      BIGINT,
+    created_at             TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    updated_at             TIMESTAMP     DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (item_type, item_id, serial_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_serial_numbers_serial ON serial_numbers(serial_number);
+CREATE INDEX IF NOT EXISTS idx_serial_numbers_status ON serial_numbers(status);
+CREATE INDEX IF NOT EXISTS idx_serial_numbers_warehouse ON serial_numbers(warehouse_id);
+
+CREATE TABLE IF NOT EXISTS stock_ledger_serials (
+    id                BIGSERIAL PRIMARY KEY,
+    stock_ledger_id   BIGINT  NOT NULL REFERENCES stock_ledger(id) ON DELETE CASCADE,
+    serial_number_id  BIGINT  NOT NULL REFERENCES serial_numbers(id) ON DELETE CASCADE,
+    UNIQUE (stock_ledger_id, serial_number_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_ledger_serials_serial ON stock_ledger_serials(serial_number_id);
+
+-- Danh sách serial trên từng dòng chứng từ (mảng JSON)
+ALTER TABLE goods_receipt_note_items ADD COLUMN IF NOT EXISTS serial_numbers JSONB;
+ALTER TABLE finished_product_receipt_items ADD COLUMN IF NOT EXISTS serial_numbers JSONB;
+ALTER TABLE material_issue_note_items ADD COLUMN IF NOT EXISTS serial_numbers JSONB;
+ALTER TABLE delivery_order_items ADD COLUMN IF NOT EXISTS serial_numbers JSONB;
+ALTER TABLE stock_transfer_items ADD COLUMN IF NOT EXISTS serial_numbers JSONB;
+ALTER TABLE stock_transfer_items ADD COLUMN IF NOT EXISTS received_serial_numbers JSONB;   -- serial thực nhận ở kho đích
+ALTER TABLE return_order_items ADD COLUMN IF NOT EXISTS serial_numbers JSONB;
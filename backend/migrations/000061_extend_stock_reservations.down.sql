@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_stock_reservations_reference_number;
+ALTER TABLE stock_reservations DROP COLUMN IF EXISTS released_quantity;
+ALTER TABLE stock_reservations DROP COLUMN IF EXISTS reference_number;
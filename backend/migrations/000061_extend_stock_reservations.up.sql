@@ -0,0 +1,8 @@
+-- Migration 000061: Manual stock reservations
+-- Giữ hàng thủ công cho đơn hàng của khách: số tham chiếu của đơn, số lượng đã nhả ra
+-- (nhả một phần hoặc toàn bộ) để theo dõi lịch sử giữ hàng.
+
+ALTER TABLE stock_reservations ADD COLUMN IF NOT EXISTS reference_number VARCHAR(100);   -- số đơn hàng của khách (customer_order)
+ALTER TABLE stock_reservations ADD COLUMN IF NOT EXISTS released_quantity DECIMAL(15,3) NOT NULL DEFAULT 0;   -- đã nhả, không còn giữ
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_reference_number ON stock_reservations(reference_number);
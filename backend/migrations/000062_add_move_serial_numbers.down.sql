@@ -0,0 +1,2 @@
+ALTER TABLE location_move_items DROP COLUMN IF EXISTS serial_numbers;
+ALTER TABLE putaway_confirmations DROP COLUMN IF EXISTS serial_numbers;
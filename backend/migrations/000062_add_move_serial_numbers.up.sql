@@ -0,0 +1,6 @@
+-- Migration 000062: Serial numbers on putaway confirmations and location moves
+-- Serial đi theo hàng khi cất hàng (putaway) và chuyển vị trí, để vị trí của serial
+-- và lịch sử trên stock_ledger luôn đúng.
+
+ALTER TABLE putaway_confirmations ADD COLUMN IF NOT EXISTS serial_numbers JSONB;
+ALTER TABLE location_move_items ADD COLUMN IF NOT EXISTS serial_numbers JSONB;
@@ -0,0 +1 @@
+ALTER TABLE purchase_agreements DROP COLUMN IF EXISTS currency;
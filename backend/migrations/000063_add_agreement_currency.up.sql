@@ -0,0 +1,5 @@
+-- Migration 000063: Currency of purchase agreements
+-- Giá thỏa thuận của hợp đồng khung được chốt theo một loại tiền; đơn gọi hàng (call-off)
+-- phải cùng loại tiền với hợp đồng, không tự lấy giá VND cho đơn USD.
+
+ALTER TABLE purchase_agreements ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'VND';
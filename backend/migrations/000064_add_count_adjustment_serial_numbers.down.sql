@@ -0,0 +1,4 @@
+ALTER TABLE cycle_count_tasks DROP COLUMN IF EXISTS serial_numbers;
+ALTER TABLE physical_inventory_lines DROP COLUMN IF EXISTS serial_numbers;
+ALTER TABLE purchase_return_items DROP COLUMN IF EXISTS serial_numbers;
+ALTER TABLE stock_adjustment_items DROP COLUMN IF EXISTS serial_numbers;
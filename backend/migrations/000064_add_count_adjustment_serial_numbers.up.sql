@@ -0,0 +1,8 @@
+-- Migration 000064: Serial numbers on stock adjustments, purchase returns and counts
+-- Điều chỉnh tồn kho, trả hàng nhà cung cấp và kiểm kê (toàn kho, kiểm kê vòng) ghi nhận serial,
+-- để trạng thái serial luôn khớp với tồn kho.
+
+ALTER TABLE stock_adjustment_items ADD COLUMN IF NOT EXISTS serial_numbers JSONB;
+ALTER TABLE purchase_return_items ADD COLUMN IF NOT EXISTS serial_numbers JSONB;
+ALTER TABLE physical_inventory_lines ADD COLUMN IF NOT EXISTS serial_numbers JSONB;
+ALTER TABLE cycle_count_tasks ADD COLUMN IF NOT EXISTS serial_numbers JSONB;
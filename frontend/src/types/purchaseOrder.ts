@@ -24,7 +24,7 @@ export interface PurchaseOrder {
     order_status?: 'pending' | 'ordered';
     payment_status?: 'pending' | 'partial' | 'completed';
     invoice_status?: 'pending' | 'received';
-    receipt_status?: 'pending' | 'completed';
+    receipt_status?: 'pending' | 'partial' | 'received' | 'closed_short';
     invoice_number?: string;
     invoice_date?: string;
     subtotal: number;